@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/indaco/md2audio/internal/cache"
 	"github.com/indaco/md2audio/internal/cli"
 	"github.com/indaco/md2audio/internal/config"
 	"github.com/indaco/md2audio/internal/logger"
 	"github.com/indaco/md2audio/internal/processor"
+	"github.com/indaco/md2audio/internal/server"
+	"github.com/indaco/md2audio/internal/ui"
 	"github.com/indaco/md2audio/internal/version"
 )
 
@@ -31,6 +36,81 @@ func run(cfg config.Config, log logger.LoggerInterface) error {
 		return cli.HandleVoiceCommands(cfg, voiceCache, log)
 	}
 
+	// Create, edit, or delete ElevenLabs voices
+	if cli.IsVoiceManagement(cfg.Commands) {
+		return cli.HandleVoiceManagement(cfg, voiceCache, log)
+	}
+
+	// List ElevenLabs models
+	if cfg.Commands.ListModels {
+		return cli.HandleModelCommands(cfg, voiceCache, log)
+	}
+
+	// Handle run history commands
+	if cfg.Commands.History || cfg.Commands.Stats {
+		return cli.HandleHistoryCommands(cfg, log)
+	}
+
+	// Report (and link) duplicate audio files
+	if cfg.Commands.DedupReport {
+		return cli.HandleDedup(cfg, log)
+	}
+
+	// Extract a bundle created by -bundle
+	if cfg.Commands.Unbundle != "" {
+		return cli.HandleUnbundle(cfg, log)
+	}
+
+	// Re-fit existing audio to updated timings
+	if cfg.Commands.Retime {
+		return processor.RetimeFile(cfg, log)
+	}
+
+	// Dump the parsed sections for other tools
+	if cfg.Commands.ExportSections != "" {
+		return processor.ExportSections(cfg, log)
+	}
+
+	// Check the ElevenLabs character quota
+	if cfg.Commands.Quota {
+		return processor.ShowQuota(cfg, log)
+	}
+
+	// Audition sections through the speakers
+	if cfg.Commands.Preview {
+		return processor.PreviewAloud(cfg, log)
+	}
+
+	// Serve generated output for review
+	if cfg.Commands.ServeOutput {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		opts, err := serverOptions(cfg, log)
+		if err != nil {
+			return err
+		}
+		return server.Serve(ctx, opts, log)
+	}
+
+	// Serve the local web UI
+	if cfg.Commands.UI {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return ui.Serve(ctx, ui.Options{Addr: cfg.Commands.UIAddr, Config: cfg, VoiceCache: voiceCache}, log)
+	}
+
+	// Pick the voice matching -voice-criteria (silent scaffolds need no voice)
+	if !cfg.SilenceOnly {
+		if cfg, err = cli.ResolveVoiceCriteria(cfg, voiceCache, log); err != nil {
+			return err
+		}
+	}
+
+	// Measure the local voice's speaking rate
+	if cfg.Commands.Calibrate {
+		return cli.HandleCalibrate(cfg, log)
+	}
+
 	// Validate configuration for audio processing
 	if err := cfg.Validate(); err != nil {
 		return err
@@ -38,7 +118,76 @@ func run(cfg config.Config, log logger.LoggerInterface) error {
 
 	cfg.Print()
 
-	// Process based on mode
+	if err := process(cfg, log); err != nil {
+		return err
+	}
+
+	// Package the output for hand-off
+	if cfg.Bundle != "" {
+		return cli.CreateBundle(cfg, log)
+	}
+	return nil
+}
+
+// serverOptions configures -serve-output, enabling token authentication when
+// tenants are configured and the GitHub webhook when -webhook-secret or
+// -webhook-tenants is set.
+func serverOptions(cfg config.Config, log logger.LoggerInterface) (server.Options, error) {
+	opts := server.Options{Addr: cfg.Commands.ServeAddr, Root: cfg.OutputDir}
+	if cfg.Server.TenantsFile != "" {
+		tenants, err := config.LoadTenants(cfg.Server.TenantsFile)
+		if err != nil {
+			return opts, err
+		}
+		cfg.Tenants = tenants
+	}
+	if err := cfg.ValidateWebhook(); err != nil {
+		return opts, err
+	}
+
+	for _, tenant := range cfg.Tenants {
+		tenantCfg := cfg.ForTenant(tenant)
+		if err := os.MkdirAll(tenantCfg.OutputDir, 0755); err != nil {
+			return opts, fmt.Errorf("error creating output directory: %w", err)
+		}
+		opts.Tenants = append(opts.Tenants, server.Tenant{Name: tenant.Name, Token: tenant.Token, Root: tenantCfg.OutputDir})
+	}
+
+	if !cfg.WebhookEnabled() {
+		return opts, nil
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return opts, fmt.Errorf("error creating output directory: %w", err)
+	}
+	regenerate := func(ctx context.Context, tenantName string, files []string) error {
+		runCfg := cfg
+		if tenant, ok := cfg.FindTenant(tenantName); ok {
+			runCfg = cfg.ForTenant(tenant)
+		}
+		return processor.ProcessChanged(ctx, files, runCfg, log)
+	}
+	limits := server.Limits{
+		MaxJobs:       cfg.Server.MaxJobs,
+		MaxQueue:      cfg.Server.MaxQueue,
+		MaxJobsPerKey: cfg.Server.MaxJobsPerClient,
+		MaxBodyBytes:  int64(cfg.Server.MaxBodyKB) * 1024,
+	}
+	if cfg.Webhook.Tenants {
+		opts.Webhook = server.NewTenantWebhook(opts.Tenants, cfg.Webhook.Paths, regenerate, limits, log)
+	} else {
+		opts.Webhook = server.NewWebhook(cfg.Webhook.Secret, cfg.Webhook.Paths, regenerate, limits, log)
+	}
+	return opts, nil
+}
+
+// process generates audio for the selected input mode.
+func process(cfg config.Config, log logger.LoggerInterface) error {
+	if cfg.Rerun.Manifest != "" {
+		return processor.ProcessManifest(cfg, log)
+	}
+	if len(cfg.Languages) > 0 {
+		return processor.ProcessLanguages(cfg, log)
+	}
 	if cfg.IsDirectoryMode() {
 		return processor.ProcessDirectory(cfg, log)
 	}
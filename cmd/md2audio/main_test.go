@@ -192,6 +192,53 @@ func TestRunNonExistentFile(t *testing.T) {
 	}
 }
 
+func TestRunServeOutputMissingDirectory(t *testing.T) {
+	cfg := config.Config{
+		OutputDir: filepath.Join(t.TempDir(), "missing"),
+		Commands: config.CommandFlags{
+			ServeOutput: true,
+			ServeAddr:   "127.0.0.1:0",
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	err := run(cfg, log)
+	if err == nil || !strings.Contains(err.Error(), "output directory not found") {
+		t.Errorf("run() should error on missing output directory, got %v", err)
+	}
+}
+
+func TestServerOptionsTenantsOnly(t *testing.T) {
+	// A review-only deployment: tenants browse their output, no checkout to regenerate from
+	tmpDir := t.TempDir()
+	tenantsFile := filepath.Join(tmpDir, "tenants.json")
+	if err := os.WriteFile(tenantsFile, []byte(`{"tenants": [{"name": "docs", "token": "t1"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{
+		Provider:  "say",
+		OutputDir: filepath.Join(tmpDir, "audio"),
+		Server:    config.ServerConfig{TenantsFile: tenantsFile},
+	}
+
+	opts, err := serverOptions(cfg, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("serverOptions() error = %v", err)
+	}
+	if len(opts.Tenants) != 1 {
+		t.Errorf("got %d tenants, want 1", len(opts.Tenants))
+	}
+	if opts.Webhook != nil {
+		t.Error("tenants alone should not enable the webhook")
+	}
+
+	// Enabling the tenant webhook needs a checkout for each tenant
+	cfg.Webhook.Tenants = true
+	if _, err := serverOptions(cfg, logger.NewDefaultLogger()); err == nil {
+		t.Error("serverOptions() should error on -webhook-tenants without a tenant checkout")
+	}
+}
+
 func TestRunEmptyDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,113 @@
+package align
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+// aeneasLanguages maps two-letter language codes to aeneas (ISO 639-3) codes.
+var aeneasLanguages = map[string]string{
+	"en": "eng",
+	"es": "spa",
+	"fr": "fra",
+	"de": "deu",
+	"it": "ita",
+	"pt": "por",
+	"nl": "nld",
+}
+
+// Aeneas aligns audio using the aeneas Python package.
+type Aeneas struct {
+	command  string
+	language string
+}
+
+// Name returns the aligner name.
+func (a *Aeneas) Name() string {
+	return "aeneas"
+}
+
+// Align runs aeneas with one text fragment per word and returns the word timings.
+func (a *Aeneas) Align(ctx context.Context, audioPath, text string) ([]timing.Word, error) {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no text to align")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "md2audio-align-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	textPath := filepath.Join(tmpDir, "text.txt")
+	if err := os.WriteFile(textPath, []byte(strings.Join(words, "\n")), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write alignment text: %w", err)
+	}
+	outPath := filepath.Join(tmpDir, "map.json")
+
+	language := a.language
+	if code, ok := aeneasLanguages[language]; ok {
+		language = code
+	}
+	task := fmt.Sprintf("task_language=%s|is_text_type=plain|os_task_file_format=json", language)
+
+	cmd := exec.CommandContext(ctx, a.command, "-m", "aeneas.tools.execute_task", audioPath, textPath, task, outPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("aeneas failed: %w\nOutput: %s", err, string(output))
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aeneas output: %w", err)
+	}
+	return parseAeneasOutput(data)
+}
+
+// aeneasSyncMap is the JSON sync map written by aeneas.
+type aeneasSyncMap struct {
+	Fragments []struct {
+		Begin string   `json:"begin"`
+		End   string   `json:"end"`
+		Lines []string `json:"lines"`
+	} `json:"fragments"`
+}
+
+// parseAeneasOutput converts an aeneas JSON sync map into word timings.
+func parseAeneasOutput(data []byte) ([]timing.Word, error) {
+	var syncMap aeneasSyncMap
+	if err := json.Unmarshal(data, &syncMap); err != nil {
+		return nil, fmt.Errorf("failed to parse aeneas output: %w", err)
+	}
+
+	words := make([]timing.Word, 0, len(syncMap.Fragments))
+	for _, f := range syncMap.Fragments {
+		begin, err := strconv.ParseFloat(f.Begin, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fragment begin %q: %w", f.Begin, err)
+		}
+		end, err := strconv.ParseFloat(f.End, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fragment end %q: %w", f.End, err)
+		}
+
+		text := strings.TrimSpace(strings.Join(f.Lines, " "))
+		if text == "" {
+			continue
+		}
+		words = append(words, timing.Word{Text: text, Start: begin, End: end})
+	}
+
+	if len(words) == 0 {
+		return nil, fmt.Errorf("aeneas returned no fragments")
+	}
+	return words, nil
+}
@@ -0,0 +1,110 @@
+// Package align provides forced alignment of generated audio against its source text.
+// It wraps external aligners (aeneas, whisper.cpp) executed as subprocesses and
+// converts their output into word-level timings.
+//
+// Key features:
+//   - Aligner interface for exec-based alignment tools
+//   - aeneas word-level alignment (one fragment per word)
+//   - whisper.cpp word-level timestamps (prompted with the source text)
+//   - JSON timing sidecar files next to audio outputs
+package align
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+// Aligner aligns an audio file against the text it was generated from.
+type Aligner interface {
+	// Align returns word timings for text spoken in the audio file.
+	Align(ctx context.Context, audioPath, text string) ([]timing.Word, error)
+
+	// Name returns the aligner name (e.g., "aeneas", "whisper").
+	Name() string
+}
+
+// Config holds configuration for creating an aligner.
+type Config struct {
+	Method   string // Aligner to use: "aeneas" or "whisper"
+	Command  string // Executable override (default: "python3" for aeneas, "whisper-cli" for whisper)
+	Model    string // Model file path (required for whisper)
+	Language string // Language code (default: "en")
+}
+
+// New creates an aligner from configuration.
+func New(cfg Config) (Aligner, error) {
+	language := cfg.Language
+	if language == "" {
+		language = "en"
+	}
+
+	switch cfg.Method {
+	case "aeneas":
+		command := cfg.Command
+		if command == "" {
+			command = "python3"
+		}
+		return &Aeneas{command: command, language: language}, nil
+	case "whisper":
+		command := cfg.Command
+		if command == "" {
+			command = "whisper-cli"
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("whisper aligner requires a model: use -whisper-model flag")
+		}
+		return &Whisper{command: command, model: cfg.Model, language: language}, nil
+	default:
+		return nil, fmt.Errorf("invalid aligner %q: must be 'aeneas' or 'whisper'", cfg.Method)
+	}
+}
+
+// Sidecar is the JSON document stored next to an audio file with its word timings.
+type Sidecar struct {
+	Audio   string        `json:"audio"`   // Audio filename (relative to the sidecar)
+	Aligner string        `json:"aligner"` // Aligner that produced the timings
+	Words   []timing.Word `json:"words"`
+}
+
+// SidecarPath returns the timing sidecar path for an audio file.
+func SidecarPath(audioPath string) string {
+	return strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".timings.json"
+}
+
+// WriteSidecar writes word timings for audioPath into its JSON sidecar file.
+func WriteSidecar(audioPath, aligner string, words []timing.Word) (string, error) {
+	data, err := json.MarshalIndent(Sidecar{
+		Audio:   filepath.Base(audioPath),
+		Aligner: aligner,
+		Words:   words,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal timings: %w", err)
+	}
+
+	path := SidecarPath(audioPath)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write timings sidecar: %w", err)
+	}
+	return path, nil
+}
+
+// ReadSidecar reads the JSON timing sidecar for an audio file.
+func ReadSidecar(audioPath string) (*Sidecar, error) {
+	data, err := os.ReadFile(SidecarPath(audioPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var sidecar Sidecar
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, fmt.Errorf("failed to parse timings sidecar: %w", err)
+	}
+	return &sidecar, nil
+}
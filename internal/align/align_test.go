@@ -0,0 +1,181 @@
+package align
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       Config
+		expectedName string
+		expectError  bool
+		errorMsg     string
+	}{
+		{
+			name:         "aeneas",
+			config:       Config{Method: "aeneas"},
+			expectedName: "aeneas",
+		},
+		{
+			name:         "whisper with model",
+			config:       Config{Method: "whisper", Model: "ggml-base.en.bin"},
+			expectedName: "whisper",
+		},
+		{
+			name:        "whisper without model",
+			config:      Config{Method: "whisper"},
+			expectError: true,
+			errorMsg:    "requires a model",
+		},
+		{
+			name:        "unknown aligner",
+			config:      Config{Method: "gentle"},
+			expectError: true,
+			errorMsg:    "invalid aligner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aligner, err := New(tt.config)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Errorf("Expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if aligner.Name() != tt.expectedName {
+				t.Errorf("Name() = %q, want %q", aligner.Name(), tt.expectedName)
+			}
+		})
+	}
+}
+
+func TestParseAeneasOutput(t *testing.T) {
+	data := []byte(`{"fragments": [
+		{"begin": "0.000", "end": "0.480", "id": "f000001", "lines": ["Hello"]},
+		{"begin": "0.480", "end": "1.120", "id": "f000002", "lines": ["world."]},
+		{"begin": "1.120", "end": "1.200", "id": "f000003", "lines": [""]}
+	]}`)
+
+	words, err := parseAeneasOutput(data)
+	if err != nil {
+		t.Fatalf("parseAeneasOutput() error = %v", err)
+	}
+
+	expected := []timing.Word{
+		{Text: "Hello", Start: 0, End: 0.48},
+		{Text: "world.", Start: 0.48, End: 1.12},
+	}
+	if len(words) != len(expected) {
+		t.Fatalf("Expected %d words, got %d", len(expected), len(words))
+	}
+	for i := range expected {
+		if words[i] != expected[i] {
+			t.Errorf("Word %d = %+v, want %+v", i, words[i], expected[i])
+		}
+	}
+
+	if _, err := parseAeneasOutput([]byte(`{"fragments": []}`)); err == nil {
+		t.Error("Expected error for empty sync map, got nil")
+	}
+	if _, err := parseAeneasOutput([]byte(`{"fragments": [{"begin": "x", "end": "1", "lines": ["a"]}]}`)); err == nil {
+		t.Error("Expected error for invalid begin time, got nil")
+	}
+}
+
+func TestParseWhisperOutput(t *testing.T) {
+	data := []byte(`{"transcription": [
+		{"offsets": {"from": 0, "to": 320}, "text": " Hello"},
+		{"offsets": {"from": 320, "to": 900}, "text": " world."},
+		{"offsets": {"from": 900, "to": 900}, "text": ""}
+	]}`)
+
+	words, err := parseWhisperOutput(data)
+	if err != nil {
+		t.Fatalf("parseWhisperOutput() error = %v", err)
+	}
+
+	expected := []timing.Word{
+		{Text: "Hello", Start: 0, End: 0.32},
+		{Text: "world.", Start: 0.32, End: 0.9},
+	}
+	if len(words) != len(expected) {
+		t.Fatalf("Expected %d words, got %d", len(expected), len(words))
+	}
+	for i := range expected {
+		if words[i] != expected[i] {
+			t.Errorf("Word %d = %+v, want %+v", i, words[i], expected[i])
+		}
+	}
+
+	if _, err := parseWhisperOutput([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON, got nil")
+	}
+}
+
+func TestSidecarRoundTrip(t *testing.T) {
+	audioPath := filepath.Join(t.TempDir(), "section_01_intro.mp3")
+	words := []timing.Word{{Text: "Hello", Start: 0, End: 0.5}}
+
+	path, err := WriteSidecar(audioPath, "aeneas", words)
+	if err != nil {
+		t.Fatalf("WriteSidecar() error = %v", err)
+	}
+	if !strings.HasSuffix(path, "section_01_intro.timings.json") {
+		t.Errorf("Unexpected sidecar path: %s", path)
+	}
+
+	sidecar, err := ReadSidecar(audioPath)
+	if err != nil {
+		t.Fatalf("ReadSidecar() error = %v", err)
+	}
+	if sidecar.Audio != "section_01_intro.mp3" || sidecar.Aligner != "aeneas" {
+		t.Errorf("Unexpected sidecar metadata: %+v", sidecar)
+	}
+	if len(sidecar.Words) != 1 || sidecar.Words[0] != words[0] {
+		t.Errorf("Unexpected sidecar words: %+v", sidecar.Words)
+	}
+}
+
+func TestAeneasAlignWithFakeCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+
+	// Fake aeneas: the sync map path is the last argument
+	script := filepath.Join(t.TempDir(), "fake-aeneas")
+	content := `#!/bin/sh
+for last; do :; done
+cat > "$last" <<'JSON'
+{"fragments": [{"begin": "0.0", "end": "0.4", "lines": ["Hi"]}, {"begin": "0.4", "end": "1.0", "lines": ["there"]}]}
+JSON
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake command: %v", err)
+	}
+
+	aligner, err := New(Config{Method: "aeneas", Command: script})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	words, err := aligner.Align(context.Background(), "audio.wav", "Hi there")
+	if err != nil {
+		t.Fatalf("Align() error = %v", err)
+	}
+	if len(words) != 2 || words[1].Text != "there" || words[1].End != 1.0 {
+		t.Errorf("Unexpected words: %+v", words)
+	}
+}
@@ -0,0 +1,102 @@
+package align
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/timing"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// Whisper aligns audio using whisper.cpp word-level timestamps.
+// whisper.cpp transcribes rather than force-aligns, so the source text is
+// passed as the initial prompt to bias the transcript towards it.
+type Whisper struct {
+	command  string
+	model    string
+	language string
+}
+
+// Name returns the aligner name.
+func (w *Whisper) Name() string {
+	return "whisper"
+}
+
+// Align transcribes the audio with one word per segment and returns the word timings.
+func (w *Whisper) Align(ctx context.Context, audioPath, text string) ([]timing.Word, error) {
+	tmpDir, err := os.MkdirTemp("", "md2audio-align-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// whisper.cpp requires 16kHz mono WAV input
+	wavPath := filepath.Join(tmpDir, "input.wav")
+	if err := utils.ConvertTo16kMonoWAV(ctx, audioPath, wavPath); err != nil {
+		return nil, err
+	}
+
+	outBase := filepath.Join(tmpDir, "transcript")
+	args := []string{
+		"-m", w.model,
+		"-f", wavPath,
+		"-l", w.language,
+		"-ml", "1", // one word per segment
+		"-sow", // split on words rather than tokens
+		"-oj",  // JSON output
+		"-of", outBase,
+		"--prompt", text,
+	}
+	cmd := exec.CommandContext(ctx, w.command, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("whisper failed: %w\nOutput: %s", err, string(output))
+	}
+
+	data, err := os.ReadFile(outBase + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read whisper output: %w", err)
+	}
+	return parseWhisperOutput(data)
+}
+
+// whisperOutput is the JSON transcript written by whisper.cpp (-oj).
+type whisperOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // milliseconds
+			To   int64 `json:"to"`   // milliseconds
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// parseWhisperOutput converts a whisper.cpp JSON transcript into word timings.
+func parseWhisperOutput(data []byte) ([]timing.Word, error) {
+	var out whisperOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse whisper output: %w", err)
+	}
+
+	words := make([]timing.Word, 0, len(out.Transcription))
+	for _, segment := range out.Transcription {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		words = append(words, timing.Word{
+			Text:  text,
+			Start: float64(segment.Offsets.From) / 1000,
+			End:   float64(segment.Offsets.To) / 1000,
+		})
+	}
+
+	if len(words) == 0 {
+		return nil, fmt.Errorf("whisper returned no words")
+	}
+	return words, nil
+}
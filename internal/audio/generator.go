@@ -8,35 +8,95 @@
 //   - Speaking rate calculation
 //   - Multiple output formats (AIFF, M4A, MP3)
 //   - Duration measurement and validation
+//   - SRT subtitles from estimated or force-aligned word timings
+//   - Read-along JSON documents for web audio players
+//   - Round-trip transcription quality checks
+//   - Truncated synthesis detection from the audio duration
+//   - Per-section provider and voice overrides
+//   - Pause directives ([pause 1.5s]) for providers without pause markup
 package audio
 
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/indaco/md2audio/internal/align"
+	"github.com/indaco/md2audio/internal/calibrate"
 	"github.com/indaco/md2audio/internal/logger"
 	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/postprocess"
+	"github.com/indaco/md2audio/internal/readalong"
 	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/timing"
 	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/tts/edge"
+	"github.com/indaco/md2audio/internal/tts/marytts"
+	"github.com/indaco/md2audio/internal/tts/playht"
+	"github.com/indaco/md2audio/internal/tts/watson"
 	"github.com/indaco/md2audio/internal/utils"
+	"github.com/indaco/md2audio/internal/verify"
 )
 
+// elevenLabsNaturalWPM approximates the ElevenLabs speaking rate at speed 1.0
+const elevenLabsNaturalWPM = 150.0
+
 // GeneratorConfig holds configuration for audio generation
 type GeneratorConfig struct {
-	Voice     string
-	Rate      int
-	Format    string
-	Prefix    string
-	OutputDir string
-	Provider  tts.Provider // TTS provider to use
+	Voice        string
+	Rate         int
+	Format       string
+	Prefix       string
+	OutputDir    string
+	Provider     tts.Provider     // TTS provider to use
+	Subtitles    bool             // Write an SRT file next to each generated audio file
+	ReadAlong    bool             // Write a read-along JSON document next to each generated audio file
+	TimingMethod timing.Method    // Word timing estimation method for subtitles (default: syllable)
+	Aligner      align.Aligner    // Optional forced aligner for accurate word timings
+	SSML         bool             // Section text contains SSML markup for providers that support it
+	Stitch       bool             // Send the text of adjacent sections with each request (see SetSections)
+	RateCurve    calibrate.Curve  // Measured speaking rates of the voice (calibrated or learned), for timed rates and estimates
+	Learner      RateLearner      // Records the measured speaking rates of generated sections (optional)
+	Resolve      ProviderResolver // Provider of sections with a provider override (nil = overrides ignored)
+
+	// Hooks run in order on each generated section file before it is measured and verified
+	PostProcessors []postprocess.Func
+
+	// File naming
+	SlugStyle      string // Title slug style: text.SlugASCII (default), text.SlugUnicode, or text.SlugHash
+	MaxFilenameLen int    // Maximum file name length without extension, in characters (0 = title slugs capped at 50)
+	StartIndex     int    // Number of the first section in file names (0 = 1)
+
+	// Round-trip transcription check (disabled when Verifier is nil)
+	Verifier        verify.Transcriber
+	VerifyThreshold float64 // Maximum word error rate before flagging (default: verify.DefaultThreshold)
+
+	// Truncated synthesis check (disabled when MinDurationRatio is 0)
+	MinDurationRatio float64 // Flag sections whose measured audio is shorter than this fraction of the estimate
+	RetryShort       bool    // Regenerate a too-short section once before flagging it
+}
+
+// RateLearner records the speaking rate measured for a generated section at
+// the requested rate (0 for providers without rate control).
+type RateLearner interface {
+	Observe(ctx context.Context, rate int, wpm float64) error
 }
 
+// ProviderResolver returns the provider named in a section's provider override
+// and the voice to use when the section does not name one.
+type ProviderResolver func(name string) (tts.Provider, string, error)
+
 // Generator handles audio file generation
 type Generator struct {
 	config GeneratorConfig
 	log    logger.LoggerInterface
+	names  map[string]string // Output base paths claimed by section (see OutputBase)
+
+	sections []parser.Section // Sections of the document in order, for stitching (see SetSections)
 }
 
 // NewGenerator creates a new audio generator
@@ -44,7 +104,62 @@ func NewGenerator(config GeneratorConfig, log logger.LoggerInterface) *Generator
 	return &Generator{
 		config: config,
 		log:    log,
+		names:  make(map[string]string),
+	}
+}
+
+// SetSections records the sections of the document being generated, in
+// order. With Stitch enabled, requests then carry the text of the sections
+// before and after them, so providers such as ElevenLabs keep the prosody
+// continuous across section boundaries.
+func (g *Generator) SetSections(sections []parser.Section) {
+	g.sections = sections
+}
+
+// neighbors returns the text of the sections before and after section in the
+// sections set with SetSections
+func (g *Generator) neighbors(section parser.Section) (previous, next string) {
+	for i, s := range g.sections {
+		if s.Index != section.Index || s.Sentence != section.Sentence {
+			continue
+		}
+		if i > 0 {
+			previous = g.sections[i-1].Content
+		}
+		if i < len(g.sections)-1 {
+			next = g.sections[i+1].Content
+		}
+		break
+	}
+	return previous, next
+}
+
+// forSection returns the generator for a section, which differs from g when
+// the section overrides the provider or voice. The returned generator shares
+// the output names claimed by g.
+func (g *Generator) forSection(section parser.Section) (*Generator, error) {
+	if section.Provider == "" && section.Voice == "" {
+		return g, nil
+	}
+
+	sg := *g
+	if section.Provider != "" && g.config.Resolve != nil && (g.config.Provider == nil || section.Provider != g.config.Provider.Name()) {
+		provider, voice, err := g.config.Resolve(section.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("error creating TTS provider %s: %w", section.Provider, err)
+		}
+		sg.config.Provider = provider
+		sg.config.Voice = voice
+	}
+	if section.Voice != "" {
+		sg.config.Voice = section.Voice
+	}
+	if sg.config.Provider != g.config.Provider || sg.config.Voice != g.config.Voice {
+		// Measured speaking rates belong to the configured voice
+		sg.config.RateCurve = nil
+		sg.config.Learner = nil
 	}
+	return &sg, nil
 }
 
 // ListAvailableVoices lists all available macOS voices
@@ -59,36 +174,433 @@ func ListAvailableVoices() error {
 	return nil
 }
 
+// Result describes the outcome of generating a section
+type Result struct {
+	OutputPath string  // Path of the generated audio file
+	Duration   float64 // Audio duration in seconds (measured when possible, otherwise estimated)
+	Flagged    bool    // Whether a quality check flagged the generated audio
+	FlagReason string  // Why the section was flagged (empty if not flagged)
+}
+
 // Generate generates an audio file for a section
 func (g *Generator) Generate(section parser.Section, index int) error {
+	_, err := g.GenerateSection(section, index)
+	return err
+}
+
+// GenerateSection generates an audio file for a section and returns its result
+func (g *Generator) GenerateSection(section parser.Section, index int) (Result, error) {
+	return g.GenerateSectionAs(section, g.OutputBase(section, index))
+}
+
+// OutputBase returns the output path of a section without file extension.
+// Sentences of a section (-granularity sentence) get a "_sNN" suffix. When
+// MaxFilenameLen truncates names so that two sections would share a path,
+// later sections get a "_2", "_3", ... suffix. Tracks of a chapter
+// (-chapters) are written to a "CC_<chapter>" subdirectory and numbered by
+// chapter and track, e.g. "01_getting_started/section_01_02_install".
+func (g *Generator) OutputBase(section parser.Section, index int) string {
+	dir := g.config.OutputDir
+	head := fmt.Sprintf("%s_%02d_", g.config.Prefix, g.OutputNumber(index))
+	if section.Chapter > 0 {
+		dir = filepath.Join(dir, g.chapterDir(section))
+		head = fmt.Sprintf("%s_%02d_%02d_", g.config.Prefix, section.Chapter, section.Track)
+	}
+	tail := ""
+	if section.Sentence > 0 {
+		tail = fmt.Sprintf("_s%02d", section.Sentence)
+	}
+
+	opts := text.SlugOptions{Style: g.config.SlugStyle}
+	maxLen := g.config.MaxFilenameLen
+	if maxLen > 0 {
+		opts.MaxLen = max(maxLen-utf8.RuneCountInString(head+tail), 1)
+	}
+	name := head + text.Slug(section.Title, opts) + tail
+	if maxLen > 0 {
+		name = truncateName(name, maxLen)
+	}
+
+	return filepath.Join(dir, g.claimName(name, fmt.Sprintf("%d/%d", index, section.Sentence)))
+}
+
+// chapterDir returns the name of the subdirectory of a chapter's tracks
+func (g *Generator) chapterDir(section parser.Section) string {
+	head := fmt.Sprintf("%02d_", section.Chapter)
+	opts := text.SlugOptions{Style: g.config.SlugStyle}
+	if g.config.MaxFilenameLen > 0 {
+		opts.MaxLen = max(g.config.MaxFilenameLen-len(head), 1)
+	}
+	return head + text.Slug(section.ChapterTitle, opts)
+}
+
+// OutputNumber returns the number of the section at index in file names,
+// shifted by StartIndex.
+func (g *Generator) OutputNumber(index int) int {
+	if g.config.StartIndex > 1 {
+		return index + g.config.StartIndex - 1
+	}
+	return index
+}
+
+// claimName returns name for the section identified by key, adding a numeric
+// suffix if another section already uses it. Repeated calls for the same
+// section return the same name.
+func (g *Generator) claimName(name, key string) string {
+	if g.names == nil {
+		g.names = make(map[string]string)
+	}
+	candidate := name
+	for n := 2; ; n++ {
+		owner, taken := g.names[candidate]
+		if !taken || owner == key {
+			g.names[candidate] = key
+			return candidate
+		}
+		suffix := fmt.Sprintf("_%d", n)
+		if g.config.MaxFilenameLen > 0 {
+			candidate = truncateName(name, g.config.MaxFilenameLen-len(suffix)) + suffix
+		} else {
+			candidate = name + suffix
+		}
+	}
+}
+
+// truncateName returns at most n characters of name
+func truncateName(name string, n int) string {
+	runes := []rune(name)
+	if len(runes) <= max(n, 0) {
+		return name
+	}
+	return string(runes[:max(n, 0)])
+}
+
+// GenerateSectionAs generates an audio file for a section at basePath
+// (an output path without extension) and returns its result.
+// The extension is chosen from the configured format and provider.
+func (g *Generator) GenerateSectionAs(section parser.Section, basePath string) (Result, error) {
+	g, err := g.forSection(section)
+	if err != nil {
+		return Result{}, err
+	}
 	if g.config.Provider == nil {
-		return fmt.Errorf("no TTS provider configured")
+		return Result{}, fmt.Errorf("no TTS provider configured")
 	}
 
-	safeTitle := text.SanitizeFilename(section.Title)
+	// Tracks of a chapter are written to its subdirectory
+	if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+		return Result{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
 
-	// Build output path based on format
-	var outputPath string
-	fileExt := g.config.Format
+	request, speakingRate := g.buildRequest(section, basePath)
 
-	// For say provider with m4a, we need to use .aiff initially
-	// For elevenlabs, use the format directly (it outputs mp3)
-	if g.config.Provider.Name() == "say" {
-		if g.config.Format == "m4a" {
-			fileExt = "aiff" // say provider will convert after generation
+	// Generate audio using TTS provider
+	ctx := context.Background()
+	finalPath, err := g.generate(ctx, section, request)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{OutputPath: finalPath, Duration: g.audioDuration(section, finalPath, speakingRate)}
+
+	// Catch silently truncated synthesis, optionally regenerating once
+	if reason := g.checkMinDuration(section, finalPath, speakingRate); reason != "" {
+		if g.config.RetryShort {
+			g.log.Warning(fmt.Sprintf("%s, regenerating once", reason))
+			if finalPath, err = g.generate(ctx, section, request); err != nil {
+				return Result{}, err
+			}
+			result = Result{OutputPath: finalPath, Duration: g.audioDuration(section, finalPath, speakingRate)}
+			reason = g.checkMinDuration(section, finalPath, speakingRate)
+		}
+		if reason != "" {
+			result.Flagged = true
+			result.FlagReason = reason
+			g.log.Warning(fmt.Sprintf("Flagged: %s", reason))
+		}
+	}
+	g.WriteTimings(section, result)
+	if !result.Flagged {
+		g.learnRate(ctx, section, finalPath, speakingRate)
+	}
+
+	// Show timing info if applicable
+	if section.HasTiming {
+		// Try to get actual duration (provider-dependent)
+		if g.config.Provider.Name() == "say" {
+			if actualDuration, err := utils.GetAudioDuration(finalPath); err == nil {
+				diff := actualDuration - section.Duration
+				g.log.WithIndent(true)
+				g.log.Hint(fmt.Sprintf("target: %.1fs, diff: %+.2fs", section.Duration, diff))
+				g.log.WithIndent(false)
+			}
+		}
+	}
+
+	// Verify the audio by transcribing it back to text
+	if g.config.Verifier != nil {
+		check, err := verify.Check(ctx, g.config.Verifier, finalPath, text.SpokenText(section.Content), g.config.VerifyThreshold)
+		switch {
+		case err != nil:
+			g.log.Warning(fmt.Sprintf("Could not verify audio: %v", err))
+		case check.Flagged:
+			result.Flagged = true
+			result.FlagReason = joinReasons(result.FlagReason, check.Reason())
+			g.log.Warning(fmt.Sprintf("Flagged by verification: %s", result.FlagReason))
+		default:
+			g.log.Faint(fmt.Sprintf("Verified: word error rate %.0f%%", check.WordErrorRate*100))
+		}
+	}
+
+	return result, nil
+}
+
+// generate synthesizes a section with the provider and runs the post-processors on the file
+func (g *Generator) generate(ctx context.Context, section parser.Section, request tts.GenerateRequest) (string, error) {
+	finalPath, err := g.synthesize(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("error generating audio: %w", err)
+	}
+	err = postprocess.Apply(ctx, postprocess.File{
+		Path:     finalPath,
+		Title:    section.Title,
+		Index:    section.Index,
+		Text:     section.Content,
+		Provider: g.config.Provider.Name(),
+		Voice:    g.config.Voice,
+	}, g.config.PostProcessors)
+	if err != nil {
+		return "", fmt.Errorf("error post-processing audio: %w", err)
+	}
+	return finalPath, nil
+}
+
+// synthesize generates the audio of a request with the provider. The pause
+// directives of providers that cannot pause on their own become silence
+// joined between the text around them.
+func (g *Generator) synthesize(ctx context.Context, request tts.GenerateRequest) (string, error) {
+	if g.handlesPauses() || text.PauseDuration(request.Text) == 0 {
+		return g.config.Provider.Generate(ctx, request)
+	}
+
+	ext := filepath.Ext(request.OutputPath)
+	base := strings.TrimSuffix(request.OutputPath, ext)
+	// Each part of a timed request gets its share of the time left for speech
+	var speech float64
+	if request.TargetDuration != nil {
+		_, speech = tts.SpeechTarget(request)
+	}
+	words := utils.CountWords(text.SpokenText(request.Text))
+
+	var parts []utils.AudioPart
+	var paths []string
+	defer func() {
+		for _, path := range paths {
+			_ = os.Remove(path)
+		}
+	}()
+	for i, segment := range text.SplitPauses(request.Text) {
+		if segment.Text != "" {
+			part := request
+			part.Text = segment.Text
+			part.OutputPath = fmt.Sprintf("%s.part%02d%s", base, i+1, ext)
+			if request.TargetDuration != nil && words > 0 {
+				share := speech * float64(utils.CountWords(segment.Text)) / float64(words)
+				part.TargetDuration = &share
+			}
+			path, err := g.config.Provider.Generate(ctx, part)
+			if err != nil {
+				return "", err
+			}
+			paths = append(paths, path)
+			parts = append(parts, utils.AudioPart{Path: path})
+		}
+		if segment.Pause > 0 {
+			parts = append(parts, utils.AudioPart{Silence: segment.Pause.Seconds()})
+		}
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no text to generate audio from")
+	}
+
+	// Providers may convert their output, so the parts give the extension
+	finalPath := base + filepath.Ext(paths[0])
+	if err := utils.JoinAudio(ctx, finalPath, parts); err != nil {
+		return "", fmt.Errorf("error joining pauses: %w", err)
+	}
+	return finalPath, nil
+}
+
+// handlesPauses reports whether the provider turns pause directives into
+// silence itself: say with silence commands, cloud providers with SSML breaks
+func (g *Generator) handlesPauses() bool {
+	switch g.config.Provider.Name() {
+	case "say", "elevenlabs", "edge", "watson", "silence":
+		return true
+	case "marytts":
+		return g.config.SSML
+	default:
+		return false
+	}
+}
+
+// checkMinDuration returns why the measured audio of a section is implausibly
+// short for its text (less than MinDurationRatio of the estimate), or an empty
+// string if it is long enough or cannot be measured
+func (g *Generator) checkMinDuration(section parser.Section, audioPath string, speakingRate int) string {
+	if g.config.MinDurationRatio <= 0 {
+		return ""
+	}
+	duration, err := utils.GetAudioDuration(audioPath)
+	if err != nil {
+		return ""
+	}
+	estimate := g.estimateDuration(section, speakingRate)
+	if estimate <= 0 || duration >= estimate*g.config.MinDurationRatio {
+		return ""
+	}
+	return fmt.Sprintf("audio is %.1fs, only %.0f%% of the %.1fs expected for %d words (possibly truncated)",
+		duration, duration/estimate*100, estimate, utils.CountWords(text.SpokenText(section.Content)))
+}
+
+// learnRate records the measured speaking rate of a section's audio with the
+// configured learner. Providers without rate control are only learned from
+// untimed sections, which are spoken at their natural speed.
+func (g *Generator) learnRate(ctx context.Context, section parser.Section, audioPath string, speakingRate int) {
+	words := utils.CountWords(text.SpokenText(section.Content))
+	if g.config.Learner == nil || words < calibrate.MinProfileWords {
+		return
+	}
+	rate := 0
+	if g.hasRateControl() {
+		// Nearby rates are learned together
+		rate = (speakingRate + 5) / 10 * 10
+	} else if section.HasTiming {
+		return
+	}
+
+	duration, err := utils.GetAudioDuration(audioPath)
+	if err != nil {
+		return
+	}
+	// Pauses are not speech
+	duration -= text.PauseDuration(section.Content).Seconds()
+	if duration <= 0 {
+		return
+	}
+	if err := g.config.Learner.Observe(ctx, rate, utils.CalculateWPM(words, duration)); err != nil {
+		g.log.Debug(fmt.Sprintf("Could not record the speaking rate: %v", err))
+	}
+}
+
+// hasRateControl reports whether the provider speaks at the requested rate
+func (g *Generator) hasRateControl() bool {
+	name := g.config.Provider.Name()
+	return name == "say" || name == "espeak" || name == "festival"
+}
+
+// joinReasons joins non-empty flag reasons
+func joinReasons(reasons ...string) string {
+	var nonEmpty []string
+	for _, reason := range reasons {
+		if reason != "" {
+			nonEmpty = append(nonEmpty, reason)
 		}
-	} else if g.config.Provider.Name() == "elevenlabs" {
-		fileExt = "mp3" // ElevenLabs outputs MP3
 	}
+	return strings.Join(nonEmpty, "; ")
+}
+
+// WriteTimings aligns (or estimates) the word timings of a section's audio and
+// writes the configured timing outputs: the alignment sidecar, SRT subtitles,
+// and the read-along document.
+func (g *Generator) WriteTimings(section parser.Section, result Result) {
+	if g.config.Aligner == nil && !g.config.Subtitles && !g.config.ReadAlong {
+		return
+	}
+	// Pause directives and SSML tags are not spoken
+	section.Content = text.SpokenText(section.Content)
 
-	outputPath = filepath.Join(g.config.OutputDir, fmt.Sprintf("%s_%02d_%s.%s", g.config.Prefix, index, safeTitle, fileExt))
+	words, source := g.wordTimings(context.Background(), section, result.OutputPath, result.Duration)
+	if g.config.Subtitles {
+		if err := g.writeSubtitles(words, result.OutputPath); err != nil {
+			g.log.Warning(fmt.Sprintf("Could not write subtitles: %v", err))
+		}
+	}
+	if g.config.ReadAlong {
+		doc := readalong.Build(section.Title, section.Content, result.OutputPath, result.Duration, source, words)
+		if path, err := readalong.Write(doc, result.OutputPath); err != nil {
+			g.log.Warning(fmt.Sprintf("Could not write read-along document: %v", err))
+		} else {
+			g.log.Faint(fmt.Sprintf("Read-along: %s", path))
+		}
+	}
+}
+
+// Synthesize generates audio for text alone at basePath (an output path without
+// extension), without timing control, subtitles, or verification, and returns
+// the output path. It is used to patch parts of existing section audio.
+func (g *Generator) Synthesize(text, basePath string) (string, error) {
+	if g.config.Provider == nil {
+		return "", fmt.Errorf("no TTS provider configured")
+	}
+
+	request, _ := g.buildRequest(parser.Section{Content: text}, basePath)
+	path, err := g.synthesize(context.Background(), request)
+	if err != nil {
+		return "", fmt.Errorf("error generating audio: %w", err)
+	}
+	return path, nil
+}
+
+// PreviewSection returns the provider request that GenerateSection would make,
+// for providers implementing tts.RequestPreviewer.
+func (g *Generator) PreviewSection(section parser.Section, index int) (tts.RequestPreview, error) {
+	g, err := g.forSection(section)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+	if g.config.Provider == nil {
+		return tts.RequestPreview{}, fmt.Errorf("no TTS provider configured")
+	}
+
+	previewer, ok := g.config.Provider.(tts.RequestPreviewer)
+	if !ok {
+		return tts.RequestPreview{}, fmt.Errorf("provider %s does not support request previews", g.config.Provider.Name())
+	}
+
+	request, _ := g.buildRequest(section, g.OutputBase(section, index))
+	return previewer.PreviewRequest(request)
+}
+
+// SpeakSection plays a section through the speakers with the voice and rate
+// GenerateSection would use, for providers implementing tts.Speaker.
+func (g *Generator) SpeakSection(ctx context.Context, section parser.Section, index int) error {
+	g, err := g.forSection(section)
+	if err != nil {
+		return err
+	}
+	if g.config.Provider == nil {
+		return fmt.Errorf("no TTS provider configured")
+	}
+
+	speaker, ok := g.config.Provider.(tts.Speaker)
+	if !ok {
+		return fmt.Errorf("provider %s cannot play audio aloud", g.config.Provider.Name())
+	}
+
+	request, _ := g.buildRequest(section, g.OutputBase(section, index))
+	return speaker.Speak(ctx, request)
+}
 
+// buildRequest builds the TTS request for a section written to basePath,
+// returning it with the speaking rate used
+func (g *Generator) buildRequest(section parser.Section, basePath string) (tts.GenerateRequest, int) {
 	// Determine speaking rate (only used by say provider)
 	speakingRate := g.config.Rate
 	var targetDuration *float64
 	if section.HasTiming {
-		// Calculate required rate to fit the duration (for say provider)
-		estimatedRate := estimateSpeakingRate(section.Content, section.Duration, g.log)
+		// Calculate required rate to fit the duration (for say provider), less the pauses
+		speech := section.Duration - text.PauseDuration(section.Content).Seconds()
+		estimatedRate := estimateSpeakingRate(text.SpokenText(section.Content), speech, g.config.RateCurve, g.log)
 		speakingRate = estimatedRate
 		g.log.Faint(fmt.Sprintf("Target duration: %.1fs, Calculated rate: %d wpm", section.Duration, speakingRate))
 
@@ -96,41 +608,174 @@ func (g *Generator) Generate(section parser.Section, index int) error {
 		targetDuration = &section.Duration
 	}
 
-	// Build TTS request
 	request := tts.GenerateRequest{
-		Text:           section.Content,
+		Text:           g.requestText(section.Content),
 		Voice:          g.config.Voice,
-		OutputPath:     outputPath,
+		OutputPath:     basePath + "." + g.fileExt(),
 		Rate:           &speakingRate,
 		Format:         g.config.Format,
 		TargetDuration: targetDuration,
+		SSML:           g.config.SSML,
 	}
+	if g.config.Stitch {
+		request.PreviousText, request.NextText = g.neighbors(section)
+	}
+	return request, speakingRate
+}
 
-	// Generate audio using TTS provider
-	ctx := context.Background()
-	finalPath, err := g.config.Provider.Generate(ctx, request)
-	if err != nil {
-		return fmt.Errorf("error generating audio: %w", err)
+// requestText returns the text of a section for the provider: SSML blocks are
+// sent as written to providers speaking SSML, and as the text they speak to
+// the others
+func (g *Generator) requestText(content string) string {
+	switch g.config.Provider.Name() {
+	case "edge", "watson":
+		return content
+	case "espeak", "marytts":
+		if g.config.SSML {
+			return text.UnwrapSSML(content)
+		}
 	}
+	return text.StripSSML(content)
+}
 
-	// Show timing info if applicable
-	if section.HasTiming {
-		// Try to get actual duration (provider-dependent)
-		if g.config.Provider.Name() == "say" {
-			if actualDuration, err := utils.GetAudioDuration(finalPath); err == nil {
-				diff := actualDuration - section.Duration
-				g.log.WithIndent(true)
-				g.log.Hint(fmt.Sprintf("target: %.1fs, diff: %+.2fs", section.Duration, diff))
-				g.log.WithIndent(false)
+// fileExt returns the extension of the file requested from the provider
+func (g *Generator) fileExt() string {
+	// For say provider with m4a, we need to use .aiff initially
+	// For elevenlabs, use the format directly (it outputs mp3)
+	switch {
+	case g.config.Provider.Name() == "say" && g.config.Format == "m4a":
+		return "aiff" // say provider will convert after generation
+	case g.config.Provider.Name() == "elevenlabs" && g.config.Format != "wav":
+		return "mp3" // ElevenLabs outputs MP3 unless lossless WAV is requested
+	case g.config.Provider.Name() == "edge" && g.config.Format != "wav":
+		return "mp3" // Edge outputs MP3 unless lossless WAV is requested
+	case g.config.Provider.Name() == "watson" && g.config.Format != "wav" && g.config.Format != "ogg":
+		return "mp3" // Watson outputs MP3 unless WAV or Ogg Opus is requested
+	case g.config.Provider.Name() == "playht" && g.config.Format != "wav" && g.config.Format != "ogg":
+		return "mp3" // Play.ht outputs MP3 unless WAV or Ogg is requested
+	default:
+		return g.config.Format
+	}
+}
+
+// wordTimings returns word timings for a generated file and their source. Forced
+// alignment is used when an aligner is configured (and its result stored in a JSON
+// sidecar); otherwise, or if alignment fails, timings are estimated from the audio
+// duration.
+func (g *Generator) wordTimings(ctx context.Context, section parser.Section, audioPath string, duration float64) ([]timing.Word, string) {
+	if g.config.Aligner != nil {
+		words, err := g.config.Aligner.Align(ctx, audioPath, section.Content)
+		if err == nil {
+			if sidecarPath, err := align.WriteSidecar(audioPath, g.config.Aligner.Name(), words); err != nil {
+				g.log.Warning(fmt.Sprintf("Could not write timings: %v", err))
+			} else {
+				g.log.Faint(fmt.Sprintf("Timings: %s", sidecarPath))
 			}
+			return words, g.config.Aligner.Name()
 		}
+		g.log.Warning(fmt.Sprintf("Alignment with %s failed, falling back to estimated timings: %v", g.config.Aligner.Name(), err))
 	}
 
-	return nil
+	return g.estimateWordTimings(section, duration), readalong.Estimated
+}
+
+// estimateWordTimings estimates word timings from the audio duration.
+func (g *Generator) estimateWordTimings(section parser.Section, duration float64) []timing.Word {
+	method := g.config.TimingMethod
+	if method == "" {
+		method = timing.MethodSyllable
+	}
+
+	return timing.EstimateWords(section.Content, duration, method)
+}
+
+// audioDuration returns the duration of a generated file in seconds.
+// The measured audio duration is preferred; otherwise the target duration or an
+// estimate at the speaking rate is used.
+func (g *Generator) audioDuration(section parser.Section, audioPath string, speakingRate int) float64 {
+	duration, err := utils.GetAudioDuration(audioPath)
+	if err == nil {
+		return duration
+	}
+
+	duration = g.estimateDuration(section, speakingRate)
+	g.log.Debug(fmt.Sprintf("Using estimated duration %.2fs: %v", duration, err))
+	return duration
+}
+
+// EstimateDuration returns the target duration of a section, or an estimate of
+// its spoken duration at the configured speaking rate.
+func (g *Generator) EstimateDuration(section parser.Section) float64 {
+	if section.Provider != "" && (g.config.Provider == nil || section.Provider != g.config.Provider.Name()) {
+		return EstimateSectionDuration(section, section.Provider, g.config.Rate)
+	}
+	return g.estimateDuration(section, g.config.Rate)
+}
+
+// estimateDuration returns the target duration of a section, or an estimate
+// of its spoken duration at the speaking rate.
+func (g *Generator) estimateDuration(section parser.Section, speakingRate int) float64 {
+	provider := ""
+	if g.config.Provider != nil {
+		provider = g.config.Provider.Name()
+	}
+	// Measured speaking rates of the voice beat the nominal ones
+	if !section.HasTiming {
+		if wpm, ok := g.config.RateCurve.WPMAt(speakingRate); ok {
+			return utils.EstimateDuration(text.SpokenText(section.Content), wpm) + text.PauseDuration(section.Content).Seconds()
+		}
+	}
+	return EstimateSectionDuration(section, provider, speakingRate)
 }
 
-// estimateSpeakingRate calculates the words per minute needed to fit target duration
-func estimateSpeakingRate(textContent string, targetDuration float64, log logger.LoggerInterface) int {
+// EstimateSectionDuration returns the target duration of a section, or an
+// estimate of its spoken duration and pauses with the named provider at the
+// speaking rate.
+func EstimateSectionDuration(section parser.Section, provider string, speakingRate int) float64 {
+	if section.HasTiming {
+		return section.Duration
+	}
+
+	wpm := float64(speakingRate)
+	switch provider {
+	case "elevenlabs":
+		wpm = elevenLabsNaturalWPM
+	case "edge":
+		wpm = edge.NaturalWPM
+	case "watson":
+		wpm = watson.NaturalWPM
+	case "marytts":
+		wpm = marytts.NaturalWPM
+	case "playht":
+		wpm = playht.NaturalWPM
+	}
+	return utils.EstimateDuration(text.SpokenText(section.Content), wpm) + text.PauseDuration(section.Content).Seconds()
+}
+
+// writeSubtitles writes an SRT file next to audioPath from word timings.
+func (g *Generator) writeSubtitles(words []timing.Word, audioPath string) error {
+	if len(words) == 0 {
+		return fmt.Errorf("no words to time")
+	}
+
+	srtPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".srt"
+	f, err := os.Create(srtPath)
+	if err != nil {
+		return fmt.Errorf("failed to create subtitle file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := timing.WriteSRT(f, timing.BuildCues(words, timing.DefaultMaxCueWords)); err != nil {
+		return err
+	}
+
+	g.log.Faint(fmt.Sprintf("Subtitles: %s", srtPath))
+	return f.Close()
+}
+
+// estimateSpeakingRate calculates the words per minute needed to fit target duration.
+// A calibration curve of the voice replaces the empirical adjustment when available.
+func estimateSpeakingRate(textContent string, targetDuration float64, curve calibrate.Curve, log logger.LoggerInterface) int {
 	const (
 		minWPM           = 90
 		maxWPM           = 360
@@ -147,6 +792,9 @@ func estimateSpeakingRate(textContent string, targetDuration float64, log logger
 
 	// Add a small adjustment factor (say command seems to be slightly faster in practice)
 	adjustedWPM := requiredWPM * adjustmentFactor
+	if rate, ok := curve.RateFor(requiredWPM); ok {
+		adjustedWPM = rate
+	}
 
 	// Clamp to reasonable values (say supports roughly 90-360 wpm)
 	if adjustedWPM > maxWPM {
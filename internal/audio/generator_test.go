@@ -2,15 +2,28 @@ package audio
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/indaco/md2audio/internal/align"
+	"github.com/indaco/md2audio/internal/calibrate"
 	"github.com/indaco/md2audio/internal/logger"
 	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/postprocess"
+	"github.com/indaco/md2audio/internal/readalong"
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/timing"
 	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
 )
 
 func TestEstimateSpeakingRate(t *testing.T) {
@@ -75,7 +88,7 @@ func TestEstimateSpeakingRate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			log := logger.NewDefaultLogger()
-			result := estimateSpeakingRate(tt.text, tt.targetDuration, log)
+			result := estimateSpeakingRate(tt.text, tt.targetDuration, nil, log)
 
 			// Allow small variance due to rounding and 0.95 adjustment factor
 			tolerance := 5
@@ -119,7 +132,7 @@ func TestEstimateSpeakingRateBoundaries(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			log := logger.NewDefaultLogger()
-			result := estimateSpeakingRate(tt.text, tt.duration, log)
+			result := estimateSpeakingRate(tt.text, tt.duration, nil, log)
 			if result < tt.minRate || result > tt.maxRate {
 				t.Errorf("Expected rate between %d and %d, got %d", tt.minRate, tt.maxRate, result)
 			}
@@ -257,7 +270,7 @@ func TestEstimateSpeakingRateRealistic(t *testing.T) {
 	targetDuration := 8.0 // From "SCENE 1: Hero Section (8s)"
 
 	log := logger.NewDefaultLogger()
-	rate := estimateSpeakingRate(text, targetDuration, log)
+	rate := estimateSpeakingRate(text, targetDuration, nil, log)
 
 	// Should be somewhere in the reasonable range
 	if rate < 150 || rate > 300 {
@@ -265,6 +278,20 @@ func TestEstimateSpeakingRateRealistic(t *testing.T) {
 	}
 }
 
+func TestEstimateSpeakingRateCalibrated(t *testing.T) {
+	// 30 words in 10s need 180 wpm; this voice speaks 20% faster than requested
+	text := repeat("word ", 30)
+	curve := calibrate.Curve{{Rate: 100, WPM: 120}, {Rate: 200, WPM: 240}}
+
+	log := logger.NewDefaultLogger()
+	if rate := estimateSpeakingRate(text, 10, curve, log); rate != 150 {
+		t.Errorf("estimateSpeakingRate() with calibration = %d, want 150", rate)
+	}
+	if rate := estimateSpeakingRate(text, 10, nil, log); rate != 171 {
+		t.Errorf("estimateSpeakingRate() without calibration = %d, want 171", rate)
+	}
+}
+
 // Helper function to repeat a string
 func repeat(s string, count int) string {
 	result := ""
@@ -512,3 +539,818 @@ func TestGenerateWithProviderError(t *testing.T) {
 		t.Errorf("Expected 'error generating audio' in error message, got: %v", err)
 	}
 }
+
+// TestGenerateLosslessElevenLabsPath tests that WAV output keeps the .wav extension for ElevenLabs
+func TestGenerateLosslessElevenLabsPath(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	var requested tts.GenerateRequest
+	mockProvider := &recordingProvider{name: "elevenlabs", record: &requested}
+
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Rachel",
+		Format:    "wav",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  mockProvider,
+	}, log)
+
+	if err := gen.Generate(parser.Section{Title: "Intro", Content: "Hello"}, 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if !strings.HasSuffix(requested.OutputPath, ".wav") {
+		t.Errorf("Expected .wav output path, got %s", requested.OutputPath)
+	}
+	if requested.Format != "wav" {
+		t.Errorf("Expected wav format in request, got %s", requested.Format)
+	}
+}
+
+// TestGenerateSectionAs tests that sections can be regenerated at a given path
+func TestGenerateSectionAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		format   string
+		wantExt  string
+	}{
+		{name: "say aiff", provider: "say", format: "aiff", wantExt: ".aiff"},
+		{name: "say m4a converts from aiff", provider: "say", format: "m4a", wantExt: ".aiff"},
+		{name: "elevenlabs mp3", provider: "elevenlabs", format: "aiff", wantExt: ".mp3"},
+		{name: "elevenlabs lossless", provider: "elevenlabs", format: "wav", wantExt: ".wav"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.NewDefaultLogger()
+			outputDir := t.TempDir()
+
+			var requested tts.GenerateRequest
+			gen := NewGenerator(GeneratorConfig{
+				Format:    tt.format,
+				Prefix:    "test",
+				OutputDir: outputDir,
+				Provider:  &recordingProvider{name: tt.provider, record: &requested},
+			}, log)
+
+			section := parser.Section{Title: "Getting Started", Content: "Hello"}
+			base := gen.OutputBase(section, 3)
+			if want := filepath.Join(outputDir, "test_03_getting_started"); base != want {
+				t.Errorf("OutputBase() = %q, want %q", base, want)
+			}
+			sentence := parser.Section{Title: "Getting Started", Content: "Hello", Sentence: 2}
+			if want := filepath.Join(outputDir, "test_03_getting_started_s02"); gen.OutputBase(sentence, 3) != want {
+				t.Errorf("OutputBase() = %q, want %q", gen.OutputBase(sentence, 3), want)
+			}
+
+			custom := filepath.Join(outputDir, "original_name")
+			if _, err := gen.GenerateSectionAs(section, custom); err != nil {
+				t.Fatalf("GenerateSectionAs() error = %v", err)
+			}
+			if want := custom + tt.wantExt; requested.OutputPath != want {
+				t.Errorf("Requested output path = %q, want %q", requested.OutputPath, want)
+			}
+		})
+	}
+}
+
+// TestGenerateSectionOverrides tests that sections can override the provider and voice
+func TestGenerateSectionOverrides(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	var sayRequest, cloudRequest tts.GenerateRequest
+	resolved := 0
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Kate",
+		Format:    "aiff",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &recordingProvider{name: "say", record: &sayRequest},
+		Resolve: func(name string) (tts.Provider, string, error) {
+			resolved++
+			if name != "elevenlabs" {
+				return nil, "", fmt.Errorf("unknown provider %q", name)
+			}
+			return &recordingProvider{name: name, record: &cloudRequest}, "Rachel", nil
+		},
+	}, log)
+
+	if _, err := gen.GenerateSection(parser.Section{Title: "Demo", Content: "Hello", Provider: "elevenlabs"}, 1); err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	if cloudRequest.Voice != "Rachel" || !strings.HasSuffix(cloudRequest.OutputPath, ".mp3") {
+		t.Errorf("overridden request = %+v, want the resolved voice and provider extension", cloudRequest)
+	}
+
+	if _, err := gen.GenerateSection(parser.Section{Title: "Intro", Content: "Hi", Provider: "say", Voice: "Alex"}, 2); err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	if sayRequest.Voice != "Alex" || resolved != 1 {
+		t.Errorf("say request voice = %q (resolved %d times), want the section voice on the configured provider", sayRequest.Voice, resolved)
+	}
+
+	if _, err := gen.GenerateSection(parser.Section{Title: "Outro", Content: "Bye", Provider: "nope"}, 3); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("GenerateSection() error = %v, want an unknown provider error", err)
+	}
+}
+
+// TestGenerateStitch tests that requests carry the text of adjacent sections
+func TestGenerateStitch(t *testing.T) {
+	sections := []parser.Section{
+		{Index: 1, Title: "Intro", Content: "First."},
+		{Index: 2, Title: "Body", Content: "Second."},
+		{Index: 3, Title: "Outro", Content: "Third."},
+	}
+
+	tests := []struct {
+		name         string
+		stitch       bool
+		section      parser.Section
+		wantPrevious string
+		wantNext     string
+	}{
+		{name: "middle section", stitch: true, section: sections[1], wantPrevious: "First.", wantNext: "Third."},
+		{name: "first section", stitch: true, section: sections[0], wantNext: "Second."},
+		{name: "last section", stitch: true, section: sections[2], wantPrevious: "Second."},
+		{name: "disabled", section: sections[1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested tts.GenerateRequest
+			gen := NewGenerator(GeneratorConfig{
+				Format:    "mp3",
+				Prefix:    "test",
+				OutputDir: t.TempDir(),
+				Provider:  &recordingProvider{name: "elevenlabs", record: &requested},
+				Stitch:    tt.stitch,
+			}, logger.NewDefaultLogger())
+			gen.SetSections(sections)
+
+			if err := gen.Generate(tt.section, tt.section.Index); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if requested.PreviousText != tt.wantPrevious || requested.NextText != tt.wantNext {
+				t.Errorf("context = %q/%q, want %q/%q", requested.PreviousText, requested.NextText, tt.wantPrevious, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestOutputBaseNaming(t *testing.T) {
+	outputDir := t.TempDir()
+	log := logger.NewDefaultLogger()
+
+	t.Run("unicode slug", func(t *testing.T) {
+		gen := NewGenerator(GeneratorConfig{Prefix: "test", OutputDir: outputDir, SlugStyle: text.SlugUnicode}, log)
+		if got, want := gen.OutputBase(parser.Section{Title: "Введение"}, 1), filepath.Join(outputDir, "test_01_введение"); got != want {
+			t.Errorf("OutputBase() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("max filename length", func(t *testing.T) {
+		gen := NewGenerator(GeneratorConfig{Prefix: "test", OutputDir: outputDir, MaxFilenameLen: 16}, log)
+		section := parser.Section{Title: "Getting Started With md2audio", Sentence: 2}
+		if got, want := gen.OutputBase(section, 1), filepath.Join(outputDir, "test_01_gett_s02"); got != want {
+			t.Errorf("OutputBase() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("start index", func(t *testing.T) {
+		gen := NewGenerator(GeneratorConfig{Prefix: "test", OutputDir: outputDir, StartIndex: 12}, log)
+		if got, want := gen.OutputBase(parser.Section{Title: "Intro"}, 1), filepath.Join(outputDir, "test_12_intro"); got != want {
+			t.Errorf("OutputBase() = %q, want %q", got, want)
+		}
+		if got := gen.OutputNumber(3); got != 14 {
+			t.Errorf("OutputNumber(3) = %d, want 14", got)
+		}
+	})
+
+	t.Run("chapters", func(t *testing.T) {
+		gen := NewGenerator(GeneratorConfig{Prefix: "test", OutputDir: outputDir}, log)
+		section := parser.Section{Title: "Install", Chapter: 2, Track: 3, ChapterTitle: "Getting Started"}
+		if got, want := gen.OutputBase(section, 7), filepath.Join(outputDir, "02_getting_started", "test_02_03_install"); got != want {
+			t.Errorf("OutputBase() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("collisions get a suffix", func(t *testing.T) {
+		gen := NewGenerator(GeneratorConfig{Prefix: "chapter_introduction", OutputDir: outputDir, MaxFilenameLen: 12}, log)
+		first := gen.OutputBase(parser.Section{Title: "Intro"}, 1)
+		second := gen.OutputBase(parser.Section{Title: "Setup"}, 2)
+		if first != filepath.Join(outputDir, "chapter_intr") || second != filepath.Join(outputDir, "chapter_in_2") {
+			t.Errorf("OutputBase() = %q, %q", first, second)
+		}
+		// Repeated calls for a section return its claimed name
+		if again := gen.OutputBase(parser.Section{Title: "Setup"}, 2); again != second {
+			t.Errorf("OutputBase() = %q on repeat, want %q", again, second)
+		}
+	})
+}
+
+// TestPreviewSection tests request previews for previewing and plain providers
+func TestPreviewSection(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	section := parser.Section{Title: "Intro", Content: "Hello world.", HasTiming: true, Duration: 2}
+
+	var requested tts.GenerateRequest
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Kate",
+		Rate:      180,
+		Format:    "aiff",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &previewingProvider{recordingProvider{name: "say", record: &requested}},
+	}, log)
+
+	preview, err := gen.PreviewSection(section, 1)
+	if err != nil {
+		t.Fatalf("PreviewSection() error = %v", err)
+	}
+	if !strings.HasSuffix(preview.Target, "test_01_intro.aiff") {
+		t.Errorf("Unexpected preview target: %q", preview.Target)
+	}
+	if preview.Body != section.Content {
+		t.Errorf("Preview body = %q, want %q", preview.Body, section.Content)
+	}
+	// Timed sections preview the calculated rate, not the default
+	if requested.Rate == nil || *requested.Rate == 180 || requested.TargetDuration == nil {
+		t.Errorf("Expected timing-adjusted request, got %+v", requested)
+	}
+
+	plain := NewGenerator(GeneratorConfig{
+		Format:   "aiff",
+		Provider: &recordingProvider{name: "say", record: &requested},
+	}, log)
+	if _, err := plain.PreviewSection(section, 1); err == nil {
+		t.Error("PreviewSection() should fail for providers without request previews")
+	}
+}
+
+// TestSpeakSection tests playing sections aloud with speaking and plain providers
+func TestSpeakSection(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	section := parser.Section{Title: "Intro", Content: "Hello world.", Voice: "Daniel"}
+
+	var spoken tts.GenerateRequest
+	gen := NewGenerator(GeneratorConfig{
+		Voice:    "Kate",
+		Rate:     200,
+		Format:   "aiff",
+		Provider: &speakingProvider{recordingProvider{name: "say", record: &spoken}},
+	}, log)
+
+	if err := gen.SpeakSection(context.Background(), section, 1); err != nil {
+		t.Fatalf("SpeakSection() error = %v", err)
+	}
+	// Section voice overrides apply as when generating
+	if spoken.Text != section.Content || spoken.Voice != "Daniel" || spoken.Rate == nil || *spoken.Rate != 200 {
+		t.Errorf("Spoken request = %+v", spoken)
+	}
+
+	plain := NewGenerator(GeneratorConfig{
+		Format:   "aiff",
+		Provider: &recordingProvider{name: "espeak", record: &spoken},
+	}, log)
+	if err := plain.SpeakSection(context.Background(), section, 1); err == nil {
+		t.Error("SpeakSection() should fail for providers that cannot play audio")
+	}
+}
+
+// TestGenerateWithSubtitles tests that an SRT file is written next to the audio file
+func TestGenerateWithSubtitles(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	outputDir := t.TempDir()
+
+	var requested tts.GenerateRequest
+	gen := NewGenerator(GeneratorConfig{
+		Voice:        "Kate",
+		Rate:         180,
+		Format:       "aiff",
+		Prefix:       "test",
+		OutputDir:    outputDir,
+		Provider:     &recordingProvider{name: "say", record: &requested},
+		Subtitles:    true,
+		TimingMethod: timing.MethodUniform,
+	}, log)
+
+	section := parser.Section{
+		Title:     "Intro",
+		Content:   "Welcome to the demo. This is the second sentence.",
+		Duration:  4.5,
+		HasTiming: true,
+	}
+	if err := gen.Generate(section, 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	srtPath := strings.TrimSuffix(requested.OutputPath, filepath.Ext(requested.OutputPath)) + ".srt"
+	data, err := os.ReadFile(srtPath)
+	if err != nil {
+		t.Fatalf("Expected subtitle file at %s: %v", srtPath, err)
+	}
+
+	srt := string(data)
+	if !strings.Contains(srt, "Welcome to the demo.") || !strings.Contains(srt, "This is the second sentence.") {
+		t.Errorf("Unexpected subtitle content:\n%s", srt)
+	}
+	// Unmeasurable audio falls back to the target duration
+	if !strings.Contains(srt, "--> 00:00:04,500") {
+		t.Errorf("Expected last cue to end at target duration, got:\n%s", srt)
+	}
+}
+
+// TestGenerateWithAligner tests that aligned timings are stored in a sidecar and used for subtitles
+func TestGenerateWithAligner(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	var requested tts.GenerateRequest
+	aligner := &fakeAligner{words: []timing.Word{
+		{Text: "Hello", Start: 0.2, End: 0.6},
+		{Text: "world.", Start: 0.7, End: 1.3},
+	}}
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Kate",
+		Rate:      180,
+		Format:    "aiff",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &recordingProvider{name: "say", record: &requested},
+		Subtitles: true,
+		Aligner:   aligner,
+	}, log)
+
+	if err := gen.Generate(parser.Section{Title: "Intro", Content: "Hello world."}, 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if aligner.audioPath != requested.OutputPath {
+		t.Errorf("Aligner received %q, want %q", aligner.audioPath, requested.OutputPath)
+	}
+
+	sidecar, err := align.ReadSidecar(requested.OutputPath)
+	if err != nil {
+		t.Fatalf("Expected timings sidecar: %v", err)
+	}
+	if sidecar.Aligner != "fake" || len(sidecar.Words) != 2 {
+		t.Errorf("Unexpected sidecar: %+v", sidecar)
+	}
+
+	srtPath := strings.TrimSuffix(requested.OutputPath, filepath.Ext(requested.OutputPath)) + ".srt"
+	data, err := os.ReadFile(srtPath)
+	if err != nil {
+		t.Fatalf("Expected subtitle file: %v", err)
+	}
+	if !strings.Contains(string(data), "00:00:00,200 --> 00:00:01,300") {
+		t.Errorf("Expected subtitles from aligned timings, got:\n%s", data)
+	}
+}
+
+// TestGenerateWithReadAlong tests that a read-along document is written from aligned timings
+func TestGenerateWithReadAlong(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	var requested tts.GenerateRequest
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Kate",
+		Rate:      180,
+		Format:    "aiff",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &recordingProvider{name: "say", record: &requested},
+		ReadAlong: true,
+		Aligner: &fakeAligner{words: []timing.Word{
+			{Text: "Hello", Start: 0.2, End: 0.6},
+			{Text: "world.", Start: 0.7, End: 1.3},
+			{Text: "Bye.", Start: 1.6, End: 2},
+		}},
+	}, log)
+
+	if err := gen.Generate(parser.Section{Title: "Intro", Content: "Hello world. Bye.", Duration: 2, HasTiming: true}, 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(readalong.Path(requested.OutputPath))
+	if err != nil {
+		t.Fatalf("Expected read-along document: %v", err)
+	}
+	var doc readalong.Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid read-along document: %v", err)
+	}
+	if doc.Audio != filepath.Base(requested.OutputPath) || doc.Timings != "fake" || len(doc.Words) != 3 {
+		t.Errorf("Unexpected read-along document: %+v", doc)
+	}
+	want := []readalong.Sentence{
+		{Text: "Hello world.", Offset: 0, Length: 12, Start: 0.2, End: 1.3},
+		{Text: "Bye.", Offset: 13, Length: 4, Start: 1.6, End: 2},
+	}
+	if !reflect.DeepEqual(doc.Sentences, want) {
+		t.Errorf("Sentences = %+v, want %+v", doc.Sentences, want)
+	}
+}
+
+// TestGenerateWithFailingAligner tests the fallback to estimated timings
+func TestGenerateWithFailingAligner(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	var requested tts.GenerateRequest
+	gen := NewGenerator(GeneratorConfig{
+		Voice:     "Kate",
+		Rate:      180,
+		Format:    "aiff",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &recordingProvider{name: "say", record: &requested},
+		Subtitles: true,
+		Aligner:   &fakeAligner{err: fmt.Errorf("aligner not installed")},
+	}, log)
+
+	if err := gen.Generate(parser.Section{Title: "Intro", Content: "Hello world."}, 1); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, err := align.ReadSidecar(requested.OutputPath); err == nil {
+		t.Error("Expected no timings sidecar when alignment fails")
+	}
+	srtPath := strings.TrimSuffix(requested.OutputPath, filepath.Ext(requested.OutputPath)) + ".srt"
+	if _, err := os.Stat(srtPath); err != nil {
+		t.Errorf("Expected subtitles from estimated timings: %v", err)
+	}
+}
+
+// TestGenerateSectionVerification tests that mismatching transcripts flag the section
+func TestGenerateSectionVerification(t *testing.T) {
+	tests := []struct {
+		name          string
+		transcript    string
+		expectFlagged bool
+	}{
+		{
+			name:          "matching transcript",
+			transcript:    "welcome to the demo",
+			expectFlagged: false,
+		},
+		{
+			name:          "skipped words",
+			transcript:    "welcome",
+			expectFlagged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.NewDefaultLogger()
+
+			var requested tts.GenerateRequest
+			gen := NewGenerator(GeneratorConfig{
+				Voice:     "Kate",
+				Rate:      180,
+				Format:    "aiff",
+				Prefix:    "test",
+				OutputDir: t.TempDir(),
+				Provider:  &recordingProvider{name: "say", record: &requested},
+				Verifier:  &fakeTranscriber{transcript: tt.transcript},
+			}, log)
+
+			result, err := gen.GenerateSection(parser.Section{Title: "Intro", Content: "Welcome to the demo."}, 1)
+			if err != nil {
+				t.Fatalf("GenerateSection() error = %v", err)
+			}
+
+			if result.OutputPath != requested.OutputPath {
+				t.Errorf("OutputPath = %q, want %q", result.OutputPath, requested.OutputPath)
+			}
+			if result.Flagged != tt.expectFlagged {
+				t.Errorf("Flagged = %v, want %v", result.Flagged, tt.expectFlagged)
+			}
+			if tt.expectFlagged && !strings.Contains(result.FlagReason, "word error rate") {
+				t.Errorf("Unexpected flag reason: %q", result.FlagReason)
+			}
+		})
+	}
+}
+
+func TestGenerateSectionMinDuration(t *testing.T) {
+	// 20 words at 180 wpm are expected to take about 6.7s
+	section := parser.Section{Title: "Intro", Content: strings.TrimSpace(strings.Repeat("word ", 20))}
+
+	tests := []struct {
+		name          string
+		durations     []float64
+		retryShort    bool
+		expectFlagged bool
+		expectCalls   int
+	}{
+		{name: "plausible duration", durations: []float64{6}, expectCalls: 1},
+		{name: "truncated", durations: []float64{1}, expectFlagged: true, expectCalls: 1},
+		{name: "truncated then fixed by retry", durations: []float64{1, 6}, retryShort: true, expectCalls: 2},
+		{name: "truncated twice", durations: []float64{1, 1}, retryShort: true, expectFlagged: true, expectCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &durationProvider{durations: tt.durations}
+			gen := NewGenerator(GeneratorConfig{
+				Rate:             180,
+				Format:           "wav",
+				Prefix:           "test",
+				OutputDir:        t.TempDir(),
+				Provider:         provider,
+				MinDurationRatio: 0.4,
+				RetryShort:       tt.retryShort,
+			}, logger.NewDefaultLogger())
+
+			result, err := gen.GenerateSection(section, 1)
+			if err != nil {
+				t.Fatalf("GenerateSection() error = %v", err)
+			}
+			if result.Flagged != tt.expectFlagged {
+				t.Errorf("Flagged = %v (%s), want %v", result.Flagged, result.FlagReason, tt.expectFlagged)
+			}
+			if tt.expectFlagged && !strings.Contains(result.FlagReason, "possibly truncated") {
+				t.Errorf("Unexpected flag reason: %q", result.FlagReason)
+			}
+			if provider.calls != tt.expectCalls {
+				t.Errorf("Provider called %d times, want %d", provider.calls, tt.expectCalls)
+			}
+		})
+	}
+}
+
+func TestGenerateSectionLearnsRate(t *testing.T) {
+	// 20 words in 5s of audio: 240 wpm
+	section := parser.Section{Index: 1, Title: "Intro", Content: strings.TrimSpace(repeat("word ", 20))}
+	learner := &fakeLearner{}
+	gen := NewGenerator(GeneratorConfig{
+		Rate:      183,
+		Format:    "wav",
+		Prefix:    "test",
+		OutputDir: t.TempDir(),
+		Provider:  &durationProvider{durations: []float64{5}},
+		Learner:   learner,
+	}, logger.NewDefaultLogger())
+
+	if _, err := gen.GenerateSection(section, 1); err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	if len(learner.rates) != 1 || learner.rates[0] != 180 || math.Abs(learner.wpms[0]-240) > 0.1 {
+		t.Errorf("learned rates = %v at %v wpm, want 240 wpm at rate 180", learner.rates, learner.wpms)
+	}
+
+	// Short sections are not learned from
+	if _, err := gen.GenerateSection(parser.Section{Index: 2, Title: "Short", Content: "Hi there."}, 2); err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	if len(learner.rates) != 1 {
+		t.Errorf("Expected short sections to be ignored, got %d observations", len(learner.rates))
+	}
+}
+
+func TestGenerateSectionPostProcessors(t *testing.T) {
+	section := parser.Section{Index: 2, Title: "Setup", Content: "Install the tools."}
+	var seen postprocess.File
+	// Padding the audio to 3s must be reflected in the measured duration
+	pad := func(ctx context.Context, file postprocess.File) error {
+		seen = file
+		return utils.WriteSilence(ctx, file.Path, 3)
+	}
+	gen := NewGenerator(GeneratorConfig{
+		Voice:          "Kate",
+		Format:         "wav",
+		OutputDir:      t.TempDir(),
+		Provider:       &durationProvider{durations: []float64{1}},
+		PostProcessors: []postprocess.Func{pad},
+	}, logger.NewDefaultLogger())
+
+	result, err := gen.GenerateSection(section, 2)
+	if err != nil {
+		t.Fatalf("GenerateSection() error = %v", err)
+	}
+	if seen.Path != result.OutputPath || seen.Title != "Setup" || seen.Index != 2 || seen.Provider != "espeak" || seen.Voice != "Kate" {
+		t.Errorf("post-processor got %+v", seen)
+	}
+	if math.Abs(result.Duration-3) > 0.01 {
+		t.Errorf("Duration = %.2f, want the post-processed 3s", result.Duration)
+	}
+
+	failing := func(ctx context.Context, file postprocess.File) error { return errors.New("denoise failed") }
+	gen = NewGenerator(GeneratorConfig{
+		Format:         "wav",
+		OutputDir:      t.TempDir(),
+		Provider:       &durationProvider{durations: []float64{1}},
+		PostProcessors: []postprocess.Func{failing},
+	}, logger.NewDefaultLogger())
+	if _, err := gen.GenerateSection(section, 2); err == nil || !strings.Contains(err.Error(), "denoise failed") {
+		t.Errorf("GenerateSection() error = %v, want the post-processing error", err)
+	}
+}
+
+func TestEstimateDurationFromRateCurve(t *testing.T) {
+	section := parser.Section{Content: strings.TrimSpace(repeat("word ", 60))}
+	gen := NewGenerator(GeneratorConfig{
+		Rate:      180,
+		Provider:  &durationProvider{},
+		RateCurve: calibrate.Curve{{Rate: 180, WPM: 240}},
+	}, logger.NewDefaultLogger())
+
+	if got := gen.EstimateDuration(section); math.Abs(got-15) > 0.001 {
+		t.Errorf("EstimateDuration() = %v, want 15s at the measured 240 wpm", got)
+	}
+}
+
+func TestGeneratePauses(t *testing.T) {
+	section := parser.Section{Index: 1, Title: "Intro", Content: "Ready? [pause 1.5s] Go."}
+
+	t.Run("provider markup", func(t *testing.T) {
+		var requested tts.GenerateRequest
+		gen := NewGenerator(GeneratorConfig{
+			Format:    "aiff",
+			Prefix:    "test",
+			OutputDir: t.TempDir(),
+			Provider:  &recordingProvider{name: "say", record: &requested},
+		}, logger.NewDefaultLogger())
+
+		if err := gen.Generate(section, 1); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if requested.Text != section.Content {
+			t.Errorf("Text = %q, want the directives for the provider", requested.Text)
+		}
+	})
+
+	t.Run("joined silence", func(t *testing.T) {
+		dir := t.TempDir()
+		provider := &durationProvider{durations: []float64{1}}
+		gen := NewGenerator(GeneratorConfig{
+			Format:    "wav",
+			Prefix:    "test",
+			OutputDir: dir,
+			Provider:  provider,
+		}, logger.NewDefaultLogger())
+
+		result, err := gen.GenerateSection(section, 1)
+		if provider.calls != 2 {
+			t.Errorf("provider calls = %d, want one per text part", provider.calls)
+		}
+		if parts, _ := filepath.Glob(filepath.Join(dir, "*.part*")); len(parts) > 0 {
+			t.Errorf("parts %v should be removed", parts)
+		}
+		if _, lookErr := exec.LookPath("ffmpeg"); lookErr != nil {
+			if err == nil {
+				t.Error("expected an error without ffmpeg")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("GenerateSection() error = %v", err)
+		}
+		if math.Abs(result.Duration-3.5) > 0.1 {
+			t.Errorf("Duration = %.2fs, want about 3.5s", result.Duration)
+		}
+	})
+}
+
+func TestGenerateSSMLBlocks(t *testing.T) {
+	section := parser.Section{Index: 1, Title: "Intro", Content: `Hello <speak><emphasis>there</emphasis>.</speak>`}
+
+	tests := []struct {
+		provider string
+		ssml     bool
+		want     string
+	}{
+		{provider: "edge", want: section.Content},
+		{provider: "watson", want: section.Content},
+		{provider: "marytts", ssml: true, want: "Hello <emphasis>there</emphasis>."},
+		{provider: "marytts", want: "Hello there."},
+		{provider: "say", want: "Hello there."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var requested tts.GenerateRequest
+			gen := NewGenerator(GeneratorConfig{
+				Format:    "mp3",
+				Prefix:    "test",
+				OutputDir: t.TempDir(),
+				Provider:  &recordingProvider{name: tt.provider, record: &requested},
+				SSML:      tt.ssml,
+			}, logger.NewDefaultLogger())
+
+			if err := gen.Generate(section, 1); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if requested.Text != tt.want {
+				t.Errorf("Text = %q, want %q", requested.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateSectionDurationPauses(t *testing.T) {
+	section := parser.Section{Content: strings.TrimSpace(repeat("word ", 30)) + " [pause 2s] [pause 500ms]"}
+	if got := EstimateSectionDuration(section, "say", 180); math.Abs(got-12.5) > 0.001 {
+		t.Errorf("EstimateSectionDuration() = %v, want 10s of speech and 2.5s of pauses", got)
+	}
+}
+
+// fakeLearner records observed speaking rates
+type fakeLearner struct {
+	rates []int
+	wpms  []float64
+}
+
+func (l *fakeLearner) Observe(ctx context.Context, rate int, wpm float64) error {
+	l.rates = append(l.rates, rate)
+	l.wpms = append(l.wpms, wpm)
+	return nil
+}
+
+// durationProvider is a mock provider writing silent WAV files of the given
+// durations, one per call
+type durationProvider struct {
+	durations []float64
+	calls     int
+}
+
+func (p *durationProvider) Name() string {
+	return "espeak"
+}
+
+func (p *durationProvider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	seconds := p.durations[min(p.calls, len(p.durations)-1)]
+	p.calls++
+	return req.OutputPath, utils.WriteSilence(ctx, req.OutputPath, seconds)
+}
+
+func (p *durationProvider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	return nil, nil
+}
+
+// fakeTranscriber is a mock transcriber returning a fixed transcript
+type fakeTranscriber struct {
+	transcript string
+}
+
+func (f *fakeTranscriber) Name() string {
+	return "fake"
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, audioPath string) (string, error) {
+	return f.transcript, nil
+}
+
+// fakeAligner is a mock aligner returning fixed timings
+type fakeAligner struct {
+	words     []timing.Word
+	err       error
+	audioPath string
+}
+
+func (a *fakeAligner) Name() string {
+	return "fake"
+}
+
+func (a *fakeAligner) Align(ctx context.Context, audioPath, text string) ([]timing.Word, error) {
+	a.audioPath = audioPath
+	return a.words, a.err
+}
+
+// recordingProvider is a mock TTS provider that records the last request
+type recordingProvider struct {
+	name   string
+	record *tts.GenerateRequest
+}
+
+func (p *recordingProvider) Name() string {
+	return p.name
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	*p.record = req
+	return req.OutputPath, nil
+}
+
+func (p *recordingProvider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	return nil, nil
+}
+
+// previewingProvider is a mock provider that also previews requests
+type previewingProvider struct {
+	recordingProvider
+}
+
+func (p *previewingProvider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	*p.record = req
+	return tts.RequestPreview{Target: "say " + req.OutputPath, Body: req.Text}, nil
+}
+
+type speakingProvider struct {
+	recordingProvider
+}
+
+func (p *speakingProvider) Speak(ctx context.Context, req tts.GenerateRequest) error {
+	*p.record = req
+	return nil
+}
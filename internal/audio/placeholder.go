@@ -0,0 +1,59 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// Placeholder kinds written in place of failed sections
+const (
+	PlaceholderSilence = "silence" // Silence of the section's target or estimated duration
+	PlaceholderSpoken  = "spoken"  // A short spoken notice that the section failed
+)
+
+// PlaceholderKinds lists the supported placeholder kinds
+var PlaceholderKinds = []string{PlaceholderSilence, PlaceholderSpoken}
+
+// ValidatePlaceholder checks a placeholder kind
+func ValidatePlaceholder(kind string) error {
+	if !slices.Contains(PlaceholderKinds, kind) {
+		return fmt.Errorf("invalid -placeholder %q: must be 'silence' or 'spoken'", kind)
+	}
+	return nil
+}
+
+// GeneratePlaceholder writes a placeholder for a section that failed to generate
+// at outputPath, keeping the section's slot in the output. Spoken placeholders
+// fall back to silence if the provider cannot generate them.
+func (g *Generator) GeneratePlaceholder(section parser.Section, outputPath, kind string) (Result, error) {
+	if err := ValidatePlaceholder(kind); err != nil {
+		return Result{}, err
+	}
+
+	if kind == PlaceholderSpoken && g.config.Provider != nil {
+		notice := parser.Section{
+			Title:   section.Title,
+			Index:   section.Index,
+			Content: fmt.Sprintf("Section %d failed to generate.", section.Index),
+		}
+		request, _ := g.buildRequest(notice, strings.TrimSuffix(outputPath, filepath.Ext(outputPath)))
+		request.SSML = false
+		finalPath, err := g.config.Provider.Generate(context.Background(), request)
+		if err == nil {
+			return Result{OutputPath: finalPath, Duration: g.audioDuration(notice, finalPath, g.config.Rate)}, nil
+		}
+		g.log.Warning(fmt.Sprintf("Could not speak placeholder, writing silence instead: %v", err))
+	}
+
+	duration := g.EstimateDuration(section)
+	if err := utils.WriteSilence(context.Background(), outputPath, duration); err != nil {
+		return Result{}, fmt.Errorf("error writing placeholder: %w", err)
+	}
+	return Result{OutputPath: outputPath, Duration: duration}, nil
+}
@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestValidatePlaceholder(t *testing.T) {
+	tests := []struct {
+		kind    string
+		wantErr bool
+	}{
+		{kind: PlaceholderSilence},
+		{kind: PlaceholderSpoken},
+		{kind: "beep", wantErr: true},
+		{kind: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			if err := ValidatePlaceholder(tt.kind); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePlaceholder(%q) error = %v, wantErr %v", tt.kind, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratePlaceholder(t *testing.T) {
+	timed := parser.Section{Title: "Intro", Index: 4, Content: "Hello there.", HasTiming: true, Duration: 2.5}
+
+	tests := []struct {
+		name         string
+		section      parser.Section
+		kind         string
+		providerErr  error
+		wantText     string
+		wantDuration float64
+	}{
+		{
+			name:         "silence of the target duration",
+			section:      timed,
+			kind:         PlaceholderSilence,
+			wantDuration: 2.5,
+		},
+		{
+			name:         "silence of the estimated duration",
+			section:      parser.Section{Title: "Intro", Index: 4, Content: "one two three"},
+			kind:         PlaceholderSilence,
+			wantDuration: 1,
+		},
+		{
+			name:     "spoken notice",
+			section:  timed,
+			kind:     PlaceholderSpoken,
+			wantText: "Section 4 failed to generate.",
+		},
+		{
+			name:         "spoken notice falls back to silence",
+			section:      timed,
+			kind:         PlaceholderSpoken,
+			providerErr:  errors.New("quota exceeded"),
+			wantText:     "Section 4 failed to generate.",
+			wantDuration: 2.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &MockProvider{name: "espeak"}
+			if tt.providerErr != nil {
+				provider.generateFunc = func(string) (string, error) { return "", tt.providerErr }
+			}
+			outputDir := t.TempDir()
+			g := NewGenerator(GeneratorConfig{
+				Rate:      180,
+				Format:    "wav",
+				Prefix:    "section",
+				OutputDir: outputDir,
+				Provider:  provider,
+			}, logger.NewDefaultLogger())
+
+			outputPath := filepath.Join(outputDir, "section_04_intro.wav")
+			result, err := g.GeneratePlaceholder(tt.section, outputPath, tt.kind)
+			if err != nil {
+				t.Fatalf("GeneratePlaceholder() error = %v", err)
+			}
+			if result.OutputPath != outputPath {
+				t.Errorf("OutputPath = %q, want %q", result.OutputPath, outputPath)
+			}
+			if provider.lastText != tt.wantText {
+				t.Errorf("Provider text = %q, want %q", provider.lastText, tt.wantText)
+			}
+			if tt.wantDuration == 0 {
+				return
+			}
+
+			duration, err := utils.GetWAVDuration(outputPath)
+			if err != nil {
+				t.Fatalf("Expected silent WAV placeholder: %v", err)
+			}
+			if math.Abs(duration-tt.wantDuration) > 0.001 || math.Abs(result.Duration-tt.wantDuration) > 0.001 {
+				t.Errorf("Duration = %.3f (result %.3f), want %.3f", duration, result.Duration, tt.wantDuration)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// Package bundle packages a run's output directory into a single archive
+// and extracts it again, so a complete narration package (audio, manifest,
+// captions, and run settings) can be handed off to video editors.
+//
+// Key features:
+//   - tar archives, optionally gzip (.tar.gz, .tgz) or zstd (.tar.zst) compressed
+//   - Bundle info file recording the settings used to generate the audio
+//   - Safe extraction that rejects paths escaping the destination directory
+//
+// zstd compression uses the zstd command, which must be installed.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// InfoFile is the name of the bundle info file stored at the archive root
+const InfoFile = "md2audio-bundle.json"
+
+// Info describes how the bundled audio was generated.
+// It never contains API keys or other secrets.
+type Info struct {
+	Version  string    `json:"version"`
+	Created  time.Time `json:"created"`
+	Provider string    `json:"provider"`
+	Voice    string    `json:"voice,omitempty"`
+	Model    string    `json:"model,omitempty"`
+	Rate     int       `json:"rate,omitempty"`
+	Format   string    `json:"format"`
+	Files    int       `json:"files"` // Number of bundled files, excluding InfoFile
+}
+
+// compression is the archive compression selected by the file extension
+type compression int
+
+const (
+	compressNone compression = iota
+	compressGzip
+	compressZstd
+)
+
+// compressionFor returns the compression for an archive path.
+func compressionFor(path string) (compression, error) {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return compressNone, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return compressGzip, nil
+	case strings.HasSuffix(name, ".tar.zst"), strings.HasSuffix(name, ".tzst"):
+		return compressZstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported bundle %q: must end in .tar, .tar.gz, .tgz, or .tar.zst", path)
+	}
+}
+
+// ValidatePath returns an error if path is not a supported archive type.
+func ValidatePath(path string) error {
+	_, err := compressionFor(path)
+	return err
+}
+
+// Create writes the files under root and an info file to the archive at path.
+// It returns the number of bundled files.
+func Create(path, root string, info Info) (int, error) {
+	comp, err := compressionFor(path)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := collect(root, path)
+	if err != nil {
+		return 0, err
+	}
+	info.Files = len(files)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	if err := writeArchive(out, comp, root, files, info); err != nil {
+		_ = out.Close()
+		_ = os.Remove(path)
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return len(files), nil
+}
+
+// collect returns the slash-separated paths of the regular files under root,
+// skipping the archive itself.
+func collect(root, archivePath string) ([]string, error) {
+	archiveAbs, _ := filepath.Abs(archivePath)
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if abs, _ := filepath.Abs(path); abs == archiveAbs {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files to bundle in %s", root)
+	}
+	return files, nil
+}
+
+// writeArchive writes the info file and files as a tar stream to out.
+func writeArchive(out io.Writer, comp compression, root string, files []string, info Info) (err error) {
+	w, finish, err := compressWriter(out, comp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if finishErr := finish(); err == nil && finishErr != nil {
+			err = fmt.Errorf("failed to compress bundle: %w", finishErr)
+		}
+	}()
+
+	tw := tar.NewWriter(w)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if err := tw.WriteHeader(&tar.Header{Name: InfoFile, Mode: 0644, Size: int64(len(data)), ModTime: info.Created}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		if err := addFile(tw, root, name); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// addFile writes the file root/name to tw.
+func addFile(tw *tar.Writer, root, name string) error {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("failed to bundle %s: %w", name, err)
+	}
+	return nil
+}
+
+// compressWriter wraps out with the compression. The returned function flushes
+// the compressor and must be called after the archive is written.
+func compressWriter(out io.Writer, comp compression) (io.Writer, func() error, error) {
+	switch comp {
+	case compressGzip:
+		gz := gzip.NewWriter(out)
+		return gz, gz.Close, nil
+	case compressZstd:
+		cmd, err := zstdCommand("-q", "-c")
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd.Stdout = out
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return stdin, func() error {
+			closeErr := stdin.Close()
+			return errors.Join(closeErr, cmd.Wait())
+		}, nil
+	default:
+		return out, func() error { return nil }, nil
+	}
+}
+
+// Extract unpacks the archive at path into dest and returns the bundle info.
+// Archives created by other tools without an info file return a zero Info.
+func Extract(path, dest string) (Info, int, error) {
+	comp, err := compressionFor(path)
+	if err != nil {
+		return Info{}, 0, err
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return Info{}, 0, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	r, finish, err := decompressReader(in, comp)
+	if err != nil {
+		return Info{}, 0, err
+	}
+
+	info, count, err := extractArchive(r, dest)
+	if finishErr := finish(); err == nil && finishErr != nil {
+		err = fmt.Errorf("failed to decompress bundle: %w", finishErr)
+	}
+	return info, count, err
+}
+
+// extractArchive writes the files of a tar stream below dest.
+func extractArchive(r io.Reader, dest string) (Info, int, error) {
+	var info Info
+	count := 0
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return info, count, nil
+		}
+		if err != nil {
+			return info, count, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if header.Name == InfoFile {
+			if err := json.NewDecoder(tr).Decode(&info); err != nil {
+				return info, count, fmt.Errorf("invalid bundle info: %w", err)
+			}
+			continue
+		}
+
+		target, err := safePath(dest, header.Name)
+		if err != nil {
+			return info, count, err
+		}
+		if err := writeFile(target, tr, header.FileInfo().Mode().Perm()); err != nil {
+			return info, count, err
+		}
+		count++
+	}
+}
+
+// safePath returns the destination of an archive entry, rejecting names
+// that would be written outside dest.
+func safePath(dest, name string) (string, error) {
+	if filepath.IsAbs(name) || !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("unsafe path in bundle: %s", name)
+	}
+	return filepath.Join(dest, filepath.FromSlash(name)), nil
+}
+
+// writeFile copies r to a new file at path, creating parent directories.
+func writeFile(path string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to extract %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// decompressReader wraps in with the decompression. The returned function
+// releases the decompressor.
+func decompressReader(in io.Reader, comp compression) (io.Reader, func() error, error) {
+	switch comp {
+	case compressGzip:
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		return gz, gz.Close, nil
+	case compressZstd:
+		cmd, err := zstdCommand("-q", "-d", "-c")
+		if err != nil {
+			return nil, nil, err
+		}
+		cmd.Stdin = in
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return stdout, func() error {
+			// Drain remaining output so zstd can exit
+			_, _ = io.Copy(io.Discard, stdout)
+			return cmd.Wait()
+		}, nil
+	default:
+		return in, func() error { return nil }, nil
+	}
+}
+
+// zstdCommand returns a zstd command with args.
+func zstdCommand(args ...string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		return nil, fmt.Errorf("zstd command not found: install zstd or use a .tar.gz bundle")
+	}
+	return exec.Command("zstd", args...), nil
+}
@@ -0,0 +1,139 @@
+package bundle
+
+import (
+	"archive/tar"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeOutput creates a sample output directory and returns its path
+func writeOutput(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"section_01_intro.mp3":          "audio one",
+		"section_01_intro.srt":          "1\n00:00:00,000 --> 00:00:01,000\nHello\n",
+		"manifest.json":                 `{"sections":[]}`,
+		"lesson-2/section_01_start.mp3": "audio two",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCreateExtract(t *testing.T) {
+	tests := []struct {
+		name     string
+		archive  string
+		needZstd bool
+	}{
+		{name: "tar", archive: "out.tar"},
+		{name: "gzip", archive: "out.tar.gz"},
+		{name: "tgz", archive: "out.tgz"},
+		{name: "zstd", archive: "out.tar.zst", needZstd: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.needZstd {
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd not installed")
+				}
+			}
+
+			root := writeOutput(t)
+			archive := filepath.Join(t.TempDir(), tt.archive)
+			created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			n, err := Create(archive, root, Info{Version: "1.0.0", Created: created, Provider: "elevenlabs", Voice: "voice-id", Format: "mp3"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if n != 4 {
+				t.Errorf("Create() bundled %d files, want 4", n)
+			}
+
+			dest := t.TempDir()
+			info, count, err := Extract(archive, dest)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if count != 4 {
+				t.Errorf("Extract() extracted %d files, want 4", count)
+			}
+			if info.Provider != "elevenlabs" || info.Files != 4 || !info.Created.Equal(created) {
+				t.Errorf("Extract() info = %+v", info)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dest, "lesson-2", "section_01_start.mp3"))
+			if err != nil || string(data) != "audio two" {
+				t.Errorf("extracted file = %q, %v", data, err)
+			}
+			if _, err := os.Stat(filepath.Join(dest, InfoFile)); !os.IsNotExist(err) {
+				t.Error("info file should not be extracted")
+			}
+		})
+	}
+}
+
+func TestCreateSkipsArchiveInRoot(t *testing.T) {
+	root := writeOutput(t)
+	archive := filepath.Join(root, "bundle.tar")
+
+	if _, err := Create(archive, root, Info{}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Bundling again must not include the previous archive
+	n, err := Create(archive, root, Info{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if n != 4 {
+		t.Errorf("Create() bundled %d files, want 4", n)
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	root := writeOutput(t)
+
+	if _, err := Create(filepath.Join(t.TempDir(), "out.zip"), root, Info{}); err == nil || !strings.Contains(err.Error(), "unsupported bundle") {
+		t.Errorf("expected unsupported bundle error, got %v", err)
+	}
+	if _, err := Create(filepath.Join(t.TempDir(), "out.tar"), t.TempDir(), Info{}); err == nil || !strings.Contains(err.Error(), "no files to bundle") {
+		t.Errorf("expected no files error, got %v", err)
+	}
+}
+
+func TestExtractRejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"../evil.mp3", "/tmp/evil.mp3", "a/../../evil.mp3"} {
+		t.Run(name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "evil.tar")
+			f, err := os.Create(archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tw := tar.NewWriter(f)
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 4, Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = tw.Write([]byte("evil"))
+			_ = tw.Close()
+			_ = f.Close()
+
+			if _, _, err := Extract(archive, t.TempDir()); err == nil || !strings.Contains(err.Error(), "unsafe path") {
+				t.Errorf("Extract() error = %v, want unsafe path error", err)
+			}
+		})
+	}
+}
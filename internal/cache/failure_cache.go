@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// DefaultFailureCoolDown is how long a recorded hard failure makes requests
+// fail fast before the provider is called again
+const DefaultFailureCoolDown = 10 * time.Minute
+
+// Failure is a recorded hard failure of a provider.
+type Failure struct {
+	Provider string
+	Key      string // Scope and subject, e.g. "voice:21m00Tcm4TlvDq8ikWAM"
+	Message  string
+	FailedAt time.Time
+}
+
+// FailureCache records provider hard failures in the SQLite database shared
+// with the voice cache.
+type FailureCache struct {
+	db *sql.DB
+}
+
+// OpenFailureCache opens the failure cache in the SQLite database at dbPath.
+func OpenFailureCache(dbPath string) (*FailureCache, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// WAL mode lets the voice cache and failure cache share the database
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS provider_failures (
+		provider TEXT NOT NULL,
+		failure_key TEXT NOT NULL,
+		message TEXT NOT NULL,
+		failed_at INTEGER NOT NULL,
+		PRIMARY KEY (provider, failure_key)
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &FailureCache{db: db}, nil
+}
+
+// Close closes the database connection.
+func (c *FailureCache) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
+
+// Record stores a hard failure, replacing an earlier one with the same key.
+func (c *FailureCache) Record(ctx context.Context, provider, key, message string) error {
+	_, err := c.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO provider_failures (provider, failure_key, message, failed_at)
+		VALUES (?, ?, ?, ?)
+	`, provider, key, message, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("failed to record failure: %w", err)
+	}
+	return nil
+}
+
+// Get returns the failure recorded for key within the cool-down window,
+// or nil if there is none.
+func (c *FailureCache) Get(ctx context.Context, provider, key string, coolDown time.Duration) (*Failure, error) {
+	cutoff := time.Now().Add(-coolDown).Unix()
+
+	var message string
+	var failedAt int64
+	err := c.db.QueryRowContext(ctx, `
+		SELECT message, failed_at
+		FROM provider_failures
+		WHERE provider = ? AND failure_key = ? AND failed_at > ?
+	`, provider, key, cutoff).Scan(&message, &failedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failures: %w", err)
+	}
+
+	return &Failure{Provider: provider, Key: key, Message: message, FailedAt: time.Unix(failedAt, 0)}, nil
+}
+
+// GuardedProvider wraps a TTS provider, failing fast with the cached diagnosis
+// while a hard failure for the same credentials or voice is within its cool-down.
+type GuardedProvider struct {
+	provider    tts.Provider
+	dbPath      string
+	credentials string
+	coolDown    time.Duration
+	log         logger.LoggerInterface
+}
+
+// NewGuardedProvider creates a guarded provider recording hard failures in
+// the SQLite database at dbPath. credentials identify the account (e.g., the
+// API key), so a corrected key is not refused because of the old one.
+func NewGuardedProvider(provider tts.Provider, dbPath, credentials string, coolDown time.Duration, log logger.LoggerInterface) *GuardedProvider {
+	return &GuardedProvider{
+		provider:    provider,
+		dbPath:      dbPath,
+		credentials: credentials,
+		coolDown:    coolDown,
+		log:         log,
+	}
+}
+
+// Generate returns the cached failure for the request's credentials or voice
+// if one is within the cool-down, otherwise delegates to the underlying
+// provider and records its hard failures.
+func (p *GuardedProvider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	// The database is opened per request, so failures recorded by concurrent runs are seen
+	failures, err := OpenFailureCache(p.dbPath)
+	if err != nil {
+		p.log.Debug(fmt.Sprintf("Failure cache unavailable: %v", err))
+		return p.provider.Generate(ctx, req)
+	}
+	defer func() { _ = failures.Close() }()
+
+	for _, scope := range []string{tts.ScopeAuth, tts.ScopeVoice} {
+		failure, err := failures.Get(ctx, p.provider.Name(), p.failureKey(scope, req), p.coolDown)
+		if err != nil {
+			p.log.Debug(fmt.Sprintf("Failure cache unavailable: %v", err))
+			break
+		}
+		if failure != nil {
+			retryIn := time.Until(failure.FailedAt.Add(p.coolDown)).Round(time.Second)
+			return "", &tts.HardFailure{
+				Scope: scope,
+				Err:   fmt.Errorf("%s (cached failure, not retried for %s; use -failure-cooldown 0 to retry now)", failure.Message, retryIn),
+			}
+		}
+	}
+
+	outputPath, err := p.provider.Generate(ctx, req)
+	var hard *tts.HardFailure
+	if errors.As(err, &hard) {
+		if recordErr := failures.Record(ctx, p.provider.Name(), p.failureKey(hard.Scope, req), err.Error()); recordErr != nil {
+			p.log.Debug(fmt.Sprintf("Could not cache failure: %v", recordErr))
+		}
+	}
+	return outputPath, err
+}
+
+// failureKey returns the cache key of a failure scope for a request
+func (p *GuardedProvider) failureKey(scope string, req tts.GenerateRequest) string {
+	if scope == tts.ScopeVoice {
+		return scope + ":" + req.Voice
+	}
+	// Only a fingerprint of the credentials is stored
+	sum := sha256.Sum256([]byte(p.credentials))
+	return scope + ":" + hex.EncodeToString(sum[:8])
+}
+
+// ListVoices delegates to the underlying provider.
+func (p *GuardedProvider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	return p.provider.ListVoices(ctx)
+}
+
+// Name returns the underlying provider's name.
+func (p *GuardedProvider) Name() string {
+	return p.provider.Name()
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// hardFailingProvider fails every request for badVoice with an unknown voice error
+type hardFailingProvider struct {
+	MockTTSProvider
+	badVoice string
+}
+
+func (p *hardFailingProvider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	p.generateCalls++
+	if req.Voice == p.badVoice {
+		return "", &tts.HardFailure{Scope: tts.ScopeVoice, Err: fmt.Errorf("voice %q not found", req.Voice)}
+	}
+	return req.OutputPath, nil
+}
+
+func TestFailureCache(t *testing.T) {
+	ctx := context.Background()
+	failures, err := OpenFailureCache(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenFailureCache() error = %v", err)
+	}
+	defer func() { _ = failures.Close() }()
+
+	if err := failures.Record(ctx, "elevenlabs", "voice:bad", "voice not found"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	failure, err := failures.Get(ctx, "elevenlabs", "voice:bad", time.Hour)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if failure == nil || failure.Message != "voice not found" {
+		t.Fatalf("Get() = %+v, want the recorded failure", failure)
+	}
+
+	// Expired and unknown failures are not returned
+	if failure, _ := failures.Get(ctx, "elevenlabs", "voice:bad", -time.Second); failure != nil {
+		t.Errorf("Get() returned a failure outside the cool-down: %+v", failure)
+	}
+	if failure, _ := failures.Get(ctx, "elevenlabs", "voice:good", time.Hour); failure != nil {
+		t.Errorf("Get() returned a failure for another key: %+v", failure)
+	}
+	if failure, _ := failures.Get(ctx, "say", "voice:bad", time.Hour); failure != nil {
+		t.Errorf("Get() returned a failure for another provider: %+v", failure)
+	}
+}
+
+func TestGuardedProvider(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	mock := &hardFailingProvider{MockTTSProvider: MockTTSProvider{name: "elevenlabs"}, badVoice: "bad"}
+	log := logger.NewDefaultLogger()
+
+	guarded := NewGuardedProvider(mock, dbPath, "key-1", time.Hour, log)
+	if _, err := guarded.Generate(ctx, tts.GenerateRequest{Voice: "bad"}); err == nil {
+		t.Fatal("Generate() should fail for an unknown voice")
+	}
+
+	// A later run fails fast without calling the provider
+	guarded = NewGuardedProvider(mock, dbPath, "key-1", time.Hour, log)
+	_, err := guarded.Generate(ctx, tts.GenerateRequest{Voice: "bad"})
+	var hard *tts.HardFailure
+	if !errors.As(err, &hard) || !strings.Contains(err.Error(), "cached failure") || !strings.Contains(err.Error(), `voice "bad" not found`) {
+		t.Errorf("Generate() error = %v, want the cached failure", err)
+	}
+	if mock.generateCalls != 1 {
+		t.Errorf("Provider called %d times, want 1", mock.generateCalls)
+	}
+
+	// Other voices still reach the provider
+	if _, err := guarded.Generate(ctx, tts.GenerateRequest{Voice: "good", OutputPath: "out.mp3"}); err != nil {
+		t.Errorf("Generate() error = %v for another voice", err)
+	}
+	if mock.generateCalls != 2 {
+		t.Errorf("Provider called %d times, want 2", mock.generateCalls)
+	}
+}
+
+func TestGuardedProviderAuthFailure(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	failures, err := OpenFailureCache(dbPath)
+	if err != nil {
+		t.Fatalf("OpenFailureCache() error = %v", err)
+	}
+	mock := &MockTTSProvider{name: "elevenlabs"}
+	guarded := NewGuardedProvider(mock, dbPath, "old-key", time.Hour, logger.NewDefaultLogger())
+	if err := failures.Record(ctx, "elevenlabs", guarded.failureKey(tts.ScopeAuth, tts.GenerateRequest{}), "invalid API key"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	_ = failures.Close()
+
+	if _, err := guarded.Generate(ctx, tts.GenerateRequest{Voice: "any"}); err == nil || !strings.Contains(err.Error(), "invalid API key") {
+		t.Errorf("Generate() error = %v, want the cached auth failure", err)
+	}
+
+	// A corrected key is not refused because of the old one
+	guarded = NewGuardedProvider(mock, dbPath, "new-key", time.Hour, logger.NewDefaultLogger())
+	if _, err := guarded.Generate(ctx, tts.GenerateRequest{Voice: "any"}); err != nil {
+		t.Errorf("Generate() error = %v with new credentials", err)
+	}
+	if mock.generateCalls != 1 {
+		t.Errorf("Provider called %d times, want 1", mock.generateCalls)
+	}
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// GetModels retrieves cached models for a provider.
+// Returns nil if cache is expired or doesn't exist.
+func (c *VoiceCache) GetModels(ctx context.Context, provider string) ([]tts.Model, error) {
+	cutoff := time.Now().Add(-c.cacheDuration).Unix()
+
+	query := `
+	SELECT model_id, name, COALESCE(description, ''), COALESCE(languages, ''), COALESCE(max_characters, 0)
+	FROM models
+	WHERE provider = ? AND cached_at > ?
+	ORDER BY model_id
+	`
+
+	rows, err := c.db.QueryContext(ctx, query, provider, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cache: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var models []tts.Model
+	for rows.Next() {
+		var m tts.Model
+		var languages string
+		if err := rows.Scan(&m.ID, &m.Name, &m.Description, &languages, &m.MaxCharacters); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if languages != "" {
+			m.Languages = strings.Split(languages, ",")
+		}
+		models = append(models, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if len(models) == 0 {
+		if c.log != nil {
+			c.log.Debug(fmt.Sprintf("Model cache miss for provider: %s", provider))
+		}
+		return nil, nil
+	}
+
+	if c.log != nil {
+		c.log.Debug(fmt.Sprintf("Model cache hit for provider: %s (%d models)", provider, len(models)))
+	}
+	return models, nil
+}
+
+// SetModels stores models for a provider in the cache.
+func (c *VoiceCache) SetModels(ctx context.Context, provider string, models []tts.Model) error {
+	if c.log != nil {
+		c.log.Debug(fmt.Sprintf("Caching %d models for provider: %s", len(models), provider))
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Delete old entries for this provider
+	if _, err := tx.ExecContext(ctx, "DELETE FROM models WHERE provider = ?", provider); err != nil {
+		return fmt.Errorf("failed to delete old entries: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO models (provider, model_id, name, description, languages, max_characters, cached_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	now := time.Now().Unix()
+	for _, model := range models {
+		languages := strings.Join(model.Languages, ",")
+		if _, err := stmt.ExecContext(ctx, provider, model.ID, model.Name, model.Description, languages, model.MaxCharacters, now); err != nil {
+			return fmt.Errorf("failed to insert model: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
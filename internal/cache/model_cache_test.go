@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+func TestModelCache(t *testing.T) {
+	cache, err := NewVoiceCacheWithPath(filepath.Join(t.TempDir(), "test_cache.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	ctx := context.Background()
+	if models, err := cache.GetModels(ctx, "elevenlabs"); err != nil || models != nil {
+		t.Fatalf("GetModels() = %v, %v, want a cache miss", models, err)
+	}
+
+	testModels := []tts.Model{
+		{ID: "eleven_multilingual_v2", Name: "Eleven Multilingual v2", Languages: []string{"en", "de"}, MaxCharacters: 10000},
+		{ID: "eleven_monolingual_v1", Name: "Eleven English v1"},
+	}
+	if err := cache.SetModels(ctx, "elevenlabs", testModels); err != nil {
+		t.Fatalf("SetModels() error = %v", err)
+	}
+	// Voices and models of a provider are cached separately
+	if voices, _ := cache.Get(ctx, "elevenlabs"); voices != nil {
+		t.Errorf("Get() = %v, want no voices", voices)
+	}
+
+	models, err := cache.GetModels(ctx, "elevenlabs")
+	if err != nil {
+		t.Fatalf("GetModels() error = %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("GetModels() = %+v, want 2 models", models)
+	}
+	if models[0].ID != "eleven_monolingual_v1" || models[0].Languages != nil {
+		t.Errorf("first model = %+v", models[0])
+	}
+	if !slices.Equal(models[1].Languages, []string{"en", "de"}) || models[1].MaxCharacters != 10000 {
+		t.Errorf("second model = %+v", models[1])
+	}
+
+	// Setting replaces the cached models
+	if err := cache.SetModels(ctx, "elevenlabs", testModels[:1]); err != nil {
+		t.Fatalf("SetModels() error = %v", err)
+	}
+	if models, _ := cache.GetModels(ctx, "elevenlabs"); len(models) != 1 {
+		t.Errorf("GetModels() = %+v, want 1 model", models)
+	}
+}
+
+func TestModelCacheExpiration(t *testing.T) {
+	cache, err := NewVoiceCacheWithPath(filepath.Join(t.TempDir(), "test_cache.db"), time.Nanosecond)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	ctx := context.Background()
+	if err := cache.SetModels(ctx, "elevenlabs", []tts.Model{{ID: "m", Name: "M"}}); err != nil {
+		t.Fatalf("SetModels() error = %v", err)
+	}
+	time.Sleep(time.Second + 10*time.Millisecond)
+	if models, err := cache.GetModels(ctx, "elevenlabs"); err != nil || models != nil {
+		t.Errorf("GetModels() = %v, %v, want an expired cache", models, err)
+	}
+}
@@ -4,9 +4,10 @@
 // Key features:
 //   - SQLite-based persistent storage (~/.md2audio/voice_cache.db)
 //   - 30-day cache duration (configurable)
-//   - Provider-specific voice caching
+//   - Provider-specific voice and model caching
 //   - Cache refresh and expiration handling
 //   - JSON export functionality
+//   - Hard failure caching (invalid voice, rejected key) with a cool-down
 //
 // The cache significantly improves performance when listing voices from
 // API-based providers like ElevenLabs, and enables offline access to voice lists.
@@ -49,18 +50,27 @@ type VoiceCache struct {
 // NewVoiceCache creates a new voice cache with default settings.
 // The cache is stored in ~/.md2audio/voice_cache.db by default.
 func NewVoiceCache() (*VoiceCache, error) {
+	cachePath, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return NewVoiceCacheWithPath(cachePath, DefaultCacheDuration)
+}
+
+// DefaultPath returns the path of the md2audio SQLite database (~/.md2audio/voice_cache.db),
+// creating its directory if needed.
+func DefaultPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	cacheDir := filepath.Join(homeDir, DefaultCacheDir)
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	cachePath := filepath.Join(cacheDir, DefaultCacheFile)
-	return NewVoiceCacheWithPath(cachePath, DefaultCacheDuration)
+	return filepath.Join(cacheDir, DefaultCacheFile), nil
 }
 
 // NewVoiceCacheWithPath creates a new voice cache with a custom path.
@@ -99,11 +109,22 @@ func NewVoiceCacheWithPath(dbPath string, cacheDuration time.Duration) (*VoiceCa
 		description TEXT,
 		language TEXT,
 		gender TEXT,
+		quality TEXT,
 		cached_at INTEGER NOT NULL,
 		PRIMARY KEY (provider, voice_id)
 	);
 	CREATE INDEX IF NOT EXISTS idx_provider ON voices(provider);
 	CREATE INDEX IF NOT EXISTS idx_cached_at ON voices(cached_at);
+	CREATE TABLE IF NOT EXISTS models (
+		provider TEXT NOT NULL,
+		model_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		languages TEXT,
+		max_characters INTEGER,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (provider, model_id)
+	);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
@@ -111,12 +132,47 @@ func NewVoiceCacheWithPath(dbPath string, cacheDuration time.Duration) (*VoiceCa
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	// Caches created before voice quality was recorded lack the column
+	if err := addColumnIfMissing(db, "voices", "quality", "TEXT"); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	return &VoiceCache{
 		db:            db,
 		cacheDuration: cacheDuration,
 	}, nil
 }
 
+// addColumnIfMissing adds a column to an existing table.
+func addColumnIfMissing(db *sql.DB, table, column, columnType string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, ctype string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to read schema: %w", err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, columnType)); err != nil {
+		return fmt.Errorf("failed to add %s column: %w", column, err)
+	}
+	return nil
+}
+
 // Close closes the database connection.
 func (c *VoiceCache) Close() error {
 	if c.db != nil {
@@ -136,7 +192,7 @@ func (c *VoiceCache) Get(ctx context.Context, provider string) ([]tts.Voice, err
 	cutoff := time.Now().Add(-c.cacheDuration).Unix()
 
 	query := `
-	SELECT voice_id, name, description, language, gender
+	SELECT voice_id, name, description, language, gender, COALESCE(quality, '')
 	FROM voices
 	WHERE provider = ? AND cached_at > ?
 	ORDER BY name
@@ -151,7 +207,7 @@ func (c *VoiceCache) Get(ctx context.Context, provider string) ([]tts.Voice, err
 	var voices []tts.Voice
 	for rows.Next() {
 		var v tts.Voice
-		if err := rows.Scan(&v.ID, &v.Name, &v.Description, &v.Language, &v.Gender); err != nil {
+		if err := rows.Scan(&v.ID, &v.Name, &v.Description, &v.Language, &v.Gender, &v.Quality); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		voices = append(voices, v)
@@ -194,8 +250,8 @@ func (c *VoiceCache) Set(ctx context.Context, provider string, voices []tts.Voic
 
 	// Insert new entries
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO voices (provider, voice_id, name, description, language, gender, cached_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO voices (provider, voice_id, name, description, language, gender, quality, cached_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -204,7 +260,7 @@ func (c *VoiceCache) Set(ctx context.Context, provider string, voices []tts.Voic
 
 	now := time.Now().Unix()
 	for _, voice := range voices {
-		if _, err := stmt.ExecContext(ctx, provider, voice.ID, voice.Name, voice.Description, voice.Language, voice.Gender, now); err != nil {
+		if _, err := stmt.ExecContext(ctx, provider, voice.ID, voice.Name, voice.Description, voice.Language, voice.Gender, voice.Quality, now); err != nil {
 			return fmt.Errorf("failed to insert voice: %w", err)
 		}
 	}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
@@ -331,3 +332,67 @@ func TestIsExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestVoiceQuality(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_cache.db")
+	cache, err := NewVoiceCacheWithPath(dbPath, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	ctx := context.Background()
+	voices := []tts.Voice{{ID: "Ava (Premium)", Name: "Ava (Premium)", Language: "en_US", Gender: "female", Quality: "premium"}}
+	if err := cache.Set(ctx, "say", voices); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	got, err := cache.Get(ctx, "say")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Quality != "premium" || got[0].Gender != "female" {
+		t.Errorf("Get() = %+v", got)
+	}
+}
+
+func TestVoiceCacheMigratesQualityColumn(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old_cache.db")
+
+	// Create a cache with the schema used before voice quality was recorded
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+	CREATE TABLE voices (
+		provider TEXT NOT NULL,
+		voice_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		language TEXT,
+		gender TEXT,
+		cached_at INTEGER NOT NULL,
+		PRIMARY KEY (provider, voice_id)
+	);
+	INSERT INTO voices VALUES ('say', 'Kate', 'Kate', '', 'en_GB', '', strftime('%s', 'now'));
+	`)
+	_ = db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	cache, err := NewVoiceCacheWithPath(dbPath, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open old cache: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	voices, err := cache.Get(context.Background(), "say")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(voices) != 1 || voices[0].Name != "Kate" || voices[0].Quality != "" {
+		t.Errorf("Get() = %+v", voices)
+	}
+}
@@ -0,0 +1,137 @@
+// Package calibrate measures the actual speaking rate of TTS voices.
+// Voices speak faster or slower than the rate they are asked for, so -calibrate
+// generates a sample at several rates, measures the words per minute from the
+// audio duration, and stores the resulting curve per voice. Generated sections
+// also refine a learned profile of each voice over time. Timed sections then
+// request the rate that actually produces the speed they need, and durations
+// are estimated from the measured speed.
+//
+// Key features:
+//   - Sample generation at several requested rates
+//   - Calibration curves mapping requested rates to measured WPM
+//   - Learned per-voice profiles from generated sections
+//   - Rate and speed lookup by linear interpolation of the curve
+//   - Curves persisted in the SQLite database shared with the voice cache
+package calibrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// DefaultRates are the requested rates sampled by -calibrate, in words per minute
+var DefaultRates = []int{120, 160, 200, 240, 280, 320}
+
+// MaxProfileSamples is the number of recent observations averaged by learned profiles
+const MaxProfileSamples = 20
+
+// MinProfileWords is the word count below which generated sections are not
+// learned from, as pauses dominate the speed of very short texts
+const MinProfileWords = 8
+
+// SampleText is spoken at each calibration rate. It uses common words of
+// average length, so the measured rate carries over to typical scripts.
+const SampleText = "Welcome to this short calibration sample. We are measuring how fast this voice " +
+	"really speaks, so that timed sections of your scripts fit their target length. " +
+	"The text uses ordinary words and sentences, much like the narration of a product " +
+	"demo, a tutorial, or a short video about a new feature."
+
+// Point is a measured calibration sample.
+type Point struct {
+	Rate int     // Requested rate in words per minute (0 = the natural speed of providers without rate control)
+	WPM  float64 // Measured words per minute
+}
+
+// Curve maps requested rates to measured speaking rates, sorted by rate.
+type Curve []Point
+
+// RateFor returns the rate to request so the voice speaks at wpm words per
+// minute, interpolating linearly between measured points and scaling
+// proportionally beyond them. It returns false for an empty curve.
+func (c Curve) RateFor(wpm float64) (float64, bool) {
+	if len(c) == 0 || c[0].Rate <= 0 || wpm <= 0 {
+		return 0, false
+	}
+
+	first, last := c[0], c[len(c)-1]
+	switch {
+	case wpm <= first.WPM:
+		return float64(first.Rate) * wpm / first.WPM, true
+	case wpm >= last.WPM:
+		return float64(last.Rate) * wpm / last.WPM, true
+	}
+	for i := 1; i < len(c); i++ {
+		lo, hi := c[i-1], c[i]
+		if wpm <= hi.WPM && hi.WPM > lo.WPM {
+			fraction := (wpm - lo.WPM) / (hi.WPM - lo.WPM)
+			return float64(lo.Rate) + fraction*float64(hi.Rate-lo.Rate), true
+		}
+	}
+	return float64(last.Rate) * wpm / last.WPM, true
+}
+
+// WPMAt returns the measured speaking rate when rate is requested,
+// interpolating linearly between measured points and scaling proportionally
+// beyond them. A curve of the natural speed (rate 0) applies to every rate.
+// It returns false for an empty curve.
+func (c Curve) WPMAt(rate int) (float64, bool) {
+	switch {
+	case len(c) == 0:
+		return 0, false
+	case c[0].Rate <= 0:
+		return c[0].WPM, true
+	case rate <= 0:
+		return 0, false
+	}
+
+	first, last := c[0], c[len(c)-1]
+	switch {
+	case rate <= first.Rate:
+		return first.WPM * float64(rate) / float64(first.Rate), true
+	case rate >= last.Rate:
+		return last.WPM * float64(rate) / float64(last.Rate), true
+	}
+	for i := 1; i < len(c); i++ {
+		lo, hi := c[i-1], c[i]
+		if rate <= hi.Rate {
+			fraction := float64(rate-lo.Rate) / float64(hi.Rate-lo.Rate)
+			return lo.WPM + fraction*(hi.WPM-lo.WPM), true
+		}
+	}
+	return last.WPM, true
+}
+
+// Measure speaks SampleText with voice at each rate, writing the samples into
+// dir as format files, and returns the curve of measured speaking rates.
+func Measure(ctx context.Context, provider tts.Provider, voice string, rates []int, dir, format string) (Curve, error) {
+	words := utils.CountWords(SampleText)
+	curve := make(Curve, 0, len(rates))
+	for _, rate := range rates {
+		outputPath, err := provider.Generate(ctx, tts.GenerateRequest{
+			Text:       SampleText,
+			Voice:      voice,
+			Rate:       &rate,
+			Format:     format,
+			OutputPath: filepath.Join(dir, fmt.Sprintf("calibrate_%d.%s", rate, format)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the %d wpm sample: %w", rate, err)
+		}
+
+		duration, err := utils.GetAudioDuration(outputPath)
+		_ = os.Remove(outputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure the %d wpm sample: %w", rate, err)
+		}
+		curve = append(curve, Point{Rate: rate, WPM: utils.CalculateWPM(words, duration)})
+	}
+
+	slices.SortFunc(curve, func(a, b Point) int { return a.Rate - b.Rate })
+	return curve, nil
+}
@@ -0,0 +1,95 @@
+package calibrate
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestCurveRateFor(t *testing.T) {
+	// A voice speaking 10% faster at low rates and 20% slower at high rates
+	curve := Curve{{Rate: 100, WPM: 110}, {Rate: 200, WPM: 200}, {Rate: 300, WPM: 240}}
+
+	tests := []struct {
+		wpm  float64
+		want float64
+	}{
+		{wpm: 55, want: 50},
+		{wpm: 110, want: 100},
+		{wpm: 155, want: 150},
+		{wpm: 220, want: 250},
+		{wpm: 240, want: 300},
+		{wpm: 320, want: 400},
+	}
+	for _, tt := range tests {
+		got, ok := curve.RateFor(tt.wpm)
+		if !ok || math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("RateFor(%v) = (%v, %v), want %v", tt.wpm, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := Curve(nil).RateFor(150); ok {
+		t.Error("Expected an empty curve to give no rate")
+	}
+}
+
+func TestCurveWPMAt(t *testing.T) {
+	curve := Curve{{Rate: 100, WPM: 110}, {Rate: 200, WPM: 200}}
+	tests := []struct {
+		rate int
+		want float64
+	}{
+		{rate: 50, want: 55},
+		{rate: 150, want: 155},
+		{rate: 200, want: 200},
+		{rate: 300, want: 300},
+	}
+	for _, tt := range tests {
+		got, ok := curve.WPMAt(tt.rate)
+		if !ok || math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("WPMAt(%d) = (%v, %v), want %v", tt.rate, got, ok, tt.want)
+		}
+	}
+
+	// The natural speed of providers without rate control applies to every rate
+	natural := Curve{{Rate: 0, WPM: 165}}
+	if got, ok := natural.WPMAt(180); !ok || got != 165 {
+		t.Errorf("WPMAt() of a natural speed = (%v, %v), want 165", got, ok)
+	}
+	if _, ok := natural.RateFor(150); ok {
+		t.Error("Expected a natural speed to give no rate")
+	}
+}
+
+// fakeProvider writes silence lasting as long as the sample at a fixed speed factor
+type fakeProvider struct {
+	speed float64 // Measured WPM per requested WPM
+}
+
+func (p fakeProvider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	wpm := float64(*req.Rate) * p.speed
+	seconds := float64(utils.CountWords(req.Text)) / wpm * 60
+	return req.OutputPath, utils.WriteSilence(ctx, req.OutputPath, seconds)
+}
+
+func (fakeProvider) ListVoices(ctx context.Context) ([]tts.Voice, error) { return nil, nil }
+
+func (fakeProvider) Name() string { return "fake" }
+
+func TestMeasure(t *testing.T) {
+	curve, err := Measure(context.Background(), fakeProvider{speed: 1.25}, "Kate", []int{200, 100}, t.TempDir(), "wav")
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if len(curve) != 2 || curve[0].Rate != 100 || curve[1].Rate != 200 {
+		t.Fatalf("Measure() = %+v, want points at 100 and 200 wpm", curve)
+	}
+	for _, point := range curve {
+		if want := float64(point.Rate) * 1.25; math.Abs(point.WPM-want) > 1 {
+			t.Errorf("measured %.1f wpm at %d, want %.1f", point.WPM, point.Rate, want)
+		}
+	}
+}
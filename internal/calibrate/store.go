@@ -0,0 +1,185 @@
+package calibrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store reads and writes calibration curves.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the calibration store in the SQLite database at dbPath.
+func Open(dbPath string) (*Store, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// WAL mode lets the voice cache and calibrations share the database
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS voice_calibrations (
+		provider TEXT NOT NULL,
+		voice TEXT NOT NULL,
+		rate INTEGER NOT NULL,
+		wpm REAL NOT NULL,
+		calibrated_at INTEGER NOT NULL,
+		PRIMARY KEY (provider, voice, rate)
+	);
+
+	CREATE TABLE IF NOT EXISTS voice_profiles (
+		provider TEXT NOT NULL,
+		voice TEXT NOT NULL,
+		rate INTEGER NOT NULL,
+		wpm REAL NOT NULL,
+		samples INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (provider, voice, rate)
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Save replaces the calibration curve of a provider's voice.
+func (s *Store) Save(ctx context.Context, provider, voice string, curve Curve) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM voice_calibrations WHERE provider = ? AND voice = ?`, provider, voice); err != nil {
+		return fmt.Errorf("failed to clear calibration: %w", err)
+	}
+	now := time.Now().Unix()
+	for _, point := range curve {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO voice_calibrations (provider, voice, rate, wpm, calibrated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, provider, voice, point.Rate, point.WPM, now); err != nil {
+			return fmt.Errorf("failed to save calibration: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Load returns the calibration curve of a provider's voice, or nil if the
+// voice has not been calibrated.
+func (s *Store) Load(ctx context.Context, provider, voice string) (Curve, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT rate, wpm
+		FROM voice_calibrations
+		WHERE provider = ? AND voice = ?
+		ORDER BY rate
+	`, provider, voice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calibration: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var curve Curve
+	for rows.Next() {
+		var point Point
+		if err := rows.Scan(&point.Rate, &point.WPM); err != nil {
+			return nil, fmt.Errorf("failed to scan calibration: %w", err)
+		}
+		curve = append(curve, point)
+	}
+	return curve, rows.Err()
+}
+
+// Observe records the speaking rate measured for a generated section in the
+// learned profile of a provider's voice. Observations at the same requested
+// rate are averaged over the last MaxProfileSamples.
+func (s *Store) Observe(ctx context.Context, provider, voice string, rate int, wpm float64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO voice_profiles (provider, voice, rate, wpm, samples, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?)
+		ON CONFLICT (provider, voice, rate) DO UPDATE SET
+			wpm = wpm + (excluded.wpm - wpm) / MIN(samples + 1, ?),
+			samples = samples + 1,
+			updated_at = excluded.updated_at
+	`, provider, voice, rate, wpm, time.Now().Unix(), MaxProfileSamples)
+	if err != nil {
+		return fmt.Errorf("failed to record speaking rate: %w", err)
+	}
+	return nil
+}
+
+// Profile returns the learned profile of a provider's voice, or nil if no
+// sections have been measured yet.
+func (s *Store) Profile(ctx context.Context, provider, voice string) (Curve, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT rate, wpm
+		FROM voice_profiles
+		WHERE provider = ? AND voice = ?
+		ORDER BY rate
+	`, provider, voice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profile: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var curve Curve
+	for rows.Next() {
+		var point Point
+		if err := rows.Scan(&point.Rate, &point.WPM); err != nil {
+			return nil, fmt.Errorf("failed to scan profile: %w", err)
+		}
+		curve = append(curve, point)
+	}
+	return curve, rows.Err()
+}
+
+// Curve returns the calibration curve of a provider's voice, or its learned
+// profile when it has not been calibrated.
+func (s *Store) Curve(ctx context.Context, provider, voice string) (Curve, error) {
+	curve, err := s.Load(ctx, provider, voice)
+	if err != nil || len(curve) > 0 {
+		return curve, err
+	}
+	return s.Profile(ctx, provider, voice)
+}
+
+// Learner records the measured speaking rates of a provider's voice in the
+// SQLite database at DBPath, opening it for each observation so concurrent
+// runs do not hold it open.
+type Learner struct {
+	DBPath   string
+	Provider string
+	Voice    string
+}
+
+// Observe records a measured speaking rate at a requested rate.
+func (l Learner) Observe(ctx context.Context, rate int, wpm float64) error {
+	store, err := Open(l.DBPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+	return store.Observe(ctx, l.Provider, l.Voice, rate, wpm)
+}
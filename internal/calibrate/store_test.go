@@ -0,0 +1,80 @@
+package calibrate
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	if curve, err := store.Load(ctx, "say", "Kate"); err != nil || curve != nil {
+		t.Fatalf("Load() before calibration = (%v, %v), want (nil, nil)", curve, err)
+	}
+
+	first := Curve{{Rate: 100, WPM: 105}, {Rate: 200, WPM: 190}, {Rate: 300, WPM: 260}}
+	if err := store.Save(ctx, "say", "Kate", first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second := Curve{{Rate: 150, WPM: 160}}
+	if err := store.Save(ctx, "say", "Kate", second); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(ctx, "espeak", "Kate", first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	curve, err := store.Load(ctx, "say", "Kate")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !slices.Equal(curve, second) {
+		t.Errorf("Load() = %+v, want the latest calibration %+v", curve, second)
+	}
+}
+
+func TestStoreProfile(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	for _, wpm := range []float64{200, 220} {
+		if err := store.Observe(ctx, "say", "Kate", 180, wpm); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+	if err := store.Observe(ctx, "say", "Kate", 240, 250); err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+
+	want := Curve{{Rate: 180, WPM: 210}, {Rate: 240, WPM: 250}}
+	profile, err := store.Profile(ctx, "say", "Kate")
+	if err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+	if !slices.Equal(profile, want) {
+		t.Errorf("Profile() = %+v, want the averaged observations %+v", profile, want)
+	}
+
+	// A calibration takes precedence over the learned profile
+	if curve, err := store.Curve(ctx, "say", "Kate"); err != nil || !slices.Equal(curve, want) {
+		t.Errorf("Curve() without calibration = (%+v, %v), want the profile", curve, err)
+	}
+	calibration := Curve{{Rate: 200, WPM: 190}}
+	if err := store.Save(ctx, "say", "Kate", calibration); err != nil {
+		t.Fatal(err)
+	}
+	if curve, err := store.Curve(ctx, "say", "Kate"); err != nil || !slices.Equal(curve, calibration) {
+		t.Errorf("Curve() with calibration = (%+v, %v), want the calibration", curve, err)
+	}
+}
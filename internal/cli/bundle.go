@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/bundle"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/version"
+)
+
+// CreateBundle packages the output directory into the -bundle archive.
+func CreateBundle(cfg config.Config, log logger.LoggerInterface) error {
+	root := parser.OutputRoot(cfg.OutputDir)
+	n, err := bundle.Create(cfg.Bundle, root, bundleInfo(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	log.Blank()
+	log.Success(fmt.Sprintf("Bundled %d file(s) from %s into %s", n, root, cfg.Bundle))
+	return nil
+}
+
+// HandleUnbundle extracts the -unbundle archive into the output directory.
+func HandleUnbundle(cfg config.Config, log logger.LoggerInterface) error {
+	info, n, err := bundle.Extract(cfg.Commands.Unbundle, cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	log.Success(fmt.Sprintf("Extracted %d file(s) into %s", n, cfg.OutputDir))
+	if info.Provider != "" {
+		log.Faint(fmt.Sprintf("  Generated %s with %s (%s), md2audio %s",
+			info.Created.Local().Format("2006-01-02 15:04"), info.Provider, describeVoice(info), info.Version))
+	}
+	return nil
+}
+
+// bundleInfo returns the bundle info for the settings of a run.
+func bundleInfo(cfg config.Config) bundle.Info {
+	info := bundle.Info{
+		Version:  version.GetVersion(),
+		Created:  time.Now().UTC(),
+		Provider: cfg.Provider,
+		Format:   cfg.OutputFormat(),
+	}
+	if cfg.Provider == "elevenlabs" {
+		info.Voice = cfg.ElevenLabs.VoiceID
+		info.Model = cfg.ElevenLabs.Model
+	} else {
+		info.Voice = cfg.Say.Voice
+		info.Rate = cfg.Say.Rate
+	}
+	return info
+}
+
+// describeVoice returns the voice and model or rate recorded in a bundle.
+func describeVoice(info bundle.Info) string {
+	parts := []string{"voice " + info.Voice}
+	if info.Model != "" {
+		parts = append(parts, "model "+info.Model)
+	}
+	if info.Rate != 0 {
+		parts = append(parts, fmt.Sprintf("rate %d", info.Rate))
+	}
+	return strings.Join(parts, ", ")
+}
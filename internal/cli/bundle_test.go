@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "section_01_intro.mp3"), []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "narration.tar.gz")
+
+	cfg := config.Config{
+		OutputDir:  outputDir,
+		Bundle:     archive,
+		Provider:   "elevenlabs",
+		Format:     "mp3",
+		ElevenLabs: config.ElevenLabsConfig{VoiceID: "voice-123", Model: "eleven_multilingual_v2", APIKey: "secret"},
+	}
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := CreateBundle(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("CreateBundle() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	dest := t.TempDir()
+	cfg = config.Config{OutputDir: dest, Commands: config.CommandFlags{Unbundle: archive}}
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := HandleUnbundle(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("HandleUnbundle() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "Extracted 1 file(s)") || !strings.Contains(output, "voice voice-123, model eleven_multilingual_v2") {
+		t.Errorf("Unexpected output: %s", output)
+	}
+	if strings.Contains(output, "secret") {
+		t.Error("bundle must not contain the API key")
+	}
+	if _, err := os.Stat(filepath.Join(dest, "section_01_intro.mp3")); err != nil {
+		t.Errorf("audio file not extracted: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/indaco/md2audio/internal/calibrate"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// HandleCalibrate measures the speaking rate of the say or espeak voice at
+// several requested rates and stores the calibration curve used for timed sections.
+func HandleCalibrate(cfg config.Config, log logger.LoggerInterface) error {
+	if cfg.HistoryDB == "" {
+		return fmt.Errorf("calibration database not available")
+	}
+	provider, err := CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating TTS provider: %w", err)
+	}
+	if name := provider.Name(); name != "say" && name != "espeak" && name != "festival" {
+		return fmt.Errorf("-calibrate supports the say, espeak, and festival providers, not %s", name)
+	}
+
+	// say writes AIFF natively; espeak and festival write WAV
+	format := "wav"
+	if provider.Name() == "say" {
+		format = "aiff"
+	}
+
+	tmpDir, err := os.MkdirTemp("", "md2audio-calibrate-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	voice := cfg.Say.Voice
+	log.Info(fmt.Sprintf("Calibrating %s voice %q at %d rates...", provider.Name(), voice, len(calibrate.DefaultRates)))
+	ctx := context.Background()
+	curve, err := calibrate.Measure(ctx, provider, voice, calibrate.DefaultRates, tmpDir, format)
+	if err != nil {
+		return err
+	}
+
+	log.Blank()
+	for _, point := range curve {
+		log.Faint(fmt.Sprintf("Requested %3d wpm -> measured %5.1f wpm (x%.2f)", point.Rate, point.WPM, point.WPM/float64(point.Rate)))
+	}
+
+	store, err := calibrate.Open(cfg.HistoryDB)
+	if err != nil {
+		return fmt.Errorf("failed to open calibrations: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.Save(ctx, provider.Name(), voice, curve); err != nil {
+		return err
+	}
+
+	log.Blank()
+	log.Success(fmt.Sprintf("Saved the calibration of %q; timed sections now use it to pick speaking rates", voice))
+	return nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+func TestHandleCalibrateErrors(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	if err := HandleCalibrate(config.Config{Provider: "say"}, log); err == nil {
+		t.Error("Expected an error without a calibration database")
+	}
+
+	cfg := config.Config{
+		Provider:   "elevenlabs",
+		HistoryDB:  filepath.Join(t.TempDir(), "test.db"),
+		ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key"},
+	}
+	err := HandleCalibrate(cfg, log)
+	if err == nil || !strings.Contains(err.Error(), "espeak, and festival") {
+		t.Errorf("HandleCalibrate() error = %v, want an unsupported provider error", err)
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/dedup"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// HandleDedup reports audio files with identical content in the output directory
+// and, with -dedup-link, replaces duplicate copies with hard links.
+func HandleDedup(cfg config.Config, log logger.LoggerInterface) error {
+	info, err := os.Stat(cfg.OutputDir)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("output directory not found: %s", cfg.OutputDir)
+	}
+
+	log.Info("Scanning for duplicate audio:", cfg.OutputDir)
+	groups, err := dedup.Find(cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		log.Success("No duplicate audio files found")
+		return nil
+	}
+
+	log.Blank()
+	if err := dedup.WriteReport(os.Stdout, cfg.OutputDir, groups); err != nil {
+		return err
+	}
+
+	if !cfg.Commands.DedupLink {
+		log.Blank()
+		log.Hint("Use -dedup-link to replace duplicates with hard links")
+		return nil
+	}
+
+	var saved int64
+	for _, group := range groups {
+		n, err := dedup.Link(group)
+		saved += n
+		if err != nil {
+			return fmt.Errorf("%w (reclaimed %s before the error)", err, dedup.FormatBytes(saved))
+		}
+	}
+	log.Blank()
+	log.Success(fmt.Sprintf("Replaced duplicates with hard links, reclaimed %s", dedup.FormatBytes(saved)))
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestHandleDedup(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("same audio"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		commands config.CommandFlags
+		want     string
+	}{
+		{name: "report", commands: config.CommandFlags{DedupReport: true}, want: "Use -dedup-link"},
+		{name: "link", commands: config.CommandFlags{DedupReport: true, DedupLink: true}, want: "reclaimed 10 B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{OutputDir: dir, Commands: tt.commands}
+			output, err := testhelpers.CaptureStdout(func() {
+				if err := HandleDedup(cfg, logger.NewDefaultLogger()); err != nil {
+					t.Errorf("HandleDedup() error = %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if !strings.Contains(output, "1 duplicate group(s)") || !strings.Contains(output, tt.want) {
+				t.Errorf("Expected %q in output:\n%s", tt.want, output)
+			}
+		})
+	}
+
+	a, _ := os.Stat(filepath.Join(dir, "a.mp3"))
+	b, _ := os.Stat(filepath.Join(dir, "b.mp3"))
+	if !os.SameFile(a, b) {
+		t.Error("Expected b.mp3 to be linked to a.mp3")
+	}
+}
+
+func TestHandleDedupMissingDirectory(t *testing.T) {
+	cfg := config.Config{OutputDir: filepath.Join(t.TempDir(), "missing"), Commands: config.CommandFlags{DedupReport: true}}
+	err := HandleDedup(cfg, logger.NewDefaultLogger())
+	if err == nil || !strings.Contains(err.Error(), "output directory not found") {
+		t.Errorf("HandleDedup() error = %v, want output directory not found", err)
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/history"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// HandleHistoryCommands handles the run history commands (history, stats).
+func HandleHistoryCommands(cfg config.Config, log logger.LoggerInterface) error {
+	if cfg.HistoryDB == "" {
+		return fmt.Errorf("run history database not available")
+	}
+
+	store, err := history.Open(cfg.HistoryDB)
+	if err != nil {
+		return fmt.Errorf("failed to open run history: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	if cfg.Commands.Stats {
+		return ShowStats(ctx, store, log)
+	}
+	return ShowHistory(ctx, store, cfg.Commands.HistoryLimit, log)
+}
+
+// ShowHistory lists the most recent runs.
+func ShowHistory(ctx context.Context, store *history.Store, limit int, log logger.LoggerInterface) error {
+	runs, err := store.Recent(ctx, limit)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		log.Info("No runs recorded yet")
+		return nil
+	}
+
+	log.Info(fmt.Sprintf("Last %d run(s):", len(runs)))
+	log.Blank()
+	return history.WriteRuns(os.Stdout, runs)
+}
+
+// ShowStats shows monthly usage per provider.
+func ShowStats(ctx context.Context, store *history.Store, log logger.LoggerInterface) error {
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		log.Info("No runs recorded yet")
+		return nil
+	}
+
+	log.Info("Usage per provider (characters approximate provider credits):")
+	log.Blank()
+	return history.WriteStats(os.Stdout, stats)
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/history"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestHandleHistoryCommands(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	store, err := history.Open(dbPath)
+	if err != nil {
+		t.Fatalf("history.Open() error = %v", err)
+	}
+	run := history.Run{StartedAt: time.Now(), Provider: "elevenlabs", Mode: "file", Input: "guide.md", Sections: 2, Generated: 2, Characters: 321}
+	if _, err := store.Record(context.Background(), run); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	_ = store.Close()
+
+	tests := []struct {
+		name     string
+		commands config.CommandFlags
+		want     string
+	}{
+		{name: "history", commands: config.CommandFlags{History: true, HistoryLimit: 5}, want: "guide.md"},
+		{name: "stats", commands: config.CommandFlags{Stats: true}, want: "elevenlabs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{HistoryDB: dbPath, Commands: tt.commands}
+			output, err := testhelpers.CaptureStdout(func() {
+				if err := HandleHistoryCommands(cfg, logger.NewDefaultLogger()); err != nil {
+					t.Errorf("HandleHistoryCommands() error = %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if !strings.Contains(output, tt.want) || !strings.Contains(output, "321") {
+				t.Errorf("Expected %q in output:\n%s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestHandleHistoryCommandsEmpty(t *testing.T) {
+	cfg := config.Config{HistoryDB: filepath.Join(t.TempDir(), "history.db"), Commands: config.CommandFlags{History: true}}
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := HandleHistoryCommands(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("HandleHistoryCommands() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+	if !strings.Contains(output, "No runs recorded yet") {
+		t.Errorf("Expected empty history message, got:\n%s", output)
+	}
+
+	if err := HandleHistoryCommands(config.Config{}, logger.NewDefaultLogger()); err == nil {
+		t.Error("Expected error without a history database")
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/indaco/md2audio/internal/cache"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+)
+
+// IsVoiceManagement reports whether an ElevenLabs voice management command
+// (-clone-voice, -edit-voice, or -delete-voice) was given.
+func IsVoiceManagement(cmds config.CommandFlags) bool {
+	return cmds.CloneVoice != "" || cmds.EditVoice != "" || cmds.DeleteVoice != ""
+}
+
+// HandleVoiceManagement creates, edits, or deletes an ElevenLabs voice, then
+// clears the cached ElevenLabs voice list so -list-voices shows the change.
+func HandleVoiceManagement(cfg config.Config, voiceCache *cache.VoiceCache, log logger.LoggerInterface) error {
+	if err := checkVoiceManagement(cfg.Commands); err != nil {
+		return err
+	}
+
+	cfg.Provider = "elevenlabs"
+	provider, err := CreateProvider(cfg)
+	if err != nil {
+		return err
+	}
+	client := provider.(*elevenlabs.Client)
+	client.SetLogger(log)
+
+	ctx := context.Background()
+	cmds := cfg.Commands
+	upload := elevenlabs.VoiceUpload{Name: cmds.VoiceName, Description: cmds.VoiceDescription, Files: cmds.VoiceSamples}
+	switch {
+	case cmds.CloneVoice != "":
+		upload.Name = cmds.CloneVoice
+		log.Info(fmt.Sprintf("Cloning voice %q from %d sample(s)...", upload.Name, len(upload.Files)))
+		voiceID, err := client.CreateVoice(ctx, upload)
+		if err != nil {
+			return fmt.Errorf("failed to clone voice: %w", err)
+		}
+		log.Success(fmt.Sprintf("Created voice %q with ID %s", upload.Name, voiceID))
+		log.Hint(fmt.Sprintf("Generate with: -provider elevenlabs -elevenlabs-voice-id %s", voiceID))
+	case cmds.EditVoice != "":
+		if err := client.EditVoice(ctx, cmds.EditVoice, upload); err != nil {
+			return fmt.Errorf("failed to edit voice: %w", err)
+		}
+		log.Success(fmt.Sprintf("Updated voice %s", cmds.EditVoice))
+	default:
+		if err := client.DeleteVoice(ctx, cmds.DeleteVoice); err != nil {
+			return fmt.Errorf("failed to delete voice: %w", err)
+		}
+		log.Success(fmt.Sprintf("Deleted voice %s", cmds.DeleteVoice))
+	}
+
+	if voiceCache != nil {
+		if err := voiceCache.Clear(ctx, client.Name()); err != nil {
+			log.Warning(fmt.Sprintf("Failed to clear the voice cache: %v", err))
+		}
+	}
+	return nil
+}
+
+// checkVoiceManagement validates the voice management flags before any request is sent
+func checkVoiceManagement(cmds config.CommandFlags) error {
+	given := 0
+	for _, value := range []string{cmds.CloneVoice, cmds.EditVoice, cmds.DeleteVoice} {
+		if value != "" {
+			given++
+		}
+	}
+	switch {
+	case given > 1:
+		return fmt.Errorf("use only one of -clone-voice, -edit-voice, and -delete-voice")
+	case cmds.CloneVoice != "" && len(cmds.VoiceSamples) == 0:
+		return fmt.Errorf("-clone-voice requires at least one -voice-sample file")
+	case cmds.EditVoice != "" && cmds.VoiceName == "" && cmds.VoiceDescription == "" && len(cmds.VoiceSamples) == 0:
+		return fmt.Errorf("-edit-voice requires -voice-name, -voice-description, or -voice-sample")
+	case cmds.DeleteVoice != "" && (len(cmds.VoiceSamples) > 0 || cmds.VoiceName != ""):
+		return fmt.Errorf("-delete-voice does not take -voice-sample or -voice-name")
+	case cmds.CloneVoice != "" && cmds.VoiceName != "":
+		return fmt.Errorf("-clone-voice takes the voice name as its value, not -voice-name")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+)
+
+func TestCheckVoiceManagement(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmds    config.CommandFlags
+		wantErr string
+	}{
+		{name: "clone", cmds: config.CommandFlags{CloneVoice: "Narrator", VoiceSamples: []string{"a.mp3"}}},
+		{name: "edit description", cmds: config.CommandFlags{EditVoice: "voice-123", VoiceDescription: "Warm"}},
+		{name: "delete", cmds: config.CommandFlags{DeleteVoice: "voice-123"}},
+		{name: "clone without samples", cmds: config.CommandFlags{CloneVoice: "Narrator"}, wantErr: "at least one -voice-sample"},
+		{name: "clone with voice name", cmds: config.CommandFlags{CloneVoice: "Narrator", VoiceName: "Host", VoiceSamples: []string{"a.mp3"}}, wantErr: "not -voice-name"},
+		{name: "edit without changes", cmds: config.CommandFlags{EditVoice: "voice-123"}, wantErr: "-edit-voice requires"},
+		{name: "delete with samples", cmds: config.CommandFlags{DeleteVoice: "voice-123", VoiceSamples: []string{"a.mp3"}}, wantErr: "does not take"},
+		{name: "several commands", cmds: config.CommandFlags{EditVoice: "voice-123", DeleteVoice: "voice-123"}, wantErr: "use only one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsVoiceManagement(tt.cmds) {
+				t.Error("IsVoiceManagement() = false")
+			}
+			err := checkVoiceManagement(tt.cmds)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkVoiceManagement() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkVoiceManagement() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if IsVoiceManagement(config.CommandFlags{VoiceSamples: []string{"a.mp3"}}) {
+		t.Error("IsVoiceManagement() = true without a command")
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/cache"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+)
+
+// HandleModelCommands lists the ElevenLabs models (-list-models), using the
+// cache unless -refresh-cache is set, and only those speaking -voice-language
+// when given.
+func HandleModelCommands(cfg config.Config, voiceCache *cache.VoiceCache, log logger.LoggerInterface) error {
+	if cfg.Provider != "elevenlabs" {
+		return fmt.Errorf("-list-models is only supported by the elevenlabs provider")
+	}
+	provider, err := CreateProvider(cfg)
+	if err != nil {
+		return err
+	}
+	client := provider.(*elevenlabs.Client)
+	client.SetLogger(log)
+
+	ctx := context.Background()
+	models, err := getModels(ctx, client, voiceCache, cfg.Commands.RefreshCache, log)
+	if err != nil {
+		return err
+	}
+
+	if language := cfg.Commands.VoiceLanguage; language != "" {
+		filtered := FilterModels(models, language)
+		log.Hint(fmt.Sprintf("Showing %d of %d models speaking %s", len(filtered), len(models), language))
+		log.Blank()
+		models = filtered
+	}
+	displayModels(models, cfg.ElevenLabs.Model, log)
+	return nil
+}
+
+// getModels retrieves models either from cache or from the API, caching them.
+func getModels(ctx context.Context, client *elevenlabs.Client, voiceCache *cache.VoiceCache, refreshCache bool, log logger.LoggerInterface) ([]tts.Model, error) {
+	if !refreshCache {
+		models, err := voiceCache.GetModels(ctx, client.Name())
+		if err != nil {
+			log.Warning(fmt.Sprintf("Failed to read model cache: %v", err))
+		} else if models != nil {
+			log.Hint("(using cached models - use -refresh-cache to update)")
+			log.Blank()
+			return models, nil
+		}
+	}
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	if err := voiceCache.SetModels(ctx, client.Name(), models); err != nil {
+		log.Warning(fmt.Sprintf("Failed to cache models: %v", err))
+	}
+	return models, nil
+}
+
+// FilterModels returns the models speaking a language, given as a language
+// code or locale (e.g., "de" or "de-DE").
+func FilterModels(models []tts.Model, language string) []tts.Model {
+	want := strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	want, _, _ = strings.Cut(want, "-")
+
+	filtered := make([]tts.Model, 0, len(models))
+	for _, model := range models {
+		for _, lang := range model.Languages {
+			if strings.EqualFold(lang, want) {
+				filtered = append(filtered, model)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// displayModels displays the model list, marking the model selected with -elevenlabs-model.
+func displayModels(models []tts.Model, selected string, log logger.LoggerInterface) {
+	log.Info("Available models for elevenlabs provider:")
+	log.Blank()
+
+	log.Default(fmt.Sprintf("  %-32s %-28s %-10s %s", "ID", "Name", "Max chars", "Languages"))
+	log.Default(strings.Repeat("-", 100))
+	for _, model := range models {
+		marker := " "
+		if model.ID == selected {
+			marker = "*"
+		}
+		maxCharacters := "-"
+		if model.MaxCharacters > 0 {
+			maxCharacters = fmt.Sprintf("%d", model.MaxCharacters)
+		}
+		log.Default(fmt.Sprintf("%s %-32s %-28s %-10s %s", marker, model.ID, model.Name, maxCharacters, formatLanguages(model.Languages)))
+	}
+	log.Blank()
+	log.Hint("* = model selected with -elevenlabs-model")
+}
+
+// formatLanguages lists the first language codes of a model and counts the rest.
+func formatLanguages(languages []string) string {
+	const shown = 8
+	if len(languages) <= shown {
+		return strings.Join(languages, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(languages[:shown], ", "), len(languages)-shown)
+}
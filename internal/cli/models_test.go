@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+func TestFilterModels(t *testing.T) {
+	models := []tts.Model{
+		{ID: "eleven_multilingual_v2", Languages: []string{"en", "de", "pt"}},
+		{ID: "eleven_monolingual_v1", Languages: []string{"en"}},
+		{ID: "unknown_languages"},
+	}
+
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{language: "de", want: []string{"eleven_multilingual_v2"}},
+		{language: "en-GB", want: []string{"eleven_multilingual_v2", "eleven_monolingual_v1"}},
+		{language: "PT_br", want: []string{"eleven_multilingual_v2"}},
+		{language: "ja", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, model := range FilterModels(models, tt.language) {
+			got = append(got, model.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("FilterModels(%q) = %v, want %v", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestFormatLanguages(t *testing.T) {
+	if got := formatLanguages([]string{"en", "de"}); got != "en, de" {
+		t.Errorf("formatLanguages() = %q", got)
+	}
+	many := []string{"en", "de", "fr", "es", "it", "pt", "pl", "nl", "ja", "zh"}
+	if got := formatLanguages(many); got != "en, de, fr, es, it, pt, pl, nl (+2 more)" {
+		t.Errorf("formatLanguages() = %q", got)
+	}
+}
+
+func TestHandleModelCommandsProvider(t *testing.T) {
+	cfg := config.Config{Provider: "say"}
+	err := HandleModelCommands(cfg, nil, logger.NewDefaultLogger())
+	if err == nil || !strings.Contains(err.Error(), "elevenlabs") {
+		t.Errorf("HandleModelCommands() error = %v, want an unsupported provider error", err)
+	}
+}
@@ -12,15 +12,26 @@ package cli
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/indaco/md2audio/internal/cache"
 	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/httpclient"
 	"github.com/indaco/md2audio/internal/logger"
 	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/tts/coqui"
+	"github.com/indaco/md2audio/internal/tts/customhttp"
+	"github.com/indaco/md2audio/internal/tts/edge"
 	"github.com/indaco/md2audio/internal/tts/elevenlabs"
 	"github.com/indaco/md2audio/internal/tts/espeak"
+	"github.com/indaco/md2audio/internal/tts/external"
+	"github.com/indaco/md2audio/internal/tts/festival"
+	"github.com/indaco/md2audio/internal/tts/marytts"
+	"github.com/indaco/md2audio/internal/tts/playht"
 	"github.com/indaco/md2audio/internal/tts/say"
+	"github.com/indaco/md2audio/internal/tts/watson"
 	"github.com/indaco/md2audio/internal/utils"
 )
 
@@ -44,7 +55,8 @@ func HandleVoiceCommands(cfg config.Config, voiceCache *cache.VoiceCache, log lo
 	}
 
 	if cfg.Commands.ListVoices {
-		return ListVoices(ctx, cachedProvider, provider.Name(), cfg.Commands.RefreshCache, log)
+		filter := VoiceFilter{Gender: cfg.Commands.VoiceGender, Language: cfg.Commands.VoiceLanguage}
+		return ListVoices(ctx, cachedProvider, provider.Name(), cfg.Commands.RefreshCache, filter, log)
 	}
 
 	return nil
@@ -58,25 +70,154 @@ func CreateProvider(cfg config.Config) (tts.Provider, error) {
 		provider = config.GetDefaultProvider()
 	}
 
+	// Enforced here too, so no code path can create a cloud provider in local-only mode
+	if cfg.LocalOnly && cfg.IsCloud(provider) {
+		return nil, fmt.Errorf("-local-only: refusing to create the cloud provider %q", provider)
+	}
+
 	switch provider {
 	case "say":
-		return say.NewProvider()
+		sayProvider, err := say.NewProvider()
+		if err != nil {
+			return nil, err
+		}
+		sayProvider.SetKeepIntermediates(cfg.Say.KeepIntermediates)
+		sayProvider.SetTimeout(cfg.ProviderTimeout(provider))
+		return sayProvider, nil
 	case "espeak":
-		return espeak.NewProvider()
+		return espeak.NewProvider(espeak.Config{
+			Pitch:     cfg.Espeak.Pitch,
+			Amplitude: cfg.Espeak.Amplitude,
+			WordGap:   cfg.Espeak.WordGap,
+			Variant:   cfg.Espeak.Variant,
+			Timeout:   cfg.ProviderTimeout(provider),
+		})
+	case "festival":
+		festivalProvider, err := festival.NewProvider()
+		if err != nil {
+			return nil, err
+		}
+		festivalProvider.SetTimeout(cfg.ProviderTimeout(provider))
+		return festivalProvider, nil
 	case "elevenlabs":
+		httpClient, err := apiHTTPClient(cfg, provider)
+		if err != nil {
+			return nil, err
+		}
 		return elevenlabs.NewClient(elevenlabs.Config{
 			APIKey:          cfg.ElevenLabs.APIKey,
+			HTTPClient:      httpClient,
+			Retry:           cfg.HTTP.Retry,
+			Model:           cfg.ElevenLabs.Model,
+			Stream:          cfg.ElevenLabs.Stream,
+			Seed:            uint32(cfg.ElevenLabs.Seed),
+			Deterministic:   cfg.ElevenLabs.Deterministic,
 			Stability:       cfg.ElevenLabs.VoiceSettings.Stability,
 			SimilarityBoost: cfg.ElevenLabs.VoiceSettings.SimilarityBoost,
 			Style:           cfg.ElevenLabs.VoiceSettings.Style,
 			UseSpeakerBoost: cfg.ElevenLabs.VoiceSettings.UseSpeakerBoost,
 			Speed:           cfg.ElevenLabs.VoiceSettings.Speed,
 		})
+	case "coqui":
+		return coqui.NewProvider(coqui.Config{
+			URL:        cfg.Coqui.URL,
+			SpeakerWav: cfg.Coqui.SpeakerWav,
+			Language:   cfg.Coqui.Language,
+			HTTPClient: localHTTPClient(cfg, provider),
+			Retry:      cfg.HTTP.Retry,
+		})
+	case "marytts":
+		return marytts.NewProvider(marytts.Config{
+			URL:        cfg.MaryTTS.URL,
+			Locale:     cfg.MaryTTS.Locale,
+			HTTPClient: localHTTPClient(cfg, provider),
+			Retry:      cfg.HTTP.Retry,
+		})
+	case "edge":
+		httpClient, err := apiHTTPClient(cfg, provider)
+		if err != nil {
+			return nil, err
+		}
+		return edge.NewProvider(edge.Config{
+			Rate:       cfg.Edge.Rate,
+			Pitch:      cfg.Edge.Pitch,
+			HTTPClient: httpClient,
+			Retry:      cfg.HTTP.Retry,
+		})
+	case customhttp.Name:
+		if cfg.CustomHTTP == nil {
+			return nil, fmt.Errorf("the custom-http provider requires a valid -custom-http-config file")
+		}
+		httpClient, err := apiHTTPClient(cfg, provider)
+		if err != nil {
+			return nil, err
+		}
+		service := *cfg.CustomHTTP
+		service.HTTPClient = httpClient
+		service.Retry = cfg.HTTP.Retry
+		return customhttp.NewProvider(service)
+	case "playht":
+		httpClient, err := apiHTTPClient(cfg, provider)
+		if err != nil {
+			return nil, err
+		}
+		return playht.NewProvider(playht.Config{
+			UserID:     cfg.PlayHT.UserID,
+			APIKey:     cfg.PlayHT.APIKey,
+			Speed:      cfg.PlayHT.Speed,
+			Emotion:    cfg.PlayHT.Emotion,
+			HTTPClient: httpClient,
+			Retry:      cfg.HTTP.Retry,
+		})
+	case "watson":
+		httpClient, err := apiHTTPClient(cfg, provider)
+		if err != nil {
+			return nil, err
+		}
+		return watson.NewProvider(watson.Config{
+			APIKey:     cfg.Watson.APIKey,
+			URL:        cfg.Watson.URL,
+			HTTPClient: httpClient,
+			Retry:      cfg.HTTP.Retry,
+		})
 	default:
+		if ext, ok := cfg.ExternalProvider(provider); ok {
+			externalProvider, err := external.NewProvider(ext)
+			if err != nil {
+				return nil, err
+			}
+			externalProvider.SetTimeout(cfg.ProviderTimeout(provider))
+			return externalProvider, nil
+		}
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
 }
 
+// apiHTTPClient returns the HTTP client of an API-based provider, with the
+// network options and timeout of the run
+func apiHTTPClient(cfg config.Config, provider string) (*http.Client, error) {
+	httpClient, err := httpclient.New(httpclient.Options{
+		Proxy:    cfg.HTTP.Proxy,
+		CABundle: cfg.HTTP.CABundle,
+		Headers:  cfg.HTTP.Headers,
+		Timeout:  cfg.ProviderTimeout(provider),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error configuring HTTP client: %w", err)
+	}
+	return httpClient, nil
+}
+
+// localHTTPClient returns the HTTP client of a local TTS server with the
+// timeout of the run, or nil for the provider's default client
+func localHTTPClient(cfg config.Config, provider string) *http.Client {
+	timeout := cfg.ProviderTimeout(provider)
+	if timeout == 0 {
+		return nil
+	}
+	return &http.Client{Timeout: timeout}
+}
+
 // ExportVoices exports cached voices to a JSON file.
 func ExportVoices(ctx context.Context, cachedProvider *cache.CachedProvider, providerName, outputPath string, log logger.LoggerInterface) error {
 	log.Info(fmt.Sprintf("Exporting cached voices for %s provider to %s...", providerName, outputPath))
@@ -98,8 +239,106 @@ func ExportVoices(ctx context.Context, cachedProvider *cache.CachedProvider, pro
 	return nil
 }
 
+// VoiceFilter selects voices by gender, language, and quality (empty fields match all voices).
+type VoiceFilter struct {
+	Gender   string // e.g., "female"
+	Language string // Language or locale prefix, e.g., "en" or "en-GB" (matches en_GB)
+	Quality  string // Voice quality tier, e.g., "premium"
+}
+
+// Match reports whether a voice passes the filter.
+func (f VoiceFilter) Match(voice tts.Voice) bool {
+	if f.Gender != "" && !strings.EqualFold(voice.Gender, f.Gender) {
+		return false
+	}
+	if f.Quality != "" && !strings.EqualFold(voice.Quality, f.Quality) {
+		return false
+	}
+	if f.Language != "" {
+		normalize := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, "_", "-")) }
+		lang, want := normalize(voice.Language), normalize(f.Language)
+		if lang != want && !strings.HasPrefix(lang, want+"-") {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterVoices returns the voices that pass the filter.
+func FilterVoices(voices []tts.Voice, filter VoiceFilter) []tts.Voice {
+	if filter == (VoiceFilter{}) {
+		return voices
+	}
+
+	filtered := make([]tts.Voice, 0, len(voices))
+	for _, voice := range voices {
+		if filter.Match(voice) {
+			filtered = append(filtered, voice)
+		}
+	}
+	return filtered
+}
+
+// CriteriaFilter builds a voice filter from -voice-criteria ("lang", "gender", and "quality" keys).
+func CriteriaFilter(criteria map[string]string) (VoiceFilter, error) {
+	var filter VoiceFilter
+	for key, value := range criteria {
+		switch key {
+		case "lang":
+			filter.Language = value
+		case "gender":
+			filter.Gender = value
+		case "quality":
+			filter.Quality = value
+		default:
+			return VoiceFilter{}, fmt.Errorf("invalid -voice-criteria key %q: must be lang, gender, or quality", key)
+		}
+	}
+	return filter, nil
+}
+
+// ResolveVoiceCriteria returns the configuration with the voice of the active
+// provider set to the first voice matching -voice-criteria in the provider's
+// (cached) voice list, so scripts work across machines with different voices.
+func ResolveVoiceCriteria(cfg config.Config, voiceCache *cache.VoiceCache, log logger.LoggerInterface) (config.Config, error) {
+	if len(cfg.VoiceCriteria) == 0 {
+		return cfg, nil
+	}
+	if cfg.Say.Voice != "" || cfg.ElevenLabs.VoiceID != "" {
+		return cfg, fmt.Errorf("cannot use -voice-criteria with an explicit voice (-voice, -v, -p, or -elevenlabs-voice-id)")
+	}
+	filter, err := CriteriaFilter(cfg.VoiceCriteria)
+	if err != nil {
+		return cfg, err
+	}
+
+	provider, err := CreateProvider(cfg)
+	if err != nil {
+		return cfg, err
+	}
+	voices, err := cache.NewCachedProvider(provider, voiceCache).ListVoices(context.Background())
+	if err != nil {
+		return cfg, fmt.Errorf("failed to list voices: %w", err)
+	}
+
+	matches := FilterVoices(voices, filter)
+	if len(matches) == 0 {
+		return cfg, fmt.Errorf("no %s voice matches -voice-criteria (%d voices available; see -list-voices)", provider.Name(), len(voices))
+	}
+	slices.SortStableFunc(matches, func(a, b tts.Voice) int { return strings.Compare(a.Name, b.Name) })
+
+	voice := matches[0]
+	if cfg.Provider == "elevenlabs" {
+		cfg.ElevenLabs.VoiceID = voice.ID
+	} else {
+		cfg.Say.Voice = voice.ID
+	}
+	log.Info(fmt.Sprintf("Voice matching -voice-criteria: %s (%d matches)", voice.Name, len(matches)))
+	return cfg, nil
+}
+
 // ListVoices lists available voices, using cache or refreshing as needed.
-func ListVoices(ctx context.Context, cachedProvider *cache.CachedProvider, providerName string, refreshCache bool, log logger.LoggerInterface) error {
+func ListVoices(ctx context.Context, cachedProvider *cache.CachedProvider, providerName string, refreshCache bool, filter VoiceFilter, log logger.LoggerInterface) error {
 	// Show cache info
 	cacheInfo, err := cachedProvider.GetCacheInfo(ctx)
 	if err == nil && cacheInfo.Count > 0 {
@@ -115,6 +354,11 @@ func ListVoices(ctx context.Context, cachedProvider *cache.CachedProvider, provi
 	}
 
 	// Display voices
+	if filtered := FilterVoices(voices, filter); len(filtered) < len(voices) {
+		log.Hint(fmt.Sprintf("Showing %d of %d voices matching the filter", len(filtered), len(voices)))
+		log.Blank()
+		voices = filtered
+	}
 	displayVoices(providerName, voices, log)
 	return nil
 }
@@ -171,10 +415,10 @@ func displayElevenLabsVoices(voices []tts.Voice, log logger.LoggerInterface) {
 	}
 }
 
-// displaySimpleVoices displays voices in simple format (for say provider).
+// displaySimpleVoices displays voices in simple format (for say and espeak providers).
 func displaySimpleVoices(voices []tts.Voice, log logger.LoggerInterface) {
 	for _, voice := range voices {
-		line := fmt.Sprintf("%-20s %-10s", voice.Name, voice.Language)
+		line := fmt.Sprintf("%-20s %-10s %-7s %-9s", voice.Name, voice.Language, voice.Gender, voice.Quality)
 		if voice.Description != "" {
 			line += fmt.Sprintf(" - %s", voice.Description)
 		}
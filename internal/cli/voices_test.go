@@ -7,12 +7,14 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/indaco/md2audio/internal/cache"
 	"github.com/indaco/md2audio/internal/config"
 	"github.com/indaco/md2audio/internal/logger"
 	"github.com/indaco/md2audio/internal/testhelpers"
 	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/tts/external"
 )
 
 func TestCreateProvider(t *testing.T) {
@@ -52,6 +54,37 @@ func TestCreateProvider(t *testing.T) {
 			expectError:  false,
 			expectedName: "elevenlabs",
 		},
+		{
+			name: "elevenlabs provider with proxy and headers",
+			cfg: config.Config{
+				Provider:   "elevenlabs",
+				ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key-123"},
+				HTTP: config.HTTPConfig{
+					Proxy:   "http://proxy.example.com:3128",
+					Headers: map[string]string{"X-Gateway-Key": "abc"},
+				},
+			},
+			expectError:  false,
+			expectedName: "elevenlabs",
+		},
+		{
+			name: "elevenlabs provider in local-only mode",
+			cfg: config.Config{
+				Provider:   "elevenlabs",
+				ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key-123"},
+				LocalOnly:  true,
+			},
+			expectError: true,
+		},
+		{
+			name: "elevenlabs provider with invalid proxy",
+			cfg: config.Config{
+				Provider:   "elevenlabs",
+				ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key-123"},
+				HTTP:       config.HTTPConfig{Proxy: "proxy.example.com"},
+			},
+			expectError: true,
+		},
 		{
 			name: "elevenlabs provider without API key",
 			cfg: config.Config{
@@ -66,6 +99,24 @@ func TestCreateProvider(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "external provider",
+			cfg: config.Config{
+				Provider:          "acme",
+				ExternalProviders: []external.Config{{Name: "acme", Command: "sh"}},
+			},
+			expectError:  false,
+			expectedName: "acme",
+		},
+		{
+			name: "external cloud provider in local-only mode",
+			cfg: config.Config{
+				Provider:          "acme",
+				ExternalProviders: []external.Config{{Name: "acme", Command: "sh", Cloud: true}},
+				LocalOnly:         true,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,6 +151,35 @@ func TestCreateProvider(t *testing.T) {
 	}
 }
 
+func TestCreateProviderTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	script := filepath.Join(t.TempDir(), "md2audio-provider-hang")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ncat > /dev/null\nsleep 30\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake plugin: %v", err)
+	}
+
+	cfg := config.Config{
+		Provider:          "hang",
+		ExternalProviders: []external.Config{{Name: "hang", Command: script}},
+		Timeout:           100 * time.Millisecond,
+	}
+	provider, err := CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+
+	start := time.Now()
+	_, err = provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.mp3")})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("Generate() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Generate() returned after %s, want the -timeout to stop the hanging provider", elapsed)
+	}
+}
+
 func TestDisplayVoicesOutput(t *testing.T) {
 	if runtime.GOOS != "darwin" {
 		t.Skip("Skipping macOS-specific test")
@@ -380,7 +460,7 @@ func TestListVoices(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			output, err := testhelpers.CaptureStdout(func() {
-				err := ListVoices(ctx, cachedProvider, provider.Name(), tt.refreshCache, log)
+				err := ListVoices(ctx, cachedProvider, provider.Name(), tt.refreshCache, VoiceFilter{}, log)
 				if err != nil {
 					t.Errorf("ListVoices() error = %v", err)
 				}
@@ -527,3 +607,103 @@ func TestGetVoicesWithCacheInfo(t *testing.T) {
 		}
 	})
 }
+
+func TestFilterVoices(t *testing.T) {
+	voices := []tts.Voice{
+		{Name: "Kate", Language: "en_GB", Gender: "female"},
+		{Name: "Daniel", Language: "en_GB", Gender: "male"},
+		{Name: "Samantha", Language: "en_US", Gender: "female"},
+		{Name: "Thomas", Language: "fr_FR", Gender: "male"},
+		{Name: "Rachel", Language: "en", Gender: "female"},
+	}
+
+	tests := []struct {
+		name   string
+		filter VoiceFilter
+		want   []string
+	}{
+		{name: "no filter", filter: VoiceFilter{}, want: []string{"Kate", "Daniel", "Samantha", "Thomas", "Rachel"}},
+		{name: "gender", filter: VoiceFilter{Gender: "Female"}, want: []string{"Kate", "Samantha", "Rachel"}},
+		{name: "language", filter: VoiceFilter{Language: "en"}, want: []string{"Kate", "Daniel", "Samantha", "Rachel"}},
+		{name: "locale", filter: VoiceFilter{Language: "en-GB"}, want: []string{"Kate", "Daniel"}},
+		{name: "gender and locale", filter: VoiceFilter{Gender: "male", Language: "en_gb"}, want: []string{"Daniel"}},
+		{name: "no match", filter: VoiceFilter{Language: "de"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, voice := range FilterVoices(voices, tt.filter) {
+				got = append(got, voice.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FilterVoices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCriteriaFilter(t *testing.T) {
+	filter, err := CriteriaFilter(map[string]string{"lang": "it", "gender": "male", "quality": "premium"})
+	if err != nil {
+		t.Fatalf("CriteriaFilter() error = %v", err)
+	}
+	if filter != (VoiceFilter{Language: "it", Gender: "male", Quality: "premium"}) {
+		t.Errorf("CriteriaFilter() = %+v", filter)
+	}
+
+	if _, err := CriteriaFilter(map[string]string{"accent": "british"}); err == nil {
+		t.Error("CriteriaFilter() should reject unknown keys")
+	}
+}
+
+func TestResolveVoiceCriteria(t *testing.T) {
+	voiceCache, err := cache.NewVoiceCacheWithPath(filepath.Join(t.TempDir(), "test.db"), time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer func() { _ = voiceCache.Close() }()
+
+	// Cached voices are used without calling the API
+	voices := []tts.Voice{
+		{ID: "id-giulia", Name: "Giulia", Language: "it", Gender: "female"},
+		{ID: "id-marco", Name: "Marco", Language: "it", Gender: "male"},
+		{ID: "id-alberto", Name: "Alberto", Language: "it-IT", Gender: "male"},
+		{ID: "id-george", Name: "George", Language: "en", Gender: "male"},
+	}
+	if err := voiceCache.Set(context.Background(), "elevenlabs", voices); err != nil {
+		t.Fatalf("Failed to seed cache: %v", err)
+	}
+
+	base := config.Config{Provider: "elevenlabs", ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key-123"}}
+	log := logger.NewDefaultLogger()
+
+	t.Run("first match", func(t *testing.T) {
+		cfg := base
+		cfg.VoiceCriteria = map[string]string{"lang": "it", "gender": "male"}
+		got, err := ResolveVoiceCriteria(cfg, voiceCache, log)
+		if err != nil {
+			t.Fatalf("ResolveVoiceCriteria() error = %v", err)
+		}
+		if got.ElevenLabs.VoiceID != "id-alberto" {
+			t.Errorf("VoiceID = %q, want id-alberto", got.ElevenLabs.VoiceID)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		cfg := base
+		cfg.VoiceCriteria = map[string]string{"lang": "de"}
+		if _, err := ResolveVoiceCriteria(cfg, voiceCache, log); err == nil || !strings.Contains(err.Error(), "no elevenlabs voice matches") {
+			t.Errorf("ResolveVoiceCriteria() error = %v", err)
+		}
+	})
+
+	t.Run("explicit voice", func(t *testing.T) {
+		cfg := base
+		cfg.ElevenLabs.VoiceID = "id-george"
+		cfg.VoiceCriteria = map[string]string{"lang": "it"}
+		if _, err := ResolveVoiceCriteria(cfg, voiceCache, log); err == nil {
+			t.Error("ResolveVoiceCriteria() should refuse an explicit voice")
+		}
+	})
+}
@@ -14,12 +14,38 @@ package config
 import (
 	"flag"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/bundle"
+	"github.com/indaco/md2audio/internal/cache"
 	"github.com/indaco/md2audio/internal/env"
+	"github.com/indaco/md2audio/internal/httpclient"
 	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/postprocess"
+	"github.com/indaco/md2audio/internal/progress"
+	"github.com/indaco/md2audio/internal/redact"
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/timing"
+	"github.com/indaco/md2audio/internal/transform"
+	"github.com/indaco/md2audio/internal/tts/coqui"
+	"github.com/indaco/md2audio/internal/tts/customhttp"
+	"github.com/indaco/md2audio/internal/tts/edge"
+	"github.com/indaco/md2audio/internal/tts/external"
+	"github.com/indaco/md2audio/internal/tts/marytts"
+	"github.com/indaco/md2audio/internal/tts/playht"
+	"github.com/indaco/md2audio/internal/tts/watson"
 )
 
 // VoicePresets maps common voice configurations to voice names
@@ -37,18 +63,49 @@ const DefaultElevenLabsVoiceID = "21m00Tcm4TlvDq8ikWAM"
 
 // CommandFlags holds command-line flags for special operations
 type CommandFlags struct {
-	ListVoices   bool   // List all available voices for the selected provider
-	RefreshCache bool   // Force refresh voice cache when listing voices
-	ExportVoices string // Export cached voices to JSON file (e.g., "voices.json")
-	Version      bool   // Print version and exit
-	Debug        bool   // Enable debug logging
-	DryRun       bool   // Dry-run mode: show what would be generated without creating files
+	ListVoices     bool   // List all available voices for the selected provider
+	ListModels     bool   // List the ElevenLabs models with their languages and character limits
+	RefreshCache   bool   // Force refresh voice cache when listing voices
+	ExportVoices   string // Export cached voices to JSON file (e.g., "voices.json")
+	VoiceGender    string // Only list voices of this gender with -list-voices
+	VoiceLanguage  string // Only list voices or models of this language or locale with -list-voices or -list-models (e.g., "en", "en-GB")
+	Version        bool   // Print version and exit
+	Debug          bool   // Enable debug logging
+	DryRun         bool   // Dry-run mode: show what would be generated without creating files
+	DryRunRequests bool   // Dry-run mode that also prints the provider request for each section
+	ServeOutput    bool   // Serve the output directory over HTTP for review
+	ServeAddr      string // Listen address for -serve-output (default: "localhost:8080")
+	DedupReport    bool   // Report audio files with identical content in the output directory
+	DedupLink      bool   // Replace duplicate audio files with hard links (implies DedupReport)
+	History        bool   // List recent runs from the run history
+	Stats          bool   // Show usage statistics per provider from the run history
+	HistoryLimit   int    // Number of runs listed by -history (default: 20)
+	Unbundle       string // Extract a bundle created by -bundle into the output directory
+	Retime         bool   // Time-stretch existing audio in the output directory to the markdown file's updated timings
+	ExportSections string // Write the parsed sections to this JSON or CSV file without generating audio
+	Calibrate      bool   // Measure the speaking rate of the local voice and store its calibration curve
+	Quota          bool   // Show the ElevenLabs character quota and whether the pending run fits in it
+	Preview        bool   // Play the sections aloud with the say provider instead of writing files
+	PreviewFilter  string // Only play these sections with -preview: numbers, ranges, or title words (e.g., "1,3-4,intro")
+	UI             bool   // Serve the local web UI
+	UIAddr         string // Listen address for -ui (default: "localhost:8090")
+
+	// ElevenLabs voice management
+	CloneVoice       string   // Create an instant voice clone with this name from the -voice-sample files
+	EditVoice        string   // ID of a voice to rename (-voice-name), describe, or add -voice-sample files to
+	DeleteVoice      string   // ID of a voice to delete
+	VoiceSamples     []string // Audio sample files of the speaker (repeatable -voice-sample)
+	VoiceName        string   // New name of the voice edited with -edit-voice
+	VoiceDescription string   // Description of the voice created or edited
 }
 
 // SayConfig holds configuration for the macOS say provider
 type SayConfig struct {
 	Voice string // Voice name (default: "Kate")
 	Rate  int    // Speaking rate in words per minute (default: 180)
+
+	OpenVoiceSettings bool // Open the System Settings voice download pane when a better voice variant is available
+	KeepIntermediates bool // Keep the AIFF synthesized before m4a conversion next to the output (debugging)
 }
 
 // VoiceSettings holds ElevenLabs voice generation settings
@@ -65,27 +122,292 @@ type ElevenLabsConfig struct {
 	VoiceID       string        // ElevenLabs voice ID (required when using elevenlabs provider)
 	Model         string        // ElevenLabs model ID (default: "eleven_multilingual_v2")
 	APIKey        string        // ElevenLabs API key (prefer ELEVENLABS_API_KEY env var)
+	Stream        bool          // Use the streaming endpoint (lower time to first byte for long sections)
+	Stitch        bool          // Send adjacent section text with each request for continuous prosody
+	Seed          uint          // Sampling seed sent with each request (0 = none, at most 4294967295)
+	Deterministic bool          // Seed every request and pin the model recorded in the output directory, for reproducible builds
 	VoiceSettings VoiceSettings // Voice generation settings (loaded from environment variables with defaults)
 }
 
+// EdgeConfig holds configuration for the Edge provider (the voice is set with -v)
+type EdgeConfig struct {
+	Rate  string // Speaking rate relative to the voice's default (e.g., "+10%", default: "+0%")
+	Pitch string // Pitch relative to the voice's default (e.g., "-5Hz", default: "+0Hz")
+}
+
+// CoquiConfig holds configuration for the Coqui XTTS provider
+type CoquiConfig struct {
+	URL        string // xtts-api-server base URL (default: "http://localhost:8020")
+	SpeakerWav string // Speaker WAV reference cloned by XTTS (used as the voice when -v is not set)
+	Language   string // Language code (default: "en")
+}
+
+// EspeakConfig holds voice tunables for the espeak provider (zero values keep the espeak defaults)
+type EspeakConfig struct {
+	Pitch     int    // Pitch adjustment, 1-99 (espeak default: 50)
+	Amplitude int    // Volume, 1-200 (espeak default: 100)
+	WordGap   int    // Pause between words in units of 10ms
+	Variant   string // Voice variant (e.g., "f3", "klatt") or MBROLA voice (e.g., "mb-en1")
+}
+
+// MaryTTSConfig holds configuration for the MaryTTS/Mimic 3 provider (the voice is set with -v)
+type MaryTTSConfig struct {
+	URL    string // Server base URL (default: "http://localhost:59125")
+	Locale string // Locale of the input text (default: "en_US")
+}
+
+// WatsonConfig holds configuration for the IBM Watson provider (the voice is set with -v)
+type WatsonConfig struct {
+	URL    string // Service instance URL (prefer TEXT_TO_SPEECH_URL env var)
+	APIKey string // API key (prefer TEXT_TO_SPEECH_APIKEY env var)
+}
+
+// PlayHTConfig holds configuration for the Play.ht provider (the voice is set with -v)
+type PlayHTConfig struct {
+	UserID  string  // User ID (prefer PLAY_HT_USER_ID env var)
+	APIKey  string  // Secret key (prefer PLAY_HT_API_KEY env var)
+	Speed   float64 // Speaking speed (0.1-5.0, default: 1.0, only for non-timed sections)
+	Emotion string  // Voice emotion (e.g., "female_happy", default: none)
+}
+
+// HTTPConfig holds network settings for API-based providers (ElevenLabs, Edge, Watson, Play.ht)
+type HTTPConfig struct {
+	Proxy    string            // HTTP/HTTPS proxy URL (default: HTTP_PROXY/HTTPS_PROXY env vars)
+	CABundle string            // PEM file with additional trusted certificates
+	Headers  map[string]string // Extra headers sent with every API request
+
+	Retry httpclient.RetryPolicy // Retries of failed requests of HTTP-based providers
+}
+
+// AlignConfig holds configuration for forced alignment of generated audio
+type AlignConfig struct {
+	Method       string // Aligner: "aeneas" or "whisper" (empty = disabled)
+	Command      string // Aligner executable override
+	WhisperModel string // whisper.cpp model path (required for whisper)
+	Language     string // Alignment language code (default: "en")
+}
+
+// VerifyConfig holds configuration for round-trip transcription checks
+type VerifyConfig struct {
+	Transcribe bool    // Transcribe generated audio and compare it with the source text
+	Threshold  float64 // Maximum word error rate before a section is flagged (default: 0.25)
+	Command    string  // whisper.cpp executable override (default: "whisper-cli")
+
+	MinDurationRatio float64 // Flag sections whose audio is shorter than this fraction of the estimated duration (default: 0.4, 0 = disabled)
+	RetryShort       bool    // Regenerate a too-short section once before flagging it
+}
+
+// RerunConfig holds configuration for regenerating sections from a manifest
+type RerunConfig struct {
+	Manifest string   // Path to a manifest.json written by a previous run
+	Only     []string // Manifest statuses to regenerate: "failed", "flagged", "skipped" (default: all three)
+}
+
+// SummaryConfig holds configuration for the chat-ready run summary
+type SummaryConfig struct {
+	Path    string // Summary file path; JSON for .json files, markdown otherwise (empty = disabled)
+	BaseURL string // URL prefix for output links (relative paths if empty)
+	Spoken  bool   // Synthesize a short spoken report of the run into the output directory
+}
+
+// SiteConfig holds configuration for static site integration assets
+type SiteConfig struct {
+	Assets  bool   // Write player snippets and a page index into <output>/site
+	BaseURL string // URL prefix for audio links (paths relative to the output root if empty)
+}
+
+// WebhookConfig holds configuration for the GitHub webhook of -serve-output
+type WebhookConfig struct {
+	Secret  string   // Shared secret verifying deliveries (prefer MD2AUDIO_WEBHOOK_SECRET env var; empty = disabled)
+	Tenants bool     // Accept deliveries signed with the -server-tenants tokens instead of Secret
+	Paths   []string // Repository path patterns whose markdown changes trigger regeneration (empty = all)
+}
+
+// ServerConfig holds settings for a shared -serve-output instance
+type ServerConfig struct {
+	MaxJobs          int // Regenerations running at once (default: 1)
+	MaxQueue         int // Regenerations waiting to run before requests are refused (default: 16)
+	MaxJobsPerClient int // Queued or running regenerations per repository (0 = unlimited)
+	MaxBodyKB        int // Maximum request body size in KiB (default: 5120)
+	MaxDocumentKB    int // Markdown files larger than this are not regenerated (0 = unlimited)
+
+	TenantsFile string // JSON file of tenants sharing the server with token authentication (empty = open access)
+}
+
 // Config holds the application configuration
 type Config struct {
 	// Input/Output Options
-	MarkdownFile string // Path to input markdown file (mutually exclusive with InputDir)
-	InputDir     string // Path to input directory for recursive processing (mutually exclusive with MarkdownFile)
-	OutputDir    string // Path to output directory for generated audio files (default: "./audio_sections")
+	MarkdownFile string      // Path to input markdown file (mutually exclusive with InputDir)
+	InputDir     string      // Path to input directory for recursive processing (mutually exclusive with MarkdownFile)
+	InputFormat  string      // Layout of the input directory: "markdown", "notion", or "confluence" export (default: "markdown")
+	OutputDir    string      // Path to output directory for generated audio files, optionally a template (default: "./audio_sections")
+	Rerun        RerunConfig // Regenerate sections recorded in a manifest (replaces -f/-d)
+
+	// Selection Options
+	Order         string        // Directory processing order: "doc", "alpha", "natural", "mtime", "shuffle", or "shuffle(seed)" (default: "doc")
+	NewestFirst   bool          // Process the most recently modified files first (same as -order mtime)
+	MaxDuration   time.Duration // Wall-clock budget; no new sections are started once it is spent (0 = unlimited)
+	MaxAPICalls   int           // Provider request budget; no new sections are started once it is spent (0 = unlimited)
+	Limit         int           // Process at most this many markdown files in directory mode (0 = all)
+	LimitSections int           // Generate at most this many sections per file (0 = all)
+	Sample        string        // Percentage of sections to randomly sample across the input set (e.g., "5%")
+	Seed          uint64        // Random seed for sampling and shuffling (0 = random)
 
 	// Common Audio Options
-	Format string // Output audio format: "aiff", "m4a", or "mp3" (default: "aiff")
-	Prefix string // Prefix for output filenames (default: "section")
+	Format   string // Output audio format: "aiff", "m4a", or "mp3" (default: "aiff")
+	Prefix   string // Prefix for output filenames (default: "section")
+	Lossless bool   // Keep provider-native lossless output (AIFF/WAV), skipping lossy conversion
+	SSML     bool   // Interpret SSML markup in section text (espeak and marytts providers only)
+
+	SlugStyle      string // Title slug style in filenames: "ascii", "unicode", or "hash" (default: "ascii")
+	MaxFilenameLen int    // Maximum output file name length without extension (0 = title slugs capped at 50 characters)
+	StartIndex     int    // Number of the first section in output file names (0 = 1; StartIndexContinue = after the sections in the output manifest)
+
+	// Section Transforms
+	EmptySection string           // Text spoken for sections left without text by cleaning, such as image- or code-only sections (empty = skip them)
+	TransformCmd string           // Command rewriting each section's text (stdin to stdout) before synthesis
+	Transforms   []transform.Func // Section transforms registered by library users, applied before TransformCmd
+
+	// Audio Post-Processing
+	PostCmd        string             // Command run on each generated audio file (path as last argument) before it is finalized
+	PostProcessors []postprocess.Func // Post-processors registered by library users, run before PostCmd
+
+	Progress progress.Func // Receives progress events, for library users rendering their own progress (optional)
+	Redact   string        // Mask ("mask") or verbalize ("verbalize") profanity and sensitive data (empty = disabled)
+
+	// Subtitle Options
+	Subtitles    bool   // Write SRT subtitles next to each audio file
+	ReadAlong    bool   // Write read-along JSON (text, sentence offsets, timings) next to each audio file
+	TimingMethod string // Word timing estimation method: "uniform" or "syllable" (default: "syllable")
+	Align        AlignConfig
+	Verify       VerifyConfig
+
+	// Report Options
+	PronunciationReport bool          // Write a report of tokens likely to be mispronounced
+	Summary             SummaryConfig // Write a compact run summary for chat tools
+	Bundle              string        // Package the output directory into this archive after the run (.tar, .tar.gz, .tar.zst)
+	ZipPerFile          bool          // Also package each document's section audio into <filename>.zip in the output root
+	Site                SiteConfig    // Static site integration assets
+	Granularity         string        // Audio file per "section" or per "sentence" (default: "section")
+	Chapters            bool          // H2 headings are chapters written to subdirectories, with their H3 headings as numbered tracks
+
+	// Multi-language Options
+	Languages      []string          // Language codes processed from per-language input subdirectories (e.g., ["en", "es"])
+	LanguageVoices map[string]string // Voice (name or ID) to use for each language code
+	Language       string            // Language currently being processed (set per language by ForLanguage)
 
 	// Command Options
 	Commands CommandFlags
+	Webhook  WebhookConfig // GitHub push webhook regenerating -d into -o while serving
+	Server   ServerConfig  // Limits and tenants of the -serve-output server
+	Tenants  []Tenant      // Teams sharing the -serve-output server (loaded from Server.TenantsFile)
+	Tenant   string        // Tenant currently being served (set by ForTenant)
+
+	// Run History
+	HistoryDB string // SQLite database for run history (default: the voice cache database)
+	NoHistory bool   // Do not record this run in the history
 
 	// TTS Provider Configuration
 	Provider   string           // TTS provider: "say" (macOS) or "elevenlabs" (default: "say")
 	Say        SayConfig        // Say provider configuration
 	ElevenLabs ElevenLabsConfig // ElevenLabs provider configuration
+	Edge       EdgeConfig       // Edge provider configuration
+	Coqui      CoquiConfig      // Coqui XTTS provider configuration
+	MaryTTS    MaryTTSConfig    // MaryTTS/Mimic 3 provider configuration
+	Espeak     EspeakConfig     // espeak provider configuration
+	Watson     WatsonConfig     // IBM Watson provider configuration
+	PlayHT     PlayHTConfig     // Play.ht provider configuration
+	HTTP       HTTPConfig       // Network settings for API-based providers
+	LocalOnly  bool             // Refuse cloud providers so document text never leaves the machine
+
+	VoiceCriteria map[string]string // Pick the first voice matching these criteria ("lang", "gender", "quality") from the provider's voice list
+
+	FailureCooldown time.Duration // Fail fast for this long after an invalid voice or rejected credentials (0 = always call the provider)
+
+	Timeout time.Duration // Time limit of each provider request or say/espeak run (0 = the <PROVIDER>_TIMEOUT env var, else the provider default)
+
+	ExternalProviders []external.Config  // Providers implemented by external executables (loaded from -external-providers)
+	CustomHTTP        *customhttp.Config // HTTP service used by the custom-http provider (loaded from -custom-http-config)
+
+	Force       bool   // Add audio to output directories generated with a different provider, voice, or format
+	TagAudio    bool   // Write the md2audio version and settings into each audio file's metadata comment (requires ffmpeg)
+	Placeholder string // Write a "silence" or "spoken" placeholder in place of failed sections (empty = disabled)
+	SilenceOnly bool   // Write silent audio of each section's target duration instead of calling the TTS provider
+	Incremental bool   // Keep unchanged sections and re-synthesize only the changed sentences of edited ones
+
+	Variants []float64 // Playback speeds of extra tempo-shifted copies of each output, written to <speed>x subdirectories (requires ffmpeg)
+}
+
+// StartIndexContinue continues the section numbering of the output directory
+// manifest (-start-index auto)
+const StartIndexContinue = -1
+
+// Output granularities for -granularity
+const (
+	GranularitySection  = "section"  // One audio file per section
+	GranularitySentence = "sentence" // One audio file per sentence
+)
+
+// LosslessFormat returns the provider-native lossless format used by -lossless.
+func LosslessFormat(provider string) string {
+	if provider == "say" {
+		return "aiff"
+	}
+	return "wav"
+}
+
+// OutputFormat returns the effective output format, honoring -lossless.
+func (c Config) OutputFormat() string {
+	if c.Lossless {
+		return LosslessFormat(c.Provider)
+	}
+	return c.Format
+}
+
+// SampleFraction returns the -sample percentage as a fraction in (0, 1].
+// Both "5%" and "5" mean five percent.
+func (c Config) SampleFraction() (float64, error) {
+	value := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(c.Sample), "%"))
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("invalid -sample %q: must be a percentage between 0 and 100 (e.g., 5%%)", c.Sample)
+	}
+	return percent / 100, nil
+}
+
+// validate checks the espeak tunables against the ranges espeak accepts
+func (e EspeakConfig) validate() error {
+	if e.Pitch < 0 || e.Pitch > 99 {
+		return fmt.Errorf("-espeak-pitch must be between 1 and 99, got %d", e.Pitch)
+	}
+	if e.Amplitude < 0 || e.Amplitude > 200 {
+		return fmt.Errorf("-espeak-amplitude must be between 1 and 200, got %d", e.Amplitude)
+	}
+	if e.WordGap < 0 {
+		return fmt.Errorf("-espeak-word-gap must not be negative, got %d", e.WordGap)
+	}
+	if strings.ContainsAny(e.Variant, " +") {
+		return fmt.Errorf("invalid -espeak-variant %q: give the variant name without '+' (e.g., f3)", e.Variant)
+	}
+	return nil
+}
+
+// applyVoice sets the voice of the selected provider from -voice, keeping the
+// voice given with the provider's own flag (-elevenlabs-voice-id or -v).
+func (c *Config) applyVoice(voice string) {
+	if voice == "" {
+		return
+	}
+	if c.Provider == "elevenlabs" {
+		if c.ElevenLabs.VoiceID == "" {
+			c.ElevenLabs.VoiceID = voice
+		}
+		return
+	}
+	// All other providers read their voice from -v
+	if c.Say.Voice == "" {
+		c.Say.Voice = voice
+	}
 }
 
 // GetDefaultProvider returns the default TTS provider based on the platform.
@@ -101,6 +423,143 @@ func GetDefaultProvider() string {
 	}
 }
 
+// EnvLocalOnly is the environment variable that enforces -local-only (e.g., set by an administrator)
+const EnvLocalOnly = "MD2AUDIO_LOCAL_ONLY"
+
+// IsCloudProvider reports whether a provider sends document text to a remote service.
+func IsCloudProvider(provider string) bool {
+	return provider == "elevenlabs" || provider == "edge" || provider == "watson" || provider == "playht"
+}
+
+// BuiltinProviders lists the providers compiled into md2audio
+var BuiltinProviders = []string{"say", "espeak", "festival", "elevenlabs", "edge", "coqui", "marytts", "watson", "playht", customhttp.Name}
+
+// ExternalProvider returns the registration of an external provider, or the
+// md2audio-provider-<name> executable discovered on PATH.
+// Built-in providers cannot be replaced by either.
+func (c Config) ExternalProvider(name string) (external.Config, bool) {
+	if slices.Contains(BuiltinProviders, name) {
+		return external.Config{}, false
+	}
+	for _, provider := range c.ExternalProviders {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+	return external.Discover(name)
+}
+
+// IsCloud reports whether a built-in or external provider sends document text to a remote service.
+func (c Config) IsCloud(provider string) bool {
+	if IsCloudProvider(provider) {
+		return true
+	}
+	if provider == "coqui" {
+		// The XTTS server is local unless it is reached over the network
+		return !isLoopbackURL(c.Coqui.URL)
+	}
+	if provider == "marytts" {
+		return !isLoopbackURL(c.MaryTTS.URL)
+	}
+	if provider == customhttp.Name {
+		return c.CustomHTTP == nil || !isLoopbackURL(os.ExpandEnv(c.CustomHTTP.URL))
+	}
+	if ext, ok := c.ExternalProvider(provider); ok {
+		return ext.Cloud
+	}
+	return false
+}
+
+// checkLocalOnly refuses a cloud provider under -local-only, suggesting the
+// providers that keep document text on this machine with this configuration
+func (c Config) checkLocalOnly() error {
+	if !c.LocalOnly || !c.IsCloud(c.Provider) {
+		return nil
+	}
+	var local []string
+	for _, name := range BuiltinProviders {
+		switch {
+		case name == "say" && runtime.GOOS != "darwin":
+		case name == customhttp.Name && c.CustomHTTP == nil:
+		case !c.IsCloud(name):
+			local = append(local, name)
+		}
+	}
+	for _, ext := range c.ExternalProviders {
+		if !ext.Cloud {
+			local = append(local, ext.Name)
+		}
+	}
+	return fmt.Errorf("-local-only: refusing to send document text to the cloud provider %q; local providers: %s", c.Provider, strings.Join(local, ", "))
+}
+
+// ProviderTimeout returns the time limit of each request or run of a
+// provider: -timeout when set, otherwise its <PROVIDER>_TIMEOUT environment
+// variable (e.g., ELEVENLABS_TIMEOUT=2m), otherwise 0 for the provider default.
+func (c Config) ProviderTimeout(provider string) time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	name := strings.ToUpper(strings.ReplaceAll(provider, "-", "_")) + "_TIMEOUT"
+	if value := os.Getenv(name); value != "" {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+	}
+	return 0
+}
+
+// isLoopbackURL reports whether a URL points to the local machine
+func isLoopbackURL(rawURL string) bool {
+	if rawURL == "" {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// EnvWebhookSecret is the environment variable holding the -webhook-secret
+const EnvWebhookSecret = EnvFlagPrefix + "WEBHOOK_SECRET"
+
+// EnvFlagPrefix prefixes the environment variables setting command-line options
+// (e.g., MD2AUDIO_SERVE_ADDR for -serve-addr)
+const EnvFlagPrefix = "MD2AUDIO_"
+
+// EnvFlagName returns the environment variable setting a command-line option
+func EnvFlagName(name string) string {
+	return EnvFlagPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnvFlags sets the flags of fs from their MD2AUDIO_<OPTION> environment
+// variables. Command-line arguments parsed afterwards take precedence.
+// Invalid values are returned as errors and leave the default in place.
+func applyEnvFlags(fs *flag.FlagSet) []error {
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(EnvFlagName(f.Name))
+		if !ok {
+			return
+		}
+		previous := f.Value.String()
+		if err := fs.Set(f.Name, value); err != nil {
+			// Some flag types store a zero value before reporting the error
+			if f.Value.String() != previous {
+				_ = f.Value.Set(previous)
+			}
+			errs = append(errs, fmt.Errorf("ignoring %s: %v", EnvFlagName(f.Name), err))
+		}
+	})
+	return errs
+}
+
 // Parse parses command-line flags and returns the configuration
 func Parse() Config {
 	// Load .env file if it exists (won't override existing env vars)
@@ -116,32 +575,258 @@ func Parse() Config {
 
 	flag.StringVar(&config.MarkdownFile, "f", "", "Input markdown file (use -f or -d, not both)")
 	flag.StringVar(&config.InputDir, "d", "", "Input directory to process recursively (use -f or -d, not both)")
-	flag.StringVar(&config.OutputDir, "o", "./audio_sections", "Output directory for audio files")
+	flag.StringVar(&config.OutputDir, "o", "./audio_sections", "Output directory for audio files (supports templates, e.g. './audio/{{.RelDir}}/{{.FileName}}')")
+
+	flag.StringVar(&config.InputFormat, "input-format", "markdown", "Layout of the input directory: markdown, notion (Notion markdown export), or confluence (Confluence space exported to markdown)")
+	flag.StringVar(&config.Order, "order", "doc", "Directory processing order: doc, alpha, natural (chapter2 before chapter10), mtime (newest first), shuffle, or shuffle(seed); doc and natural follow an _order.yaml index in -d")
+	flag.BoolVar(&config.NewestFirst, "newest-first", false, "Process the most recently modified files first (same as -order mtime)")
+	flag.DurationVar(&config.MaxDuration, "max-duration", 0, "Stop starting new sections after this wall-clock time, e.g. 30m (0 = unlimited)")
+	flag.DurationVar(&config.MaxDuration, "max-runtime", 0, "Alias for -max-duration")
+	flag.IntVar(&config.MaxAPICalls, "max-api-calls", 0, "Stop starting new sections after this many provider requests (0 = unlimited)")
+	flag.IntVar(&config.Limit, "limit", 0, "Process only the first N markdown files in directory mode (0 = all)")
+	flag.IntVar(&config.LimitSections, "limit-sections", 0, "Generate only the first N sections of each file (0 = all)")
+
+	flag.StringVar(&config.Sample, "sample", "", "Randomly generate a percentage of sections across all input files for spot checks (e.g., 5%)")
+	flag.Uint64Var(&config.Seed, "seed", 0, "Random seed for reproducible -sample selections and -order shuffle (0 = random)")
+
+	var only string
+	flag.StringVar(&config.Rerun.Manifest, "from-manifest", "", "Regenerate sections recorded in a manifest.json, keeping their output paths")
+	flag.StringVar(&only, "only", "", "Manifest statuses to regenerate with -from-manifest (failed, flagged, skipped; default: all)")
 
 	// TTS Provider - auto-detect based on platform
 	defaultProvider := GetDefaultProvider()
-	flag.StringVar(&config.Provider, "provider", defaultProvider, "TTS provider: 'say' (macOS), 'espeak' (Linux), or 'elevenlabs'")
+	var voice string
+	flag.StringVar(&voice, "voice", "", "Voice for the selected provider: a voice name (say, espeak, edge, ...), an ElevenLabs voice ID, or a Coqui speaker WAV; -v and -elevenlabs-voice-id override it")
+	flag.StringVar(&config.Provider, "provider", defaultProvider, "TTS provider: 'say' (macOS), 'espeak' (Linux), 'festival', 'elevenlabs', 'edge', 'coqui', 'marytts', 'watson', 'playht', 'custom-http', an external provider name, or an md2audio-provider-<name> plugin on PATH")
 
 	// Say provider options
 	var preset string
 	flag.StringVar(&preset, "p", "", "Voice preset for say provider (british-female, british-male, us-female, us-male, australian-female, indian-female)")
 	flag.StringVar(&config.Say.Voice, "v", "", "Specific voice name for say provider (overrides preset)")
+	flag.Func("voice-criteria", "Use the first voice matching these criteria from the provider's voice list (e.g., lang=it,gender=male)", func(s string) error {
+		criteria, err := parseKeyValueList(s)
+		if err != nil {
+			return err
+		}
+		config.VoiceCriteria = criteria
+		return nil
+	})
 	flag.IntVar(&config.Say.Rate, "r", 180, "Speaking rate for say provider (lower = slower)")
+	flag.BoolVar(&config.Say.OpenVoiceSettings, "open-voice-settings", false, "Open System Settings to download the enhanced or premium variant of a compact say voice")
+	flag.BoolVar(&config.Say.KeepIntermediates, "keep-intermediates", false, "Keep the AIFF files the say provider synthesizes before m4a conversion next to the output (debugging)")
+
+	// espeak provider options (defaults from ESPEAK_* environment variables)
+	flag.IntVar(&config.Espeak.Pitch, "espeak-pitch", getEnvInt("ESPEAK_PITCH", 0), "espeak pitch, 1-99 (0 = espeak default of 50)")
+	flag.IntVar(&config.Espeak.Amplitude, "espeak-amplitude", getEnvInt("ESPEAK_AMPLITUDE", 0), "espeak volume, 1-200 (0 = espeak default of 100)")
+	flag.IntVar(&config.Espeak.WordGap, "espeak-word-gap", getEnvInt("ESPEAK_WORD_GAP", 0), "Pause between words for espeak in units of 10ms")
+	flag.StringVar(&config.Espeak.Variant, "espeak-variant", os.Getenv("ESPEAK_VARIANT"), "espeak voice variant appended to the voice (e.g., f3, klatt), or an MBROLA voice used instead (e.g., mb-en1)")
 
 	// ElevenLabs provider options
 	flag.StringVar(&config.ElevenLabs.VoiceID, "elevenlabs-voice-id", "", "ElevenLabs voice ID (e.g., '21m00Tcm4TlvDq8ikWAM')")
 	flag.StringVar(&config.ElevenLabs.Model, "elevenlabs-model", "eleven_multilingual_v2", "ElevenLabs model ID")
 	flag.StringVar(&config.ElevenLabs.APIKey, "elevenlabs-api-key", "", "ElevenLabs API key (prefer ELEVENLABS_API_KEY env var)")
+	flag.BoolVar(&config.ElevenLabs.Stream, "stream", false, "Use the ElevenLabs streaming endpoint, writing audio to disk as it arrives")
+	flag.BoolVar(&config.ElevenLabs.Stitch, "stitch", false, "Send the text of adjacent sections with each ElevenLabs request, so prosody flows across sections")
+	flag.UintVar(&config.ElevenLabs.Seed, "elevenlabs-seed", 0, "ElevenLabs sampling seed, so unchanged text produces the same audio (0 = none, up to 4294967295)")
+	flag.BoolVar(&config.ElevenLabs.Deterministic, "deterministic", false, "Reproducible ElevenLabs builds: seed every request (from -elevenlabs-seed or the section text) and refuse to change the model of an output directory")
+
+	// Edge provider options
+	flag.StringVar(&config.Edge.Rate, "edge-rate", "+0%", "Edge speaking rate relative to the voice's default (e.g., +10%, -20%)")
+	flag.StringVar(&config.Edge.Pitch, "edge-pitch", "+0Hz", "Edge pitch relative to the voice's default (e.g., +5Hz, -10%)")
+
+	// Coqui provider options
+	flag.StringVar(&config.Coqui.URL, "coqui-url", coqui.DefaultURL, "Base URL of the Coqui XTTS server (xtts-api-server)")
+	flag.StringVar(&config.Coqui.SpeakerWav, "coqui-speaker-wav", "", "Speaker WAV reference for Coqui XTTS voice cloning (file name in the server's speakers folder or a path on the server)")
+	flag.StringVar(&config.Coqui.Language, "coqui-language", coqui.DefaultLanguage, "Language spoken by Coqui XTTS (e.g., en, it, de)")
+
+	// MaryTTS provider options
+	flag.StringVar(&config.MaryTTS.URL, "marytts-url", marytts.DefaultURL, "Base URL of the MaryTTS or Mimic 3 server")
+	flag.StringVar(&config.MaryTTS.Locale, "marytts-locale", marytts.DefaultLocale, "Locale of the text spoken by MaryTTS (e.g., en_US, de)")
+
+	// Watson provider options
+	flag.StringVar(&config.Watson.URL, "watson-url", "", "IBM Watson Text to Speech service URL (prefer TEXT_TO_SPEECH_URL env var)")
+	flag.StringVar(&config.Watson.APIKey, "watson-api-key", "", "IBM Watson Text to Speech API key (prefer TEXT_TO_SPEECH_APIKEY env var)")
+
+	// Play.ht provider options
+	flag.StringVar(&config.PlayHT.UserID, "playht-user-id", "", "Play.ht user ID (prefer PLAY_HT_USER_ID env var)")
+	flag.StringVar(&config.PlayHT.APIKey, "playht-api-key", "", "Play.ht secret key (prefer PLAY_HT_API_KEY env var)")
+	flag.Float64Var(&config.PlayHT.Speed, "playht-speed", 1.0, "Play.ht speaking speed (0.1-5.0, only for non-timed sections)")
+	flag.StringVar(&config.PlayHT.Emotion, "playht-emotion", "", "Play.ht voice emotion (e.g., female_happy, male_sad)")
+
+	flag.Func("external-providers", "JSON file registering providers implemented by external executables, selected by name with -provider", func(s string) error {
+		if s == "" {
+			config.ExternalProviders = nil
+			return nil
+		}
+		providers, err := external.LoadConfigs(s, BuiltinProviders)
+		if err != nil {
+			return err
+		}
+		config.ExternalProviders = providers
+		return nil
+	})
+	flag.Func("custom-http-config", "JSON file describing the HTTP service of the custom-http provider (URL, headers, body template, response)", func(s string) error {
+		if s == "" {
+			config.CustomHTTP = nil
+			return nil
+		}
+		service, err := customhttp.LoadConfig(s)
+		if err != nil {
+			return err
+		}
+		config.CustomHTTP = &service
+		return nil
+	})
+	flag.DurationVar(&config.Timeout, "timeout", 0, "Time limit of each provider request or local command run (say, espeak, festival, external), e.g. 5m (0 = <PROVIDER>_TIMEOUT env var, else 60s for API requests and no limit for local commands)")
+	flag.DurationVar(&config.FailureCooldown, "failure-cooldown", cache.DefaultFailureCoolDown, "Fail fast for this long after an invalid voice or rejected credentials instead of calling the provider again (0 = disabled)")
+	flag.BoolVar(&config.LocalOnly, "local-only", false, "Refuse cloud providers so document text never leaves the machine (always on when "+EnvLocalOnly+"=1)")
+
+	// Network options for API-based providers
+	flag.StringVar(&config.HTTP.Proxy, "http-proxy", "", "HTTP/HTTPS proxy URL for API requests (default: HTTP_PROXY/HTTPS_PROXY env vars)")
+	flag.StringVar(&config.HTTP.CABundle, "ca-bundle", "", "PEM file with additional CA certificates to trust for API requests")
+	flag.Func("http-header", "Extra header for API requests as 'Name: value' (repeatable)", func(s string) error {
+		name, value, err := httpclient.ParseHeader(s)
+		if err != nil {
+			return err
+		}
+		if config.HTTP.Headers == nil {
+			config.HTTP.Headers = make(map[string]string)
+		}
+		config.HTTP.Headers[name] = value
+		return nil
+	})
+	defaultRetry := httpclient.DefaultRetryPolicy()
+	flag.IntVar(&config.HTTP.Retry.MaxRetries, "http-retries", defaultRetry.MaxRetries, "Retries of failed API requests after the first attempt (0 = never retry)")
+	flag.DurationVar(&config.HTTP.Retry.InitialInterval, "http-retry-initial", defaultRetry.InitialInterval, "Wait before the first retry of a failed API request, growing exponentially with each retry")
+	flag.DurationVar(&config.HTTP.Retry.MaxInterval, "http-retry-max", defaultRetry.MaxInterval, "Longest wait between retries of a failed API request")
+	flag.DurationVar(&config.HTTP.Retry.MaxRetryAfter, "http-retry-after-max", defaultRetry.MaxRetryAfter, "Longest wait honored from a Retry-After header; longer delays are cut to it")
+	config.HTTP.Retry.RetryOn = defaultRetry.RetryOn
+	flag.Func("http-retry-on", "Comma-separated HTTP status codes of API responses that are retried (default "+formatStatusList(defaultRetry.RetryOn)+")", func(s string) error {
+		statuses, err := parseStatusList(s)
+		if err != nil {
+			return err
+		}
+		config.HTTP.Retry.RetryOn = statuses
+		return nil
+	})
 
 	// Common options
 	flag.StringVar(&config.Format, "format", "aiff", "Output audio format (aiff, m4a, mp3)")
 	flag.StringVar(&config.Prefix, "prefix", "section", "Prefix for output filenames")
+	flag.Func("start-index", "Number of the first section in output filenames, or 'auto' to continue the numbering in the output directory manifest", func(s string) error {
+		if strings.EqualFold(s, "auto") {
+			config.StartIndex = StartIndexContinue
+			return nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("must be a positive number or 'auto'")
+		}
+		config.StartIndex = n
+		return nil
+	})
+	flag.StringVar(&config.SlugStyle, "slug-style", text.SlugASCII, "Title slug style in filenames: 'ascii' (transliterated), 'unicode', or 'hash'")
+	flag.IntVar(&config.MaxFilenameLen, "max-filename-len", 0, "Maximum output file name length without extension; colliding names get a numeric suffix (0 = title slugs capped at 50 characters)")
+	flag.BoolVar(&config.TagAudio, "tag-audio", false, "Write the md2audio version and settings into each audio file's metadata comment (requires ffmpeg)")
+	flag.BoolVar(&config.Incremental, "incremental", false, "Keep unchanged sections and re-synthesize only changed sentences of edited ones, splicing them into the existing audio (requires ffmpeg)")
+	flag.BoolVar(&config.SilenceOnly, "silence-only", false, "Write silent audio of each section's target duration without calling any TTS (timing scaffolds)")
+	flag.StringVar(&config.Placeholder, "placeholder", "", "Write a placeholder in place of failed sections to keep the timeline (silence, spoken)")
+	flag.BoolVar(&config.Force, "force", false, "Add audio to an output directory generated with a different provider, voice, or format")
+	flag.StringVar(&config.EmptySection, "empty-section", "", "Text spoken for image- or code-only sections instead of skipping them (e.g., \"This section contains a diagram.\")")
+	flag.StringVar(&config.TransformCmd, "transform-cmd", "", "Command that rewrites each section's text before synthesis (reads stdin, writes stdout)")
+	flag.StringVar(&config.PostCmd, "post-cmd", "", "Command run on each generated audio file (path as last argument), rewriting it in place")
+	flag.StringVar(&config.Redact, "redact", "", "Mask or verbalize emails, phone numbers, API keys, and profanity before synthesis (mask, verbalize)")
+	flag.BoolVar(&config.SSML, "ssml", false, "Interpret SSML markup in section text (espeak and marytts providers only)")
+	flag.BoolVar(&config.Subtitles, "srt", false, "Write SRT subtitles with estimated word timings next to each audio file")
+	flag.BoolVar(&config.ReadAlong, "read-along", false, "Write read-along JSON with sentence offsets and word timings next to each audio file for web players")
+	flag.StringVar(&config.TimingMethod, "timing-method", "syllable", "Word timing estimation method for subtitles (uniform, syllable)")
+	flag.StringVar(&config.Align.Method, "align", "", "Force-align generated audio for accurate word timings (aeneas, whisper)")
+	flag.StringVar(&config.Align.Command, "aligner-cmd", "", "Aligner executable (default: python3 for aeneas, whisper-cli for whisper)")
+	flag.StringVar(&config.Align.WhisperModel, "whisper-model", "", "whisper.cpp model file for -align whisper")
+	flag.StringVar(&config.Align.Language, "align-language", "en", "Language code used for alignment and transcription")
+	flag.BoolVar(&config.Verify.Transcribe, "verify-transcribe", false, "Transcribe generated audio with whisper.cpp and flag sections that differ from the source text")
+	flag.Float64Var(&config.Verify.Threshold, "verify-threshold", 0.25, "Maximum word error rate (0.0-1.0) before -verify-transcribe flags a section")
+	flag.StringVar(&config.Verify.Command, "transcribe-cmd", "", "whisper.cpp executable for -verify-transcribe (default: whisper-cli)")
+	flag.Float64Var(&config.Verify.MinDurationRatio, "min-duration-ratio", 0.4, "Flag sections whose audio is shorter than this fraction of the estimated duration, a sign of truncated synthesis (0 = disabled)")
+	flag.BoolVar(&config.Verify.RetryShort, "retry-short", false, "Regenerate sections flagged by -min-duration-ratio once before flagging them")
+	flag.BoolVar(&config.PronunciationReport, "pronunciation-report", false, "Report identifiers, acronyms, numbers with units and rare words likely to be mispronounced")
+	flag.StringVar(&config.Summary.Path, "summary", "", "Write a compact run summary for chat tools (e.g., summary.md or summary.json)")
+	flag.StringVar(&config.Summary.BaseURL, "summary-url", "", "Base URL for output links in -summary (e.g., http://localhost:8080/)")
+	flag.BoolVar(&config.Summary.Spoken, "spoken-summary", false, "Synthesize a short spoken report of the run (run_summary.<format>) into the output directory")
+	flag.StringVar(&config.Granularity, "granularity", GranularitySection, "Generate one audio file per section or per sentence (section, sentence)")
+	flag.BoolVar(&config.Chapters, "chapters", false, "Treat H2 headings as chapters in numbered subdirectories and their H3 headings as numbered tracks")
+	flag.BoolVar(&config.Site.Assets, "site-assets", false, "Write HTML player snippets and a JSON index keyed by page slug into <output>/site for static site generators")
+	flag.StringVar(&config.Site.BaseURL, "site-url", "", "Base URL for audio links in -site-assets (e.g., /audio/)")
+	flag.BoolVar(&config.ZipPerFile, "zip-per-file", false, "Also package each markdown file's section audio into <filename>.zip in the output root")
+	flag.StringVar(&config.Bundle, "bundle", "", "Package generated audio, manifest, captions, and settings into an archive after the run (e.g., out.tar.zst, out.tar.gz)")
+	flag.BoolVar(&config.Lossless, "lossless", false, "Output provider-native lossless audio (AIFF for say, WAV otherwise), overriding -format")
+
+	// Multi-language options
+	var languages string
+	flag.StringVar(&languages, "languages", "", "Comma-separated language codes; processes <dir>/<lang> into <output>/<lang> (e.g., en,es,fr)")
+	flag.Func("language-voices", "Per-language voices for -languages (e.g., en=Kate,es=Monica)", func(s string) error {
+		voices, err := parseKeyValueList(s)
+		if err != nil {
+			return err
+		}
+		config.LanguageVoices = voices
+		return nil
+	})
 	flag.BoolVar(&config.Commands.ListVoices, "list-voices", false, "List all available voices (uses cache if available)")
-	flag.BoolVar(&config.Commands.RefreshCache, "refresh-cache", false, "Force refresh of voice cache when listing voices")
+	flag.BoolVar(&config.Commands.ListModels, "list-models", false, "List the ElevenLabs models with their languages and character limits (uses cache if available)")
+	flag.BoolVar(&config.Commands.RefreshCache, "refresh-cache", false, "Force refresh of voice cache when listing voices or models")
 	flag.StringVar(&config.Commands.ExportVoices, "export-voices", "", "Export cached voices to JSON file (e.g., voices.json)")
+	flag.StringVar(&config.Commands.VoiceGender, "voice-gender", "", "Only list voices of this gender with -list-voices (female, male)")
+	flag.StringVar(&config.Commands.VoiceLanguage, "voice-lang", "", "Only list voices of this language or locale with -list-voices, or models speaking it with -list-models (e.g., en, en-GB)")
 	flag.BoolVar(&config.Commands.Version, "version", false, "Print version and exit")
 	flag.BoolVar(&config.Commands.Debug, "debug", false, "Enable debug logging")
 	flag.BoolVar(&config.Commands.DryRun, "dry-run", false, "Show what would be generated without creating files")
+	flag.BoolVar(&config.Commands.DryRunRequests, "dry-run-requests", false, "Dry-run that prints the exact provider request (payload, voice settings, model) for each section")
+	flag.BoolVar(&config.Commands.ServeOutput, "serve-output", false, "Serve the output directory (-o) over HTTP with an HTML index for review")
+	flag.StringVar(&config.Commands.ServeAddr, "serve-addr", "localhost:8080", "Listen address for -serve-output")
+	flag.BoolVar(&config.Commands.UI, "ui", false, "Serve a local web UI to convert markdown files from a browser (also: md2audio ui)")
+	flag.StringVar(&config.Commands.UIAddr, "ui-addr", "localhost:8090", "Listen address for -ui")
+	flag.Func("variants", "Comma-separated playback speeds of extra tempo-shifted copies of each output (e.g., 1.25x,1.5x; requires ffmpeg)", func(s string) error {
+		speeds, err := parseVariants(s)
+		if err != nil {
+			return err
+		}
+		config.Variants = speeds
+		return nil
+	})
+
+	var webhookPaths string
+	flag.StringVar(&config.Webhook.Secret, "webhook-secret", "", "Enable a GitHub push webhook at /webhook with -serve-output that pulls -d and regenerates changed files (prefer "+EnvWebhookSecret+" env var)")
+	flag.BoolVar(&config.Webhook.Tenants, "webhook-tenants", false, "Enable the /webhook of -serve-output for -server-tenants, each delivery signed with its tenant's token")
+	flag.StringVar(&webhookPaths, "webhook-paths", "", "Comma-separated repository paths or patterns whose markdown changes trigger the webhook (e.g., docs/,guides/*.md; default: all)")
+	flag.StringVar(&config.Server.TenantsFile, "server-tenants", "", "JSON file of tenants (name, token, output_prefix, input_dir, elevenlabs_api_key) requiring token auth for -serve-output")
+	flag.IntVar(&config.Server.MaxJobs, "server-max-jobs", 1, "Webhook regenerations running at once")
+	flag.IntVar(&config.Server.MaxQueue, "server-max-queue", 16, "Webhook regenerations waiting to run before further requests are refused")
+	flag.IntVar(&config.Server.MaxJobsPerClient, "server-max-jobs-per-client", 0, "Queued or running webhook regenerations per repository (0 = unlimited)")
+	flag.IntVar(&config.Server.MaxBodyKB, "server-max-body-kb", 5120, "Maximum request body size in KiB")
+	flag.IntVar(&config.Server.MaxDocumentKB, "server-max-document-kb", 0, "Skip markdown files larger than this many KiB in webhook regenerations (0 = unlimited)")
+	flag.BoolVar(&config.Commands.DedupReport, "dedup-report", false, "Report audio files with identical content in the output directory (-o)")
+	flag.BoolVar(&config.Commands.DedupLink, "dedup-link", false, "Replace duplicate audio files in the output directory with hard links (implies -dedup-report)")
+	flag.BoolVar(&config.Commands.Retime, "retime", false, "Time-stretch existing audio in -o to the updated timings in -f with ffmpeg instead of regenerating it")
+	flag.StringVar(&config.Commands.ExportSections, "export-sections", "", "Write the parsed sections (titles, cleaned text, timings, word counts, estimated durations) of -f or -d to a JSON or .csv file without generating audio")
+	flag.StringVar(&config.Commands.CloneVoice, "clone-voice", "", "Create an ElevenLabs instant voice clone with this name from the -voice-sample files and print its voice ID")
+	flag.StringVar(&config.Commands.EditVoice, "edit-voice", "", "ElevenLabs voice ID to rename (-voice-name), describe (-voice-description), or add -voice-sample files to")
+	flag.StringVar(&config.Commands.DeleteVoice, "delete-voice", "", "ElevenLabs voice ID to delete from the account")
+	flag.Func("voice-sample", "Audio sample of the speaker for -clone-voice or -edit-voice (repeatable)", func(s string) error {
+		config.Commands.VoiceSamples = append(config.Commands.VoiceSamples, s)
+		return nil
+	})
+	flag.StringVar(&config.Commands.VoiceName, "voice-name", "", "New voice name for -edit-voice")
+	flag.StringVar(&config.Commands.VoiceDescription, "voice-description", "", "Voice description for -clone-voice or -edit-voice")
+	flag.BoolVar(&config.Commands.Quota, "quota", false, "Show the remaining ElevenLabs characters and reset date, and whether the sections of -f or -d fit in them")
+	flag.BoolVar(&config.Commands.Preview, "preview", false, "Play the sections of -f or -d aloud with the say provider instead of writing files, to audition voices and rates")
+	flag.StringVar(&config.Commands.PreviewFilter, "preview-sections", "", "Only play these sections with -preview: numbers, ranges, or words of their titles (e.g., '1,3-4,intro')")
+	flag.BoolVar(&config.Commands.Calibrate, "calibrate", false, "Measure the actual speaking rate of the say/espeak voice at several rates and store it for timed sections")
+	flag.StringVar(&config.Commands.Unbundle, "unbundle", "", "Extract an archive created by -bundle into the output directory (-o)")
+	flag.BoolVar(&config.Commands.History, "history", false, "List recent runs (also: md2audio history)")
+	flag.BoolVar(&config.Commands.Stats, "stats", false, "Show monthly usage per provider from the run history (also: md2audio stats)")
+	flag.IntVar(&config.Commands.HistoryLimit, "history-limit", 20, "Number of runs listed by -history")
+	flag.BoolVar(&config.NoHistory, "no-history", false, "Do not record this run in the run history")
 
 	flag.Usage = func() {
 		log.Default("Markdown to Audio Generator")
@@ -163,6 +848,38 @@ func Parse() Config {
 		log.Faint("  # Generate m4a files")
 		log.Faint(fmt.Sprintf("  %s -d ./docs -p british-female -format m4a", os.Args[0]))
 		log.Blank()
+		log.Faint("  # Quickly sample the first 2 sections of the first 3 files")
+		log.Faint(fmt.Sprintf("  %s -d ./docs -limit 3 -limit-sections 2", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Spot-check a reproducible random 5% of all sections")
+		log.Faint(fmt.Sprintf("  %s -d ./docs -sample 5%% -seed 1234", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Refresh recently edited files first within a 30 minute budget")
+		log.Faint(fmt.Sprintf("  %s -d ./docs -newest-first -max-duration 30m", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Flatten the output tree using a template")
+		log.Faint(fmt.Sprintf("  %s -d ./docs -o './audio/{{.Parent}}_{{.FileName}}'", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Regenerate failed, flagged, or skipped sections from a previous run")
+		log.Faint(fmt.Sprintf("  %s -from-manifest ./audio_sections/manifest.json -only failed", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Review generated audio in the browser")
+		log.Faint(fmt.Sprintf("  %s -serve-output -o ./audio_sections", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Regenerate audio when a GitHub push changes docs/ in this checkout")
+		log.Faint(fmt.Sprintf("  %s -serve-output -d ./repo -o ./audio_sections -webhook-secret $SECRET -webhook-paths docs/", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Find identical audio files and replace copies with hard links")
+		log.Faint(fmt.Sprintf("  %s -dedup-link -o ./audio_sections", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Hand off a complete narration package, then unpack it elsewhere")
+		log.Faint(fmt.Sprintf("  %s -d ./docs -srt -bundle narration.tar.zst", os.Args[0]))
+		log.Faint(fmt.Sprintf("  %s -unbundle narration.tar.zst -o ./narration", os.Args[0]))
+		log.Blank()
+		log.Faint("  # Review past runs and monthly usage per provider")
+		log.Faint(fmt.Sprintf("  %s history -history-limit 5", os.Args[0]))
+		log.Faint(fmt.Sprintf("  %s stats", os.Args[0]))
+		log.Blank()
 		log.Faint("  # List available say voices")
 		log.Faint(fmt.Sprintf("  %s -list-voices", os.Args[0]))
 		log.Blank()
@@ -183,13 +900,57 @@ func Parse() Config {
 		log.Faint("  australian-female, indian-female")
 	}
 
+	// Every option can also be set with an MD2AUDIO_<OPTION> environment variable
+	for _, err := range applyEnvFlags(flag.CommandLine) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
 	flag.Parse()
 
+	// "md2audio history", "md2audio stats", and "md2audio ui" are aliases for -history, -stats, and -ui
+	switch flag.Arg(0) {
+	case "history":
+		config.Commands.History = true
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+	case "stats":
+		config.Commands.Stats = true
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+	case "ui":
+		config.Commands.UI = true
+		_ = flag.CommandLine.Parse(flag.Args()[1:])
+	}
+
+	// The environment variable cannot be overridden with -local-only=false
+	if getEnvBool(EnvLocalOnly, false) {
+		config.LocalOnly = true
+	}
+
 	// Return early if version flag is set (skip all initialization)
 	if config.Commands.Version {
 		return config
 	}
 
+	// Linking duplicates reports them first
+	if config.Commands.DedupLink {
+		config.Commands.DedupReport = true
+	}
+
+	// Request previews are a dry-run
+	if config.Commands.DryRunRequests {
+		config.Commands.DryRun = true
+	}
+
+	if path, err := cache.DefaultPath(); err == nil {
+		config.HistoryDB = path
+	}
+
+	config.Languages = parseList(languages)
+	config.Webhook.Paths = parseList(webhookPaths)
+	config.Rerun.Only = parseList(only)
+
+	// -voice applies to the selected provider unless a provider-specific flag is set
+	config.applyVoice(voice)
+
 	// Determine voice to use (for say and espeak providers)
 	if config.Provider == "say" || config.Provider == "espeak" || config.Provider == "" {
 		if config.Say.Voice != "" {
@@ -202,7 +963,7 @@ func Parse() Config {
 				fmt.Printf("Unknown preset: %s, using default voice 'Kate'\n", preset)
 				config.Say.Voice = "Kate"
 			}
-		} else if !config.Commands.ListVoices {
+		} else if !config.Commands.ListVoices && len(config.VoiceCriteria) == 0 {
 			config.Say.Voice = "Kate"
 			fmt.Println("No voice specified, using default: Kate")
 		}
@@ -214,11 +975,34 @@ func Parse() Config {
 	}
 
 	// Set default ElevenLabs voice if not specified and not listing voices
-	if config.Provider == "elevenlabs" && config.ElevenLabs.VoiceID == "" && !config.Commands.ListVoices {
+	if config.Provider == "elevenlabs" && config.ElevenLabs.VoiceID == "" && !config.Commands.ListVoices && len(config.VoiceCriteria) == 0 {
 		config.ElevenLabs.VoiceID = DefaultElevenLabsVoiceID
 		fmt.Println("No ElevenLabs voice specified, using default: Rachel (21m00Tcm4TlvDq8ikWAM)")
 	}
 
+	// Set default Edge voice if not specified and not listing voices
+	if config.Provider == "edge" && config.Say.Voice == "" && !config.Commands.ListVoices && len(config.VoiceCriteria) == 0 {
+		config.Say.Voice = edge.DefaultVoice
+		fmt.Printf("No Edge voice specified, using default: %s\n", edge.DefaultVoice)
+	}
+
+	// Set default Watson voice if not specified and not listing voices
+	if config.Provider == "watson" && config.Say.Voice == "" && !config.Commands.ListVoices && len(config.VoiceCriteria) == 0 {
+		config.Say.Voice = watson.DefaultVoice
+		fmt.Printf("No Watson voice specified, using default: %s\n", watson.DefaultVoice)
+	}
+
+	// Set default Play.ht voice if not specified and not listing voices
+	if config.Provider == "playht" && config.Say.Voice == "" && !config.Commands.ListVoices && len(config.VoiceCriteria) == 0 {
+		config.Say.Voice = playht.DefaultVoice
+		fmt.Println("No Play.ht voice specified, using default: Jennifer")
+	}
+
+	// The Coqui speaker reference is the voice unless -v is set
+	if config.Provider == "coqui" && config.Say.Voice == "" {
+		config.Say.Voice = config.Coqui.SpeakerWav
+	}
+
 	// Load ElevenLabs voice settings from environment variables (with defaults)
 	if config.Provider == "elevenlabs" {
 		config.ElevenLabs.VoiceSettings.Stability = getEnvFloat("ELEVENLABS_STABILITY", 0.5)
@@ -241,6 +1025,16 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// getEnvInt retrieves an int value from environment variable with a default fallback
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 // getEnvBool retrieves a bool value from environment variable with a default fallback
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -251,6 +1045,89 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// parseList splits a comma-separated list, trimming whitespace and dropping empty items
+func parseList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseStatusList parses a comma-separated list of HTTP status codes such as "429,503"
+func parseStatusList(value string) ([]int, error) {
+	statuses := []int{}
+	for _, item := range parseList(value) {
+		status, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", item)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// formatStatusList formats HTTP status codes as a comma-separated list
+func formatStatusList(statuses []int) string {
+	items := make([]string, len(statuses))
+	for i, status := range statuses {
+		items[i] = strconv.Itoa(status)
+	}
+	return strings.Join(items, ",")
+}
+
+// parseVariants parses a comma-separated list of playback speeds such as "1.25x,1.5x"
+func parseVariants(value string) ([]float64, error) {
+	var speeds []float64
+	for _, item := range parseList(value) {
+		speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(item), "x"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid speed %q: expected e.g. 1.25x", item)
+		}
+		speeds = append(speeds, speed)
+	}
+	return speeds, nil
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs
+func parseKeyValueList(value string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, item := range parseList(value) {
+		key, val, ok := strings.Cut(item, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !ok || key == "" || val == "" {
+			return nil, fmt.Errorf("invalid entry %q: expected key=value", item)
+		}
+		pairs[key] = val
+	}
+	return pairs, nil
+}
+
+// ForLanguage returns a copy of the configuration targeting a single language.
+// The input and output directories are narrowed to the language subdirectory and the
+// per-language voice (if configured) replaces the voice of the active provider.
+func (c Config) ForLanguage(lang string) Config {
+	langCfg := c
+	langCfg.Languages = nil
+	langCfg.Language = lang
+	langCfg.InputDir = filepath.Join(c.InputDir, lang)
+	if !parser.IsOutputTemplate(c.OutputDir) {
+		langCfg.OutputDir = filepath.Join(c.OutputDir, lang)
+	}
+
+	if voice, ok := c.LanguageVoices[lang]; ok {
+		if c.Provider == "elevenlabs" {
+			langCfg.ElevenLabs.VoiceID = voice
+		} else {
+			langCfg.Say.Voice = voice
+		}
+	}
+
+	return langCfg
+}
+
 // Validate checks if the configuration is valid
 func (c Config) Validate() error {
 	// Check mutual exclusivity of -f and -d
@@ -258,26 +1135,261 @@ func (c Config) Validate() error {
 		return fmt.Errorf("cannot use both -f and -d flags; use one or the other")
 	}
 
+	// Manifest re-runs replace -f and -d
+	if c.Rerun.Manifest != "" {
+		if c.MarkdownFile != "" || c.InputDir != "" {
+			return fmt.Errorf("cannot use -from-manifest with -f or -d")
+		}
+		for _, status := range c.Rerun.Only {
+			if status != string(manifest.StatusFailed) && status != string(manifest.StatusFlagged) && status != string(manifest.StatusSkipped) {
+				return fmt.Errorf("invalid -only value %q: must be 'failed', 'flagged', or 'skipped'", status)
+			}
+		}
+	} else if len(c.Rerun.Only) > 0 {
+		return fmt.Errorf("-only requires -from-manifest")
+	}
+
 	// Check that at least one input is provided (unless listing voices)
-	if !c.Commands.ListVoices && c.MarkdownFile == "" && c.InputDir == "" {
+	if !c.Commands.ListVoices && c.MarkdownFile == "" && c.InputDir == "" && c.Rerun.Manifest == "" {
 		return fmt.Errorf("either -f (file) or -d (directory) is required")
 	}
 
+	// ffmpeg atempo accepts speeds between 0.5 and 2
+	for _, speed := range c.Variants {
+		if speed < 0.5 || speed > 2 {
+			return fmt.Errorf("invalid -variants speed %gx: must be between 0.5x and 2x", speed)
+		}
+	}
+
+	// Validate sampling limits
+	if c.Limit < 0 {
+		return fmt.Errorf("invalid -limit %d: must be 0 or greater", c.Limit)
+	}
+	if c.LimitSections < 0 {
+		return fmt.Errorf("invalid -limit-sections %d: must be 0 or greater", c.LimitSections)
+	}
+
+	order, err := parser.ParseOrder(c.Order)
+	if err != nil {
+		return err
+	}
+	if _, err := parser.ParseInputFormat(c.InputFormat); err != nil {
+		return err
+	}
+	if c.NewestFirst && order.Kind != parser.OrderDoc && order.Kind != parser.OrderMtime {
+		return fmt.Errorf("cannot use -newest-first with -order %s", order)
+	}
+
+	if c.MaxDuration < 0 {
+		return fmt.Errorf("invalid -max-duration %s: must be 0 or greater", c.MaxDuration)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("invalid -timeout %s: must be 0 or greater", c.Timeout)
+	}
+	if c.FailureCooldown < 0 {
+		return fmt.Errorf("invalid -failure-cooldown %s: must be 0 or greater", c.FailureCooldown)
+	}
+	if c.MaxAPICalls < 0 {
+		return fmt.Errorf("invalid -max-api-calls %d: must be 0 or greater", c.MaxAPICalls)
+	}
+
+	if c.Sample != "" {
+		if _, err := c.SampleFraction(); err != nil {
+			return err
+		}
+	}
+
 	// Validate provider
-	if c.Provider != "say" && c.Provider != "espeak" && c.Provider != "elevenlabs" {
-		return fmt.Errorf("invalid provider %q: must be 'say', 'espeak', or 'elevenlabs'", c.Provider)
+	for _, ext := range c.ExternalProviders {
+		if slices.Contains(BuiltinProviders, ext.Name) {
+			return fmt.Errorf("invalid -external-providers: %q is the name of a built-in provider", ext.Name)
+		}
+	}
+	if _, ok := c.ExternalProvider(c.Provider); !ok && !slices.Contains(BuiltinProviders, c.Provider) {
+		return fmt.Errorf("invalid provider %q: must be 'say', 'espeak', 'festival', 'elevenlabs', 'edge', 'coqui', 'marytts', 'watson', 'playht', 'custom-http', registered with -external-providers, or an md2audio-provider-<name> executable on PATH", c.Provider)
+	}
+
+	if err := c.checkLocalOnly(); err != nil {
+		return err
 	}
 
 	// Validate provider-specific requirements
-	if c.Provider == "elevenlabs" && !c.Commands.ListVoices {
+	if c.Provider == "elevenlabs" && !c.Commands.ListVoices && !c.SilenceOnly {
 		if c.ElevenLabs.VoiceID == "" {
 			return fmt.Errorf("ElevenLabs voice ID is required: use -elevenlabs-voice-id flag")
 		}
 	}
+	if c.ElevenLabs.Seed > math.MaxUint32 {
+		return fmt.Errorf("-elevenlabs-seed must be at most %d", uint32(math.MaxUint32))
+	}
+	if !c.HTTP.Retry.IsZero() {
+		if err := c.HTTP.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid HTTP retry options: %w", err)
+		}
+	}
+	if c.Provider == "coqui" && c.Say.Voice == "" && !c.Commands.ListVoices && !c.SilenceOnly {
+		return fmt.Errorf("Coqui speaker WAV reference is required: use -coqui-speaker-wav or -v")
+	}
+	if c.Provider == "edge" && c.Edge.Rate != "" {
+		if err := edge.ValidateRate(c.Edge.Rate); err != nil {
+			return err
+		}
+	}
+	if c.Provider == "espeak" {
+		if err := c.Espeak.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Provider == "edge" && c.Edge.Pitch != "" {
+		if err := edge.ValidatePitch(c.Edge.Pitch); err != nil {
+			return err
+		}
+	}
+	if c.Provider == customhttp.Name && c.CustomHTTP == nil {
+		return fmt.Errorf("the custom-http provider requires a valid -custom-http-config file")
+	}
+	if c.Provider == "playht" {
+		if c.PlayHT.Speed != 0 {
+			if err := playht.ValidateSpeed(c.PlayHT.Speed); err != nil {
+				return err
+			}
+		}
+		if err := playht.ValidateEmotion(c.PlayHT.Emotion); err != nil {
+			return err
+		}
+	}
+
+	if c.Redact != "" {
+		if _, err := redact.ParseMode(c.Redact); err != nil {
+			return err
+		}
+	}
+
+	if c.SlugStyle != "" && !slices.Contains(text.SlugStyles, c.SlugStyle) {
+		return fmt.Errorf("invalid -slug-style %q: must be 'ascii', 'unicode', or 'hash'", c.SlugStyle)
+	}
+	if c.MaxFilenameLen < 0 {
+		return fmt.Errorf("invalid -max-filename-len %d: must be 0 or greater", c.MaxFilenameLen)
+	}
+
+	if c.Granularity != "" && c.Granularity != GranularitySection && c.Granularity != GranularitySentence {
+		return fmt.Errorf("invalid -granularity %q: must be 'section' or 'sentence'", c.Granularity)
+	}
+
+	if c.Chapters && c.StartIndex != 0 {
+		return fmt.Errorf("-start-index cannot be used with -chapters: chapters and tracks are numbered from the document")
+	}
+
+	if c.Placeholder != "" {
+		if err := audio.ValidatePlaceholder(c.Placeholder); err != nil {
+			return err
+		}
+	}
+
+	if c.SilenceOnly && (c.Align.Method != "" || c.Verify.Transcribe) {
+		return fmt.Errorf("cannot use -silence-only with -align or -verify-transcribe")
+	}
+
+	// Validate the bundle archive type
+	if c.Bundle != "" {
+		if c.Commands.DryRun {
+			return fmt.Errorf("cannot use -bundle with -dry-run")
+		}
+		if err := bundle.ValidatePath(c.Bundle); err != nil {
+			return err
+		}
+	}
+
+	// SSML markup is only understood by espeak-ng and MaryTTS
+	if c.SSML && c.Provider != "espeak" && c.Provider != "marytts" {
+		return fmt.Errorf("-ssml is only supported by the espeak and marytts providers")
+	}
+
+	// Validate subtitle timing method
+	if c.TimingMethod != "" {
+		if _, err := timing.ParseMethod(c.TimingMethod); err != nil {
+			return err
+		}
+	}
+
+	// Validate aligner selection
+	if c.Align.Method != "" && c.Align.Method != "aeneas" && c.Align.Method != "whisper" {
+		return fmt.Errorf("invalid aligner %q: must be 'aeneas' or 'whisper'", c.Align.Method)
+	}
+	if c.Align.Method == "whisper" && c.Align.WhisperModel == "" {
+		return fmt.Errorf("-align whisper requires -whisper-model")
+	}
+
+	// Validate transcription check
+	if c.Verify.Transcribe {
+		if c.Align.WhisperModel == "" {
+			return fmt.Errorf("-verify-transcribe requires -whisper-model")
+		}
+		if c.Verify.Threshold < 0 || c.Verify.Threshold > 1 {
+			return fmt.Errorf("invalid -verify-threshold %.2f: must be between 0.0 and 1.0", c.Verify.Threshold)
+		}
+	}
+	if c.Verify.MinDurationRatio < 0 || c.Verify.MinDurationRatio > 1 {
+		return fmt.Errorf("invalid -min-duration-ratio %.2f: must be between 0.0 and 1.0", c.Verify.MinDurationRatio)
+	}
+
+	// Multi-language runs read per-language subdirectories
+	if len(c.Languages) > 0 && c.InputDir == "" {
+		return fmt.Errorf("-languages requires directory mode (-d)")
+	}
+	// Templates are not nested in language subdirectories, so without the language they would overwrite each other
+	if len(c.Languages) > 1 && parser.IsOutputTemplate(c.OutputDir) && !strings.Contains(c.OutputDir, ".Lang") {
+		return fmt.Errorf("-o template %q must contain {{.Lang}} with more than one language in -languages", c.OutputDir)
+	}
+
+	// Validate output directory template syntax
+	if parser.IsOutputTemplate(c.OutputDir) {
+		if _, err := parser.ParseOutputTemplate(c.OutputDir); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// WebhookEnabled reports whether -serve-output accepts GitHub push webhooks,
+// signed with -webhook-secret or, with -webhook-tenants, with the tenants'
+// tokens. Tenants alone only require token authentication to browse.
+func (c Config) WebhookEnabled() bool {
+	return c.Webhook.Secret != "" || c.Webhook.Tenants
+}
+
+// ValidateWebhook checks the webhook configuration of -serve-output
+func (c Config) ValidateWebhook() error {
+	if len(c.Tenants) > 0 && c.Webhook.Secret != "" {
+		return fmt.Errorf("cannot use -webhook-secret with -server-tenants; tenants sign webhooks with their tokens (-webhook-tenants)")
+	}
+	if c.Webhook.Tenants && len(c.Tenants) == 0 {
+		return fmt.Errorf("-webhook-tenants requires -server-tenants")
+	}
+	if !c.WebhookEnabled() {
+		if len(c.Webhook.Paths) > 0 {
+			return fmt.Errorf("-webhook-paths requires -webhook-secret or -webhook-tenants")
+		}
+		return nil
+	}
+	if c.InputDir == "" && len(c.Tenants) == 0 {
+		return fmt.Errorf("-webhook-secret requires -d with the git checkout to regenerate")
+	}
+	for _, tenant := range c.Tenants {
+		if tenant.InputDir == "" && c.InputDir == "" {
+			return fmt.Errorf("tenant %q has no input_dir; set it in the tenants file or use -d", tenant.Name)
+		}
+	}
+	if c.Server.MaxJobs < 0 || c.Server.MaxQueue < 0 || c.Server.MaxJobsPerClient < 0 || c.Server.MaxBodyKB < 0 || c.Server.MaxDocumentKB < 0 {
+		return fmt.Errorf("server limits must be 0 or greater")
+	}
+	if err := c.checkLocalOnly(); err != nil {
+		return err
+	}
+	return nil
+}
+
 // IsDirectoryMode returns true if processing a directory
 func (c Config) IsDirectoryMode() bool {
 	return c.InputDir != ""
@@ -299,28 +1411,144 @@ func maskSecret(secret string) string {
 // NOTE: This method is safe for logging - sensitive data (API keys) are never printed
 func (c Config) Print() {
 	fmt.Println("\nConfiguration:")
-	if c.IsDirectoryMode() {
+	switch {
+	case c.Rerun.Manifest != "":
+		fmt.Printf("  Manifest: %s\n", c.Rerun.Manifest)
+	case c.IsDirectoryMode():
 		fmt.Printf("  Input directory: %s\n", c.InputDir)
-	} else {
+	default:
 		fmt.Printf("  Markdown file: %s\n", c.MarkdownFile)
 	}
 	fmt.Printf("  TTS Provider: %s\n", c.Provider)
+	if c.LocalOnly {
+		fmt.Println("  Local only: yes (cloud providers refused)")
+	}
+	if c.SilenceOnly {
+		fmt.Println("  Silence only: yes (no TTS calls)")
+	}
+	if c.Granularity == GranularitySentence {
+		fmt.Println("  Granularity: one file per sentence")
+	}
+	if c.Chapters {
+		fmt.Println("  Chapters: H2 chapters, H3 tracks")
+	}
+	if timeout := c.ProviderTimeout(c.Provider); timeout > 0 {
+		fmt.Printf("  Timeout: %s\n", timeout)
+	}
 
 	// Provider-specific configuration
 	switch c.Provider {
 	case "say":
 		fmt.Printf("  Voice: %s\n", c.Say.Voice)
 		fmt.Printf("  Rate: %d\n", c.Say.Rate)
+	case "espeak":
+		fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		fmt.Printf("  Rate: %d\n", c.Say.Rate)
+		if c.Espeak.Pitch > 0 {
+			fmt.Printf("  Pitch: %d\n", c.Espeak.Pitch)
+		}
+		if c.Espeak.Amplitude > 0 {
+			fmt.Printf("  Amplitude: %d\n", c.Espeak.Amplitude)
+		}
+		if c.Espeak.WordGap > 0 {
+			fmt.Printf("  Word gap: %d\n", c.Espeak.WordGap)
+		}
+		if c.Espeak.Variant != "" {
+			fmt.Printf("  Variant: %s\n", c.Espeak.Variant)
+		}
 	case "elevenlabs":
 		fmt.Printf("  Voice ID: %s\n", c.ElevenLabs.VoiceID)
 		fmt.Printf("  Model: %s\n", c.ElevenLabs.Model)
+		if c.ElevenLabs.Stream {
+			fmt.Println("  Streaming: enabled")
+		}
+		if c.ElevenLabs.Stitch {
+			fmt.Println("  Stitching: enabled")
+		}
+		if c.ElevenLabs.Deterministic {
+			fmt.Println("  Deterministic: enabled")
+		}
+		if c.ElevenLabs.Seed != 0 {
+			fmt.Printf("  Seed: %d\n", c.ElevenLabs.Seed)
+		}
 		// API key is intentionally not printed for security
 		// If debugging is needed, check environment variable ELEVENLABS_API_KEY
 		if c.ElevenLabs.APIKey != "" {
 			fmt.Printf("  API Key: %s\n", maskSecret(c.ElevenLabs.APIKey))
 		}
+		if c.HTTP.Proxy != "" {
+			fmt.Printf("  Proxy: %s\n", c.HTTP.Proxy)
+		}
+		if c.HTTP.CABundle != "" {
+			fmt.Printf("  CA Bundle: %s\n", c.HTTP.CABundle)
+		}
+		if len(c.HTTP.Headers) > 0 {
+			fmt.Printf("  Extra Headers: %d\n", len(c.HTTP.Headers))
+		}
+		if !c.HTTP.Retry.IsZero() {
+			fmt.Printf("  Retries: %d (%s to %s backoff, Retry-After up to %s, on %s)\n", c.HTTP.Retry.MaxRetries, c.HTTP.Retry.InitialInterval, c.HTTP.Retry.MaxInterval, c.HTTP.Retry.MaxRetryAfter, formatStatusList(c.HTTP.Retry.RetryOn))
+		}
+	case "coqui":
+		fmt.Printf("  Server: %s\n", c.Coqui.URL)
+		fmt.Printf("  Speaker: %s\n", c.Say.Voice)
+		fmt.Printf("  Language: %s\n", c.Coqui.Language)
+	case "marytts":
+		fmt.Printf("  Server: %s\n", c.MaryTTS.URL)
+		if c.Say.Voice != "" {
+			fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		}
+		fmt.Printf("  Locale: %s\n", c.MaryTTS.Locale)
+	case "edge":
+		fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		fmt.Printf("  Rate: %s\n", c.Edge.Rate)
+		fmt.Printf("  Pitch: %s\n", c.Edge.Pitch)
+		if c.HTTP.Proxy != "" {
+			fmt.Printf("  Proxy: %s\n", c.HTTP.Proxy)
+		}
+	case customhttp.Name:
+		if c.CustomHTTP != nil {
+			fmt.Printf("  Service: %s\n", c.CustomHTTP.URL)
+		}
+		if c.Say.Voice != "" {
+			fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		}
+	case "playht":
+		fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		fmt.Printf("  Speed: %.2f\n", c.PlayHT.Speed)
+		if c.PlayHT.Emotion != "" {
+			fmt.Printf("  Emotion: %s\n", c.PlayHT.Emotion)
+		}
+		if c.HTTP.Proxy != "" {
+			fmt.Printf("  Proxy: %s\n", c.HTTP.Proxy)
+		}
+	case "watson":
+		fmt.Printf("  Voice: %s\n", c.Say.Voice)
+		if c.Watson.URL != "" {
+			fmt.Printf("  Service URL: %s\n", c.Watson.URL)
+		}
+		if c.Watson.APIKey != "" {
+			fmt.Printf("  API Key: %s\n", maskSecret(c.Watson.APIKey))
+		}
+		if c.HTTP.Proxy != "" {
+			fmt.Printf("  Proxy: %s\n", c.HTTP.Proxy)
+		}
+	default:
+		if ext, ok := c.ExternalProvider(c.Provider); ok {
+			fmt.Printf("  Command: %s\n", ext.Command)
+			if c.Say.Voice != "" {
+				fmt.Printf("  Voice: %s\n", c.Say.Voice)
+			}
+		}
+	}
+
+	if len(c.Languages) > 0 {
+		fmt.Printf("  Languages: %s\n", strings.Join(c.Languages, ", "))
 	}
 
-	fmt.Printf("  Format: %s\n", c.Format)
+	if c.Lossless {
+		fmt.Printf("  Format: %s (lossless)\n", c.OutputFormat())
+	} else {
+		fmt.Printf("  Format: %s\n", c.Format)
+	}
 	fmt.Printf("  Output directory: %s\n\n", c.OutputDir)
 }
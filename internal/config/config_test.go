@@ -3,10 +3,20 @@ package config
 import (
 	"bytes"
 	"flag"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts/customhttp"
+	"github.com/indaco/md2audio/internal/tts/external"
 )
 
 func TestVoicePresets(t *testing.T) {
@@ -250,97 +260,700 @@ func TestConfigValidate(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name: "valid directory mode",
+			name: "valid directory mode",
+			config: Config{
+				InputDir: "./docs",
+				Provider: "say",
+			},
+			expectError: false,
+		},
+		{
+			name: "both file and directory",
+			config: Config{
+				MarkdownFile: "test.md",
+				InputDir:     "./docs",
+			},
+			expectError: true,
+			errorMsg:    "cannot use both -f and -d flags",
+		},
+		{
+			name: "neither file nor directory",
+			config: Config{
+				MarkdownFile: "",
+				InputDir:     "",
+			},
+			expectError: true,
+			errorMsg:    "either -f (file) or -d (directory) is required",
+		},
+		{
+			name: "list voices mode ignores missing input",
+			config: Config{
+				MarkdownFile: "",
+				InputDir:     "",
+				Provider:     "say",
+				Commands: CommandFlags{
+					ListVoices: true,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid say provider",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+			},
+			expectError: false,
+		},
+		{
+			name: "valid espeak provider",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+			},
+			expectError: false,
+		},
+		{
+			name: "valid elevenlabs provider with voice ID",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+				ElevenLabs: ElevenLabsConfig{
+					VoiceID: "21m00Tcm4TlvDq8ikWAM",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "elevenlabs provider without voice ID",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+			},
+			expectError: true,
+			errorMsg:    "ElevenLabs voice ID is required",
+		},
+		{
+			name: "valid edge provider with rate and pitch",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "edge",
+				Edge:         EdgeConfig{Rate: "+10%", Pitch: "-5Hz"},
+			},
+			expectError: false,
+		},
+		{
+			name: "edge provider with invalid rate",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "edge",
+				Edge:         EdgeConfig{Rate: "fast"},
+			},
+			expectError: true,
+			errorMsg:    "invalid Edge rate",
+		},
+		{
+			name: "playht provider with invalid emotion",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "playht",
+				PlayHT:       PlayHTConfig{Speed: 1.0, Emotion: "bored"},
+			},
+			expectError: true,
+			errorMsg:    "invalid Play.ht emotion",
+		},
+		{
+			name: "invalid provider name",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "invalid-provider",
+			},
+			expectError: true,
+			errorMsg:    "invalid provider",
+		},
+		{
+			name: "elevenlabs list voices without voice ID is ok",
+			config: Config{
+				Provider: "elevenlabs",
+				Commands: CommandFlags{
+					ListVoices: true,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "valid output directory template",
+			config: Config{
+				InputDir:  "./docs",
+				Provider:  "say",
+				OutputDir: "./audio/{{.RelDir}}/{{.FileName}}",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid timing method",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				TimingMethod: "random",
+			},
+			expectError: true,
+			errorMsg:    "invalid timing method",
+		},
+		{
+			name: "invalid aligner",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Align:        AlignConfig{Method: "gentle"},
+			},
+			expectError: true,
+			errorMsg:    "invalid aligner",
+		},
+		{
+			name: "whisper aligner without model",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Align:        AlignConfig{Method: "whisper"},
+			},
+			expectError: true,
+			errorMsg:    "-align whisper requires -whisper-model",
+		},
+		{
+			name: "negative limit",
+			config: Config{
+				InputDir: "./docs",
+				Provider: "say",
+				Limit:    -1,
+			},
+			expectError: true,
+			errorMsg:    "invalid -limit -1",
+		},
+		{
+			name: "negative section limit",
+			config: Config{
+				MarkdownFile:  "test.md",
+				Provider:      "say",
+				LimitSections: -2,
+			},
+			expectError: true,
+			errorMsg:    "invalid -limit-sections -2",
+		},
+		{
+			name: "variant speed out of range",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Variants:     []float64{1.25, 2.5},
+			},
+			expectError: true,
+			errorMsg:    "invalid -variants speed 2.5x",
+		},
+		{
+			name: "valid sample",
+			config: Config{
+				InputDir: "./docs",
+				Provider: "say",
+				Sample:   "5%",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid order",
+			config: Config{
+				InputDir: "./docs",
+				Provider: "say",
+				Order:    "size",
+			},
+			expectError: true,
+			errorMsg:    "invalid -order \"size\"",
+		},
+		{
+			name: "newest first with conflicting order",
+			config: Config{
+				InputDir:    "./docs",
+				Provider:    "say",
+				Order:       "alpha",
+				NewestFirst: true,
+			},
+			expectError: true,
+			errorMsg:    "cannot use -newest-first with -order alpha",
+		},
+		{
+			name: "unsupported bundle type",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Bundle:       "out.zip",
+			},
+			expectError: true,
+			errorMsg:    "unsupported bundle",
+		},
+		{
+			name: "bundle with dry run",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Bundle:       "out.tar.zst",
+				Commands:     CommandFlags{DryRun: true},
+			},
+			expectError: true,
+			errorMsg:    "cannot use -bundle with -dry-run",
+		},
+		{
+			name: "local only with espeak",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+				LocalOnly:    true,
+			},
+			expectError: false,
+		},
+		{
+			name: "local only with cloud provider",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+				ElevenLabs:   ElevenLabsConfig{VoiceID: "voice-123"},
+				LocalOnly:    true,
+			},
+			expectError: true,
+			errorMsg:    `-local-only: refusing to send document text to the cloud provider "elevenlabs"`,
+		},
+		{
+			name: "local only with local coqui server",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "coqui",
+				Say:          SayConfig{Voice: "narrator.wav"},
+				Coqui:        CoquiConfig{URL: "http://127.0.0.1:8020"},
+				LocalOnly:    true,
+			},
+			expectError: false,
+		},
+		{
+			name: "local only with remote coqui server",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "coqui",
+				Say:          SayConfig{Voice: "narrator.wav"},
+				Coqui:        CoquiConfig{URL: "http://gpu-box.lan:8020"},
+				LocalOnly:    true,
+			},
+			expectError: true,
+			errorMsg:    `refusing to send document text to the cloud provider "coqui"`,
+		},
+		{
+			name: "local only with local marytts server",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "marytts",
+				MaryTTS:      MaryTTSConfig{URL: "http://localhost:59125"},
+				LocalOnly:    true,
+				SSML:         true,
+			},
+			expectError: false,
+		},
+		{
+			name: "local only with remote marytts server",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "marytts",
+				MaryTTS:      MaryTTSConfig{URL: "https://tts.example.com"},
+				LocalOnly:    true,
+			},
+			expectError: true,
+			errorMsg:    `refusing to send document text to the cloud provider "marytts"`,
+		},
+		{
+			name: "coqui without speaker reference",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "coqui",
+			},
+			expectError: true,
+			errorMsg:    "Coqui speaker WAV reference is required",
+		},
+		{
+			name: "custom-http without service file",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "custom-http",
+			},
+			expectError: true,
+			errorMsg:    "requires a valid -custom-http-config file",
+		},
+		{
+			name: "local only with custom-http service on localhost",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "custom-http",
+				CustomHTTP:   &customhttp.Config{URL: "http://localhost:5002/api/tts"},
+				LocalOnly:    true,
+			},
+			expectError: false,
+		},
+		{
+			name: "local only with watson provider",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "watson",
+				Say:          SayConfig{Voice: "en-US_AllisonV3Voice"},
+				LocalOnly:    true,
+			},
+			expectError: true,
+			errorMsg:    `refusing to send document text to the cloud provider "watson"`,
+		},
+		{
+			name: "invalid slug style",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				SlugStyle:    "pinyin",
+			},
+			expectError: true,
+			errorMsg:    "invalid -slug-style",
+		},
+		{
+			name: "invalid min duration ratio",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Verify:       VerifyConfig{MinDurationRatio: 1.5},
+			},
+			expectError: true,
+			errorMsg:    "invalid -min-duration-ratio",
+		},
+		{
+			name: "negative failure cooldown",
+			config: Config{
+				MarkdownFile:    "test.md",
+				Provider:        "say",
+				FailureCooldown: -time.Minute,
+			},
+			expectError: true,
+			errorMsg:    "invalid -failure-cooldown",
+		},
+		{
+			name: "external provider",
+			config: Config{
+				MarkdownFile:      "test.md",
+				Provider:          "acme",
+				ExternalProviders: []external.Config{{Name: "acme", Command: "acme-tts"}},
+			},
+			expectError: false,
+		},
+		{
+			name: "local only with external cloud provider",
+			config: Config{
+				MarkdownFile:      "test.md",
+				Provider:          "acme",
+				ExternalProviders: []external.Config{{Name: "acme", Command: "acme-tts", Cloud: true}},
+				LocalOnly:         true,
+			},
+			expectError: true,
+			errorMsg:    `-local-only: refusing to send document text to the cloud provider "acme"`,
+		},
+		{
+			name: "local only with a registration named after a built-in cloud provider",
+			config: Config{
+				MarkdownFile:      "test.md",
+				Provider:          "elevenlabs",
+				ElevenLabs:        ElevenLabsConfig{VoiceID: "voice-123"},
+				ExternalProviders: []external.Config{{Name: "elevenlabs", Command: "true"}},
+				LocalOnly:         true,
+			},
+			expectError: true,
+			errorMsg:    `"elevenlabs" is the name of a built-in provider`,
+		},
+		{
+			name: "invalid redact mode",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Redact:       "remove",
+			},
+			expectError: true,
+			errorMsg:    "invalid -redact",
+		},
+		{
+			name: "silence placeholder",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Placeholder:  "silence",
+			},
+			expectError: false,
+		},
+		{
+			name: "silence only with elevenlabs and no voice ID",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+				Format:       "mp3",
+				SilenceOnly:  true,
+			},
+			expectError: false,
+		},
+		{
+			name: "silence only with verification",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				SilenceOnly:  true,
+				Verify:       VerifyConfig{Transcribe: true},
+			},
+			expectError: true,
+			errorMsg:    "cannot use -silence-only with -align or -verify-transcribe",
+		},
+		{
+			name: "sentence granularity",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Granularity:  "sentence",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid granularity",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Granularity:  "word",
+			},
+			expectError: true,
+			errorMsg:    "invalid -granularity",
+		},
+		{
+			name: "chapters with start index",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Chapters:     true,
+				StartIndex:   StartIndexContinue,
+			},
+			expectError: true,
+			errorMsg:    "-start-index cannot be used with -chapters",
+		},
+		{
+			name: "invalid placeholder",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Placeholder:  "beep",
+			},
+			expectError: true,
+			errorMsg:    "invalid -placeholder",
+		},
+		{
+			name: "ssml with espeak",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+				SSML:         true,
+			},
+			expectError: false,
+		},
+		{
+			name: "elevenlabs seed out of range",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+				ElevenLabs:   ElevenLabsConfig{VoiceID: "voice-123", Seed: 1 << 32},
+			},
+			expectError: true,
+			errorMsg:    "-elevenlabs-seed must be at most 4294967295",
+		},
+		{
+			name: "negative timeout",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				Timeout:      -time.Second,
+			},
+			expectError: true,
+			errorMsg:    "invalid -timeout",
+		},
+		{
+			name: "retry initial interval above maximum",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "elevenlabs",
+				ElevenLabs:   ElevenLabsConfig{VoiceID: "voice-123"},
+				HTTP:         HTTPConfig{Retry: httpclient.RetryPolicy{MaxRetries: 2, InitialInterval: time.Minute, MaxInterval: time.Second}},
+			},
+			expectError: true,
+			errorMsg:    "invalid HTTP retry options",
+		},
+		{
+			name: "espeak pitch out of range",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+				Espeak:       EspeakConfig{Pitch: 120},
+			},
+			expectError: true,
+			errorMsg:    "-espeak-pitch must be between 1 and 99",
+		},
+		{
+			name: "espeak variant with plus",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+				Espeak:       EspeakConfig{Variant: "+f3"},
+			},
+			expectError: true,
+			errorMsg:    "invalid -espeak-variant",
+		},
+		{
+			name: "espeak tunables",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "espeak",
+				Espeak:       EspeakConfig{Pitch: 40, Amplitude: 150, WordGap: 2, Variant: "mb-en1"},
+			},
+			expectError: false,
+		},
+		{
+			name: "ssml with say",
+			config: Config{
+				MarkdownFile: "test.md",
+				Provider:     "say",
+				SSML:         true,
+			},
+			expectError: true,
+			errorMsg:    "-ssml is only supported by the espeak and marytts providers",
+		},
+		{
+			name: "negative max duration",
+			config: Config{
+				InputDir:    "./docs",
+				Provider:    "say",
+				MaxDuration: -time.Minute,
+			},
+			expectError: true,
+			errorMsg:    "invalid -max-duration -1m0s",
+		},
+		{
+			name: "negative API call budget",
+			config: Config{
+				InputDir:    "./docs",
+				Provider:    "say",
+				MaxAPICalls: -1,
+			},
+			expectError: true,
+			errorMsg:    "invalid -max-api-calls -1",
+		},
+		{
+			name: "invalid sample",
+			config: Config{
+				InputDir: "./docs",
+				Provider: "say",
+				Sample:   "150%",
+			},
+			expectError: true,
+			errorMsg:    "invalid -sample \"150%\"",
+		},
+		{
+			name: "valid manifest rerun",
 			config: Config{
-				InputDir: "./docs",
 				Provider: "say",
+				Rerun:    RerunConfig{Manifest: "manifest.json", Only: []string{"failed"}},
 			},
 			expectError: false,
 		},
 		{
-			name: "both file and directory",
+			name: "manifest rerun with file input",
 			config: Config{
 				MarkdownFile: "test.md",
-				InputDir:     "./docs",
+				Provider:     "say",
+				Rerun:        RerunConfig{Manifest: "manifest.json"},
 			},
 			expectError: true,
-			errorMsg:    "cannot use both -f and -d flags",
+			errorMsg:    "cannot use -from-manifest with -f or -d",
 		},
 		{
-			name: "neither file nor directory",
+			name: "invalid only status",
 			config: Config{
-				MarkdownFile: "",
-				InputDir:     "",
+				Provider: "say",
+				Rerun:    RerunConfig{Manifest: "manifest.json", Only: []string{"ok"}},
 			},
 			expectError: true,
-			errorMsg:    "either -f (file) or -d (directory) is required",
+			errorMsg:    "invalid -only value",
 		},
 		{
-			name: "list voices mode ignores missing input",
+			name: "only without manifest",
 			config: Config{
-				MarkdownFile: "",
-				InputDir:     "",
+				MarkdownFile: "test.md",
 				Provider:     "say",
-				Commands: CommandFlags{
-					ListVoices: true,
-				},
+				Rerun:        RerunConfig{Only: []string{"failed"}},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "-only requires -from-manifest",
 		},
 		{
-			name: "valid say provider",
+			name: "verify transcribe without whisper model",
 			config: Config{
 				MarkdownFile: "test.md",
 				Provider:     "say",
+				Verify:       VerifyConfig{Transcribe: true, Threshold: 0.25},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "-verify-transcribe requires -whisper-model",
 		},
 		{
-			name: "valid espeak provider",
+			name: "verify threshold out of range",
 			config: Config{
 				MarkdownFile: "test.md",
-				Provider:     "espeak",
+				Provider:     "say",
+				Align:        AlignConfig{WhisperModel: "model.bin"},
+				Verify:       VerifyConfig{Transcribe: true, Threshold: 1.5},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "invalid -verify-threshold",
 		},
 		{
-			name: "valid elevenlabs provider with voice ID",
+			name: "languages require directory mode",
 			config: Config{
 				MarkdownFile: "test.md",
-				Provider:     "elevenlabs",
-				ElevenLabs: ElevenLabsConfig{
-					VoiceID: "21m00Tcm4TlvDq8ikWAM",
-				},
+				Provider:     "say",
+				Languages:    []string{"en", "es"},
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "-languages requires directory mode",
 		},
 		{
-			name: "elevenlabs provider without voice ID",
+			name: "languages with an output template without the language",
 			config: Config{
-				MarkdownFile: "test.md",
-				Provider:     "elevenlabs",
+				InputDir:  "./docs",
+				OutputDir: "./audio/{{.FileName}}",
+				Provider:  "say",
+				Languages: []string{"en", "es"},
 			},
 			expectError: true,
-			errorMsg:    "ElevenLabs voice ID is required",
+			errorMsg:    "must contain {{.Lang}}",
 		},
 		{
-			name: "invalid provider name",
+			name: "languages with an output template with the language",
 			config: Config{
-				MarkdownFile: "test.md",
-				Provider:     "invalid-provider",
+				InputDir:  "./docs",
+				OutputDir: "./audio/{{.Lang}}/{{.FileName}}",
+				Provider:  "say",
+				Languages: []string{"en", "es"},
 			},
-			expectError: true,
-			errorMsg:    "invalid provider",
+			expectError: false,
 		},
 		{
-			name: "elevenlabs list voices without voice ID is ok",
+			name: "malformed output directory template",
 			config: Config{
-				Provider: "elevenlabs",
-				Commands: CommandFlags{
-					ListVoices: true,
-				},
+				InputDir:  "./docs",
+				Provider:  "say",
+				OutputDir: "./audio/{{.RelDir",
 			},
-			expectError: false,
+			expectError: true,
+			errorMsg:    "invalid output template",
 		},
 	}
 
@@ -1205,6 +1818,50 @@ func TestParseWithDryRunFlag(t *testing.T) {
 	}
 }
 
+// TestParseMalformedLists tests that malformed list flags fail parsing like scalar flags
+func TestParseMalformedLists(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	tests := []struct {
+		flag  string
+		value string
+	}{
+		{flag: "-variants", value: "fast"},
+		{flag: "-language-voices", value: "en"},
+		{flag: "-voice-criteria", value: "lang"},
+		{flag: "-external-providers", value: missing},
+		{flag: "-custom-http-config", value: missing},
+		{flag: "-http-retry-on", value: "429,soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			oldArgs := os.Args
+			oldCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			// Panic instead of exiting on the parse error
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.PanicOnError)
+			flag.CommandLine.SetOutput(io.Discard)
+			os.Args = []string{"cmd", "-f", "test.md", tt.flag, tt.value}
+			_, w, _ := os.Pipe()
+			os.Stdout = w
+			defer func() { _ = w.Close() }()
+
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), tt.flag) {
+					t.Errorf("Parse() error = %v, want a parse error of %s %q", r, tt.flag, tt.value)
+				}
+			}()
+			Parse()
+		})
+	}
+}
+
 // TestConfigPrintDirectoryMode tests Print with directory mode
 func TestConfigPrintDirectoryMode(t *testing.T) {
 	cfg := Config{
@@ -1245,3 +1902,421 @@ func TestConfigPrintDirectoryMode(t *testing.T) {
 		t.Errorf("Should not contain 'Markdown file' in directory mode, got:\n%s", output)
 	}
 }
+
+func TestParseKeyValueList(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name:     "single pair",
+			input:    "en=Kate",
+			expected: map[string]string{"en": "Kate"},
+		},
+		{
+			name:     "multiple pairs with whitespace",
+			input:    " en = Kate , es=Monica,",
+			expected: map[string]string{"en": "Kate", "es": "Monica"},
+		},
+		{
+			name:        "missing separator",
+			input:       "en=Kate,es",
+			expectError: true,
+		},
+		{
+			name:        "empty value",
+			input:       "en=",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseKeyValueList(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("parseKeyValueList(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseKeyValueList(%q) error = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseKeyValueList(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseVariants(t *testing.T) {
+	speeds, err := parseVariants(" 1.0x, 1.25X,1.5 ")
+	if err != nil {
+		t.Fatalf("parseVariants() error = %v", err)
+	}
+	if !reflect.DeepEqual(speeds, []float64{1, 1.25, 1.5}) {
+		t.Errorf("parseVariants() = %v, want [1 1.25 1.5]", speeds)
+	}
+	if _, err := parseVariants("1.25x,fast"); err == nil {
+		t.Error("expected error for a non-numeric speed")
+	}
+}
+
+func TestParseStatusList(t *testing.T) {
+	statuses, err := parseStatusList(" 429, 503,")
+	if err != nil {
+		t.Fatalf("parseStatusList() error = %v", err)
+	}
+	if !reflect.DeepEqual(statuses, []int{429, 503}) {
+		t.Errorf("parseStatusList() = %v, want [429 503]", statuses)
+	}
+	if formatStatusList(statuses) != "429,503" {
+		t.Errorf("formatStatusList() = %q", formatStatusList(statuses))
+	}
+	if _, err := parseStatusList("429,busy"); err == nil {
+		t.Error("expected error for a non-numeric status")
+	}
+}
+
+func TestConfigForLanguage(t *testing.T) {
+	cfg := Config{
+		InputDir:       "docs",
+		OutputDir:      "out",
+		Provider:       "say",
+		Say:            SayConfig{Voice: "Kate", Rate: 180},
+		Languages:      []string{"en", "es"},
+		LanguageVoices: map[string]string{"es": "Monica"},
+	}
+
+	es := cfg.ForLanguage("es")
+	if es.InputDir != filepath.Join("docs", "es") {
+		t.Errorf("InputDir = %q, want %q", es.InputDir, filepath.Join("docs", "es"))
+	}
+	if es.OutputDir != filepath.Join("out", "es") {
+		t.Errorf("OutputDir = %q, want %q", es.OutputDir, filepath.Join("out", "es"))
+	}
+	if es.Say.Voice != "Monica" {
+		t.Errorf("Say.Voice = %q, want %q", es.Say.Voice, "Monica")
+	}
+	if es.Language != "es" || len(es.Languages) != 0 {
+		t.Errorf("Expected single-language config for es, got Language=%q Languages=%v", es.Language, es.Languages)
+	}
+
+	// Languages without a mapping keep the global voice
+	if en := cfg.ForLanguage("en"); en.Say.Voice != "Kate" {
+		t.Errorf("Say.Voice = %q, want %q", en.Say.Voice, "Kate")
+	}
+
+	// ElevenLabs mappings apply to the voice ID
+	cfg.Provider = "elevenlabs"
+	cfg.LanguageVoices = map[string]string{"es": "voice-es"}
+	if es := cfg.ForLanguage("es"); es.ElevenLabs.VoiceID != "voice-es" {
+		t.Errorf("ElevenLabs.VoiceID = %q, want %q", es.ElevenLabs.VoiceID, "voice-es")
+	}
+
+	// Templates are left untouched so they can use {{.Lang}}
+	cfg.OutputDir = "out/{{.Lang}}/{{.FileName}}"
+	if es := cfg.ForLanguage("es"); es.OutputDir != cfg.OutputDir {
+		t.Errorf("OutputDir = %q, want template %q", es.OutputDir, cfg.OutputDir)
+	}
+}
+
+func TestParseWithLanguages(t *testing.T) {
+	oldArgs := os.Args
+	oldCommandLine := flag.CommandLine
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldCommandLine
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	os.Args = []string{"cmd", "-d", "./docs", "-v", "Kate", "-languages", "en, es", "-language-voices", "es=Monica"}
+
+	cfg := Parse()
+
+	if !reflect.DeepEqual(cfg.Languages, []string{"en", "es"}) {
+		t.Errorf("Languages = %v, want [en es]", cfg.Languages)
+	}
+	if cfg.LanguageVoices["es"] != "Monica" {
+		t.Errorf("LanguageVoices[es] = %q, want %q", cfg.LanguageVoices["es"], "Monica")
+	}
+}
+
+func TestConfigOutputFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name:     "format without lossless",
+			config:   Config{Provider: "espeak", Format: "mp3"},
+			expected: "mp3",
+		},
+		{
+			name:     "lossless say keeps aiff",
+			config:   Config{Provider: "say", Format: "m4a", Lossless: true},
+			expected: "aiff",
+		},
+		{
+			name:     "lossless espeak uses wav",
+			config:   Config{Provider: "espeak", Format: "mp3", Lossless: true},
+			expected: "wav",
+		},
+		{
+			name:     "lossless elevenlabs uses wav",
+			config:   Config{Provider: "elevenlabs", Format: "mp3", Lossless: true},
+			expected: "wav",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.OutputFormat(); got != tt.expected {
+				t.Errorf("OutputFormat() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSampleFraction(t *testing.T) {
+	tests := []struct {
+		sample  string
+		want    float64
+		wantErr bool
+	}{
+		{sample: "5%", want: 0.05},
+		{sample: "5", want: 0.05},
+		{sample: " 12.5% ", want: 0.125},
+		{sample: "100%", want: 1},
+		{sample: "0%", wantErr: true},
+		{sample: "-5%", wantErr: true},
+		{sample: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sample, func(t *testing.T) {
+			got, err := Config{Sample: tt.sample}.SampleFraction()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SampleFraction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SampleFraction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalOnlyHint(t *testing.T) {
+	cfg := Config{
+		MarkdownFile: "test.md",
+		Provider:     "elevenlabs",
+		ElevenLabs:   ElevenLabsConfig{VoiceID: "voice-123"},
+		Coqui:        CoquiConfig{URL: "http://tts.example.com:8020"},
+		ExternalProviders: []external.Config{
+			{Name: "piper", Command: "piper-tts"},
+			{Name: "acme", Command: "acme-tts", Cloud: true},
+		},
+		LocalOnly: true,
+	}
+
+	// Validate and ValidateWebhook refuse with the same hint
+	webhookCfg := cfg
+	webhookCfg.InputDir = "./repo"
+	webhookCfg.Webhook = WebhookConfig{Secret: "s3cret"}
+	for _, err := range []error{cfg.Validate(), webhookCfg.ValidateWebhook()} {
+		if err == nil {
+			t.Fatal("expected a local-only refusal")
+		}
+		_, hint, ok := strings.Cut(err.Error(), "local providers: ")
+		if !ok {
+			t.Fatalf("error %q has no local providers hint", err)
+		}
+		local := strings.Split(hint, ", ")
+		for _, want := range []string{"espeak", "festival", "marytts", "piper"} {
+			if !slices.Contains(local, want) {
+				t.Errorf("hint %q is missing %q", hint, want)
+			}
+		}
+		for _, cloud := range []string{"elevenlabs", "edge", "coqui", "acme", customhttp.Name} {
+			if slices.Contains(local, cloud) {
+				t.Errorf("hint %q suggests %q", hint, cloud)
+			}
+		}
+		if slices.Contains(local, "say") != (runtime.GOOS == "darwin") {
+			t.Errorf("hint %q should only suggest say on macOS", hint)
+		}
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "disabled", config: Config{}},
+		{name: "enabled with input directory", config: Config{InputDir: "./repo", Provider: "say", Webhook: WebhookConfig{Secret: "s3cret", Paths: []string{"docs/"}}}},
+		{name: "enabled without input directory", config: Config{Webhook: WebhookConfig{Secret: "s3cret"}}, wantErr: true},
+		{name: "paths without secret", config: Config{InputDir: "./repo", Webhook: WebhookConfig{Paths: []string{"docs/"}}}, wantErr: true},
+		{name: "cloud provider in local-only mode", config: Config{InputDir: "./repo", Provider: "elevenlabs", LocalOnly: true, Webhook: WebhookConfig{Secret: "s3cret"}}, wantErr: true},
+		{name: "tenants with their own checkouts", config: Config{Webhook: WebhookConfig{Tenants: true}, Tenants: []Tenant{{Name: "docs", Token: "t1", InputDir: "./docs"}}}},
+		{name: "tenant without a checkout", config: Config{Webhook: WebhookConfig{Tenants: true}, Tenants: []Tenant{{Name: "docs", Token: "t1"}}}, wantErr: true},
+		{name: "tenants without the webhook", config: Config{Tenants: []Tenant{{Name: "docs", Token: "t1"}}}},
+		{name: "tenant webhook without tenants", config: Config{InputDir: "./repo", Webhook: WebhookConfig{Tenants: true}}, wantErr: true},
+		{name: "tenants with a webhook secret", config: Config{InputDir: "./repo", Webhook: WebhookConfig{Secret: "s3cret"}, Tenants: []Tenant{{Name: "docs", Token: "t1"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.ValidateWebhook(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyEnvFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addr := fs.String("serve-addr", "localhost:8080", "")
+	serve := fs.Bool("serve-output", false, "")
+	jobs := fs.Int("server-max-jobs", 1, "")
+	format := fs.String("format", "aiff", "")
+
+	t.Setenv("MD2AUDIO_SERVE_ADDR", "0.0.0.0:8080")
+	t.Setenv("MD2AUDIO_SERVE_OUTPUT", "true")
+	t.Setenv("MD2AUDIO_SERVER_MAX_JOBS", "many")
+	t.Setenv("MD2AUDIO_FORMAT", "mp3")
+
+	errs := applyEnvFlags(fs)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "MD2AUDIO_SERVER_MAX_JOBS") {
+		t.Errorf("Expected one error for MD2AUDIO_SERVER_MAX_JOBS, got %v", errs)
+	}
+	if *addr != "0.0.0.0:8080" || !*serve || *jobs != 1 {
+		t.Errorf("Unexpected values: addr %q, serve %v, jobs %d", *addr, *serve, *jobs)
+	}
+
+	// Command-line arguments take precedence over the environment
+	if err := fs.Parse([]string{"-format", "m4a"}); err != nil {
+		t.Fatal(err)
+	}
+	if *format != "m4a" {
+		t.Errorf("format = %q, want m4a", *format)
+	}
+}
+
+func TestEnvFlagName(t *testing.T) {
+	if got := EnvFlagName("server-max-body-kb"); got != "MD2AUDIO_SERVER_MAX_BODY_KB" {
+		t.Errorf("EnvFlagName() = %q", got)
+	}
+	if EnvFlagName("local-only") != EnvLocalOnly || EnvFlagName("webhook-secret") != EnvWebhookSecret {
+		t.Error("Documented environment variables should match their options")
+	}
+}
+
+func TestExternalProviderDiscovery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"md2audio-provider-acme", "md2audio-provider-say"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	cfg := Config{MarkdownFile: "test.md", Provider: "acme"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a discovered provider error = %v", err)
+	}
+	if !cfg.IsCloud("acme") {
+		t.Error("discovered providers should be treated as cloud providers")
+	}
+	if _, ok := cfg.ExternalProvider("say"); ok {
+		t.Error("a plugin on PATH must not replace a built-in provider")
+	}
+
+	// Registrations cannot make a built-in cloud provider local
+	shadowed := Config{ExternalProviders: []external.Config{{Name: "elevenlabs", Command: "true"}}}
+	if !shadowed.IsCloud("elevenlabs") {
+		t.Error("a registration must not replace a built-in cloud provider")
+	}
+
+	// Registrations take precedence over discovery
+	cfg.ExternalProviders = []external.Config{{Name: "acme", Command: "acme-tts"}}
+	cfg.LocalOnly = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a registered local provider error = %v", err)
+	}
+}
+
+func TestApplyVoice(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		voice       string
+		wantVoice   string
+		wantVoiceID string
+	}{
+		{name: "say", cfg: Config{Provider: "say"}, voice: "Daniel", wantVoice: "Daniel"},
+		{name: "default provider", cfg: Config{}, voice: "Daniel", wantVoice: "Daniel"},
+		{name: "edge", cfg: Config{Provider: "edge"}, voice: "en-GB-SoniaNeural", wantVoice: "en-GB-SoniaNeural"},
+		{name: "elevenlabs", cfg: Config{Provider: "elevenlabs"}, voice: "voice-123", wantVoiceID: "voice-123"},
+		{name: "-v overrides", cfg: Config{Provider: "say", Say: SayConfig{Voice: "Kate"}}, voice: "Daniel", wantVoice: "Kate"},
+		{
+			name:        "-elevenlabs-voice-id overrides",
+			cfg:         Config{Provider: "elevenlabs", ElevenLabs: ElevenLabsConfig{VoiceID: "voice-456"}},
+			voice:       "voice-123",
+			wantVoiceID: "voice-456",
+		},
+		{name: "no -voice", cfg: Config{Provider: "say"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.applyVoice(tt.voice)
+			if cfg.Say.Voice != tt.wantVoice || cfg.ElevenLabs.VoiceID != tt.wantVoiceID {
+				t.Errorf("voices = (%q, %q), want (%q, %q)", cfg.Say.Voice, cfg.ElevenLabs.VoiceID, tt.wantVoice, tt.wantVoiceID)
+			}
+		})
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	t.Setenv("MD2AUDIO_TEST_INT", "42")
+	if got := getEnvInt("MD2AUDIO_TEST_INT", 1); got != 42 {
+		t.Errorf("getEnvInt() = %d, want 42", got)
+	}
+	t.Setenv("MD2AUDIO_TEST_INT", "loud")
+	if got := getEnvInt("MD2AUDIO_TEST_INT", 1); got != 1 {
+		t.Errorf("getEnvInt() = %d, want the default for an invalid value", got)
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	t.Setenv("ELEVENLABS_TIMEOUT", "2m")
+	t.Setenv("CUSTOM_HTTP_TIMEOUT", "30s")
+	t.Setenv("ESPEAK_TIMEOUT", "soon")
+
+	cfg := Config{}
+	if got := cfg.ProviderTimeout("elevenlabs"); got != 2*time.Minute {
+		t.Errorf("ProviderTimeout(elevenlabs) = %s, want the env var", got)
+	}
+	if got := cfg.ProviderTimeout("custom-http"); got != 30*time.Second {
+		t.Errorf("ProviderTimeout(custom-http) = %s, want the env var", got)
+	}
+	if got := cfg.ProviderTimeout("espeak"); got != 0 {
+		t.Errorf("ProviderTimeout(espeak) = %s, want 0 for an invalid value", got)
+	}
+	if got := cfg.ProviderTimeout("say"); got != 0 {
+		t.Errorf("ProviderTimeout(say) = %s, want 0 without env var", got)
+	}
+
+	cfg.Timeout = 10 * time.Minute
+	if got := cfg.ProviderTimeout("elevenlabs"); got != cfg.Timeout {
+		t.Errorf("ProviderTimeout(elevenlabs) = %s, want -timeout", got)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Tenant is a team sharing a -serve-output instance, identified by its token
+type Tenant struct {
+	Name             string `json:"name"`
+	Token            string `json:"token"`                        // Authenticates review requests and signs the tenant's webhook deliveries
+	OutputPrefix     string `json:"output_prefix,omitempty"`      // Subdirectory of -o holding the tenant's audio (default: name)
+	InputDir         string `json:"input_dir,omitempty"`          // Git checkout regenerated by the tenant's webhook (default: -d)
+	ElevenLabsAPIKey string `json:"elevenlabs_api_key,omitempty"` // ElevenLabs API key billed for the tenant (default: ELEVENLABS_API_KEY)
+}
+
+// tenantsFile is the JSON document read by LoadTenants
+type tenantsFile struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// LoadTenants reads and validates a -server-tenants file
+func LoadTenants(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+
+	var file tenantsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid tenants file %s: %w", path, err)
+	}
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("tenants file %s defines no tenants", path)
+	}
+
+	names := make(map[string]bool)
+	tokens := make(map[string]bool)
+	for i, tenant := range file.Tenants {
+		if tenant.Name == "" || tenant.Token == "" {
+			return nil, fmt.Errorf("tenant %d in %s: name and token are required", i+1, path)
+		}
+		if names[tenant.Name] {
+			return nil, fmt.Errorf("duplicate tenant %q in %s", tenant.Name, path)
+		}
+		if tokens[tenant.Token] {
+			return nil, fmt.Errorf("tenant %q in %s reuses the token of another tenant", tenant.Name, path)
+		}
+		names[tenant.Name], tokens[tenant.Token] = true, true
+
+		if file.Tenants[i].OutputPrefix == "" {
+			file.Tenants[i].OutputPrefix = tenant.Name
+		}
+		prefix := filepath.Clean(file.Tenants[i].OutputPrefix)
+		if filepath.IsAbs(prefix) || prefix == "." || prefix == ".." || strings.HasPrefix(prefix, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("tenant %q in %s: output_prefix must be a subdirectory of the output directory", tenant.Name, path)
+		}
+		file.Tenants[i].OutputPrefix = prefix
+	}
+
+	return file.Tenants, nil
+}
+
+// ForTenant returns a copy of the configuration generating audio for a tenant:
+// output goes to the tenant's subdirectory of the output directory, the tenant's
+// checkout replaces the input directory, and its ElevenLabs key is used.
+func (c Config) ForTenant(tenant Tenant) Config {
+	tenantCfg := c
+	tenantCfg.Tenants = nil
+	tenantCfg.Tenant = tenant.Name
+	tenantCfg.OutputDir = filepath.Join(c.OutputDir, tenant.OutputPrefix)
+	if tenant.InputDir != "" {
+		tenantCfg.InputDir = tenant.InputDir
+	}
+	if tenant.ElevenLabsAPIKey != "" {
+		tenantCfg.ElevenLabs.APIKey = tenant.ElevenLabsAPIKey
+	}
+	return tenantCfg
+}
+
+// FindTenant returns the tenant with the given name
+func (c Config) FindTenant(name string) (Tenant, bool) {
+	for _, tenant := range c.Tenants {
+		if tenant.Name == name {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTenants(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantErr    bool
+		wantPrefix string
+	}{
+		{
+			name:       "default output prefix",
+			content:    `{"tenants": [{"name": "docs", "token": "t1"}]}`,
+			wantPrefix: "docs",
+		},
+		{
+			name:       "nested output prefix",
+			content:    `{"tenants": [{"name": "docs", "token": "t1", "output_prefix": "teams/docs/"}]}`,
+			wantPrefix: filepath.Join("teams", "docs"),
+		},
+		{name: "no tenants", content: `{"tenants": []}`, wantErr: true},
+		{name: "missing token", content: `{"tenants": [{"name": "docs"}]}`, wantErr: true},
+		{name: "duplicate name", content: `{"tenants": [{"name": "docs", "token": "t1"}, {"name": "docs", "token": "t2"}]}`, wantErr: true},
+		{name: "shared token", content: `{"tenants": [{"name": "docs", "token": "t1"}, {"name": "blog", "token": "t1"}]}`, wantErr: true},
+		{name: "prefix escapes output", content: `{"tenants": [{"name": "docs", "token": "t1", "output_prefix": "../docs"}]}`, wantErr: true},
+		{name: "invalid JSON", content: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tenants.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			tenants, err := LoadTenants(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTenants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tenants[0].OutputPrefix != tt.wantPrefix {
+				t.Errorf("OutputPrefix = %q, want %q", tenants[0].OutputPrefix, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestConfigForTenant(t *testing.T) {
+	cfg := Config{
+		InputDir:   "./repo",
+		OutputDir:  "./audio",
+		Provider:   "elevenlabs",
+		ElevenLabs: ElevenLabsConfig{APIKey: "shared-key"},
+		Tenants:    []Tenant{{Name: "docs", Token: "t1", OutputPrefix: "docs"}},
+	}
+
+	tenantCfg := cfg.ForTenant(Tenant{Name: "docs", OutputPrefix: "docs", InputDir: "./docs-repo", ElevenLabsAPIKey: "docs-key"})
+	if tenantCfg.OutputDir != filepath.Join("audio", "docs") || tenantCfg.InputDir != "./docs-repo" {
+		t.Errorf("Unexpected directories: input %q, output %q", tenantCfg.InputDir, tenantCfg.OutputDir)
+	}
+	if tenantCfg.ElevenLabs.APIKey != "docs-key" || tenantCfg.Tenant != "docs" || tenantCfg.Tenants != nil {
+		t.Errorf("Unexpected tenant settings: %+v", tenantCfg)
+	}
+
+	// Tenants without their own settings inherit the shared ones
+	tenantCfg = cfg.ForTenant(Tenant{Name: "blog", OutputPrefix: "blog"})
+	if tenantCfg.InputDir != "./repo" || tenantCfg.ElevenLabs.APIKey != "shared-key" {
+		t.Errorf("Unexpected inherited settings: %+v", tenantCfg)
+	}
+
+	if _, ok := cfg.FindTenant("docs"); !ok {
+		t.Error("FindTenant(docs) should find the configured tenant")
+	}
+}
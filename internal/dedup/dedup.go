@@ -0,0 +1,185 @@
+// Package dedup finds generated audio files with identical content.
+// Boilerplate sections repeated across documents produce byte-identical
+// audio, which can be reported and replaced with hard links to save space.
+//
+// Key features:
+//   - Content hashing (SHA-256) of audio files in an output tree
+//   - Size pre-grouping so only potential duplicates are hashed
+//   - Report of duplicate groups and reclaimable space
+//   - Optional replacement of duplicates with hard links
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// audioExtensions lists the file extensions considered for deduplication
+var audioExtensions = []string{".aiff", ".m4a", ".mp3", ".ogg", ".wav"}
+
+// Group is a set of audio files with identical content.
+type Group struct {
+	Hash  string   // SHA-256 of the content
+	Size  int64    // Size of each file in bytes
+	Files []string // Paths, sorted; the first file is kept when linking
+}
+
+// Wasted returns the bytes used by duplicate copies, not counting files
+// that are already hard links to the kept file.
+func (g Group) Wasted() int64 {
+	return g.Size * int64(len(g.copies()))
+}
+
+// copies returns the files that are separate copies of the first file
+func (g Group) copies() []string {
+	keep, err := os.Stat(g.Files[0])
+	if err != nil {
+		return g.Files[1:]
+	}
+
+	var copies []string
+	for _, file := range g.Files[1:] {
+		if info, err := os.Stat(file); err == nil && os.SameFile(keep, info) {
+			continue
+		}
+		copies = append(copies, file)
+	}
+	return copies
+}
+
+// Find returns the groups of identical audio files under root, most duplicated bytes first.
+func Find(root string) ([]Group, error) {
+	bySize := make(map[int64][]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && info.Size() > 0 {
+			bySize[info.Size()] = append(bySize[info.Size()], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	var groups []Group
+	for size, files := range bySize {
+		if len(files) < 2 {
+			continue
+		}
+
+		byHash := make(map[string][]string)
+		for _, file := range files {
+			hash, err := hashFile(file)
+			if err != nil {
+				return nil, err
+			}
+			byHash[hash] = append(byHash[hash], file)
+		}
+		for hash, same := range byHash {
+			if len(same) < 2 {
+				continue
+			}
+			slices.Sort(same)
+			groups = append(groups, Group{Hash: hash, Size: size, Files: same})
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b Group) int {
+		if wa, wb := a.Size*int64(len(a.Files)-1), b.Size*int64(len(b.Files)-1); wa != wb {
+			if wa > wb {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Files[0], b.Files[0])
+	})
+	return groups, nil
+}
+
+// Link replaces the duplicate copies in a group with hard links to its first
+// file and returns the number of bytes reclaimed.
+func Link(group Group) (int64, error) {
+	var saved int64
+	for _, file := range group.copies() {
+		tmp := file + ".dedup-link"
+		if err := os.Link(group.Files[0], tmp); err != nil {
+			return saved, fmt.Errorf("failed to link %s: %w", file, err)
+		}
+		if err := os.Rename(tmp, file); err != nil {
+			_ = os.Remove(tmp)
+			return saved, fmt.Errorf("failed to replace %s: %w", file, err)
+		}
+		saved += group.Size
+	}
+	return saved, nil
+}
+
+// WriteReport writes the duplicate groups with paths relative to root.
+func WriteReport(w io.Writer, root string, groups []Group) error {
+	var b strings.Builder
+	var wasted int64
+	for _, group := range groups {
+		wasted += group.Wasted()
+		fmt.Fprintf(&b, "%s  %d files, %s each\n", group.Hash[:12], len(group.Files), FormatBytes(group.Size))
+		for i, file := range group.Files {
+			marker := " "
+			if i == 0 {
+				marker = "*"
+			}
+			if rel, err := filepath.Rel(root, file); err == nil {
+				file = rel
+			}
+			fmt.Fprintf(&b, "  %s %s\n", marker, file)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d duplicate group(s), %s reclaimable (* = file kept when linking)\n", len(groups), FormatBytes(wasted))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// FormatBytes formats a size in bytes (e.g., "1.5 MB")
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// hashFile returns the hex SHA-256 of a file
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
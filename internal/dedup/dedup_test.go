@@ -0,0 +1,145 @@
+package dedup
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir from a path -> content map
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/section_01_license.mp3": "license audio",
+		"b/section_05_license.mp3": "license audio",
+		"c/section_02_license.mp3": "license audio",
+		"a/section_02_intro.mp3":   "intro audio A",
+		"b/section_01_intro.mp3":   "intro audio B", // same size, different content
+		"a/notes.txt":              "license audio", // not audio
+		"b/empty.mp3":              "",
+		"c/empty.mp3":              "",
+	})
+
+	groups, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 group, got %d: %+v", len(groups), groups)
+	}
+
+	group := groups[0]
+	if len(group.Files) != 3 || group.Size != int64(len("license audio")) {
+		t.Errorf("Unexpected group: %+v", group)
+	}
+	if group.Files[0] != filepath.Join(dir, "a", "section_01_license.mp3") {
+		t.Errorf("Expected files sorted, first = %s", group.Files[0])
+	}
+	if got := group.Wasted(); got != 2*group.Size {
+		t.Errorf("Wasted() = %d, want %d", got, 2*group.Size)
+	}
+}
+
+func TestFindMissingRoot(t *testing.T) {
+	if _, err := Find(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error for missing root")
+	}
+}
+
+func TestLink(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.mp3": "same audio",
+		"b.mp3": "same audio",
+		"c.mp3": "same audio",
+	})
+
+	groups, err := Find(dir)
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("Find() = %v, %v", groups, err)
+	}
+
+	saved, err := Link(groups[0])
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if saved != 2*groups[0].Size {
+		t.Errorf("Link() saved %d, want %d", saved, 2*groups[0].Size)
+	}
+
+	keep, _ := os.Stat(filepath.Join(dir, "a.mp3"))
+	for _, name := range []string{"b.mp3", "c.mp3"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if !os.SameFile(keep, info) {
+			t.Errorf("%s is not a hard link to a.mp3", name)
+		}
+	}
+
+	// Already linked files are not counted again
+	if got := groups[0].Wasted(); got != 0 {
+		t.Errorf("Wasted() after linking = %d, want 0", got)
+	}
+	if saved, err := Link(groups[0]); err != nil || saved != 0 {
+		t.Errorf("Link() again = %d, %v; want 0, nil", saved, err)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/x.mp3": "repeated",
+		"b/x.mp3": "repeated",
+	})
+
+	groups, err := Find(dir)
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, dir, groups); err != nil {
+		t.Fatalf("WriteReport() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"2 files, 8 B each", "* " + filepath.Join("a", "x.mp3"), "1 duplicate group(s), 8 B reclaimable"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in report:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package history records md2audio runs in the SQLite database shared with
+// the voice cache, so past runs can be reviewed and usage aggregated per
+// provider over time.
+//
+// Key features:
+//   - One row per run with inputs, settings, counts, and durations
+//   - Character totals as a cost estimate (providers like ElevenLabs bill per character)
+//   - Recent run listing
+//   - Monthly usage statistics per provider
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultLimit is the number of runs listed by -history when no limit is given
+const DefaultLimit = 20
+
+// Run describes a completed run.
+type Run struct {
+	ID           int64
+	StartedAt    time.Time
+	Elapsed      time.Duration
+	Provider     string
+	Mode         string            // "file", "directory", "languages", or "manifest"
+	Input        string            // Markdown file, input directory, or manifest path
+	Output       string            // Output directory (or template)
+	Settings     map[string]string // Voice, format, and other generation settings
+	Files        int
+	Sections     int
+	Generated    int
+	Failed       int
+	Flagged      int
+	Skipped      int
+	AudioSeconds float64
+	Characters   int // Characters sent to the provider
+}
+
+// ProviderStats aggregates the runs of a provider in one month.
+type ProviderStats struct {
+	Month        string // "YYYY-MM" in UTC
+	Provider     string
+	Runs         int
+	Sections     int
+	Generated    int
+	Failed       int
+	AudioSeconds float64
+	Characters   int
+}
+
+// Store reads and writes run history.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the history store in the SQLite database at dbPath.
+func Open(dbPath string) (*Store, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// WAL mode lets the voice cache and history share the database
+	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		started_at INTEGER NOT NULL,
+		elapsed_ms INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		mode TEXT NOT NULL,
+		input TEXT,
+		output TEXT,
+		settings TEXT,
+		files INTEGER NOT NULL,
+		sections INTEGER NOT NULL,
+		generated INTEGER NOT NULL,
+		failed INTEGER NOT NULL,
+		flagged INTEGER NOT NULL,
+		skipped INTEGER NOT NULL,
+		audio_seconds REAL NOT NULL,
+		characters INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_runs_started_at ON runs(started_at);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database connection.
+func (s *Store) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Record stores a run and returns its ID.
+func (s *Store) Record(ctx context.Context, run Run) (int64, error) {
+	settings, err := json.Marshal(run.Settings)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO runs (started_at, elapsed_ms, provider, mode, input, output, settings,
+			files, sections, generated, failed, flagged, skipped, audio_seconds, characters)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.StartedAt.Unix(), run.Elapsed.Milliseconds(), run.Provider, run.Mode, run.Input, run.Output, string(settings),
+		run.Files, run.Sections, run.Generated, run.Failed, run.Flagged, run.Skipped, run.AudioSeconds, run.Characters)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// Recent returns the most recent runs, newest first.
+func (s *Store) Recent(ctx context.Context, limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, started_at, elapsed_ms, provider, mode, input, output, settings,
+			files, sections, generated, failed, flagged, skipped, audio_seconds, characters
+		FROM runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []Run
+	for rows.Next() {
+		var run Run
+		var startedAt, elapsedMS int64
+		var settings string
+		if err := rows.Scan(&run.ID, &startedAt, &elapsedMS, &run.Provider, &run.Mode, &run.Input, &run.Output, &settings,
+			&run.Files, &run.Sections, &run.Generated, &run.Failed, &run.Flagged, &run.Skipped, &run.AudioSeconds, &run.Characters); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		run.StartedAt = time.Unix(startedAt, 0)
+		run.Elapsed = time.Duration(elapsedMS) * time.Millisecond
+		if settings != "" {
+			_ = json.Unmarshal([]byte(settings), &run.Settings)
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return runs, nil
+}
+
+// Stats aggregates runs per month and provider, newest month first.
+func (s *Store) Stats(ctx context.Context) ([]ProviderStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT strftime('%Y-%m', started_at, 'unixepoch') AS month, provider,
+			COUNT(*), SUM(sections), SUM(generated), SUM(failed), SUM(audio_seconds), SUM(characters)
+		FROM runs
+		GROUP BY month, provider
+		ORDER BY month DESC, provider
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var stats []ProviderStats
+	for rows.Next() {
+		var st ProviderStats
+		if err := rows.Scan(&st.Month, &st.Provider, &st.Runs, &st.Sections, &st.Generated, &st.Failed, &st.AudioSeconds, &st.Characters); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return stats, nil
+}
+
+// WriteRuns writes runs as a table.
+func WriteRuns(w io.Writer, runs []Run) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-5s %-16s %-10s %-9s %9s %7s %7s %8s %8s %10s  %s\n",
+		"ID", "Started", "Provider", "Mode", "Generated", "Failed", "Flagged", "Audio", "Took", "Characters", "Input")
+	b.WriteString(strings.Repeat("-", 110) + "\n")
+	for _, run := range runs {
+		fmt.Fprintf(&b, "%-5d %-16s %-10s %-9s %4d/%-4d %7d %7d %8s %8s %10d  %s\n",
+			run.ID, run.StartedAt.Local().Format("2006-01-02 15:04"), run.Provider, run.Mode,
+			run.Generated, run.Sections, run.Failed, run.Flagged,
+			formatSeconds(run.AudioSeconds), run.Elapsed.Round(time.Second), run.Characters, run.Input)
+		if settings := formatSettings(run.Settings); settings != "" {
+			fmt.Fprintf(&b, "%-5s %s\n", "", settings)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteStats writes provider statistics as a table.
+func WriteStats(w io.Writer, stats []ProviderStats) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-8s %-10s %5s %9s %9s %7s %9s %11s\n",
+		"Month", "Provider", "Runs", "Sections", "Generated", "Failed", "Audio", "Characters")
+	b.WriteString(strings.Repeat("-", 76) + "\n")
+	for _, st := range stats {
+		fmt.Fprintf(&b, "%-8s %-10s %5d %9d %9d %7d %9s %11d\n",
+			st.Month, st.Provider, st.Runs, st.Sections, st.Generated, st.Failed, formatSeconds(st.AudioSeconds), st.Characters)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatSeconds formats an audio duration as minutes and seconds (e.g., "12m05s")
+func formatSeconds(seconds float64) string {
+	total := int(seconds + 0.5)
+	return fmt.Sprintf("%dm%02ds", total/60, total%60)
+}
+
+// formatSettings formats settings as sorted key=value pairs
+func formatSettings(settings map[string]string) string {
+	pairs := make([]string, 0, len(settings))
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if settings[key] != "" {
+			pairs = append(pairs, key+"="+settings[key])
+		}
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,162 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStoreRecordAndRecent(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	first := Run{
+		StartedAt:    time.Date(2026, 9, 30, 10, 0, 0, 0, time.UTC),
+		Elapsed:      90 * time.Second,
+		Provider:     "say",
+		Mode:         "file",
+		Input:        "script.md",
+		Output:       "./audio_sections",
+		Settings:     map[string]string{"voice": "Kate", "format": "aiff"},
+		Files:        1,
+		Sections:     3,
+		Generated:    3,
+		AudioSeconds: 42.5,
+		Characters:   640,
+	}
+	second := Run{
+		StartedAt: time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC),
+		Provider:  "elevenlabs",
+		Mode:      "directory",
+		Input:     "./docs",
+		Files:     4,
+		Sections:  10,
+		Generated: 8,
+		Failed:    1,
+		Skipped:   1,
+	}
+
+	for _, run := range []Run{first, second} {
+		if _, err := store.Record(ctx, run); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	runs, err := store.Recent(ctx, 10)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].Provider != "elevenlabs" || runs[0].Skipped != 1 {
+		t.Errorf("Expected newest run first, got %+v", runs[0])
+	}
+
+	got := runs[1]
+	if got.Elapsed != first.Elapsed || got.Characters != 640 || got.AudioSeconds != 42.5 || got.Settings["voice"] != "Kate" {
+		t.Errorf("Run not round-tripped: %+v", got)
+	}
+	if !got.StartedAt.Equal(first.StartedAt) {
+		t.Errorf("StartedAt = %v, want %v", got.StartedAt, first.StartedAt)
+	}
+
+	limited, err := store.Recent(ctx, 1)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 run with limit, got %d", len(limited))
+	}
+}
+
+func TestStoreStats(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	runs := []Run{
+		{StartedAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Provider: "elevenlabs", Mode: "file", Sections: 2, Generated: 2, AudioSeconds: 30, Characters: 500},
+		{StartedAt: time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC), Provider: "elevenlabs", Mode: "file", Sections: 3, Generated: 2, Failed: 1, AudioSeconds: 20, Characters: 300},
+		{StartedAt: time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC), Provider: "say", Mode: "file", Sections: 1, Generated: 1},
+		{StartedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), Provider: "elevenlabs", Mode: "file", Sections: 1, Generated: 1, Characters: 100},
+	}
+	for _, run := range runs {
+		if _, err := store.Record(ctx, run); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 month/provider rows, got %d: %+v", len(stats), stats)
+	}
+	if stats[0].Month != "2026-10" {
+		t.Errorf("Expected newest month first, got %s", stats[0].Month)
+	}
+
+	september := stats[1]
+	if september.Month != "2026-09" || september.Provider != "elevenlabs" || september.Runs != 2 ||
+		september.Sections != 5 || september.Failed != 1 || september.AudioSeconds != 50 || september.Characters != 800 {
+		t.Errorf("Unexpected stats: %+v", september)
+	}
+}
+
+func TestWriteRuns(t *testing.T) {
+	runs := []Run{{
+		ID:           7,
+		StartedAt:    time.Now(),
+		Elapsed:      75 * time.Second,
+		Provider:     "say",
+		Mode:         "directory",
+		Input:        "./docs",
+		Settings:     map[string]string{"voice": "Kate", "format": "m4a", "rate": ""},
+		Sections:     4,
+		Generated:    3,
+		AudioSeconds: 125,
+		Characters:   1200,
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteRuns(&buf, runs); err != nil {
+		t.Fatalf("WriteRuns() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"3/4", "2m05s", "1m15s", "1200", "./docs", "format=m4a voice=Kate"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "rate=") {
+		t.Errorf("Empty settings should be omitted:\n%s", out)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteStats(&buf, []ProviderStats{{Month: "2026-10", Provider: "elevenlabs", Runs: 2, Sections: 5, Generated: 4, Failed: 1, AudioSeconds: 61, Characters: 900}})
+	if err != nil {
+		t.Fatalf("WriteStats() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"2026-10", "elevenlabs", "1m01s", "900"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
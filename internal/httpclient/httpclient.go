@@ -0,0 +1,117 @@
+// Package httpclient builds the HTTP clients used by API-based TTS providers.
+// Corporate networks often require an explicit proxy, a private certificate
+// authority, or extra headers (e.g., for an API gateway), none of which the
+// default client supports.
+//
+// Key features:
+//   - HTTP/HTTPS proxy (defaults to the HTTP_PROXY/HTTPS_PROXY environment variables)
+//   - Custom CA bundle added to the system certificate pool
+//   - Extra headers sent with every request
+//   - Retries of failed requests with exponential backoff (see Do)
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the request timeout used when Options.Timeout is zero
+const DefaultTimeout = 60 * time.Second
+
+// Options configures an HTTP client.
+type Options struct {
+	Proxy    string            // Proxy URL (empty = use HTTP_PROXY/HTTPS_PROXY)
+	CABundle string            // PEM file with additional trusted certificates
+	Headers  map[string]string // Extra headers sent with every request
+	Timeout  time.Duration     // Request timeout (default: DefaultTimeout)
+}
+
+// IsZero reports whether no option differs from the default client configuration.
+func (o Options) IsZero() bool {
+	return o.Proxy == "" && o.CABundle == "" && len(o.Headers) == 0 && o.Timeout == 0
+}
+
+// New creates an HTTP client configured with opts.
+func New(opts Options) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.CABundle != "" {
+		pool, err := certPool(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	var rt http.RoundTripper = transport
+	if len(opts.Headers) > 0 {
+		rt = &headerTransport{base: transport, headers: opts.Headers}
+	}
+
+	return &http.Client{Transport: rt, Timeout: timeout}, nil
+}
+
+// certPool returns the system certificate pool with the certificates from
+// the PEM file at path added.
+func certPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// ParseHeader parses a "Name: value" header.
+func ParseHeader(s string) (string, string, error) {
+	name, value, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q: must be in the form 'Name: value'", s)
+	}
+	return http.CanonicalHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// headerTransport adds extra headers to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip sets the extra headers on a copy of req and sends it.
+// Headers set by the provider itself (e.g., the API key) take precedence.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		if req.Header.Get(name) == "" {
+			req.Header.Set(name, value)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,135 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{input: "X-Gateway-Key: abc123", wantName: "X-Gateway-Key", wantValue: "abc123"},
+		{input: "x-team:  audio ", wantName: "X-Team", wantValue: "audio"},
+		{input: "X-Empty:", wantName: "X-Empty", wantValue: ""},
+		{input: "no colon", wantErr: true},
+		{input: ": value", wantErr: true},
+		{input: "Bad Name: value", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, value, err := ParseHeader(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHeader(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("ParseHeader(%q) = %q, %q, want %q, %q", tt.input, name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestNewHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client, err := New(Options{Headers: map[string]string{"X-Gateway-Key": "abc", "Xi-Api-Key": "override"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("xi-api-key", "secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got.Get("X-Gateway-Key") != "abc" {
+		t.Errorf("X-Gateway-Key = %q, want %q", got.Get("X-Gateway-Key"), "abc")
+	}
+	if got.Get("Xi-Api-Key") != "secret" {
+		t.Errorf("Xi-Api-Key = %q, provider header should take precedence", got.Get("Xi-Api-Key"))
+	}
+	if req.Header.Get("X-Gateway-Key") != "" {
+		t.Error("original request should not be modified")
+	}
+}
+
+func TestNewProxy(t *testing.T) {
+	client, err := New(Options{Proxy: "http://proxy.example.com:3128"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	transport := client.Transport.(*http.Transport)
+	proxy, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "api.elevenlabs.io"}})
+	if err != nil || proxy == nil || proxy.Host != "proxy.example.com:3128" {
+		t.Errorf("proxy = %v, %v, want proxy.example.com:3128", proxy, err)
+	}
+	if client.Timeout != DefaultTimeout {
+		t.Errorf("Timeout = %v, want %v", client.Timeout, DefaultTimeout)
+	}
+
+	if _, err := New(Options{Proxy: "proxy.example.com"}); err == nil {
+		t.Error("expected error for proxy URL without scheme")
+	}
+}
+
+func TestNewCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, pemData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := New(Options{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with CA bundle failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Without the bundle the test server certificate is untrusted
+	defaultClient, _ := New(Options{})
+	if _, err := defaultClient.Get(server.URL); err == nil {
+		t.Error("expected certificate error without CA bundle")
+	} else if !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Options{CABundle: empty}); err == nil {
+		t.Error("expected error for CA bundle without certificates")
+	}
+	if _, err := New(Options{CABundle: filepath.Join(dir, "missing.pem")}); err == nil {
+		t.Error("expected error for missing CA bundle")
+	}
+}
@@ -0,0 +1,177 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+)
+
+// RetryPolicy configures how failed API requests are retried.
+type RetryPolicy struct {
+	MaxRetries      int           // Retries after the first attempt (0 = never retry)
+	InitialInterval time.Duration // Wait before the first retry, growing exponentially
+	MaxInterval     time.Duration // Longest wait between retries of the exponential backoff
+	MaxRetryAfter   time.Duration // Longest wait honored from a Retry-After header; longer delays are cut to it (0 = DefaultMaxRetryAfter)
+	RetryOn         []int         // HTTP status codes that are retried
+}
+
+// DefaultMaxRetryAfter is the longest Retry-After delay waited for by default,
+// long enough for the per-minute rate limits of API providers
+const DefaultMaxRetryAfter = 5 * time.Minute
+
+// DefaultRetryPolicy returns the retry policy of API-based providers: two
+// retries (three attempts) with backoff from 1s to 10s, on rate limiting and
+// transient server errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:      2,
+		InitialInterval: 1 * time.Second,
+		MaxInterval:     10 * time.Second,
+		MaxRetryAfter:   DefaultMaxRetryAfter,
+		RetryOn: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+		},
+	}
+}
+
+// IsZero reports whether the policy is unset.
+func (p RetryPolicy) IsZero() bool {
+	return p.MaxRetries == 0 && p.InitialInterval == 0 && p.MaxInterval == 0 && p.MaxRetryAfter == 0 && len(p.RetryOn) == 0
+}
+
+// Validate checks that the policy's values are usable.
+func (p RetryPolicy) Validate() error {
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("invalid retry count %d: must be 0 or greater", p.MaxRetries)
+	}
+	if p.InitialInterval <= 0 || p.MaxInterval <= 0 {
+		return fmt.Errorf("retry intervals must be greater than 0")
+	}
+	if p.InitialInterval > p.MaxInterval {
+		return fmt.Errorf("initial retry interval %s exceeds the maximum interval %s", p.InitialInterval, p.MaxInterval)
+	}
+	if p.MaxRetryAfter < 0 {
+		return fmt.Errorf("invalid maximum Retry-After wait %s: must be 0 or greater", p.MaxRetryAfter)
+	}
+	for _, status := range p.RetryOn {
+		if status < 400 || status > 599 {
+			return fmt.Errorf("invalid retry status %d: must be an HTTP error status (400-599)", status)
+		}
+	}
+	return nil
+}
+
+// retryAfterLimit returns the longest Retry-After delay waited for
+func (p RetryPolicy) retryAfterLimit() time.Duration {
+	if p.MaxRetryAfter > 0 {
+		return p.MaxRetryAfter
+	}
+	return DefaultMaxRetryAfter
+}
+
+// Retries reports whether responses with statusCode are retried.
+func (p RetryPolicy) Retries(statusCode int) bool {
+	return slices.Contains(p.RetryOn, statusCode)
+}
+
+// Do sends req with client, retrying network errors and responses with a
+// retried status code with exponential backoff, or after the delay of the
+// response's Retry-After header when it has one, cut to the policy's
+// MaxRetryAfter. A response with any other status is returned for the
+// caller to handle; the error of the last attempt is returned once the
+// retries are exhausted.
+func Do(ctx context.Context, client *http.Client, req *http.Request, policy RetryPolicy) (*http.Response, error) {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = policy.InitialInterval
+	expBackoff.MaxInterval = policy.MaxInterval
+	expBackoff.Reset()
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		wait := time.Duration(-1)
+		resp, err := send(ctx, client, req)
+		switch {
+		case err != nil:
+			// Network error - retry
+			lastErr = err
+		case policy.Retries(resp.StatusCode):
+			// The server knows better than the backoff when to come back
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				// Waiting hours for a quota to reset would stall the run
+				wait = min(after, policy.retryAfterLimit())
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			lastErr = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		default:
+			// Success or non-retryable error
+			return resp, nil
+		}
+
+		if attempt >= policy.MaxRetries {
+			return nil, lastErr
+		}
+
+		if wait < 0 {
+			wait = expBackoff.NextBackOff()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d, or returns the error of ctx when it is done first.
+// Tests replace it to check waits without waiting.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP
+// date, into the wait from now. Dates in the past mean no wait.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// send sends a copy of req, with a fresh body when the request has one, so
+// the request can be sent again.
+func send(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	reqClone := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		reqClone.Body = body
+	}
+	return client.Do(reqClone)
+}
@@ -0,0 +1,199 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var attempts int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch r.URL.Path {
+		case "/flaky":
+			if attempts < 3 {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		case "/down":
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, RetryOn: []int{http.StatusServiceUnavailable}}
+	send := func(path string, policy RetryPolicy) (*http.Response, error) {
+		attempts, bodies = 0, nil
+		req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader("payload"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Do(context.Background(), server.Client(), req, policy)
+	}
+
+	// Retried requests are sent with their body again
+	resp, err := send("/flaky", policy)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Errorf("status %d after %d attempts, want 200 after 3", resp.StatusCode, attempts)
+	}
+	if strings.Join(bodies, ",") != "payload,payload,payload" {
+		t.Errorf("bodies = %v", bodies)
+	}
+
+	if _, err := send("/down", policy); err == nil || !strings.Contains(err.Error(), "status 503") || attempts != 3 {
+		t.Errorf("Do() error = %v after %d attempts, want a 503 error after 3", err, attempts)
+	}
+
+	// Statuses outside the policy are returned to the caller
+	resp, err = send("/invalid", policy)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || attempts != 1 {
+		t.Errorf("status %d after %d attempts, want 400 after 1", resp.StatusCode, attempts)
+	}
+
+	policy.MaxRetries = 0
+	if _, err := send("/flaky", policy); err == nil || attempts != 1 {
+		t.Errorf("Do() error = %v after %d attempts, want a single attempt", err, attempts)
+	}
+}
+
+func TestDoRetryAfter(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The backoff would wait an hour; Retry-After says to retry right away
+	policy := RetryPolicy{MaxRetries: 1, InitialInterval: time.Hour, MaxInterval: time.Hour, RetryOn: []int{http.StatusTooManyRequests}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := Do(ctx, server.Client(), req, policy)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("status %d after %d attempts, want 200 after 2", resp.StatusCode, attempts)
+	}
+}
+
+func TestDoRetryAfterWaits(t *testing.T) {
+	tests := []struct {
+		name          string
+		retryAfter    string
+		maxRetryAfter time.Duration
+		want          time.Duration
+	}{
+		{name: "longer than the backoff", retryAfter: "30", want: 30 * time.Second},
+		{name: "cut to the default maximum", retryAfter: "86400", want: DefaultMaxRetryAfter},
+		{name: "cut to the policy maximum", retryAfter: "86400", maxRetryAfter: time.Minute, want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var waits []time.Duration
+			oldSleep := sleep
+			sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			defer func() { sleep = oldSleep }()
+
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					http.Error(w, "rate limited", http.StatusTooManyRequests)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			policy := RetryPolicy{MaxRetries: 2, InitialInterval: time.Second, MaxInterval: 10 * time.Second, MaxRetryAfter: tt.maxRetryAfter, RetryOn: []int{http.StatusTooManyRequests}}
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			resp, err := Do(context.Background(), server.Client(), req, policy)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || len(waits) != 1 || waits[0] != tt.want {
+				t.Errorf("status %d after waits %v, want 200 after waiting %s", resp.StatusCode, waits, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "12", want: 12 * time.Second, wantOK: true},
+		{value: " 0 ", want: 0, wantOK: true},
+		{value: "-3", wantOK: false},
+		{value: "Fri, 02 Jan 2026 15:04:35 GMT", want: 30 * time.Second, wantOK: true},
+		{value: "Fri, 02 Jan 2026 15:00:00 GMT", want: 0, wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := retryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := DefaultRetryPolicy().Validate(); err != nil {
+		t.Errorf("default policy: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+	}{
+		{name: "negative retries", policy: RetryPolicy{MaxRetries: -1, InitialInterval: time.Second, MaxInterval: time.Second}},
+		{name: "zero interval", policy: RetryPolicy{MaxRetries: 1, MaxInterval: time.Second}},
+		{name: "initial above maximum", policy: RetryPolicy{InitialInterval: time.Minute, MaxInterval: time.Second}},
+		{name: "success status", policy: RetryPolicy{InitialInterval: time.Second, MaxInterval: time.Second, RetryOn: []int{200}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); err == nil {
+				t.Errorf("Validate() accepted %+v", tt.policy)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Package manifest records the outcome of each generated section.
+// A manifest.json file is kept in every output directory, listing one entry
+// per section with its source file, output path and status, so runs can be
+// audited and failed or flagged sections regenerated later.
+//
+// Key features:
+//   - JSON manifest persisted next to the generated audio
+//   - Per-section status (ok, failed, flagged, skipped) with reasons
+//   - Entry updates keyed by output path
+//   - Status filtering for selective re-runs
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FileName is the manifest file name written to each output directory
+const FileName = "manifest.json"
+
+// Version is the current manifest format version
+const Version = 1
+
+// Status is the outcome of generating a section.
+type Status string
+
+const (
+	// StatusOK marks a section generated successfully.
+	StatusOK Status = "ok"
+	// StatusFailed marks a section whose generation failed.
+	StatusFailed Status = "failed"
+	// StatusFlagged marks a section generated but flagged by verification.
+	StatusFlagged Status = "flagged"
+	// StatusSkipped marks a section not started because a run budget was spent.
+	StatusSkipped Status = "skipped"
+)
+
+// ParseStatus converts a string into a Status.
+func ParseStatus(s string) (Status, error) {
+	switch Status(s) {
+	case StatusOK, StatusFailed, StatusFlagged, StatusSkipped:
+		return Status(s), nil
+	default:
+		return "", fmt.Errorf("invalid status %q: must be 'ok', 'failed', 'flagged', or 'skipped'", s)
+	}
+}
+
+// Entry records the outcome of a single section.
+type Entry struct {
+	Source      string    `json:"source"`                // Absolute path of the markdown file
+	Position    int       `json:"position,omitempty"`    // 1-based position of the source file in the directory run's processing order
+	Index       int       `json:"index"`                 // 1-based section index within the source file
+	Number      int       `json:"number,omitempty"`      // Section number in the output file name, when it differs from Index (-start-index)
+	Sentence    int       `json:"sentence,omitempty"`    // 1-based sentence index within the section (-granularity sentence)
+	Title       string    `json:"title"`                 // Section title
+	Output      string    `json:"output"`                // Audio file path (planned path if generation failed)
+	Status      Status    `json:"status"`                // Generation outcome
+	Reason      string    `json:"reason,omitempty"`      // Failure, flag, or skip reason
+	Placeholder bool      `json:"placeholder,omitempty"` // Whether a placeholder was written in place of failed audio
+	Text        string    `json:"text,omitempty"`        // Synthesized text, compared by -incremental to find changed sentences
+	Fingerprint string    `json:"fingerprint,omitempty"` // SHA-256 of the decoded audio samples, unaffected by metadata changes
+	Loudness    *Loudness `json:"loudness,omitempty"`    // Levels of the decoded audio
+	UpdatedAt   time.Time `json:"updated_at"`            // When the entry was last written
+}
+
+// Loudness records the levels of a generated audio file in dBFS.
+type Loudness struct {
+	RMS  float64 `json:"rms_dbfs"`
+	Peak float64 `json:"peak_dbfs"`
+}
+
+// Settings records how the audio in an output directory was generated.
+type Settings struct {
+	Version  string            `json:"md2audio_version,omitempty"` // md2audio version that generated the audio
+	Provider string            `json:"provider"`
+	Voice    string            `json:"voice"`
+	Format   string            `json:"format"`
+	Model    string            `json:"model,omitempty"`   // Provider model ID (ElevenLabs)
+	Rate     int               `json:"rate,omitempty"`    // Speaking rate (say, espeak)
+	Options  map[string]string `json:"options,omitempty"` // Other effective settings (e.g., voice stability, -redact)
+}
+
+// Comment formats the settings as a single line for audio metadata comments
+// (e.g., "md2audio 1.2.0; provider=say; voice=Kate; format=aiff; rate=180").
+func (s Settings) Comment() string {
+	parts := []string{"md2audio " + s.Version, "provider=" + s.Provider}
+	if s.Voice != "" {
+		parts = append(parts, "voice="+s.Voice)
+	}
+	parts = append(parts, "format="+s.Format)
+	if s.Model != "" {
+		parts = append(parts, "model="+s.Model)
+	}
+	if s.Rate != 0 {
+		parts = append(parts, fmt.Sprintf("rate=%d", s.Rate))
+	}
+	keys := slices.Sorted(maps.Keys(s.Options))
+	for _, key := range keys {
+		parts = append(parts, key+"="+s.Options[key])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Diff describes the settings that differ in other (e.g., "voice Kate -> Daniel").
+func (s Settings) Diff(other Settings) []string {
+	var diff []string
+	for _, field := range []struct{ name, old, new string }{
+		{"provider", s.Provider, other.Provider},
+		{"voice", s.Voice, other.Voice},
+		{"format", s.Format, other.Format},
+	} {
+		if field.old != field.new {
+			diff = append(diff, fmt.Sprintf("%s %s -> %s", field.name, field.old, field.new))
+		}
+	}
+	return diff
+}
+
+// Manifest lists the sections generated into an output directory.
+type Manifest struct {
+	Version  int       `json:"version"`
+	Settings *Settings `json:"settings,omitempty"` // Settings of the latest run (nil in manifests written before settings were recorded)
+	Order    string    `json:"order,omitempty"`    // Processing order of the latest directory run (e.g., "doc", "_order.yaml", "shuffle(42)")
+	Entries  []Entry   `json:"entries"`
+}
+
+// New creates an empty manifest.
+func New() *Manifest {
+	return &Manifest{Version: Version}
+}
+
+// PathFor returns the manifest path for an output directory.
+func PathFor(outputDir string) string {
+	return filepath.Join(outputDir, FileName)
+}
+
+// Load reads a manifest file.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// LoadOrNew reads a manifest file, returning an empty manifest if it does not exist.
+func LoadOrNew(path string) (*Manifest, error) {
+	m, err := Load(path)
+	if err != nil {
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			return New(), nil
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// Save writes the manifest to path.
+func (m *Manifest) Save(path string) error {
+	m.Version = Version
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Put adds an entry or replaces the entry for the same output file.
+// Entries are matched by output path without extension, so a section
+// regenerated in a different format replaces its previous entry.
+func (m *Manifest) Put(entry Entry) {
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = time.Now().UTC()
+	}
+
+	key := outputKey(entry.Output)
+	for i, existing := range m.Entries {
+		if outputKey(existing.Output) == key {
+			m.Entries[i] = entry
+			return
+		}
+	}
+	m.Entries = append(m.Entries, entry)
+}
+
+// Get returns the entry for an output path.
+func (m *Manifest) Get(output string) (Entry, bool) {
+	key := outputKey(output)
+	for _, entry := range m.Entries {
+		if outputKey(entry.Output) == key {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Filter returns the entries whose status is one of statuses.
+func (m *Manifest) Filter(statuses ...Status) []Entry {
+	var entries []Entry
+	for _, entry := range m.Entries {
+		if slices.Contains(statuses, entry.Status) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Count returns the number of entries with the given status.
+func (m *Manifest) Count(status Status) int {
+	return len(m.Filter(status))
+}
+
+// OutputNumber returns the section number used in the entry's output file name.
+func (e Entry) OutputNumber() int {
+	if e.Number > 0 {
+		return e.Number
+	}
+	return e.Index
+}
+
+// NextStartIndex returns the number of the first section of source that
+// continues the numbering of the output set: the number it started at in
+// earlier runs, or the number after the highest of the other sources.
+func (m *Manifest) NextStartIndex(source string) int {
+	highest := 0
+	for _, entry := range m.Entries {
+		if entry.Source == source {
+			return entry.OutputNumber() - entry.Index + 1
+		}
+		highest = max(highest, entry.OutputNumber())
+	}
+	return highest + 1
+}
+
+// outputKey normalizes an output path for entry matching
+func outputKey(path string) string {
+	return strings.TrimSuffix(filepath.Clean(path), filepath.Ext(path))
+}
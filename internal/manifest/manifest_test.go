@@ -0,0 +1,182 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Status
+		wantErr bool
+	}{
+		{"ok", StatusOK, false},
+		{"failed", StatusFailed, false},
+		{"flagged", StatusFlagged, false},
+		{"skipped", StatusSkipped, false},
+		{"pending", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseStatus(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStatus(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseStatus(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManifestPut(t *testing.T) {
+	m := New()
+	m.Put(Entry{Index: 1, Title: "Intro", Output: "out/section_01_intro.aiff", Status: StatusFailed, Reason: "boom"})
+	m.Put(Entry{Index: 2, Title: "Setup", Output: "out/section_02_setup.aiff", Status: StatusOK})
+
+	// Same output in a different format replaces the entry
+	m.Put(Entry{Index: 1, Title: "Intro", Output: "out/section_01_intro.m4a", Status: StatusOK})
+
+	if len(m.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(m.Entries))
+	}
+	if m.Entries[0].Status != StatusOK || m.Entries[0].Reason != "" {
+		t.Errorf("Expected replaced entry to be ok without reason, got %+v", m.Entries[0])
+	}
+	if m.Entries[0].UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+}
+
+func TestManifestGet(t *testing.T) {
+	m := New()
+	m.Put(Entry{Index: 1, Title: "Intro", Output: "out/section_01_intro.aiff", Status: StatusOK, Text: "Hello."})
+
+	// Entries are found regardless of the output format
+	entry, ok := m.Get("out/section_01_intro.m4a")
+	if !ok || entry.Text != "Hello." {
+		t.Errorf("Get() = %+v, %v", entry, ok)
+	}
+	if _, ok := m.Get("out/section_02_setup.aiff"); ok {
+		t.Error("Get() found an entry for an unknown output")
+	}
+}
+
+func TestManifestFilter(t *testing.T) {
+	m := New()
+	m.Put(Entry{Output: "a.aiff", Status: StatusOK})
+	m.Put(Entry{Output: "b.aiff", Status: StatusFailed})
+	m.Put(Entry{Output: "c.aiff", Status: StatusFlagged})
+	m.Put(Entry{Output: "d.aiff", Status: StatusFailed})
+
+	if got := len(m.Filter(StatusFailed, StatusFlagged)); got != 3 {
+		t.Errorf("Filter(failed, flagged) returned %d entries, want 3", got)
+	}
+	if got := m.Count(StatusFailed); got != 2 {
+		t.Errorf("Count(failed) = %d, want 2", got)
+	}
+	if got := len(m.Filter()); got != 0 {
+		t.Errorf("Filter() returned %d entries, want 0", got)
+	}
+}
+
+func TestManifestNextStartIndex(t *testing.T) {
+	m := New()
+	if got := m.NextStartIndex("/docs/part1.md"); got != 1 {
+		t.Errorf("NextStartIndex() on an empty manifest = %d, want 1", got)
+	}
+
+	m.Put(Entry{Source: "/docs/part1.md", Index: 1, Output: "out/section_01_intro.aiff"})
+	m.Put(Entry{Source: "/docs/part1.md", Index: 2, Output: "out/section_02_setup.aiff"})
+	m.Put(Entry{Source: "/docs/part2.md", Index: 1, Number: 3, Output: "out/section_03_usage.aiff"})
+
+	// New sources continue after the highest number
+	if got := m.NextStartIndex("/docs/part3.md"); got != 4 {
+		t.Errorf("NextStartIndex(part3) = %d, want 4", got)
+	}
+	// Known sources keep their numbers
+	if got := m.NextStartIndex("/docs/part2.md"); got != 3 {
+		t.Errorf("NextStartIndex(part2) = %d, want 3", got)
+	}
+	if got := m.NextStartIndex("/docs/part1.md"); got != 1 {
+		t.Errorf("NextStartIndex(part1) = %d, want 1", got)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := PathFor(t.TempDir())
+
+	m := New()
+	m.Put(Entry{Source: "/docs/intro.md", Index: 1, Title: "Intro", Output: "out/section_01_intro.aiff", Status: StatusFlagged, Reason: "word error rate 40% exceeds 25%"})
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Version != Version {
+		t.Errorf("Version = %d, want %d", loaded.Version, Version)
+	}
+	if len(loaded.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(loaded.Entries))
+	}
+	got := loaded.Entries[0]
+	want := m.Entries[0]
+	if got.Source != want.Source || got.Index != want.Index || got.Title != want.Title ||
+		got.Output != want.Output || got.Status != want.Status || got.Reason != want.Reason {
+		t.Errorf("Loaded entry = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadOrNew(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := LoadOrNew(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("LoadOrNew() error = %v", err)
+	}
+	if len(m.Entries) != 0 {
+		t.Errorf("Expected empty manifest, got %d entries", len(m.Entries))
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := LoadOrNew(invalid); err == nil {
+		t.Error("LoadOrNew() should fail for an invalid manifest")
+	}
+}
+
+func TestSettingsDiff(t *testing.T) {
+	base := Settings{Provider: "say", Voice: "Kate", Format: "aiff"}
+
+	tests := []struct {
+		name  string
+		other Settings
+		want  []string
+	}{
+		{name: "same settings", other: base, want: nil},
+		{name: "different voice", other: Settings{Provider: "say", Voice: "Daniel", Format: "aiff"}, want: []string{"voice Kate -> Daniel"}},
+		{
+			name:  "different provider and format",
+			other: Settings{Provider: "elevenlabs", Voice: "Kate", Format: "mp3"},
+			want:  []string{"provider say -> elevenlabs", "format aiff -> mp3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Diff(tt.other); !slices.Equal(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
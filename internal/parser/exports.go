@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Input formats for -input-format
+const (
+	InputMarkdown   = "markdown"   // Plain markdown tree
+	InputNotion     = "notion"     // Notion markdown export
+	InputConfluence = "confluence" // Confluence space exported to markdown
+)
+
+var (
+	// Notion appends the page ID to page files and subpage folders: "Setup 0123456789abcdef0123456789abcdef.md"
+	notionIDPattern = regexp.MustCompile(`\s+[0-9a-f]{32}$`)
+
+	// Confluence appends the page ID to page files: "Setup-Guide_98304.md"
+	confluenceIDPattern = regexp.MustCompile(`_\d+$`)
+)
+
+// confluenceSkipped are the directories and root files of a Confluence export that hold no pages
+var confluenceSkipped = []string{"attachments", "images", "styles", "index"}
+
+// ParseInputFormat parses an -input-format value (empty = markdown).
+func ParseInputFormat(value string) (string, error) {
+	switch value {
+	case "", InputMarkdown:
+		return InputMarkdown, nil
+	case InputNotion, InputConfluence:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid input format %q: must be 'markdown', 'notion', or 'confluence'", value)
+	}
+}
+
+// MapExport maps the files of a Notion or Confluence export to a readable
+// output tree: page IDs are removed from file and folder names, so subpages
+// land under their parent page's output, and Confluence attachment folders
+// and the space index are skipped. When two pages would map to the same
+// output, the later one keeps its original name. Plain markdown is returned unchanged.
+func MapExport(files []MarkdownFile, format string) []MarkdownFile {
+	if format != InputNotion && format != InputConfluence {
+		return files
+	}
+
+	mapped := make([]MarkdownFile, 0, len(files))
+	used := make(map[string]bool, len(files))
+	for _, file := range files {
+		parts := strings.Split(filepath.ToSlash(strings.TrimSuffix(file.RelPath, filepath.Ext(file.RelPath))), "/")
+		if format == InputConfluence && skipsConfluence(parts) {
+			continue
+		}
+
+		clean := make([]string, len(parts))
+		for i, part := range parts {
+			clean[i] = cleanExportName(part, format)
+		}
+		outputPath := filepath.Join(clean...)
+		if used[strings.ToLower(outputPath)] {
+			mapped = append(mapped, file)
+			continue
+		}
+		used[strings.ToLower(outputPath)] = true
+
+		file.FileName = clean[len(clean)-1]
+		file.OutputRelPath = outputPath + filepath.Ext(file.RelPath)
+		mapped = append(mapped, file)
+	}
+	return mapped
+}
+
+// skipsConfluence reports whether a Confluence export path, without extension,
+// is an attachment or the space index rather than a page
+func skipsConfluence(parts []string) bool {
+	if len(parts) == 1 {
+		return strings.EqualFold(parts[0], "index")
+	}
+	for _, dir := range parts[:len(parts)-1] {
+		if slices.Contains(confluenceSkipped, strings.ToLower(dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanExportName removes the page ID from a file or folder name of an export
+func cleanExportName(name, format string) string {
+	pattern := notionIDPattern
+	if format == InputConfluence {
+		pattern = confluenceIDPattern
+	}
+	if clean := pattern.ReplaceAllString(name, ""); clean != "" {
+		return clean
+	}
+	return name
+}
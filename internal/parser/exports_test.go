@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseInputFormat(t *testing.T) {
+	for value, want := range map[string]string{"": InputMarkdown, "markdown": InputMarkdown, "notion": InputNotion, "confluence": InputConfluence} {
+		got, err := ParseInputFormat(value)
+		if err != nil || got != want {
+			t.Errorf("ParseInputFormat(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseInputFormat("html"); err == nil {
+		t.Error("expected error for an unknown input format")
+	}
+}
+
+func TestMapExportNotion(t *testing.T) {
+	baseDir := t.TempDir()
+	id1, id2 := "0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"
+	for _, rel := range []string{
+		"Guide " + id1 + ".md",
+		"Guide " + id1 + "/Setup " + id2 + ".md",
+		"Guide " + id1 + "/diagram.png",
+	} {
+		path := filepath.Join(baseDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("## Intro\n\nText.\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := FindMarkdownFiles(baseDir)
+	if err != nil {
+		t.Fatalf("FindMarkdownFiles() error = %v", err)
+	}
+	files = MapExport(files, InputNotion)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+
+	want := map[string]string{
+		"Guide " + id1 + ".md":                          filepath.Join("out", "Guide"),
+		filepath.Join("Guide "+id1, "Setup "+id2+".md"): filepath.Join("out", "Guide", "Setup"),
+	}
+	for _, file := range files {
+		if got := file.GetOutputDir("out"); got != want[file.RelPath] {
+			t.Errorf("GetOutputDir(%s) = %q, want %q", file.RelPath, got, want[file.RelPath])
+		}
+	}
+	if data := files[1].TemplateData(); data.FileName != "Setup" || data.Parent != "Guide" {
+		t.Errorf("TemplateData() = %+v, want the cleaned names", data)
+	}
+}
+
+func TestMapExportConfluence(t *testing.T) {
+	files := []MarkdownFile{
+		{RelPath: "index.md", FileName: "index"},
+		{RelPath: "Setup-Guide_98304.md", FileName: "Setup-Guide_98304"},
+		{RelPath: filepath.Join("attachments", "98304", "notes.md"), FileName: "notes"},
+		{RelPath: "Setup-Guide_98305.md", FileName: "Setup-Guide_98305"},
+	}
+
+	mapped := MapExport(files, InputConfluence)
+	if len(mapped) != 2 {
+		t.Fatalf("got %d files, want the two pages", len(mapped))
+	}
+	if mapped[0].FileName != "Setup-Guide" || mapped[0].GetOutputDir("out") != filepath.Join("out", "Setup-Guide") {
+		t.Errorf("first page = %+v", mapped[0])
+	}
+	// Pages mapping to the same output keep their original names
+	if mapped[1].FileName != "Setup-Guide_98305" || mapped[1].GetOutputDir("out") != filepath.Join("out", "Setup-Guide_98305") {
+		t.Errorf("second page = %+v", mapped[1])
+	}
+
+	if got := MapExport(files, InputMarkdown); len(got) != len(files) {
+		t.Error("plain markdown must be returned unchanged")
+	}
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter holds the settings of a markdown file's YAML front matter,
+// overriding the run's configuration for that file. Empty fields keep the
+// configured value.
+type FrontMatter struct {
+	Provider  string // TTS provider
+	Voice     string // Voice of the provider (voice name, ElevenLabs voice ID, ...)
+	Rate      int    // Speaking rate in words per minute (0 = configured rate)
+	Format    string // Output audio format
+	Prefix    string // Prefix of output filenames
+	OutputDir string // Output directory, relative to the output root (-o)
+}
+
+// IsZero reports whether the front matter overrides nothing.
+func (fm FrontMatter) IsZero() bool {
+	return fm == FrontMatter{}
+}
+
+// LoadFrontMatter reads the front matter of a markdown file. Files without
+// front matter return a zero FrontMatter.
+func LoadFrontMatter(filename string) (FrontMatter, error) {
+	if err := validateMarkdownFile(filename); err != nil {
+		return FrontMatter{}, fmt.Errorf("file validation failed: %w", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return FrontMatter{}, err
+	}
+
+	fm, _, err := ParseFrontMatter(data)
+	return fm, err
+}
+
+// ParseFrontMatter parses the YAML front matter delimited by "---" lines at
+// the top of markdown content, returning it with the content that follows.
+// Only flat "key: value" pairs are read; keys md2audio does not use (title,
+// tags, ...) are ignored.
+func ParseFrontMatter(data []byte) (FrontMatter, []byte, error) {
+	block, body, ok := splitFrontMatter(data)
+	if !ok {
+		return FrontMatter{}, data, nil
+	}
+
+	var fm FrontMatter
+	for n, line := range strings.Split(block, "\n") {
+		// Nested values, list items, and comments belong to keys md2audio does not use
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value, _, _ = strings.Cut(value, " #")
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "provider":
+			fm.Provider = value
+		case "voice":
+			fm.Voice = value
+		case "rate":
+			rate, err := strconv.Atoi(value)
+			if err != nil || rate <= 0 {
+				return FrontMatter{}, nil, fmt.Errorf("front matter line %d: invalid rate %q: must be a positive number of words per minute", n+2, value)
+			}
+			fm.Rate = rate
+		case "format":
+			fm.Format = value
+		case "prefix":
+			fm.Prefix = value
+		case "output", "output_dir", "output-dir":
+			fm.OutputDir = value
+		}
+	}
+	return fm, body, nil
+}
+
+// splitFrontMatter splits content into its front matter block and the
+// content that follows. Front matter starts at the first line with "---"
+// and ends at the next "---" or "..." line.
+func splitFrontMatter(data []byte) (string, []byte, bool) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	first, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok || string(bytes.TrimRight(first, " \r")) != "---" {
+		return "", nil, false
+	}
+
+	var block []string
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		trimmed := string(bytes.TrimRight(line, " \r"))
+		if trimmed == "---" || trimmed == "..." {
+			return strings.Join(block, "\n"), rest, true
+		}
+		block = append(block, trimmed)
+	}
+	return "", nil, false
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		want     FrontMatter
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "no front matter",
+			content:  "# Title\n\n## Intro\nHello.\n",
+			wantBody: "# Title\n\n## Intro\nHello.\n",
+		},
+		{
+			name: "all settings",
+			content: "---\nprovider: elevenlabs\nvoice: \"21m00Tcm4TlvDq8ikWAM\"\nrate: 180\nformat: mp3\n" +
+				"prefix: 'lesson'\noutput_dir: course/week1\n---\n## Intro\nHello.\n",
+			want: FrontMatter{
+				Provider:  "elevenlabs",
+				Voice:     "21m00Tcm4TlvDq8ikWAM",
+				Rate:      180,
+				Format:    "mp3",
+				Prefix:    "lesson",
+				OutputDir: "course/week1",
+			},
+			wantBody: "## Intro\nHello.\n",
+		},
+		{
+			name: "other keys, lists, and comments are ignored",
+			content: "\ufeff---\r\ntitle: Week 1 # the first week\r\n# a comment\r\ntags:\r\n  - audio\r\n- loose\r\n" +
+				"voice: Samantha # narrator\r\n...\r\n## Intro\r\n",
+			want:     FrontMatter{Voice: "Samantha"},
+			wantBody: "## Intro\r\n",
+		},
+		{
+			name:     "unclosed front matter is content",
+			content:  "---\nvoice: Alex\n## Intro\n",
+			wantBody: "---\nvoice: Alex\n## Intro\n",
+		},
+		{
+			name:    "invalid rate",
+			content: "---\nrate: fast\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "zero rate",
+			content: "---\nrate: 0\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, body, err := ParseFrontMatter([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFrontMatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseFrontMatter() = %+v, want %+v", got, tt.want)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("ParseFrontMatter() body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseMarkdown_SkipsFrontMatter(t *testing.T) {
+	content := "---\nvoice: Alex\ntitle: Notes\n---\n## Intro\nHello there.\n"
+
+	sections, err := ParseMarkdown([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if len(sections) != 1 {
+		t.Fatalf("ParseMarkdown() returned %d sections, want 1", len(sections))
+	}
+	if sections[0].Title != "Intro" || sections[0].Content != "Hello there." {
+		t.Errorf("ParseMarkdown() = %+v, want the Intro section only", sections[0])
+	}
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrderKind is a directory processing order
+type OrderKind string
+
+const (
+	OrderDoc     OrderKind = "doc"     // Discovery order, by relative path in natural order (default)
+	OrderAlpha   OrderKind = "alpha"   // Case-insensitive by relative path
+	OrderNatural OrderKind = "natural" // By relative path, numbers compared by value (chapter2 before chapter10)
+	OrderMtime   OrderKind = "mtime"   // Most recently modified first
+	OrderShuffle OrderKind = "shuffle" // Seeded random order
+)
+
+// Order controls the order in which markdown files are processed.
+type Order struct {
+	Kind OrderKind
+	Seed uint64 // Shuffle seed (0 = not set)
+}
+
+// OrderFileName is the index file listing the processing order of a directory
+const OrderFileName = "_order.yaml"
+
+// ParseOrder parses an -order value: doc, alpha, natural, mtime, shuffle, or shuffle(seed).
+// An empty value selects the discovery order.
+func ParseOrder(value string) (Order, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch OrderKind(value) {
+	case "", OrderDoc:
+		return Order{Kind: OrderDoc}, nil
+	case OrderAlpha, OrderNatural, OrderMtime, OrderShuffle:
+		return Order{Kind: OrderKind(value)}, nil
+	}
+
+	if seed, ok := strings.CutPrefix(value, string(OrderShuffle)+"("); ok {
+		if seed, ok := strings.CutSuffix(seed, ")"); ok {
+			n, err := strconv.ParseUint(strings.TrimSpace(seed), 10, 64)
+			if err == nil && n > 0 {
+				return Order{Kind: OrderShuffle, Seed: n}, nil
+			}
+		}
+	}
+
+	return Order{}, fmt.Errorf("invalid -order %q: must be 'doc', 'alpha', 'natural', 'mtime', 'shuffle', or 'shuffle(seed)'", value)
+}
+
+// String returns the order as accepted by ParseOrder
+func (o Order) String() string {
+	if o.Kind == OrderShuffle && o.Seed != 0 {
+		return fmt.Sprintf("%s(%d)", o.Kind, o.Seed)
+	}
+	return string(o.Kind)
+}
+
+// SortMarkdownFiles reorders files in place. Shuffling with a zero seed
+// uses a random seed; the seed used is returned so the order can be reproduced.
+func SortMarkdownFiles(files []MarkdownFile, order Order) uint64 {
+	switch order.Kind {
+	case OrderAlpha:
+		slices.SortStableFunc(files, func(a, b MarkdownFile) int {
+			return strings.Compare(strings.ToLower(a.RelPath), strings.ToLower(b.RelPath))
+		})
+	case OrderNatural:
+		slices.SortStableFunc(files, func(a, b MarkdownFile) int {
+			return CompareNatural(a.RelPath, b.RelPath)
+		})
+	case OrderMtime:
+		mtimes := make(map[string]time.Time, len(files))
+		for _, file := range files {
+			if info, err := os.Stat(file.AbsPath); err == nil {
+				mtimes[file.AbsPath] = info.ModTime()
+			}
+		}
+		slices.SortStableFunc(files, func(a, b MarkdownFile) int {
+			return mtimes[b.AbsPath].Compare(mtimes[a.AbsPath])
+		})
+	case OrderShuffle:
+		seed := order.Seed
+		if seed == 0 {
+			seed = uint64(time.Now().UnixNano())
+		}
+		r := rand.New(rand.NewPCG(seed, seed))
+		r.Shuffle(len(files), func(i, j int) {
+			files[i], files[j] = files[j], files[i]
+		})
+		return seed
+	}
+	return 0
+}
+
+// CompareNatural compares two paths case-insensitively, comparing runs of
+// digits by their numeric value, so "chapter2" sorts before "chapter10".
+func CompareNatural(a, b string) int {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			if c := compareNumbers(aDigits, bDigits); c != 0 {
+				return c
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return cmp.Compare(a[0], b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// leadingDigits returns the run of ASCII digits at the start of s
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// compareNumbers compares two digit runs by value, then by length so
+// "01" sorts after "1"
+func compareNumbers(a, b string) int {
+	trimmedA, trimmedB := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if c := cmp.Compare(len(trimmedA), len(trimmedB)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(trimmedA, trimmedB); c != 0 {
+		return c
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// LoadOrderFile reads the _order.yaml index of baseDir, a YAML list of
+// markdown paths relative to baseDir, optionally under a "files:" key.
+// It returns nil without error when the directory has no index.
+func LoadOrderFile(baseDir string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, OrderFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", OrderFileName, err)
+	}
+
+	var paths []string
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" || line == "files:" {
+			continue
+		}
+		item, ok := strings.CutPrefix(line, "- ")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected a list item (- path), got %q", OrderFileName, n+1, line)
+		}
+		item, _, _ = strings.Cut(item, " #")
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if item != "" {
+			paths = append(paths, filepath.Clean(filepath.FromSlash(item)))
+		}
+	}
+	return paths, nil
+}
+
+// ApplyOrderFile moves the files listed in an _order.yaml index to the
+// front in the listed order; unlisted files follow in their current order.
+// Listed paths matching no file are returned.
+func ApplyOrderFile(files []MarkdownFile, listed []string) []string {
+	rank := make(map[string]int, len(listed))
+	for i, path := range listed {
+		if _, ok := rank[path]; !ok {
+			rank[path] = i
+		}
+	}
+
+	found := make(map[string]bool, len(files))
+	slices.SortStableFunc(files, func(a, b MarkdownFile) int {
+		rankA, okA := rank[a.RelPath]
+		rankB, okB := rank[b.RelPath]
+		switch {
+		case okA && okB:
+			return cmp.Compare(rankA, rankB)
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return 0
+	})
+	for _, file := range files {
+		found[file.RelPath] = true
+	}
+
+	var missing []string
+	for _, path := range listed {
+		if !found[path] && !slices.Contains(missing, path) {
+			missing = append(missing, path)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Order
+		wantErr bool
+	}{
+		{value: "", want: Order{Kind: OrderDoc}},
+		{value: "doc", want: Order{Kind: OrderDoc}},
+		{value: "Alpha", want: Order{Kind: OrderAlpha}},
+		{value: "natural", want: Order{Kind: OrderNatural}},
+		{value: "mtime", want: Order{Kind: OrderMtime}},
+		{value: "shuffle", want: Order{Kind: OrderShuffle}},
+		{value: "shuffle(42)", want: Order{Kind: OrderShuffle, Seed: 42}},
+		{value: "shuffle()", wantErr: true},
+		{value: "shuffle(0)", wantErr: true},
+		{value: "shuffle(abc)", wantErr: true},
+		{value: "size", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseOrder(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrder(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOrder(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderString(t *testing.T) {
+	if got := (Order{Kind: OrderShuffle, Seed: 7}).String(); got != "shuffle(7)" {
+		t.Errorf("String() = %q, want %q", got, "shuffle(7)")
+	}
+	if got := (Order{Kind: OrderMtime}).String(); got != "mtime" {
+		t.Errorf("String() = %q, want %q", got, "mtime")
+	}
+}
+
+func TestSortMarkdownFiles(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"b.md", "A.md", "c.md"}
+	now := time.Now()
+	mtimes := map[string]time.Time{
+		"b.md": now.Add(-2 * time.Hour),
+		"A.md": now.Add(-3 * time.Hour),
+		"c.md": now.Add(-1 * time.Hour),
+	}
+
+	newFiles := func() []MarkdownFile {
+		files := make([]MarkdownFile, len(names))
+		for i, name := range names {
+			files[i] = MarkdownFile{AbsPath: filepath.Join(dir, name), RelPath: name}
+		}
+		return files
+	}
+	relPaths := func(files []MarkdownFile) []string {
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.RelPath
+		}
+		return paths
+	}
+
+	for name, mtime := range mtimes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("## Title\n\nText."), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	tests := []struct {
+		order Order
+		want  []string
+	}{
+		{order: Order{Kind: OrderDoc}, want: []string{"b.md", "A.md", "c.md"}},
+		{order: Order{Kind: OrderAlpha}, want: []string{"A.md", "b.md", "c.md"}},
+		{order: Order{Kind: OrderMtime}, want: []string{"c.md", "b.md", "A.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			files := newFiles()
+			SortMarkdownFiles(files, tt.order)
+			if got := relPaths(files); !slices.Equal(got, tt.want) {
+				t.Errorf("SortMarkdownFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("shuffle", func(t *testing.T) {
+		first, second := newFiles(), newFiles()
+		if seed := SortMarkdownFiles(first, Order{Kind: OrderShuffle, Seed: 9}); seed != 9 {
+			t.Errorf("SortMarkdownFiles() seed = %d, want 9", seed)
+		}
+		SortMarkdownFiles(second, Order{Kind: OrderShuffle, Seed: 9})
+		if !slices.Equal(relPaths(first), relPaths(second)) {
+			t.Errorf("Expected the same seed to give the same order: %v vs %v", relPaths(first), relPaths(second))
+		}
+
+		if seed := SortMarkdownFiles(newFiles(), Order{Kind: OrderShuffle}); seed == 0 {
+			t.Error("Expected a random seed to be returned")
+		}
+	})
+}
+
+func TestCompareNatural(t *testing.T) {
+	paths := []string{"chapter10.md", "Chapter2.md", "chapter1.md", "appendix.md", "chapter02.md", "chapter2/intro.md"}
+	slices.SortStableFunc(paths, CompareNatural)
+	want := []string{"appendix.md", "chapter1.md", "Chapter2.md", "chapter2/intro.md", "chapter02.md", "chapter10.md"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("sorted = %v, want %v", paths, want)
+	}
+}
+
+func TestLoadOrderFile(t *testing.T) {
+	dir := t.TempDir()
+	if paths, err := LoadOrderFile(dir); err != nil || paths != nil {
+		t.Fatalf("LoadOrderFile() without index = (%v, %v), want (nil, nil)", paths, err)
+	}
+
+	index := "# Book order\nfiles:\n  - intro.md\n  - \"part 2/chapter10.md\" # late\n\n  - 'appendix.md'\n"
+	if err := os.WriteFile(filepath.Join(dir, OrderFileName), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := LoadOrderFile(dir)
+	if err != nil {
+		t.Fatalf("LoadOrderFile() error = %v", err)
+	}
+	want := []string{"intro.md", filepath.Join("part 2", "chapter10.md"), "appendix.md"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("LoadOrderFile() = %v, want %v", paths, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, OrderFileName), []byte("intro: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrderFile(dir); err == nil {
+		t.Error("Expected an error for an index that is not a list")
+	}
+}
+
+func TestApplyOrderFile(t *testing.T) {
+	files := []MarkdownFile{{RelPath: "a.md"}, {RelPath: "b.md"}, {RelPath: "c.md"}, {RelPath: "d.md"}}
+	missing := ApplyOrderFile(files, []string{"c.md", "gone.md", "a.md"})
+
+	got := make([]string, len(files))
+	for i, file := range files {
+		got[i] = file.RelPath
+	}
+	if want := []string{"c.md", "a.md", "b.md", "d.md"}; !slices.Equal(got, want) {
+		t.Errorf("ApplyOrderFile() order = %v, want %v", got, want)
+	}
+	if !slices.Equal(missing, []string{"gone.md"}) {
+		t.Errorf("ApplyOrderFile() missing = %v, want [gone.md]", missing)
+	}
+}
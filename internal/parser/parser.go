@@ -4,10 +4,16 @@
 //
 // Key features:
 //   - H2 section extraction from markdown files
+//   - Chapter mode, with H2 chapters and nested H3 tracks
 //   - Timing annotation parsing (e.g., "## Scene 1 (5s)")
+//   - Per-section provider and voice overrides (e.g., "## Intro {provider=say voice=Alex}")
+//   - Skipped sections (e.g., "## References {skip}" or "<!-- md2audio:skip -->")
+//   - Per-file settings from YAML front matter (voice, provider, rate, format, prefix, output)
 //   - Recursive markdown file discovery
 //   - Input validation (file size, path safety)
 //   - Directory structure mirroring for batch processing
+//   - Output directory templating (e.g., "./audio/{{.RelDir}}/{{.FileName}}")
+//   - Output trees for Notion and Confluence exports
 package parser
 
 import (
@@ -15,7 +21,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"text/template"
 
 	"github.com/indaco/md2audio/internal/text"
 )
@@ -30,16 +38,62 @@ var (
 	// Pattern to match H2 headers (##)
 	h2Pattern = regexp.MustCompile(`^##\s+(.+)$`)
 
+	// Pattern to match H3 headers (###), the tracks of a chapter in chapter mode
+	h3Pattern = regexp.MustCompile(`^###\s+(.+)$`)
+
 	// Pattern to extract timing from title: (0-8s) or (10s) or (8 seconds)
 	timingPattern = regexp.MustCompile(`\((\d+(?:\.\d+)?)\s*(?:-\s*(\d+(?:\.\d+)?))?\s*s(?:ec(?:ond)?s?)?\)`)
+
+	// Pattern to extract key=value overrides and the skip flag from a title: {provider=say voice=Alex}, {skip}
+	overridePattern = regexp.MustCompile(`\{\s*((?:\w+=[^\s{}]+|(?i:skip))(?:\s+(?:\w+=[^\s{}]+|(?i:skip)))*)\s*\}`)
+
+	// Pattern to match the comment excluding a section from audio generation, in its heading or on a line of its own
+	skipCommentPattern = regexp.MustCompile(`(?im)^\s*<!--\s*md2audio:skip\s*-->\s*$`)
+	skipTitlePattern   = regexp.MustCompile(`(?i)\s*<!--\s*md2audio:skip\s*-->`)
 )
 
 // Section represents a markdown section with title and content
 type Section struct {
+	Index     int // 1-based position of the section in its source file
 	Title     string
 	Content   string
 	Duration  float64 // Target duration in seconds
 	HasTiming bool    // Whether timing was specified
+	Sentence  int     // 1-based position of the sentence within the section (0 = the whole section)
+	Provider  string  // TTS provider overriding the configured one (empty = configured provider)
+	Voice     string  // Voice overriding the configured one (empty = configured voice)
+
+	Chapter      int    // 1-based position of the section's H2 chapter in chapter mode (0 = not in chapter mode)
+	Track        int    // 1-based position of the section within its chapter in chapter mode
+	ChapterTitle string // Title of the section's H2 chapter in chapter mode
+}
+
+// Sentences splits a section into one section per sentence, for -granularity sentence.
+// A target duration is shared among the sentences in proportion to their word counts.
+func (s Section) Sentences() []Section {
+	sentences := text.SplitSentences(s.Content)
+	totalWords := len(strings.Fields(s.Content))
+
+	split := make([]Section, len(sentences))
+	for i, sentence := range sentences {
+		split[i] = Section{
+			Index:     s.Index,
+			Title:     s.Title,
+			Content:   sentence,
+			HasTiming: s.HasTiming,
+			Sentence:  i + 1,
+			Provider:  s.Provider,
+			Voice:     s.Voice,
+
+			Chapter:      s.Chapter,
+			Track:        s.Track,
+			ChapterTitle: s.ChapterTitle,
+		}
+		if s.HasTiming && totalWords > 0 {
+			split[i].Duration = s.Duration * float64(len(strings.Fields(sentence))) / float64(totalWords)
+		}
+	}
+	return split
 }
 
 // validateMarkdownFile validates that a file is safe to read
@@ -109,17 +163,63 @@ func parseTimingAnnotation(titleWithTiming string) (duration float64, hasTiming
 	return 0, false, titleWithTiming
 }
 
+// parseOverrideAnnotation extracts the provider and voice overrides of a title,
+// e.g. "Intro (8s) {provider=say voice=Alex}", and whether it is marked
+// {skip}, and returns them with the title without the annotation. Unknown
+// keys are ignored.
+func parseOverrideAnnotation(title string) (provider, voice string, skip bool, cleanTitle string) {
+	match := overridePattern.FindStringSubmatchIndex(title)
+	if match == nil {
+		return "", "", false, title
+	}
+
+	for _, pair := range strings.Fields(title[match[2]:match[3]]) {
+		key, value, _ := strings.Cut(pair, "=")
+		switch strings.ToLower(key) {
+		case "provider":
+			provider = strings.ToLower(value)
+		case "voice":
+			voice = value
+		case "skip":
+			skip = true
+		}
+	}
+	return provider, voice, skip, strings.Join(strings.Fields(title[:match[0]]+" "+title[match[1]:]), " ")
+}
+
+// ParseOptions controls how sections are extracted from markdown.
+type ParseOptions struct {
+	Clean        text.CleanOptions // Text cleaning options
+	EmptySection string            // Text spoken for sections left without text by cleaning, such as image- or code-only sections (empty = drop them)
+	Chapters     bool              // Treat H2 headings as chapters and their H3 headings as tracks
+}
+
 // saveSection saves a section with cleaned content to the sections slice.
 // Returns the updated sections slice.
-func saveSection(sections []Section, section *Section, contentLines []string) []Section {
+func saveSection(sections []Section, section *Section, contentLines []string, opts ParseOptions) []Section {
 	if section == nil {
 		return sections
 	}
 
 	sectionText := strings.Join(contentLines, "\n")
-	sectionText = text.CleanMarkdown(sectionText)
-	if sectionText != "" {
-		section.Content = sectionText
+	if skipCommentPattern.MatchString(sectionText) {
+		// Marked as excluded from audio generation
+		return sections
+	}
+	cleaned := text.CleanMarkdownWith(sectionText, opts.Clean)
+	if cleaned == "" && strings.TrimSpace(sectionText) != "" {
+		// Media-only sections keep their place in the numbering when a text is configured
+		cleaned = opts.EmptySection
+	}
+	if cleaned != "" {
+		section.Content = cleaned
+		section.Index = len(sections) + 1
+		if section.Chapter > 0 {
+			section.Track = 1
+			if last := len(sections) - 1; last >= 0 && sections[last].Chapter == section.Chapter {
+				section.Track = sections[last].Track + 1
+			}
+		}
 		sections = append(sections, *section)
 	}
 
@@ -128,6 +228,11 @@ func saveSection(sections []Section, section *Section, contentLines []string) []
 
 // ParseMarkdownFile parses a markdown file and extracts H2 sections
 func ParseMarkdownFile(filename string) ([]Section, error) {
+	return ParseMarkdownFileWith(filename, ParseOptions{})
+}
+
+// ParseMarkdownFileWith parses a markdown file and extracts H2 sections using opts
+func ParseMarkdownFileWith(filename string, opts ParseOptions) ([]Section, error) {
 	// Validate file before reading
 	if err := validateMarkdownFile(filename); err != nil {
 		return nil, fmt.Errorf("file validation failed: %w", err)
@@ -138,30 +243,86 @@ func ParseMarkdownFile(filename string) ([]Section, error) {
 		return nil, err
 	}
 
-	content := string(data)
-	lines := strings.Split(content, "\n")
+	return parseSections(data, opts)
+}
+
+// ParseMarkdown parses in-memory markdown content and extracts H2 sections,
+// for callers such as server mode that do not read from the filesystem
+func ParseMarkdown(data []byte) ([]Section, error) {
+	return parseSections(data, ParseOptions{})
+}
+
+// ParseMarkdownWith parses in-memory markdown content, cleaning section text with opts
+func ParseMarkdownWith(data []byte, opts text.CleanOptions) ([]Section, error) {
+	return parseSections(data, ParseOptions{Clean: opts})
+}
+
+// parseSections extracts the H2 sections of in-memory markdown content, or
+// the tracks of its H2 chapters in chapter mode
+func parseSections(data []byte, opts ParseOptions) ([]Section, error) {
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("content too large: %d bytes (max: %d bytes)", len(data), MaxFileSize)
+	}
+
+	// Front matter is configuration, not narration
+	if _, body, ok := splitFrontMatter(data); ok {
+		data = body
+	}
+	lines := strings.Split(string(data), "\n")
 
 	var sections []Section
 	var currentSection *Section
 	var contentLines []string
 
+	// In chapter mode, the H2 heading whose tracks are being read (nil before the first chapter or in a skipped one)
+	var chapter *Section
+	chapters := 0
+
 	for _, line := range lines {
 		if match := h2Pattern.FindStringSubmatch(line); match != nil {
 			// Save previous section if exists
-			sections = saveSection(sections, currentSection, contentLines)
+			sections = saveSection(sections, currentSection, contentLines, opts)
 
 			// Start new section
-			titleWithTiming := strings.TrimSpace(match[1])
-			duration, hasTiming, cleanTitle := parseTimingAnnotation(titleWithTiming)
-
-			currentSection = &Section{
-				Title:     cleanTitle,
-				Duration:  duration,
-				HasTiming: hasTiming,
+			section, skip := parseHeading(match[1])
+			if skip {
+				// Skipped sections and their content are left out
+				currentSection, chapter = nil, nil
+				continue
+			}
+			if opts.Chapters {
+				// Text before the first H3 is the chapter's first track
+				chapters++
+				section.Chapter = chapters
+				section.ChapterTitle = section.Title
+				chapter = &section
 			}
+			currentSection = &section
 
 			// Reset content lines for new section
 			contentLines = []string{}
+		} else if match := h3Pattern.FindStringSubmatch(line); match != nil && opts.Chapters {
+			sections = saveSection(sections, currentSection, contentLines, opts)
+			currentSection = nil
+			if chapter == nil {
+				continue
+			}
+
+			section, skip := parseHeading(match[1])
+			if skip {
+				continue
+			}
+			// Tracks use the voice of their chapter unless they override it
+			if section.Provider == "" {
+				section.Provider = chapter.Provider
+			}
+			if section.Voice == "" {
+				section.Voice = chapter.Voice
+			}
+			section.Chapter = chapter.Chapter
+			section.ChapterTitle = chapter.ChapterTitle
+			currentSection = &section
+			contentLines = []string{}
 		} else if currentSection != nil {
 			// Add line to current section content
 			contentLines = append(contentLines, line)
@@ -169,11 +330,49 @@ func ParseMarkdownFile(filename string) ([]Section, error) {
 	}
 
 	// Save last section
-	sections = saveSection(sections, currentSection, contentLines)
+	sections = saveSection(sections, currentSection, contentLines, opts)
 
+	if opts.Chapters {
+		renumberChapters(sections)
+	}
 	return sections, nil
 }
 
+// parseHeading parses the title of an H2 or H3 heading, with its timing and
+// override annotations, into a section without content. It reports whether
+// the heading is marked as skipped.
+func parseHeading(heading string) (Section, bool) {
+	title := strings.TrimSpace(heading)
+	skipComment := skipTitlePattern.MatchString(title)
+	title = skipTitlePattern.ReplaceAllString(title, "")
+	provider, voice, skip, titleWithTiming := parseOverrideAnnotation(title)
+	if skip || skipComment {
+		return Section{}, true
+	}
+	duration, hasTiming, cleanTitle := parseTimingAnnotation(titleWithTiming)
+
+	return Section{
+		Title:     cleanTitle,
+		Duration:  duration,
+		HasTiming: hasTiming,
+		Provider:  provider,
+		Voice:     voice,
+	}, false
+}
+
+// renumberChapters numbers the chapters of sections consecutively, so
+// chapters left without tracks do not leave gaps in the numbering
+func renumberChapters(sections []Section) {
+	number, last := 0, 0
+	for i := range sections {
+		if sections[i].Chapter != last {
+			last = sections[i].Chapter
+			number++
+		}
+		sections[i].Chapter = number
+	}
+}
+
 // parseFloat parses a string to float64
 func parseFloat(s string) (float64, error) {
 	var f float64
@@ -187,9 +386,21 @@ type MarkdownFile struct {
 	RelPath  string // Relative path from base directory
 	BaseDir  string // Base directory that was scanned
 	FileName string // Just the filename without extension
+	Lang     string // Language code in multi-language runs (empty otherwise)
+
+	OutputRelPath string // Relative path mirrored in the output tree (RelPath if empty; see MapExport)
 }
 
-// FindMarkdownFiles recursively finds all .md files in the given directory
+// outputRelPath returns the relative path mirrored in the output tree
+func (mf MarkdownFile) outputRelPath() string {
+	if mf.OutputRelPath != "" {
+		return mf.OutputRelPath
+	}
+	return mf.RelPath
+}
+
+// FindMarkdownFiles recursively finds all .md files in the given directory,
+// in natural order of their relative paths (chapter2 before chapter10)
 func FindMarkdownFiles(baseDir string) ([]MarkdownFile, error) {
 	var files []MarkdownFile
 
@@ -236,6 +447,9 @@ func FindMarkdownFiles(baseDir string) ([]MarkdownFile, error) {
 		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
+	slices.SortStableFunc(files, func(a, b MarkdownFile) int {
+		return CompareNatural(a.RelPath, b.RelPath)
+	})
 	return files, nil
 }
 
@@ -243,7 +457,7 @@ func FindMarkdownFiles(baseDir string) ([]MarkdownFile, error) {
 // It creates a mirror structure based on the relative path
 func (mf MarkdownFile) GetOutputDir(baseOutputDir string) string {
 	// Get the directory containing the markdown file (relative to base)
-	relDir := filepath.Dir(mf.RelPath)
+	relDir := filepath.Dir(mf.outputRelPath())
 
 	// If the file is in the root, use the filename as the directory
 	if relDir == "." {
@@ -253,3 +467,95 @@ func (mf MarkdownFile) GetOutputDir(baseOutputDir string) string {
 	// Otherwise, append the directory path and filename
 	return filepath.Join(baseOutputDir, relDir, mf.FileName)
 }
+
+// OutputTemplateData holds the variables available to output directory templates.
+type OutputTemplateData struct {
+	RelDir   string // Directory of the file relative to the input root ("." for root files)
+	FileName string // Filename without extension
+	BaseDir  string // Name of the scanned input directory
+	Parent   string // Name of the file's immediate parent directory
+	Lang     string // Language code in multi-language runs (empty otherwise)
+}
+
+// IsOutputTemplate reports whether an output directory contains template actions.
+func IsOutputTemplate(outputDir string) bool {
+	return strings.Contains(outputDir, "{{")
+}
+
+// OutputRoot returns the directory holding all output. For output templates
+// it is the static part of the template before the first placeholder.
+func OutputRoot(outputDir string) string {
+	if !IsOutputTemplate(outputDir) {
+		return outputDir
+	}
+	prefix, _, _ := strings.Cut(outputDir, "{{")
+	if strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, string(filepath.Separator)) {
+		return filepath.Clean(prefix)
+	}
+	return filepath.Dir(prefix)
+}
+
+// ParseOutputTemplate parses an output directory template.
+// Unknown variables are reported as errors when the template is executed.
+func ParseOutputTemplate(outputDir string) (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template %q: %w", outputDir, err)
+	}
+	return tmpl, nil
+}
+
+// NewMarkdownFile describes a single markdown file as if it had been discovered
+// at the root of its own directory.
+func NewMarkdownFile(path string) (MarkdownFile, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return MarkdownFile{}, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	return MarkdownFile{
+		AbsPath:  absPath,
+		RelPath:  filepath.Base(absPath),
+		BaseDir:  filepath.Dir(absPath),
+		FileName: strings.TrimSuffix(filepath.Base(absPath), ".md"),
+	}, nil
+}
+
+// TemplateData returns the output template variables for this markdown file.
+func (mf MarkdownFile) TemplateData() OutputTemplateData {
+	relDir := filepath.Dir(mf.outputRelPath())
+
+	parent := filepath.Base(relDir)
+	if relDir == "." {
+		parent = filepath.Base(mf.BaseDir)
+	}
+
+	return OutputTemplateData{
+		RelDir:   relDir,
+		FileName: mf.FileName,
+		BaseDir:  filepath.Base(mf.BaseDir),
+		Parent:   parent,
+		Lang:     mf.Lang,
+	}
+}
+
+// ResolveOutputDir returns the output directory for this markdown file.
+// If outputDir is a template it is rendered with the file's variables,
+// otherwise the default mirror structure from GetOutputDir is used.
+func (mf MarkdownFile) ResolveOutputDir(outputDir string) (string, error) {
+	if !IsOutputTemplate(outputDir) {
+		return mf.GetOutputDir(outputDir), nil
+	}
+
+	tmpl, err := ParseOutputTemplate(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, mf.TemplateData()); err != nil {
+		return "", fmt.Errorf("failed to render output template: %w", err)
+	}
+
+	return filepath.Clean(buf.String()), nil
+}
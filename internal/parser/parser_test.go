@@ -3,7 +3,11 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/indaco/md2audio/internal/text"
 )
 
 func TestParseMarkdownFile(t *testing.T) {
@@ -164,6 +168,10 @@ Content.`,
 				if sections[i].Content == "" {
 					t.Errorf("Section %d: content should not be empty", i)
 				}
+
+				if sections[i].Index != i+1 {
+					t.Errorf("Section %d: expected index %d, got %d", i, i+1, sections[i].Index)
+				}
 			}
 		})
 	}
@@ -193,6 +201,78 @@ func TestParseMarkdownFileEmpty(t *testing.T) {
 	}
 }
 
+func TestParseMarkdown(t *testing.T) {
+	content := []byte("# Title\n\n## Intro (5s)\nStart with `md2audio`\n\n## Empty\n\n## Usage\nSee [docs](https://example.com).\n")
+
+	tests := []struct {
+		name     string
+		opts     text.CleanOptions
+		wantText string
+	}{
+		{name: "default cleaning", wantText: "Start with"},
+		{name: "keep inline code", opts: text.CleanOptions{KeepInlineCode: true}, wantText: "Start with md2audio"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := ParseMarkdownWith(content, tt.opts)
+			if err != nil {
+				t.Fatalf("ParseMarkdownWith() error = %v", err)
+			}
+			if len(sections) != 2 {
+				t.Fatalf("got %d sections, want 2", len(sections))
+			}
+			if sections[0].Title != "Intro" || !sections[0].HasTiming || sections[0].Duration != 5 {
+				t.Errorf("first section = %+v", sections[0])
+			}
+			if sections[0].Content != tt.wantText {
+				t.Errorf("first section content = %q, want %q", sections[0].Content, tt.wantText)
+			}
+			if sections[1].Title != "Usage" || sections[1].Index != 2 || sections[1].Content != "See docs." {
+				t.Errorf("second section = %+v", sections[1])
+			}
+		})
+	}
+
+	if sections, err := ParseMarkdown(content); err != nil || len(sections) != 2 {
+		t.Errorf("ParseMarkdown() = %d sections, %v", len(sections), err)
+	}
+	if _, err := ParseMarkdown(make([]byte, MaxFileSize+1)); err == nil {
+		t.Error("expected error for content larger than MaxFileSize")
+	}
+}
+
+func TestParseMarkdownFileEmptySection(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "guide.md")
+	content := "## Intro\nWelcome.\n\n## Architecture\n![Diagram](arch.png)\n\n## Divider\n\n## Usage\nRun it.\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	sections, err := ParseMarkdownFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseMarkdownFile() error = %v", err)
+	}
+	if len(sections) != 2 || sections[1].Title != "Usage" || sections[1].Index != 2 {
+		t.Errorf("Expected media-only sections to be dropped by default, got %+v", sections)
+	}
+
+	sections, err = ParseMarkdownFileWith(tmpFile, ParseOptions{EmptySection: "This section contains a diagram."})
+	if err != nil {
+		t.Fatalf("ParseMarkdownFileWith() error = %v", err)
+	}
+	// Sections without any content are still dropped
+	if len(sections) != 3 {
+		t.Fatalf("got %d sections, want 3", len(sections))
+	}
+	if sections[1].Title != "Architecture" || sections[1].Content != "This section contains a diagram." || sections[1].Index != 2 {
+		t.Errorf("media-only section = %+v", sections[1])
+	}
+	if sections[2].Title != "Usage" || sections[2].Index != 3 {
+		t.Errorf("last section = %+v", sections[2])
+	}
+}
+
 func TestSectionStructure(t *testing.T) {
 	markdown := `## Test Section (10s)
 
@@ -230,6 +310,118 @@ This is test content.`
 	}
 }
 
+func TestParseOverrideAnnotation(t *testing.T) {
+	content := []byte("## Intro (8s) {provider=say}\nHello.\n\n## Demo {provider=ElevenLabs voice=Rachel}\nLook.\n\n## Setup {#setup}\nRun it.\n\n## Outro {voice=Alex mood=calm} (0-4s)\nBye.\n")
+
+	sections, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	want := []Section{
+		{Index: 1, Title: "Intro", Content: "Hello.", Duration: 8, HasTiming: true, Provider: "say"},
+		{Index: 2, Title: "Demo", Content: "Look.", Provider: "elevenlabs", Voice: "Rachel"},
+		{Index: 3, Title: "Setup {#setup}", Content: "Run it."},
+		{Index: 4, Title: "Outro", Content: "Bye.", Duration: 4, HasTiming: true, Voice: "Alex"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d", len(sections), len(want))
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d = %+v, want %+v", i+1, sections[i], want[i])
+		}
+	}
+
+	for _, sentence := range sections[1].Sentences() {
+		if sentence.Provider != "elevenlabs" || sentence.Voice != "Rachel" {
+			t.Errorf("sentence lost the section overrides: %+v", sentence)
+		}
+	}
+}
+
+func TestParseMarkdownSkip(t *testing.T) {
+	content := []byte("## Intro\nHello.\n\n## Changelog {skip}\n- Fixed things.\n\n## Setup {provider=say SKIP}\nRun it.\n\n" +
+		"## Usage\n<!-- md2audio:skip -->\nNot spoken.\n\n## References <!-- md2audio:skip -->\nLinks.\n\n" +
+		"## Outro\nSee the <!-- md2audio:skip --> comment.\n")
+
+	sections, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	want := []Section{
+		{Index: 1, Title: "Intro", Content: "Hello."},
+		{Index: 2, Title: "Outro", Content: "See the <!-- md2audio:skip --> comment."},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %+v, want %+v", sections, want)
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d = %+v, want %+v", i+1, sections[i], want[i])
+		}
+	}
+}
+
+func TestParseMarkdownChapters(t *testing.T) {
+	content := []byte("Preamble.\n\n### Orphan\nNot in a chapter.\n\n" +
+		"## Getting Started (10s) {voice=Kate}\nWelcome.\n\n### Install\nRun the installer.\n\n### Configure {voice=Alex}\nEdit the file.\n\n" +
+		"## Changelog {skip}\n### Fixes\nFixed things.\n\n" +
+		"## Usage\n### Basics (5s)\nRun it.\n")
+
+	sections, err := parseSections(content, ParseOptions{Chapters: true})
+	if err != nil {
+		t.Fatalf("parseSections() error = %v", err)
+	}
+	want := []Section{
+		{Index: 1, Title: "Getting Started", Content: "Welcome.", Duration: 10, HasTiming: true, Voice: "Kate", Chapter: 1, Track: 1, ChapterTitle: "Getting Started"},
+		{Index: 2, Title: "Install", Content: "Run the installer.", Voice: "Kate", Chapter: 1, Track: 2, ChapterTitle: "Getting Started"},
+		{Index: 3, Title: "Configure", Content: "Edit the file.", Voice: "Alex", Chapter: 1, Track: 3, ChapterTitle: "Getting Started"},
+		{Index: 4, Title: "Basics", Content: "Run it.", Duration: 5, HasTiming: true, Chapter: 2, Track: 1, ChapterTitle: "Usage"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %+v, want %+v", sections, want)
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d = %+v, want %+v", i+1, sections[i], want[i])
+		}
+	}
+
+	// Without chapter mode, H3 headings are part of their H2 section
+	sections, err = ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if len(sections) != 2 || sections[0].Chapter != 0 || !strings.Contains(sections[0].Content, "Install") {
+		t.Errorf("ParseMarkdown() = %+v, want 2 sections with their H3 content", sections)
+	}
+}
+
+func TestSectionSentences(t *testing.T) {
+	section := Section{Index: 2, Title: "Intro", Content: "Hello there. Welcome to the demo!", Duration: 12, HasTiming: true}
+
+	got := section.Sentences()
+	want := []Section{
+		{Index: 2, Title: "Intro", Content: "Hello there.", Duration: 4, HasTiming: true, Sentence: 1},
+		{Index: 2, Title: "Intro", Content: "Welcome to the demo!", Duration: 8, HasTiming: true, Sentence: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Sentences() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Sentences()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	untimed := Section{Index: 1, Title: "Notes", Content: "One. Two."}
+	for _, sentence := range untimed.Sentences() {
+		if sentence.HasTiming || sentence.Duration != 0 {
+			t.Errorf("Untimed section produced a timed sentence: %+v", sentence)
+		}
+	}
+}
+
 func TestFindMarkdownFiles(t *testing.T) {
 	// Create temporary directory structure
 	tmpDir := t.TempDir()
@@ -330,6 +522,32 @@ func TestMarkdownFileGetOutputDir(t *testing.T) {
 	}
 }
 
+func TestFindMarkdownFilesNaturalOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"chapter10.md", "chapter2.md", "chapter1.md", "part2/a.md", "part10/a.md"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("## Title\nContent"), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", name, err)
+		}
+	}
+
+	mdFiles, err := FindMarkdownFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("FindMarkdownFiles() error = %v", err)
+	}
+	var got []string
+	for _, mf := range mdFiles {
+		got = append(got, filepath.ToSlash(mf.RelPath))
+	}
+	want := []string{"chapter1.md", "chapter2.md", "chapter10.md", "part2/a.md", "part10/a.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindMarkdownFiles() order = %v, want %v", got, want)
+	}
+}
+
 func TestFindMarkdownFilesEmptyDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -349,3 +567,138 @@ func TestFindMarkdownFilesNonExistentDirectory(t *testing.T) {
 		t.Error("Expected error for nonexistent directory, got nil")
 	}
 }
+
+func TestMarkdownFileResolveOutputDir(t *testing.T) {
+	tests := []struct {
+		name      string
+		relPath   string
+		fileName  string
+		baseDir   string
+		lang      string
+		outputDir string
+		expected  string
+	}{
+		{
+			name:      "plain directory uses mirror structure",
+			relPath:   "chapter1/content.md",
+			fileName:  "content",
+			baseDir:   "/docs",
+			outputDir: "/output",
+			expected:  "/output/chapter1/content",
+		},
+		{
+			name:      "template reproducing mirror structure",
+			relPath:   "chapter1/content.md",
+			fileName:  "content",
+			baseDir:   "/docs",
+			outputDir: "/output/{{.RelDir}}/{{.FileName}}",
+			expected:  "/output/chapter1/content",
+		},
+		{
+			name:      "template with root level file",
+			relPath:   "intro.md",
+			fileName:  "intro",
+			baseDir:   "/docs",
+			outputDir: "/output/{{.RelDir}}/{{.FileName}}",
+			expected:  "/output/intro",
+		},
+		{
+			name:      "flattened with parent directory",
+			relPath:   "api/v1/endpoints.md",
+			fileName:  "endpoints",
+			baseDir:   "/docs",
+			outputDir: "/output/{{.Parent}}_{{.FileName}}",
+			expected:  "/output/v1_endpoints",
+		},
+		{
+			name:      "parent of root file is base directory",
+			relPath:   "intro.md",
+			fileName:  "intro",
+			baseDir:   "/docs",
+			outputDir: "/output/{{.Parent}}/{{.FileName}}-en",
+			expected:  "/output/docs/intro-en",
+		},
+		{
+			name:      "language variable",
+			relPath:   "guide.md",
+			fileName:  "guide",
+			baseDir:   "/docs/es",
+			lang:      "es",
+			outputDir: "/output/{{.FileName}}_{{.Lang}}",
+			expected:  "/output/guide_es",
+		},
+		{
+			name:      "base directory variable",
+			relPath:   "intro.md",
+			fileName:  "intro",
+			baseDir:   "/docs",
+			outputDir: "/output/{{.BaseDir}}/{{.FileName}}",
+			expected:  "/output/docs/intro",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf := MarkdownFile{
+				RelPath:  tt.relPath,
+				FileName: tt.fileName,
+				BaseDir:  tt.baseDir,
+				Lang:     tt.lang,
+			}
+
+			result, err := mf.ResolveOutputDir(tt.outputDir)
+			if err != nil {
+				t.Fatalf("ResolveOutputDir() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("ResolveOutputDir() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownFileResolveOutputDirErrors(t *testing.T) {
+	mf := MarkdownFile{RelPath: "intro.md", FileName: "intro", BaseDir: "/docs"}
+
+	if _, err := mf.ResolveOutputDir("/output/{{.RelDir"); err == nil {
+		t.Error("Expected error for malformed template, got nil")
+	}
+	if _, err := mf.ResolveOutputDir("/output/{{.Unknown}}"); err == nil {
+		t.Error("Expected error for unknown template variable, got nil")
+	}
+}
+
+func TestNewMarkdownFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "script.md")
+
+	mf, err := NewMarkdownFile(path)
+	if err != nil {
+		t.Fatalf("NewMarkdownFile() error = %v", err)
+	}
+
+	if mf.RelPath != "script.md" {
+		t.Errorf("RelPath = %q, want %q", mf.RelPath, "script.md")
+	}
+	if mf.FileName != "script" {
+		t.Errorf("FileName = %q, want %q", mf.FileName, "script")
+	}
+	if mf.BaseDir != tmpDir {
+		t.Errorf("BaseDir = %q, want %q", mf.BaseDir, tmpDir)
+	}
+}
+
+func TestOutputRoot(t *testing.T) {
+	tests := map[string]string{
+		"./audio_sections":                  "./audio_sections",
+		"./audio/{{.Parent}}_{{.FileName}}": "audio",
+		"./audio/{{.RelDir}}/{{.FileName}}": "audio",
+		"./audio/lesson-{{.FileName}}":      "audio",
+		"{{.FileName}}":                     ".",
+	}
+	for outputDir, want := range tests {
+		if got := OutputRoot(outputDir); got != want {
+			t.Errorf("OutputRoot(%q) = %q, want %q", outputDir, got, want)
+		}
+	}
+}
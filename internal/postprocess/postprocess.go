@@ -0,0 +1,81 @@
+// Package postprocess runs hooks on generated audio files before they are
+// finalized. Post-processors let library users and the CLI (via -post-cmd)
+// chain custom processing such as denoising or EQ onto every output.
+//
+// Key features:
+//   - Func hook type for in-process post-processors
+//   - Command post-processor running an external program on each file
+//   - Ordered application with errors naming the failing hook
+package postprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// File describes a generated audio file.
+type File struct {
+	Path     string // Audio file path; post-processors rewrite it in place
+	Title    string // Section title
+	Index    int    // Section index (1-based)
+	Text     string // Text spoken in the file
+	Provider string // TTS provider name
+	Voice    string // Voice used
+}
+
+// Func processes a generated audio file in place.
+type Func func(ctx context.Context, file File) error
+
+// Command returns a post-processor that runs command with the audio file path
+// as its last argument. The command line is split on whitespace (no shell
+// quoting) and must rewrite the file in place. The path and section metadata
+// are also passed in the MD2AUDIO_FILE, MD2AUDIO_SECTION_TITLE,
+// MD2AUDIO_SECTION_INDEX, MD2AUDIO_PROVIDER and MD2AUDIO_VOICE environment variables.
+func Command(command string) (Func, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("post-processing command is empty")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("post-processing command not found: %s", args[0])
+	}
+
+	return func(ctx context.Context, file File) error {
+		cmd := exec.CommandContext(ctx, args[0], append(args[1:], file.Path)...)
+		cmd.Env = append(os.Environ(),
+			"MD2AUDIO_FILE="+file.Path,
+			"MD2AUDIO_SECTION_TITLE="+file.Title,
+			"MD2AUDIO_SECTION_INDEX="+strconv.Itoa(file.Index),
+			"MD2AUDIO_PROVIDER="+file.Provider,
+			"MD2AUDIO_VOICE="+file.Voice,
+		)
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("post-processing command failed: %w\nOutput: %s", err, strings.TrimSpace(output.String()))
+		}
+		return nil
+	}, nil
+}
+
+// Apply runs the post-processors in order on a file and checks it is still there.
+func Apply(ctx context.Context, file File, hooks []Func) error {
+	for i, hook := range hooks {
+		if err := hook(ctx, file); err != nil {
+			return fmt.Errorf("post-processor %d: %w", i+1, err)
+		}
+	}
+	if len(hooks) > 0 {
+		if _, err := os.Stat(file.Path); err != nil {
+			return fmt.Errorf("post-processing removed %s", file.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package postprocess
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "section.wav")
+	if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := File{Path: path, Title: "Intro", Index: 1}
+
+	var calls []string
+	record := func(name string) Func {
+		return func(ctx context.Context, f File) error {
+			calls = append(calls, name+":"+f.Title)
+			return nil
+		}
+	}
+
+	if err := Apply(context.Background(), file, []Func{record("denoise"), record("eq")}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if strings.Join(calls, ",") != "denoise:Intro,eq:Intro" {
+		t.Errorf("calls = %v, want both hooks in order", calls)
+	}
+
+	failing := func(ctx context.Context, f File) error { return errors.New("clipped") }
+	if err := Apply(context.Background(), file, []Func{record("a"), failing}); err == nil || !strings.Contains(err.Error(), "post-processor 2: clipped") {
+		t.Errorf("Apply() error = %v, want the failing hook named", err)
+	}
+
+	remove := func(ctx context.Context, f File) error { return os.Remove(f.Path) }
+	if err := Apply(context.Background(), file, []Func{remove}); err == nil {
+		t.Error("expected an error when a hook removes the file")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "post.sh")
+	content := "#!/bin/sh\necho \"$MD2AUDIO_SECTION_INDEX $MD2AUDIO_SECTION_TITLE $MD2AUDIO_PROVIDER\" >> \"$1\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "section.wav")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hook, err := Command(script)
+	if err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	if err := hook(context.Background(), File{Path: path, Title: "Usage", Index: 3, Provider: "say"}); err != nil {
+		t.Fatalf("hook error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "3 Usage say" {
+		t.Errorf("file = %q, want %q", got, "3 Usage say")
+	}
+
+	fail, err := Command("false")
+	if err != nil {
+		t.Skip("false not available")
+	}
+	if err := fail(context.Background(), File{Path: path}); err == nil {
+		t.Error("expected error from failing command")
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	if _, err := Command("  "); err == nil {
+		t.Error("expected error for empty command")
+	}
+	if _, err := Command("md2audio-no-such-command --flag"); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// overTargetRatio is how much longer than its target duration a section may run
+// before the run's next steps suggest adjusting it
+const overTargetRatio = 1.15
+
+// maxListedFiles caps the files named in a next step
+const maxListedFiles = 3
+
+// runStats counts the outcomes of a run that call for follow-up
+type runStats struct {
+	emptyFiles []string // Markdown files without H2 sections
+	failed     int      // Sections that failed to generate
+	flagged    int      // Sections flagged by verification
+	skipped    int      // Sections not started after reaching the run budget
+	overTarget int      // Sections running more than overTargetRatio over their target duration
+	manifests  []string // Manifests holding entries to regenerate
+}
+
+// addEntry records the outcome of a section saved in the manifest at manifestPath
+func (s *runStats) addEntry(entry manifest.Entry, manifestPath string) {
+	switch entry.Status {
+	case manifest.StatusFailed:
+		s.failed++
+	case manifest.StatusFlagged:
+		s.flagged++
+	case manifest.StatusSkipped:
+		s.skipped++
+	default:
+		return
+	}
+	if !slices.Contains(s.manifests, manifestPath) {
+		s.manifests = append(s.manifests, manifestPath)
+	}
+}
+
+// addDuration records the audio duration of a generated section
+func (s *runStats) addDuration(section parser.Section, duration float64) {
+	if section.HasTiming && section.Duration > 0 && duration > section.Duration*overTargetRatio {
+		s.overTarget++
+	}
+}
+
+// nextSteps turns the run outcomes into follow-up suggestions
+func (s *runStats) nextSteps() []string {
+	var steps []string
+
+	if n := len(s.emptyFiles); n > 0 {
+		steps = append(steps, fmt.Sprintf("%d file(s) skipped: no H2 sections (%s); add '## ' headings to split them into sections", n, listFiles(s.emptyFiles)))
+	}
+	if s.failed > 0 {
+		steps = append(steps, fmt.Sprintf("%d section(s) failed: fix the errors above, then retry with -from-manifest %s -only failed", s.failed, s.manifestArg()))
+	}
+	if s.flagged > 0 {
+		steps = append(steps, fmt.Sprintf("%d section(s) flagged by verification: listen to them, then regenerate with -from-manifest %s -only flagged", s.flagged, s.manifestArg()))
+	}
+	if s.skipped > 0 {
+		steps = append(steps, fmt.Sprintf("%d section(s) skipped after reaching the run budget: resume with -from-manifest %s -only skipped", s.skipped, s.manifestArg()))
+	}
+	if s.overTarget > 0 {
+		steps = append(steps, fmt.Sprintf("%d section(s) exceeded their target duration by more than %.0f%%: shorten their text or lengthen their timing", s.overTarget, (overTargetRatio-1)*100))
+	}
+
+	return steps
+}
+
+// manifestArg returns the -from-manifest value for regenerating entries
+func (s *runStats) manifestArg() string {
+	if len(s.manifests) == 1 {
+		return s.manifests[0]
+	}
+	return filepath.Join("<output>", manifest.FileName)
+}
+
+// listFiles names the first maxListedFiles files of a list
+func listFiles(files []string) string {
+	if len(files) <= maxListedFiles {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s, and %d more", strings.Join(files[:maxListedFiles], ", "), len(files)-maxListedFiles)
+}
+
+// logNextSteps prints the follow-up suggestions of a run, if any
+func logNextSteps(stats *runStats, log logger.LoggerInterface) {
+	steps := stats.nextSteps()
+	if len(steps) == 0 {
+		return
+	}
+
+	log.Blank()
+	log.Info("Next steps:")
+	log.WithIndent(true)
+	for _, step := range steps {
+		log.Hint(step)
+	}
+	log.WithIndent(false)
+}
+
+// displayPath returns markdownFile relative to the input directory when possible
+func displayPath(markdownFile string, cfg config.Config) string {
+	if cfg.InputDir == "" {
+		return markdownFile
+	}
+	inputDir, err := filepath.Abs(cfg.InputDir)
+	if err != nil {
+		return markdownFile
+	}
+	if rel, err := filepath.Rel(inputDir, markdownFile); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return markdownFile
+}
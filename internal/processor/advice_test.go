@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestRunStatsNextSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats runStats
+		want  []string
+	}{
+		{
+			name:  "clean run",
+			stats: runStats{},
+			want:  nil,
+		},
+		{
+			name:  "files without sections",
+			stats: runStats{emptyFiles: []string{"a.md", "b.md", "c.md", "d.md"}},
+			want:  []string{"4 file(s) skipped: no H2 sections (a.md, b.md, c.md, and 1 more); add '## ' headings to split them into sections"},
+		},
+		{
+			name:  "failed sections in one manifest",
+			stats: runStats{failed: 2, manifests: []string{"out/manifest.json"}},
+			want:  []string{"2 section(s) failed: fix the errors above, then retry with -from-manifest out/manifest.json -only failed"},
+		},
+		{
+			name: "flagged and skipped sections in several manifests",
+			stats: runStats{
+				flagged:   1,
+				skipped:   3,
+				manifests: []string{"out/a/manifest.json", "out/b/manifest.json"},
+			},
+			want: []string{
+				"1 section(s) flagged by verification: listen to them, then regenerate with -from-manifest " + filepath.Join("<output>", "manifest.json") + " -only flagged",
+				"3 section(s) skipped after reaching the run budget: resume with -from-manifest " + filepath.Join("<output>", "manifest.json") + " -only skipped",
+			},
+		},
+		{
+			name:  "sections over target duration",
+			stats: runStats{overTarget: 3},
+			want:  []string{"3 section(s) exceeded their target duration by more than 15%: shorten their text or lengthen their timing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stats.nextSteps()
+			if len(got) != len(tt.want) {
+				t.Fatalf("nextSteps() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("nextSteps()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunStatsAdd(t *testing.T) {
+	var stats runStats
+	stats.addEntry(manifest.Entry{Status: manifest.StatusOK}, "a/manifest.json")
+	stats.addEntry(manifest.Entry{Status: manifest.StatusFailed}, "a/manifest.json")
+	stats.addEntry(manifest.Entry{Status: manifest.StatusFailed}, "a/manifest.json")
+	stats.addEntry(manifest.Entry{Status: manifest.StatusSkipped}, "b/manifest.json")
+
+	if stats.failed != 2 || stats.skipped != 1 || stats.flagged != 0 {
+		t.Errorf("Unexpected counts: failed=%d skipped=%d flagged=%d", stats.failed, stats.skipped, stats.flagged)
+	}
+	if len(stats.manifests) != 2 {
+		t.Errorf("Expected 2 manifests, got %q", stats.manifests)
+	}
+
+	timed := parser.Section{HasTiming: true, Duration: 10}
+	stats.addDuration(timed, 11.5)
+	stats.addDuration(timed, 11.6)
+	stats.addDuration(parser.Section{}, 30)
+	if stats.overTarget != 1 {
+		t.Errorf("overTarget = %d, want 1", stats.overTarget)
+	}
+}
+
+func TestProcessDirectoryNextSteps(t *testing.T) {
+	inputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(inputDir, "notes.md"), []byte("# Notes\n\nNo sections here.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: t.TempDir(),
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format: "mp3",
+		Prefix: "section",
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, log); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+	if !strings.Contains(output, "Next steps:") || !strings.Contains(output, "1 file(s) skipped: no H2 sections (notes.md)") {
+		t.Errorf("Expected next steps for the file without sections, got output:\n%s", output)
+	}
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+)
+
+// maxListedTags is the number of distinct audio tags named in a warning
+const maxListedTags = 5
+
+// checkAudioTags warns when sections contain audio tags such as [whispers]
+// that their provider or ElevenLabs model would read aloud instead of
+// performing them
+func checkAudioTags(sections []parser.Section, cfg config.Config, log logger.LoggerInterface) {
+	if cfg.SilenceOnly {
+		return
+	}
+
+	type unsupported struct {
+		sections int
+		tags     []string
+	}
+	var readers []string
+	found := make(map[string]*unsupported)
+	for _, section := range sections {
+		tags := text.AudioTags(section.Content)
+		if len(tags) == 0 {
+			continue
+		}
+		provider := cmp.Or(section.Provider, cfg.Provider)
+		if provider == "elevenlabs" && elevenlabs.SupportsAudioTags(cfg.ElevenLabs.Model) {
+			continue
+		}
+
+		reader := "the " + provider + " provider"
+		if provider == "elevenlabs" {
+			reader = cmp.Or(cfg.ElevenLabs.Model, elevenlabs.DefaultModel)
+		}
+		u, ok := found[reader]
+		if !ok {
+			u = &unsupported{}
+			found[reader] = u
+			readers = append(readers, reader)
+		}
+		u.sections++
+		for _, tag := range tags {
+			if !slices.Contains(u.tags, tag) {
+				u.tags = append(u.tags, tag)
+			}
+		}
+	}
+
+	for _, reader := range readers {
+		u := found[reader]
+		tags := u.tags
+		if len(tags) > maxListedTags {
+			tags = append(tags[:maxListedTags:maxListedTags], "...")
+		}
+		log.Warning(fmt.Sprintf("%d section(s) have audio tags (%s) that %s reads aloud", u.sections, strings.Join(tags, " "), reader))
+		log.Hint("Audio tags are performed by ElevenLabs v3 models only: use -provider elevenlabs -elevenlabs-model eleven_v3")
+	}
+}
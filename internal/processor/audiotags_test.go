@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestCheckAudioTags(t *testing.T) {
+	sections := []parser.Section{
+		{Title: "Intro", Content: "[whispers] Welcome. [laughs]"},
+		{Title: "Setup", Content: "Run the [installer](https://example.com)."},
+		{Title: "Outro", Content: "[sighs] That's all.", Provider: "say"},
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{
+			name: "v3 model performs the tags",
+			cfg:  config.Config{Provider: "elevenlabs", ElevenLabs: config.ElevenLabsConfig{Model: "eleven_v3"}},
+			want: []string{"1 section(s) have audio tags ([sighs]) that the say provider reads aloud"},
+		},
+		{
+			name: "older model reads them aloud",
+			cfg:  config.Config{Provider: "elevenlabs", ElevenLabs: config.ElevenLabsConfig{Model: "eleven_multilingual_v2"}},
+			want: []string{
+				"1 section(s) have audio tags ([whispers] [laughs]) that eleven_multilingual_v2 reads aloud",
+				"1 section(s) have audio tags ([sighs]) that the say provider reads aloud",
+			},
+		},
+		{
+			name: "local provider",
+			cfg:  config.Config{Provider: "espeak"},
+			want: []string{"1 section(s) have audio tags ([whispers] [laughs]) that the espeak provider reads aloud"},
+		},
+		{
+			name: "silent scaffolds",
+			cfg:  config.Config{Provider: "say", SilenceOnly: true},
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := testhelpers.CaptureStdout(func() {
+				checkAudioTags(sections, tt.cfg, log)
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output = %q, want %q", output, want)
+				}
+			}
+			if len(tt.want) == 0 && output != "" {
+				t.Errorf("output = %q, want no warning", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// budgetSpent returns why the -max-duration or -max-api-calls budget of the run
+// is spent (empty while within budget), logging a warning the first time.
+func (rs *runState) budgetSpent(cfg config.Config, log logger.LoggerInterface) string {
+	if rs.stopReason != "" {
+		return rs.stopReason
+	}
+
+	switch {
+	case !rs.deadline.IsZero() && !time.Now().Before(rs.deadline):
+		rs.stopReason = fmt.Sprintf("time budget of %s reached", cfg.MaxDuration)
+	case cfg.MaxAPICalls > 0 && rs.apiCalls >= cfg.MaxAPICalls:
+		rs.stopReason = fmt.Sprintf("API call budget of %d reached", cfg.MaxAPICalls)
+	default:
+		return ""
+	}
+
+	log.Blank()
+	log.Warning(fmt.Sprintf("%s%s, not starting new sections", strings.ToUpper(rs.stopReason[:1]), rs.stopReason[1:]))
+	return rs.stopReason
+}
+
+// skippedEntry completes a manifest entry for a section not started because the run budget was spent
+func skippedEntry(entry manifest.Entry, plannedPath, reason string) manifest.Entry {
+	entry.Output = plannedPath
+	entry.Status = manifest.StatusSkipped
+	entry.Reason = reason
+	return entry
+}
+
+// recordSkippedFile records the selected sections of a file not started because the
+// run budget was spent, so the run can be resumed with -from-manifest.
+func recordSkippedFile(markdownFile, outputDir, reason string, cfg config.Config, log logger.LoggerInterface, rs *runState) error {
+	sections, err := parseMarkdownFile(markdownFile, cfg)
+	if err != nil {
+		return fmt.Errorf("error parsing markdown: %w", err)
+	}
+	sections = rs.selectSections(markdownFile, sections, cfg)
+	if len(sections) == 0 {
+		return nil
+	}
+	if cfg.Granularity == config.GranularitySentence {
+		sections = splitSentences(sections)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	manifestPath := manifest.PathFor(outputDir)
+	m, err := manifest.LoadOrNew(manifestPath)
+	if err != nil {
+		m = manifest.New()
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+	if rs.order != "" {
+		m.Order = rs.order
+	}
+
+	// Only output paths are needed, so no provider is configured
+	cfg.StartIndex = resolveStartIndex(markdownFile, outputDir, cfg)
+	paths := outputPaths(cfg, outputDir, log)
+	if rs.summary != nil {
+		rs.summary.AddFile()
+	}
+	for _, section := range sections {
+		entry := newEntry(sourcePath, rs.positions[markdownFile], section, paths)
+		entry = skippedEntry(entry, paths.OutputBase(section, section.Index)+"."+cfg.OutputFormat(), reason)
+		m.Put(entry)
+		rs.addEntry(entry, manifestPath, 0)
+	}
+
+	return m.Save(manifestPath)
+}
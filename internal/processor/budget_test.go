@@ -0,0 +1,140 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestRunStateBudgetSpent(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		apiCalls int
+		expired  bool
+		want     string
+	}{
+		{name: "unlimited", cfg: config.Config{}, apiCalls: 100},
+		{name: "within time budget", cfg: config.Config{MaxDuration: time.Hour}},
+		{name: "time budget spent", cfg: config.Config{MaxDuration: time.Hour}, expired: true, want: "time budget of 1h0m0s reached"},
+		{name: "within API call budget", cfg: config.Config{MaxAPICalls: 3}, apiCalls: 2},
+		{name: "API call budget spent", cfg: config.Config{MaxAPICalls: 3}, apiCalls: 3, want: "API call budget of 3 reached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newRunState(tt.cfg)
+			rs.apiCalls = tt.apiCalls
+			if tt.expired {
+				rs.deadline = time.Now().Add(-time.Second)
+			}
+
+			var got string
+			_, err := testhelpers.CaptureStdout(func() {
+				got = rs.budgetSpent(tt.cfg, log)
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("budgetSpent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunStateBudgetSpentWarnsOnce(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	cfg := config.Config{MaxAPICalls: 1}
+	rs := newRunState(cfg)
+	rs.apiCalls = 1
+
+	output, err := testhelpers.CaptureStdout(func() {
+		for range 2 {
+			if rs.budgetSpent(cfg, log) == "" {
+				t.Error("Expected the budget to be spent")
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+	if got := strings.Count(output, "API call budget of 1 reached, not starting new sections"); got != 1 {
+		t.Errorf("Expected one budget warning, got %d in output:\n%s", got, output)
+	}
+}
+
+func TestProcessDirectoryBudgetRecordsSkipped(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		content := "## One\n\nFirst.\n\n## Two\n\nSecond.\n"
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format:        "mp3",
+		Prefix:        "section",
+		MaxAPICalls:   5,
+		LimitSections: 1,
+	}
+
+	// Budget already spent, so no provider requests are made
+	rs := newRunState(cfg)
+	rs.apiCalls = cfg.MaxAPICalls
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := processDirectory(cfg, log, rs); err != nil {
+			t.Errorf("processDirectory() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+	if !strings.Contains(output, "Skipped 2 file(s) after reaching the run budget") {
+		t.Errorf("Expected skipped files message, got output:\n%s", output)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		m, err := manifest.Load(manifest.PathFor(filepath.Join(outputDir, name)))
+		if err != nil {
+			t.Fatalf("Failed to load manifest: %v", err)
+		}
+		if len(m.Entries) != 1 {
+			t.Fatalf("Expected 1 entry for %s, got %d", name, len(m.Entries))
+		}
+		entry := m.Entries[0]
+		if entry.Status != manifest.StatusSkipped || entry.Reason != "API call budget of 5 reached" {
+			t.Errorf("Unexpected entry: %+v", entry)
+		}
+		if want := filepath.Join(outputDir, name, "section_01_one.mp3"); entry.Output != want {
+			t.Errorf("Output = %q, want %q", entry.Output, want)
+		}
+	}
+}
+
+func TestSkippedEntry(t *testing.T) {
+	entry := skippedEntry(manifest.Entry{Index: 2, Title: "Setup"}, "out/section_02_setup.mp3", "time budget of 30m0s reached")
+	if entry.Status != manifest.StatusSkipped || entry.Output != "out/section_02_setup.mp3" || entry.Reason != "time budget of 30m0s reached" {
+		t.Errorf("skippedEntry() = %+v", entry)
+	}
+}
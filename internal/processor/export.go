@@ -0,0 +1,174 @@
+package processor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// ExportedSection is a parsed section as written by -export-sections
+type ExportedSection struct {
+	File              string  `json:"file"`
+	Index             int     `json:"index"`
+	Sentence          int     `json:"sentence,omitempty"`
+	Title             string  `json:"title"`
+	Text              string  `json:"text"`
+	HasTiming         bool    `json:"has_timing"`
+	TargetDuration    float64 `json:"target_duration,omitempty"`
+	Words             int     `json:"words"`
+	EstimatedDuration float64 `json:"estimated_duration"`
+}
+
+// exportColumns are the CSV columns written by -export-sections
+var exportColumns = []string{"file", "index", "sentence", "title", "text", "has_timing", "target_duration", "words", "estimated_duration"}
+
+// ExportSections parses the markdown input (-f or -d) and writes its sections,
+// cleaned and transformed as they would be spoken, to the -export-sections
+// file as JSON, or CSV for .csv files. No audio is generated.
+func ExportSections(cfg config.Config, log logger.LoggerInterface) error {
+	files, err := exportFiles(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	var exported []ExportedSection
+	for _, file := range files {
+		sections, err := exportedSections(file, cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.RelPath, err)
+		}
+		exported = append(exported, sections...)
+	}
+
+	if err := writeExportedSections(cfg.Commands.ExportSections, exported); err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("Exported %d section(s) from %d file(s) to %s", len(exported), len(files), cfg.Commands.ExportSections))
+	return nil
+}
+
+// exportFiles returns the markdown files of the run in processing order
+func exportFiles(cfg config.Config, log logger.LoggerInterface) ([]parser.MarkdownFile, error) {
+	if !cfg.IsDirectoryMode() {
+		if cfg.MarkdownFile == "" {
+			return nil, fmt.Errorf("-export-sections requires a markdown file (-f) or directory (-d)")
+		}
+		return []parser.MarkdownFile{{AbsPath: cfg.MarkdownFile, RelPath: filepath.Base(cfg.MarkdownFile)}}, nil
+	}
+
+	files, err := parser.FindMarkdownFiles(cfg.InputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+	files = parser.MapExport(files, cfg.InputFormat)
+	if _, err := sortMarkdownFiles(files, cfg, log); err != nil {
+		return nil, err
+	}
+	if cfg.Limit > 0 && cfg.Limit < len(files) {
+		files = files[:cfg.Limit]
+	}
+	return files, nil
+}
+
+// exportedSections parses a markdown file and prepares its sections as a run
+// would, applying -limit-sections, transforms, -redact and -granularity
+func exportedSections(file parser.MarkdownFile, cfg config.Config) ([]ExportedSection, error) {
+	sections, err := parseMarkdownFile(file.AbsPath, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing markdown: %w", err)
+	}
+	sections = newRunState(cfg).selectSections(file.AbsPath, sections, cfg)
+	if sections, err = transformSections(sections, cfg); err != nil {
+		return nil, err
+	}
+	sections, _ = redactSections(sections, file.AbsPath, cfg)
+	if cfg.Granularity == config.GranularitySentence {
+		sections = splitSentences(sections)
+	}
+
+	exported := make([]ExportedSection, len(sections))
+	for i, section := range sections {
+		exported[i] = ExportedSection{
+			File:              filepath.ToSlash(file.RelPath),
+			Index:             section.Index,
+			Sentence:          section.Sentence,
+			Title:             section.Title,
+			Text:              section.Content,
+			HasTiming:         section.HasTiming,
+			TargetDuration:    section.Duration,
+			Words:             utils.CountWords(section.Content),
+			EstimatedDuration: math.Round(audio.EstimateSectionDuration(section, cfg.Provider, cfg.Say.Rate)*100) / 100,
+		}
+	}
+	return exported, nil
+}
+
+// writeExportedSections writes sections to path, as CSV for .csv files and JSON otherwise
+func writeExportedSections(path string, sections []ExportedSection) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create section export: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = writeSectionsCSV(file, sections)
+	} else {
+		err = writeSectionsJSON(file, sections)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeSectionsJSON writes sections as an indented JSON array
+func writeSectionsJSON(w io.Writer, sections []ExportedSection) error {
+	if sections == nil {
+		sections = []ExportedSection{}
+	}
+	data, err := json.MarshalIndent(sections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sections: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// writeSectionsCSV writes sections as CSV with a header row
+func writeSectionsCSV(w io.Writer, sections []ExportedSection) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return err
+	}
+	for _, s := range sections {
+		record := []string{
+			s.File,
+			strconv.Itoa(s.Index),
+			strconv.Itoa(s.Sentence),
+			s.Title,
+			s.Text,
+			strconv.FormatBool(s.HasTiming),
+			formatFloat(s.TargetDuration),
+			strconv.Itoa(s.Words),
+			formatFloat(s.EstimatedDuration),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+func TestExportSections(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	content := "## Intro (2s)\n\nHello **world**.\n\n## Body\n\nThe main part. Second sentence.\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{MarkdownFile: mdFile, Provider: "say", Say: config.SayConfig{Rate: 180}}
+
+	t.Run("json", func(t *testing.T) {
+		cfg := cfg
+		cfg.Commands.ExportSections = filepath.Join(tmpDir, "sections.json")
+		if err := ExportSections(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Fatalf("ExportSections() error = %v", err)
+		}
+
+		data, err := os.ReadFile(cfg.Commands.ExportSections)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sections []ExportedSection
+		if err := json.Unmarshal(data, &sections); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if len(sections) != 2 {
+			t.Fatalf("got %d sections, want 2", len(sections))
+		}
+
+		intro := sections[0]
+		if intro.File != "video.md" || intro.Index != 1 || intro.Title != "Intro" || intro.Text != "Hello world." {
+			t.Errorf("unexpected intro section: %+v", intro)
+		}
+		if !intro.HasTiming || intro.TargetDuration != 2 || intro.EstimatedDuration != 2 {
+			t.Errorf("intro timing = (%v, %v, %v), want target 2s", intro.HasTiming, intro.TargetDuration, intro.EstimatedDuration)
+		}
+		body := sections[1]
+		if body.HasTiming || body.Words != 5 || body.EstimatedDuration <= 0 {
+			t.Errorf("unexpected body section: %+v", body)
+		}
+	})
+
+	t.Run("csv sentences", func(t *testing.T) {
+		cfg := cfg
+		cfg.Granularity = config.GranularitySentence
+		cfg.Commands.ExportSections = filepath.Join(tmpDir, "sections.csv")
+		if err := ExportSections(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Fatalf("ExportSections() error = %v", err)
+		}
+
+		file, err := os.Open(cfg.Commands.ExportSections)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = file.Close() }()
+		records, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatalf("invalid CSV: %v", err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("got %d rows, want header and 3 sentences", len(records))
+		}
+		if records[0][0] != "file" || records[3][2] != "2" || records[3][4] != "Second sentence." {
+			t.Errorf("unexpected rows: %v", records)
+		}
+	})
+
+	t.Run("requires input", func(t *testing.T) {
+		err := ExportSections(config.Config{Commands: config.CommandFlags{ExportSections: filepath.Join(tmpDir, "x.json")}}, logger.NewDefaultLogger())
+		if err == nil {
+			t.Error("expected an error without -f or -d")
+		}
+	})
+}
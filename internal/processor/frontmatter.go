@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// applyFrontMatter returns the output directory and configuration of a
+// markdown file with the overrides of its YAML front matter applied
+func applyFrontMatter(markdownFile, outputDir string, cfg config.Config, log logger.LoggerInterface) (string, config.Config, error) {
+	fm, err := parser.LoadFrontMatter(markdownFile)
+	if err != nil {
+		return "", cfg, err
+	}
+	if fm.IsZero() {
+		return outputDir, cfg, nil
+	}
+
+	if fm.Provider != "" && fm.Provider != cfg.Provider {
+		cfg = withProvider(cfg, fm.Provider)
+	}
+	if fm.Voice != "" {
+		if cfg.Provider == "elevenlabs" {
+			cfg.ElevenLabs.VoiceID = fm.Voice
+		} else {
+			cfg.Say.Voice = fm.Voice
+		}
+	}
+	if fm.Rate > 0 {
+		cfg.Say.Rate = fm.Rate
+	}
+	if fm.Format != "" {
+		cfg.Format = fm.Format
+	}
+	if fm.Prefix != "" {
+		cfg.Prefix = fm.Prefix
+	}
+	if err := cfg.Validate(); err != nil {
+		return "", cfg, fmt.Errorf("front matter: %w", err)
+	}
+
+	if fm.OutputDir != "" {
+		if outputDir, err = frontMatterOutputDir(fm.OutputDir, cfg); err != nil {
+			return "", cfg, err
+		}
+	}
+
+	log.Hint("Front matter: " + describeFrontMatter(fm))
+	return outputDir, cfg, nil
+}
+
+// fileOutputDir returns the output directory of a markdown file, moved by
+// its front matter. Front matter errors are reported when processing the file.
+func fileOutputDir(markdownFile, outputDir string, cfg config.Config) string {
+	fm, err := parser.LoadFrontMatter(markdownFile)
+	if err != nil || fm.OutputDir == "" {
+		return outputDir
+	}
+	dir, err := frontMatterOutputDir(fm.OutputDir, cfg)
+	if err != nil {
+		return outputDir
+	}
+	return dir
+}
+
+// frontMatterOutputDir resolves a front matter output directory, which must
+// stay inside the root of -o so documents cannot write elsewhere
+func frontMatterOutputDir(value string, cfg config.Config) (string, error) {
+	dir := filepath.Clean(filepath.FromSlash(value))
+	if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("front matter: output %q must be a directory inside the output directory (-o)", value)
+	}
+	return filepath.Join(parser.OutputRoot(cfg.OutputDir), dir), nil
+}
+
+// describeFrontMatter lists the settings overridden by front matter
+func describeFrontMatter(fm parser.FrontMatter) string {
+	var settings []string
+	add := func(name, value string) {
+		if value != "" {
+			settings = append(settings, name+"="+value)
+		}
+	}
+	add("provider", fm.Provider)
+	add("voice", fm.Voice)
+	if fm.Rate > 0 {
+		add("rate", strconv.Itoa(fm.Rate))
+	}
+	add("format", fm.Format)
+	add("prefix", fm.Prefix)
+	add("output", fm.OutputDir)
+	return strings.Join(settings, ", ")
+}
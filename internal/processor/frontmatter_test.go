@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestApplyFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontMatter string
+		wantDir     string
+		wantErr     bool
+		check       func(t *testing.T, cfg config.Config)
+	}{
+		{
+			name:    "no front matter",
+			wantDir: "out/docs",
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Provider != "say" || cfg.Say.Voice != "Kate" {
+					t.Errorf("config changed without front matter: %+v", cfg)
+				}
+			},
+		},
+		{
+			name:        "say settings",
+			frontMatter: "voice: Samantha\nrate: 200\nformat: m4a\nprefix: lesson\n",
+			wantDir:     "out/docs",
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Say.Voice != "Samantha" || cfg.Say.Rate != 200 || cfg.Format != "m4a" || cfg.Prefix != "lesson" {
+					t.Errorf("front matter not applied: %+v", cfg)
+				}
+			},
+		},
+		{
+			name:        "provider switch",
+			frontMatter: "provider: elevenlabs\nvoice: abc123\n",
+			wantDir:     "out/docs",
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Provider != "elevenlabs" || cfg.ElevenLabs.VoiceID != "abc123" || cfg.Say.Voice != "" {
+					t.Errorf("provider not switched: %+v", cfg)
+				}
+			},
+		},
+		{
+			name:        "output directory",
+			frontMatter: "output: course/week1\n",
+			wantDir:     filepath.Join("out", "course", "week1"),
+		},
+		{
+			name:        "output outside -o",
+			frontMatter: "output: ../elsewhere\n",
+			wantErr:     true,
+		},
+		{
+			name:        "absolute output",
+			frontMatter: "output: /tmp/audio\n",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown provider",
+			frontMatter: "provider: nope\n",
+			wantErr:     true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "## Intro\nHello.\n"
+			if tt.frontMatter != "" {
+				content = "---\n" + tt.frontMatter + "---\n" + content
+			}
+			mdFile := filepath.Join(t.TempDir(), "doc.md")
+			if err := os.WriteFile(mdFile, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.Config{
+				Provider:     "say",
+				MarkdownFile: mdFile,
+				OutputDir:    "out",
+				Say:          config.SayConfig{Voice: "Kate", Rate: 170},
+			}
+
+			var (
+				dir string
+				got config.Config
+				err error
+			)
+			testhelpers.CaptureStdout(func() {
+				dir, got, err = applyFrontMatter(mdFile, "out/docs", cfg, log)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyFrontMatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dir != tt.wantDir {
+				t.Errorf("applyFrontMatter() dir = %q, want %q", dir, tt.wantDir)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/history"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/progress"
+)
+
+// Run modes recorded in the run history
+const (
+	modeFile      = "file"
+	modeDirectory = "directory"
+	modeLanguages = "languages"
+	modeManifest  = "manifest"
+)
+
+// recordsHistory reports whether runs are recorded in the run history
+func recordsHistory(cfg config.Config) bool {
+	return cfg.HistoryDB != "" && !cfg.NoHistory
+}
+
+// finishRun prints the next steps, writes the written and spoken run summaries,
+// reports the completed run, and records it in the history
+func finishRun(cfg config.Config, rs *runState, mode, input string, log logger.LoggerInterface) {
+	logNextSteps(&rs.stats, log)
+	writeRunSummary(cfg, rs.summary, log)
+	writeSpokenSummary(cfg, rs.summary, log)
+	if rs.summary != nil {
+		rs.emit(progress.Event{Kind: progress.RunCompleted, Summary: rs.summary})
+	}
+	recordRun(cfg, rs, mode, input, log)
+}
+
+// recordRun stores the outcome of a run in the run history.
+// Failures are logged and do not fail the run.
+func recordRun(cfg config.Config, rs *runState, mode, input string, log logger.LoggerInterface) {
+	if rs.summary == nil || !recordsHistory(cfg) {
+		return
+	}
+
+	store, err := history.Open(cfg.HistoryDB)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Could not record run history: %v", err))
+		return
+	}
+	defer func() { _ = store.Close() }()
+
+	if _, err := store.Record(context.Background(), newHistoryRun(cfg, rs, mode, input)); err != nil {
+		log.Warning(fmt.Sprintf("Could not record run history: %v", err))
+		return
+	}
+	log.Debug("Run recorded in history: " + cfg.HistoryDB)
+}
+
+// newHistoryRun builds the history record of a finished run
+func newHistoryRun(cfg config.Config, rs *runState, mode, input string) history.Run {
+	sum := rs.summary
+
+	settings := map[string]string{"format": cfg.OutputFormat()}
+	switch cfg.Provider {
+	case "elevenlabs":
+		settings["voice"] = cfg.ElevenLabs.VoiceID
+		settings["model"] = cfg.ElevenLabs.Model
+	case "coqui":
+		settings["voice"] = cfg.Say.Voice
+		settings["language"] = cfg.Coqui.Language
+	case "marytts":
+		settings["voice"] = cfg.Say.Voice
+		settings["locale"] = cfg.MaryTTS.Locale
+	case "watson":
+		settings["voice"] = cfg.Say.Voice
+	case "playht":
+		settings["voice"] = cfg.Say.Voice
+		settings["speed"] = strconv.FormatFloat(cfg.PlayHT.Speed, 'f', -1, 64)
+		if cfg.PlayHT.Emotion != "" {
+			settings["emotion"] = cfg.PlayHT.Emotion
+		}
+	case "edge":
+		settings["voice"] = cfg.Say.Voice
+		settings["rate"] = cfg.Edge.Rate
+		settings["pitch"] = cfg.Edge.Pitch
+	default:
+		settings["voice"] = cfg.Say.Voice
+		settings["rate"] = strconv.Itoa(cfg.Say.Rate)
+	}
+	if cfg.Tenant != "" {
+		settings["tenant"] = cfg.Tenant
+	}
+
+	return history.Run{
+		StartedAt:    sum.StartedAt,
+		Elapsed:      sum.Elapsed,
+		Provider:     cfg.Provider,
+		Mode:         mode,
+		Input:        input,
+		Output:       cfg.OutputDir,
+		Settings:     settings,
+		Files:        sum.Files,
+		Sections:     sum.Sections,
+		Generated:    sum.Generated,
+		Failed:       sum.Failed,
+		Flagged:      sum.Flagged,
+		Skipped:      sum.Skipped,
+		AudioSeconds: sum.AudioSeconds,
+		Characters:   rs.characters,
+	}
+}
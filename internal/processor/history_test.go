@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/history"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestRecordRun(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "guide.md")
+	if err := os.WriteFile(mdFile, []byte("## One\n\nFirst.\n\n## Two\n\nSecond.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{
+		MarkdownFile: mdFile,
+		OutputDir:    filepath.Join(dir, "audio"),
+		Provider:     "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "voice-1",
+			Model:   "eleven_multilingual_v2",
+		},
+		Format:      "mp3",
+		Prefix:      "section",
+		MaxAPICalls: 1,
+		HistoryDB:   filepath.Join(dir, "history.db"),
+	}
+
+	// Budget already spent, so no provider requests are made
+	rs := newRunState(cfg)
+	rs.apiCalls = cfg.MaxAPICalls
+
+	log := logger.NewDefaultLogger()
+	if _, err := testhelpers.CaptureStdout(func() {
+		if _, _, err := processSingleFile(mdFile, cfg.OutputDir, cfg, log, rs); err != nil {
+			t.Errorf("processSingleFile() error = %v", err)
+		}
+		finishRun(cfg, rs, modeFile, mdFile, log)
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	store, err := history.Open(cfg.HistoryDB)
+	if err != nil {
+		t.Fatalf("history.Open() error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	runs, err := store.Recent(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("Expected 1 recorded run, got %d", len(runs))
+	}
+	run := runs[0]
+	if run.Mode != modeFile || run.Input != mdFile || run.Provider != "elevenlabs" || run.Sections != 2 || run.Skipped != 2 {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+	if run.Settings["voice"] != "voice-1" || run.Settings["format"] != "mp3" {
+		t.Errorf("Unexpected settings: %v", run.Settings)
+	}
+}
+
+func TestRecordRunDisabled(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "history.db")
+	log := logger.NewDefaultLogger()
+
+	for _, cfg := range []config.Config{
+		{Provider: "say", HistoryDB: dbPath, NoHistory: true},
+		{Provider: "say", HistoryDB: dbPath, Commands: config.CommandFlags{DryRun: true}},
+		{Provider: "say"},
+	} {
+		rs := newRunState(cfg)
+		finishRun(cfg, rs, modeFile, "guide.md", log)
+	}
+
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no history database, stat error = %v", err)
+	}
+}
+
+func TestNewHistoryRunSettings(t *testing.T) {
+	cfg := config.Config{Provider: "say", Format: "m4a", Say: config.SayConfig{Voice: "Kate", Rate: 180}}
+	rs := newRunState(config.Config{Summary: config.SummaryConfig{Path: "summary.md"}})
+	rs.characters = 42
+
+	run := newHistoryRun(cfg, rs, modeDirectory, "./docs")
+	if run.Settings["voice"] != "Kate" || run.Settings["rate"] != "180" || run.Settings["format"] != "m4a" {
+		t.Errorf("Unexpected settings: %v", run.Settings)
+	}
+	if run.Characters != 42 || run.Mode != modeDirectory || run.Input != "./docs" {
+		t.Errorf("Unexpected run: %+v", run)
+	}
+}
+
+func TestNewHistoryRunTenant(t *testing.T) {
+	cfg := config.Config{Provider: "elevenlabs", Format: "mp3", Tenant: "docs"}
+	rs := newRunState(config.Config{Summary: config.SummaryConfig{Path: "summary.md"}})
+
+	run := newHistoryRun(cfg, rs, modeWebhook, "./repo")
+	if run.Settings["tenant"] != "docs" {
+		t.Errorf("Expected the tenant in the settings, got %v", run.Settings)
+	}
+}
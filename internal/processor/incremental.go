@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/indaco/md2audio/internal/align"
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/splice"
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/timing"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// updateIncrementally reuses the existing audio of a section with -incremental.
+// Unchanged sections are kept as they are; edited sections have only their changed
+// sentences re-synthesized and spliced into the existing audio. It returns false
+// when the section has to be generated in full.
+func updateIncrementally(generator *audio.Generator, section parser.Section, m *manifest.Manifest, plannedPath string, rs *runState, log logger.LoggerInterface) (audio.Result, bool) {
+	entry, ok := m.Get(plannedPath)
+	if !ok || entry.Status != manifest.StatusOK || entry.Text == "" {
+		return audio.Result{}, false
+	}
+	if _, err := os.Stat(entry.Output); err != nil {
+		return audio.Result{}, false
+	}
+
+	log.WithIndent(true)
+	defer log.WithIndent(false)
+
+	duration, err := utils.GetAudioDuration(entry.Output)
+	if entry.Text == section.Content {
+		if err != nil {
+			duration = generator.EstimateDuration(section)
+		}
+		log.Faint("Unchanged, keeping " + entry.Output)
+		return audio.Result{OutputPath: entry.Output, Duration: duration}, true
+	}
+	if err != nil {
+		log.Debug(fmt.Sprintf("Cannot splice %s, regenerating the section: %v", entry.Output, err))
+		return audio.Result{}, false
+	}
+
+	oldSentences := text.SplitSentences(entry.Text)
+	newSentences := text.SplitSentences(section.Content)
+	ops := splice.Diff(oldSentences, newSentences)
+	spans := sentenceTimings(entry.Output, entry.Text, oldSentences, duration)
+	if !splice.Kept(ops) || spans == nil {
+		return audio.Result{}, false
+	}
+
+	tmpDir, err := os.MkdirTemp(filepath.Dir(entry.Output), ".splice-")
+	if err != nil {
+		log.Warning(fmt.Sprintf("Could not splice changed sentences, regenerating the section: %v", err))
+		return audio.Result{}, false
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	var segments []splice.Segment
+	for i, op := range ops {
+		if op.Keep {
+			segments = append(segments, splice.Segment{Path: entry.Output, Start: splice.Cut(spans, op.OldStart), End: splice.Cut(spans, op.OldEnd)})
+			continue
+		}
+		if len(op.New) == 0 {
+			continue
+		}
+
+		changed := strings.Join(op.New, " ")
+		rs.apiCalls++
+		rs.characters += utf8.RuneCountInString(changed)
+		path, err := generator.Synthesize(changed, filepath.Join(tmpDir, fmt.Sprintf("part_%02d", i)))
+		if err != nil {
+			log.Warning(fmt.Sprintf("Could not synthesize changed sentences, regenerating the section: %v", err))
+			return audio.Result{}, false
+		}
+		segments = append(segments, splice.Segment{Path: path, End: -1})
+	}
+
+	if err := splice.Join(context.Background(), entry.Output, segments, splice.DefaultCrossfade); err != nil {
+		log.Warning(fmt.Sprintf("Could not splice changed sentences, regenerating the section: %v", err))
+		return audio.Result{}, false
+	}
+
+	// Aligned timings no longer match the spliced audio; they are rewritten below
+	_ = os.Remove(align.SidecarPath(entry.Output))
+
+	log.Faint(fmt.Sprintf("Re-synthesized %d of %d sentence(s)", splice.Changed(ops), len(newSentences)))
+	if measured, err := utils.GetAudioDuration(entry.Output); err == nil {
+		duration = measured
+	}
+	result := audio.Result{OutputPath: entry.Output, Duration: duration}
+	generator.WriteTimings(section, result)
+	return result, true
+}
+
+// sentenceTimings returns the timings of the sentences of existing audio, from its
+// aligned timings when available, otherwise estimated from its duration
+func sentenceTimings(audioPath, spoken string, sentences []string, duration float64) []timing.Sentence {
+	if sidecar, err := align.ReadSidecar(audioPath); err == nil {
+		if spans := timing.GroupSentences(sentences, sidecar.Words); spans != nil {
+			return spans
+		}
+	}
+	return timing.GroupSentences(sentences, timing.EstimateWords(spoken, duration, timing.MethodSyllable))
+}
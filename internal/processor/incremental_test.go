@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/testhelpers"
+	"github.com/indaco/md2audio/internal/tts/silence"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestUpdateIncrementally(t *testing.T) {
+	const spoken = "Hello there. This is the middle. Goodbye now."
+	_, ffmpegErr := exec.LookPath("ffmpeg")
+
+	tests := []struct {
+		name         string
+		content      string
+		noEntry      bool
+		wantReused   bool
+		wantAPICalls int
+	}{
+		{name: "unchanged", content: spoken, wantReused: true},
+		{name: "no manifest entry", content: spoken, noEntry: true},
+		{name: "rewritten", content: "Something else entirely."},
+		{name: "one sentence edited", content: "Hello there. This is the new middle. Goodbye now.", wantReused: ffmpegErr == nil, wantAPICalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			log := logger.NewDefaultLogger()
+			generator := audio.NewGenerator(audio.GeneratorConfig{
+				Rate:      180,
+				Format:    "wav",
+				Prefix:    "section",
+				OutputDir: outputDir,
+				Provider:  silence.NewProvider(),
+			}, log)
+
+			output := filepath.Join(outputDir, "section_01_intro.wav")
+			if err := utils.WriteSilence(context.Background(), output, 3); err != nil {
+				t.Fatal(err)
+			}
+			m := manifest.New()
+			if !tt.noEntry {
+				m.Put(manifest.Entry{Index: 1, Title: "Intro", Output: output, Status: manifest.StatusOK, Text: spoken})
+			}
+
+			rs := &runState{}
+			section := parser.Section{Index: 1, Title: "Intro", Content: tt.content}
+			var result audio.Result
+			var reused bool
+			if _, err := testhelpers.CaptureStdout(func() {
+				result, reused = updateIncrementally(generator, section, m, output, rs, log)
+			}); err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+
+			if reused != tt.wantReused {
+				t.Errorf("updateIncrementally() reused = %v, want %v", reused, tt.wantReused)
+			}
+			if rs.apiCalls != tt.wantAPICalls {
+				t.Errorf("API calls = %d, want %d", rs.apiCalls, tt.wantAPICalls)
+			}
+			if reused && result.OutputPath != output {
+				t.Errorf("OutputPath = %q, want %q", result.OutputPath, output)
+			}
+		})
+	}
+}
+
+func TestProcessFileIncrementalKeepsUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	if err := os.WriteFile(mdFile, []byte("## Intro (2s)\n\nHello there.\n\n## Outro (1s)\n\nBye.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	outputDir := filepath.Join(tmpDir, "output")
+
+	cfg := config.Config{
+		Provider:    "espeak",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+		Incremental: true,
+	}
+	log := logger.NewDefaultLogger()
+	run := func() string {
+		output, err := testhelpers.CaptureStdout(func() {
+			if err := ProcessFile(mdFile, outputDir, cfg, log); err != nil {
+				t.Errorf("ProcessFile() error = %v", err)
+			}
+		})
+		if err != nil {
+			t.Fatalf("Failed to capture output: %v", err)
+		}
+		return output
+	}
+
+	if output := run(); strings.Contains(output, "Unchanged, keeping") {
+		t.Errorf("First run should generate all sections, got output:\n%s", output)
+	}
+
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if len(m.Entries) != 2 || m.Entries[0].Text != "Hello there." {
+		t.Fatalf("Expected the synthesized text in the manifest, got %+v", m.Entries)
+	}
+
+	if output := run(); strings.Count(output, "Unchanged, keeping") != 2 {
+		t.Errorf("Second run should keep both sections, got output:\n%s", output)
+	}
+}
@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// sortMarkdownFiles applies the -order processing order to files and returns
+// the order used, as recorded in manifests. -newest-first selects the mtime
+// order. The doc and natural orders follow an _order.yaml index in the input
+// directory when there is one. Shuffling without an explicit seed uses -seed,
+// or a random seed that is logged.
+func sortMarkdownFiles(files []parser.MarkdownFile, cfg config.Config, log logger.LoggerInterface) (string, error) {
+	order, err := parser.ParseOrder(cfg.Order)
+	if err != nil {
+		return "", err
+	}
+	if cfg.NewestFirst {
+		order = parser.Order{Kind: parser.OrderMtime}
+	}
+	if order.Kind == parser.OrderDoc || order.Kind == parser.OrderNatural {
+		return applyOrderFile(files, order, cfg, log)
+	}
+
+	if order.Kind == parser.OrderShuffle && order.Seed == 0 {
+		order.Seed = cfg.Seed
+	}
+	seed := parser.SortMarkdownFiles(files, order)
+	if order.Kind == parser.OrderShuffle {
+		order.Seed = seed
+	}
+	log.Hint(fmt.Sprintf("Processing order: %s", order))
+	return order.String(), nil
+}
+
+// applyOrderFile sorts files by the _order.yaml index of the input directory,
+// unlisted files following in natural order, or by the doc or natural order
+// without an index
+func applyOrderFile(files []parser.MarkdownFile, order parser.Order, cfg config.Config, log logger.LoggerInterface) (string, error) {
+	listed, err := parser.LoadOrderFile(cfg.InputDir)
+	if err != nil {
+		return "", err
+	}
+	if listed == nil {
+		if order.Kind == parser.OrderNatural {
+			parser.SortMarkdownFiles(files, order)
+			log.Hint(fmt.Sprintf("Processing order: %s", order))
+		}
+		return order.String(), nil
+	}
+
+	parser.SortMarkdownFiles(files, parser.Order{Kind: parser.OrderNatural})
+	for _, path := range parser.ApplyOrderFile(files, listed) {
+		log.Warning(fmt.Sprintf("%s lists %s, which is not a markdown file in %s", parser.OrderFileName, path, cfg.InputDir))
+	}
+	log.Hint(fmt.Sprintf("Processing order: %s", parser.OrderFileName))
+	return parser.OrderFileName, nil
+}
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// PreviewAloud plays the sections of the markdown input (-f or -d) through
+// the speakers with the say provider, so voices and rates can be auditioned
+// before a batch run. No files are written.
+func PreviewAloud(cfg config.Config, log logger.LoggerInterface) error {
+	if cfg.Provider != "say" {
+		return fmt.Errorf("-preview is only supported by the say provider")
+	}
+	if !cfg.IsDirectoryMode() && cfg.MarkdownFile == "" {
+		return fmt.Errorf("-preview requires a markdown file (-f) or directory (-d)")
+	}
+	filter, err := parseSectionFilter(cfg.Commands.PreviewFilter)
+	if err != nil {
+		return err
+	}
+
+	files, err := exportFiles(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is generated, so there are no failures to guard against
+	cfg.FailureCooldown = 0
+	generator, err := newGenerator(cfg, cfg.OutputDir, log)
+	if err != nil {
+		return err
+	}
+
+	played := 0
+	for _, file := range files {
+		sections, err := parseMarkdownFile(file.AbsPath, cfg)
+		if err != nil {
+			return fmt.Errorf("%s: error parsing markdown: %w", file.RelPath, err)
+		}
+		sections = newRunState(cfg).selectSections(file.AbsPath, sections, cfg)
+		if sections, err = transformSections(sections, cfg); err != nil {
+			return fmt.Errorf("%s: %w", file.RelPath, err)
+		}
+
+		for _, section := range sections {
+			if !filter.matches(section) {
+				continue
+			}
+			log.Info(fmt.Sprintf("Playing %s, section %d:", file.RelPath, section.Index), section.Title)
+			if err := generator.SpeakSection(context.Background(), section, section.Index); err != nil {
+				return fmt.Errorf("%s: section %d: %w", file.RelPath, section.Index, err)
+			}
+			played++
+		}
+	}
+
+	if played == 0 {
+		log.Warning("No sections matched -preview-sections")
+		return nil
+	}
+	log.Blank()
+	log.Success(fmt.Sprintf("Played %d section(s)", played))
+	return nil
+}
+
+// sectionFilter selects sections by number, range of numbers, or title word
+type sectionFilter struct {
+	ranges [][2]int
+	words  []string
+}
+
+// parseSectionFilter parses a comma-separated list of section numbers
+// ("3"), ranges ("2-4"), and words matched case-insensitively against the
+// section titles ("intro"). An empty list selects all sections.
+func parseSectionFilter(spec string) (sectionFilter, error) {
+	var filter sectionFilter
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			filter.words = append(filter.words, strings.ToLower(part))
+			continue
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+				return sectionFilter{}, fmt.Errorf("invalid -preview-sections range %q", part)
+			}
+		}
+		if from < 1 || to < from {
+			return sectionFilter{}, fmt.Errorf("invalid -preview-sections range %q: sections are numbered from 1", part)
+		}
+		filter.ranges = append(filter.ranges, [2]int{from, to})
+	}
+	return filter, nil
+}
+
+// matches reports whether a section is selected by the filter
+func (f sectionFilter) matches(section parser.Section) bool {
+	if len(f.ranges) == 0 && len(f.words) == 0 {
+		return true
+	}
+	for _, r := range f.ranges {
+		if section.Index >= r[0] && section.Index <= r[1] {
+			return true
+		}
+	}
+	title := strings.ToLower(section.Title)
+	for _, word := range f.words {
+		if strings.Contains(title, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+func TestParseSectionFilter(t *testing.T) {
+	sections := []parser.Section{
+		{Index: 1, Title: "Introduction"},
+		{Index: 2, Title: "Setup"},
+		{Index: 3, Title: "Usage"},
+		{Index: 4, Title: "Advanced usage"},
+		{Index: 5, Title: "Summary"},
+	}
+
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr string
+	}{
+		{spec: "", want: []int{1, 2, 3, 4, 5}},
+		{spec: "2", want: []int{2}},
+		{spec: "1, 3-4", want: []int{1, 3, 4}},
+		{spec: "intro,USAGE", want: []int{1, 3, 4}},
+		{spec: "5,setup", want: []int{2, 5}},
+		{spec: "4-2", wantErr: "invalid -preview-sections range"},
+		{spec: "0", wantErr: "numbered from 1"},
+		{spec: "2-x", wantErr: "invalid -preview-sections range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			filter, err := parseSectionFilter(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseSectionFilter() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSectionFilter() error = %v", err)
+			}
+
+			var got []int
+			for _, section := range sections {
+				if filter.matches(section) {
+					got = append(got, section.Index)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("selected = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("selected = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewAloudErrors(t *testing.T) {
+	log := logger.NewDefaultLogger()
+
+	cfg := config.Config{Provider: "elevenlabs", MarkdownFile: "doc.md"}
+	if err := PreviewAloud(cfg, log); err == nil || !strings.Contains(err.Error(), "say provider") {
+		t.Errorf("PreviewAloud() error = %v, want a provider error", err)
+	}
+
+	cfg = config.Config{Provider: "say"}
+	if err := PreviewAloud(cfg, log); err == nil || !strings.Contains(err.Error(), "-f") {
+		t.Errorf("PreviewAloud() error = %v, want a missing input error", err)
+	}
+
+	cfg = config.Config{Provider: "say", MarkdownFile: "doc.md", Commands: config.CommandFlags{PreviewFilter: "3-1"}}
+	if err := PreviewAloud(cfg, log); err == nil || !strings.Contains(err.Error(), "-preview-sections") {
+		t.Errorf("PreviewAloud() error = %v, want a filter error", err)
+	}
+}
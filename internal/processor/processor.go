@@ -12,22 +12,125 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/schollz/progressbar/v3"
 
+	"github.com/indaco/md2audio/internal/align"
 	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/cache"
+	"github.com/indaco/md2audio/internal/calibrate"
 	"github.com/indaco/md2audio/internal/cli"
 	"github.com/indaco/md2audio/internal/config"
 	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
 	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/postprocess"
+	"github.com/indaco/md2audio/internal/progress"
+	"github.com/indaco/md2audio/internal/pronounce"
+	"github.com/indaco/md2audio/internal/summary"
+	"github.com/indaco/md2audio/internal/timing"
+	"github.com/indaco/md2audio/internal/transform"
+	"github.com/indaco/md2audio/internal/tts"
 	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+	"github.com/indaco/md2audio/internal/tts/say"
+	"github.com/indaco/md2audio/internal/tts/silence"
+	"github.com/indaco/md2audio/internal/verify"
 )
 
+// PronunciationReportFile is the report written to each output directory by -pronunciation-report
+const PronunciationReportFile = "pronunciation_report.txt"
+
+// runState holds state shared across the files of a run
+type runState struct {
+	summary *summary.Summary        // Run summary (nil in dry-runs and when nothing uses it)
+	sample  map[string]map[int]bool // Sampled section indices per markdown file (nil = all sections)
+
+	order     string         // Processing order of a directory run, recorded in manifests
+	positions map[string]int // 1-based position of each markdown file in the processing order
+
+	deadline   time.Time // End of the -max-duration budget (zero = unlimited)
+	apiCalls   int       // Provider requests made so far, counted against -max-api-calls
+	characters int       // Characters sent to the provider, recorded in the run history
+	stopReason string    // Why the run budget was spent (empty while within budget)
+
+	stats runStats // Outcomes turned into next steps at the end of the run
+
+	progress progress.Func // Receives progress events (nil = none)
+}
+
+// newRunState creates the state for a run
+func newRunState(cfg config.Config) *runState {
+	rs := &runState{summary: newRunSummary(cfg), progress: cfg.Progress}
+	if cfg.MaxDuration > 0 {
+		rs.deadline = time.Now().Add(cfg.MaxDuration)
+	}
+	return rs
+}
+
+// addEntry records the outcome of a section saved in the manifest at manifestPath
+// in the run stats and summary, and reports it to the progress callback
+func (rs *runState) addEntry(entry manifest.Entry, manifestPath string, duration float64) {
+	rs.stats.addEntry(entry, manifestPath)
+	if rs.summary != nil {
+		rs.summary.Add(entry, duration)
+	}
+	rs.emit(progress.SectionEvent(entry, duration))
+}
+
+// emit reports a progress event to the progress callback, if any
+func (rs *runState) emit(event progress.Event) {
+	if rs.progress != nil {
+		rs.progress(event)
+	}
+}
+
+// selected reports whether a section of markdownFile is part of the run
+func (rs *runState) selected(markdownFile string, index int) bool {
+	if rs.sample == nil {
+		return true
+	}
+	return rs.sample[markdownFile][index]
+}
+
+// selectSections returns the sections of markdownFile that are part of the run,
+// applying -limit-sections and -sample
+func (rs *runState) selectSections(markdownFile string, sections []parser.Section, cfg config.Config) []parser.Section {
+	if cfg.LimitSections > 0 && cfg.LimitSections < len(sections) {
+		sections = sections[:cfg.LimitSections]
+	}
+	if rs.sample == nil {
+		return sections
+	}
+
+	selected := make([]parser.Section, 0, len(sections))
+	for _, section := range sections {
+		if rs.selected(markdownFile, section.Index) {
+			selected = append(selected, section)
+		}
+	}
+	return selected
+}
+
 // ProcessDirectory processes all markdown files in a directory recursively
 func ProcessDirectory(cfg config.Config, log logger.LoggerInterface) error {
+	rs := newRunState(cfg)
+	if err := processDirectory(cfg, log, rs); err != nil {
+		return err
+	}
+	finishRun(cfg, rs, modeDirectory, cfg.InputDir, log)
+	return nil
+}
+
+// processDirectory processes a directory, recording outcomes in rs
+func processDirectory(cfg config.Config, log logger.LoggerInterface, rs *runState) error {
 	log.Info("Scanning directory:", cfg.InputDir)
 
 	// Find all markdown files
@@ -35,12 +138,33 @@ func ProcessDirectory(cfg config.Config, log logger.LoggerInterface) error {
 	if err != nil {
 		return fmt.Errorf("failed to scan directory: %w", err)
 	}
+	mdFiles = parser.MapExport(mdFiles, cfg.InputFormat)
 
 	if len(mdFiles) == 0 {
 		return fmt.Errorf("no markdown files found in directory: %s", cfg.InputDir)
 	}
 
 	log.Success(fmt.Sprintf("Found %d markdown file(s)", len(mdFiles)))
+	if rs.order, err = sortMarkdownFiles(mdFiles, cfg, log); err != nil {
+		return err
+	}
+	rs.positions = make(map[string]int, len(mdFiles))
+	for i, mdFile := range mdFiles {
+		rs.positions[mdFile.AbsPath] = i + 1
+	}
+	if cfg.Limit > 0 && cfg.Limit < len(mdFiles) {
+		mdFiles = mdFiles[:cfg.Limit]
+		log.Hint(fmt.Sprintf("Limiting to the first %d file(s)", cfg.Limit))
+	}
+	if cfg.Sample != "" {
+		files := make([]string, len(mdFiles))
+		for i, mdFile := range mdFiles {
+			files[i] = mdFile.AbsPath
+		}
+		if err := rs.sampleSections(files, cfg, log); err != nil {
+			return err
+		}
+	}
 	log.Blank()
 
 	totalSuccess := 0
@@ -62,15 +186,35 @@ func ProcessDirectory(cfg config.Config, log logger.LoggerInterface) error {
 	)
 
 	// Process each markdown file
+	processedFiles := 0
 	for i, mdFile := range mdFiles {
+		// Get output directory for this file
+		mdFile.Lang = cfg.Language
+		outputDir, err := mdFile.ResolveOutputDir(cfg.OutputDir)
+
+		// Record the remaining files as skipped once the run budget is spent
+		if reason := rs.budgetSpent(cfg, log); reason != "" {
+			if err == nil && !cfg.Commands.DryRun {
+				err = recordSkippedFile(mdFile.AbsPath, outputDir, reason, cfg, log, rs)
+			}
+			if err != nil {
+				log.Warning(fmt.Sprintf("Failed to record skipped file %s: %v", mdFile.RelPath, err))
+			}
+			continue
+		}
+		processedFiles++
+
 		log.Blank()
 		log.Info(fmt.Sprintf("Processing file %d/%d:", i+1, len(mdFiles))).WithAttrs("file", mdFile.RelPath)
 
-		// Get output directory for this file
-		outputDir := mdFile.GetOutputDir(cfg.OutputDir)
+		if err != nil {
+			log.Warning(fmt.Sprintf("Failed to process %s: %v", mdFile.RelPath, err))
+			_ = bar.Add(1)
+			continue
+		}
 
 		// Process the file
-		successCount, sectionCount, err := processSingleFile(mdFile.AbsPath, outputDir, cfg, log)
+		successCount, sectionCount, err := processSingleFile(mdFile.AbsPath, outputDir, cfg, log, rs)
 		if err != nil {
 			log.Warning(fmt.Sprintf("Failed to process %s: %v", mdFile.RelPath, err))
 			_ = bar.Add(1)
@@ -79,6 +223,9 @@ func ProcessDirectory(cfg config.Config, log logger.LoggerInterface) error {
 
 		totalSuccess += successCount
 		totalSections += sectionCount
+		outputDir = fileOutputDir(mdFile.AbsPath, outputDir, cfg)
+		writeFileZip(mdFile.AbsPath, mdFile.RelPath, outputDir, cfg, log)
+		writeSiteAssets(mdFile.AbsPath, mdFile.RelPath, outputDir, cfg, log)
 
 		// Update progress bar
 		_ = bar.Add(1)
@@ -91,81 +238,191 @@ func ProcessDirectory(cfg config.Config, log logger.LoggerInterface) error {
 	// Final summary
 	log.Blank()
 	log.Success("Directory processing complete!")
-	log.Info(fmt.Sprintf("Generated %d/%d audio files from %d markdown file(s)", totalSuccess, totalSections, len(mdFiles)))
+	log.Info(fmt.Sprintf("Generated %d/%d audio files from %d markdown file(s)", totalSuccess, totalSections, processedFiles))
+	if skipped := len(mdFiles) - processedFiles; skipped > 0 {
+		log.Warning(fmt.Sprintf("Skipped %d file(s) after reaching the run budget", skipped))
+	}
 	log.Info("Output directory:", cfg.OutputDir)
 
 	return nil
 }
 
+// ProcessLanguages processes one input subdirectory per configured language.
+// Each language <lang> reads from <InputDir>/<lang> and writes to <OutputDir>/<lang>,
+// using the language's voice mapping when one is configured.
+func ProcessLanguages(cfg config.Config, log logger.LoggerInterface) error {
+	rs := newRunState(cfg)
+	processed := 0
+	for _, lang := range cfg.Languages {
+		langCfg := cfg.ForLanguage(lang)
+
+		if info, err := os.Stat(langCfg.InputDir); err != nil || !info.IsDir() {
+			log.Warning(fmt.Sprintf("Skipping language %s: input directory not found: %s", lang, langCfg.InputDir))
+			continue
+		}
+
+		log.Blank()
+		log.Info("Processing language:", lang)
+
+		if err := processDirectory(langCfg, log, rs); err != nil {
+			log.Warning(fmt.Sprintf("Failed to process language %s: %v", lang, err))
+			continue
+		}
+		processed++
+	}
+
+	if processed == 0 {
+		return fmt.Errorf("no language directories processed in: %s", cfg.InputDir)
+	}
+
+	log.Blank()
+	log.Success(fmt.Sprintf("Processed %d/%d language(s)", processed, len(cfg.Languages)))
+	finishRun(cfg, rs, modeLanguages, cfg.InputDir, log)
+	return nil
+}
+
 // ProcessFile processes a single markdown file
+// If outputDir is a template, it is rendered using the file's own directory as the input root.
 func ProcessFile(markdownFile, outputDir string, cfg config.Config, log logger.LoggerInterface) error {
-	_, _, err := processSingleFile(markdownFile, outputDir, cfg, log)
-	return err
+	if parser.IsOutputTemplate(outputDir) {
+		mdFile, err := parser.NewMarkdownFile(markdownFile)
+		if err != nil {
+			return err
+		}
+		if outputDir, err = mdFile.ResolveOutputDir(outputDir); err != nil {
+			return err
+		}
+	}
+
+	rs := newRunState(cfg)
+	if cfg.Sample != "" {
+		if err := rs.sampleSections([]string{markdownFile}, cfg, log); err != nil {
+			return err
+		}
+	}
+	if _, _, err := processSingleFile(markdownFile, outputDir, cfg, log, rs); err != nil {
+		return err
+	}
+	outputDir = fileOutputDir(markdownFile, outputDir, cfg)
+	writeFileZip(markdownFile, filepath.Base(markdownFile), outputDir, cfg, log)
+	writeSiteAssets(markdownFile, filepath.Base(markdownFile), outputDir, cfg, log)
+	finishRun(cfg, rs, modeFile, markdownFile, log)
+	return nil
 }
 
-// processSingleFile processes one markdown file and returns success count and section count
-func processSingleFile(markdownFile, outputDir string, cfg config.Config, log logger.LoggerInterface) (int, int, error) {
+// processSingleFile processes one markdown file and returns success count and section count.
+// Only the sections selected for the run are generated, and their outcomes recorded in rs.
+func processSingleFile(markdownFile, outputDir string, cfg config.Config, log logger.LoggerInterface, rs *runState) (int, int, error) {
+	// Per-file settings from the front matter override the run's configuration
+	outputDir, cfg, err := applyFrontMatter(markdownFile, outputDir, cfg, log)
+	if err != nil {
+		return 0, 0, err
+	}
 	log.Debug(fmt.Sprintf("Processing file: %s -> %s", markdownFile, outputDir))
 
 	// Parse markdown file
 	log.Info("Parsing markdown file...")
-	sections, err := parser.ParseMarkdownFile(markdownFile)
+	sections, err := parseMarkdownFile(markdownFile, cfg)
 	if err != nil {
 		return 0, 0, fmt.Errorf("error parsing markdown: %w", err)
 	}
 
 	if len(sections) == 0 {
 		log.Warning("No H2 sections found in the markdown file.")
+		rs.stats.emptyFiles = append(rs.stats.emptyFiles, displayPath(markdownFile, cfg))
 		return 0, 0, nil
 	}
 
 	log.Success(fmt.Sprintf("Found %d section(s)", len(sections)))
+	if cfg.LimitSections > 0 && cfg.LimitSections < len(sections) {
+		log.Hint(fmt.Sprintf("Limiting to the first %d section(s)", cfg.LimitSections))
+	}
+	sections = rs.selectSections(markdownFile, sections, cfg)
+	if rs.sample != nil {
+		log.Hint(fmt.Sprintf("Sampled %d section(s)", len(sections)))
+		if len(sections) == 0 {
+			return 0, 0, nil
+		}
+	}
+	if sections, err = transformSections(sections, cfg); err != nil {
+		return 0, 0, err
+	}
+	sections, redactions := redactSections(sections, markdownFile, cfg)
 	log.Blank()
+	logRedactions(redactions, log)
+	if cfg.Granularity == config.GranularitySentence {
+		sections = splitSentences(sections)
+		log.Hint(fmt.Sprintf("Split into %d sentence(s)", len(sections)))
+	}
+	checkAudioTags(sections, cfg, log)
 
 	// Create output directory
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return 0, 0, fmt.Errorf("error creating output directory: %w", err)
 	}
 
-	// Create TTS provider
-	provider, err := cli.CreateProvider(cfg)
+	// Continue the numbering of earlier runs into the same output directory
+	cfg.StartIndex = resolveStartIndex(markdownFile, outputDir, cfg)
+
+	// Create audio generator
+	generator, err := newGenerator(cfg, outputDir, log)
 	if err != nil {
-		return 0, 0, fmt.Errorf("error creating TTS provider: %w", err)
+		return 0, 0, err
 	}
+	generator.SetSections(sections)
 
-	// Set logger on provider if it supports it (ElevenLabs client)
-	if elevenlabsClient, ok := provider.(*elevenlabs.Client); ok {
-		elevenlabsClient.SetLogger(log)
+	// Dry-run mode: show what would be generated
+	if cfg.Commands.DryRun {
+		if cfg.PronunciationReport {
+			logPronunciationReport(sections, log)
+		}
+		return handleDryRun(sections, cfg, generator, log)
 	}
 
-	log.Info("Using TTS provider:", provider.Name())
-	log.Blank()
-
-	// Determine voice to use based on provider
-	voice := cfg.Say.Voice
-	if cfg.Provider == "elevenlabs" {
-		voice = cfg.ElevenLabs.VoiceID
+	// Record section outcomes in the output directory manifest
+	manifestPath := manifest.PathFor(outputDir)
+	m, err := manifest.LoadOrNew(manifestPath)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Starting a new manifest: %v", err))
+		m = manifest.New()
+	}
+	// Existing audio is only reused when generated with the same settings
+	// Post-processed files cannot be spliced: their unchanged parts would be processed twice
+	incremental := cfg.Incremental && m.Settings != nil && len(m.Settings.Diff(runSettings(cfg))) == 0 && !postProcesses(cfg)
+	if err := checkSettings(m, outputDir, cfg, log); err != nil {
+		return 0, 0, err
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+	if rs.order != "" {
+		m.Order = rs.order
 	}
-	// espeak uses cfg.Say.Voice (same as say provider)
-
-	// Create audio generator
-	generator := audio.NewGenerator(audio.GeneratorConfig{
-		Voice:     voice,
-		Rate:      cfg.Say.Rate,
-		Format:    cfg.Format,
-		Prefix:    cfg.Prefix,
-		OutputDir: outputDir,
-		Provider:  provider,
-	}, log)
 
-	// Dry-run mode: show what would be generated
-	if cfg.Commands.DryRun {
-		return handleDryRun(sections, outputDir, cfg, log)
+	if rs.summary != nil {
+		rs.summary.AddFile()
 	}
+	rs.emit(progress.Event{Kind: progress.FileStarted, File: sourcePath, Sections: len(sections)})
 
 	// Generate audio for each section
 	successCount := 0
+	flaggedCount := 0
+	skippedCount := 0
 	for i, section := range sections {
+		entry := newEntry(sourcePath, rs.positions[markdownFile], section, generator)
+		plannedPath := generator.OutputBase(section, section.Index) + "." + cfg.OutputFormat()
+
+		// Record the remaining sections as skipped once the run budget is spent
+		if reason := rs.budgetSpent(cfg, log); reason != "" {
+			entry = skippedEntry(entry, plannedPath, reason)
+			m.Put(entry)
+			rs.addEntry(entry, manifestPath, 0)
+			skippedCount++
+			continue
+		}
+
+		logChapter(sections, i, log)
 		log.Blank()
 		log.Info(fmt.Sprintf("Section %d/%d:", i+1, len(sections))).WithAttrs("title", section.Title)
 
@@ -183,26 +440,527 @@ func processSingleFile(markdownFile, outputDir string, cfg config.Config, log lo
 		log.Faint(fmt.Sprintf("Text: %s", preview))
 		log.WithIndent(false)
 
-		if err := generator.Generate(section, i+1); err != nil {
+		var result audio.Result
+		var err error
+		reused := false
+		if incremental {
+			result, reused = updateIncrementally(generator, section, m, plannedPath, rs, log)
+		}
+		if !reused {
+			rs.apiCalls++
+			rs.characters += utf8.RuneCountInString(section.Content)
+			result, err = generator.GenerateSection(section, section.Index)
+		}
+		entry = manifestEntry(entry, plannedPath, result, err)
+		if err != nil {
 			log.Error("Failed:", err)
+			entry = writePlaceholder(generator, section, entry, cfg, log)
 		} else {
-			successCount++
+			entry.Text = section.Content
+			tagAudio(result.OutputPath, *m.Settings, cfg, log)
+			entry = fingerprintEntry(entry, log)
+			writeVariants(result.OutputPath, cfg, log)
+		}
+		m.Put(entry)
+		rs.addEntry(entry, manifestPath, result.Duration)
+		if err != nil {
+			continue
+		}
+		rs.stats.addDuration(section, result.Duration)
+		successCount++
+		if result.Flagged {
+			flaggedCount++
 		}
 	}
 
+	if err := m.Save(manifestPath); err != nil {
+		log.Warning(fmt.Sprintf("Could not write manifest: %v", err))
+	}
+
 	log.Blank()
 	log.Success(fmt.Sprintf("Complete! Generated %d/%d audio files", successCount, len(sections)))
+	if flaggedCount > 0 {
+		log.Warning(fmt.Sprintf("%d section(s) flagged by verification", flaggedCount))
+	}
+	if skippedCount > 0 {
+		log.Warning(fmt.Sprintf("%d section(s) skipped after reaching the run budget", skippedCount))
+	}
 	log.Info("Files saved to:", outputDir)
 
+	if cfg.PronunciationReport {
+		reportPath, count, err := writePronunciationReport(sections, outputDir)
+		if err != nil {
+			log.Warning(fmt.Sprintf("Failed to write pronunciation report: %v", err))
+		} else {
+			log.Info(fmt.Sprintf("Pronunciation report (%d token(s)):", count), reportPath)
+		}
+	}
+	if cfg.Redact != "" {
+		reportPath, err := writeRedactionReport(redactions, outputDir)
+		if err != nil {
+			log.Warning(fmt.Sprintf("Failed to write redaction report: %v", err))
+		} else {
+			log.Info(fmt.Sprintf("Redaction report (%d item(s)):", len(redactions)), reportPath)
+		}
+	}
+
 	return successCount, len(sections), nil
 }
 
+// parseMarkdownFile parses the sections of a markdown file, speaking
+// -empty-section for sections left without text by cleaning
+func parseMarkdownFile(markdownFile string, cfg config.Config) ([]parser.Section, error) {
+	return parser.ParseMarkdownFileWith(markdownFile, parser.ParseOptions{EmptySection: cfg.EmptySection, Chapters: cfg.Chapters})
+}
+
+// splitSentences splits sections into one section per sentence for -granularity sentence
+func splitSentences(sections []parser.Section) []parser.Section {
+	var sentences []parser.Section
+	for _, section := range sections {
+		sentences = append(sentences, section.Sentences()...)
+	}
+	return sentences
+}
+
+// transformSections rewrites section text with the library transforms and -transform-cmd
+func transformSections(sections []parser.Section, cfg config.Config) ([]parser.Section, error) {
+	transforms := cfg.Transforms
+	if cfg.TransformCmd != "" {
+		command, err := transform.Command(context.Background(), cfg.TransformCmd)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(slices.Clip(transforms), command)
+	}
+	return transform.Apply(sections, transforms)
+}
+
+// postProcessors returns the library post-processors followed by -post-cmd
+func postProcessors(cfg config.Config) ([]postprocess.Func, error) {
+	hooks := cfg.PostProcessors
+	if cfg.PostCmd != "" {
+		command, err := postprocess.Command(cfg.PostCmd)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(slices.Clip(hooks), command)
+	}
+	return hooks, nil
+}
+
+// postProcesses reports whether generated files are post-processed
+func postProcesses(cfg config.Config) bool {
+	return cfg.PostCmd != "" || len(cfg.PostProcessors) > 0
+}
+
+// newGenerator creates the TTS provider, optional aligner and transcriber,
+// and the audio generator writing into outputDir
+func newGenerator(cfg config.Config, outputDir string, log logger.LoggerInterface) (*audio.Generator, error) {
+	// Create TTS provider (silent scaffolds never call one)
+	var provider tts.Provider = silence.NewProvider()
+	var err error
+	if !cfg.SilenceOnly {
+		if provider, err = cli.CreateProvider(cfg); err != nil {
+			return nil, fmt.Errorf("error creating TTS provider: %w", err)
+		}
+	}
+
+	// Set logger on provider if it supports it (ElevenLabs client)
+	if elevenlabsClient, ok := provider.(*elevenlabs.Client); ok {
+		elevenlabsClient.SetLogger(log)
+	}
+
+	log.Info("Using TTS provider:", provider.Name())
+	log.Blank()
+
+	// Warn when a compact say voice has a higher quality variant
+	if sayProvider, ok := provider.(*say.Provider); ok {
+		checkVoiceQuality(sayProvider, cfg, log)
+	}
+
+	// Fail fast on invalid voices and rejected credentials recorded by recent runs
+	if !cfg.SilenceOnly && !cfg.Commands.DryRun && cfg.FailureCooldown > 0 && cfg.HistoryDB != "" {
+		provider = cache.NewGuardedProvider(provider, cfg.HistoryDB, providerCredentials(cfg), cfg.FailureCooldown, log)
+	}
+
+	// Create forced aligner if requested
+	var aligner align.Aligner
+	if cfg.Align.Method != "" {
+		aligner, err = align.New(align.Config{
+			Method:   cfg.Align.Method,
+			Command:  cfg.Align.Command,
+			Model:    cfg.Align.WhisperModel,
+			Language: cfg.Align.Language,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating aligner: %w", err)
+		}
+	}
+
+	postProcessors, err := postProcessors(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create transcriber for round-trip verification if requested
+	var verifier verify.Transcriber
+	if cfg.Verify.Transcribe {
+		verifier, err = verify.NewWhisperTranscriber(cfg.Verify.Command, cfg.Align.WhisperModel, cfg.Align.Language)
+		if err != nil {
+			return nil, fmt.Errorf("error creating transcriber: %w", err)
+		}
+	}
+
+	voice := providerVoice(cfg)
+
+	// Silent scaffolds have the estimated duration by construction
+	minDurationRatio := cfg.Verify.MinDurationRatio
+	var resolve audio.ProviderResolver
+	if cfg.SilenceOnly {
+		minDurationRatio = 0
+	} else {
+		resolve = sectionProviders(cfg, log)
+	}
+
+	return audio.NewGenerator(audio.GeneratorConfig{
+		Voice:           voice,
+		Rate:            cfg.Say.Rate,
+		Format:          cfg.OutputFormat(),
+		Prefix:          cfg.Prefix,
+		OutputDir:       outputDir,
+		Provider:        provider,
+		Subtitles:       cfg.Subtitles,
+		ReadAlong:       cfg.ReadAlong,
+		TimingMethod:    timing.Method(cfg.TimingMethod),
+		Aligner:         aligner,
+		SSML:            cfg.SSML,
+		Stitch:          cfg.ElevenLabs.Stitch,
+		RateCurve:       loadRateCurve(cfg, provider.Name(), voice, log),
+		Learner:         rateLearner(cfg, provider.Name(), voice),
+		PostProcessors:  postProcessors,
+		SlugStyle:       cfg.SlugStyle,
+		MaxFilenameLen:  cfg.MaxFilenameLen,
+		StartIndex:      cfg.StartIndex,
+		Verifier:        verifier,
+		VerifyThreshold: cfg.Verify.Threshold,
+
+		MinDurationRatio: minDurationRatio,
+		RetryShort:       cfg.Verify.RetryShort,
+		Resolve:          resolve,
+	}, log), nil
+}
+
+// sectionProviders returns the resolver of section provider overrides
+// ("## Intro {provider=say}"). Each provider is created once, with the
+// settings of the run; without a voice override, sections use the provider's
+// default voice.
+func sectionProviders(cfg config.Config, log logger.LoggerInterface) audio.ProviderResolver {
+	type created struct {
+		provider tts.Provider
+		err      error
+	}
+	providers := make(map[string]created)
+
+	return func(name string) (tts.Provider, string, error) {
+		override := withProvider(cfg, name)
+
+		c, ok := providers[name]
+		if !ok {
+			c.provider, c.err = cli.CreateProvider(override)
+			if elevenlabsClient, ok := c.provider.(*elevenlabs.Client); ok {
+				elevenlabsClient.SetLogger(log)
+			}
+			providers[name] = c
+		}
+		return c.provider, providerVoice(override), c.err
+	}
+}
+
+// withProvider returns cfg switched to another provider with its default voice
+func withProvider(cfg config.Config, name string) config.Config {
+	cfg.Provider = name
+	cfg.Say.Voice = ""
+	if name == "elevenlabs" && cfg.ElevenLabs.VoiceID == "" {
+		cfg.ElevenLabs.VoiceID = config.DefaultElevenLabsVoiceID
+	}
+	if name == "coqui" {
+		cfg.Say.Voice = cfg.Coqui.SpeakerWav
+	}
+	return cfg
+}
+
+// providerVoice returns the voice of the configured provider
+func providerVoice(cfg config.Config) string {
+	if cfg.Provider == "elevenlabs" {
+		return cfg.ElevenLabs.VoiceID
+	}
+	// espeak uses cfg.Say.Voice (same as say provider)
+	return cfg.Say.Voice
+}
+
+// outputPaths returns a generator without provider, used only to compute
+// the output paths of sections in outputDir
+func outputPaths(cfg config.Config, outputDir string, log logger.LoggerInterface) *audio.Generator {
+	return audio.NewGenerator(audio.GeneratorConfig{
+		OutputDir:      outputDir,
+		Prefix:         cfg.Prefix,
+		SlugStyle:      cfg.SlugStyle,
+		MaxFilenameLen: cfg.MaxFilenameLen,
+		StartIndex:     cfg.StartIndex,
+	}, log)
+}
+
+// loadRateCurve returns the -calibrate curve of a voice, or the profile
+// learned from its generated sections, or nil if neither exists yet
+func loadRateCurve(cfg config.Config, provider, voice string, log logger.LoggerInterface) calibrate.Curve {
+	if cfg.SilenceOnly || cfg.HistoryDB == "" {
+		return nil
+	}
+	store, err := calibrate.Open(cfg.HistoryDB)
+	if err != nil {
+		log.Debug(fmt.Sprintf("Calibrations unavailable: %v", err))
+		return nil
+	}
+	defer func() { _ = store.Close() }()
+
+	curve, err := store.Curve(context.Background(), provider, voice)
+	if err != nil {
+		log.Debug(fmt.Sprintf("Calibrations unavailable: %v", err))
+		return nil
+	}
+	if len(curve) > 0 {
+		log.Debug(fmt.Sprintf("Using the measured speaking rates of %s (%d points)", voice, len(curve)))
+	}
+	return curve
+}
+
+// rateLearner returns the learner recording the speaking rates measured for
+// generated sections, or nil when no audio is synthesized
+func rateLearner(cfg config.Config, provider, voice string) audio.RateLearner {
+	if cfg.SilenceOnly || cfg.Commands.DryRun || cfg.HistoryDB == "" {
+		return nil
+	}
+	return calibrate.Learner{DBPath: cfg.HistoryDB, Provider: provider, Voice: voice}
+}
+
+// providerCredentials returns the credentials identifying the provider
+// account, so failures cached for one API key do not affect another
+func providerCredentials(cfg config.Config) string {
+	if cfg.Provider != "elevenlabs" {
+		return ""
+	}
+	if cfg.ElevenLabs.APIKey != "" {
+		return cfg.ElevenLabs.APIKey
+	}
+	return os.Getenv(elevenlabs.EnvVarAPIKey)
+}
+
+// checkVoiceQuality warns when the say voice is a compact variant and a
+// higher quality one is installed or downloadable, optionally opening
+// the System Settings pane to download it
+func checkVoiceQuality(provider *say.Provider, cfg config.Config, log logger.LoggerInterface) {
+	ctx := context.Background()
+	hint, err := provider.VoiceQualityHint(ctx, cfg.Say.Voice)
+	if err != nil || hint == "" {
+		return
+	}
+
+	log.Warning(hint)
+	if cfg.Say.OpenVoiceSettings {
+		if err := provider.OpenVoiceSettings(ctx); err != nil {
+			log.Warning(fmt.Sprintf("%v", err))
+		}
+	}
+	log.Blank()
+}
+
+// newEntry returns the manifest entry of a section of source, recording its
+// number in file names when -start-index shifts it
+func newEntry(source string, position int, section parser.Section, paths *audio.Generator) manifest.Entry {
+	entry := manifest.Entry{Source: source, Position: position, Index: section.Index, Sentence: section.Sentence, Title: section.Title}
+	if n := paths.OutputNumber(section.Index); n != section.Index {
+		entry.Number = n
+	}
+	return entry
+}
+
+// resolveStartIndex returns the -start-index of markdownFile. With
+// -start-index auto, numbering continues after the sections recorded in the
+// output directory manifest; files recorded there keep their numbers.
+func resolveStartIndex(markdownFile, outputDir string, cfg config.Config) int {
+	if cfg.StartIndex != config.StartIndexContinue {
+		return cfg.StartIndex
+	}
+	m, err := manifest.LoadOrNew(manifest.PathFor(outputDir))
+	if err != nil {
+		return 1
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+	return m.NextStartIndex(sourcePath)
+}
+
+// manifestEntry completes a manifest entry from a generation result.
+// Failed sections are recorded with their planned output path.
+func manifestEntry(entry manifest.Entry, plannedPath string, result audio.Result, err error) manifest.Entry {
+	entry.Placeholder = false
+	entry.Fingerprint, entry.Loudness = "", nil
+	switch {
+	case err != nil:
+		entry.Text = ""
+		entry.Output = plannedPath
+		entry.Status = manifest.StatusFailed
+		entry.Reason = err.Error()
+	case result.Flagged:
+		entry.Output = result.OutputPath
+		entry.Status = manifest.StatusFlagged
+		entry.Reason = result.FlagReason
+	default:
+		entry.Output = result.OutputPath
+		entry.Status = manifest.StatusOK
+	}
+	return entry
+}
+
+// writePlaceholder writes the -placeholder for a failed section at its planned
+// output path, keeping the section's slot in the output, and marks its manifest entry
+func writePlaceholder(generator *audio.Generator, section parser.Section, entry manifest.Entry, cfg config.Config, log logger.LoggerInterface) manifest.Entry {
+	if cfg.Placeholder == "" {
+		return entry
+	}
+
+	result, err := generator.GeneratePlaceholder(section, entry.Output, cfg.Placeholder)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Could not write placeholder: %v", err))
+		return entry
+	}
+	log.WithIndent(true)
+	log.Faint(fmt.Sprintf("Placeholder (%s, %.1fs): %s", cfg.Placeholder, result.Duration, result.OutputPath))
+	log.WithIndent(false)
+	entry.Placeholder = true
+	return entry
+}
+
+// logRequestPreview prints the provider request that would be made for a section
+func logRequestPreview(generator *audio.Generator, section parser.Section, index int, log logger.LoggerInterface) {
+	preview, err := generator.PreviewSection(section, index)
+	log.WithIndent(true)
+	defer log.WithIndent(false)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Cannot preview request: %v", err))
+		return
+	}
+
+	log.Faint(fmt.Sprintf("Request: %s", preview.Target))
+	keys := make([]string, 0, len(preview.Headers))
+	for key := range preview.Headers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		log.Faint(fmt.Sprintf("%s: %s", key, preview.Headers[key]))
+	}
+	for line := range strings.SplitSeq(preview.Body, "\n") {
+		log.Faint(line)
+	}
+}
+
+// newRunSummary creates a run summary if -summary or -spoken-summary is set,
+// the run is recorded in the history, or progress events are reported
+func newRunSummary(cfg config.Config) *summary.Summary {
+	if (cfg.Summary.Path == "" && !cfg.Summary.Spoken && !recordsHistory(cfg) && cfg.Progress == nil) || cfg.Commands.DryRun {
+		return nil
+	}
+	return summary.New(cfg.Provider)
+}
+
+// writeRunSummary writes the run summary to the -summary path.
+// Links are relative to the summary file, or to the output directory under -summary-url.
+func writeRunSummary(cfg config.Config, sum *summary.Summary, log logger.LoggerInterface) {
+	if sum == nil {
+		return
+	}
+	sum.Finish()
+	if cfg.Summary.Path == "" {
+		return
+	}
+
+	opts := summary.Options{BaseDir: filepath.Dir(cfg.Summary.Path), BaseURL: cfg.Summary.BaseURL}
+	if cfg.Summary.BaseURL != "" && !parser.IsOutputTemplate(cfg.OutputDir) {
+		opts.BaseDir = cfg.OutputDir
+	}
+
+	if err := sum.WriteFile(cfg.Summary.Path, opts); err != nil {
+		log.Warning(fmt.Sprintf("Could not write run summary: %v", err))
+		return
+	}
+	log.Info("Run summary:", cfg.Summary.Path)
+}
+
+// buildPronunciationReport scans all sections for tokens likely to be mispronounced
+func buildPronunciationReport(sections []parser.Section) *pronounce.Report {
+	report := pronounce.NewReport()
+	for _, section := range sections {
+		report.Add(section.Title, section.Content)
+	}
+	return report
+}
+
+// writePronunciationReport writes the pronunciation report into outputDir
+// and returns its path and the number of flagged tokens
+func writePronunciationReport(sections []parser.Section, outputDir string) (string, int, error) {
+	report := buildPronunciationReport(sections)
+	reportPath := filepath.Join(outputDir, PronunciationReportFile)
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	if err := pronounce.WriteReport(file, report.Findings()); err != nil {
+		return "", 0, err
+	}
+	return reportPath, report.Len(), nil
+}
+
+// logPronunciationReport prints the pronunciation report without writing files
+func logPronunciationReport(sections []parser.Section, log logger.LoggerInterface) {
+	findings := buildPronunciationReport(sections).Findings()
+	if len(findings) == 0 {
+		log.Info("No tokens likely to be mispronounced")
+		log.Blank()
+		return
+	}
+
+	log.Info(fmt.Sprintf("Tokens likely to be mispronounced (%d):", len(findings)))
+	log.WithIndent(true)
+	for _, f := range findings {
+		log.Faint(fmt.Sprintf("%s (%s, x%d)", f.Token, f.Reason, f.Count))
+	}
+	log.WithIndent(false)
+	log.Blank()
+}
+
+// logChapter announces the chapter of the section at i when it starts one (-chapters)
+func logChapter(sections []parser.Section, i int, log logger.LoggerInterface) {
+	section := sections[i]
+	if section.Chapter == 0 || (i > 0 && sections[i-1].Chapter == section.Chapter) {
+		return
+	}
+	log.Blank()
+	log.Info(fmt.Sprintf("Chapter %d:", section.Chapter), section.ChapterTitle)
+}
+
 // handleDryRun shows what would be generated without creating files
-func handleDryRun(sections []parser.Section, outputDir string, cfg config.Config, log logger.LoggerInterface) (int, int, error) {
+func handleDryRun(sections []parser.Section, cfg config.Config, generator *audio.Generator, log logger.LoggerInterface) (int, int, error) {
 	log.Hint("DRY-RUN MODE: No files will be created")
 	log.Blank()
 
 	for i, section := range sections {
+		logChapter(sections, i, log)
 		log.Blank()
 		log.Info(fmt.Sprintf("Section %d/%d:", i+1, len(sections))).WithAttrs("title", section.Title)
 
@@ -220,19 +978,16 @@ func handleDryRun(sections []parser.Section, outputDir string, cfg config.Config
 		log.Hint(fmt.Sprintf("Text: %s", preview))
 		log.WithIndent(false)
 
-		// Show what would be generated
-		safeTitle := section.Title
-		// Simple sanitization for preview
-		safeTitle = strings.ReplaceAll(safeTitle, " ", "_")
-		safeTitle = strings.ToLower(safeTitle)
-		if len(safeTitle) > 50 {
-			safeTitle = safeTitle[:50]
-		}
-		outputFile := fmt.Sprintf("%s/%s_%02d_%s.%s", outputDir, cfg.Prefix, i+1, safeTitle, cfg.Format)
+		// Show what would be generated, named as a real run would
+		outputFile := generator.OutputBase(section, section.Index) + "." + cfg.OutputFormat()
 
 		log.WithIndent(true)
 		log.Faint(fmt.Sprintf("Would create: %s", outputFile))
 		log.WithIndent(false)
+
+		if cfg.Commands.DryRunRequests {
+			logRequestPreview(generator, section, section.Index, log)
+		}
 	}
 
 	log.Blank()
@@ -1,15 +1,27 @@
 package processor
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/indaco/md2audio/internal/audio"
 	"github.com/indaco/md2audio/internal/config"
 	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/postprocess"
+	"github.com/indaco/md2audio/internal/progress"
+	"github.com/indaco/md2audio/internal/testhelpers"
+	"github.com/indaco/md2audio/internal/transform"
+	"github.com/indaco/md2audio/internal/utils"
 )
 
 func TestProcessFile(t *testing.T) {
@@ -631,3 +643,911 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestProcessLanguages(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputDir := filepath.Join(tmpDir, "docs")
+
+	files := map[string]string{
+		"en/guide.md": "## Welcome\nHello and welcome.",
+		"es/guide.md": "## Bienvenida\nHola y bienvenidos.",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(inputDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file %s: %v", path, err)
+		}
+	}
+
+	outputDir := filepath.Join(tmpDir, "out")
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format:    "mp3",
+		Prefix:    "section",
+		Languages: []string{"en", "es", "fr"}, // fr has no input directory and is skipped
+		Commands: config.CommandFlags{
+			DryRun: true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	if err := ProcessLanguages(cfg, log); err != nil {
+		t.Fatalf("ProcessLanguages() error = %v", err)
+	}
+
+	for _, lang := range []string{"en", "es"} {
+		if _, err := os.Stat(filepath.Join(outputDir, lang, "guide")); err != nil {
+			t.Errorf("Expected output directory for language %s: %v", lang, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "fr")); !os.IsNotExist(err) {
+		t.Error("Expected no output directory for missing language fr")
+	}
+}
+
+func TestProcessLanguagesNoDirectories(t *testing.T) {
+	cfg := config.Config{
+		InputDir:  t.TempDir(),
+		OutputDir: t.TempDir(),
+		Provider:  "say",
+		Languages: []string{"de"},
+	}
+
+	log := logger.NewDefaultLogger()
+	if err := ProcessLanguages(cfg, log); err == nil {
+		t.Error("ProcessLanguages() should error when no language directories exist")
+	}
+}
+
+func TestWritePronunciationReport(t *testing.T) {
+	outputDir := t.TempDir()
+	sections := []parser.Section{
+		{Title: "Intro", Content: "The API returns JSON."},
+		{Title: "Setup", Content: "Set max_retries in the API client."},
+	}
+
+	reportPath, count, err := writePronunciationReport(sections, outputDir)
+	if err != nil {
+		t.Fatalf("writePronunciationReport() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 flagged tokens, got %d", count)
+	}
+	if reportPath != filepath.Join(outputDir, PronunciationReportFile) {
+		t.Errorf("Unexpected report path: %s", reportPath)
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	for _, want := range []string{"API", "JSON", "max_retries", "Intro, Setup"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Report missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestManifestEntry(t *testing.T) {
+	base := manifest.Entry{Source: "/docs/guide.md", Index: 1, Title: "Intro"}
+	planned := "out/section_01_intro.aiff"
+
+	tests := []struct {
+		name       string
+		result     audio.Result
+		err        error
+		wantStatus manifest.Status
+		wantOutput string
+		wantReason string
+	}{
+		{
+			name:       "ok",
+			result:     audio.Result{OutputPath: "out/section_01_intro.m4a"},
+			wantStatus: manifest.StatusOK,
+			wantOutput: "out/section_01_intro.m4a",
+		},
+		{
+			name:       "flagged",
+			result:     audio.Result{OutputPath: "out/section_01_intro.m4a", Flagged: true, FlagReason: "word error rate 40% exceeds 25%"},
+			wantStatus: manifest.StatusFlagged,
+			wantOutput: "out/section_01_intro.m4a",
+			wantReason: "word error rate 40% exceeds 25%",
+		},
+		{
+			name:       "failed",
+			err:        fmt.Errorf("provider timeout"),
+			wantStatus: manifest.StatusFailed,
+			wantOutput: planned,
+			wantReason: "provider timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := manifestEntry(base, planned, tt.result, tt.err)
+			if entry.Status != tt.wantStatus || entry.Output != tt.wantOutput || entry.Reason != tt.wantReason {
+				t.Errorf("manifestEntry() = %+v", entry)
+			}
+			if entry.Title != base.Title || entry.Index != base.Index {
+				t.Errorf("manifestEntry() lost section fields: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "audio")
+	log := logger.NewDefaultLogger()
+
+	if newRunSummary(config.Config{}) != nil {
+		t.Error("Expected no summary without -summary")
+	}
+	if newRunSummary(config.Config{Summary: config.SummaryConfig{Path: "s.md"}, Commands: config.CommandFlags{DryRun: true}}) != nil {
+		t.Error("Expected no summary in dry-run mode")
+	}
+
+	cfg := config.Config{
+		Provider:  "say",
+		OutputDir: outputDir,
+		Summary:   config.SummaryConfig{Path: filepath.Join(dir, "summary.md"), BaseURL: "http://localhost:8080/"},
+	}
+	sum := newRunSummary(cfg)
+	if sum == nil {
+		t.Fatal("Expected summary with -summary")
+	}
+	sum.AddFile()
+	sum.Add(manifest.Entry{Source: "guide.md", Title: "Intro", Output: filepath.Join(outputDir, "guide", "section_01_intro.aiff"), Status: manifest.StatusOK}, 12)
+
+	writeRunSummary(cfg, sum, log)
+
+	data, err := os.ReadFile(cfg.Summary.Path)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	if !strings.Contains(string(data), "(http://localhost:8080/guide/section_01_intro.aiff)") {
+		t.Errorf("Expected output link under the base URL:\n%s", data)
+	}
+}
+
+func TestProcessFileDryRunRequests(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	content := `## Intro
+
+Welcome to the demo.
+`
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{
+		Provider: "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key-abcd",
+			VoiceID: "voice-123",
+		},
+		Format: "mp3",
+		Prefix: "section",
+		Commands: config.CommandFlags{
+			DryRun:         true,
+			DryRunRequests: true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, filepath.Join(tmpDir, "output"), cfg, log); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{"Request: POST https://api.elevenlabs.io/v1/text-to-speech/voice-123", "xi-api-key: ****abcd", `"text": "Welcome to the demo."`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "test-key-abcd") {
+		t.Error("API key must not be printed")
+	}
+}
+
+func TestProcessFileDryRunChapters(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "course.md")
+	content := `## Getting Started
+
+Welcome.
+
+### Install
+
+Run the installer.
+`
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	cfg := config.Config{
+		Provider:    "say",
+		SilenceOnly: true,
+		Format:      "wav",
+		Prefix:      "section",
+		Chapters:    true,
+		Commands:    config.CommandFlags{DryRun: true},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, outputDir, cfg, log); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{
+		filepath.Join(outputDir, "01_getting_started", "section_01_01_getting_started.wav"),
+		filepath.Join(outputDir, "01_getting_started", "section_01_02_install.wav"),
+	} {
+		if !strings.Contains(output, "Would create: "+want) {
+			t.Errorf("Output missing %q:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "01_getting_started")); !os.IsNotExist(err) {
+		t.Error("Dry-run mode should not create chapter directories")
+	}
+}
+
+func TestProcessDirectoryLimits(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	content := `## One
+
+First.
+
+## Two
+
+Second.
+
+## Three
+
+Third.
+`
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format:        "mp3",
+		Prefix:        "section",
+		Limit:         2,
+		LimitSections: 1,
+		Commands: config.CommandFlags{
+			DryRun: true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, log); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("Failed to read output directory: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 processed files, got %d", len(entries))
+	}
+	if got := strings.Count(output, "Would generate 1 audio files"); got != 2 {
+		t.Errorf("Expected 1 section per file, got output:\n%s", output)
+	}
+}
+
+func TestProcessDirectoryOrder(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, name := range []string{"b", "A", "c"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte("## Title\n\nText.\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  string
+	}{
+		{order: "alpha", want: "Processing order: alpha"},
+		{order: "shuffle(5)", want: "Processing order: shuffle(5)"},
+		{order: "shuffle", want: "Processing order: shuffle(11)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			cfg := config.Config{
+				InputDir:  inputDir,
+				OutputDir: outputDir,
+				Provider:  "elevenlabs",
+				ElevenLabs: config.ElevenLabsConfig{
+					APIKey:  "test-key",
+					VoiceID: "default-voice",
+				},
+				Format: "mp3",
+				Prefix: "section",
+				Order:  tt.order,
+				Seed:   11,
+				Limit:  1,
+				Commands: config.CommandFlags{
+					DryRun: true,
+				},
+			}
+
+			log := logger.NewDefaultLogger()
+			output, err := testhelpers.CaptureStdout(func() {
+				if err := ProcessDirectory(cfg, log); err != nil {
+					t.Errorf("ProcessDirectory() error = %v", err)
+				}
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("Expected %q in output:\n%s", tt.want, output)
+			}
+			if tt.order == "alpha" && !strings.Contains(output, "A.md") {
+				t.Errorf("Expected A.md to be processed first, got output:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestProcessDirectoryOrderFile(t *testing.T) {
+	inputDir := t.TempDir()
+	for _, name := range []string{"chapter10", "chapter2", "intro"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte("## "+name+"\n\nText.\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{InputDir: inputDir, Order: "doc", Provider: "say", Say: config.SayConfig{Rate: 180}}
+	export := func() []string {
+		cfg.Commands.ExportSections = filepath.Join(t.TempDir(), "sections.json")
+		if _, err := testhelpers.CaptureStdout(func() {
+			if err := ExportSections(cfg, logger.NewDefaultLogger()); err != nil {
+				t.Errorf("ExportSections() error = %v", err)
+			}
+		}); err != nil {
+			t.Fatalf("Failed to capture output: %v", err)
+		}
+		data, err := os.ReadFile(cfg.Commands.ExportSections)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sections []ExportedSection
+		if err := json.Unmarshal(data, &sections); err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for _, section := range sections {
+			files = append(files, section.File)
+		}
+		return files
+	}
+
+	cfg.Order = "natural"
+	if got, want := export(), []string{"chapter2.md", "chapter10.md", "intro.md"}; !slices.Equal(got, want) {
+		t.Errorf("natural order = %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(inputDir, "_order.yaml"), []byte("- intro.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Order = "doc"
+	if got, want := export(), []string{"intro.md", "chapter2.md", "chapter10.md"}; !slices.Equal(got, want) {
+		t.Errorf("_order.yaml order = %v, want %v", got, want)
+	}
+}
+
+func TestProcessDirectoryMaxDuration(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte("## Title\n\nText.\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format:      "mp3",
+		Prefix:      "section",
+		NewestFirst: true,
+		MaxDuration: time.Nanosecond,
+		Commands: config.CommandFlags{
+			DryRun: true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, log); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{"Processing order: mtime", "Time budget of 1ns reached", "Skipped 3 file(s)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Would generate") {
+		t.Errorf("Expected no sections after the budget was spent, got output:\n%s", output)
+	}
+}
+
+func TestProcessFileTransforms(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("## Intro\n\nDeploy to k8s.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	expand := func(s parser.Section) (parser.Section, error) {
+		s.Content = strings.ReplaceAll(s.Content, "k8s", "Kubernetes")
+		return s, nil
+	}
+
+	tests := []struct {
+		name         string
+		transforms   []transform.Func
+		transformCmd string
+		want         string
+		wantErr      bool
+	}{
+		{name: "library transform", transforms: []transform.Func{expand}, want: `"text": "Deploy to Kubernetes."`},
+		{name: "transform command after library transforms", transforms: []transform.Func{expand}, transformCmd: "tr a-z A-Z", want: `"text": "DEPLOY TO KUBERNETES."`},
+		{name: "missing transform command", transformCmd: "md2audio-no-such-command", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{
+				Provider:     "elevenlabs",
+				ElevenLabs:   config.ElevenLabsConfig{APIKey: "test-key", VoiceID: "voice-123"},
+				Format:       "mp3",
+				Prefix:       "section",
+				Transforms:   tt.transforms,
+				TransformCmd: tt.transformCmd,
+				Commands:     config.CommandFlags{DryRun: true, DryRunRequests: true},
+			}
+
+			var processErr error
+			output, err := testhelpers.CaptureStdout(func() {
+				processErr = ProcessFile(mdFile, filepath.Join(tmpDir, "output"), cfg, logger.NewDefaultLogger())
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if tt.wantErr {
+				if processErr == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if processErr != nil {
+				t.Fatalf("ProcessFile() error = %v", processErr)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("Output missing %q:\n%s", tt.want, output)
+			}
+		})
+	}
+}
+
+func TestProcessFileEmptySection(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("## Architecture\n\n![Diagram](arch.png)\n\n## Usage\n\nRun it.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{
+		Provider:     "elevenlabs",
+		ElevenLabs:   config.ElevenLabsConfig{APIKey: "test-key", VoiceID: "voice-123"},
+		Format:       "mp3",
+		Prefix:       "section",
+		EmptySection: "This section contains a diagram.",
+		Commands:     config.CommandFlags{DryRun: true},
+	}
+
+	var processErr error
+	output, err := testhelpers.CaptureStdout(func() {
+		processErr = ProcessFile(mdFile, filepath.Join(tmpDir, "output"), cfg, logger.NewDefaultLogger())
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+	if processErr != nil {
+		t.Fatalf("ProcessFile() error = %v", processErr)
+	}
+	for _, want := range []string{"section_01_architecture", "This section contains a diagram.", "section_02_usage"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestPostProcessors(t *testing.T) {
+	noop := func(ctx context.Context, file postprocess.File) error { return nil }
+
+	hooks, err := postProcessors(config.Config{PostProcessors: []postprocess.Func{noop}, PostCmd: "true"})
+	if err != nil {
+		t.Fatalf("postProcessors() error = %v", err)
+	}
+	if len(hooks) != 2 {
+		t.Errorf("got %d post-processors, want the library hook and -post-cmd", len(hooks))
+	}
+	if _, err := postProcessors(config.Config{PostCmd: "md2audio-no-such-command"}); err == nil {
+		t.Error("expected error for a missing -post-cmd")
+	}
+
+	if postProcesses(config.Config{}) {
+		t.Error("expected no post-processing by default")
+	}
+	if !postProcesses(config.Config{PostCmd: "true"}) {
+		t.Error("expected post-processing with -post-cmd")
+	}
+}
+
+func TestProcessFileRedact(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("## Support\n\nEmail jane.doe@example.com for help.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{
+		Provider:   "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{APIKey: "test-key", VoiceID: "voice-123"},
+		Format:     "mp3",
+		Prefix:     "section",
+		Redact:     "verbalize",
+		Commands:   config.CommandFlags{DryRun: true, DryRunRequests: true},
+	}
+
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, filepath.Join(tmpDir, "output"), cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{`"text": "Email an email address for help."`, "Redacted 1 item(s) before synthesis", "email jane******** in test.md: Support (#1)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "example.com") {
+		t.Error("redacted text must not be printed")
+	}
+}
+
+func TestWritePlaceholder(t *testing.T) {
+	section := parser.Section{Title: "Intro", Index: 1, Content: "Hello.", HasTiming: true, Duration: 3}
+
+	tests := []struct {
+		name        string
+		placeholder string
+		want        bool
+	}{
+		{name: "disabled", placeholder: "", want: false},
+		{name: "silence", placeholder: "silence", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			cfg := config.Config{Format: "wav", Prefix: "section", Placeholder: tt.placeholder}
+			log := logger.NewDefaultLogger()
+			generator := audio.NewGenerator(audio.GeneratorConfig{OutputDir: outputDir, Format: "wav", Prefix: "section"}, log)
+
+			planned := filepath.Join(outputDir, "section_01_intro.wav")
+			entry := manifestEntry(manifest.Entry{Title: "Intro", Index: 1}, planned, audio.Result{}, fmt.Errorf("provider timeout"))
+			entry = writePlaceholder(generator, section, entry, cfg, log)
+
+			if entry.Placeholder != tt.want || entry.Status != manifest.StatusFailed {
+				t.Errorf("writePlaceholder() = %+v", entry)
+			}
+			if _, err := os.Stat(planned); (err == nil) != tt.want {
+				t.Errorf("Placeholder file exists = %v, want %v", err == nil, tt.want)
+			}
+
+			// A later successful generation clears the placeholder mark
+			if entry = manifestEntry(entry, planned, audio.Result{OutputPath: planned}, nil); entry.Placeholder {
+				t.Errorf("manifestEntry() kept placeholder mark: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestProcessFileSilenceOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	content := "## Intro (3s)\n\nWelcome to the demo.\n\n## Outro (1.5s)\n\nThanks for watching.\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	outputDir := filepath.Join(tmpDir, "output")
+
+	// No API key or voice: silent scaffolds never call the provider
+	cfg := config.Config{
+		Provider:    "elevenlabs",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+	}
+
+	log := logger.NewDefaultLogger()
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, outputDir, cfg, log); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for name, want := range map[string]float64{"section_01_intro.wav": 3, "section_02_outro.wav": 1.5} {
+		duration, err := utils.GetWAVDuration(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Expected silent scaffold %s: %v", name, err)
+		}
+		if duration < want-0.001 || duration > want+0.001 {
+			t.Errorf("%s duration = %.3f, want %.3f", name, duration, want)
+		}
+	}
+
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if m.Settings == nil || m.Settings.Provider != "silence" {
+		t.Errorf("manifest settings = %+v, want the silence provider", m.Settings)
+	}
+	for _, entry := range m.Entries {
+		if entry.Fingerprint == "" || entry.Loudness == nil || entry.Loudness.Peak != utils.SilenceFloorDB {
+			t.Errorf("entry %s fingerprint = %q, loudness = %+v, want silent audio fingerprinted", entry.Title, entry.Fingerprint, entry.Loudness)
+		}
+	}
+}
+
+func TestProcessFileStartIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "output")
+	for name, content := range map[string]string{
+		"part1.md": "## Intro (1s)\n\nWelcome.\n\n## Setup (1s)\n\nInstall it.\n",
+		"part2.md": "## Usage (1s)\n\nRun it.\n",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{Provider: "elevenlabs", Format: "wav", Prefix: "section", SilenceOnly: true, StartIndex: config.StartIndexContinue}
+	log := logger.NewDefaultLogger()
+	// part1 is processed again last and keeps its numbers
+	for _, name := range []string{"part1.md", "part2.md", "part1.md"} {
+		if _, err := testhelpers.CaptureStdout(func() {
+			if err := ProcessFile(filepath.Join(tmpDir, name), outputDir, cfg, log); err != nil {
+				t.Errorf("ProcessFile(%s) error = %v", name, err)
+			}
+		}); err != nil {
+			t.Fatalf("Failed to capture output: %v", err)
+		}
+	}
+
+	for _, name := range []string{"section_01_intro.wav", "section_02_setup.wav", "section_03_usage.wav"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if len(m.Entries) != 3 {
+		t.Fatalf("manifest has %d entries, want 3", len(m.Entries))
+	}
+	for _, entry := range m.Entries {
+		if entry.Title == "Usage" && (entry.Index != 1 || entry.Number != 3) {
+			t.Errorf("Usage entry index %d, number %d, want index 1 and number 3", entry.Index, entry.Number)
+		}
+	}
+}
+
+func TestProcessFileProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	if err := os.WriteFile(mdFile, []byte("## Intro (1s)\n\nWelcome.\n\n## Outro (2s)\n\nThanks.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var events []progress.Event
+	cfg := config.Config{
+		Provider:    "elevenlabs",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+		Progress:    func(event progress.Event) { events = append(events, event) },
+	}
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, filepath.Join(tmpDir, "output"), cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	var kinds []string
+	for _, event := range events {
+		kinds = append(kinds, string(event.Kind))
+	}
+	want := []string{"file_started", "section_generated", "section_generated", "run_completed"}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("events = %v, want %v", kinds, want)
+	}
+	if events[0].Sections != 2 || events[2].Title != "Outro" || events[2].Duration != 2 {
+		t.Errorf("unexpected events: %+v", events[:3])
+	}
+	if sum := events[3].Summary; sum == nil || sum.Generated != 2 || sum.AudioSeconds != 3 {
+		t.Errorf("run_completed summary = %+v", sum)
+	}
+}
+
+func TestProcessDirectoryManifestOrder(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, name := range []string{"chapter10", "chapter2"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte("## Intro (1s)\n\nText.\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{
+		InputDir:    inputDir,
+		OutputDir:   outputDir,
+		Provider:    "say",
+		Format:      "wav",
+		Prefix:      "section",
+		Order:       "doc",
+		SilenceOnly: true,
+	}
+
+	log := logger.NewDefaultLogger()
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, log); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for name, want := range map[string]int{"chapter2": 1, "chapter10": 2} {
+		m, err := manifest.Load(manifest.PathFor(filepath.Join(outputDir, name)))
+		if err != nil {
+			t.Fatalf("Failed to load manifest of %s: %v", name, err)
+		}
+		if m.Order != "doc" {
+			t.Errorf("%s manifest order = %q, want %q", name, m.Order, "doc")
+		}
+		if len(m.Entries) != 1 || m.Entries[0].Position != want {
+			t.Errorf("%s manifest entries = %+v, want position %d", name, m.Entries, want)
+		}
+	}
+}
+
+func TestProcessFileSentenceGranularity(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "lesson.md")
+	if err := os.WriteFile(mdFile, []byte("## Intro (3s)\n\nHello there. Welcome back.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	outputDir := filepath.Join(tmpDir, "output")
+
+	cfg := config.Config{
+		Provider:    "espeak",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+		Granularity: config.GranularitySentence,
+	}
+
+	log := logger.NewDefaultLogger()
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessFile(mdFile, outputDir, cfg, log); err != nil {
+			t.Errorf("ProcessFile() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	want := []manifest.Entry{
+		{Index: 1, Sentence: 1, Title: "Intro", Output: filepath.Join(outputDir, "section_01_intro_s01.wav"), Text: "Hello there."},
+		{Index: 1, Sentence: 2, Title: "Intro", Output: filepath.Join(outputDir, "section_01_intro_s02.wav"), Text: "Welcome back."},
+	}
+	if len(m.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), m.Entries)
+	}
+	for i, entry := range m.Entries {
+		if entry.Index != want[i].Index || entry.Sentence != want[i].Sentence || entry.Output != want[i].Output || entry.Text != want[i].Text {
+			t.Errorf("Entry %d = %+v, want %+v", i, entry, want[i])
+		}
+		if _, err := os.Stat(entry.Output); err != nil {
+			t.Errorf("Expected sentence audio: %v", err)
+		}
+	}
+}
+
+func TestSectionProviders(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	cfg := config.Config{Provider: "say", Say: config.SayConfig{Voice: "Kate"}}
+
+	resolve := sectionProviders(cfg, log)
+	provider, voice, err := resolve("edge")
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+	if provider.Name() != "edge" || voice != "" {
+		t.Errorf("resolve() = %s, %q, want edge with its default voice", provider.Name(), voice)
+	}
+	if again, _, _ := resolve("edge"); again != provider {
+		t.Error("resolve() created the provider twice")
+	}
+
+	cfg.LocalOnly = true
+	if _, _, err := sectionProviders(cfg, log)("edge"); err == nil || !strings.Contains(err.Error(), "local-only") {
+		t.Errorf("resolve() error = %v, want a local-only refusal", err)
+	}
+}
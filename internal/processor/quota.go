@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/indaco/md2audio/internal/cli"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+)
+
+// ShowQuota prints the character quota of the ElevenLabs account and, when a
+// markdown file (-f) or directory (-d) is given, whether its sections fit in
+// the characters left.
+func ShowQuota(cfg config.Config, log logger.LoggerInterface) error {
+	cfg.Provider = "elevenlabs"
+	provider, err := cli.CreateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating TTS provider: %w", err)
+	}
+	client := provider.(*elevenlabs.Client)
+	client.SetLogger(log)
+
+	subscription, err := client.Subscription(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get the ElevenLabs quota: %w", err)
+	}
+
+	log.Info(fmt.Sprintf("ElevenLabs quota (%s tier)", subscription.Tier))
+	log.Faint(fmt.Sprintf("Used:      %d of %d characters", subscription.CharacterCount, subscription.CharacterLimit))
+	log.Faint(fmt.Sprintf("Remaining: %d characters", subscription.Remaining()))
+	if reset := subscription.ResetsAt(); !reset.IsZero() {
+		log.Faint(fmt.Sprintf("Resets:    %s (in %s)", reset.Local().Format("2006-01-02 15:04 MST"), formatUntil(time.Until(reset))))
+	}
+
+	if !cfg.IsDirectoryMode() && cfg.MarkdownFile == "" {
+		return nil
+	}
+	pending, sections, err := pendingCharacters(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	log.Blank()
+	message := quotaVerdict(subscription, pending, sections)
+	if pending > subscription.Remaining() {
+		log.Warning(message)
+	} else {
+		log.Success(message)
+	}
+	return nil
+}
+
+// pendingCharacters returns the characters and number of the sections a run
+// on -f or -d would send to the provider, before skipping unchanged sections
+func pendingCharacters(cfg config.Config, log logger.LoggerInterface) (int, int, error) {
+	files, err := exportFiles(cfg, log)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	characters, count := 0, 0
+	for _, file := range files {
+		sections, err := exportedSections(file, cfg)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", file.RelPath, err)
+		}
+		for _, section := range sections {
+			characters += utf8.RuneCountInString(section.Text)
+		}
+		count += len(sections)
+	}
+	return characters, count, nil
+}
+
+// quotaVerdict describes whether pending characters fit in the quota left
+func quotaVerdict(subscription *elevenlabs.Subscription, pending, sections int) string {
+	remaining := subscription.Remaining()
+	if pending > remaining {
+		return fmt.Sprintf("The run needs %d characters (%d section(s)), %d more than remain; it would not fit in the quota", pending, sections, pending-remaining)
+	}
+	return fmt.Sprintf("The run needs %d characters (%d section(s)) and fits in the quota, leaving %d", pending, sections, remaining-pending)
+}
+
+// formatUntil formats a duration until a date in days or hours
+func formatUntil(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return "less than an hour"
+	}
+}
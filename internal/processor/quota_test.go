@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/tts/elevenlabs"
+)
+
+func TestPendingCharacters(t *testing.T) {
+	mdFile := filepath.Join(t.TempDir(), "video.md")
+	content := "## Intro (2s)\n\nHello **world**.\n\n## Café\n\nVoilà tout.\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.Config{MarkdownFile: mdFile, Provider: "elevenlabs"}
+	characters, sections, err := pendingCharacters(cfg, logger.NewDefaultLogger())
+	if err != nil {
+		t.Fatalf("pendingCharacters() error = %v", err)
+	}
+	// "Hello world." and "Voilà tout." are counted in characters, not bytes
+	if characters != 23 || sections != 2 {
+		t.Errorf("pendingCharacters() = %d characters in %d sections, want 23 in 2", characters, sections)
+	}
+}
+
+func TestQuotaVerdict(t *testing.T) {
+	subscription := &elevenlabs.Subscription{CharacterCount: 9000, CharacterLimit: 10000}
+
+	if got := quotaVerdict(subscription, 400, 3); !strings.Contains(got, "fits in the quota, leaving 600") {
+		t.Errorf("quotaVerdict() = %q, want a fitting run", got)
+	}
+	if got := quotaVerdict(subscription, 1500, 3); !strings.Contains(got, "500 more than remain") {
+		t.Errorf("quotaVerdict() = %q, want an exceeded quota", got)
+	}
+}
+
+func TestFormatUntil(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 72 * time.Hour, want: "3 days"},
+		{d: 30 * time.Hour, want: "30 hours"},
+		{d: 10 * time.Minute, want: "less than an hour"},
+		{d: -time.Hour, want: "less than an hour"},
+	}
+	for _, tt := range tests {
+		if got := formatUntil(tt.d); got != tt.want {
+			t.Errorf("formatUntil(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
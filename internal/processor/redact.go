@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/redact"
+)
+
+// RedactionReportFile is the report written to each output directory by -redact
+const RedactionReportFile = "redaction_report.txt"
+
+// redactSections masks or verbalizes profanity and sensitive data in the
+// section text with -redact, returning the redactions made
+func redactSections(sections []parser.Section, markdownFile string, cfg config.Config) ([]parser.Section, []redact.Redaction) {
+	if cfg.Redact == "" {
+		return sections, nil
+	}
+
+	var redactions []redact.Redaction
+	result := make([]parser.Section, len(sections))
+	for i, section := range sections {
+		var found []redact.Redaction
+		section.Content, found = redact.Redact(section.Content, redact.Mode(cfg.Redact))
+		for _, r := range found {
+			r.Source = fmt.Sprintf("%s: %s (#%d)", filepath.Base(markdownFile), section.Title, section.Index)
+			redactions = append(redactions, r)
+		}
+		result[i] = section
+	}
+	return result, redactions
+}
+
+// writeRedactionReport writes the redaction report into outputDir and returns its path
+func writeRedactionReport(redactions []redact.Redaction, outputDir string) (string, error) {
+	reportPath := filepath.Join(outputDir, RedactionReportFile)
+
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := redact.WriteReport(file, redactions); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
+
+// logRedactions prints the redactions without writing files
+func logRedactions(redactions []redact.Redaction, log logger.LoggerInterface) {
+	if len(redactions) == 0 {
+		return
+	}
+
+	log.Warning(fmt.Sprintf("Redacted %d item(s) before synthesis:", len(redactions)))
+	log.WithIndent(true)
+	for _, r := range redactions {
+		log.Faint(fmt.Sprintf("%s %s in %s", r.Category, r.Preview, r.Source))
+	}
+	log.WithIndent(false)
+	log.Blank()
+}
@@ -0,0 +1,212 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// DefaultRerunStatuses are the manifest statuses regenerated when -only is not set
+var DefaultRerunStatuses = []manifest.Status{manifest.StatusFailed, manifest.StatusFlagged, manifest.StatusSkipped}
+
+// ProcessManifest regenerates the manifest entries selected by -only,
+// writing each section back to its original output path and updating
+// the entry statuses in the manifest.
+func ProcessManifest(cfg config.Config, log logger.LoggerInterface) error {
+	m, err := manifest.Load(cfg.Rerun.Manifest)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := rerunStatuses(cfg.Rerun.Only)
+	if err != nil {
+		return err
+	}
+
+	entries := m.Filter(statuses...)
+	if len(entries) == 0 {
+		log.Success(fmt.Sprintf("No %s entries to regenerate in %s", joinStatuses(statuses), cfg.Rerun.Manifest))
+		return nil
+	}
+
+	if !cfg.Commands.DryRun {
+		if err := checkSettings(m, filepath.Dir(cfg.Rerun.Manifest), cfg, log); err != nil {
+			return err
+		}
+	}
+
+	log.Info(fmt.Sprintf("Regenerating %d %s section(s) from %s", len(entries), joinStatuses(statuses), cfg.Rerun.Manifest))
+	if cfg.Commands.DryRun {
+		log.Hint("DRY-RUN MODE: No files will be created")
+	}
+	log.Blank()
+
+	rs := newRunState(cfg)
+	generators := make(map[string]*audio.Generator)
+	sources := make(map[string][]parser.Section)
+	successCount := 0
+	flaggedCount := 0
+
+	regenerated := 0
+	for i, entry := range entries {
+		// Remaining entries keep their status, so the re-run can be resumed
+		if rs.budgetSpent(cfg, log) != "" {
+			break
+		}
+		regenerated++
+
+		log.Blank()
+		log.Info(fmt.Sprintf("Section %d/%d:", i+1, len(entries))).WithAttrs("title", entry.Title)
+
+		section, err := findSection(sources, entry, cfg)
+		if err == nil {
+			var transformed []parser.Section
+			if transformed, err = transformSections([]parser.Section{section}, cfg); err == nil {
+				transformed, redactions := redactSections(transformed, entry.Source, cfg)
+				logRedactions(redactions, log)
+				section = transformed[0]
+			}
+		}
+		if err == nil && entry.Sentence > 0 {
+			section, err = sentenceOf(section, entry.Sentence)
+		}
+		if err != nil {
+			log.Error("Failed:", err)
+			entry = manifestEntry(entry, entry.Output, audio.Result{}, err)
+			m.Put(entry)
+			rs.addEntry(entry, cfg.Rerun.Manifest, 0)
+			continue
+		}
+
+		basePath := strings.TrimSuffix(entry.Output, filepath.Ext(entry.Output))
+		if cfg.Commands.DryRun {
+			log.WithIndent(true)
+			log.Faint(fmt.Sprintf("Would regenerate: %s.%s", basePath, cfg.OutputFormat()))
+			log.WithIndent(false)
+			continue
+		}
+
+		outputDir := filepath.Dir(entry.Output)
+		generator, ok := generators[outputDir]
+		if !ok {
+			generator, err = newGenerator(cfg, outputDir, log)
+			if err != nil {
+				return err
+			}
+			generators[outputDir] = generator
+		}
+
+		rs.apiCalls++
+		rs.characters += utf8.RuneCountInString(section.Content)
+		result, err := generator.GenerateSectionAs(section, basePath)
+		entry = manifestEntry(entry, entry.Output, result, err)
+		if err != nil {
+			log.Error("Failed:", err)
+			entry = writePlaceholder(generator, section, entry, cfg, log)
+		} else {
+			entry.Text = section.Content
+			tagAudio(result.OutputPath, *m.Settings, cfg, log)
+			entry = fingerprintEntry(entry, log)
+			writeVariants(result.OutputPath, cfg, log)
+		}
+		m.Put(entry)
+		rs.addEntry(entry, cfg.Rerun.Manifest, result.Duration)
+		if err != nil {
+			continue
+		}
+		rs.stats.addDuration(section, result.Duration)
+		successCount++
+		if result.Flagged {
+			flaggedCount++
+		}
+	}
+
+	log.Blank()
+	if cfg.Commands.DryRun {
+		log.Success(fmt.Sprintf("Would regenerate %d audio files", len(entries)))
+		return nil
+	}
+
+	if err := m.Save(cfg.Rerun.Manifest); err != nil {
+		return err
+	}
+
+	log.Success(fmt.Sprintf("Complete! Regenerated %d/%d audio files", successCount, len(entries)))
+	if remaining := len(entries) - regenerated; remaining > 0 {
+		log.Warning(fmt.Sprintf("%d section(s) left for the next run after reaching the run budget", remaining))
+	}
+	if flaggedCount > 0 {
+		log.Warning(fmt.Sprintf("%d section(s) flagged by verification", flaggedCount))
+	}
+	log.Info("Manifest updated:", cfg.Rerun.Manifest)
+	finishRun(cfg, rs, modeManifest, cfg.Rerun.Manifest, log)
+
+	return nil
+}
+
+// rerunStatuses converts -only values into manifest statuses
+func rerunStatuses(only []string) ([]manifest.Status, error) {
+	if len(only) == 0 {
+		return DefaultRerunStatuses, nil
+	}
+
+	statuses := make([]manifest.Status, 0, len(only))
+	for _, value := range only {
+		status, err := manifest.ParseStatus(value)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// joinStatuses formats statuses for log messages (e.g., "failed/flagged")
+func joinStatuses(statuses []manifest.Status) string {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return strings.Join(names, "/")
+}
+
+// sentenceOf returns sentence n (1-based) of a section, for entries written with -granularity sentence
+func sentenceOf(section parser.Section, n int) (parser.Section, error) {
+	sentences := section.Sentences()
+	if n > len(sentences) {
+		return parser.Section{}, fmt.Errorf("sentence %d of section %q not found", n, section.Title)
+	}
+	return sentences[n-1], nil
+}
+
+// findSection returns the section an entry was generated from.
+// Sections are matched by index, falling back to the title if the
+// source file was edited since the manifest was written.
+func findSection(sources map[string][]parser.Section, entry manifest.Entry, cfg config.Config) (parser.Section, error) {
+	sections, ok := sources[entry.Source]
+	if !ok {
+		var err error
+		sections, err = parseMarkdownFile(entry.Source, cfg)
+		if err != nil {
+			return parser.Section{}, fmt.Errorf("error parsing markdown: %w", err)
+		}
+		sources[entry.Source] = sections
+	}
+
+	if entry.Index >= 1 && entry.Index <= len(sections) && sections[entry.Index-1].Title == entry.Title {
+		return sections[entry.Index-1], nil
+	}
+	for _, section := range sections {
+		if section.Title == entry.Title {
+			return section, nil
+		}
+	}
+	return parser.Section{}, fmt.Errorf("section %q not found in %s", entry.Title, entry.Source)
+}
@@ -0,0 +1,161 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// writeRerunFixture writes a markdown file and a manifest with one entry per status
+func writeRerunFixture(t *testing.T) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "guide.md")
+	content := `## Intro
+
+Welcome to the guide.
+
+## Setup
+
+Install the tool.
+
+## Usage
+
+Run the tool.
+`
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outputDir := filepath.Join(dir, "audio")
+	m := manifest.New()
+	m.Put(manifest.Entry{Source: mdFile, Index: 1, Title: "Intro", Output: filepath.Join(outputDir, "section_01_intro.mp3"), Status: manifest.StatusOK})
+	m.Put(manifest.Entry{Source: mdFile, Index: 2, Title: "Setup", Output: filepath.Join(outputDir, "section_02_setup.mp3"), Status: manifest.StatusFailed, Reason: "timeout"})
+	m.Put(manifest.Entry{Source: mdFile, Index: 3, Title: "Usage", Output: filepath.Join(outputDir, "section_03_usage.mp3"), Status: manifest.StatusFlagged, Reason: "word error rate 50% exceeds 25%"})
+
+	manifestPath := filepath.Join(dir, manifest.FileName)
+	if err := m.Save(manifestPath); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+	return mdFile, manifestPath
+}
+
+func TestProcessManifestDryRun(t *testing.T) {
+	_, manifestPath := writeRerunFixture(t)
+
+	tests := []struct {
+		name string
+		only []string
+	}{
+		{name: "default statuses", only: nil},
+		{name: "only failed", only: []string{"failed"}},
+		{name: "only flagged", only: []string{"flagged"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{
+				Provider: "elevenlabs",
+				ElevenLabs: config.ElevenLabsConfig{
+					APIKey:  "test-key",
+					VoiceID: "default-voice",
+				},
+				Format: "mp3",
+				Prefix: "section",
+				Rerun:  config.RerunConfig{Manifest: manifestPath, Only: tt.only},
+				Commands: config.CommandFlags{
+					DryRun: true,
+				},
+			}
+
+			log := logger.NewDefaultLogger()
+			if err := ProcessManifest(cfg, log); err != nil {
+				t.Fatalf("ProcessManifest() error = %v", err)
+			}
+
+			// Dry-run leaves the manifest untouched
+			m, err := manifest.Load(manifestPath)
+			if err != nil {
+				t.Fatalf("Failed to load manifest: %v", err)
+			}
+			if m.Count(manifest.StatusFailed) != 1 || m.Count(manifest.StatusFlagged) != 1 {
+				t.Errorf("Dry-run should not change manifest statuses: %+v", m.Entries)
+			}
+		})
+	}
+}
+
+func TestProcessManifestErrors(t *testing.T) {
+	_, manifestPath := writeRerunFixture(t)
+	log := logger.NewDefaultLogger()
+
+	if err := ProcessManifest(config.Config{Rerun: config.RerunConfig{Manifest: filepath.Join(t.TempDir(), "missing.json")}}, log); err == nil {
+		t.Error("ProcessManifest() should fail for a missing manifest")
+	}
+	if err := ProcessManifest(config.Config{Rerun: config.RerunConfig{Manifest: manifestPath, Only: []string{"pending"}}}, log); err == nil {
+		t.Error("ProcessManifest() should fail for an invalid status")
+	}
+}
+
+func TestFindSection(t *testing.T) {
+	mdFile, _ := writeRerunFixture(t)
+
+	tests := []struct {
+		name      string
+		entry     manifest.Entry
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name:      "matched by index",
+			entry:     manifest.Entry{Source: mdFile, Index: 2, Title: "Setup"},
+			wantTitle: "Setup",
+		},
+		{
+			name:      "moved section matched by title",
+			entry:     manifest.Entry{Source: mdFile, Index: 1, Title: "Usage"},
+			wantTitle: "Usage",
+		},
+		{
+			name:    "removed section",
+			entry:   manifest.Entry{Source: mdFile, Index: 4, Title: "FAQ"},
+			wantErr: true,
+		},
+		{
+			name:    "missing source",
+			entry:   manifest.Entry{Source: filepath.Join(t.TempDir(), "missing.md"), Index: 1, Title: "Intro"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section, err := findSection(make(map[string][]parser.Section), tt.entry, config.Config{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findSection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if section.Title != tt.wantTitle {
+				t.Errorf("findSection() title = %q, want %q", section.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestSentenceOf(t *testing.T) {
+	section := parser.Section{Index: 1, Title: "Intro", Content: "Hello there. Welcome back."}
+
+	sentence, err := sentenceOf(section, 2)
+	if err != nil || sentence.Content != "Welcome back." || sentence.Sentence != 2 {
+		t.Errorf("sentenceOf() = %+v, %v", sentence, err)
+	}
+	if _, err := sentenceOf(section, 3); err == nil || !strings.Contains(err.Error(), "sentence 3 of section \"Intro\" not found") {
+		t.Errorf("Expected missing sentence error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// Time-stretching bounds for -retime. Beyond them the audio sounds audibly
+// rushed or dragged, and the section should be regenerated instead.
+const (
+	minRetimeTempo  = 0.8
+	maxRetimeTempo  = 1.25
+	retimeTolerance = 0.02 // Relative difference below which audio already fits its timing
+)
+
+// retimeTempo returns the atempo factor fitting audio of duration current into
+// target seconds, and whether it is within the quality bounds. A tempo of 1
+// means the audio already fits.
+func retimeTempo(current, target float64) (float64, bool) {
+	if current <= 0 || target <= 0 {
+		return 0, false
+	}
+	tempo := current / target
+	if math.Abs(tempo-1) <= retimeTolerance {
+		return 1, true
+	}
+	return tempo, tempo >= minRetimeTempo && tempo <= maxRetimeTempo
+}
+
+// RetimeFile re-fits the existing audio of a markdown file's sections to their
+// updated timing annotations by time-stretching it with ffmpeg, instead of
+// regenerating it. Sections needing more than the quality bounds allow are
+// reported for regeneration.
+func RetimeFile(cfg config.Config, log logger.LoggerInterface) error {
+	if cfg.MarkdownFile == "" {
+		return fmt.Errorf("-retime requires a markdown file (-f) and its output directory (-o)")
+	}
+
+	outputDir := cfg.OutputDir
+	if parser.IsOutputTemplate(outputDir) {
+		mdFile, err := parser.NewMarkdownFile(cfg.MarkdownFile)
+		if err != nil {
+			return err
+		}
+		if outputDir, err = mdFile.ResolveOutputDir(outputDir); err != nil {
+			return err
+		}
+	}
+
+	sections, err := parseMarkdownFile(cfg.MarkdownFile, cfg)
+	if err != nil {
+		return fmt.Errorf("error parsing markdown: %w", err)
+	}
+	outputs, err := sectionOutputs(cfg.MarkdownFile, outputDir, sections, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("Retiming audio in %s to the timings of %s", outputDir, cfg.MarkdownFile))
+	if cfg.Commands.DryRun {
+		log.Hint("DRY-RUN MODE: No files will be changed")
+	}
+	log.Blank()
+
+	ctx := context.Background()
+	retimed, fitting, regenerate := 0, 0, 0
+	for _, section := range sections {
+		if !section.HasTiming {
+			continue
+		}
+		audioPath := outputs[section.Index]
+		log.Info(fmt.Sprintf("Section %d:", section.Index)).WithAttrs("title", section.Title)
+		log.WithIndent(true)
+
+		current, err := utils.GetAudioDuration(audioPath)
+		if err != nil {
+			log.Warning(fmt.Sprintf("Cannot measure %s: %v", audioPath, err))
+			log.WithIndent(false)
+			continue
+		}
+
+		tempo, ok := retimeTempo(current, section.Duration)
+		switch {
+		case tempo == 1:
+			log.Faint(fmt.Sprintf("Already fits: %.1fs (target %.1fs)", current, section.Duration))
+			fitting++
+		case !ok:
+			log.Warning(fmt.Sprintf("%.1fs -> %.1fs needs tempo x%.2f, beyond x%.2f-x%.2f: regenerate this section", current, section.Duration, tempo, minRetimeTempo, maxRetimeTempo))
+			regenerate++
+		case cfg.Commands.DryRun:
+			log.Faint(fmt.Sprintf("Would retime: %.1fs -> %.1fs (tempo x%.2f)", current, section.Duration, tempo))
+			retimed++
+		default:
+			if err := utils.ChangeTempo(ctx, audioPath, tempo); err != nil {
+				log.Error("Failed:", err)
+				break
+			}
+			log.Success(fmt.Sprintf("Retimed: %.1fs -> %.1fs (tempo x%.2f)", current, section.Duration, tempo))
+			retimed++
+		}
+		log.WithIndent(false)
+	}
+
+	log.Blank()
+	verb := "Retimed"
+	if cfg.Commands.DryRun {
+		verb = "Would retime"
+	}
+	log.Success(fmt.Sprintf("%s %d section(s), %d already fit", verb, retimed, fitting))
+	if regenerate > 0 {
+		log.Warning(fmt.Sprintf("%d section(s) beyond the time-stretching bounds: regenerate them with -f %s -o %s", regenerate, cfg.MarkdownFile, cfg.OutputDir))
+	}
+	return nil
+}
+
+// sectionOutputs returns the existing audio file of each section by index, as
+// recorded in the output directory manifest or at its default output path
+func sectionOutputs(markdownFile, outputDir string, sections []parser.Section, cfg config.Config, log logger.LoggerInterface) (map[int]string, error) {
+	if _, err := os.Stat(outputDir); err != nil {
+		return nil, fmt.Errorf("output directory not found: %s", outputDir)
+	}
+
+	// Only output paths are needed, so no provider is configured
+	paths := outputPaths(cfg, outputDir, log)
+	outputs := make(map[int]string, len(sections))
+	for _, section := range sections {
+		outputs[section.Index] = paths.OutputBase(section, section.Index) + "." + cfg.OutputFormat()
+	}
+
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		return outputs, nil
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+	for _, entry := range m.Filter(manifest.StatusOK, manifest.StatusFlagged) {
+		if entry.Source == sourcePath {
+			if _, ok := outputs[entry.Index]; ok {
+				outputs[entry.Index] = entry.Output
+			}
+		}
+	}
+	return outputs, nil
+}
@@ -0,0 +1,129 @@
+package processor
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestRetimeTempo(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   float64
+		target    float64
+		wantTempo float64
+		wantOK    bool
+	}{
+		{name: "already fits", current: 10, target: 10.1, wantTempo: 1, wantOK: true},
+		{name: "speed up", current: 11, target: 10, wantTempo: 1.1, wantOK: true},
+		{name: "slow down", current: 9, target: 10, wantTempo: 0.9, wantOK: true},
+		{name: "too fast", current: 15, target: 10, wantTempo: 1.5, wantOK: false},
+		{name: "too slow", current: 5, target: 10, wantTempo: 0.5, wantOK: false},
+		{name: "no audio", current: 0, target: 10, wantTempo: 0, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempo, ok := retimeTempo(tt.current, tt.target)
+			if math.Abs(tempo-tt.wantTempo) > 0.0001 || ok != tt.wantOK {
+				t.Errorf("retimeTempo(%v, %v) = (%v, %v), want (%v, %v)", tt.current, tt.target, tempo, ok, tt.wantTempo, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetimeFileDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	content := "## Intro (2s)\n\nHello.\n\n## Body (5s)\n\nMain part.\n\n## Outro (1s)\n\nBye.\n\n## Credits\n\nNo timing.\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "output")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, seconds := range map[string]float64{"section_01_intro.wav": 2, "section_02_body.wav": 10, "section_03_outro.wav": 1.1} {
+		if err := utils.WriteSilence(context.Background(), filepath.Join(outputDir, name), seconds); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.Config{
+		MarkdownFile: mdFile,
+		OutputDir:    outputDir,
+		Format:       "wav",
+		Prefix:       "section",
+		Commands:     config.CommandFlags{Retime: true, DryRun: true},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := RetimeFile(cfg, log); err != nil {
+			t.Errorf("RetimeFile() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	for _, want := range []string{
+		"Already fits: 2.0s (target 2.0s)",
+		"10.0s -> 5.0s needs tempo x2.00, beyond x0.80-x1.25: regenerate this section",
+		"Would retime: 1.1s -> 1.0s (tempo x1.10)",
+		"Would retime 1 section(s), 1 already fit",
+		"1 section(s) beyond the time-stretching bounds",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	// Dry runs leave the audio untouched
+	if duration, _ := utils.GetWAVDuration(filepath.Join(outputDir, "section_03_outro.wav")); math.Abs(duration-1.1) > 0.001 {
+		t.Errorf("Dry run changed the audio duration to %.3f", duration)
+	}
+}
+
+func TestRetimeFileErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "video.md")
+	if err := os.WriteFile(mdFile, []byte("## Intro (2s)\n\nHello.\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{
+			name:    "no markdown file",
+			cfg:     config.Config{OutputDir: tmpDir},
+			wantErr: "-retime requires a markdown file",
+		},
+		{
+			name:    "missing output directory",
+			cfg:     config.Config{MarkdownFile: mdFile, OutputDir: filepath.Join(tmpDir, "missing"), Format: "wav", Prefix: "section"},
+			wantErr: "output directory not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RetimeFile(tt.cfg, logger.NewDefaultLogger())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RetimeFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
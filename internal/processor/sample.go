@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// sampleSections randomly selects the -sample fraction of all sections across files.
+// The selection is reproducible with -seed; without it a random seed is used and logged.
+func (rs *runState) sampleSections(files []string, cfg config.Config, log logger.LoggerInterface) error {
+	fraction, err := cfg.SampleFraction()
+	if err != nil {
+		return err
+	}
+
+	counts := make([]int, len(files))
+	for i, file := range files {
+		sections, err := parseMarkdownFile(file, cfg)
+		if err != nil {
+			// Reported when the file itself is processed
+			continue
+		}
+		counts[i] = len(sections)
+		if cfg.LimitSections > 0 && counts[i] > cfg.LimitSections {
+			counts[i] = cfg.LimitSections
+		}
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+
+	var total int
+	rs.sample, total = sampleIndices(files, counts, fraction, seed)
+	log.Hint(fmt.Sprintf("Sampling %d of %d section(s) (%s, seed %d)", countSelected(rs.sample), total, cfg.Sample, seed))
+	return nil
+}
+
+// sampleIndices selects round(fraction * total) sections (at least one) across files,
+// where counts[i] is the number of sections in files[i]. It returns the selected
+// 1-based section indices per file and the total number of sections.
+func sampleIndices(files []string, counts []int, fraction float64, seed uint64) (map[string]map[int]bool, int) {
+	type ref struct {
+		file  string
+		index int
+	}
+
+	var all []ref
+	for i, file := range files {
+		for index := 1; index <= counts[i]; index++ {
+			all = append(all, ref{file: file, index: index})
+		}
+	}
+
+	sample := make(map[string]map[int]bool)
+	if len(all) == 0 {
+		return sample, 0
+	}
+
+	n := int(math.Round(fraction * float64(len(all))))
+	n = max(1, min(n, len(all)))
+
+	r := rand.New(rand.NewPCG(seed, seed))
+	for _, i := range r.Perm(len(all))[:n] {
+		picked := all[i]
+		if sample[picked.file] == nil {
+			sample[picked.file] = make(map[int]bool)
+		}
+		sample[picked.file][picked.index] = true
+	}
+
+	return sample, len(all)
+}
+
+// countSelected returns the number of selected sections
+func countSelected(sample map[string]map[int]bool) int {
+	n := 0
+	for _, indices := range sample {
+		n += len(indices)
+	}
+	return n
+}
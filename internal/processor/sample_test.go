@@ -0,0 +1,132 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestSampleIndices(t *testing.T) {
+	files := []string{"a.md", "b.md", "c.md"}
+	counts := []int{10, 0, 30}
+
+	tests := []struct {
+		name     string
+		fraction float64
+		want     int
+	}{
+		{name: "five percent", fraction: 0.05, want: 2},
+		{name: "at least one", fraction: 0.001, want: 1},
+		{name: "all", fraction: 1, want: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, total := sampleIndices(files, counts, tt.fraction, 42)
+			if total != 40 {
+				t.Errorf("total = %d, want 40", total)
+			}
+			if got := countSelected(sample); got != tt.want {
+				t.Errorf("selected %d sections, want %d", got, tt.want)
+			}
+			for file, indices := range sample {
+				for index := range indices {
+					if file == "b.md" || index < 1 || (file == "a.md" && index > 10) || index > 30 {
+						t.Errorf("invalid selection %s #%d", file, index)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSampleIndicesSeed(t *testing.T) {
+	files := []string{"a.md", "b.md"}
+	counts := []int{50, 50}
+
+	first, _ := sampleIndices(files, counts, 0.1, 7)
+	second, _ := sampleIndices(files, counts, 0.1, 7)
+	other, _ := sampleIndices(files, counts, 0.1, 8)
+
+	if !sameSample(first, second) {
+		t.Error("Expected the same seed to select the same sections")
+	}
+	if sameSample(first, other) {
+		t.Error("Expected different seeds to select different sections")
+	}
+}
+
+func TestSampleIndicesEmpty(t *testing.T) {
+	sample, total := sampleIndices([]string{"a.md"}, []int{0}, 0.5, 1)
+	if total != 0 || countSelected(sample) != 0 {
+		t.Errorf("Expected empty sample, got %v (total %d)", sample, total)
+	}
+}
+
+func TestProcessDirectorySample(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	var content strings.Builder
+	for _, title := range []string{"One", "Two", "Three", "Four", "Five"} {
+		content.WriteString("## " + title + "\n\nSome text.\n\n")
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".md"), []byte(content.String()), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.Config{
+		InputDir:  inputDir,
+		OutputDir: outputDir,
+		Provider:  "elevenlabs",
+		ElevenLabs: config.ElevenLabsConfig{
+			APIKey:  "test-key",
+			VoiceID: "default-voice",
+		},
+		Format: "mp3",
+		Prefix: "section",
+		Sample: "20%",
+		Seed:   3,
+		Commands: config.CommandFlags{
+			DryRun: true,
+		},
+	}
+
+	log := logger.NewDefaultLogger()
+	output, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, log); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	if !strings.Contains(output, "Sampling 2 of 10 section(s) (20%, seed 3)") {
+		t.Errorf("Expected sampling message, got output:\n%s", output)
+	}
+	if got := strings.Count(output, "Would create:"); got != 2 {
+		t.Errorf("Expected 2 sampled sections, got %d in output:\n%s", got, output)
+	}
+}
+
+// sameSample reports whether two samples select the same sections
+func sameSample(a, b map[string]map[int]bool) bool {
+	if countSelected(a) != countSelected(b) {
+		return false
+	}
+	for file, indices := range a {
+		for index := range indices {
+			if !b[file][index] {
+				return false
+			}
+		}
+	}
+	return true
+}
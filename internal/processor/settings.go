@@ -0,0 +1,155 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/tts/silence"
+	"github.com/indaco/md2audio/internal/utils"
+	"github.com/indaco/md2audio/internal/version"
+)
+
+// runSettings returns the effective settings of a run, as recorded in manifests
+// and audio metadata comments
+func runSettings(cfg config.Config) manifest.Settings {
+	if cfg.SilenceOnly {
+		return manifest.Settings{Version: version.GetVersion(), Provider: silence.Name, Format: cfg.OutputFormat()}
+	}
+
+	settings := manifest.Settings{
+		Version:  version.GetVersion(),
+		Provider: cfg.Provider,
+		Voice:    cfg.Say.Voice,
+		Format:   cfg.OutputFormat(),
+		Options:  make(map[string]string),
+	}
+
+	if cfg.Provider == "elevenlabs" {
+		vs := cfg.ElevenLabs.VoiceSettings
+		settings.Voice = cfg.ElevenLabs.VoiceID
+		settings.Model = cfg.ElevenLabs.Model
+		settings.Options["stability"] = formatFloat(vs.Stability)
+		settings.Options["similarity_boost"] = formatFloat(vs.SimilarityBoost)
+		settings.Options["style"] = formatFloat(vs.Style)
+		settings.Options["speaker_boost"] = strconv.FormatBool(vs.UseSpeakerBoost)
+		settings.Options["speed"] = formatFloat(vs.Speed)
+		if cfg.ElevenLabs.Stitch {
+			settings.Options["stitch"] = "true"
+		}
+		if cfg.ElevenLabs.Seed != 0 {
+			settings.Options["seed"] = strconv.FormatUint(uint64(cfg.ElevenLabs.Seed), 10)
+		}
+		if cfg.ElevenLabs.Deterministic {
+			settings.Options["deterministic"] = "true"
+		}
+	} else if cfg.Provider == "edge" {
+		settings.Options["rate"] = cfg.Edge.Rate
+		settings.Options["pitch"] = cfg.Edge.Pitch
+	} else if cfg.Provider == "coqui" {
+		settings.Options["language"] = cfg.Coqui.Language
+	} else if cfg.Provider == "marytts" {
+		settings.Options["locale"] = cfg.MaryTTS.Locale
+	} else if cfg.Provider == "playht" {
+		settings.Options["speed"] = formatFloat(cfg.PlayHT.Speed)
+		if cfg.PlayHT.Emotion != "" {
+			settings.Options["emotion"] = cfg.PlayHT.Emotion
+		}
+	} else if cfg.Provider == "espeak" {
+		settings.Rate = cfg.Say.Rate
+		for name, value := range map[string]int{"pitch": cfg.Espeak.Pitch, "amplitude": cfg.Espeak.Amplitude, "word_gap": cfg.Espeak.WordGap} {
+			if value > 0 {
+				settings.Options[name] = strconv.Itoa(value)
+			}
+		}
+		if cfg.Espeak.Variant != "" {
+			settings.Options["variant"] = cfg.Espeak.Variant
+		}
+	} else if cfg.Provider != "watson" {
+		// Watson voices have no options beyond the voice
+		settings.Rate = cfg.Say.Rate
+	}
+
+	if cfg.SSML {
+		settings.Options["ssml"] = "true"
+	}
+	if cfg.Redact != "" {
+		settings.Options["redact"] = cfg.Redact
+	}
+	if cfg.TransformCmd != "" {
+		settings.Options["transform_cmd"] = cfg.TransformCmd
+	}
+	if len(settings.Options) == 0 {
+		settings.Options = nil
+	}
+	return settings
+}
+
+// formatFloat formats a setting value without trailing zeros
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// fingerprintEntry records the sample fingerprint and loudness of the entry's
+// audio, so downstream tools can tell changed audio from rewritten files
+func fingerprintEntry(entry manifest.Entry, log logger.LoggerInterface) manifest.Entry {
+	stats, err := utils.AnalyzeAudio(context.Background(), entry.Output)
+	if err != nil {
+		log.Debug(fmt.Sprintf("Could not fingerprint %s: %v", entry.Output, err))
+		return entry
+	}
+	entry.Fingerprint = stats.Fingerprint
+	entry.Loudness = &manifest.Loudness{RMS: stats.RMS, Peak: stats.Peak}
+	return entry
+}
+
+// tagAudio writes the run settings into the metadata comment of an audio file with -tag-audio
+func tagAudio(audioPath string, settings manifest.Settings, cfg config.Config, log logger.LoggerInterface) {
+	if !cfg.TagAudio {
+		return
+	}
+	if err := utils.SetAudioComment(context.Background(), audioPath, settings.Comment()); err != nil {
+		log.Warning(fmt.Sprintf("Could not tag %s: %v", audioPath, err))
+	}
+}
+
+// checkSettings refuses to add audio to an output directory whose manifest records
+// a different provider, voice, or format, unless -force is set, so that inconsistent
+// sounding audio sets do not accumulate silently. Silent timing scaffolds may be
+// replaced freely. With -deterministic, the model recorded in the manifest is
+// pinned even with -force. The manifest is updated to the settings of this run.
+func checkSettings(m *manifest.Manifest, outputDir string, cfg config.Config, log logger.LoggerInterface) error {
+	current := runSettings(cfg)
+	if err := checkPinnedModel(m, outputDir, current, cfg); err != nil {
+		return err
+	}
+	if m.Settings != nil && m.Settings.Provider != silence.Name && len(m.Entries) > 0 {
+		if diff := m.Settings.Diff(current); len(diff) > 0 {
+			if !cfg.Force {
+				return fmt.Errorf("%s contains audio generated with different settings (%s): use -force to add to it anyway, or choose another output directory",
+					outputDir, strings.Join(diff, ", "))
+			}
+			log.Warning(fmt.Sprintf("Mixing settings in %s (%s)", outputDir, strings.Join(diff, ", ")))
+		}
+	}
+	m.Settings = &current
+	return nil
+}
+
+// checkPinnedModel refuses -deterministic runs with a model other than the one
+// that generated the audio of the output directory, since a model change alters
+// the audio of every section regenerated later
+func checkPinnedModel(m *manifest.Manifest, outputDir string, current manifest.Settings, cfg config.Config) error {
+	if !cfg.ElevenLabs.Deterministic || m.Settings == nil || len(m.Entries) == 0 {
+		return nil
+	}
+	if m.Settings.Provider != current.Provider || m.Settings.Model == "" || m.Settings.Model == current.Model {
+		return nil
+	}
+	return fmt.Errorf("-deterministic: %s was generated with the model %s, not %s; use -elevenlabs-model %s or another output directory",
+		outputDir, m.Settings.Model, current.Model, m.Settings.Model)
+}
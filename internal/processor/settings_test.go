@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+func TestCheckSettings(t *testing.T) {
+	cfg := config.Config{Provider: "say", Say: config.SayConfig{Voice: "Kate"}, Format: "aiff"}
+	entry := manifest.Entry{Source: "/docs/guide.md", Index: 1, Title: "Intro", Output: "out/section_01_intro.aiff", Status: manifest.StatusOK}
+
+	tests := []struct {
+		name     string
+		settings *manifest.Settings
+		entries  []manifest.Entry
+		force    bool
+		wantErr  string
+	}{
+		{name: "new manifest", entries: nil},
+		{name: "manifest without settings", entries: []manifest.Entry{entry}},
+		{name: "same settings", settings: &manifest.Settings{Provider: "say", Voice: "Kate", Format: "aiff"}, entries: []manifest.Entry{entry}},
+		{
+			name:     "different voice",
+			settings: &manifest.Settings{Provider: "say", Voice: "Daniel", Format: "aiff"},
+			entries:  []manifest.Entry{entry},
+			wantErr:  "different settings (voice Daniel -> Kate): use -force",
+		},
+		{name: "different voice with force", settings: &manifest.Settings{Provider: "say", Voice: "Daniel", Format: "aiff"}, entries: []manifest.Entry{entry}, force: true},
+		{name: "replacing a silent scaffold", settings: &manifest.Settings{Provider: "silence", Format: "wav"}, entries: []manifest.Entry{entry}},
+		{name: "different settings without entries", settings: &manifest.Settings{Provider: "elevenlabs", Voice: "voice-123", Format: "mp3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &manifest.Manifest{Settings: tt.settings, Entries: tt.entries}
+			runCfg := cfg
+			runCfg.Force = tt.force
+
+			err := checkSettings(m, "out", runCfg, logger.NewDefaultLogger())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("checkSettings() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkSettings() error = %v", err)
+			}
+			if m.Settings == nil || !reflect.DeepEqual(*m.Settings, runSettings(runCfg)) {
+				t.Errorf("manifest settings = %+v, want the settings of this run", m.Settings)
+			}
+		})
+	}
+}
+
+func TestRunSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		wantComment string
+	}{
+		{
+			name:        "say",
+			cfg:         config.Config{Provider: "say", Say: config.SayConfig{Voice: "Kate", Rate: 170}, Format: "m4a", Redact: "mask"},
+			wantComment: "provider=say; voice=Kate; format=m4a; rate=170; redact=mask",
+		},
+		{
+			name: "elevenlabs",
+			cfg: config.Config{
+				Provider: "elevenlabs",
+				Format:   "mp3",
+				ElevenLabs: config.ElevenLabsConfig{
+					VoiceID:       "voice-123",
+					Model:         "eleven_multilingual_v2",
+					VoiceSettings: config.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75, UseSpeakerBoost: true, Speed: 1},
+				},
+			},
+			wantComment: "provider=elevenlabs; voice=voice-123; format=mp3; model=eleven_multilingual_v2; similarity_boost=0.75; speaker_boost=true; speed=1; stability=0.5; style=0",
+		},
+		{
+			name: "elevenlabs deterministic",
+			cfg: config.Config{
+				Provider: "elevenlabs",
+				Format:   "mp3",
+				ElevenLabs: config.ElevenLabsConfig{
+					VoiceID:       "voice-123",
+					Model:         "eleven_multilingual_v2",
+					Seed:          42,
+					Deterministic: true,
+					VoiceSettings: config.VoiceSettings{Stability: 0.5, SimilarityBoost: 0.75, UseSpeakerBoost: true, Speed: 1},
+				},
+			},
+			wantComment: "provider=elevenlabs; voice=voice-123; format=mp3; model=eleven_multilingual_v2; deterministic=true; seed=42; similarity_boost=0.75; speaker_boost=true; speed=1; stability=0.5; style=0",
+		},
+		{
+			name:        "espeak",
+			cfg:         config.Config{Provider: "espeak", Say: config.SayConfig{Voice: "en-gb", Rate: 170}, Format: "wav", Espeak: config.EspeakConfig{Pitch: 40, WordGap: 2, Variant: "f3"}},
+			wantComment: "provider=espeak; voice=en-gb; format=wav; rate=170; pitch=40; variant=f3; word_gap=2",
+		},
+		{
+			name:        "silence only",
+			cfg:         config.Config{Provider: "say", Say: config.SayConfig{Voice: "Kate", Rate: 170}, Format: "aiff", Redact: "mask", SilenceOnly: true},
+			wantComment: "provider=silence; format=aiff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := runSettings(tt.cfg)
+			if settings.Version == "" {
+				t.Error("settings should record the md2audio version")
+			}
+			comment := settings.Comment()
+			if !strings.HasPrefix(comment, "md2audio "+settings.Version+"; ") || !strings.HasSuffix(comment, tt.wantComment) {
+				t.Errorf("Comment() = %q, want suffix %q", comment, tt.wantComment)
+			}
+		})
+	}
+}
+
+func TestCheckSettingsDeterministic(t *testing.T) {
+	entry := manifest.Entry{Source: "/docs/guide.md", Index: 1, Title: "Intro", Output: "out/section_01_intro.mp3", Status: manifest.StatusOK}
+	recorded := manifest.Settings{Provider: "elevenlabs", Voice: "voice-123", Format: "mp3", Model: "eleven_multilingual_v2"}
+	cfg := config.Config{
+		Provider:   "elevenlabs",
+		Format:     "mp3",
+		Force:      true,
+		ElevenLabs: config.ElevenLabsConfig{VoiceID: "voice-123", Model: "eleven_turbo_v2_5"},
+	}
+
+	// Without -deterministic, the model is not compared
+	m := &manifest.Manifest{Settings: &recorded, Entries: []manifest.Entry{entry}}
+	if err := checkSettings(m, "out", cfg, logger.NewDefaultLogger()); err != nil {
+		t.Fatalf("checkSettings() error = %v", err)
+	}
+
+	// With -deterministic, the recorded model is pinned even with -force
+	cfg.ElevenLabs.Deterministic = true
+	m = &manifest.Manifest{Settings: &recorded, Entries: []manifest.Entry{entry}}
+	err := checkSettings(m, "out", cfg, logger.NewDefaultLogger())
+	if err == nil || !strings.Contains(err.Error(), "use -elevenlabs-model eleven_multilingual_v2") {
+		t.Fatalf("checkSettings() error = %v, want a pinned model error", err)
+	}
+
+	cfg.ElevenLabs.Model = "eleven_multilingual_v2"
+	if err := checkSettings(m, "out", cfg, logger.NewDefaultLogger()); err != nil {
+		t.Errorf("checkSettings() error = %v, want the pinned model accepted", err)
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/readalong"
+	"github.com/indaco/md2audio/internal/site"
+	"github.com/indaco/md2audio/internal/summary"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// siteAssetsDir is the -site-assets directory in the output root
+const siteAssetsDir = "site"
+
+// sitePage builds the site page of the audio generated for markdownFile into
+// outputDir, from the entries of the output directory manifest. Audio links are
+// relative to root, or under baseURL when set.
+func sitePage(markdownFile, relPath, outputDir, root, baseURL string) (site.Page, error) {
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		return site.Page{}, err
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+
+	links := summary.Options{BaseDir: root, BaseURL: baseURL}
+	page := site.Page{Slug: site.Slug(relPath), Source: filepath.ToSlash(relPath)}
+	for _, entry := range m.Filter(manifest.StatusOK, manifest.StatusFlagged) {
+		if entry.Source != sourcePath {
+			continue
+		}
+		track := site.Track{
+			Index:    entry.Index,
+			Sentence: entry.Sentence,
+			Title:    entry.Title,
+			Audio:    links.Link(entry.Output),
+		}
+		if duration, err := utils.GetAudioDuration(entry.Output); err == nil {
+			track.Duration = duration
+		}
+		if _, err := os.Stat(readalong.Path(entry.Output)); err == nil {
+			track.ReadAlong = links.Link(readalong.Path(entry.Output))
+		}
+		page.Tracks = append(page.Tracks, track)
+	}
+
+	slices.SortStableFunc(page.Tracks, func(a, b site.Track) int {
+		return cmp.Or(cmp.Compare(a.Index, b.Index), cmp.Compare(a.Sentence, b.Sentence))
+	})
+	return page, nil
+}
+
+// writeSiteAssets writes the -site-assets player snippet of a markdown file and
+// records it in the site index in the output root.
+func writeSiteAssets(markdownFile, relPath, outputDir string, cfg config.Config, log logger.LoggerInterface) {
+	if !cfg.Site.Assets || cfg.Commands.DryRun {
+		return
+	}
+
+	root := parser.OutputRoot(cfg.OutputDir)
+	page, err := sitePage(markdownFile, relPath, outputDir, root, cfg.Site.BaseURL)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Failed to write site assets for %s: %v", relPath, err))
+		return
+	}
+	if len(page.Tracks) == 0 {
+		return
+	}
+
+	snippetPath, err := site.Write(filepath.Join(root, siteAssetsDir), page)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Failed to write site assets for %s: %v", relPath, err))
+		return
+	}
+	log.Success(fmt.Sprintf("Player snippet for %q: %s", page.Slug, snippetPath))
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/site"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+func TestProcessDirectorySiteAssets(t *testing.T) {
+	inputDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(inputDir, "guides"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"intro.md":        "## Welcome (2s)\n\nHello.\n\n## Next (1s)\n\nRead on.\n",
+		"guides/setup.md": "## Install\n\nRun the installer.\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(inputDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	outputDir := filepath.Join(t.TempDir(), "audio")
+
+	cfg := config.Config{
+		InputDir:    inputDir,
+		OutputDir:   outputDir,
+		Provider:    "espeak",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+		Site:        config.SiteConfig{Assets: true, BaseURL: "/audio/"},
+	}
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessDirectory(cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("ProcessDirectory() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	index, err := site.LoadIndex(filepath.Join(outputDir, siteAssetsDir))
+	if err != nil {
+		t.Fatalf("LoadIndex() error = %v", err)
+	}
+	intro, ok := index["intro"]
+	if !ok || len(index) != 2 {
+		t.Fatalf("Expected pages intro and guides/setup, got %+v", index)
+	}
+	if len(intro.Tracks) != 2 || intro.Tracks[0].Audio != "/audio/intro/section_01_welcome.wav" || intro.Tracks[0].Duration != 2 {
+		t.Errorf("Unexpected intro tracks: %+v", intro.Tracks)
+	}
+	if setup := index["guides/setup"]; setup.Source != "guides/setup.md" || setup.Snippet != "guides/setup.html" {
+		t.Errorf("Unexpected setup page: %+v", setup)
+	}
+
+	snippet, err := os.ReadFile(filepath.Join(outputDir, siteAssetsDir, "intro.html"))
+	if err != nil {
+		t.Fatalf("Expected player snippet: %v", err)
+	}
+	if !strings.Contains(string(snippet), `src="/audio/intro/section_02_next.wav"`) {
+		t.Errorf("Unexpected snippet:\n%s", snippet)
+	}
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/cli"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+	"github.com/indaco/md2audio/internal/summary"
+)
+
+// spokenSummaryName is the base name of the -spoken-summary audio file
+const spokenSummaryName = "run_summary"
+
+// writeSpokenSummary synthesizes the spoken report of a run into the output
+// directory with the run's provider and voice. Failures are logged and do not
+// fail the run.
+func writeSpokenSummary(cfg config.Config, sum *summary.Summary, log logger.LoggerInterface) {
+	if sum == nil || !cfg.Summary.Spoken {
+		return
+	}
+	switch {
+	case cfg.SilenceOnly:
+		log.Warning("Skipping -spoken-summary: -silence-only runs have no voice to speak it")
+		return
+	case parser.IsOutputTemplate(cfg.OutputDir):
+		log.Warning("Skipping -spoken-summary: the output directory is a template")
+		return
+	}
+
+	provider, err := cli.CreateProvider(cfg)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Could not write spoken summary: %v", err))
+		return
+	}
+	generator := audio.NewGenerator(audio.GeneratorConfig{
+		Voice:     providerVoice(cfg),
+		Rate:      cfg.Say.Rate,
+		Format:    cfg.OutputFormat(),
+		OutputDir: cfg.OutputDir,
+		Provider:  provider,
+	}, log)
+
+	path, err := speakSummary(generator, sum, cfg.OutputDir)
+	if err != nil {
+		log.Warning(fmt.Sprintf("Could not write spoken summary: %v", err))
+		return
+	}
+	log.Info("Spoken summary:", path)
+}
+
+// speakSummary synthesizes the spoken report of sum into outputDir and returns its path
+func speakSummary(generator *audio.Generator, sum *summary.Summary, outputDir string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return generator.Synthesize(sum.SpokenText(), filepath.Join(outputDir, spokenSummaryName))
+}
@@ -0,0 +1,66 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/audio"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/summary"
+	"github.com/indaco/md2audio/internal/testhelpers"
+	"github.com/indaco/md2audio/internal/tts/silence"
+)
+
+func TestSpeakSummary(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "audio")
+	sum := summary.New("say")
+	sum.AddFile()
+	sum.Add(manifest.Entry{Source: "guide.md", Title: "Intro", Status: manifest.StatusOK}, 12)
+
+	generator := audio.NewGenerator(audio.GeneratorConfig{
+		Rate:      180,
+		Format:    "wav",
+		OutputDir: outputDir,
+		Provider:  silence.NewProvider(),
+	}, logger.NewDefaultLogger())
+
+	path, err := speakSummary(generator, sum, outputDir)
+	if err != nil {
+		t.Fatalf("speakSummary() error = %v", err)
+	}
+	if want := filepath.Join(outputDir, "run_summary.wav"); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("spoken summary not written: %v", err)
+	}
+}
+
+func TestWriteSpokenSummarySkips(t *testing.T) {
+	sum := summary.New("say")
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"silence only", config.Config{OutputDir: t.TempDir(), SilenceOnly: true, Summary: config.SummaryConfig{Spoken: true}}, "-silence-only"},
+		{"output template", config.Config{OutputDir: "out/{{.Lang}}", Summary: config.SummaryConfig{Spoken: true}}, "template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := testhelpers.CaptureStdout(func() {
+				writeSpokenSummary(tt.cfg, sum, logger.NewDefaultLogger())
+			})
+			if err != nil {
+				t.Fatalf("Failed to capture output: %v", err)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("expected a warning mentioning %q, got %q", tt.want, output)
+			}
+		})
+	}
+}
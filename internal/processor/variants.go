@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// variantDir returns the subdirectory of the -variants copies at speed, e.g. "1.25x"
+func variantDir(speed float64) string {
+	return formatFloat(speed) + "x"
+}
+
+// variantPath returns the path of the copy of audioPath at speed
+func variantPath(audioPath string, speed float64) string {
+	return filepath.Join(filepath.Dir(audioPath), variantDir(speed), filepath.Base(audioPath))
+}
+
+// writeVariants writes the -variants copies of an audio file, time-stretched to
+// each speed with ffmpeg. The original speed (1x) is the file itself.
+// Failures are logged and do not fail the section.
+func writeVariants(audioPath string, cfg config.Config, log logger.LoggerInterface) {
+	for _, speed := range cfg.Variants {
+		if speed == 1 {
+			continue
+		}
+		outputPath := variantPath(audioPath, speed)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			log.Warning(fmt.Sprintf("Could not write %s variant: %v", variantDir(speed), err))
+			continue
+		}
+		if err := utils.WriteTempo(context.Background(), audioPath, outputPath, speed); err != nil {
+			log.Warning(fmt.Sprintf("Could not write %s variant: %v", variantDir(speed), err))
+			continue
+		}
+		log.Debug("Variant: " + outputPath)
+	}
+}
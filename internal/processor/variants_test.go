@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestVariantPath(t *testing.T) {
+	got := variantPath(filepath.Join("out", "guide", "section_01_intro.mp3"), 1.25)
+	if want := filepath.Join("out", "guide", "1.25x", "section_01_intro.mp3"); got != want {
+		t.Errorf("variantPath() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteVariants(t *testing.T) {
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "section_01_intro.wav")
+	if err := utils.WriteSilence(context.Background(), audioPath, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{Variants: []float64{1, 1.5}}
+	output, err := testhelpers.CaptureStdout(func() {
+		writeVariants(audioPath, cfg, logger.NewDefaultLogger())
+	})
+	if err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "1x")); !os.IsNotExist(err) {
+		t.Error("the original speed should not be copied")
+	}
+	_, statErr := os.Stat(filepath.Join(dir, "1.5x", "section_01_intro.wav"))
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		if statErr == nil || output == "" {
+			t.Errorf("expected a warning and no variant without ffmpeg, got %q", output)
+		}
+		return
+	}
+	if statErr != nil {
+		t.Errorf("1.5x variant not written: %v\n%s", statErr, output)
+	}
+}
@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// modeWebhook is the run mode recorded for webhook-triggered regenerations
+const modeWebhook = "webhook"
+
+// pullMu serializes pulls of concurrent webhook regenerations
+var pullMu sync.Mutex
+
+// ProcessChanged pulls the git checkout containing the input directory and
+// regenerates the audio of the given markdown files, as directory mode would.
+// Files are slash-separated paths relative to the repository root; files
+// outside the input directory or no longer present are skipped.
+func ProcessChanged(ctx context.Context, files []string, cfg config.Config, log logger.LoggerInterface) error {
+	repoRoot, err := pullCheckout(ctx, cfg.InputDir)
+	if err != nil {
+		return err
+	}
+
+	mdFiles, err := changedMarkdownFiles(repoRoot, cfg.InputDir, files)
+	if err != nil {
+		return err
+	}
+	if len(mdFiles) == 0 {
+		log.Info("No changed markdown files in the input directory")
+		return nil
+	}
+
+	rs := newRunState(cfg)
+	for _, mdFile := range mdFiles {
+		if err := checkDocumentSize(mdFile.AbsPath, cfg.Server.MaxDocumentKB); err != nil {
+			log.Warning(fmt.Sprintf("Skipping %s: %v", mdFile.RelPath, err))
+			continue
+		}
+
+		log.Blank()
+		log.Info("Regenerating:", mdFile.RelPath)
+
+		outputDir, err := mdFile.ResolveOutputDir(cfg.OutputDir)
+		if err != nil {
+			log.Warning(fmt.Sprintf("Failed to process %s: %v", mdFile.RelPath, err))
+			continue
+		}
+		if _, _, err := processSingleFile(mdFile.AbsPath, outputDir, cfg, log, rs); err != nil {
+			log.Warning(fmt.Sprintf("Failed to process %s: %v", mdFile.RelPath, err))
+			continue
+		}
+		outputDir = fileOutputDir(mdFile.AbsPath, outputDir, cfg)
+		writeFileZip(mdFile.AbsPath, mdFile.RelPath, outputDir, cfg, log)
+		writeSiteAssets(mdFile.AbsPath, mdFile.RelPath, outputDir, cfg, log)
+	}
+
+	finishRun(cfg, rs, modeWebhook, cfg.InputDir, log)
+	return nil
+}
+
+// checkDocumentSize refuses markdown files larger than maxKB KiB (0 = unlimited)
+func checkDocumentSize(path string, maxKB int) error {
+	if maxKB <= 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > int64(maxKB)*1024 {
+		return fmt.Errorf("document size %d KiB exceeds the limit of %d KiB", (info.Size()+1023)/1024, maxKB)
+	}
+	return nil
+}
+
+// pullCheckout fast-forwards the git checkout containing dir and returns
+// the repository root
+func pullCheckout(ctx context.Context, dir string) (string, error) {
+	pullMu.Lock()
+	defer pullMu.Unlock()
+
+	if out, err := exec.CommandContext(ctx, "git", "-C", dir, "pull", "--ff-only").CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git pull failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// changedMarkdownFiles maps repository-relative files to markdown files
+// discovered in inputDir, dropping files outside it or no longer present
+func changedMarkdownFiles(repoRoot, inputDir string, files []string) ([]parser.MarkdownFile, error) {
+	absBaseDir, err := filepath.Abs(inputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	// Compare against the resolved input directory, as git reports the real repository root
+	if resolved, err := filepath.EvalSymlinks(absBaseDir); err == nil {
+		absBaseDir = resolved
+	}
+
+	var mdFiles []parser.MarkdownFile
+	for _, file := range files {
+		absPath := filepath.Join(repoRoot, filepath.FromSlash(file))
+		relPath, err := filepath.Rel(absBaseDir, absPath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if info, err := os.Stat(absPath); err != nil || info.IsDir() {
+			continue
+		}
+		mdFiles = append(mdFiles, parser.MarkdownFile{
+			AbsPath:  absPath,
+			RelPath:  relPath,
+			BaseDir:  absBaseDir,
+			FileName: strings.TrimSuffix(filepath.Base(absPath), ".md"),
+		})
+	}
+	return mdFiles, nil
+}
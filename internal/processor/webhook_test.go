@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/testhelpers"
+)
+
+// runGit runs a git command in dir, failing the test on error
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestProcessChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	// An upstream repository and a checkout of it
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "-q")
+	if err := os.MkdirAll(filepath.Join(upstream, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(upstream, "docs", "intro.md"), []byte("## Welcome (1s)\n\nHello.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "-q", "-m", "initial")
+
+	checkout := filepath.Join(t.TempDir(), "checkout")
+	runGit(t, upstream, "clone", "-q", upstream, checkout)
+
+	// A new file pushed upstream after the checkout was made
+	if err := os.WriteFile(filepath.Join(upstream, "docs", "setup.md"), []byte("## Install (1s)\n\nRun it.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "-q", "-m", "add setup")
+
+	outputDir := filepath.Join(t.TempDir(), "audio")
+	cfg := config.Config{
+		InputDir:    filepath.Join(checkout, "docs"),
+		OutputDir:   outputDir,
+		Provider:    "espeak",
+		Format:      "wav",
+		Prefix:      "section",
+		SilenceOnly: true,
+	}
+	files := []string{"docs/setup.md", "README.md", "docs/removed.md"}
+	if _, err := testhelpers.CaptureStdout(func() {
+		if err := ProcessChanged(context.Background(), files, cfg, logger.NewDefaultLogger()); err != nil {
+			t.Errorf("ProcessChanged() error = %v", err)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to capture output: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "setup", "section_01_install.wav")); err != nil {
+		t.Errorf("Expected the pulled file to be regenerated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "intro")); !os.IsNotExist(err) {
+		t.Errorf("Unchanged files should not be regenerated, stat error = %v", err)
+	}
+}
+
+func TestProcessChangedNotACheckout(t *testing.T) {
+	cfg := config.Config{InputDir: t.TempDir(), OutputDir: t.TempDir()}
+	if err := ProcessChanged(context.Background(), []string{"a.md"}, cfg, logger.NewDefaultLogger()); err == nil {
+		t.Error("ProcessChanged() should fail outside a git checkout")
+	}
+}
+
+func TestCheckDocumentSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.md")
+	if err := os.WriteFile(path, make([]byte, 3*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkDocumentSize(path, 0); err != nil {
+		t.Errorf("checkDocumentSize() without a limit error = %v", err)
+	}
+	if err := checkDocumentSize(path, 4); err != nil {
+		t.Errorf("checkDocumentSize() within the limit error = %v", err)
+	}
+	if err := checkDocumentSize(path, 2); err == nil {
+		t.Error("checkDocumentSize() should refuse documents over the limit")
+	}
+}
@@ -0,0 +1,115 @@
+package processor
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// zipName returns the -zip-per-file archive name for a markdown file:
+// its path relative to the input directory without the extension,
+// with directory separators replaced (e.g., "course/intro.md" -> "course_intro.zip").
+func zipName(relPath string) string {
+	name := strings.TrimSuffix(filepath.ToSlash(relPath), filepath.Ext(relPath))
+	return strings.ReplaceAll(name, "/", "_") + ".zip"
+}
+
+// zipFileAudio packages the audio generated for markdownFile into outputDir
+// into a zip archive at zipPath, using the entries of the output directory manifest.
+// It returns the number of archived files.
+func zipFileAudio(markdownFile, outputDir, zipPath string) (int, error) {
+	m, err := manifest.Load(manifest.PathFor(outputDir))
+	if err != nil {
+		return 0, err
+	}
+	sourcePath, err := filepath.Abs(markdownFile)
+	if err != nil {
+		sourcePath = markdownFile
+	}
+
+	var files []string
+	for _, entry := range m.Filter(manifest.StatusOK, manifest.StatusFlagged) {
+		if entry.Source == sourcePath {
+			files = append(files, entry.Output)
+		}
+	}
+	if len(files) == 0 {
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(zipPath), 0755); err != nil {
+		return 0, err
+	}
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return 0, err
+	}
+
+	zw := zip.NewWriter(out)
+	for _, file := range files {
+		if err := addZipFile(zw, file, outputDir); err != nil {
+			_ = zw.Close()
+			_ = out.Close()
+			_ = os.Remove(zipPath)
+			return 0, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		return 0, err
+	}
+	return len(files), out.Close()
+}
+
+// addZipFile writes file to zw, named relative to baseDir.
+func addZipFile(zw *zip.Writer, file, baseDir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(stat)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(baseDir, file); err == nil && filepath.IsLocal(rel) {
+		header.Name = filepath.ToSlash(rel)
+	}
+	header.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeFileZip writes the -zip-per-file archive of a markdown file into the output root.
+func writeFileZip(markdownFile, relPath, outputDir string, cfg config.Config, log logger.LoggerInterface) {
+	if !cfg.ZipPerFile || cfg.Commands.DryRun {
+		return
+	}
+
+	zipPath := filepath.Join(parser.OutputRoot(cfg.OutputDir), zipName(relPath))
+	n, err := zipFileAudio(markdownFile, outputDir, zipPath)
+	switch {
+	case err != nil:
+		log.Warning(fmt.Sprintf("Failed to write %s: %v", zipPath, err))
+	case n > 0:
+		log.Success(fmt.Sprintf("Packaged %d audio file(s) into %s", n, zipPath))
+	}
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+func TestZipName(t *testing.T) {
+	tests := map[string]string{
+		"lesson-1.md":          "lesson-1.zip",
+		"course/intro.md":      "course_intro.zip",
+		"course/part-2/faq.md": "course_part-2_faq.zip",
+	}
+	for relPath, want := range tests {
+		if got := zipName(relPath); got != want {
+			t.Errorf("zipName(%q) = %q, want %q", relPath, got, want)
+		}
+	}
+}
+
+func TestZipFileAudio(t *testing.T) {
+	outputDir := t.TempDir()
+	mdFile := filepath.Join(t.TempDir(), "guide.md")
+	otherFile := filepath.Join(t.TempDir(), "other.md")
+
+	m := manifest.New()
+	entries := []manifest.Entry{
+		{Source: mdFile, Index: 1, Output: filepath.Join(outputDir, "section_01_intro.mp3"), Status: manifest.StatusOK},
+		{Source: mdFile, Index: 2, Output: filepath.Join(outputDir, "section_02_setup.mp3"), Status: manifest.StatusFlagged},
+		{Source: mdFile, Index: 3, Output: filepath.Join(outputDir, "section_03_usage.mp3"), Status: manifest.StatusFailed},
+		{Source: otherFile, Index: 1, Output: filepath.Join(outputDir, "section_01_other.mp3"), Status: manifest.StatusOK},
+	}
+	for _, entry := range entries {
+		m.Put(entry)
+		if entry.Status != manifest.StatusFailed {
+			if err := os.WriteFile(entry.Output, []byte("audio"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := m.Save(manifest.PathFor(outputDir)); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "guide.zip")
+	n, err := zipFileAudio(mdFile, outputDir, zipPath)
+	if err != nil {
+		t.Fatalf("zipFileAudio() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("zipFileAudio() archived %d files, want 2", n)
+	}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	want := []string{"section_01_intro.mp3", "section_02_setup.mp3"}
+	if !slices.Equal(names, want) {
+		t.Errorf("zip entries = %v, want %v", names, want)
+	}
+
+	// A file without generated audio writes no archive
+	emptyZip := filepath.Join(t.TempDir(), "missing.zip")
+	if n, err := zipFileAudio(filepath.Join(t.TempDir(), "missing.md"), outputDir, emptyZip); err != nil || n != 0 {
+		t.Errorf("zipFileAudio() = %d, %v, want 0, nil", n, err)
+	}
+	if _, err := os.Stat(emptyZip); !os.IsNotExist(err) {
+		t.Error("no archive should be written without audio")
+	}
+}
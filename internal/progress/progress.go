@@ -0,0 +1,74 @@
+// Package progress streams the progress of a run to library users, so GUIs
+// and bots embedding md2audio can render their own progress instead of
+// scraping logs.
+//
+// Key features:
+//   - Typed events for files, section outcomes, and run completion
+//   - Func callback type registered in the run configuration
+//   - Channel adapter for consumers reading events from another goroutine
+package progress
+
+import (
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/summary"
+)
+
+// Kind identifies a progress event.
+type Kind string
+
+// Progress event kinds
+const (
+	FileStarted      Kind = "file_started"      // A markdown file is about to be processed
+	SectionGenerated Kind = "section_generated" // A section was generated (possibly flagged)
+	SectionFailed    Kind = "section_failed"    // A section failed to generate
+	SectionSkipped   Kind = "section_skipped"   // A section was not started because the run budget was spent
+	RunCompleted     Kind = "run_completed"     // The run finished
+)
+
+// Event describes a step of a run. Fields not relevant to the kind are zero.
+type Event struct {
+	Kind     Kind
+	File     string           // Markdown file (FileStarted and section events)
+	Sections int              // Sections selected in the file (FileStarted)
+	Index    int              // 1-based section index within the file
+	Sentence int              // 1-based sentence index within the section (-granularity sentence)
+	Title    string           // Section title
+	Output   string           // Audio file path (planned path for failed and skipped sections)
+	Status   manifest.Status  // Section outcome
+	Reason   string           // Failure, flag, or skip reason
+	Duration float64          // Audio duration in seconds (0 if failed or skipped)
+	Summary  *summary.Summary // Outcome of the run (RunCompleted)
+}
+
+// Func receives progress events. It is called synchronously from the run,
+// so slow consumers should hand events off, e.g. with Channel.
+type Func func(Event)
+
+// Channel returns a Func sending events to ch. Sends block while ch is full.
+func Channel(ch chan<- Event) Func {
+	return func(event Event) {
+		ch <- event
+	}
+}
+
+// SectionEvent returns the event of a section outcome recorded in the manifest.
+func SectionEvent(entry manifest.Entry, duration float64) Event {
+	kind := SectionGenerated
+	switch entry.Status {
+	case manifest.StatusFailed:
+		kind, duration = SectionFailed, 0
+	case manifest.StatusSkipped:
+		kind, duration = SectionSkipped, 0
+	}
+	return Event{
+		Kind:     kind,
+		File:     entry.Source,
+		Index:    entry.Index,
+		Sentence: entry.Sentence,
+		Title:    entry.Title,
+		Output:   entry.Output,
+		Status:   entry.Status,
+		Reason:   entry.Reason,
+		Duration: duration,
+	}
+}
@@ -0,0 +1,38 @@
+package progress
+
+import (
+	"testing"
+
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+func TestSectionEvent(t *testing.T) {
+	tests := []struct {
+		status       manifest.Status
+		wantKind     Kind
+		wantDuration float64
+	}{
+		{manifest.StatusOK, SectionGenerated, 4.5},
+		{manifest.StatusFlagged, SectionGenerated, 4.5},
+		{manifest.StatusFailed, SectionFailed, 0},
+		{manifest.StatusSkipped, SectionSkipped, 0},
+	}
+	for _, tt := range tests {
+		entry := manifest.Entry{Source: "/docs/guide.md", Index: 2, Title: "Setup", Output: "out/section_02_setup.aiff", Status: tt.status}
+		event := SectionEvent(entry, 4.5)
+		if event.Kind != tt.wantKind || event.Duration != tt.wantDuration {
+			t.Errorf("SectionEvent(%s) = %s, %.1fs, want %s, %.1fs", tt.status, event.Kind, event.Duration, tt.wantKind, tt.wantDuration)
+		}
+		if event.File != entry.Source || event.Index != 2 || event.Title != "Setup" || event.Output != entry.Output {
+			t.Errorf("SectionEvent(%s) = %+v", tt.status, event)
+		}
+	}
+}
+
+func TestChannel(t *testing.T) {
+	ch := make(chan Event, 1)
+	Channel(ch)(Event{Kind: RunCompleted})
+	if event := <-ch; event.Kind != RunCompleted {
+		t.Errorf("received %s, want %s", event.Kind, RunCompleted)
+	}
+}
@@ -0,0 +1,199 @@
+// Package pronounce detects tokens that TTS engines are likely to mispronounce.
+// It scans section text for identifiers, numbers with units, acronyms and
+// unusual words, so authors can add lexicon entries before generating audio.
+//
+// Key features:
+//   - Heuristic detection of risky tokens (no dictionary required)
+//   - Aggregation of findings across sections and files
+//   - Plain-text report output
+package pronounce
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Reason describes why a token was flagged.
+type Reason string
+
+const (
+	// ReasonIdentifier marks mixed-case or snake_case identifiers (e.g., "getUserID").
+	ReasonIdentifier Reason = "identifier"
+	// ReasonNumberUnit marks numbers followed by a unit (e.g., "5ms", "10 GB").
+	ReasonNumberUnit Reason = "number with unit"
+	// ReasonAcronym marks all-caps abbreviations (e.g., "SQL").
+	ReasonAcronym Reason = "acronym"
+	// ReasonRare marks words with unusual spelling (e.g., "Pwn", "Schwarzschild").
+	ReasonRare Reason = "rare word"
+)
+
+// rareWordLength is the word length from which words are reported as rare
+const rareWordLength = 14
+
+// Pre-compiled regular expressions for performance
+var (
+	tokenPattern      = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}_.\-/'’]*[\p{L}\p{N}]|[\p{L}\p{N}]`)
+	numberUnitPattern = regexp.MustCompile(`\b\d+(?:[.,]\d+)?\s?(?:ms|µs|ns|[kKMGTP]i?[Bb]|[kKMG]Hz|Hz|kbps|Mbps|Gbps|fps|dpi|px|em|rem|pt|mm|cm|km|kg|mg|ml|°[CF]|rpm|[kM]?W|V|mAh)\b`)
+	consonantRun      = regexp.MustCompile(`(?i)[bcdfghjklmnpqrstvwxz]{5,}`)
+	ordinalPattern    = regexp.MustCompile(`^\d+(?:st|nd|rd|th|s)$`)
+)
+
+// Finding is a token that is likely to be mispronounced.
+type Finding struct {
+	Token    string   // Token as it appears in the text
+	Reason   Reason   // Why the token was flagged
+	Count    int      // Number of occurrences
+	Sources  []string // Sections (or files) where the token occurs, in first-seen order
+	firstPos int
+}
+
+// Report aggregates findings across multiple texts.
+type Report struct {
+	findings map[string]*Finding
+	next     int
+}
+
+// NewReport creates an empty report.
+func NewReport() *Report {
+	return &Report{findings: make(map[string]*Finding)}
+}
+
+// Add scans text and records its findings under source.
+func (r *Report) Add(source, text string) {
+	for _, f := range Scan(text) {
+		existing, ok := r.findings[f.Token]
+		if !ok {
+			existing = &Finding{Token: f.Token, Reason: f.Reason, firstPos: r.next}
+			r.findings[f.Token] = existing
+			r.next++
+		}
+		existing.Count += f.Count
+		if source != "" && !slices.Contains(existing.Sources, source) {
+			existing.Sources = append(existing.Sources, source)
+		}
+	}
+}
+
+// Findings returns the aggregated findings, most frequent first.
+func (r *Report) Findings() []Finding {
+	findings := make([]Finding, 0, len(r.findings))
+	for _, f := range r.findings {
+		findings = append(findings, *f)
+	}
+	slices.SortFunc(findings, func(a, b Finding) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return a.firstPos - b.firstPos
+	})
+	return findings
+}
+
+// Len returns the number of distinct flagged tokens.
+func (r *Report) Len() int {
+	return len(r.findings)
+}
+
+// Scan returns the risky tokens found in text, in order of first occurrence.
+func Scan(text string) []Finding {
+	var findings []Finding
+	index := make(map[string]int)
+
+	record := func(token string, reason Reason) {
+		if i, ok := index[token]; ok {
+			findings[i].Count++
+			return
+		}
+		index[token] = len(findings)
+		findings = append(findings, Finding{Token: token, Reason: reason, Count: 1})
+	}
+
+	// Numbers with units are matched first, since they may span whitespace
+	for _, match := range numberUnitPattern.FindAllString(text, -1) {
+		record(match, ReasonNumberUnit)
+	}
+	text = numberUnitPattern.ReplaceAllString(text, " ")
+
+	for _, token := range tokenPattern.FindAllString(text, -1) {
+		if reason, ok := Classify(token); ok {
+			record(token, reason)
+		}
+	}
+
+	return findings
+}
+
+// Classify reports whether a single token is likely to be mispronounced, and why.
+func Classify(token string) (Reason, bool) {
+	var upper, lower, digits, letters int
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+			letters++
+		case unicode.IsLower(r):
+			lower++
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+
+	if letters == 0 || ordinalPattern.MatchString(token) {
+		return "", false
+	}
+
+	switch {
+	case strings.ContainsAny(token, "_./") || (digits > 0 && letters > 0):
+		return ReasonIdentifier, true
+	case lower > 0 && upper > 1, lower > 0 && upper == 1 && !unicode.IsUpper([]rune(token)[0]):
+		// camelCase, PascalCase with inner capitals, or iPhone-style tokens
+		return ReasonIdentifier, true
+	case upper >= 2 && lower == 0:
+		return ReasonAcronym, true
+	case isRare(token):
+		return ReasonRare, true
+	}
+
+	return "", false
+}
+
+// isRare reports whether a word has an unusual spelling for English speech
+func isRare(word string) bool {
+	if strings.Contains(word, "-") {
+		return false
+	}
+	if len([]rune(word)) >= rareWordLength {
+		return true
+	}
+	if len(word) > 2 && !strings.ContainsAny(strings.ToLower(word), "aeiouy") {
+		return true
+	}
+	return consonantRun.MatchString(word)
+}
+
+// WriteReport writes findings as a plain-text report.
+func WriteReport(w io.Writer, findings []Finding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No tokens likely to be mispronounced were found.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Tokens likely to be mispronounced (%d):\n\n", len(findings)); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		line := fmt.Sprintf("%-30s %-18s x%d", f.Token, f.Reason, f.Count)
+		if len(f.Sources) > 0 {
+			line += "  " + strings.Join(f.Sources, ", ")
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
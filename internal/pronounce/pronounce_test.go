@@ -0,0 +1,133 @@
+package pronounce
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		token      string
+		wantReason Reason
+		wantOK     bool
+	}{
+		{"hello", "", false},
+		{"Hello", "", false},
+		{"don't", "", false},
+		{"well-known", "", false},
+		{"3rd", "", false},
+		{"1990s", "", false},
+		{"2024", "", false},
+		{"getUserID", ReasonIdentifier, true},
+		{"JavaScript", ReasonIdentifier, true},
+		{"iPhone", ReasonIdentifier, true},
+		{"max_retries", ReasonIdentifier, true},
+		{"config.yaml", ReasonIdentifier, true},
+		{"v2", ReasonIdentifier, true},
+		{"SQL", ReasonAcronym, true},
+		{"Pwn", ReasonRare, true},
+		{"Schwarzschild", ReasonRare, true},
+		{"internationalization", ReasonRare, true},
+		{"Mr", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			reason, ok := Classify(tt.token)
+			if ok != tt.wantOK || reason != tt.wantReason {
+				t.Errorf("Classify(%q) = (%q, %v), want (%q, %v)", tt.token, reason, ok, tt.wantReason, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	text := "Set max_retries to 3 and wait 500ms. The API returns 10 GB of JSON, and the API is fast."
+
+	findings := Scan(text)
+
+	want := map[string]struct {
+		reason Reason
+		count  int
+	}{
+		"500ms":       {ReasonNumberUnit, 1},
+		"10 GB":       {ReasonNumberUnit, 1},
+		"max_retries": {ReasonIdentifier, 1},
+		"API":         {ReasonAcronym, 2},
+		"JSON":        {ReasonAcronym, 1},
+	}
+
+	if len(findings) != len(want) {
+		t.Fatalf("Scan() returned %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for _, f := range findings {
+		w, ok := want[f.Token]
+		if !ok {
+			t.Errorf("Unexpected finding %q", f.Token)
+			continue
+		}
+		if f.Reason != w.reason || f.Count != w.count {
+			t.Errorf("Finding %q = (%q, %d), want (%q, %d)", f.Token, f.Reason, f.Count, w.reason, w.count)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	report := NewReport()
+	report.Add("intro.md#Intro", "Run tcpdmp with the CLI.")
+	report.Add("setup.md#Setup", "The CLI reads config.yaml.")
+
+	findings := report.Findings()
+	if report.Len() != 3 || len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %d", len(findings))
+	}
+
+	// Most frequent first
+	if findings[0].Token != "CLI" || findings[0].Count != 2 {
+		t.Errorf("Expected CLI first with count 2, got %+v", findings[0])
+	}
+	if len(findings[0].Sources) != 2 {
+		t.Errorf("Expected CLI in 2 sources, got %v", findings[0].Sources)
+	}
+
+	// Ties keep first-seen order
+	if findings[1].Token != "tcpdmp" || findings[2].Token != "config.yaml" {
+		t.Errorf("Unexpected order: %q, %q", findings[1].Token, findings[2].Token)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []Finding
+		contains []string
+	}{
+		{
+			name:     "no findings",
+			findings: nil,
+			contains: []string{"No tokens likely to be mispronounced"},
+		},
+		{
+			name: "with findings",
+			findings: []Finding{
+				{Token: "SQL", Reason: ReasonAcronym, Count: 2, Sources: []string{"a.md", "b.md"}},
+			},
+			contains: []string{"(1):", "SQL", "acronym", "x2", "a.md, b.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteReport(&buf, tt.findings); err != nil {
+				t.Fatalf("WriteReport() error = %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("Report missing %q:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
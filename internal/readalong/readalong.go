@@ -0,0 +1,108 @@
+// Package readalong builds read-along documents for web audio players.
+// A document holds the spoken text of a section with its sentence and word
+// timings, so a player can highlight the text as the audio plays.
+//
+// Key features:
+//   - Sentence offsets into the section text, as indexed by JavaScript strings
+//   - Sentence and word timings (force-aligned or estimated)
+//   - JSON files next to audio outputs
+package readalong
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+// Estimated is the timing source of documents with estimated timings
+const Estimated = "estimated"
+
+// Document is the read-along JSON stored next to an audio file.
+type Document struct {
+	Audio     string        `json:"audio"` // Audio filename (relative to the document)
+	Title     string        `json:"title"`
+	Text      string        `json:"text"`     // Spoken text, sentences separated by single spaces
+	Duration  float64       `json:"duration"` // Audio duration in seconds
+	Timings   string        `json:"timings"`  // Aligner that produced the timings, or "estimated"
+	Sentences []Sentence    `json:"sentences"`
+	Words     []timing.Word `json:"words"`
+}
+
+// Sentence is a sentence of the document text with its position in the audio.
+type Sentence struct {
+	Text   string  `json:"text"`
+	Offset int     `json:"offset"` // Offset in the document text, in UTF-16 code units
+	Length int     `json:"length"` // Length in UTF-16 code units
+	Start  float64 `json:"start"`  // Start offset in seconds
+	End    float64 `json:"end"`    // End offset in seconds
+}
+
+// Path returns the read-along document path for an audio file.
+func Path(audioPath string) string {
+	return strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".readalong.json"
+}
+
+// Build builds the read-along document of spoken text from its word timings,
+// produced by source (an aligner name, or Estimated). When the words do not
+// match the sentences of the text, timings are estimated from the duration.
+func Build(title, spoken, audioPath string, duration float64, source string, words []timing.Word) Document {
+	sentences := text.SplitSentences(spoken)
+	spans := timing.GroupSentences(sentences, words)
+	if spans == nil {
+		source = Estimated
+		words = timing.EstimateWords(strings.Join(sentences, " "), duration, timing.MethodSyllable)
+		spans = timing.GroupSentences(sentences, words)
+	}
+
+	doc := Document{
+		Audio:     filepath.Base(audioPath),
+		Title:     title,
+		Text:      strings.Join(sentences, " "),
+		Duration:  duration,
+		Timings:   source,
+		Sentences: make([]Sentence, 0, len(spans)),
+		Words:     words,
+	}
+	offset := 0
+	for _, span := range spans {
+		length := utf16Len(span.Text)
+		doc.Sentences = append(doc.Sentences, Sentence{
+			Text:   span.Text,
+			Offset: offset,
+			Length: length,
+			Start:  span.Start,
+			End:    span.End,
+		})
+		offset += length + 1 // Separating space
+	}
+	return doc
+}
+
+// Write writes doc next to audioPath and returns its path.
+func Write(doc Document, audioPath string) (string, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal read-along document: %w", err)
+	}
+
+	path := Path(audioPath)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write read-along document: %w", err)
+	}
+	return path, nil
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,77 @@
+package readalong
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+func TestPath(t *testing.T) {
+	if got := Path("out/section_01_intro.mp3"); got != "out/section_01_intro.readalong.json" {
+		t.Errorf("Path() = %q", got)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	words := []timing.Word{
+		{Text: "Ça", Start: 0, End: 0.3},
+		{Text: "va?", Start: 0.3, End: 0.8},
+		{Text: "Oui", Start: 1, End: 1.4},
+		{Text: "😀.", Start: 1.4, End: 2},
+	}
+
+	tests := []struct {
+		name        string
+		spoken      string
+		words       []timing.Word
+		wantTimings string
+	}{
+		{name: "aligned words", spoken: "Ça va?\n\nOui 😀.", words: words, wantTimings: "whisper"},
+		{name: "mismatching words", spoken: "Ça va? Oui 😀.", words: words[:2], wantTimings: Estimated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Build("Intro", tt.spoken, "out/intro.wav", 2, "whisper", tt.words)
+
+			if doc.Audio != "intro.wav" || doc.Text != "Ça va? Oui 😀." || doc.Timings != tt.wantTimings {
+				t.Errorf("Unexpected document: %+v", doc)
+			}
+			if len(doc.Sentences) != 2 || len(doc.Words) != 4 {
+				t.Fatalf("Expected 2 sentences and 4 words, got %+v", doc)
+			}
+			// Offsets count UTF-16 code units, so the emoji counts twice
+			first, second := doc.Sentences[0], doc.Sentences[1]
+			if first.Offset != 0 || first.Length != 6 || second.Offset != 7 || second.Length != 7 {
+				t.Errorf("Unexpected sentence offsets: %+v", doc.Sentences)
+			}
+			if first.Start != 0 || second.End != 2 {
+				t.Errorf("Unexpected sentence timings: %+v", doc.Sentences)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	audioPath := filepath.Join(t.TempDir(), "intro.wav")
+	doc := Build("Intro", "Hello world.", audioPath, 1, Estimated, timing.EstimateWords("Hello world.", 1, timing.MethodUniform))
+
+	path, err := Write(doc, audioPath)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Document
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if got.Title != "Intro" || len(got.Sentences) != 1 || got.Sentences[0].End != 1 {
+		t.Errorf("Unexpected document: %+v", got)
+	}
+}
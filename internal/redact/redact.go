@@ -0,0 +1,154 @@
+// Package redact detects profanity and personal or secret data in section text
+// and masks or verbalizes it before the text is sent to a TTS provider.
+// Docs occasionally contain contact details or API keys pasted by accident,
+// which should never reach a cloud provider or end up in narration.
+//
+// Key features:
+//   - Detection of email addresses, phone numbers, API keys, and profanity
+//   - Masking ("redacted") or verbalizing ("an email address") of matches
+//   - Plain-text report of redactions with safe previews of the matched text
+package redact
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Category is the kind of redacted content.
+type Category string
+
+const (
+	// CategoryEmail marks email addresses.
+	CategoryEmail Category = "email"
+	// CategoryPhone marks phone numbers.
+	CategoryPhone Category = "phone"
+	// CategorySecret marks API keys and access tokens.
+	CategorySecret Category = "secret"
+	// CategoryProfanity marks swear words.
+	CategoryProfanity Category = "profanity"
+)
+
+// Mode selects how redacted content is spoken.
+type Mode string
+
+const (
+	// ModeMask replaces every match with "redacted".
+	ModeMask Mode = "mask"
+	// ModeVerbalize replaces matches with a description such as "an email address".
+	ModeVerbalize Mode = "verbalize"
+)
+
+// ParseMode converts a string into a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case ModeMask, ModeVerbalize:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("invalid -redact %q: must be 'mask' or 'verbalize'", s)
+	}
+}
+
+// detector matches one category of content
+type detector struct {
+	category Category
+	pattern  *regexp.Regexp
+	spoken   string            // Replacement in ModeVerbalize
+	valid    func(string) bool // Optional check of a match (nil = all matches)
+}
+
+// detectors are applied in order; secrets come first so that keys
+// containing digits are not reported as phone numbers.
+var detectors = []detector{
+	{
+		category: CategorySecret,
+		pattern:  regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{20,}|sk_(?:live|test)_[A-Za-z0-9]{16,}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,}|xox[abprs]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35})`),
+		spoken:   "a secret key",
+	},
+	{
+		category: CategoryEmail,
+		pattern:  regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+		spoken:   "an email address",
+	},
+	{
+		category: CategoryPhone,
+		pattern:  regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\b\d{2,4}[ .-])\d{3,4}[ .-]?\d{3,4}\b`),
+		spoken:   "a phone number",
+		valid: func(s string) bool {
+			digits := strings.Count(strings.Map(keepDigits, s), "") - 1
+			return digits >= 9 && digits <= 15
+		},
+	},
+	{
+		category: CategoryProfanity,
+		pattern:  regexp.MustCompile(`(?i)\b(?:motherfuck\w*|fuck\w*|shit\w*|bullshit|bitch\w*|asshole\w*|bastard\w*|dickhead\w*|piss(?:ed)?|crap\w*|damn\w*)\b`),
+		spoken:   "bleep",
+	},
+}
+
+// keepDigits is a strings.Map function dropping all non-digit runes
+func keepDigits(r rune) rune {
+	if unicode.IsDigit(r) {
+		return r
+	}
+	return -1
+}
+
+// Redaction is a match replaced in the text.
+type Redaction struct {
+	Category Category // Kind of content
+	Preview  string   // Matched text with most characters hidden
+	Source   string   // Section (or file) containing the match
+}
+
+// Redact replaces detected content in text according to mode and
+// returns the new text and the redactions, in detector order.
+func Redact(text string, mode Mode) (string, []Redaction) {
+	var redactions []Redaction
+	for _, d := range detectors {
+		replacement := "redacted"
+		if mode == ModeVerbalize {
+			replacement = d.spoken
+		}
+		text = d.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			redactions = append(redactions, Redaction{Category: d.category, Preview: Preview(match)})
+			return replacement
+		})
+	}
+	return text, redactions
+}
+
+// Preview hides all but the first characters of a match, so reports can be
+// shared without leaking the redacted data.
+func Preview(match string) string {
+	runes := []rune(match)
+	visible := 1
+	if len(runes) >= 12 {
+		visible = 4
+	}
+	return string(runes[:visible]) + strings.Repeat("*", min(len(runes)-visible, 8))
+}
+
+// WriteReport writes redactions as a plain-text report.
+func WriteReport(w io.Writer, redactions []Redaction) error {
+	if len(redactions) == 0 {
+		_, err := fmt.Fprintln(w, "No content was redacted.")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "Redacted before synthesis (%d):\n\n", len(redactions)); err != nil {
+		return err
+	}
+	for _, r := range redactions {
+		line := fmt.Sprintf("%-10s %-14s %s", r.Category, r.Preview, r.Source)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Health check endpoints for container orchestrators
+const (
+	HealthzPath = "/healthz" // Liveness: the process is serving requests
+	ReadyzPath  = "/readyz"  // Readiness: output directories exist and the job queue has room
+)
+
+// healthzHandler reports that the server is alive
+func healthzHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// readyzHandler reports whether the server can accept work: every served
+// output directory exists and the webhook queue (if any) is not full
+func readyzHandler(opts Options) http.Handler {
+	roots := []string{opts.Root}
+	for _, tenant := range opts.Tenants {
+		roots = append(roots, tenant.Root)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(roots, opts.Webhook); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ready")
+	})
+}
+
+// ready returns why the server cannot accept work, or nil
+func ready(roots []string, webhook *Webhook) error {
+	for _, root := range roots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return fmt.Errorf("output directory not available: %s", root)
+		}
+	}
+	if webhook != nil && len(webhook.queue.jobs) >= cap(webhook.queue.jobs) {
+		return fmt.Errorf("job queue is full")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	healthzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HealthzPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	root := t.TempDir()
+	fullQueue := NewWebhook("s3cret", nil, nil, Limits{MaxQueue: 1}, logger.NewDefaultLogger())
+	if err := fullQueue.queue.submit("repo", func(ctx context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantStatus int
+	}{
+		{name: "ready", opts: Options{Root: root}, wantStatus: http.StatusOK},
+		{name: "missing output directory", opts: Options{Root: filepath.Join(root, "missing")}, wantStatus: http.StatusServiceUnavailable},
+		{name: "missing tenant directory", opts: Options{Root: root, Tenants: []Tenant{{Name: "docs", Root: filepath.Join(root, "docs")}}}, wantStatus: http.StatusServiceUnavailable},
+		{name: "full job queue", opts: Options{Root: root, Webhook: fullQueue}, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			readyzHandler(tt.opts).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadyzPath, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Default server limits
+const (
+	DefaultMaxJobs      = 1               // Jobs running at once
+	DefaultMaxQueue     = 16              // Jobs waiting to run
+	DefaultMaxBodyBytes = 5 * 1024 * 1024 // Request body size
+)
+
+// Limits bound the work a shared server accepts, so a single client
+// cannot overwhelm it. Zero values use the defaults.
+type Limits struct {
+	MaxJobs       int   // Jobs running at once (default: DefaultMaxJobs)
+	MaxQueue      int   // Jobs waiting to run; further requests get 503 (default: DefaultMaxQueue)
+	MaxJobsPerKey int   // Queued or running jobs per client key; further requests get 429 (0 = unlimited)
+	MaxBodyBytes  int64 // Request body size; larger requests get 413 (default: DefaultMaxBodyBytes)
+}
+
+// withDefaults returns the limits with zero values replaced by the defaults
+func (l Limits) withDefaults() Limits {
+	if l.MaxJobs <= 0 {
+		l.MaxJobs = DefaultMaxJobs
+	}
+	if l.MaxQueue <= 0 {
+		l.MaxQueue = DefaultMaxQueue
+	}
+	if l.MaxBodyBytes <= 0 {
+		l.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return l
+}
+
+// Errors returned when a job is refused
+var (
+	errQueueFull  = errors.New("job queue is full")
+	errClientBusy = errors.New("too many jobs for this client")
+)
+
+// job is a unit of work submitted by a client
+type job struct {
+	key string
+	run func(ctx context.Context)
+}
+
+// jobQueue runs jobs on a fixed number of workers, bounding the jobs
+// waiting to run and the jobs in flight per client key
+type jobQueue struct {
+	limits Limits
+	jobs   chan job
+
+	mu     sync.Mutex
+	perKey map[string]int
+}
+
+// newJobQueue creates a job queue with the given limits
+func newJobQueue(limits Limits) *jobQueue {
+	limits = limits.withDefaults()
+	return &jobQueue{
+		limits: limits,
+		jobs:   make(chan job, limits.MaxQueue),
+		perKey: make(map[string]int),
+	}
+}
+
+// submit queues run for key, refusing it when the queue is full or
+// the client already has MaxJobsPerKey jobs queued or running
+func (q *jobQueue) submit(key string, run func(ctx context.Context)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.limits.MaxJobsPerKey > 0 && q.perKey[key] >= q.limits.MaxJobsPerKey {
+		return errClientBusy
+	}
+	select {
+	case q.jobs <- job{key: key, run: run}:
+		q.perKey[key]++
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// run processes jobs on MaxJobs workers until ctx is canceled
+func (q *jobQueue) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range q.limits.MaxJobs {
+		wg.Go(func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-q.jobs:
+					j.run(ctx)
+					q.done(j.key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// done releases a finished job of key
+func (q *jobQueue) done(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.perKey[key]--; q.perKey[key] <= 0 {
+		delete(q.perKey, key)
+	}
+}
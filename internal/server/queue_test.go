@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobQueueRunsConcurrently(t *testing.T) {
+	q := newJobQueue(Limits{MaxJobs: 2, MaxQueue: 4})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.run(ctx)
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	finished := make(chan struct{}, 3)
+	for range 3 {
+		err := q.submit("client", func(ctx context.Context) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			finished <- struct{}{}
+		})
+		if err != nil {
+			t.Fatalf("submit() error = %v", err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	for range 3 {
+		select {
+		case <-finished:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Queued jobs did not finish")
+		}
+	}
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("Peak concurrency = %d, want 2", got)
+	}
+}
+
+func TestJobQueueReleasesClientSlots(t *testing.T) {
+	q := newJobQueue(Limits{MaxJobsPerKey: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.run(ctx)
+
+	done := make(chan struct{})
+	if err := q.submit("client", func(ctx context.Context) { close(done) }); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+	<-done
+
+	// The slot is released once the job finishes
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := q.submit("client", func(ctx context.Context) {})
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("submit() error = %v after the previous job finished", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,262 @@
+// Package server serves generated audio over HTTP for review.
+// It renders an HTML index of the output tree with inline audio players
+// and streams files with HTTP range support.
+//
+// Key features:
+//   - HTML directory index with <audio> players
+//   - Manifest status (ok, failed, flagged, skipped) shown next to each file
+//   - Range requests for audio streaming and seeking
+//   - Path traversal protection
+//   - GitHub push webhook regenerating changed markdown files
+//   - Token authentication with a separate output directory per tenant
+//   - Liveness and readiness endpoints for container deployments
+//   - Graceful shutdown on interrupt
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// DefaultAddr is the default listen address for the review server
+const DefaultAddr = "localhost:8080"
+
+// audioExtensions lists the file extensions rendered with an audio player
+var audioExtensions = []string{".aiff", ".m4a", ".mp3", ".ogg", ".wav"}
+
+// entry is a file or directory listed in the index
+type entry struct {
+	Name   string
+	URL    string
+	IsDir  bool
+	Audio  bool
+	Status manifest.Status
+	Reason string
+}
+
+// indexData is the data rendered by indexTemplate
+type indexData struct {
+	Path    string
+	Parent  string
+	Entries []entry
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>md2audio - {{.Path}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #222; }
+li { margin: 0.5rem 0; list-style: none; }
+audio { display: block; margin-top: 0.25rem; }
+.status { font-size: 0.8rem; padding: 0 0.4rem; border-radius: 0.2rem; margin-left: 0.5rem; }
+.flagged { background: #fff3cd; }
+.failed { background: #f8d7da; }
+.skipped { background: #e2e3e5; }
+.reason { color: #666; font-size: 0.85rem; }
+</style>
+</head>
+<body>
+<h1>{{.Path}}</h1>
+<ul>
+{{- if .Parent}}
+<li><a href="{{.Parent}}">../</a></li>
+{{- end}}
+{{- range .Entries}}
+<li>
+{{- if .IsDir}}<a href="{{.URL}}">{{.Name}}/</a>
+{{- else}}<a href="{{.URL}}">{{.Name}}</a>
+{{- if and .Status (ne .Status "ok")}}<span class="status {{.Status}}">{{.Status}}</span>{{end}}
+{{- if .Reason}} <span class="reason">{{.Reason}}</span>{{end}}
+{{- if .Audio}}<audio controls preload="none" src="{{.URL}}"></audio>{{end}}
+{{- end}}
+</li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+// Handler returns an HTTP handler serving the output tree rooted at root.
+// Directories are rendered as an HTML index; files are served with range support.
+func Handler(root string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		urlPath := path.Clean("/" + r.URL.Path)
+		fsPath := filepath.Join(root, filepath.FromSlash(urlPath))
+
+		info, err := os.Stat(fsPath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !info.IsDir() {
+			http.ServeFile(w, r, fsPath)
+			return
+		}
+
+		// Directory URLs end with a slash so relative links resolve
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, urlPath+"/", http.StatusMovedPermanently)
+			return
+		}
+
+		entries, err := listDirectory(fsPath, urlPath)
+		if err != nil {
+			http.Error(w, "failed to read directory", http.StatusInternalServerError)
+			return
+		}
+
+		data := indexData{Path: urlPath, Entries: entries}
+		if urlPath != "/" {
+			data.Parent = path.Dir(strings.TrimSuffix(urlPath, "/"))
+			if data.Parent != "/" {
+				data.Parent += "/"
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := indexTemplate.Execute(w, data); err != nil {
+			http.Error(w, "failed to render index", http.StatusInternalServerError)
+		}
+	})
+}
+
+// listDirectory lists a directory for the index, directories first,
+// annotating audio files with their manifest status when available
+func listDirectory(dir, urlPath string) ([]entry, error) {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]manifest.Entry)
+	if m, err := manifest.Load(manifest.PathFor(dir)); err == nil {
+		for _, e := range m.Entries {
+			statuses[filepath.Base(e.Output)] = e
+		}
+	}
+
+	entries := make([]entry, 0, len(items))
+	for _, item := range items {
+		name := item.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		e := entry{
+			Name:  name,
+			URL:   path.Join(urlPath, name),
+			IsDir: item.IsDir(),
+			Audio: !item.IsDir() && slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(name))),
+		}
+		if e.IsDir {
+			e.URL += "/"
+		}
+		if m, ok := statuses[name]; ok {
+			e.Status = m.Status
+			e.Reason = m.Reason
+		}
+		entries = append(entries, e)
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return entries, nil
+}
+
+// Options configures Serve
+type Options struct {
+	Addr    string   // Listen address (default: DefaultAddr)
+	Root    string   // Output directory served for review
+	Tenants []Tenant // Tenants authenticated by token, each served its own output directory (nil = open access to Root)
+	Webhook *Webhook // GitHub push webhook mounted at WebhookPath (nil = disabled)
+}
+
+// Serve serves the output directory until ctx is canceled.
+func Serve(ctx context.Context, opts Options, log logger.LoggerInterface) error {
+	root, addr := opts.Root, opts.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("output directory not found: %s", root)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(HealthzPath, healthzHandler())
+	mux.Handle(ReadyzPath, readyzHandler(opts))
+	if len(opts.Tenants) > 0 {
+		mux.Handle("/", tenantHandler(opts.Tenants))
+	} else {
+		mux.Handle("/", Handler(root))
+	}
+	if opts.Webhook != nil {
+		mux.Handle(WebhookPath, opts.Webhook)
+		go opts.Webhook.Run(ctx)
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	log.Success(fmt.Sprintf("Serving %s at http://%s/", root, addr))
+	if len(opts.Tenants) > 0 {
+		log.Info(fmt.Sprintf("Token authentication enabled for %d tenant(s)", len(opts.Tenants)))
+	}
+	if opts.Webhook != nil {
+		log.Info(fmt.Sprintf("GitHub webhook: http://%s%s", addr, WebhookPath))
+	}
+	log.Faint("Press Ctrl+C to stop")
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to stop server: %w", err)
+		}
+		log.Blank()
+		log.Info("Server stopped")
+		return nil
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// newOutputTree creates an output tree with audio, subtitles and a manifest
+func newOutputTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	guideDir := filepath.Join(root, "guide")
+	if err := os.MkdirAll(guideDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(guideDir, "section_01_intro.mp3"): "0123456789",
+		filepath.Join(guideDir, "section_01_intro.srt"): "1\n00:00:00,000 --> 00:00:01,000\nHello\n",
+		filepath.Join(guideDir, "section_02_setup.mp3"): "abcdefghij",
+		filepath.Join(root, ".hidden"):                  "secret",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	m := manifest.New()
+	m.Put(manifest.Entry{Title: "Intro", Output: filepath.Join(guideDir, "section_01_intro.mp3"), Status: manifest.StatusOK})
+	m.Put(manifest.Entry{Title: "Setup", Output: filepath.Join(guideDir, "section_02_setup.mp3"), Status: manifest.StatusFlagged, Reason: "word error rate 40% exceeds 25%"})
+	if err := m.Save(manifest.PathFor(guideDir)); err != nil {
+		t.Fatalf("Failed to save manifest: %v", err)
+	}
+
+	return root
+}
+
+func TestHandler(t *testing.T) {
+	root := newOutputTree(t)
+	handler := Handler(root)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		header      map[string]string
+		wantStatus  int
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "root index",
+			path:        "/",
+			wantStatus:  http.StatusOK,
+			contains:    []string{`href="/guide/"`},
+			notContains: []string{".hidden", `href="/"`},
+		},
+		{
+			name:       "directory index with players and statuses",
+			path:       "/guide/",
+			wantStatus: http.StatusOK,
+			contains: []string{
+				`<audio controls preload="none" src="/guide/section_01_intro.mp3">`,
+				`href="/guide/section_01_intro.srt"`,
+				`<span class="status flagged">flagged</span>`,
+				"word error rate 40% exceeds 25%",
+				`href="/">../</a>`,
+			},
+			notContains: []string{`src="/guide/section_01_intro.srt"`, `status ok`},
+		},
+		{
+			name:       "directory without trailing slash redirects",
+			path:       "/guide",
+			wantStatus: http.StatusMovedPermanently,
+		},
+		{
+			name:       "audio file",
+			path:       "/guide/section_01_intro.mp3",
+			wantStatus: http.StatusOK,
+			contains:   []string{"0123456789"},
+		},
+		{
+			name:       "range request",
+			path:       "/guide/section_02_setup.mp3",
+			header:     map[string]string{"Range": "bytes=2-4"},
+			wantStatus: http.StatusPartialContent,
+			contains:   []string{"cde"},
+		},
+		{
+			name:       "traversal stays inside root",
+			path:       "/../../etc/passwd",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "missing file",
+			path:       "/guide/missing.mp3",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			path:       "/",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			body := rec.Body.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(body, s) {
+					t.Errorf("Body missing %q:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(body, s) {
+					t.Errorf("Body should not contain %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	root := newOutputTree(t)
+	log := logger.NewDefaultLogger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, Options{Addr: "127.0.0.1:0", Root: root}, log)
+	}()
+
+	// Give the server a moment to start, then stop it
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not stop after context cancellation")
+	}
+}
+
+func TestServeMissingDirectory(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	err := Serve(context.Background(), Options{Addr: "127.0.0.1:0", Root: filepath.Join(t.TempDir(), "missing")}, log)
+	if err == nil {
+		t.Error("Serve() should fail for a missing directory")
+	}
+}
+
+func TestListDirectoryOrder(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.mp3", "a.wav"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "z"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	entries, err := listDirectory(dir, "/")
+	if err != nil {
+		t.Fatalf("listDirectory() error = %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if got := strings.Join(names, ","); got != "z,a.wav,b.mp3" {
+		t.Errorf("Entry order = %s, want z,a.wav,b.mp3", got)
+	}
+	if !entries[1].Audio {
+		t.Error("Expected a.wav to be rendered as audio")
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Tenant is a client of a shared server, identified by its token.
+// The token authenticates review requests and signs the tenant's webhook deliveries.
+type Tenant struct {
+	Name  string // Tenant name, used as the client key for limits
+	Token string // Secret token
+	Root  string // Output directory served to the tenant
+}
+
+// authenticate returns the tenant whose token is presented as a bearer
+// token or as the password of HTTP basic authentication
+func authenticate(r *http.Request, tenants []Tenant) (Tenant, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	if !ok || token == "" {
+		return Tenant{}, false
+	}
+	for _, tenant := range tenants {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(tenant.Token)) == 1 {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// tenantHandler serves each authenticated tenant its own output directory
+func tenantHandler(tenants []Tenant) http.Handler {
+	handlers := make(map[string]http.Handler, len(tenants))
+	for _, tenant := range tenants {
+		handlers[tenant.Name] = Handler(tenant.Root)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, ok := authenticate(r, tenants)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="md2audio"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handlers[tenant.Name].ServeHTTP(w, r)
+	})
+}
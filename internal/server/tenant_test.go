@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTenantHandler(t *testing.T) {
+	root := t.TempDir()
+	tenants := []Tenant{
+		{Name: "docs", Token: "docs-token", Root: filepath.Join(root, "docs")},
+		{Name: "blog", Token: "blog-token", Root: filepath.Join(root, "blog")},
+	}
+	for _, tenant := range tenants {
+		if err := os.MkdirAll(tenant.Root, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tenant.Root, tenant.Name+".mp3"), []byte(tenant.Name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := tenantHandler(tenants)
+
+	tests := []struct {
+		name       string
+		path       string
+		auth       func(r *http.Request)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "missing token",
+			path:       "/",
+			auth:       func(r *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown token",
+			path:       "/",
+			auth:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bearer token serves the tenant's directory",
+			path:       "/docs.mp3",
+			auth:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer docs-token") },
+			wantStatus: http.StatusOK,
+			wantBody:   "docs",
+		},
+		{
+			name:       "basic auth password",
+			path:       "/blog.mp3",
+			auth:       func(r *http.Request) { r.SetBasicAuth("anyone", "blog-token") },
+			wantStatus: http.StatusOK,
+			wantBody:   "blog",
+		},
+		{
+			name:       "other tenants' files are not visible",
+			path:       "/blog.mp3",
+			auth:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer docs-token") },
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			tt.auth(req)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("Body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
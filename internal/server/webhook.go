@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// WebhookPath is the endpoint receiving GitHub push webhooks
+const WebhookPath = "/webhook"
+
+// RegenerateFunc regenerates the audio of markdown files for a tenant
+// (empty without tenants), given as slash-separated paths relative to the
+// repository root.
+type RegenerateFunc func(ctx context.Context, tenant string, files []string) error
+
+// Webhook receives GitHub push events and regenerates the audio of
+// changed markdown files in the background, within the server limits.
+type Webhook struct {
+	tenants    []Tenant
+	paths      []string
+	regenerate RegenerateFunc
+	log        logger.LoggerInterface
+	queue      *jobQueue
+}
+
+// NewWebhook creates a webhook verifying deliveries with secret.
+// Only markdown files matching paths (path.Match patterns or directory
+// prefixes) trigger regeneration; an empty list matches every file.
+// Per-key limits apply to each repository sending pushes.
+func NewWebhook(secret string, paths []string, regenerate RegenerateFunc, limits Limits, log logger.LoggerInterface) *Webhook {
+	return NewTenantWebhook([]Tenant{{Token: secret}}, paths, regenerate, limits, log)
+}
+
+// NewTenantWebhook creates a webhook shared by tenants, each signing its
+// deliveries with its own token. Per-key limits apply to each tenant.
+func NewTenantWebhook(tenants []Tenant, paths []string, regenerate RegenerateFunc, limits Limits, log logger.LoggerInterface) *Webhook {
+	return &Webhook{
+		tenants:    tenants,
+		paths:      paths,
+		regenerate: regenerate,
+		log:        log,
+		queue:      newJobQueue(limits),
+	}
+}
+
+// pushEvent holds the fields of a GitHub push event payload used by the webhook
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+// ServeHTTP handles a webhook delivery. Push events touching matching
+// markdown files are queued and acknowledged with 202 Accepted.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, wh.queue.limits.MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	tenant, ok := wh.signedBy(body, r.Header.Get("X-Hub-Signature-256"))
+	if !ok {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "push":
+	default:
+		http.Error(w, fmt.Sprintf("unsupported event %q", event), http.StatusBadRequest)
+		return
+	}
+
+	var push pushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		http.Error(w, "invalid push payload", http.StatusBadRequest)
+		return
+	}
+
+	files := wh.changedFiles(push)
+	if len(files) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	repo := push.Repository.FullName
+	key := repo
+	if tenant.Name != "" {
+		key = tenant.Name
+	}
+	err = wh.queue.submit(key, func(ctx context.Context) {
+		if err := wh.regenerate(ctx, tenant.Name, files); err != nil {
+			wh.log.Warning(fmt.Sprintf("Webhook: regeneration failed for %s: %v", key, err))
+		}
+	})
+	switch {
+	case errors.Is(err, errClientBusy):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		wh.log.Info(fmt.Sprintf("Webhook: queued %d changed markdown file(s) from %s %s for %s", len(files), repo, push.Ref, key))
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// Run regenerates queued pushes until ctx is canceled
+func (wh *Webhook) Run(ctx context.Context) {
+	wh.queue.run(ctx)
+}
+
+// signedBy returns the tenant whose token produced the X-Hub-Signature-256
+// header of the body
+func (wh *Webhook) signedBy(body []byte, signature string) (Tenant, bool) {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return Tenant{}, false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return Tenant{}, false
+	}
+	for _, tenant := range wh.tenants {
+		mac := hmac.New(sha256.New, []byte(tenant.Token))
+		mac.Write(body)
+		if hmac.Equal(got, mac.Sum(nil)) {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// changedFiles returns the matching markdown files added or modified by a
+// push and still present after its last commit, in first-seen order
+func (wh *Webhook) changedFiles(push pushEvent) []string {
+	var files []string
+	for _, commit := range push.Commits {
+		for _, file := range slices.Concat(commit.Added, commit.Modified) {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+		files = slices.DeleteFunc(files, func(file string) bool {
+			return slices.Contains(commit.Removed, file)
+		})
+	}
+	return slices.DeleteFunc(files, func(file string) bool {
+		return path.Ext(file) != ".md" || !matchesPaths(file, wh.paths)
+	})
+}
+
+// matchesPaths reports whether a repository file matches one of the
+// configured patterns, either as a path.Match pattern or a directory prefix
+func matchesPaths(file string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if dir := strings.TrimSuffix(pattern, "/"); strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/logger"
+)
+
+// sign returns the X-Hub-Signature-256 header value for body
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const pushPayload = `{
+	"ref": "refs/heads/main",
+	"repository": {"full_name": "acme/docs"},
+	"commits": [
+		{"added": ["docs/intro.md", "docs/diagram.png"], "modified": ["README.md"], "removed": []},
+		{"added": ["docs/draft.md"], "modified": ["docs/intro.md", "blog/post.md"], "removed": []},
+		{"added": [], "modified": [], "removed": ["docs/draft.md"]}
+	]
+}`
+
+func TestWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		event      string
+		body       string
+		signature  string
+		wantStatus int
+		wantFiles  []string
+	}{
+		{
+			name:       "push with matching markdown files",
+			event:      "push",
+			body:       pushPayload,
+			wantStatus: http.StatusAccepted,
+			wantFiles:  []string{"docs/intro.md"},
+		},
+		{
+			name:       "ping",
+			event:      "ping",
+			body:       `{"zen": "Keep it simple."}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "push without matching files",
+			event:      "push",
+			body:       `{"commits": [{"modified": ["blog/post.md"]}]}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid signature",
+			event:      "push",
+			body:       pushPayload,
+			signature:  sign("wrong", pushPayload),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unsupported event",
+			event:      "issues",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh := NewWebhook("s3cret", []string{"docs/"}, nil, Limits{}, logger.NewDefaultLogger())
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			req := httptest.NewRequest(method, WebhookPath, strings.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", tt.event)
+			signature := tt.signature
+			if signature == "" {
+				signature = sign("s3cret", tt.body)
+			}
+			req.Header.Set("X-Hub-Signature-256", signature)
+			rec := httptest.NewRecorder()
+
+			wh.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := len(wh.queue.jobs); got != len(tt.wantFiles) {
+				t.Errorf("Queued jobs = %d, want %d", got, len(tt.wantFiles))
+			}
+		})
+	}
+}
+
+func TestWebhookRun(t *testing.T) {
+	done := make(chan []string, 1)
+	regenerate := func(ctx context.Context, tenant string, files []string) error {
+		done <- files
+		return nil
+	}
+	wh := NewWebhook("s3cret", []string{"docs/"}, regenerate, Limits{}, logger.NewDefaultLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wh.Run(ctx)
+
+	req := httptest.NewRequest(http.MethodPost, WebhookPath, strings.NewReader(pushPayload))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", sign("s3cret", pushPayload))
+	wh.ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case files := <-done:
+		if !slices.Equal(files, []string{"docs/intro.md"}) {
+			t.Errorf("Regenerated files = %v, want [docs/intro.md]", files)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Queued push was not regenerated")
+	}
+}
+
+func TestMatchesPaths(t *testing.T) {
+	tests := []struct {
+		file     string
+		patterns []string
+		want     bool
+	}{
+		{"docs/intro.md", nil, true},
+		{"docs/intro.md", []string{"docs"}, true},
+		{"docs/guides/setup.md", []string{"docs/"}, true},
+		{"docs-old/intro.md", []string{"docs"}, false},
+		{"guides/setup.md", []string{"guides/*.md"}, true},
+		{"guides/deep/setup.md", []string{"guides/*.md"}, false},
+		{"README.md", []string{"docs/", "*.md"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := matchesPaths(tt.file, tt.patterns); got != tt.want {
+			t.Errorf("matchesPaths(%q, %v) = %v, want %v", tt.file, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookLimits(t *testing.T) {
+	log := logger.NewDefaultLogger()
+	send := func(wh *Webhook, body string) int {
+		req := httptest.NewRequest(http.MethodPost, WebhookPath, strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", sign("s3cret", body))
+		rec := httptest.NewRecorder()
+		wh.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("body too large", func(t *testing.T) {
+		wh := NewWebhook("s3cret", nil, nil, Limits{MaxBodyBytes: 16}, log)
+		if got := send(wh, pushPayload); got != http.StatusRequestEntityTooLarge {
+			t.Errorf("Status = %d, want %d", got, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("per-repository quota", func(t *testing.T) {
+		wh := NewWebhook("s3cret", nil, nil, Limits{MaxJobsPerKey: 1}, log)
+		if got := send(wh, pushPayload); got != http.StatusAccepted {
+			t.Fatalf("First push status = %d, want %d", got, http.StatusAccepted)
+		}
+		if got := send(wh, pushPayload); got != http.StatusTooManyRequests {
+			t.Errorf("Second push status = %d, want %d", got, http.StatusTooManyRequests)
+		}
+		other := strings.Replace(pushPayload, "acme/docs", "acme/blog", 1)
+		if got := send(wh, other); got != http.StatusAccepted {
+			t.Errorf("Other repository status = %d, want %d", got, http.StatusAccepted)
+		}
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		wh := NewWebhook("s3cret", nil, nil, Limits{MaxQueue: 1}, log)
+		if got := send(wh, pushPayload); got != http.StatusAccepted {
+			t.Fatalf("First push status = %d, want %d", got, http.StatusAccepted)
+		}
+		if got := send(wh, pushPayload); got != http.StatusServiceUnavailable {
+			t.Errorf("Second push status = %d, want %d", got, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func TestTenantWebhook(t *testing.T) {
+	done := make(chan string, 1)
+	regenerate := func(ctx context.Context, tenant string, files []string) error {
+		done <- tenant
+		return nil
+	}
+	tenants := []Tenant{{Name: "docs", Token: "docs-token"}, {Name: "blog", Token: "blog-token"}}
+	wh := NewTenantWebhook(tenants, nil, regenerate, Limits{}, logger.NewDefaultLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wh.Run(ctx)
+
+	req := httptest.NewRequest(http.MethodPost, WebhookPath, strings.NewReader(pushPayload))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", sign("blog-token", pushPayload))
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	select {
+	case tenant := <-done:
+		if tenant != "blog" {
+			t.Errorf("Regenerated for tenant %q, want blog", tenant)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Queued push was not regenerated")
+	}
+}
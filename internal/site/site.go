@@ -0,0 +1,129 @@
+// Package site generates integration assets for static site generators.
+// For each markdown page it writes an HTML player snippet, and records the
+// page's audio in a JSON index keyed by the page slug, so sites built with
+// Hugo, Astro, and the like can embed a "listen to this page" player with
+// a single include.
+//
+// Key features:
+//   - Page slugs from markdown file paths
+//   - HTML player snippets (one audio element per section)
+//   - JSON index of pages, merged across runs
+package site
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/text"
+)
+
+// IndexFile is the name of the page index in the assets directory
+const IndexFile = "index.json"
+
+// Track is the audio of one section of a page.
+type Track struct {
+	Index     int     `json:"index"`              // 1-based section index
+	Sentence  int     `json:"sentence,omitempty"` // 1-based sentence index (-granularity sentence)
+	Title     string  `json:"title"`
+	Audio     string  `json:"audio"`               // Audio URL
+	Duration  float64 `json:"duration,omitempty"`  // Audio duration in seconds (0 = unknown)
+	ReadAlong string  `json:"readalong,omitempty"` // Read-along document URL
+}
+
+// Page is the audio of a markdown page.
+type Page struct {
+	Slug    string  `json:"slug"`
+	Source  string  `json:"source"`  // Markdown file path relative to the input directory
+	Snippet string  `json:"snippet"` // HTML snippet path relative to the assets directory
+	Tracks  []Track `json:"tracks"`
+}
+
+// Slug returns the slug of a markdown file from its path relative to the input
+// directory: the path without extension, with each segment sanitized like
+// output filenames (e.g., "Guides/Getting Started.md" -> "guides/getting_started").
+func Slug(relPath string) string {
+	segments := strings.Split(filepath.ToSlash(strings.TrimSuffix(relPath, filepath.Ext(relPath))), "/")
+	for i, segment := range segments {
+		segments[i] = text.SanitizeFilename(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// snippetTemplate renders the player snippet of a page
+var snippetTemplate = template.Must(template.New("snippet").Parse(`<!-- md2audio: {{.Slug}} -->
+<section class="md2audio-player" data-slug="{{.Slug}}">
+  <p class="md2audio-player-label">Listen to this page</p>
+  <ol class="md2audio-player-tracks">
+{{- range .Tracks}}
+    <li><span class="md2audio-player-title">{{.Title}}</span> <audio controls preload="none" src="{{.Audio}}"{{if .ReadAlong}} data-readalong="{{.ReadAlong}}"{{end}}></audio></li>
+{{- end}}
+  </ol>
+</section>
+`))
+
+// WriteSnippet writes the HTML player snippet of a page.
+func WriteSnippet(w io.Writer, page Page) error {
+	if err := snippetTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to render player snippet: %w", err)
+	}
+	return nil
+}
+
+// LoadIndex reads the page index in dir, keyed by slug. A missing index is empty.
+func LoadIndex(dir string) (map[string]Page, error) {
+	index := make(map[string]Page)
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site index: %w", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse site index: %w", err)
+	}
+	return index, nil
+}
+
+// Write writes the player snippet of a page into dir and records the page in
+// the index, replacing its previous entry. It returns the snippet path.
+func Write(dir string, page Page) (string, error) {
+	page.Snippet = page.Slug + ".html"
+	snippetPath := filepath.Join(dir, filepath.FromSlash(page.Snippet))
+	if err := os.MkdirAll(filepath.Dir(snippetPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create site assets directory: %w", err)
+	}
+
+	f, err := os.Create(snippetPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create player snippet: %w", err)
+	}
+	if err := WriteSnippet(f, page); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write player snippet: %w", err)
+	}
+
+	index, err := LoadIndex(dir)
+	if err != nil {
+		return "", err
+	}
+	index[page.Slug] = page
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal site index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, IndexFile), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write site index: %w", err)
+	}
+	return snippetPath, nil
+}
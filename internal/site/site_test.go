@@ -0,0 +1,82 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"intro.md":                      "intro",
+		"Guides/Getting Started.md":     "guides/getting_started",
+		"docs/api/reference.markdown":   "docs/api/reference",
+		filepath.Join("course", "q.md"): "course/q",
+	}
+	for relPath, want := range tests {
+		if got := Slug(relPath); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", relPath, got, want)
+		}
+	}
+}
+
+func TestWriteSnippet(t *testing.T) {
+	page := Page{
+		Slug: "guides/intro",
+		Tracks: []Track{
+			{Index: 1, Title: "Q&A <live>", Audio: "/audio/guides/intro/section_01_qa.mp3", ReadAlong: "/audio/guides/intro/section_01_qa.readalong.json"},
+			{Index: 2, Title: "Outro", Audio: "/audio/guides/intro/section_02_outro.mp3"},
+		},
+	}
+
+	var b strings.Builder
+	if err := WriteSnippet(&b, page); err != nil {
+		t.Fatalf("WriteSnippet() error = %v", err)
+	}
+	snippet := b.String()
+	for _, want := range []string{
+		`<section class="md2audio-player" data-slug="guides/intro">`,
+		`<span class="md2audio-player-title">Q&amp;A &lt;live&gt;</span> <audio controls preload="none" src="/audio/guides/intro/section_01_qa.mp3" data-readalong="/audio/guides/intro/section_01_qa.readalong.json"></audio>`,
+		`<audio controls preload="none" src="/audio/guides/intro/section_02_outro.mp3"></audio>`,
+	} {
+		if !strings.Contains(snippet, want) {
+			t.Errorf("Expected %q in snippet:\n%s", want, snippet)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, page := range []Page{
+		{Slug: "intro", Source: "intro.md", Tracks: []Track{{Index: 1, Title: "Old", Audio: "old.mp3"}}},
+		{Slug: "guides/setup", Source: "guides/setup.md", Tracks: []Track{{Index: 1, Title: "Setup", Audio: "guides/setup/section_01_setup.mp3"}}},
+		{Slug: "intro", Source: "intro.md", Tracks: []Track{{Index: 1, Title: "New", Audio: "new.mp3"}}},
+	} {
+		if _, err := Write(dir, page); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "guides", "setup.html")); err != nil {
+		t.Errorf("Expected nested snippet: %v", err)
+	}
+	index, err := LoadIndex(dir)
+	if err != nil {
+		t.Fatalf("LoadIndex() error = %v", err)
+	}
+	if len(index) != 2 {
+		t.Fatalf("Expected 2 pages, got %+v", index)
+	}
+	if intro := index["intro"]; intro.Snippet != "intro.html" || len(intro.Tracks) != 1 || intro.Tracks[0].Title != "New" {
+		t.Errorf("Expected the page to be replaced, got %+v", intro)
+	}
+}
+
+func TestLoadIndexMissing(t *testing.T) {
+	index, err := LoadIndex(t.TempDir())
+	if err != nil || len(index) != 0 {
+		t.Errorf("LoadIndex() = %v, %v, want empty index", index, err)
+	}
+}
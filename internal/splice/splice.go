@@ -0,0 +1,184 @@
+// Package splice patches section audio at sentence granularity.
+// It diffs the old and new sentences of an edited section, and joins the
+// unchanged spans of the existing audio with newly synthesized sentences
+// using short crossfades, so small edits do not require regenerating the
+// whole section.
+//
+// Key features:
+//   - Sentence-level diff (longest common subsequence)
+//   - Cut points between sentences from sentence timings
+//   - Crossfaded joins with ffmpeg
+package splice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+// DefaultCrossfade is the crossfade between joined segments, in seconds
+const DefaultCrossfade = 0.05
+
+// spliceSampleRate is the sample rate segments are converted to before joining
+const spliceSampleRate = 44100
+
+// Op is a step turning the old sentences into the new ones: either keep
+// the old sentences [OldStart, OldEnd), or replace them with New (an
+// insertion when the old range is empty, a deletion when New is empty).
+type Op struct {
+	Keep     bool
+	OldStart int
+	OldEnd   int
+	New      []string
+}
+
+// Diff returns the operations turning old into new, keeping their longest
+// common subsequence of sentences.
+func Diff(old, new []string) []Op {
+	// lcs[i][j] is the common subsequence length of old[i:] and new[j:]
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		if i < len(old) && j < len(new) && old[i] == new[j] {
+			if n := len(ops); n > 0 && ops[n-1].Keep {
+				ops[n-1].OldEnd++
+			} else {
+				ops = append(ops, Op{Keep: true, OldStart: i, OldEnd: i + 1})
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Open or extend a replacement
+		if n := len(ops); n == 0 || ops[n-1].Keep {
+			ops = append(ops, Op{OldStart: i, OldEnd: i})
+		}
+		op := &ops[len(ops)-1]
+		if j < len(new) && (i == len(old) || lcs[i][j+1] >= lcs[i+1][j]) {
+			op.New = append(op.New, new[j])
+			j++
+		} else {
+			op.OldEnd++
+			i++
+		}
+	}
+	return ops
+}
+
+// Changed returns the number of new sentences to synthesize
+func Changed(ops []Op) int {
+	n := 0
+	for _, op := range ops {
+		n += len(op.New)
+	}
+	return n
+}
+
+// Kept reports whether any old sentences are kept
+func Kept(ops []Op) bool {
+	for _, op := range ops {
+		if op.Keep {
+			return true
+		}
+	}
+	return false
+}
+
+// Cut returns the cut point before sentence i of the old audio, halfway through
+// the pause between sentences. Cutting before the first sentence returns 0 and
+// after the last returns -1 (the end of the audio).
+func Cut(sentences []timing.Sentence, i int) float64 {
+	switch {
+	case i <= 0:
+		return 0
+	case i >= len(sentences):
+		return -1
+	default:
+		return (sentences[i-1].End + sentences[i].Start) / 2
+	}
+}
+
+// Segment is a span of an audio file to join.
+type Segment struct {
+	Path  string
+	Start float64 // Start offset in seconds
+	End   float64 // End offset in seconds (negative = end of the file)
+}
+
+// Filter returns the ffmpeg filter graph joining one input per segment with
+// crossfades, and the label of its output.
+func Filter(segments []Segment, crossfade float64) (string, string) {
+	var parts []string
+	for i, seg := range segments {
+		trim := "atrim=start=" + formatSeconds(seg.Start)
+		if seg.End >= 0 {
+			trim += ":end=" + formatSeconds(seg.End)
+		}
+		parts = append(parts, fmt.Sprintf("[%d:a]%s,asetpts=PTS-STARTPTS,aformat=sample_rates=%d:channel_layouts=mono[s%d]", i, trim, spliceSampleRate, i))
+	}
+
+	out := "s0"
+	for i := 1; i < len(segments); i++ {
+		next := fmt.Sprintf("x%d", i)
+		parts = append(parts, fmt.Sprintf("[%s][s%d]acrossfade=d=%s:c1=tri:c2=tri[%s]", out, i, formatSeconds(crossfade), next))
+		out = next
+	}
+	return strings.Join(parts, ";"), out
+}
+
+// Join joins segments into outputPath with crossfades using ffmpeg. The output is
+// written to a temporary file first, so outputPath may also be a segment source.
+func Join(ctx context.Context, outputPath string, segments []Segment, crossfade float64) error {
+	if len(segments) == 0 {
+		return fmt.Errorf("no audio segments to join")
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for splicing audio but not found")
+	}
+
+	filter, out := Filter(segments, crossfade)
+	args := make([]string, 0, 2*len(segments)+7)
+	for _, seg := range segments {
+		args = append(args, "-i", seg.Path)
+	}
+	ext := filepath.Ext(outputPath)
+	tmpPath := strings.TrimSuffix(outputPath, ext) + ".splicing" + ext
+	args = append(args, "-filter_complex", filter, "-map", "["+out+"]", "-y", tmpPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ffmpeg splicing failed: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace spliced audio: %w", err)
+	}
+	return nil
+}
+
+// formatSeconds formats a duration for ffmpeg filter options
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
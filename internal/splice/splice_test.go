@@ -0,0 +1,140 @@
+package splice
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/timing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []string
+		new  []string
+		want []Op
+	}{
+		{
+			name: "unchanged",
+			old:  []string{"A.", "B."},
+			new:  []string{"A.", "B."},
+			want: []Op{{Keep: true, OldStart: 0, OldEnd: 2}},
+		},
+		{
+			name: "one sentence edited",
+			old:  []string{"A.", "B.", "C."},
+			new:  []string{"A.", "B2.", "C."},
+			want: []Op{
+				{Keep: true, OldStart: 0, OldEnd: 1},
+				{OldStart: 1, OldEnd: 2, New: []string{"B2."}},
+				{Keep: true, OldStart: 2, OldEnd: 3},
+			},
+		},
+		{
+			name: "sentence inserted at the start",
+			old:  []string{"A.", "B."},
+			new:  []string{"Z.", "A.", "B."},
+			want: []Op{
+				{OldStart: 0, OldEnd: 0, New: []string{"Z."}},
+				{Keep: true, OldStart: 0, OldEnd: 2},
+			},
+		},
+		{
+			name: "sentence deleted at the end",
+			old:  []string{"A.", "B.", "C."},
+			new:  []string{"A.", "B."},
+			want: []Op{
+				{Keep: true, OldStart: 0, OldEnd: 2},
+				{OldStart: 2, OldEnd: 3},
+			},
+		},
+		{
+			name: "everything rewritten",
+			old:  []string{"A."},
+			new:  []string{"X.", "Y."},
+			want: []Op{{OldStart: 0, OldEnd: 1, New: []string{"X.", "Y."}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedAndKept(t *testing.T) {
+	ops := Diff([]string{"A.", "B.", "C."}, []string{"A.", "X.", "Y.", "C."})
+	if got := Changed(ops); got != 2 {
+		t.Errorf("Changed() = %d, want 2", got)
+	}
+	if !Kept(ops) {
+		t.Error("Kept() = false, want true")
+	}
+	if Kept(Diff([]string{"A."}, []string{"B."})) {
+		t.Error("Kept() = true for a rewritten section")
+	}
+}
+
+func TestCut(t *testing.T) {
+	sentences := []timing.Sentence{
+		{Text: "A.", Start: 0, End: 1},
+		{Text: "B.", Start: 1.4, End: 2},
+	}
+
+	tests := []struct {
+		i    int
+		want float64
+	}{
+		{i: 0, want: 0},
+		{i: 1, want: 1.2},
+		{i: 2, want: -1},
+	}
+	for _, tt := range tests {
+		if got := Cut(sentences, tt.i); got != tt.want {
+			t.Errorf("Cut(%d) = %v, want %v", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	filter, out := Filter([]Segment{
+		{Path: "old.wav", Start: 0, End: 1.2},
+		{Path: "part.wav", Start: 0, End: -1},
+		{Path: "old.wav", Start: 2.5, End: -1},
+	}, 0.05)
+
+	want := strings.Join([]string{
+		"[0:a]atrim=start=0.000:end=1.200,asetpts=PTS-STARTPTS,aformat=sample_rates=44100:channel_layouts=mono[s0]",
+		"[1:a]atrim=start=0.000,asetpts=PTS-STARTPTS,aformat=sample_rates=44100:channel_layouts=mono[s1]",
+		"[2:a]atrim=start=2.500,asetpts=PTS-STARTPTS,aformat=sample_rates=44100:channel_layouts=mono[s2]",
+		"[s0][s1]acrossfade=d=0.050:c1=tri:c2=tri[x1]",
+		"[x1][s2]acrossfade=d=0.050:c1=tri:c2=tri[x2]",
+	}, ";")
+	if filter != want {
+		t.Errorf("Filter() =\n%s\nwant\n%s", filter, want)
+	}
+	if out != "x2" {
+		t.Errorf("Filter() output label = %q, want x2", out)
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	if err := Join(context.Background(), "out.wav", nil, DefaultCrossfade); err == nil {
+		t.Error("Expected error for no segments, got nil")
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		t.Skip("ffmpeg is installed")
+	}
+	err := Join(context.Background(), "out.wav", []Segment{{Path: "in.wav", End: -1}}, DefaultCrossfade)
+	if err == nil || !strings.Contains(err.Error(), "ffmpeg is required") {
+		t.Errorf("Expected missing ffmpeg error, got %v", err)
+	}
+}
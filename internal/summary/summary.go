@@ -0,0 +1,281 @@
+// Package summary builds compact run summaries for sharing in chat tools.
+// A summary counts processed files and sections, totals the generated audio
+// duration, and lists failures and flagged sections with links to outputs.
+//
+// Key features:
+//   - Per-section outcome collection during a run
+//   - Markdown output suitable for Slack/Discord messages
+//   - JSON output for bots and CI integrations
+//   - Short spoken report text for -spoken-summary
+//   - Links as relative paths or under a base URL
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// MaxListedOutputs caps the outputs listed in the markdown summary
+const MaxListedOutputs = 20
+
+// Item is the outcome of a single section.
+type Item struct {
+	Source   string          `json:"source"`           // Markdown file the section came from
+	Title    string          `json:"title"`            // Section title
+	Output   string          `json:"output"`           // Audio file path
+	Link     string          `json:"link,omitempty"`   // Relative path or URL of the output
+	Status   manifest.Status `json:"status"`           // Generation outcome
+	Reason   string          `json:"reason,omitempty"` // Failure, flag, or skip reason
+	Duration float64         `json:"duration_seconds"` // Audio duration in seconds (0 if failed or skipped)
+}
+
+// Summary collects the outcome of a run.
+type Summary struct {
+	Provider     string        `json:"provider"`
+	StartedAt    time.Time     `json:"started_at"`
+	Elapsed      time.Duration `json:"-"`
+	Files        int           `json:"files"`
+	Sections     int           `json:"sections"`
+	Generated    int           `json:"generated"`
+	Failed       int           `json:"failed"`
+	Flagged      int           `json:"flagged"`
+	Skipped      int           `json:"skipped"`
+	AudioSeconds float64       `json:"audio_seconds"`
+	Items        []Item        `json:"items"`
+}
+
+// New creates a summary for a run starting now.
+func New(provider string) *Summary {
+	return &Summary{Provider: provider, StartedAt: time.Now().UTC()}
+}
+
+// AddFile records a processed markdown file.
+func (s *Summary) AddFile() {
+	s.Files++
+}
+
+// Add records the outcome of a section.
+func (s *Summary) Add(entry manifest.Entry, duration float64) {
+	s.Sections++
+	switch entry.Status {
+	case manifest.StatusFailed:
+		s.Failed++
+		duration = 0
+	case manifest.StatusFlagged:
+		s.Generated++
+		s.Flagged++
+	case manifest.StatusSkipped:
+		s.Skipped++
+		duration = 0
+	default:
+		s.Generated++
+	}
+	s.AudioSeconds += duration
+
+	s.Items = append(s.Items, Item{
+		Source:   entry.Source,
+		Title:    entry.Title,
+		Output:   entry.Output,
+		Status:   entry.Status,
+		Reason:   entry.Reason,
+		Duration: duration,
+	})
+}
+
+// Finish records the elapsed run time.
+func (s *Summary) Finish() {
+	s.Elapsed = time.Since(s.StartedAt)
+}
+
+// SpokenText returns a short report of the run meant to be read aloud, e.g.
+// "Generated 42 sections totaling 35 minutes. 2 failures, in intro.md."
+func (s *Summary) SpokenText() string {
+	var b strings.Builder
+	if s.Generated == s.Sections {
+		fmt.Fprintf(&b, "Generated %s", plural(s.Generated, "section"))
+	} else {
+		fmt.Fprintf(&b, "Generated %d of %s", s.Generated, plural(s.Sections, "section"))
+	}
+	if s.Files > 1 {
+		fmt.Fprintf(&b, " from %s", plural(s.Files, "file"))
+	}
+	fmt.Fprintf(&b, " totaling %s.", spokenDuration(s.AudioSeconds))
+
+	if s.Failed > 0 {
+		fmt.Fprintf(&b, " %s, in %s.", plural(s.Failed, "failure"), joinSpoken(s.sources(manifest.StatusFailed)))
+	} else {
+		b.WriteString(" No failures.")
+	}
+	if s.Flagged > 0 {
+		fmt.Fprintf(&b, " %s flagged for review.", plural(s.Flagged, "section"))
+	}
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, " %s skipped.", plural(s.Skipped, "section"))
+	}
+	return b.String()
+}
+
+// sources returns the base names of the files with sections of status, in order of appearance
+func (s *Summary) sources(status manifest.Status) []string {
+	var names []string
+	for _, item := range s.Items {
+		name := filepath.Base(item.Source)
+		if item.Status == status && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// plural formats a count with its noun, e.g. "1 section" or "3 sections"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// spokenDuration formats an audio duration in whole minutes, or seconds below a minute
+func spokenDuration(seconds float64) string {
+	if seconds < 60 {
+		return plural(int(math.Round(seconds)), "second")
+	}
+	return plural(int(math.Round(seconds/60)), "minute")
+}
+
+// joinSpoken joins names as a spoken list, e.g. "a, b and c"
+func joinSpoken(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// Options controls how output links are written.
+type Options struct {
+	BaseDir string // Directory output links are relative to
+	BaseURL string // URL prefix for output links (relative paths if empty)
+}
+
+// Link returns the link to an output file.
+func (o Options) Link(output string) string {
+	link := filepath.ToSlash(output)
+	if o.BaseDir != "" {
+		if rel, err := filepath.Rel(o.BaseDir, output); err == nil {
+			link = filepath.ToSlash(rel)
+		}
+	}
+	if o.BaseURL == "" {
+		return link
+	}
+
+	u, err := url.Parse(o.BaseURL)
+	if err != nil {
+		return strings.TrimSuffix(o.BaseURL, "/") + "/" + link
+	}
+	u.Path = path.Join(u.Path, link)
+	return u.String()
+}
+
+// WriteFile writes the summary to path, as JSON for .json files and markdown otherwise.
+func (s *Summary) WriteFile(path string, opts Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create summary: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = s.WriteJSON(file, opts)
+	} else {
+		err = s.WriteMarkdown(file, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// WriteJSON writes the summary as indented JSON.
+func (s *Summary) WriteJSON(w io.Writer, opts Options) error {
+	out := *s
+	out.Items = s.linkedItems(opts)
+
+	data, err := json.MarshalIndent(struct {
+		Summary
+		ElapsedSeconds float64 `json:"elapsed_seconds"`
+	}{out, s.Elapsed.Seconds()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %w", err)
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteMarkdown writes the summary as chat-friendly markdown.
+func (s *Summary) WriteMarkdown(w io.Writer, opts Options) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "**md2audio run summary** (%s)\n\n", s.StartedAt.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&b, "- Files: %d, sections: %d, generated: %d, failed: %d, flagged: %d", s.Files, s.Sections, s.Generated, s.Failed, s.Flagged)
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, ", skipped: %d", s.Skipped)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "- Audio: %.1f min, took %s, provider: %s\n", s.AudioSeconds/60, s.Elapsed.Round(time.Second), s.Provider)
+
+	items := s.linkedItems(opts)
+
+	writeList := func(heading string, status manifest.Status) {
+		var lines []string
+		for _, item := range items {
+			if item.Status == status {
+				lines = append(lines, fmt.Sprintf("- %s: %s (%s) [%s](%s)", filepath.Base(item.Source), item.Title, item.Reason, filepath.Base(item.Output), item.Link))
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n**%s**\n%s\n", heading, strings.Join(lines, "\n"))
+		}
+	}
+	writeList("Failures", manifest.StatusFailed)
+	writeList("Flagged", manifest.StatusFlagged)
+
+	var outputs []string
+	for _, item := range items {
+		if item.Status != manifest.StatusFailed && item.Status != manifest.StatusSkipped {
+			outputs = append(outputs, fmt.Sprintf("- [%s](%s)", filepath.Base(item.Output), item.Link))
+		}
+	}
+	if len(outputs) > 0 {
+		b.WriteString("\n**Outputs**\n")
+		if len(outputs) > MaxListedOutputs {
+			more := len(outputs) - MaxListedOutputs
+			outputs = append(outputs[:MaxListedOutputs], fmt.Sprintf("- ...and %d more", more))
+		}
+		b.WriteString(strings.Join(outputs, "\n") + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// linkedItems returns the items with their links resolved
+func (s *Summary) linkedItems(opts Options) []Item {
+	items := make([]Item, len(s.Items))
+	for i, item := range s.Items {
+		item.Link = opts.Link(item.Output)
+		items[i] = item
+	}
+	return items
+}
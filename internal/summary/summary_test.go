@@ -0,0 +1,224 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// newTestSummary returns a summary with one entry per status
+func newTestSummary() *Summary {
+	s := New("elevenlabs")
+	s.AddFile()
+	s.Add(manifest.Entry{Source: "/docs/guide.md", Title: "Intro", Output: "/out/guide/section_01_intro.mp3", Status: manifest.StatusOK}, 60)
+	s.Add(manifest.Entry{Source: "/docs/guide.md", Title: "Setup", Output: "/out/guide/section_02_setup.mp3", Status: manifest.StatusFailed, Reason: "rate limited"}, 45)
+	s.Add(manifest.Entry{Source: "/docs/guide.md", Title: "Usage", Output: "/out/guide/section_03_usage.mp3", Status: manifest.StatusFlagged, Reason: "word error rate 40% exceeds 25%"}, 30)
+	s.Finish()
+	return s
+}
+
+func TestSummaryAdd(t *testing.T) {
+	s := newTestSummary()
+
+	if s.Files != 1 || s.Sections != 3 || s.Generated != 2 || s.Failed != 1 || s.Flagged != 1 {
+		t.Errorf("Unexpected counts: %+v", s)
+	}
+	// Failed sections do not count towards the audio total
+	if s.AudioSeconds != 90 {
+		t.Errorf("AudioSeconds = %.1f, want 90", s.AudioSeconds)
+	}
+}
+
+func TestSummaryAddSkipped(t *testing.T) {
+	s := newTestSummary()
+	s.Add(manifest.Entry{Source: "/docs/guide.md", Title: "Outro", Output: "/out/guide/section_04_outro.mp3", Status: manifest.StatusSkipped, Reason: "time budget of 30m0s reached"}, 20)
+
+	if s.Sections != 4 || s.Generated != 2 || s.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", s)
+	}
+	// Skipped sections do not count towards the audio total
+	if s.AudioSeconds != 90 {
+		t.Errorf("AudioSeconds = %.1f, want 90", s.AudioSeconds)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteMarkdown(&buf, Options{}); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, ", skipped: 1\n") {
+		t.Errorf("Expected skipped count in summary:\n%s", out)
+	}
+	if strings.Contains(out, "section_04_outro.mp3") {
+		t.Errorf("Skipped section should not be listed as output:\n%s", out)
+	}
+}
+
+func TestSpokenText(t *testing.T) {
+	s := newTestSummary()
+	want := "Generated 2 of 3 sections totaling 2 minutes. 1 failure, in guide.md. 1 section flagged for review."
+	if got := s.SpokenText(); got != want {
+		t.Errorf("SpokenText() = %q, want %q", got, want)
+	}
+
+	s = New("say")
+	s.AddFile()
+	s.AddFile()
+	s.Add(manifest.Entry{Source: "/docs/a.md", Status: manifest.StatusOK}, 20)
+	s.Add(manifest.Entry{Source: "/docs/b.md", Status: manifest.StatusOK}, 1.4)
+	want = "Generated 2 sections from 2 files totaling 21 seconds. No failures."
+	if got := s.SpokenText(); got != want {
+		t.Errorf("SpokenText() = %q, want %q", got, want)
+	}
+}
+
+func TestJoinSpoken(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a.md"}, "a.md"},
+		{[]string{"a.md", "b.md"}, "a.md and b.md"},
+		{[]string{"a.md", "b.md", "c.md"}, "a.md, b.md and c.md"},
+	}
+	for _, tt := range tests {
+		if got := joinSpoken(tt.names); got != tt.want {
+			t.Errorf("joinSpoken(%v) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestOptionsLink(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		output string
+		want   string
+	}{
+		{
+			name:   "relative to base directory",
+			opts:   Options{BaseDir: "/out"},
+			output: "/out/guide/a.mp3",
+			want:   "guide/a.mp3",
+		},
+		{
+			name:   "base URL",
+			opts:   Options{BaseDir: "/out", BaseURL: "http://localhost:8080/"},
+			output: "/out/guide/a.mp3",
+			want:   "http://localhost:8080/guide/a.mp3",
+		},
+		{
+			name:   "base URL with path",
+			opts:   Options{BaseDir: "/out", BaseURL: "https://example.com/review"},
+			output: "/out/guide/a b.mp3",
+			want:   "https://example.com/review/guide/a%20b.mp3",
+		},
+		{
+			name:   "no base directory",
+			opts:   Options{},
+			output: "out/a.mp3",
+			want:   "out/a.mp3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Link(tt.output); got != tt.want {
+				t.Errorf("Link(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	s := newTestSummary()
+
+	var buf bytes.Buffer
+	if err := s.WriteMarkdown(&buf, Options{BaseDir: "/out"}); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"**md2audio run summary**",
+		"Files: 1, sections: 3, generated: 2, failed: 1, flagged: 1",
+		"Audio: 1.5 min",
+		"provider: elevenlabs",
+		"**Failures**\n- guide.md: Setup (rate limited) [section_02_setup.mp3](guide/section_02_setup.mp3)",
+		"**Flagged**\n- guide.md: Usage (word error rate 40% exceeds 25%)",
+		"- [section_01_intro.mp3](guide/section_01_intro.mp3)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, out)
+		}
+	}
+
+	// Failed sections are not listed as outputs
+	if strings.Contains(out, "- [section_02_setup.mp3]") {
+		t.Errorf("Failed section should not be listed as output:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownCapsOutputs(t *testing.T) {
+	s := New("say")
+	for i := range MaxListedOutputs + 5 {
+		s.Add(manifest.Entry{Title: "S", Output: fmt.Sprintf("/out/section_%02d.aiff", i), Status: manifest.StatusOK}, 1)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteMarkdown(&buf, Options{}); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "...and 5 more") {
+		t.Errorf("Expected capped output list:\n%s", buf.String())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSummary()
+
+	jsonPath := filepath.Join(dir, "summary.json")
+	if err := s.WriteFile(jsonPath, Options{BaseDir: "/out", BaseURL: "http://localhost:8080"}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+
+	var decoded struct {
+		Provider       string  `json:"provider"`
+		Failed         int     `json:"failed"`
+		ElapsedSeconds float64 `json:"elapsed_seconds"`
+		Items          []Item  `json:"items"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON summary: %v\n%s", err, data)
+	}
+	if decoded.Provider != "elevenlabs" || decoded.Failed != 1 || len(decoded.Items) != 3 {
+		t.Errorf("Unexpected JSON summary: %+v", decoded)
+	}
+	if decoded.Items[0].Link != "http://localhost:8080/guide/section_01_intro.mp3" {
+		t.Errorf("Unexpected link: %s", decoded.Items[0].Link)
+	}
+
+	mdPath := filepath.Join(dir, "summary.md")
+	if err := s.WriteFile(mdPath, Options{}); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err = os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "**md2audio run summary**") {
+		t.Errorf("Expected markdown summary, got:\n%s", data)
+	}
+}
@@ -0,0 +1,142 @@
+package text
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// Slug styles for SlugOptions.Style
+const (
+	SlugASCII   = "ascii"   // Lowercase ASCII with Latin letters transliterated (default)
+	SlugUnicode = "unicode" // Lowercase letters and digits of any script
+	SlugHash    = "hash"    // Short hash of the title, for titles that make poor filenames
+)
+
+// SlugStyles lists the supported slug styles
+var SlugStyles = []string{SlugASCII, SlugUnicode, SlugHash}
+
+// DefaultSlugMaxLen is the default maximum slug length in characters
+const DefaultSlugMaxLen = 50
+
+// slugHashLen is the number of hex digits of a hash slug
+const slugHashLen = 10
+
+// SlugOptions controls how Slug turns titles into filenames.
+// The zero value produces transliterated ASCII slugs of at most DefaultSlugMaxLen characters.
+type SlugOptions struct {
+	Style  string // SlugASCII, SlugUnicode, or SlugHash (default: SlugASCII)
+	MaxLen int    // Maximum length in characters (default: DefaultSlugMaxLen)
+}
+
+// transliterations maps Latin letters without an ASCII decomposition to ASCII
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "TH", 'ł': "l", 'Ł': "L",
+	'ı': "i", 'ħ': "h", 'Ħ': "H",
+}
+
+// accentedLetters lists Latin letters with diacritics and their base letter
+var accentedLetters = []struct {
+	base    rune
+	letters string
+}{
+	{'a', "àáâãäåāăą"}, {'A', "ÀÁÂÃÄÅĀĂĄ"},
+	{'c', "çćĉċč"}, {'C', "ÇĆĈĊČ"},
+	{'d', "ď"}, {'D', "Ď"},
+	{'e', "èéêëēĕėęě"}, {'E', "ÈÉÊËĒĔĖĘĚ"},
+	{'g', "ĝğġģ"}, {'G', "ĜĞĠĢ"},
+	{'h', "ĥ"}, {'H', "Ĥ"},
+	{'i', "ìíîïĩīĭįİ"}, {'I', "ÌÍÎÏĨĪĬĮ"},
+	{'j', "ĵ"}, {'J', "Ĵ"},
+	{'k', "ķ"}, {'K', "Ķ"},
+	{'l', "ĺļľŀ"}, {'L', "ĹĻĽĿ"},
+	{'n', "ñńņňŉ"}, {'N', "ÑŃŅŇ"},
+	{'o', "òóôõöōŏő"}, {'O', "ÒÓÔÕÖŌŎŐ"},
+	{'r', "ŕŗř"}, {'R', "ŔŖŘ"},
+	{'s', "śŝşšș"}, {'S', "ŚŜŞŠȘ"},
+	{'t', "ţťŧț"}, {'T', "ŢŤŦȚ"},
+	{'u', "ùúûüũūŭůűų"}, {'U', "ÙÚÛÜŨŪŬŮŰŲ"},
+	{'w', "ŵ"}, {'W', "Ŵ"},
+	{'y', "ýÿŷ"}, {'Y', "ÝŸŶ"},
+	{'z', "źżž"}, {'Z', "ŹŻŽ"},
+}
+
+func init() {
+	for _, entry := range accentedLetters {
+		for _, r := range entry.letters {
+			transliterations[r] = string(entry.base)
+		}
+	}
+}
+
+// Slug converts a title into a safe filename using opts. ASCII slugs of
+// titles without Latin letters or digits fall back to a hash, so every
+// non-empty title gets a non-empty slug.
+func Slug(title string, opts SlugOptions) string {
+	maxLen := opts.MaxLen
+	if maxLen <= 0 {
+		maxLen = DefaultSlugMaxLen
+	}
+
+	var slug string
+	switch opts.Style {
+	case SlugHash:
+		slug = hashSlug(title)
+	case SlugUnicode:
+		slug = unicodeSlug(title)
+	default:
+		slug = asciiSlug(Transliterate(title))
+		if strings.Trim(slug, "_-") == "" && strings.TrimSpace(title) != "" {
+			slug = hashSlug(title)
+		}
+	}
+
+	return truncateRunes(slug, maxLen)
+}
+
+// Transliterate replaces accented and special Latin letters with their
+// closest ASCII spelling (e.g., "Größe" becomes "Grosse"); other characters
+// are kept.
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if ascii, ok := transliterations[r]; ok {
+			b.WriteString(ascii)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unicodeSlug keeps letters, marks, and digits of any script, lowercased,
+// with whitespace replaced by underscores
+func unicodeSlug(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(whitespacePattern.ReplaceAllString(strings.TrimSpace(title), "_")) {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// hashSlug returns a short hash of the title
+func hashSlug(title string) string {
+	if title == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(title))
+	return hex.EncodeToString(sum[:])[:slugHashLen]
+}
+
+// truncateRunes returns at most n characters of s
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,52 @@
+package text
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		opts  SlugOptions
+		want  string
+	}{
+		{name: "ascii", title: "Scene 1: Introduction (8s)", want: "scene_1_introduction_8s"},
+		{name: "ascii transliterates accents", title: "Café Größe à la carte", want: "cafe_grosse_a_la_carte"},
+		{name: "ascii transliterates special letters", title: "Łódź Ærø", want: "lodz_aero"},
+		{name: "ascii falls back to hash", title: "日本語のタイトル", want: hashSlug("日本語のタイトル")},
+		{name: "ascii keeps partial slug", title: "Intro 🎉", want: "intro_"},
+		{name: "unicode keeps scripts", title: "Введение: Обзор", opts: SlugOptions{Style: SlugUnicode}, want: "введение_обзор"},
+		{name: "unicode keeps combining marks", title: "हिन्दी पाठ", opts: SlugOptions{Style: SlugUnicode}, want: "हिन्दी_पाठ"},
+		{name: "hash", title: "Introduction", opts: SlugOptions{Style: SlugHash}, want: hashSlug("Introduction")},
+		{name: "max length", title: "A very long title", opts: SlugOptions{MaxLen: 6}, want: "a_very"},
+		{name: "unicode max length counts characters", title: "Введение", opts: SlugOptions{Style: SlugUnicode, MaxLen: 3}, want: "вве"},
+		{name: "empty", title: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slug(tt.title, tt.opts); got != tt.want {
+				t.Errorf("Slug(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugDefaultMaxLen(t *testing.T) {
+	got := Slug(strings.Repeat("é", 100), SlugOptions{})
+	if utf8.RuneCountInString(got) != DefaultSlugMaxLen {
+		t.Errorf("Slug() length = %d, want %d", utf8.RuneCountInString(got), DefaultSlugMaxLen)
+	}
+}
+
+func TestHashSlugStable(t *testing.T) {
+	if hashSlug("Intro") != hashSlug("Intro") || hashSlug("Intro") == hashSlug("Outro") {
+		t.Error("hashSlug() should be stable and distinguish titles")
+	}
+	if len(hashSlug("Intro")) != slugHashLen {
+		t.Errorf("hashSlug() length = %d, want %d", len(hashSlug("Intro")), slugHashLen)
+	}
+}
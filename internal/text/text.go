@@ -3,13 +3,22 @@
 //
 // Key features:
 //   - Markdown formatting removal for TTS compatibility
-//   - Safe filename generation from section titles
+//   - Safe filename generation from section titles (ASCII, Unicode, or hash slugs)
+//   - Sentence splitting
+//   - Audio tag detection ([whispers], [laughs])
+//   - Pause directives ([pause 1.5s])
+//   - SSML passthrough blocks (```ssml fenced code)
 //   - Pre-compiled regex patterns for performance
 package text
 
 import (
+	"html"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Pre-compiled regular expressions for performance
@@ -17,20 +26,62 @@ import (
 var (
 	// Markdown cleaning patterns
 	newlinePattern      = regexp.MustCompile(`\n+`)
+	paragraphPattern    = regexp.MustCompile(`\n\s*\n\s*`)
 	whitespacePattern   = regexp.MustCompile(`\s+`)
+	spacePattern        = regexp.MustCompile(`[ \t\r\n]+`)
 	markdownLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\([^\)]+\)`)
+	imagePattern        = regexp.MustCompile(`!\[[^\]]*\]\([^\)]*\)`)
+	fencedCodePattern   = regexp.MustCompile("(?ms)^[ \t]*```.*?^[ \t]*```[^\n]*$|^[ \t]*~~~.*?^[ \t]*~~~[^\n]*$")
 	boldItalicPattern   = regexp.MustCompile(`[*_]{1,2}([^*_]+)[*_]{1,2}`)
-	codeBlockPattern    = regexp.MustCompile("`[^`]+`")
+	codeBlockPattern    = regexp.MustCompile("`([^`]+)`")
+	audioTagPattern     = regexp.MustCompile(`\[([A-Za-z][A-Za-z' -]*[A-Za-z])\]`)
+	pausePattern        = regexp.MustCompile(`(?i)\[pause[ \t]+(\d+(?:\.\d+)?)[ \t]*(ms|s)\]`)
+	blankPattern        = regexp.MustCompile(`[ \t]{2,}`)
+	ssmlFencePattern    = regexp.MustCompile("(?ms)^[ \t]*(?:```|~~~)[ \t]*ssml[ \t]*\n(.*?)^[ \t]*(?:```|~~~)[ \t]*$")
+	ssmlBlockPattern    = regexp.MustCompile(`(?s)<speak\b[^>]*>(.*?)</speak>`)
+	ssmlTagPattern      = regexp.MustCompile(`<[^>]*>`)
+	punctSpacePattern   = regexp.MustCompile(`[ \t]+([.,;:!?])`)
+	ssmlMarkerPattern   = regexp.MustCompile("\x00(\\d+)\x00")
 
 	// Filename sanitization patterns
 	invalidCharsPattern = regexp.MustCompile(`[^\w\s-]`)
 )
 
-// CleanMarkdown removes markdown formatting from text for speech synthesis
+// CleanOptions controls how CleanMarkdown prepares text for speech synthesis.
+// The zero value matches CleanMarkdown.
+type CleanOptions struct {
+	KeepInlineCode bool // Speak the content of inline code spans instead of removing them
+	KeepParagraphs bool // Keep paragraph breaks as "\n\n" so providers pause between paragraphs
+}
+
+// CleanMarkdown removes markdown formatting from text for speech synthesis.
+// Audio tags such as [whispers] are kept for the providers that perform them,
+// and SSML blocks (```ssml fenced code) as <speak> elements for the providers
+// speaking SSML.
 func CleanMarkdown(text string) string {
+	return CleanMarkdownWith(text, CleanOptions{})
+}
+
+// CleanMarkdownWith removes markdown formatting from text using opts
+func CleanMarkdownWith(text string, opts CleanOptions) string {
+	// Keep SSML blocks as written, out of reach of the markdown cleanup
+	text, blocks := protectSSML(text)
+
+	// Remove fenced code blocks and images, which cannot be read aloud
+	text = fencedCodePattern.ReplaceAllString(text, "")
+	text = imagePattern.ReplaceAllString(text, "")
+
 	// Remove extra whitespace and newlines
-	text = newlinePattern.ReplaceAllString(text, " ")
-	text = whitespacePattern.ReplaceAllString(text, " ")
+	if opts.KeepParagraphs {
+		paragraphs := paragraphPattern.Split(strings.TrimSpace(text), -1)
+		for i, p := range paragraphs {
+			paragraphs[i] = spacePattern.ReplaceAllString(p, " ")
+		}
+		text = strings.Join(paragraphs, "\n\n")
+	} else {
+		text = newlinePattern.ReplaceAllString(text, " ")
+		text = whitespacePattern.ReplaceAllString(text, " ")
+	}
 
 	// Remove markdown links [text](url) -> text
 	text = markdownLinkPattern.ReplaceAllString(text, "$1")
@@ -38,26 +89,219 @@ func CleanMarkdown(text string) string {
 	// Remove bold/italic markers
 	text = boldItalicPattern.ReplaceAllString(text, "$1")
 
-	// Remove code blocks
-	text = codeBlockPattern.ReplaceAllString(text, "")
+	// Remove code blocks, or keep their content
+	if opts.KeepInlineCode {
+		text = codeBlockPattern.ReplaceAllString(text, "$1")
+	} else {
+		text = codeBlockPattern.ReplaceAllString(text, "")
+	}
 
+	text = ssmlMarkerPattern.ReplaceAllStringFunc(text, func(marker string) string {
+		i, _ := strconv.Atoi(strings.Trim(marker, "\x00"))
+		return blocks[i]
+	})
 	return strings.TrimSpace(text)
 }
 
+// protectSSML replaces the SSML blocks of text, fenced or already cleaned into
+// <speak> elements, with numbered markers, returning the blocks as <speak>
+// elements on a single line
+func protectSSML(text string) (string, []string) {
+	var blocks []string
+	protect := func(block string) string {
+		block = whitespacePattern.ReplaceAllString(strings.TrimSpace(block), " ")
+		if !ssmlBlockPattern.MatchString(block) {
+			block = "<speak>" + block + "</speak>"
+		}
+		blocks = append(blocks, block)
+		return "\x00" + strconv.Itoa(len(blocks)-1) + "\x00"
+	}
+
+	text = ssmlFencePattern.ReplaceAllStringFunc(text, func(fence string) string {
+		return protect(ssmlFencePattern.FindStringSubmatch(fence)[1])
+	})
+	text = ssmlBlockPattern.ReplaceAllStringFunc(text, protect)
+	return text, blocks
+}
+
+// SSMLSegment is a span of plain text, or the markup of an SSML block, as
+// split by SplitSSML
+type SSMLSegment struct {
+	Text string
+	SSML bool
+}
+
+// SplitSSML splits text cleaned by CleanMarkdown into plain text and the
+// markup of its SSML blocks (the content of their <speak> elements).
+func SplitSSML(text string) []SSMLSegment {
+	var segments []SSMLSegment
+	start := 0
+	for _, match := range ssmlBlockPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > start {
+			segments = append(segments, SSMLSegment{Text: text[start:match[0]]})
+		}
+		segments = append(segments, SSMLSegment{Text: text[match[2]:match[3]], SSML: true})
+		start = match[1]
+	}
+	if start < len(text) {
+		segments = append(segments, SSMLSegment{Text: text[start:]})
+	}
+	return segments
+}
+
+// UnwrapSSML replaces the SSML blocks of text with their markup, for text
+// that is SSML as a whole (-ssml)
+func UnwrapSSML(text string) string {
+	return ssmlBlockPattern.ReplaceAllString(text, "$1")
+}
+
+// StripSSML replaces the SSML blocks of text with the text they speak, for
+// providers without SSML support
+func StripSSML(text string) string {
+	if !ssmlBlockPattern.MatchString(text) {
+		return text
+	}
+	text = ssmlBlockPattern.ReplaceAllStringFunc(text, func(block string) string {
+		return html.UnescapeString(ssmlTagPattern.ReplaceAllString(block, " "))
+	})
+	text = blankPattern.ReplaceAllString(text, " ")
+	return strings.TrimSpace(punctSpacePattern.ReplaceAllString(text, "$1"))
+}
+
+// SpokenText returns the words of text that are spoken, without pause
+// directives and SSML tags, for word counts, subtitles, and transcripts
+func SpokenText(text string) string {
+	return StripSSML(StripPauses(text))
+}
+
+// AudioTags returns the expressive audio tags in text, such as [whispers] or
+// [laughs harder], in order of appearance. Link texts ("[docs](url)") are
+// not tags.
+func AudioTags(text string) []string {
+	var tags []string
+	for _, match := range audioTagPattern.FindAllStringIndex(text, -1) {
+		if match[1] < len(text) && text[match[1]] == '(' {
+			continue
+		}
+		tags = append(tags, text[match[0]:match[1]])
+	}
+	return tags
+}
+
+// Segment is a span of text followed by a pause, as split by SplitPauses
+type Segment struct {
+	Text  string
+	Pause time.Duration
+}
+
+// SplitPauses splits text at its pause directives ("[pause 1.5s]",
+// "[pause 500ms]"). Each segment holds the text before a directive and the
+// directive's pause; the last segment holds the text after the last directive.
+func SplitPauses(text string) []Segment {
+	var segments []Segment
+	start := 0
+	for _, match := range pausePattern.FindAllStringSubmatchIndex(text, -1) {
+		segments = append(segments, Segment{
+			Text:  strings.TrimSpace(text[start:match[0]]),
+			Pause: pauseDuration(text[match[2]:match[3]], text[match[4]:match[5]]),
+		})
+		start = match[1]
+	}
+	return append(segments, Segment{Text: strings.TrimSpace(text[start:])})
+}
+
+// ReplacePauses replaces the pause directives in text with the markup
+// render returns for their pause, such as an SSML break
+func ReplacePauses(text string, render func(time.Duration) string) string {
+	return pausePattern.ReplaceAllStringFunc(text, func(directive string) string {
+		m := pausePattern.FindStringSubmatch(directive)
+		return render(pauseDuration(m[1], m[2]))
+	})
+}
+
+// StripPauses removes the pause directives from text, leaving the text that is spoken
+func StripPauses(text string) string {
+	if !pausePattern.MatchString(text) {
+		return text
+	}
+	text = pausePattern.ReplaceAllString(text, "")
+	return strings.TrimSpace(blankPattern.ReplaceAllString(text, " "))
+}
+
+// PauseDuration returns the total pause of the pause directives in text
+func PauseDuration(text string) time.Duration {
+	var total time.Duration
+	for _, m := range pausePattern.FindAllStringSubmatch(text, -1) {
+		total += pauseDuration(m[1], m[2])
+	}
+	return total
+}
+
+// pauseDuration converts the amount and unit of a pause directive
+func pauseDuration(amount, unit string) time.Duration {
+	value, _ := strconv.ParseFloat(amount, 64)
+	if strings.EqualFold(unit, "ms") {
+		return time.Duration(value * float64(time.Millisecond))
+	}
+	return time.Duration(value * float64(time.Second))
+}
+
 // SanitizeFilename converts a title into a safe filename
 func SanitizeFilename(title string) string {
+	filename := asciiSlug(title)
+
+	// Limit length
+	if len(filename) > 50 {
+		filename = filename[:50]
+	}
+
+	return filename
+}
+
+// asciiSlug lowercases a title, drops characters other than ASCII letters,
+// digits, underscores, and hyphens, and replaces whitespace with underscores
+func asciiSlug(title string) string {
 	// Remove or replace invalid characters
 	filename := invalidCharsPattern.ReplaceAllString(title, "")
 
 	// Replace spaces with underscores
 	filename = whitespacePattern.ReplaceAllString(filename, "_")
 
-	filename = strings.ToLower(filename)
+	return strings.ToLower(filename)
+}
 
-	// Limit length
-	if len(filename) > 50 {
-		filename = filename[:50]
+// SplitSentences splits text into sentences at '.', '!', or '?' followed by a word
+// starting with an uppercase letter, digit, or opening quote, so abbreviations such
+// as "e.g." in the middle of a sentence do not split it. SSML blocks are not split.
+// Whitespace within each sentence is collapsed to single spaces.
+func SplitSentences(text string) []string {
+	tokens := strings.Fields(text)
+	var sentences []string
+	start := 0
+	inSSML := false
+	for i, token := range tokens {
+		if strings.Contains(token, "<speak") {
+			inSSML = true
+		}
+		if strings.Contains(token, "</speak>") {
+			inSSML = false
+		}
+		if i == len(tokens)-1 || (!inSSML && endsSentence(token) && startsSentence(tokens[i+1])) {
+			sentences = append(sentences, strings.Join(tokens[start:i+1], " "))
+			start = i + 1
+		}
 	}
+	return sentences
+}
 
-	return filename
+// endsSentence reports whether a token ends with sentence punctuation, ignoring closing quotes and brackets
+func endsSentence(token string) bool {
+	trimmed := strings.TrimRight(token, `"')]}»”’`)
+	return strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, "!") || strings.HasSuffix(trimmed, "?")
+}
+
+// startsSentence reports whether a token can start a sentence or an SSML block
+func startsSentence(token string) bool {
+	r, _ := utf8.DecodeRuneInString(token)
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune(`"'(«“‘`, r) || strings.HasPrefix(token, "<speak")
 }
@@ -1,6 +1,11 @@
 package text
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestCleanMarkdown(t *testing.T) {
 	tests := []struct {
@@ -23,6 +28,21 @@ func TestCleanMarkdown(t *testing.T) {
 			input:    "Check out [this link](https://example.com)",
 			expected: "Check out this link",
 		},
+		{
+			name:     "keeps audio tags",
+			input:    "[whispers] Don't tell *anyone*. [laughs] See [the docs](https://example.com).",
+			expected: "[whispers] Don't tell anyone. [laughs] See the docs.",
+		},
+		{
+			name:     "removes images",
+			input:    "See the diagram ![Architecture](arch.png) below",
+			expected: "See the diagram below",
+		},
+		{
+			name:     "removes fenced code blocks",
+			input:    "Install it:\n\n```bash\nnpm install\n```\n\n~~~\nmore code\n~~~\nDone.",
+			expected: "Install it: Done.",
+		},
 		{
 			name:     "removes bold markers",
 			input:    "This is **bold text** here",
@@ -75,6 +95,122 @@ func TestCleanMarkdown(t *testing.T) {
 	}
 }
 
+func TestAudioTags(t *testing.T) {
+	got := AudioTags("[whispers] Quiet. [laughs harder] See [docs](url), [x] done, [pause 2s] and [sighs]")
+	want := []string{"[whispers]", "[laughs harder]", "[sighs]"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("AudioTags() = %v, want %v", got, want)
+	}
+	if tags := AudioTags("No tags here"); tags != nil {
+		t.Errorf("AudioTags() = %v, want none", tags)
+	}
+}
+
+func TestSplitPauses(t *testing.T) {
+	got := SplitPauses("[pause 1s] Welcome. [PAUSE 1.5s] Let's begin [pause 250 ms]")
+	want := []Segment{
+		{Text: "", Pause: time.Second},
+		{Text: "Welcome.", Pause: 1500 * time.Millisecond},
+		{Text: "Let's begin", Pause: 250 * time.Millisecond},
+		{Text: ""},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitPauses() = %+v, want %+v", got, want)
+	}
+
+	if got := SplitPauses("No pauses [pause] here"); !slices.Equal(got, []Segment{{Text: "No pauses [pause] here"}}) {
+		t.Errorf("SplitPauses() = %+v, want a single segment", got)
+	}
+}
+
+func TestReplacePauses(t *testing.T) {
+	content := "One. [pause 2s] Two. [pause 500ms] Three."
+
+	got := ReplacePauses(content, func(pause time.Duration) string { return "<" + pause.String() + ">" })
+	if want := "One. <2s> Two. <500ms> Three."; got != want {
+		t.Errorf("ReplacePauses() = %q, want %q", got, want)
+	}
+	if got, want := StripPauses(content), "One. Two. Three."; got != want {
+		t.Errorf("StripPauses() = %q, want %q", got, want)
+	}
+	if got, want := PauseDuration(content), 2500*time.Millisecond; got != want {
+		t.Errorf("PauseDuration() = %v, want %v", got, want)
+	}
+}
+
+func TestCleanMarkdownSSML(t *testing.T) {
+	input := "Intro **text**.\n\n```ssml\n<prosody rate=\"slow\">\n  Take _your_ time.\n</prosody>\n```\n\n" +
+		"```go\nfmt.Println()\n```\n\n~~~ssml\n<speak xml:lang=\"en-US\"><emphasis>Done</emphasis>.</speak>\n~~~"
+	want := `Intro text. <speak><prosody rate="slow"> Take _your_ time. </prosody></speak> ` +
+		`<speak xml:lang="en-US"><emphasis>Done</emphasis>.</speak>`
+
+	got := CleanMarkdown(input)
+	if got != want {
+		t.Errorf("CleanMarkdown() = %q, want %q", got, want)
+	}
+	if again := CleanMarkdown(got); again != got {
+		t.Errorf("CleanMarkdown() of cleaned text = %q, want it unchanged", again)
+	}
+}
+
+func TestSplitSSML(t *testing.T) {
+	content := `Say <speak><say-as interpret-as="characters">SSML</say-as> &amp; more</speak> [pause 1s] again.`
+
+	got := SplitSSML(content)
+	want := []SSMLSegment{
+		{Text: "Say "},
+		{Text: `<say-as interpret-as="characters">SSML</say-as> &amp; more`, SSML: true},
+		{Text: " [pause 1s] again."},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitSSML() = %+v, want %+v", got, want)
+	}
+	if got, want := UnwrapSSML(content), `Say <say-as interpret-as="characters">SSML</say-as> &amp; more [pause 1s] again.`; got != want {
+		t.Errorf("UnwrapSSML() = %q, want %q", got, want)
+	}
+	if got, want := StripSSML(content), "Say SSML & more [pause 1s] again."; got != want {
+		t.Errorf("StripSSML() = %q, want %q", got, want)
+	}
+	if got, want := SpokenText(content), "Say SSML & more again."; got != want {
+		t.Errorf("SpokenText() = %q, want %q", got, want)
+	}
+}
+
+func TestCleanMarkdownWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		opts     CleanOptions
+		expected string
+	}{
+		{
+			name:     "zero options match CleanMarkdown",
+			input:    "**Start**\n\nthen run `npm install`",
+			expected: "Start then run",
+		},
+		{
+			name:     "keeps inline code content",
+			input:    "Run `npm install` now",
+			opts:     CleanOptions{KeepInlineCode: true},
+			expected: "Run npm install now",
+		},
+		{
+			name:     "keeps paragraph breaks",
+			input:    "First line\nstill first.\n\n\n  Second [para](https://example.com).  ",
+			opts:     CleanOptions{KeepParagraphs: true},
+			expected: "First line still first.\n\nSecond para.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := CleanMarkdownWith(tt.input, tt.opts); result != tt.expected {
+				t.Errorf("CleanMarkdownWith() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestSanitizeFilename(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -164,3 +300,48 @@ func TestSanitizeFilenameLength(t *testing.T) {
 		t.Errorf("SanitizeFilename should truncate to 50 chars, got %d chars: %q", len(result), result)
 	}
 }
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "single sentence without punctuation", input: "Hello world", want: []string{"Hello world"}},
+		{
+			name:  "several sentences",
+			input: "First one. Second one!  Third one?\n\nFourth.",
+			want:  []string{"First one.", "Second one!", "Third one?", "Fourth."},
+		},
+		{
+			name:  "abbreviation inside a sentence",
+			input: "Use a flag, e.g. the format flag. Then run it.",
+			want:  []string{"Use a flag, e.g. the format flag.", "Then run it."},
+		},
+		{
+			name:  "quotes and numbers",
+			input: `He said "stop." "Why?" she asked. 3 reasons follow.`,
+			want:  []string{`He said "stop."`, `"Why?" she asked.`, "3 reasons follow."},
+		},
+		{
+			name:  "SSML block",
+			input: "Listen. <speak>One. <break time=\"1s\"/> Two.</speak> Done.",
+			want:  []string{"Listen.", "<speak>One. <break time=\"1s\"/> Two.</speak> Done."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitSentences(tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitSentences() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SplitSentences()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Package timing estimates word-level timestamps for synthesized speech.
+// It distributes a measured (or target) audio duration across the words of
+// the spoken text, for providers that do not offer alignment data.
+//
+// Key features:
+//   - Uniform or syllable-weighted word timing estimation
+//   - Extra pause weight after punctuation
+//   - Subtitle cue grouping at sentence boundaries
+//   - Sentence timings from word timings
+//   - SRT subtitle output
+package timing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Method selects how the audio duration is distributed across words.
+type Method string
+
+const (
+	// MethodUniform gives every word the same duration
+	MethodUniform Method = "uniform"
+
+	// MethodSyllable weights each word by its estimated syllable count
+	MethodSyllable Method = "syllable"
+)
+
+// Pause weights (in syllable units) added after punctuation
+const (
+	clausePauseWeight   = 0.5 // After , ; :
+	sentencePauseWeight = 1.0 // After . ! ?
+)
+
+// DefaultMaxCueWords is the default maximum number of words per subtitle cue
+const DefaultMaxCueWords = 12
+
+// Word is a single spoken word with its estimated position in the audio.
+type Word struct {
+	Text  string  `json:"text"`
+	Start float64 `json:"start"` // Start offset in seconds
+	End   float64 `json:"end"`   // End offset in seconds
+}
+
+// Sentence is a sentence with its position in the audio.
+type Sentence struct {
+	Text  string  `json:"text"`
+	Start float64 `json:"start"` // Start offset in seconds
+	End   float64 `json:"end"`   // End offset in seconds
+}
+
+// Cue is a subtitle cue spanning one or more words.
+type Cue struct {
+	Index int
+	Start float64
+	End   float64
+	Text  string
+}
+
+// ParseMethod converts a string into a timing Method.
+func ParseMethod(s string) (Method, error) {
+	switch Method(s) {
+	case MethodUniform, MethodSyllable:
+		return Method(s), nil
+	default:
+		return "", fmt.Errorf("invalid timing method %q: must be 'uniform' or 'syllable'", s)
+	}
+}
+
+// EstimateWords distributes duration (in seconds) across the words in text.
+// Returns nil if the text has no words or the duration is not positive.
+func EstimateWords(text string, duration float64, method Method) []Word {
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 || duration <= 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(tokens))
+	pauses := make([]float64, len(tokens))
+	total := 0.0
+	for i, token := range tokens {
+		weights[i] = 1
+		if method == MethodSyllable {
+			weights[i] = float64(CountSyllables(token))
+		}
+		// No trailing pause after the final word
+		if i < len(tokens)-1 {
+			pauses[i] = pauseWeight(token)
+		}
+		total += weights[i] + pauses[i]
+	}
+
+	unit := duration / total
+	words := make([]Word, len(tokens))
+	cursor := 0.0
+	for i, token := range tokens {
+		start := cursor
+		end := start + weights[i]*unit
+		words[i] = Word{Text: token, Start: start, End: end}
+		cursor = end + pauses[i]*unit
+	}
+
+	// Avoid floating point drift on the last word
+	words[len(words)-1].End = duration
+
+	return words
+}
+
+// pauseWeight returns the pause weight following a token based on its trailing punctuation.
+func pauseWeight(token string) float64 {
+	trimmed := strings.TrimRight(token, `"')]}`)
+	if trimmed == "" {
+		return 0
+	}
+	switch trimmed[len(trimmed)-1] {
+	case '.', '!', '?':
+		return sentencePauseWeight
+	case ',', ';', ':':
+		return clausePauseWeight
+	default:
+		return 0
+	}
+}
+
+// CountSyllables estimates the number of syllables in a word using vowel groups.
+// Tokens without letters (e.g., numbers) are weighted by their digit count.
+// Always returns at least 1.
+func CountSyllables(word string) int {
+	word = strings.ToLower(word)
+
+	letters := make([]rune, 0, len(word))
+	digits := 0
+	for _, r := range word {
+		switch {
+		case unicode.IsLetter(r):
+			letters = append(letters, r)
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+
+	if len(letters) == 0 {
+		// Numbers are read digit groups at a time, roughly one syllable per digit
+		return max(1, digits)
+	}
+
+	count := 0
+	prevVowel := false
+	for _, r := range letters {
+		vowel := isVowel(r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	// Silent trailing 'e' (e.g., "make"), but not "-le" endings (e.g., "table")
+	n := len(letters)
+	if n > 2 && letters[n-1] == 'e' && !isVowel(letters[n-2]) && letters[n-2] != 'l' && count > 1 {
+		count--
+	}
+
+	return max(1, count)
+}
+
+// isVowel reports whether r is a vowel (including y) for syllable counting.
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiouyàáâäèéêëìíîïòóôöùúûü", r)
+}
+
+// BuildCues groups words into subtitle cues, breaking at sentence ends
+// or after maxWords words (DefaultMaxCueWords if maxWords <= 0).
+func BuildCues(words []Word, maxWords int) []Cue {
+	if maxWords <= 0 {
+		maxWords = DefaultMaxCueWords
+	}
+
+	var cues []Cue
+	var current []Word
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		texts := make([]string, len(current))
+		for i, w := range current {
+			texts[i] = w.Text
+		}
+		cues = append(cues, Cue{
+			Index: len(cues) + 1,
+			Start: current[0].Start,
+			End:   current[len(current)-1].End,
+			Text:  strings.Join(texts, " "),
+		})
+		current = nil
+	}
+
+	for _, w := range words {
+		current = append(current, w)
+		if len(current) >= maxWords || pauseWeight(w.Text) == sentencePauseWeight {
+			flush()
+		}
+	}
+	flush()
+
+	return cues
+}
+
+// GroupSentences assigns word timings to sentences, in order, by their word counts.
+// Returns nil if the sentences do not have as many words as there are word timings.
+func GroupSentences(sentences []string, words []Word) []Sentence {
+	total := 0
+	for _, sentence := range sentences {
+		total += len(strings.Fields(sentence))
+	}
+	if total == 0 || total != len(words) {
+		return nil
+	}
+
+	grouped := make([]Sentence, 0, len(sentences))
+	next := 0
+	for _, sentence := range sentences {
+		count := len(strings.Fields(sentence))
+		if count == 0 {
+			continue
+		}
+		grouped = append(grouped, Sentence{Text: sentence, Start: words[next].Start, End: words[next+count-1].End})
+		next += count
+	}
+	return grouped
+}
+
+// FormatSRTTimestamp formats seconds as an SRT timestamp (HH:MM:SS,mmm).
+func FormatSRTTimestamp(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	h := ms / 3_600_000
+	m := (ms / 60_000) % 60
+	s := (ms / 1000) % 60
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, s, ms%1000)
+}
+
+// WriteSRT writes cues in SubRip (SRT) format.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	for _, cue := range cues {
+		if _, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n",
+			cue.Index, FormatSRTTimestamp(cue.Start), FormatSRTTimestamp(cue.End), cue.Text); err != nil {
+			return fmt.Errorf("failed to write SRT cue: %w", err)
+		}
+	}
+	return nil
+}
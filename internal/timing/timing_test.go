@@ -0,0 +1,210 @@
+package timing
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Method
+		expectError bool
+	}{
+		{input: "uniform", expected: MethodUniform},
+		{input: "syllable", expected: MethodSyllable},
+		{input: "linear", expectError: true},
+		{input: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			method, err := ParseMethod(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseMethod(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMethod(%q) error = %v", tt.input, err)
+			}
+			if method != tt.expected {
+				t.Errorf("ParseMethod(%q) = %q, want %q", tt.input, method, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected int
+	}{
+		{word: "a", expected: 1},
+		{word: "cat", expected: 1},
+		{word: "make", expected: 1},
+		{word: "table", expected: 2},
+		{word: "hello", expected: 2},
+		{word: "beautiful", expected: 3},
+		{word: "documentation,", expected: 5},
+		{word: "2025", expected: 4},
+		{word: "—", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := CountSyllables(tt.word); got != tt.expected {
+				t.Errorf("CountSyllables(%q) = %d, want %d", tt.word, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEstimateWordsUniform(t *testing.T) {
+	words := EstimateWords("one two three four", 4.0, MethodUniform)
+	if len(words) != 4 {
+		t.Fatalf("Expected 4 words, got %d", len(words))
+	}
+
+	for i, w := range words {
+		if !almostEqual(w.Start, float64(i)) || !almostEqual(w.End, float64(i+1)) {
+			t.Errorf("Word %d (%s) = [%.3f, %.3f], want [%d, %d]", i, w.Text, w.Start, w.End, i, i+1)
+		}
+	}
+}
+
+func TestEstimateWordsSyllableWeighted(t *testing.T) {
+	words := EstimateWords("cat beautiful", 4.0, MethodSyllable)
+	if len(words) != 2 {
+		t.Fatalf("Expected 2 words, got %d", len(words))
+	}
+
+	// 1 syllable vs 3 syllables
+	if !almostEqual(words[0].End, 1.0) {
+		t.Errorf("Expected first word to end at 1.0, got %.3f", words[0].End)
+	}
+	if !almostEqual(words[1].Start, 1.0) || !almostEqual(words[1].End, 4.0) {
+		t.Errorf("Expected second word [1.0, 4.0], got [%.3f, %.3f]", words[1].Start, words[1].End)
+	}
+}
+
+func TestEstimateWordsPunctuationPause(t *testing.T) {
+	words := EstimateWords("Hello. World", 3.0, MethodUniform)
+	if len(words) != 2 {
+		t.Fatalf("Expected 2 words, got %d", len(words))
+	}
+
+	// weights: 1 + 1 (sentence pause) + 1 = 3 units of 1s each
+	if !almostEqual(words[0].End, 1.0) || !almostEqual(words[1].Start, 2.0) {
+		t.Errorf("Expected a 1s pause after the sentence, got %.3f -> %.3f", words[0].End, words[1].Start)
+	}
+	if words[1].End != 3.0 {
+		t.Errorf("Expected last word to end exactly at duration, got %f", words[1].End)
+	}
+}
+
+func TestEstimateWordsEmpty(t *testing.T) {
+	if words := EstimateWords("   ", 5.0, MethodUniform); words != nil {
+		t.Errorf("Expected nil for empty text, got %v", words)
+	}
+	if words := EstimateWords("hello", 0, MethodUniform); words != nil {
+		t.Errorf("Expected nil for zero duration, got %v", words)
+	}
+}
+
+func TestBuildCues(t *testing.T) {
+	words := EstimateWords("First sentence here. Second one is a bit longer than the limit", 10.0, MethodUniform)
+	cues := BuildCues(words, 4)
+
+	expected := []string{
+		"First sentence here.",
+		"Second one is a",
+		"bit longer than the",
+		"limit",
+	}
+	if len(cues) != len(expected) {
+		t.Fatalf("Expected %d cues, got %d: %+v", len(expected), len(cues), cues)
+	}
+	for i, cue := range cues {
+		if cue.Text != expected[i] {
+			t.Errorf("Cue %d text = %q, want %q", i, cue.Text, expected[i])
+		}
+		if cue.Index != i+1 {
+			t.Errorf("Cue %d index = %d, want %d", i, cue.Index, i+1)
+		}
+		if cue.End < cue.Start {
+			t.Errorf("Cue %d ends before it starts: %+v", i, cue)
+		}
+	}
+}
+
+func TestFormatSRTTimestamp(t *testing.T) {
+	tests := []struct {
+		seconds  float64
+		expected string
+	}{
+		{seconds: 0, expected: "00:00:00,000"},
+		{seconds: 1.5, expected: "00:00:01,500"},
+		{seconds: 61.0005, expected: "00:01:01,001"},
+		{seconds: 3723.25, expected: "01:02:03,250"},
+		{seconds: -1, expected: "00:00:00,000"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatSRTTimestamp(tt.seconds); got != tt.expected {
+			t.Errorf("FormatSRTTimestamp(%v) = %q, want %q", tt.seconds, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	cues := []Cue{
+		{Index: 1, Start: 0, End: 1.25, Text: "Hello there."},
+		{Index: 2, Start: 1.5, End: 3, Text: "General Kenobi."},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSRT(&buf, cues); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+
+	expected := "1\n00:00:00,000 --> 00:00:01,250\nHello there.\n\n" +
+		"2\n00:00:01,500 --> 00:00:03,000\nGeneral Kenobi.\n\n"
+	if buf.String() != expected {
+		t.Errorf("WriteSRT() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestGroupSentences(t *testing.T) {
+	words := []Word{
+		{Text: "Hello", Start: 0, End: 0.4},
+		{Text: "there.", Start: 0.4, End: 0.8},
+		{Text: "Welcome", Start: 1.0, End: 1.5},
+		{Text: "back", Start: 1.5, End: 1.8},
+		{Text: "today.", Start: 1.8, End: 2.2},
+	}
+
+	got := GroupSentences([]string{"Hello there.", "Welcome back today."}, words)
+	want := []Sentence{
+		{Text: "Hello there.", Start: 0, End: 0.8},
+		{Text: "Welcome back today.", Start: 1.0, End: 2.2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GroupSentences() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GroupSentences()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := GroupSentences([]string{"Hello there."}, words); got != nil {
+		t.Errorf("Expected nil for mismatched word counts, got %+v", got)
+	}
+}
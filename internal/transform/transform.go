@@ -0,0 +1,80 @@
+// Package transform rewrites section text before synthesis.
+// Transforms let library users and the CLI (via -transform-cmd) apply custom
+// preprocessing such as terminology enforcement or profanity filtering.
+//
+// Key features:
+//   - Func hook type for in-process transforms
+//   - Command transform piping section text through an external program
+//   - Ordered application with errors naming the failing section
+package transform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+// Func rewrites a section before synthesis. It may change any field,
+// typically Content; the returned section replaces the original.
+type Func func(parser.Section) (parser.Section, error)
+
+// Command returns a transform that runs command with the section text on stdin
+// and uses its stdout as the new text. The command line is split on whitespace
+// (no shell quoting). The section title and index are passed in the
+// MD2AUDIO_SECTION_TITLE and MD2AUDIO_SECTION_INDEX environment variables.
+func Command(ctx context.Context, command string) (Func, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("transform command is empty")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("transform command not found: %s", args[0])
+	}
+
+	return func(section parser.Section) (parser.Section, error) {
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(section.Content)
+		cmd.Env = append(os.Environ(),
+			"MD2AUDIO_SECTION_TITLE="+section.Title,
+			"MD2AUDIO_SECTION_INDEX="+strconv.Itoa(section.Index),
+		)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return section, fmt.Errorf("transform command failed: %w\nOutput: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		section.Content = strings.TrimSpace(stdout.String())
+		return section, nil
+	}, nil
+}
+
+// Apply runs the transforms in order on each section.
+// A section whose text a transform leaves empty is an error.
+func Apply(sections []parser.Section, transforms []Func) ([]parser.Section, error) {
+	if len(transforms) == 0 {
+		return sections, nil
+	}
+
+	result := make([]parser.Section, len(sections))
+	for i, section := range sections {
+		var err error
+		for _, transform := range transforms {
+			if section, err = transform(section); err != nil {
+				return nil, fmt.Errorf("error transforming section %d %q: %w", sections[i].Index, sections[i].Title, err)
+			}
+		}
+		if strings.TrimSpace(section.Content) == "" {
+			return nil, fmt.Errorf("error transforming section %d %q: no text left", sections[i].Index, sections[i].Title)
+		}
+		result[i] = section
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/parser"
+)
+
+func TestApply(t *testing.T) {
+	sections := []parser.Section{
+		{Index: 1, Title: "Intro", Content: "Welcome to k8s"},
+		{Index: 2, Title: "Setup", Content: "Install k8s tools"},
+	}
+	expand := func(s parser.Section) (parser.Section, error) {
+		s.Content = strings.ReplaceAll(s.Content, "k8s", "Kubernetes")
+		return s, nil
+	}
+	upper := func(s parser.Section) (parser.Section, error) {
+		s.Content = strings.ToUpper(s.Content)
+		return s, nil
+	}
+
+	tests := []struct {
+		name       string
+		transforms []Func
+		want       []string
+		wantErr    string
+	}{
+		{name: "no transforms", want: []string{"Welcome to k8s", "Install k8s tools"}},
+		{name: "applied in order", transforms: []Func{expand, upper}, want: []string{"WELCOME TO KUBERNETES", "INSTALL KUBERNETES TOOLS"}},
+		{
+			name: "error names the section",
+			transforms: []Func{func(s parser.Section) (parser.Section, error) {
+				if s.Index == 2 {
+					return s, errors.New("blocked term")
+				}
+				return s, nil
+			}},
+			wantErr: `section 2 "Setup": blocked term`,
+		},
+		{
+			name: "empty text",
+			transforms: []Func{func(s parser.Section) (parser.Section, error) {
+				s.Content = " "
+				return s, nil
+			}},
+			wantErr: `section 1 "Intro": no text left`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(sections, tt.transforms)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Apply() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			for i, want := range tt.want {
+				if got[i].Content != want {
+					t.Errorf("section %d = %q, want %q", i+1, got[i].Content, want)
+				}
+			}
+		})
+	}
+
+	if sections[0].Content != "Welcome to k8s" {
+		t.Error("Apply() must not modify the input sections")
+	}
+}
+
+func TestCommand(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+
+	transform, err := Command(context.Background(), "tr a-z A-Z")
+	if err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	got, err := transform(parser.Section{Index: 1, Title: "Intro", Content: "hello world"})
+	if err != nil {
+		t.Fatalf("transform error = %v", err)
+	}
+	if got.Content != "HELLO WORLD" || got.Title != "Intro" {
+		t.Errorf("transform = %+v", got)
+	}
+}
+
+func TestCommandEnvironment(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	script := filepath.Join(t.TempDir(), "section.sh")
+	content := "#!/bin/sh\necho \"$MD2AUDIO_SECTION_INDEX: $MD2AUDIO_SECTION_TITLE\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transform, err := Command(context.Background(), script)
+	if err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	got, err := transform(parser.Section{Index: 3, Title: "Usage", Content: "text"})
+	if err != nil {
+		t.Fatalf("transform error = %v", err)
+	}
+	if got.Content != "3: Usage" {
+		t.Errorf("transform = %q, want %q", got.Content, "3: Usage")
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	if _, err := Command(context.Background(), "  "); err == nil {
+		t.Error("expected error for empty command")
+	}
+	if _, err := Command(context.Background(), "md2audio-no-such-command --flag"); err == nil {
+		t.Error("expected error for missing command")
+	}
+}
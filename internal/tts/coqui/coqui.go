@@ -0,0 +1,250 @@
+// Package coqui implements the TTS Provider interface for Coqui XTTS served
+// over HTTP by xtts-api-server. XTTS clones a voice from a short speaker WAV
+// reference and runs locally, so voices can be custom without a paid API.
+//
+// Key features:
+//   - Synthesis with a speaker WAV reference and language
+//   - WAV responses streamed to disk, converted with ffmpeg to mp3, m4a, or aiff
+//   - Speaker listing from the server's speakers folder
+package coqui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+const (
+	// DefaultURL is the default address of xtts-api-server
+	DefaultURL = "http://localhost:8020"
+
+	// DefaultLanguage is the language spoken when none is specified
+	DefaultLanguage = "en"
+)
+
+// Provider implements the TTS Provider interface for an XTTS server.
+type Provider struct {
+	baseURL    string
+	speakerWav string
+	language   string
+	httpClient *http.Client
+	retry      httpclient.RetryPolicy // Retries of failed requests
+}
+
+// Config holds configuration for the Coqui provider.
+type Config struct {
+	URL        string // Server base URL (default: DefaultURL)
+	SpeakerWav string // Speaker WAV reference used when requests have no voice (file name in the server's speakers folder, or a path on the server)
+	Language   string // Language code (default: DefaultLanguage)
+	HTTPClient *http.Client
+	Retry      httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// NewProvider creates a new Coqui provider.
+func NewProvider(cfg Config) (*Provider, error) {
+	baseURL := strings.TrimRight(cfg.URL, "/")
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("invalid Coqui server URL %q: must start with http:// or https://", cfg.URL)
+	}
+
+	language := cfg.Language
+	if language == "" {
+		language = DefaultLanguage
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		// Local synthesis of long sections can take minutes on a CPU
+		httpClient = &http.Client{
+			Timeout: 5 * time.Minute,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{
+		baseURL:    baseURL,
+		speakerWav: cfg.SpeakerWav,
+		language:   language,
+		httpClient: httpClient,
+		retry:      retry,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "coqui"
+}
+
+// ttsRequest is the body of a synthesis request
+type ttsRequest struct {
+	Text       string `json:"text"`
+	SpeakerWav string `json:"speaker_wav"`
+	Language   string `json:"language"`
+}
+
+// buildRequest returns the URL and JSON body of the synthesis request for req.
+func (p *Provider) buildRequest(req tts.GenerateRequest) (string, []byte, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return "", nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	speakerWav := req.Voice
+	if speakerWav == "" {
+		speakerWav = p.speakerWav
+	}
+	if speakerWav == "" {
+		return "", nil, fmt.Errorf("no speaker WAV reference: use -coqui-speaker-wav or -v")
+	}
+
+	body, err := json.Marshal(ttsRequest{Text: text, SpeakerWav: speakerWav, Language: p.language})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return p.baseURL + "/tts_to_audio/", body, nil
+}
+
+// PreviewRequest returns the API request that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	url, bodyBytes, err := p.buildRequest(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	var body bytes.Buffer
+	if err := json.Indent(&body, bodyBytes, "", "  "); err != nil {
+		return tts.RequestPreview{}, fmt.Errorf("failed to format request: %w", err)
+	}
+
+	return tts.RequestPreview{
+		Target:  http.MethodPost + " " + url,
+		Headers: map[string]string{"Content-Type": "application/json", "Accept": "audio/wav"},
+		Body:    body.String(),
+	}, nil
+}
+
+// Generate creates audio from text using the XTTS server.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	url, bodyBytes, err := p.buildRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "audio/wav")
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach the Coqui server at %s (is it running?): %w", p.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("Coqui server request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The server returns WAV, streamed to disk as it arrives
+	wavPath := strings.TrimSuffix(req.OutputPath, filepath.Ext(req.OutputPath)) + ".wav"
+	if err := writeWAV(wavPath, resp.Body); err != nil {
+		return "", err
+	}
+
+	// Convert to other formats if requested
+	if req.Format == "wav" || req.Format == "" {
+		return wavPath, nil
+	}
+	convertedPath := strings.TrimSuffix(wavPath, ".wav") + "." + req.Format
+	if err := utils.ConvertWAV(ctx, wavPath, convertedPath, req.Format); err != nil {
+		return wavPath, fmt.Errorf("audio created but conversion to %s failed: %w", req.Format, err)
+	}
+	if err := os.Remove(wavPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not remove temporary wav file: %v\n", err)
+	}
+	return convertedPath, nil
+}
+
+// writeWAV streams a WAV response to path, refusing bodies that are not WAV
+// data (e.g., an error page returned with status 200).
+func writeWAV(path string, r io.Reader) error {
+	head := make([]byte, 12)
+	n, _ := io.ReadFull(r, head)
+	if n < 12 || string(head[:4]) != "RIFF" || string(head[8:12]) != "WAVE" {
+		return fmt.Errorf("Coqui server returned non-WAV data: %q", head[:n])
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := io.Copy(f, io.MultiReader(bytes.NewReader(head), r)); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write audio data: %w", err)
+	}
+	return nil
+}
+
+// ListVoices returns the speaker WAV references available on the server.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/speakers_list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the Coqui server at %s (is it running?): %w", p.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("speaker list request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var speakers []string
+	if err := json.NewDecoder(resp.Body).Decode(&speakers); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	voices := make([]tts.Voice, 0, len(speakers))
+	for _, speaker := range speakers {
+		voices = append(voices, tts.Voice{
+			ID:          speaker,
+			Name:        speaker,
+			Language:    p.language,
+			Description: "XTTS speaker reference",
+		})
+	}
+	return voices, nil
+}
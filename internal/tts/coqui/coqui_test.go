@@ -0,0 +1,167 @@
+package coqui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// testWAV is a valid WAV file with an empty data chunk
+const testWAV = "RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x22\x56\x00\x00\x44\xac\x00\x00\x02\x00\x10\x00data\x00\x00\x00\x00"
+
+func TestGenerate(t *testing.T) {
+	var got ttsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/tts_to_audio/" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte(testWAV))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL + "/", SpeakerWav: "narrator.wav", Language: "it"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Ciao a tutti",
+		OutputPath: filepath.Join(t.TempDir(), "out", "section.wav"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != testWAV {
+		t.Errorf("output = %q, want the server's WAV", data)
+	}
+	if got != (ttsRequest{Text: "Ciao a tutti", SpeakerWav: "narrator.wav", Language: "it"}) {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestGenerateConvert(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testWAV))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello",
+		Voice:      "speaker.wav",
+		OutputPath: filepath.Join(t.TempDir(), "section.mp3"),
+		Format:     "mp3",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".mp3" {
+		t.Errorf("output path = %s, want .mp3", outputPath)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(outputPath, ".mp3") + ".wav"); !os.IsNotExist(err) {
+		t.Error("intermediate WAV file was not removed")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/html/") {
+			_, _ = w.Write([]byte("<html>oops</html>"))
+			return
+		}
+		http.Error(w, "speaker not found", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	ctx := context.Background()
+	outputPath := filepath.Join(t.TempDir(), "section.wav")
+
+	if _, err := provider.Generate(ctx, tts.GenerateRequest{Text: "Hello", OutputPath: outputPath}); err == nil || !strings.Contains(err.Error(), "speaker WAV") {
+		t.Errorf("Generate() without a speaker error = %v", err)
+	}
+	if _, err := provider.Generate(ctx, tts.GenerateRequest{Text: "Hello", Voice: "x.wav", OutputPath: outputPath}); err == nil || !strings.Contains(err.Error(), "speaker not found") {
+		t.Errorf("Generate() error = %v, want the server error", err)
+	}
+
+	provider.baseURL += "/html"
+	if _, err := provider.Generate(ctx, tts.GenerateRequest{Text: "Hello", Voice: "x.wav", OutputPath: outputPath}); err == nil || !strings.Contains(err.Error(), "non-WAV") {
+		t.Errorf("Generate() error = %v, want a non-WAV error", err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Error("output file written for a failed request")
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/speakers_list" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`["calm_female", "narrator"]`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL, Language: "de"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	if len(voices) != 2 || voices[1].ID != "narrator" || voices[1].Language != "de" {
+		t.Errorf("unexpected voices: %+v", voices)
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{SpeakerWav: "narrator.wav"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "Hello"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "POST "+DefaultURL+"/tts_to_audio/" {
+		t.Errorf("target = %s", preview.Target)
+	}
+	if !strings.Contains(preview.Body, `"speaker_wav": "narrator.wav"`) || !strings.Contains(preview.Body, `"language": "en"`) {
+		t.Errorf("unexpected body: %s", preview.Body)
+	}
+}
+
+func TestNewProviderInvalidURL(t *testing.T) {
+	if _, err := NewProvider(Config{URL: "localhost:8020"}); err == nil {
+		t.Error("expected an error for a URL without scheme")
+	}
+}
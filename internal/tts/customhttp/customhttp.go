@@ -0,0 +1,341 @@
+// Package customhttp implements the TTS Provider interface for HTTP services
+// described by a configuration file, so in-house TTS services can be used
+// without writing code or forking md2audio.
+//
+// Key features:
+//   - Endpoint URL, method, and headers (with environment variable expansion)
+//   - JSON body template with {{.Text}}, {{.Voice}}, and {{.Format}} placeholders
+//   - Binary audio responses, or base64 audio in a JSON response field
+//   - Static voice list for -list-voices
+package customhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// Name is the provider name selected with -provider
+const Name = "custom-http"
+
+// Response types
+const (
+	ResponseBinary = "binary" // The response body is the audio
+	ResponseBase64 = "base64" // The audio is base64-encoded in a field of a JSON response
+)
+
+// Config describes the HTTP service, as read from the configuration file.
+type Config struct {
+	URL      string            `json:"url"`               // Endpoint URL ($VAR and ${VAR} are expanded)
+	Method   string            `json:"method,omitempty"`  // HTTP method (default: POST)
+	Headers  map[string]string `json:"headers,omitempty"` // Request headers ($VAR and ${VAR} are expanded)
+	Body     string            `json:"body"`              // Body template (text/template)
+	Response ResponseConfig    `json:"response"`          // Where the audio is in the response
+	Format   string            `json:"format,omitempty"`  // Audio format returned by the service (default: mp3)
+	Voices   []Voice           `json:"voices,omitempty"`  // Voices listed by -list-voices
+
+	HTTPClient *http.Client           `json:"-"`
+	Retry      httpclient.RetryPolicy `json:"-"` // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// ResponseConfig describes how audio is read from a response.
+type ResponseConfig struct {
+	Type  string `json:"type,omitempty"`  // ResponseBinary (default) or ResponseBase64
+	Field string `json:"field,omitempty"` // Dot-separated path of the base64 field (e.g., "data.audio")
+}
+
+// Voice is a voice listed in the configuration file.
+type Voice struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+}
+
+// TemplateData holds the values available in the body template. String
+// values are escaped for use inside JSON strings, e.g. "text": "{{.Text}}".
+type TemplateData struct {
+	Text   string
+	Voice  string
+	Format string
+	SSML   bool
+}
+
+// Provider implements the TTS Provider interface for a configured HTTP service.
+type Provider struct {
+	config     Config
+	body       *template.Template
+	httpClient *http.Client
+	retry      httpclient.RetryPolicy // Retries of failed requests
+}
+
+// LoadConfig reads a service description from a JSON file:
+//
+//	{"url": "https://tts.example.com/speak", "headers": {"Authorization": "Bearer ${TTS_TOKEN}"},
+//	 "body": "{\"text\": \"{{.Text}}\", \"voice\": \"{{.Voice}}\"}", "response": {"type": "binary"}, "format": "mp3"}
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read custom HTTP provider file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid custom HTTP provider file %s: %w", path, err)
+	}
+	if _, err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid custom HTTP provider file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks the configuration and returns the parsed body template
+func (c Config) validate() (*template.Template, error) {
+	if c.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if url := os.ExpandEnv(c.URL); !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid url %q: must start with http:// or https://", c.URL)
+	}
+	switch c.Response.Type {
+	case "", ResponseBinary:
+	case ResponseBase64:
+		if c.Response.Field == "" {
+			return nil, fmt.Errorf("response.field is required for base64 responses")
+		}
+	default:
+		return nil, fmt.Errorf("invalid response type %q: must be %q or %q", c.Response.Type, ResponseBinary, ResponseBase64)
+	}
+	body, err := template.New("body").Option("missingkey=error").Parse(c.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	return body, nil
+}
+
+// NewProvider creates a provider for the described service.
+func NewProvider(cfg Config) (*Provider, error) {
+	body, err := cfg.validate()
+	if err != nil {
+		return nil, fmt.Errorf("custom HTTP provider: %w", err)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.Format == "" {
+		cfg.Format = "mp3"
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 60 * time.Second,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{config: cfg, body: body, httpClient: httpClient, retry: retry}, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return Name
+}
+
+// buildRequest returns the URL, headers, and body of the request for req.
+func (p *Provider) buildRequest(req tts.GenerateRequest) (string, map[string]string, []byte, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return "", nil, nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	var body bytes.Buffer
+	data := TemplateData{Text: jsonEscape(text), Voice: jsonEscape(req.Voice), Format: jsonEscape(req.Format), SSML: req.SSML}
+	if err := p.body.Execute(&body, data); err != nil {
+		return "", nil, nil, fmt.Errorf("failed to render request body: %w", err)
+	}
+
+	headers := make(map[string]string, len(p.config.Headers))
+	for name, value := range p.config.Headers {
+		headers[name] = os.ExpandEnv(value)
+	}
+	return os.ExpandEnv(p.config.URL), headers, body.Bytes(), nil
+}
+
+// jsonEscape escapes s for use inside a JSON string
+func jsonEscape(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// PreviewRequest returns the request that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	url, headers, body, err := p.buildRequest(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	// Header values often hold credentials
+	for name, value := range headers {
+		if !strings.EqualFold(name, "Content-Type") && !strings.EqualFold(name, "Accept") {
+			headers[name] = maskSecret(value)
+		}
+	}
+	return tts.RequestPreview{
+		Target:  p.config.Method + " " + url,
+		Headers: headers,
+		Body:    string(body),
+	}, nil
+}
+
+// maskSecret masks all but the last 4 characters of a secret.
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// Generate creates audio from text using the configured service.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	url, headers, body, err := p.buildRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, p.config.Method, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range headers {
+		httpReq.Header.Set(name, value)
+	}
+	if httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return "", &tts.HardFailure{Scope: tts.ScopeAuth, Err: err}
+		}
+		return "", err
+	}
+
+	audio, err := p.readAudio(resp)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The service returns the configured format, ensure correct extension
+	outputPath := strings.TrimSuffix(req.OutputPath, filepath.Ext(req.OutputPath)) + "." + p.config.Format
+	if err := os.WriteFile(outputPath, audio, 0644); err != nil {
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+
+	// WAV can be converted to other formats if requested
+	if p.config.Format != "wav" || req.Format == "wav" || req.Format == "" {
+		return outputPath, nil
+	}
+	convertedPath := strings.TrimSuffix(outputPath, ".wav") + "." + req.Format
+	if err := utils.ConvertWAV(ctx, outputPath, convertedPath, req.Format); err != nil {
+		return outputPath, fmt.Errorf("audio created but conversion to %s failed: %w", req.Format, err)
+	}
+	if err := os.Remove(outputPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not remove temporary wav file: %v\n", err)
+	}
+	return convertedPath, nil
+}
+
+// readAudio returns the audio of a successful response.
+func (p *Provider) readAudio(resp *http.Response) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	if p.config.Response.Type != ResponseBase64 {
+		// Refuse error bodies sent in place of audio
+		if mediaType == "application/json" || strings.HasPrefix(mediaType, "text/") {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+			return nil, fmt.Errorf("service returned %s instead of audio: %s", mediaType, body)
+		}
+		audio, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio data: %w", err)
+		}
+		if len(audio) == 0 {
+			return nil, fmt.Errorf("service returned an empty audio response")
+		}
+		return audio, nil
+	}
+
+	var doc any
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	for _, key := range strings.Split(p.config.Response.Field, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("response has no field %q", p.config.Response.Field)
+		}
+		doc = obj[key]
+	}
+	encoded, ok := doc.(string)
+	if !ok || encoded == "" {
+		return nil, fmt.Errorf("response field %q is not a base64 string", p.config.Response.Field)
+	}
+	audio, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response field %q: %w", p.config.Response.Field, err)
+	}
+	return audio, nil
+}
+
+// ListVoices returns the voices listed in the configuration file.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	voices := make([]tts.Voice, 0, len(p.config.Voices))
+	for _, v := range p.config.Voices {
+		name := v.Name
+		if name == "" {
+			name = v.ID
+		}
+		voices = append(voices, tts.Voice{
+			ID:          v.ID,
+			Name:        name,
+			Description: v.Description,
+			Language:    v.Language,
+			Gender:      v.Gender,
+		})
+	}
+	return voices, nil
+}
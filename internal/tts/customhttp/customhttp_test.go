@@ -0,0 +1,193 @@
+package customhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+func TestGenerateBinary(t *testing.T) {
+	t.Setenv("TEST_TTS_TOKEN", "token-123")
+
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/speak" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token-123" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("body is not valid JSON: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write([]byte("OggS"))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{
+		URL:     server.URL + "/speak",
+		Method:  http.MethodPut,
+		Headers: map[string]string{"Authorization": "Bearer ${TEST_TTS_TOKEN}"},
+		Body:    `{"text": "{{.Text}}", "voice": "{{.Voice}}"}`,
+		Format:  "ogg",
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Say \"hi\"\nthen stop",
+		Voice:      "anna",
+		OutputPath: filepath.Join(t.TempDir(), "section.mp3"),
+		Format:     "mp3",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".ogg" {
+		t.Errorf("output path = %s, want the service format", outputPath)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "OggS" {
+		t.Errorf("output = %q", data)
+	}
+	if body["text"] != "Say \"hi\"\nthen stop" || body["voice"] != "anna" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestGenerateBase64(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data": {"audio": "SUQzYXVkaW8="}}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{
+		URL:      server.URL,
+		Body:     `{"input": "{{.Text}}"}`,
+		Response: ResponseConfig{Type: ResponseBase64, Field: "data.audio"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "section.mp3")})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "ID3audio" {
+		t.Errorf("output = %q, want the decoded field", data)
+	}
+
+	provider.config.Response.Field = "data.missing"
+	if _, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: outputPath}); err == nil || !strings.Contains(err.Error(), "data.missing") {
+		t.Errorf("Generate() error = %v, want a missing field error", err)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/denied":
+			http.Error(w, "bad token", http.StatusUnauthorized)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = io.WriteString(w, "<html>maintenance</html>")
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL + "/denied", Body: "{{.Text}}"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	req := tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "section.mp3")}
+
+	var hard *tts.HardFailure
+	if _, err := provider.Generate(context.Background(), req); !errors.As(err, &hard) || hard.Scope != tts.ScopeAuth {
+		t.Errorf("Generate() error = %v, want an auth failure", err)
+	}
+
+	provider.config.URL = server.URL + "/html"
+	if _, err := provider.Generate(context.Background(), req); err == nil || !strings.Contains(err.Error(), "instead of audio") {
+		t.Errorf("Generate() error = %v, want a non-audio error", err)
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{
+		URL:     "https://tts.example.com/speak",
+		Headers: map[string]string{"X-Api-Key": "secret-value", "Accept": "audio/mpeg"},
+		Body:    `{"text": "{{.Text}}", "format": "{{.Format}}"}`,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "Hello", Format: "mp3"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "POST https://tts.example.com/speak" {
+		t.Errorf("target = %s", preview.Target)
+	}
+	if preview.Headers["X-Api-Key"] != "****alue" || preview.Headers["Accept"] != "audio/mpeg" {
+		t.Errorf("headers = %v, want credentials masked", preview.Headers)
+	}
+	if preview.Body != `{"text": "Hello", "format": "mp3"}` {
+		t.Errorf("body = %s", preview.Body)
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	provider, err := NewProvider(Config{URL: "https://tts.example.com", Voices: []Voice{{ID: "anna", Language: "en-US"}}})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	if len(voices) != 1 || voices[0].Name != "anna" || voices[0].Language != "en-US" {
+		t.Errorf("voices = %+v", voices)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: `{"url": "https://tts.example.com", "body": "{\"text\": \"{{.Text}}\"}"}`},
+		{name: "missing url", content: `{"body": "{{.Text}}"}`, wantErr: "url is required"},
+		{name: "invalid url", content: `{"url": "tts.example.com"}`, wantErr: "must start with http"},
+		{name: "unknown response type", content: `{"url": "https://x", "response": {"type": "xml"}}`, wantErr: "invalid response type"},
+		{name: "base64 without field", content: `{"url": "https://x", "response": {"type": "base64"}}`, wantErr: "response.field is required"},
+		{name: "invalid template", content: `{"url": "https://x", "body": "{{.Text"}`, wantErr: "invalid body template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "service.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(path)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("LoadConfig() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,473 @@
+// Package edge implements the TTS Provider interface for the Microsoft Edge
+// read aloud service. It offers the neural voices of the Edge browser without
+// an API key or account, as an alternative to paid cloud providers.
+//
+// Key features:
+//   - Synthesis over the service's WebSocket protocol (MP3, or PCM wrapped as WAV)
+//   - Voice listing with locales, genders, and voice personalities
+//   - Rate and pitch options, with the rate adjusted to timed sections
+//   - Long texts split into several requests and joined
+package edge
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+const (
+	// SynthesisURL is the default WebSocket endpoint of the read aloud service
+	SynthesisURL = "wss://speech.platform.bing.com/consumer/speech/synthesize/readaloud/edge/v1"
+
+	// VoicesURL is the default voice list endpoint of the read aloud service
+	VoicesURL = "https://speech.platform.bing.com/consumer/speech/synthesize/readaloud/voices/list"
+
+	// TrustedClientToken is the public token the Edge browser sends with every request
+	TrustedClientToken = "6A5AA1D4EAFF4E9FB37E23D68491D6F4"
+
+	// DefaultVoice is the voice used when none is specified
+	DefaultVoice = "en-US-AriaNeural"
+
+	// MP3OutputFormat is the output format requested for MP3 generation
+	MP3OutputFormat = "audio-24khz-48kbitrate-mono-mp3"
+
+	// PCMOutputFormat is the output format requested for lossless (WAV) generation
+	PCMOutputFormat = "raw-24khz-16bit-mono-pcm"
+
+	// PCMSampleRate is the sample rate of PCMOutputFormat
+	PCMSampleRate = 24000
+
+	// NaturalWPM approximates the speaking rate of neural voices at rate +0%
+	NaturalWPM = 160.0
+)
+
+// chromiumVersion is the Edge version the service expects clients to be
+const chromiumVersion = "130.0.2849.68"
+
+// maxChunkBytes is the size of the text sent per request; the service
+// rejects SSML documents much larger than 4 KB
+const maxChunkBytes = 3000
+
+var (
+	ratePattern  = regexp.MustCompile(`^[+-]\d+%$`)
+	pitchPattern = regexp.MustCompile(`^[+-]\d+(Hz|%)$`)
+)
+
+// Provider implements the TTS Provider interface for the Edge read aloud service.
+type Provider struct {
+	synthesisURL string
+	voicesURL    string
+	httpClient   *http.Client
+	retry        httpclient.RetryPolicy // Retries of failed requests
+	rate         string
+	pitch        string
+}
+
+// Config holds configuration for the Edge provider.
+type Config struct {
+	Rate         string // Speaking rate relative to the voice's default (e.g., "+10%", default: "+0%")
+	Pitch        string // Pitch relative to the voice's default (e.g., "-5Hz", default: "+0Hz")
+	SynthesisURL string // WebSocket endpoint (defaults to SynthesisURL)
+	VoicesURL    string // Voice list endpoint (defaults to VoicesURL)
+	HTTPClient   *http.Client
+	Retry        httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// NewProvider creates a new Edge provider.
+func NewProvider(cfg Config) (*Provider, error) {
+	rate := cfg.Rate
+	if rate == "" {
+		rate = "+0%"
+	}
+	if err := ValidateRate(rate); err != nil {
+		return nil, err
+	}
+
+	pitch := cfg.Pitch
+	if pitch == "" {
+		pitch = "+0Hz"
+	}
+	if err := ValidatePitch(pitch); err != nil {
+		return nil, err
+	}
+
+	synthesisURL := cfg.SynthesisURL
+	if synthesisURL == "" {
+		synthesisURL = SynthesisURL
+	}
+
+	voicesURL := cfg.VoicesURL
+	if voicesURL == "" {
+		voicesURL = VoicesURL
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 60 * time.Second,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{
+		synthesisURL: synthesisURL,
+		voicesURL:    voicesURL,
+		httpClient:   httpClient,
+		retry:        retry,
+		rate:         rate,
+		pitch:        pitch,
+	}, nil
+}
+
+// ValidateRate checks a relative speaking rate such as "+10%" or "-20%".
+func ValidateRate(rate string) error {
+	if !ratePattern.MatchString(rate) {
+		return fmt.Errorf("invalid Edge rate %q: must be a signed percentage (e.g., +10%% or -20%%)", rate)
+	}
+	return nil
+}
+
+// ValidatePitch checks a relative pitch such as "+5Hz" or "-10%".
+func ValidatePitch(pitch string) error {
+	if !pitchPattern.MatchString(pitch) {
+		return fmt.Errorf("invalid Edge pitch %q: must be signed Hz or a percentage (e.g., +5Hz or -10%%)", pitch)
+	}
+	return nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "edge"
+}
+
+// Generate creates audio from text using the Edge read aloud service.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	ssmls, err := p.buildSSML(req)
+	if err != nil {
+		return "", err
+	}
+
+	// Lossless output requests raw PCM, which is wrapped in a WAV container
+	lossless := req.Format == "wav"
+	outputFormat := MP3OutputFormat
+	ext := ".mp3"
+	if lossless {
+		outputFormat = PCMOutputFormat
+		ext = ".wav"
+	}
+
+	// MP3 frames and raw PCM both concatenate, so long texts are joined as received
+	var audio bytes.Buffer
+	for _, ssml := range ssmls {
+		if err := p.synthesize(ctx, ssml, outputFormat, &audio); err != nil {
+			return "", err
+		}
+	}
+	if audio.Len() == 0 {
+		return "", fmt.Errorf("no audio received for voice %q (see -list-voices for valid voices)", voiceOrDefault(req.Voice))
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The service returns MP3 (or PCM wrapped as WAV), ensure correct extension
+	outputPath := req.OutputPath
+	if filepath.Ext(outputPath) != ext {
+		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ext
+	}
+
+	if lossless {
+		if err := utils.WritePCMAsWAV(outputPath, &audio, PCMSampleRate); err != nil {
+			return "", err
+		}
+		return outputPath, nil
+	}
+
+	if err := os.WriteFile(outputPath, audio.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+	return outputPath, nil
+}
+
+// PreviewRequest returns the SSML documents that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	ssmls, err := p.buildSSML(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	outputFormat := MP3OutputFormat
+	if req.Format == "wav" {
+		outputFormat = PCMOutputFormat
+	}
+
+	return tts.RequestPreview{
+		Target:  "WSS " + p.synthesisURL,
+		Headers: map[string]string{"Output-Format": outputFormat},
+		Body:    strings.Join(ssmls, "\n"),
+	}, nil
+}
+
+// buildSSML returns the SSML documents of the requests for req, one per text chunk.
+func (p *Provider) buildSSML(req tts.GenerateRequest) ([]string, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	// Timing annotations override the configured rate
+	rate := p.rate
+	if req.TargetDuration != nil && *req.TargetDuration > 0 {
+		rate = rateForDuration(tts.SpeechTarget(req))
+		fmt.Fprintf(os.Stderr, "Target duration: %.1fs, Calculated rate: %s\n", *req.TargetDuration, rate)
+	}
+
+	voice := voiceOrDefault(req.Voice)
+	chunks := utils.SplitText(text, maxChunkBytes)
+	ssmls := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		ssmls = append(ssmls, ssmlDocument(voice, rate, p.pitch, chunk))
+	}
+	return ssmls, nil
+}
+
+// voiceOrDefault returns voice, or DefaultVoice if it is empty
+func voiceOrDefault(voice string) string {
+	if voice == "" {
+		return DefaultVoice
+	}
+	return voice
+}
+
+// ssmlDocument returns the SSML document speaking text with a voice (see
+// tts.SSMLContent)
+func ssmlDocument(voice, rate, pitch, text string) string {
+	// Voice short names start with their locale, e.g., en-US-AriaNeural
+	lang := "en-US"
+	if parts := strings.SplitN(voice, "-", 3); len(parts) == 3 {
+		lang = parts[0] + "-" + parts[1]
+	}
+
+	return fmt.Sprintf("<speak version='1.0' xmlns='http://www.w3.org/2001/10/synthesis' xml:lang='%s'>"+
+		"<voice name='%s'><prosody pitch='%s' rate='%s' volume='+0%%'>%s</prosody></voice></speak>",
+		escapeXML(lang), escapeXML(voice), pitch, rate, tts.SSMLContent(text))
+}
+
+// escapeXML escapes text for use in SSML content and attributes
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// rateForDuration returns the relative rate that speaks text in targetDuration seconds.
+// The rate is clamped to -50%..+100%, the range in which voices stay intelligible.
+func rateForDuration(text string, targetDuration float64) string {
+	const (
+		minSpeed = 0.5
+		maxSpeed = 2.0
+	)
+
+	speed := utils.EstimateDuration(text, NaturalWPM) / targetDuration
+	clamped := utils.ClampFloat64(speed, minSpeed, maxSpeed)
+	if clamped != speed {
+		fmt.Fprintf(os.Stderr, "Warning: Required speed (%.2f) is outside %.1f-%.1f, clamping (audio will not match the target)\n", speed, minSpeed, maxSpeed)
+	}
+	return fmt.Sprintf("%+d%%", int(math.Round((clamped-1)*100)))
+}
+
+// synthesize sends an SSML document over a new connection and writes the
+// audio received until the end of the turn to w.
+func (p *Provider) synthesize(ctx context.Context, ssml, outputFormat string, w io.Writer) error {
+	if p.httpClient.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.httpClient.Timeout)
+		defer cancel()
+	}
+
+	connectionID := newID()
+	query := url.Values{
+		"TrustedClientToken": {TrustedClientToken},
+		"Sec-MS-GEC":         {secMSGEC(time.Now())},
+		"Sec-MS-GEC-Version": {"1-" + chromiumVersion},
+		"ConnectionId":       {connectionID},
+	}
+	conn, err := dial(ctx, p.httpClient, p.synthesisURL+"?"+query.Encode(), requestHeaders())
+	if err != nil {
+		return fmt.Errorf("failed to connect to the Edge TTS service: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Unblock reads when the context is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.rw.Close() })
+	defer stop()
+
+	timestamp := time.Now().UTC().Format("Mon Jan 02 2006 15:04:05 GMT+0000 (Coordinated Universal Time)")
+	speechConfig := fmt.Sprintf("X-Timestamp:%s\r\nContent-Type:application/json; charset=utf-8\r\nPath:speech.config\r\n\r\n"+
+		`{"context":{"synthesis":{"audio":{"metadataoptions":{"sentenceBoundaryEnabled":"false","wordBoundaryEnabled":"false"},"outputFormat":"%s"}}}}`,
+		timestamp, outputFormat)
+	if err := conn.WriteMessage(opText, []byte(speechConfig)); err != nil {
+		return err
+	}
+	request := fmt.Sprintf("X-RequestId:%s\r\nContent-Type:application/ssml+xml\r\nX-Timestamp:%sZ\r\nPath:ssml\r\n\r\n%s",
+		connectionID, timestamp, ssml)
+	if err := conn.WriteMessage(opText, []byte(request)); err != nil {
+		return err
+	}
+
+	for {
+		opcode, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("Edge TTS service stopped before the end of the audio: %w", err)
+		}
+
+		switch opcode {
+		case opText:
+			if messagePath(string(message)) == "turn.end" {
+				return nil
+			}
+		case opBinary:
+			// Binary messages start with the big-endian length of their headers
+			if len(message) < 2 {
+				continue
+			}
+			headerLen := int(binary.BigEndian.Uint16(message))
+			if 2+headerLen > len(message) || messagePath(string(message[2:2+headerLen])) != "audio" {
+				continue
+			}
+			if _, err := w.Write(message[2+headerLen:]); err != nil {
+				return fmt.Errorf("failed to buffer audio data: %w", err)
+			}
+		}
+	}
+}
+
+// messagePath returns the Path header of a service message
+func messagePath(message string) string {
+	headers, _, _ := strings.Cut(message, "\r\n\r\n")
+	for line := range strings.SplitSeq(headers, "\r\n") {
+		if value, ok := strings.CutPrefix(line, "Path:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// requestHeaders returns the headers identifying requests as coming from Edge
+func requestHeaders() http.Header {
+	major, _, _ := strings.Cut(chromiumVersion, ".")
+	return http.Header{
+		"Pragma":        {"no-cache"},
+		"Cache-Control": {"no-cache"},
+		"Origin":        {"chrome-extension://jdiccldimpdaibmpdkjnbmckianbfold"},
+		"User-Agent": {fmt.Sprintf("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) "+
+			"Chrome/%s.0.0.0 Safari/537.36 Edg/%s.0.0.0", major, major)},
+	}
+}
+
+// secMSGEC returns the Sec-MS-GEC token for a time: the SHA-256 of the
+// Windows file time, rounded down to 5 minutes, followed by the client token.
+func secMSGEC(now time.Time) string {
+	const windowsEpochOffset = 11644473600 // Seconds from 1601 to 1970
+
+	seconds := now.Unix() + windowsEpochOffset
+	seconds -= seconds % 300
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d%s", seconds*10_000_000, TrustedClientToken))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// newID returns a random 32-character hex identifier
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// voiceInfo is a voice in the service's voice list
+type voiceInfo struct {
+	ShortName    string `json:"ShortName"`
+	FriendlyName string `json:"FriendlyName"`
+	Gender       string `json:"Gender"`
+	Locale       string `json:"Locale"`
+	VoiceTag     struct {
+		ContentCategories  []string `json:"ContentCategories"`
+		VoicePersonalities []string `json:"VoicePersonalities"`
+	} `json:"VoiceTag"`
+}
+
+// ListVoices retrieves the available voices from the read aloud service.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	query := url.Values{
+		"trustedclienttoken": {TrustedClientToken},
+		"Sec-MS-GEC":         {secMSGEC(time.Now())},
+		"Sec-MS-GEC-Version": {"1-" + chromiumVersion},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.voicesURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range requestHeaders() {
+		httpReq.Header[name] = values
+	}
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("voice list request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var infos []voiceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&infos); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	voices := make([]tts.Voice, 0, len(infos))
+	for _, info := range infos {
+		description := strings.Join(info.VoiceTag.VoicePersonalities, ", ")
+		if description == "" {
+			description = info.FriendlyName
+		}
+		voices = append(voices, tts.Voice{
+			ID:          info.ShortName,
+			Name:        info.ShortName,
+			Description: description,
+			Language:    info.Locale,
+			Gender:      strings.ToLower(info.Gender),
+		})
+	}
+	return voices, nil
+}
@@ -0,0 +1,267 @@
+package edge
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// newTestServer returns a fake read aloud service answering each SSML request
+// with audio split over two binary messages, and recording the requests.
+func newTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("TrustedClientToken") != TrustedClientToken {
+			http.Error(w, "missing token", http.StatusForbidden)
+			return
+		}
+
+		netConn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		conn := newConn(netConn, bufio.NewReader(rw), false)
+		defer func() { _ = conn.Close() }()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messagePath(string(message)) != "ssml" {
+				continue
+			}
+			*requests = append(*requests, string(message))
+
+			_ = conn.WriteMessage(opText, []byte("X-RequestId:1\r\nPath:turn.start\r\n\r\n{}"))
+			for _, chunk := range []string{"AUDIO", "DATA"} {
+				_ = conn.WriteMessage(opBinary, audioMessage(chunk))
+			}
+			_ = conn.WriteMessage(opText, []byte("X-RequestId:1\r\nPath:turn.end\r\n\r\n{}"))
+		}
+	}))
+}
+
+// audioMessage returns a binary service message carrying data
+func audioMessage(data string) []byte {
+	headers := "X-RequestId:1\r\nContent-Type:audio/mpeg\r\nPath:audio\r\n"
+	message := binary.BigEndian.AppendUint16(nil, uint16(len(headers)))
+	return append(append(message, headers...), data...)
+}
+
+func TestGenerate(t *testing.T) {
+	var requests []string
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	provider, err := NewProvider(Config{SynthesisURL: "ws" + strings.TrimPrefix(server.URL, "http"), Rate: "+10%"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello <world> & friends",
+		Voice:      "en-GB-SoniaNeural",
+		OutputPath: filepath.Join(t.TempDir(), "out", "section.aiff"),
+		Format:     "mp3",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".mp3" {
+		t.Errorf("output path = %s, want .mp3 extension", outputPath)
+	}
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "AUDIODATA" {
+		t.Errorf("audio = %q, want %q", data, "AUDIODATA")
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	for _, want := range []string{"xml:lang='en-GB'", "<voice name='en-GB-SoniaNeural'>", "rate='+10%'", "Hello &lt;world&gt; &amp; friends"} {
+		if !strings.Contains(requests[0], want) {
+			t.Errorf("request %q does not contain %q", requests[0], want)
+		}
+	}
+}
+
+func TestGenerateWAV(t *testing.T) {
+	var requests []string
+	server := newTestServer(t, &requests)
+	defer server.Close()
+
+	provider, err := NewProvider(Config{SynthesisURL: "ws" + strings.TrimPrefix(server.URL, "http")})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       strings.Repeat("word ", maxChunkBytes/5+10),
+		OutputPath: filepath.Join(t.TempDir(), "section.wav"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("got %d requests, want the text split into 2", len(requests))
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "RIFF") || !strings.HasSuffix(string(data), "AUDIODATAAUDIODATA") {
+		t.Errorf("expected a WAV file of both chunks, got %q", data)
+	}
+}
+
+func TestGenerateRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{SynthesisURL: "ws" + strings.TrimPrefix(server.URL, "http")})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	_, err = provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.mp3")})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Generate() error = %v, want the refused status", err)
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("trustedclienttoken") != TrustedClientToken {
+			http.Error(w, "missing token", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"ShortName":"en-US-AriaNeural","FriendlyName":"Microsoft Aria Online","Gender":"Female","Locale":"en-US",
+			"VoiceTag":{"VoicePersonalities":["Positive","Confident"]}},
+			{"ShortName":"it-IT-DiegoNeural","FriendlyName":"Microsoft Diego Online","Gender":"Male","Locale":"it-IT"}]`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{VoicesURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	want := []tts.Voice{
+		{ID: "en-US-AriaNeural", Name: "en-US-AriaNeural", Description: "Positive, Confident", Language: "en-US", Gender: "female"},
+		{ID: "it-IT-DiegoNeural", Name: "it-IT-DiegoNeural", Description: "Microsoft Diego Online", Language: "it-IT", Gender: "male"},
+	}
+	if len(voices) != len(want) {
+		t.Fatalf("got %d voices, want %d", len(voices), len(want))
+	}
+	for i := range want {
+		if voices[i] != want[i] {
+			t.Errorf("voice %d = %+v, want %+v", i, voices[i], want[i])
+		}
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{Pitch: "-5Hz"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	target := 2.0
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "One two three four five six seven eight", TargetDuration: &target, Format: "wav"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "WSS "+SynthesisURL || preview.Headers["Output-Format"] != PCMOutputFormat {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+	for _, want := range []string{"<voice name='" + DefaultVoice + "'>", "pitch='-5Hz'", "rate='+50%'"} {
+		if !strings.Contains(preview.Body, want) {
+			t.Errorf("body %q does not contain %q", preview.Body, want)
+		}
+	}
+
+	// Pauses become breaks and do not count against the target duration
+	target = 3.0
+	preview, err = provider.PreviewRequest(tts.GenerateRequest{Text: "One two three four [pause 1s] five six seven eight", TargetDuration: &target})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	for _, want := range []string{`four <break time="1000ms"/> five`, "rate='+50%'"} {
+		if !strings.Contains(preview.Body, want) {
+			t.Errorf("body %q does not contain %q", preview.Body, want)
+		}
+	}
+}
+
+func TestNewProviderValidation(t *testing.T) {
+	tests := []Config{
+		{Rate: "10%"},
+		{Rate: "+10"},
+		{Pitch: "+5"},
+		{Pitch: "high"},
+	}
+	for _, cfg := range tests {
+		if _, err := NewProvider(cfg); err == nil {
+			t.Errorf("NewProvider(%+v) expected an error", cfg)
+		}
+	}
+	if _, err := NewProvider(Config{Rate: "-20%", Pitch: "+10%"}); err != nil {
+		t.Errorf("NewProvider() error = %v", err)
+	}
+}
+
+func TestRateForDuration(t *testing.T) {
+	// 16 words at 160 wpm take 6 seconds
+	text := strings.Repeat("word ", 16)
+	tests := []struct {
+		target float64
+		want   string
+	}{
+		{6, "+0%"},
+		{4, "+50%"},
+		{12, "-50%"},
+		{60, "-50%"},
+		{1, "+100%"},
+	}
+	for _, tt := range tests {
+		if got := rateForDuration(text, tt.target); got != tt.want {
+			t.Errorf("rateForDuration(%v) = %s, want %s", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestSecMSGEC(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	token := secMSGEC(start)
+	if len(token) != 64 || strings.ToUpper(token) != token {
+		t.Errorf("token = %q, want 64 uppercase hex characters", token)
+	}
+	if secMSGEC(start.Add(4*time.Minute)) != token {
+		t.Error("token changed within the same 5-minute window")
+	}
+	if secMSGEC(start.Add(5*time.Minute)) == token {
+		t.Error("token did not change in the next 5-minute window")
+	}
+}
@@ -0,0 +1,241 @@
+package edge
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WebSocket opcodes used by the Edge TTS service
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// websocketGUID is appended to the handshake key to compute the accept key (RFC 6455)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxMessageSize bounds the messages read from the service
+const maxMessageSize = 16 << 20
+
+// errClosed reports a connection closed by the server
+var errClosed = errors.New("connection closed by the server")
+
+// wsConn is a minimal WebSocket connection, sufficient for the request and
+// response exchange of the Edge TTS service.
+type wsConn struct {
+	rw     io.ReadWriteCloser
+	br     *bufio.Reader
+	client bool // Clients mask the frames they send
+}
+
+// dial opens a WebSocket connection to url (ws:// or wss://) through client,
+// so proxies, CA bundles, and extra headers configured for it apply. The
+// client timeout is not applied, as it would hide the upgraded connection;
+// bound the connection with ctx instead.
+func dial(ctx context.Context, client *http.Client, url string, header http.Header) (*wsConn, error) {
+	httpURL := url
+	switch {
+	case strings.HasPrefix(url, "wss://"):
+		httpURL = "https://" + strings.TrimPrefix(url, "wss://")
+	case strings.HasPrefix(url, "ws://"):
+		httpURL = "http://" + strings.TrimPrefix(url, "ws://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	upgradeClient := *client
+	upgradeClient.Timeout = 0
+	resp, err := upgradeClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("connection refused with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	rw, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("connection upgrade not supported by the HTTP client")
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = rw.Close()
+		return nil, fmt.Errorf("invalid WebSocket handshake")
+	}
+
+	return newConn(rw, bufio.NewReader(rw), true), nil
+}
+
+// newConn wraps an upgraded connection
+func newConn(rw io.ReadWriteCloser, br *bufio.Reader, client bool) *wsConn {
+	return &wsConn{rw: rw, br: br, client: client}
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value for a handshake key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Close sends a close frame and closes the connection.
+func (c *wsConn) Close() error {
+	_ = c.writeFrame(opClose, nil)
+	return c.rw.Close()
+}
+
+// WriteMessage sends a single-frame message.
+func (c *wsConn) WriteMessage(opcode byte, payload []byte) error {
+	return c.writeFrame(opcode, payload)
+}
+
+// writeFrame writes a final frame, masked when sent by a client
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = maskBit | byte(n)
+	case n <= 0xFFFF:
+		header[1] = maskBit | 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = maskBit | 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	data := payload
+	if c.client {
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		header = append(header, mask...)
+		data = make([]byte, len(payload))
+		for i, b := range payload {
+			data[i] = b ^ mask[i%4]
+		}
+	}
+
+	if _, err := c.rw.Write(append(header, data...)); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// ReadMessage returns the next text or binary message, answering pings and
+// joining fragmented frames. It returns errClosed when the server closes
+// the connection.
+func (c *wsConn) ReadMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, errClosed
+		case opContinuation:
+		default:
+			opcode = op
+		}
+
+		message = append(message, payload...)
+		if len(message) > maxMessageSize {
+			return 0, nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+		}
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// readFrame reads a single frame, unmasking its payload
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	fin := head[0]&0x80 != 0
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+
+	size := uint64(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > maxMessageSize {
+		return false, 0, nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, fmt.Errorf("failed to read message: %w", err)
+		}
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
@@ -1,20 +1,26 @@
 package elevenlabs
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/cenkalti/backoff/v5"
-
 	"github.com/indaco/md2audio/internal/env"
+	"github.com/indaco/md2audio/internal/httpclient"
 	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/text"
 	"github.com/indaco/md2audio/internal/tts"
 	"github.com/indaco/md2audio/internal/utils"
 )
@@ -31,6 +37,16 @@ const (
 
 	// EnvVarAPIKey is the environment variable name for the API key
 	EnvVarAPIKey = "ELEVENLABS_API_KEY"
+
+	// PCMOutputFormat is the output format requested for lossless (WAV) generation.
+	// Raw 44.1kHz PCM requires a Pro tier subscription or above.
+	PCMOutputFormat = "pcm_44100"
+
+	// PCMSampleRate is the sample rate of PCMOutputFormat
+	PCMSampleRate = 44100
+
+	// maxBreak is the longest pause of a single break tag
+	maxBreak = 3 * time.Second
 )
 
 // Client implements the TTS Provider interface for ElevenLabs API.
@@ -39,7 +55,16 @@ type Client struct {
 	textToSpeechBaseURL string // Base URL for text-to-speech operations (v1)
 	voicesBaseURL       string // Base URL for voices operations (v2)
 	httpClient          *http.Client
+	retry               httpclient.RetryPolicy // Retries of failed requests
+	model               string                 // Model of requests without a model ID
 	log                 logger.LoggerInterface // Optional logger for debug output
+	stream              bool                   // Use the streaming text-to-speech endpoint
+	seed                uint32                 // Sampling seed of every request (0 = none)
+	deterministic       bool                   // Seed requests without a seed from their text
+
+	// Request IDs of generated texts, sent with the requests that follow them
+	mu         sync.Mutex
+	requestIDs map[string]string
 
 	// Default voice settings
 	stability       float64
@@ -55,6 +80,11 @@ type Config struct {
 	TextToSpeechBaseURL string // Base URL for text-to-speech operations (defaults to v1)
 	VoicesBaseURL       string // Base URL for voices operations (defaults to v2)
 	HTTPClient          *http.Client
+	Retry               httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+	Model               string                 // Model of requests without a model ID (default: DefaultModel)
+	Stream              bool                   // Use the streaming endpoint, which sends audio as it is generated
+	Seed                uint32                 // Sampling seed sent with every request, for repeatable audio (0 = none)
+	Deterministic       bool                   // Seed every request (with Seed, or one derived from the text) and omit run-specific request IDs
 
 	// Voice Settings (optional, with defaults)
 	Stability       float64 // Voice consistency (0.0-1.0, default: 0.5)
@@ -101,6 +131,11 @@ func NewClient(cfg Config) (*Client, error) {
 		}
 	}
 
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
 	// Set voice settings with defaults if not provided
 	stability := cfg.Stability
 	if stability == 0 {
@@ -129,6 +164,11 @@ func NewClient(cfg Config) (*Client, error) {
 		textToSpeechBaseURL: textToSpeechBaseURL,
 		voicesBaseURL:       voicesBaseURL,
 		httpClient:          httpClient,
+		retry:               retry,
+		model:               cfg.Model,
+		stream:              cfg.Stream,
+		seed:                cfg.Seed,
+		deterministic:       cfg.Deterministic,
 		stability:           stability,
 		similarityBoost:     similarityBoost,
 		style:               style,
@@ -147,127 +187,231 @@ func (c *Client) SetLogger(log logger.LoggerInterface) {
 	c.log = log
 }
 
-// retryableHTTPRequest executes an HTTP request with exponential backoff retry logic.
-// Retries on network errors and 429/500/502/503 status codes.
+// retryableHTTPRequest executes an HTTP request, retrying network errors and
+// the status codes of the client's retry policy with exponential backoff,
+// waiting as long as a Retry-After header asks instead when there is one.
 func (c *Client) retryableHTTPRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
-	var resp *http.Response
-	var lastErr error
-
-	operation := func() error {
-		// Clone the request for retry (in case body needs to be re-read)
-		reqClone := req.Clone(ctx)
-
-		var err error
-		resp, err = c.httpClient.Do(reqClone)
-		if err != nil {
-			// Network error - retry
-			lastErr = err
-			return err
-		}
+	return httpclient.Do(ctx, c.httpClient, req, c.retry)
+}
 
-		// Check if we should retry based on status code
-		if shouldRetry(resp.StatusCode) {
-			body, _ := io.ReadAll(resp.Body)
-			_ = resp.Body.Close()
-			lastErr = fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-			return lastErr
-		}
+// SupportsAudioTags reports whether a model performs expressive audio tags
+// such as [whispers] or [laughs] instead of reading them aloud.
+func SupportsAudioTags(model string) bool {
+	return strings.HasPrefix(model, "eleven_v3")
+}
 
-		// Success or non-retryable error
-		return nil
+// classifyFailure returns the error for a failed text-to-speech request,
+// marking rejected credentials and unknown voices as hard failures
+func classifyFailure(statusCode int, body []byte) error {
+	err := fmt.Errorf("API request failed with status %d: %s", statusCode, string(body))
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return &tts.HardFailure{Scope: tts.ScopeAuth, Err: err}
+	case statusCode == http.StatusNotFound || bytes.Contains(body, []byte("voice_not_found")):
+		return &tts.HardFailure{Scope: tts.ScopeVoice, Err: err}
+	default:
+		return err
 	}
+}
 
-	// Configure exponential backoff: 3 retries
-	expBackoff := backoff.NewExponentialBackOff()
-	expBackoff.InitialInterval = 1 * time.Second
-	expBackoff.MaxInterval = 10 * time.Second
-	expBackoff.Reset()
-
-	// Wrap with max retries and context awareness
-	retryCount := 0
-	maxRetries := 3
-
-	for retryCount < maxRetries {
-		err := operation()
-		if err == nil {
-			return resp, nil
-		}
-
-		retryCount++
-		if retryCount >= maxRetries {
-			return nil, lastErr
-		}
+// audioSniffLen is the number of leading response bytes checked by validateAudio
+const audioSniffLen = 512
 
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
-		// Wait with exponential backoff
-		wait := expBackoff.NextBackOff()
-		if wait == backoff.Stop {
-			return nil, lastErr
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(wait):
-			// Continue to next retry
+// validateAudio checks that a text-to-speech response holds audio, given its
+// Content-Type header and leading bytes. MP3 responses must start with an
+// ID3 tag or an MPEG frame sync; raw PCM has no signature to check.
+func validateAudio(contentType string, head []byte, lossless bool) error {
+	if len(head) == 0 {
+		return fmt.Errorf("API returned an empty audio response")
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "application/json" || strings.HasPrefix(mediaType, "text/") {
+			return fmt.Errorf("API returned %s instead of audio: %s", mediaType, truncate(head, 200))
 		}
 	}
-
-	return nil, lastErr
+	if lossless {
+		return nil
+	}
+	if bytes.HasPrefix(head, []byte("ID3")) || (len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0) {
+		return nil
+	}
+	return fmt.Errorf("API response is not MP3 audio: %s", truncate(head, 200))
 }
 
-// shouldRetry returns true if the HTTP status code indicates a retryable error.
-func shouldRetry(statusCode int) bool {
-	return statusCode == 429 || // Too Many Requests
-		statusCode == 500 || // Internal Server Error
-		statusCode == 502 || // Bad Gateway
-		statusCode == 503 // Service Unavailable
+// truncate returns at most n bytes of data as a string, marking truncation
+func truncate(data []byte, n int) string {
+	if len(data) <= n {
+		return string(data)
+	}
+	return string(data[:n]) + "..."
 }
 
-// Generate creates audio from text using the ElevenLabs API.
-func (c *Client) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+// buildTTSRequest returns the URL, JSON body and model of the text-to-speech request for req.
+func (c *Client) buildTTSRequest(req tts.GenerateRequest) (string, []byte, string, error) {
 	// Determine model
-	modelID := DefaultModel
+	modelID := c.model
 	if req.ModelID != nil && *req.ModelID != "" {
 		modelID = *req.ModelID
 	}
+	if modelID == "" {
+		modelID = DefaultModel
+	}
 
 	// Prepare voice settings from client defaults
 	voiceSettings := c.prepareVoiceSettings(req)
 
 	// Prepare request body
 	reqBody := TTSRequest{
-		Text:          req.Text,
+		Text:          breakTags(req.Text),
 		ModelID:       modelID,
 		VoiceSettings: voiceSettings,
+		PreviousText:  req.PreviousText,
+		NextText:      req.NextText,
+		Seed:          c.requestSeed(req.Text),
+	}
+	// Request IDs differ between runs, so deterministic requests rely on the text alone
+	if id := c.requestID(req.PreviousText); id != "" && !c.deterministic {
+		reqBody.PreviousRequestIDs = []string{id}
 	}
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
 	url := fmt.Sprintf("%s/text-to-speech/%s", c.textToSpeechBaseURL, req.Voice)
+	if c.stream {
+		// Same request and audio, sent in chunks as soon as they are generated
+		url += "/stream"
+	}
+	if req.Format == "wav" {
+		url += "?output_format=" + PCMOutputFormat
+	}
+
+	return url, bodyBytes, modelID, nil
+}
+
+// breakTags replaces the pause directives of text ("[pause 1.5s]") with
+// break tags. A break lasts at most maxBreak, so longer pauses take several.
+func breakTags(s string) string {
+	return text.ReplacePauses(s, func(pause time.Duration) string {
+		var tags []string
+		for ; pause > 0; pause -= maxBreak {
+			tags = append(tags, fmt.Sprintf(`<break time="%ss" />`, strconv.FormatFloat(min(pause, maxBreak).Seconds(), 'f', -1, 64)))
+		}
+		return strings.Join(tags, " ")
+	})
+}
+
+// requestSeed returns the sampling seed of the request for text: the
+// configured seed, or in deterministic mode without one, a seed derived from
+// the text, so each unchanged section is generated the same way in every run.
+func (c *Client) requestSeed(text string) uint32 {
+	if c.seed != 0 || !c.deterministic {
+		return c.seed
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(text))
+	return max(h.Sum32(), 1)
+}
+
+// requestID returns the request ID of the audio generated for text, or an
+// empty string if it was not generated by this client
+func (c *Client) requestID(text string) string {
+	if text == "" {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requestIDs[text]
+}
+
+// rememberRequest records the request ID of the audio generated for text, so
+// the request for the text that follows can be stitched to it
+func (c *Client) rememberRequest(text, id string) {
+	if id == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.requestIDs == nil {
+		c.requestIDs = make(map[string]string)
+	}
+	c.requestIDs[text] = id
+}
+
+// ttsHeaders returns the headers of a text-to-speech request.
+func (c *Client) ttsHeaders(lossless bool) map[string]string {
+	headers := map[string]string{
+		"xi-api-key":   c.apiKey,
+		"Content-Type": "application/json",
+		"Accept":       "audio/mpeg",
+	}
+	if lossless {
+		headers["Accept"] = "audio/pcm"
+	}
+	return headers
+}
+
+// PreviewRequest returns the API request that Generate would send for req.
+// The API key is masked.
+func (c *Client) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	url, bodyBytes, _, err := c.buildTTSRequest(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	var body bytes.Buffer
+	if err := json.Indent(&body, bodyBytes, "", "  "); err != nil {
+		return tts.RequestPreview{}, fmt.Errorf("failed to format request: %w", err)
+	}
+
+	headers := c.ttsHeaders(req.Format == "wav")
+	headers["xi-api-key"] = maskAPIKey(c.apiKey)
+
+	return tts.RequestPreview{
+		Target:  http.MethodPost + " " + url,
+		Headers: headers,
+		Body:    body.String(),
+	}, nil
+}
+
+// maskAPIKey masks all but the last 4 characters of an API key.
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// Generate creates audio from text using the ElevenLabs API.
+func (c *Client) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	url, bodyBytes, modelID, err := c.buildTTSRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	// Lossless output requests raw PCM, which is wrapped in a WAV container
+	lossless := req.Format == "wav"
+
+	// Create HTTP request
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
-	httpReq.Header.Set("xi-api-key", c.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "audio/mpeg")
+	for key, value := range c.ttsHeaders(lossless) {
+		httpReq.Header.Set(key, value)
+	}
 
 	// Log API request
 	if c.log != nil {
-		c.log.Debug(fmt.Sprintf("ElevenLabs API: POST /text-to-speech/%s (model: %s)", req.Voice, modelID))
+		endpoint := "/text-to-speech/" + req.Voice
+		if c.stream {
+			endpoint += "/stream"
+		}
+		c.log.Debug(fmt.Sprintf("ElevenLabs API: POST %s (model: %s)", endpoint, modelID))
 	}
 
 	// Execute request with retry logic
@@ -280,7 +424,14 @@ func (c *Client) Generate(ctx context.Context, req tts.GenerateRequest) (string,
 	// Check response status (non-retryable errors)
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return "", classifyFailure(resp.StatusCode, body)
+	}
+
+	// Refuse error bodies sent in place of audio before writing anything
+	body := bufio.NewReader(resp.Body)
+	head, _ := body.Peek(audioSniffLen)
+	if err := validateAudio(resp.Header.Get("Content-Type"), head, lossless); err != nil {
+		return "", err
 	}
 
 	// Ensure output directory exists
@@ -291,9 +442,21 @@ func (c *Client) Generate(ctx context.Context, req tts.GenerateRequest) (string,
 
 	// Determine output path with correct extension
 	outputPath := req.OutputPath
-	// ElevenLabs returns MP3, ensure correct extension
-	if filepath.Ext(outputPath) != ".mp3" {
-		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ".mp3"
+	ext := ".mp3"
+	if lossless {
+		ext = ".wav"
+	}
+	// ElevenLabs returns MP3 (or PCM wrapped as WAV), ensure correct extension
+	if filepath.Ext(outputPath) != ext {
+		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ext
+	}
+
+	if lossless {
+		if err := utils.WritePCMAsWAV(outputPath, body, PCMSampleRate); err != nil {
+			return "", err
+		}
+		c.rememberRequest(req.Text, resp.Header.Get("request-id"))
+		return outputPath, nil
 	}
 
 	// Create output file
@@ -303,11 +466,15 @@ func (c *Client) Generate(ctx context.Context, req tts.GenerateRequest) (string,
 	}
 	defer func() { _ = outFile.Close() }()
 
-	// Copy audio data to file
-	if _, err := io.Copy(outFile, resp.Body); err != nil {
+	// Copy audio data to file as it arrives, without buffering the response
+	if _, err := io.Copy(outFile, body); err != nil {
+		// A stream interrupted midway leaves truncated audio
+		_ = outFile.Close()
+		_ = os.Remove(outputPath)
 		return "", fmt.Errorf("failed to write audio data: %w", err)
 	}
 
+	c.rememberRequest(req.Text, resp.Header.Get("request-id"))
 	return outputPath, nil
 }
 
@@ -360,11 +527,153 @@ func (c *Client) ListVoices(ctx context.Context) ([]tts.Voice, error) {
 	return voices, nil
 }
 
+// ListModels retrieves the text-to-speech models available from ElevenLabs API.
+func (c *Client) ListModels(ctx context.Context) ([]tts.Model, error) {
+	url := fmt.Sprintf("%s/models", c.textToSpeechBaseURL)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("xi-api-key", c.apiKey)
+
+	// Log API request
+	if c.log != nil {
+		c.log.Debug("ElevenLabs API: GET /models")
+	}
+
+	// Execute request with retry logic
+	resp, err := c.retryableHTTPRequest(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Check response status (non-retryable errors)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var modelsResp []ModelInfo
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Convert to tts.Model, leaving out speech-to-speech and other models
+	models := make([]tts.Model, 0, len(modelsResp))
+	for _, m := range modelsResp {
+		if !m.CanDoTextToSpeech {
+			continue
+		}
+		languages := make([]string, len(m.Languages))
+		for i, language := range m.Languages {
+			languages[i] = language.LanguageID
+		}
+		maxCharacters := m.MaximumTextLengthPerRequest
+		if maxCharacters == 0 {
+			maxCharacters = m.MaxCharactersRequestSubscribedUser
+		}
+		models = append(models, tts.Model{
+			ID:            m.ModelID,
+			Name:          m.Name,
+			Description:   m.Description,
+			Languages:     languages,
+			MaxCharacters: maxCharacters,
+		})
+	}
+
+	return models, nil
+}
+
+// Subscription retrieves the character quota of the account from ElevenLabs API.
+func (c *Client) Subscription(ctx context.Context) (*Subscription, error) {
+	url := fmt.Sprintf("%s/user/subscription", c.textToSpeechBaseURL)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("xi-api-key", c.apiKey)
+
+	// Log API request
+	if c.log != nil {
+		c.log.Debug("ElevenLabs API: GET /user/subscription")
+	}
+
+	// Execute request with retry logic
+	resp, err := c.retryableHTTPRequest(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Check response status (non-retryable errors)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var subscription Subscription
+	if err := json.NewDecoder(resp.Body).Decode(&subscription); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &subscription, nil
+}
+
+// Subscription represents the response from the user subscription API.
+type Subscription struct {
+	Tier               string `json:"tier"`
+	CharacterCount     int    `json:"character_count"`                 // Characters used in the current period
+	CharacterLimit     int    `json:"character_limit"`                 // Characters available per period
+	NextCharacterReset int64  `json:"next_character_count_reset_unix"` // Unix time the character count resets
+}
+
+// Remaining returns the characters left in the current period.
+func (s *Subscription) Remaining() int {
+	return max(s.CharacterLimit-s.CharacterCount, 0)
+}
+
+// ResetsAt returns when the character count resets, or the zero time if unknown.
+func (s *Subscription) ResetsAt() time.Time {
+	if s.NextCharacterReset == 0 {
+		return time.Time{}
+	}
+	return time.Unix(s.NextCharacterReset, 0)
+}
+
 // TTSRequest represents the request body for text-to-speech API.
 type TTSRequest struct {
 	Text          string         `json:"text"`
 	ModelID       string         `json:"model_id"`
 	VoiceSettings *VoiceSettings `json:"voice_settings,omitempty"`
+
+	// Context for continuous prosody across requests
+	PreviousText       string   `json:"previous_text,omitempty"`
+	NextText           string   `json:"next_text,omitempty"`
+	PreviousRequestIDs []string `json:"previous_request_ids,omitempty"`
+
+	// Seed makes the sampling repeatable for the same request (0 = random)
+	Seed uint32 `json:"seed,omitempty"`
+}
+
+// ModelInfo contains information about a model from the models API.
+type ModelInfo struct {
+	ModelID                            string          `json:"model_id"`
+	Name                               string          `json:"name"`
+	Description                        string          `json:"description"`
+	CanDoTextToSpeech                  bool            `json:"can_do_text_to_speech"`
+	Languages                          []ModelLanguage `json:"languages"`
+	MaxCharactersRequestSubscribedUser int             `json:"max_characters_request_subscribed_user"`
+	MaximumTextLengthPerRequest        int             `json:"maximum_text_length_per_request"`
+}
+
+// ModelLanguage is a language spoken by a model.
+type ModelLanguage struct {
+	LanguageID string `json:"language_id"`
+	Name       string `json:"name"`
 }
 
 // VoiceSettings contains voice configuration parameters.
@@ -414,8 +723,8 @@ func (c *Client) prepareVoiceSettings(req tts.GenerateRequest) *VoiceSettings {
 
 	// Speed handling: timing annotation overrides default speed
 	if req.TargetDuration != nil && *req.TargetDuration > 0 {
-		// Calculate speed to match target duration
-		speed := calculateSpeed(req.Text, *req.TargetDuration)
+		// Calculate speed to match target duration, less the pauses
+		speed := calculateSpeed(tts.SpeechTarget(req))
 		settings.Speed = &speed
 		// Note: Using stderr for progress messages to avoid polluting stdout
 		// TODO: Consider passing logger via context or provider interface for better integration
@@ -2,6 +2,8 @@ package elevenlabs
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/indaco/md2audio/internal/tts"
 )
@@ -144,7 +147,7 @@ func TestClient_Generate(t *testing.T) {
 				OutputPath: "/tmp/test.mp3",
 			},
 			serverStatus: http.StatusOK,
-			serverBody:   "fake-audio-data",
+			serverBody:   "ID3fake-audio-data",
 			expectError:  false,
 		},
 		{
@@ -156,7 +159,7 @@ func TestClient_Generate(t *testing.T) {
 				ModelID:    stringPtr("eleven_multilingual_v2"),
 			},
 			serverStatus: http.StatusOK,
-			serverBody:   "fake-audio-data",
+			serverBody:   "ID3fake-audio-data",
 			expectError:  false,
 		},
 		{
@@ -265,6 +268,96 @@ func TestClient_Generate(t *testing.T) {
 	}
 }
 
+func TestClient_GenerateRejectsNonAudio(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		errorMsg    string
+	}{
+		{name: "JSON error body", contentType: "application/json", body: `{"detail":{"status":"quota_exceeded"}}`, errorMsg: "application/json instead of audio"},
+		{name: "HTML error page", contentType: "text/html; charset=utf-8", body: "<html>Bad Gateway</html>", errorMsg: "text/html instead of audio"},
+		{name: "not MP3", contentType: "audio/mpeg", body: "not audio", errorMsg: "not MP3 audio"},
+		{name: "empty body", contentType: "audio/mpeg", body: "", errorMsg: "empty audio response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusOK)
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := &Client{
+				apiKey:              "test-api-key",
+				textToSpeechBaseURL: server.URL,
+				httpClient:          server.Client(),
+			}
+
+			outputPath := filepath.Join(t.TempDir(), "test.mp3")
+			_, err := client.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", Voice: "voice-123", OutputPath: outputPath})
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.errorMsg)
+			}
+			if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+				t.Error("No audio file should be written for a non-audio response")
+			}
+		})
+	}
+}
+
+func TestValidateAudio(t *testing.T) {
+	frame := []byte{0xFF, 0xFB, 0x90, 0x64}
+	if err := validateAudio("audio/mpeg", frame, false); err != nil {
+		t.Errorf("validateAudio() error = %v for an MPEG frame", err)
+	}
+	if err := validateAudio("", []byte("ID3\x04"), false); err != nil {
+		t.Errorf("validateAudio() error = %v for an ID3 tag", err)
+	}
+	if err := validateAudio("audio/pcm", []byte{0x7B, 0x00}, true); err != nil {
+		t.Errorf("validateAudio() error = %v for raw PCM", err)
+	}
+	if err := validateAudio("application/json", []byte(`{"detail":"error"}`), true); err == nil {
+		t.Error("validateAudio() should reject JSON in place of PCM")
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantScope string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"detail":"Invalid API key"}`, wantScope: tts.ScopeAuth},
+		{name: "forbidden", status: http.StatusForbidden, body: `{}`, wantScope: tts.ScopeAuth},
+		{name: "voice not found", status: http.StatusNotFound, body: `{}`, wantScope: tts.ScopeVoice},
+		{name: "voice not found in body", status: http.StatusBadRequest, body: `{"detail":{"status":"voice_not_found"}}`, wantScope: tts.ScopeVoice},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"detail":"text too long"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyFailure(tt.status, []byte(tt.body))
+			if !strings.Contains(err.Error(), fmt.Sprint(tt.status)) {
+				t.Errorf("Error %q does not mention status %d", err, tt.status)
+			}
+			var hard *tts.HardFailure
+			if !errors.As(err, &hard) {
+				if tt.wantScope != "" {
+					t.Errorf("Expected a hard failure with scope %q, got %v", tt.wantScope, err)
+				}
+				return
+			}
+			if hard.Scope != tt.wantScope {
+				t.Errorf("Scope = %q, want %q", hard.Scope, tt.wantScope)
+			}
+		})
+	}
+}
+
 func TestClient_ListVoices(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -383,11 +476,92 @@ func TestClient_ListVoices(t *testing.T) {
 	}
 }
 
+func TestClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("xi-api-key") != "test-api-key" {
+			http.Error(w, `{"detail":"Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `[
+			{"model_id": "eleven_multilingual_v2", "name": "Eleven Multilingual v2", "can_do_text_to_speech": true,
+			 "languages": [{"language_id": "en", "name": "English"}, {"language_id": "de", "name": "German"}],
+			 "max_characters_request_subscribed_user": 10000, "maximum_text_length_per_request": 10000},
+			{"model_id": "eleven_turbo_v2", "name": "Eleven Turbo v2", "can_do_text_to_speech": true,
+			 "languages": [{"language_id": "en", "name": "English"}], "max_characters_request_subscribed_user": 30000},
+			{"model_id": "eleven_english_sts_v2", "name": "Eleven English v2", "can_do_text_to_speech": false}
+		]`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:              "test-api-key",
+		textToSpeechBaseURL: server.URL,
+		httpClient:          server.Client(),
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	// Speech-to-speech models are left out
+	if len(models) != 2 {
+		t.Fatalf("ListModels() = %+v, want 2 text-to-speech models", models)
+	}
+	if m := models[0]; m.ID != "eleven_multilingual_v2" || strings.Join(m.Languages, ",") != "en,de" || m.MaxCharacters != 10000 {
+		t.Errorf("first model = %+v", m)
+	}
+	if m := models[1]; m.MaxCharacters != 30000 {
+		t.Errorf("second model = %+v, want the subscriber limit without a per-request maximum", m)
+	}
+
+	client.apiKey = "wrong"
+	if _, err := client.ListModels(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("ListModels() error = %v, want a 401 error", err)
+	}
+}
+
+func TestClient_Subscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user/subscription" || r.Header.Get("xi-api-key") != "test-api-key" {
+			http.Error(w, `{"detail":"Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"tier":"starter","character_count":27000,"character_limit":30000,"next_character_count_reset_unix":1767225600}`)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:              "test-api-key",
+		textToSpeechBaseURL: server.URL,
+		httpClient:          server.Client(),
+	}
+	subscription, err := client.Subscription(context.Background())
+	if err != nil {
+		t.Fatalf("Subscription() error = %v", err)
+	}
+	if subscription.Tier != "starter" || subscription.Remaining() != 3000 {
+		t.Errorf("subscription = %+v, want starter with 3000 characters left", subscription)
+	}
+	if !subscription.ResetsAt().Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("ResetsAt() = %v", subscription.ResetsAt())
+	}
+	if (&Subscription{CharacterCount: 12, CharacterLimit: 10}).Remaining() != 0 {
+		t.Error("Remaining() should not be negative when the quota is exceeded")
+	}
+	if !(&Subscription{}).ResetsAt().IsZero() {
+		t.Error("ResetsAt() should be zero without a reset time")
+	}
+
+	client.apiKey = "wrong"
+	if _, err := client.Subscription(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Subscription() error = %v, want a 401 error", err)
+	}
+}
+
 func TestClient_GenerateOutputPathExtension(t *testing.T) {
 	// Create mock server that returns successful response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprint(w, "audio-data")
+		_, _ = fmt.Fprint(w, "ID3audio-data")
 	}))
 	defer server.Close()
 
@@ -457,3 +631,270 @@ func TestClient_GenerateOutputPathExtension(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestClient_GenerateLosslessWAV(t *testing.T) {
+	pcm := []byte{0x00, 0x01, 0x02, 0x03}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("output_format"); got != PCMOutputFormat {
+			t.Errorf("Expected output_format=%s, got %q", PCMOutputFormat, got)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(pcm)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:              "test-api-key",
+		textToSpeechBaseURL: server.URL,
+		httpClient:          server.Client(),
+	}
+
+	outputPath, err := client.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello world",
+		Voice:      "21m00Tcm4TlvDq8ikWAM",
+		OutputPath: filepath.Join(t.TempDir(), "test.mp3"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if filepath.Ext(outputPath) != ".wav" {
+		t.Errorf("Output path should have .wav extension, got %s", outputPath)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if len(data) != 44+len(pcm) || string(data[:4]) != "RIFF" {
+		t.Errorf("Expected WAV file wrapping %d PCM bytes, got %d bytes", len(pcm), len(data))
+	}
+}
+
+func TestClient_GenerateStream(t *testing.T) {
+	chunks := []string{"ID3", "chunk1", "chunk2"}
+	truncate := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/text-to-speech/voice-123/stream" {
+			t.Errorf("Expected the streaming endpoint, got %s", r.URL.Path)
+		}
+		if truncate {
+			// Declare more audio than is sent, as when a stream breaks off
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte("ID3partial"))
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		for _, chunk := range chunks {
+			_, _ = w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:              "test-api-key",
+		textToSpeechBaseURL: server.URL,
+		httpClient:          server.Client(),
+		stream:              true,
+	}
+
+	outputPath, err := client.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello world",
+		Voice:      "voice-123",
+		OutputPath: filepath.Join(t.TempDir(), "test.mp3"),
+		Format:     "mp3",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != strings.Join(chunks, "") {
+		t.Errorf("Output = %q, want all streamed chunks", data)
+	}
+
+	// A broken stream leaves no truncated file behind
+	truncate = true
+	truncatedPath := filepath.Join(t.TempDir(), "truncated.mp3")
+	_, err = client.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello world",
+		Voice:      "voice-123",
+		OutputPath: truncatedPath,
+		Format:     "mp3",
+	})
+	if err == nil {
+		t.Fatal("Expected an error for a broken stream")
+	}
+	if _, statErr := os.Stat(truncatedPath); !os.IsNotExist(statErr) {
+		t.Errorf("Expected the truncated file to be removed, stat error = %v", statErr)
+	}
+}
+
+func TestClient_GenerateStitching(t *testing.T) {
+	var bodies []TTSRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body TTSRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("request-id", fmt.Sprintf("req-%d", len(bodies)))
+		_, _ = w.Write([]byte("ID3audio"))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:              "test-api-key",
+		textToSpeechBaseURL: server.URL,
+		httpClient:          server.Client(),
+	}
+
+	dir := t.TempDir()
+	requests := []tts.GenerateRequest{
+		{Text: "First.", NextText: "Second.", Voice: "v", OutputPath: filepath.Join(dir, "1.mp3")},
+		{Text: "Second.", PreviousText: "First.", Voice: "v", OutputPath: filepath.Join(dir, "2.mp3")},
+	}
+	for _, req := range requests {
+		if _, err := client.Generate(context.Background(), req); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(bodies))
+	}
+	if bodies[0].NextText != "Second." || bodies[0].PreviousText != "" || bodies[0].PreviousRequestIDs != nil {
+		t.Errorf("First request = %+v", bodies[0])
+	}
+	if bodies[1].PreviousText != "First." || len(bodies[1].PreviousRequestIDs) != 1 || bodies[1].PreviousRequestIDs[0] != "req-1" {
+		t.Errorf("Second request = %+v, want the previous text and request ID", bodies[1])
+	}
+}
+
+func TestClient_Seed(t *testing.T) {
+	requestBody := func(t *testing.T, client *Client, req tts.GenerateRequest) TTSRequest {
+		t.Helper()
+		_, bodyBytes, _, err := client.buildTTSRequest(req)
+		if err != nil {
+			t.Fatalf("buildTTSRequest() error = %v", err)
+		}
+		var body TTSRequest
+		if err := json.Unmarshal(bodyBytes, &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	req := tts.GenerateRequest{Text: "Second.", PreviousText: "First.", Voice: "v"}
+
+	client := &Client{}
+	client.rememberRequest("First.", "req-1")
+	if body := requestBody(t, client, req); body.Seed != 0 || len(body.PreviousRequestIDs) != 1 {
+		t.Errorf("request = %+v, want no seed and the previous request ID", body)
+	}
+
+	client.seed = 42
+	if body := requestBody(t, client, req); body.Seed != 42 {
+		t.Errorf("seed = %d, want 42", body.Seed)
+	}
+
+	// Deterministic requests are seeded from the text and carry no request IDs
+	client = &Client{deterministic: true}
+	client.rememberRequest("First.", "req-1")
+	body := requestBody(t, client, req)
+	if body.Seed == 0 || body.PreviousRequestIDs != nil {
+		t.Errorf("request = %+v, want a text seed without request IDs", body)
+	}
+	if again := requestBody(t, client, req); again.Seed != body.Seed {
+		t.Errorf("seed = %d, then %d for the same text", body.Seed, again.Seed)
+	}
+	if other := requestBody(t, client, tts.GenerateRequest{Text: "Third.", Voice: "v"}); other.Seed == body.Seed {
+		t.Errorf("seed = %d for different texts", other.Seed)
+	}
+	client.seed = 7
+	if body := requestBody(t, client, req); body.Seed != 7 {
+		t.Errorf("seed = %d, want the configured seed 7", body.Seed)
+	}
+}
+
+func TestClient_PreviewRequest(t *testing.T) {
+	client := &Client{
+		apiKey:              "sk-test-api-key-1234",
+		textToSpeechBaseURL: TextToSpeechBaseURL,
+		stability:           0.6,
+		similarityBoost:     0.7,
+		useSpeakerBoost:     true,
+		speed:               1.0,
+	}
+
+	tests := []struct {
+		name       string
+		format     string
+		wantTarget string
+		wantAccept string
+	}{
+		{
+			name:       "mp3",
+			format:     "mp3",
+			wantTarget: "POST " + TextToSpeechBaseURL + "/text-to-speech/voice-123",
+			wantAccept: "audio/mpeg",
+		},
+		{
+			name:       "lossless",
+			format:     "wav",
+			wantTarget: "POST " + TextToSpeechBaseURL + "/text-to-speech/voice-123?output_format=" + PCMOutputFormat,
+			wantAccept: "audio/pcm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := client.PreviewRequest(tts.GenerateRequest{
+				Text:   "Hello world",
+				Voice:  "voice-123",
+				Format: tt.format,
+			})
+			if err != nil {
+				t.Fatalf("PreviewRequest() error = %v", err)
+			}
+
+			if preview.Target != tt.wantTarget {
+				t.Errorf("Target = %q, want %q", preview.Target, tt.wantTarget)
+			}
+			if preview.Headers["Accept"] != tt.wantAccept {
+				t.Errorf("Accept = %q, want %q", preview.Headers["Accept"], tt.wantAccept)
+			}
+			if got := preview.Headers["xi-api-key"]; got != "****1234" {
+				t.Errorf("API key should be masked, got %q", got)
+			}
+			for _, want := range []string{`"text": "Hello world"`, `"model_id": "` + DefaultModel + `"`, `"stability": 0.6`, `"similarity_boost": 0.7`, `"use_speaker_boost": true`} {
+				if !strings.Contains(preview.Body, want) {
+					t.Errorf("Body missing %s:\n%s", want, preview.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Model(t *testing.T) {
+	client := &Client{apiKey: "test-api-key", textToSpeechBaseURL: TextToSpeechBaseURL, model: "eleven_v3"}
+	preview, err := client.PreviewRequest(tts.GenerateRequest{Text: "[whispers] Hello", Voice: "voice-123"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	// Audio tags are sent as written
+	if !strings.Contains(preview.Body, `"model_id": "eleven_v3"`) || !strings.Contains(preview.Body, `"text": "[whispers] Hello"`) {
+		t.Errorf("Body = %s, want the configured model and the tagged text", preview.Body)
+	}
+
+	if !SupportsAudioTags("eleven_v3") || SupportsAudioTags(DefaultModel) {
+		t.Error("SupportsAudioTags() should accept v3 models only")
+	}
+}
+
+func TestBreakTags(t *testing.T) {
+	got := breakTags("Ready? [pause 1.5s] Go. [pause 4s] Done.")
+	want := `Ready? <break time="1.5s" /> Go. <break time="3s" /> <break time="1s" /> Done.`
+	if got != want {
+		t.Errorf("breakTags() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package elevenlabs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// VoiceUpload describes a voice created or edited with samples.
+type VoiceUpload struct {
+	Name        string   // Voice name (required when creating)
+	Description string   // Optional description shown in the voice library
+	Files       []string // Audio sample files of the speaker
+}
+
+// CreateVoice creates an instant voice clone from the sample files and
+// returns the ID of the new voice.
+func (c *Client) CreateVoice(ctx context.Context, upload VoiceUpload) (string, error) {
+	if upload.Name == "" {
+		return "", fmt.Errorf("voice name is required")
+	}
+	if len(upload.Files) == 0 {
+		return "", fmt.Errorf("at least one voice sample file is required")
+	}
+
+	url := fmt.Sprintf("%s/voices/add", c.textToSpeechBaseURL)
+	resp, err := c.postVoiceForm(ctx, url, upload)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var created struct {
+		VoiceID string `json:"voice_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if created.VoiceID == "" {
+		return "", fmt.Errorf("API response did not include a voice ID")
+	}
+	return created.VoiceID, nil
+}
+
+// EditVoice renames or re-describes a voice and adds the sample files to it.
+// The current name is kept when upload.Name is empty.
+func (c *Client) EditVoice(ctx context.Context, voiceID string, upload VoiceUpload) error {
+	if upload.Name == "" {
+		voice, err := c.GetVoice(ctx, voiceID)
+		if err != nil {
+			return err
+		}
+		upload.Name = voice.Name
+	}
+
+	url := fmt.Sprintf("%s/voices/%s/edit", c.textToSpeechBaseURL, voiceID)
+	resp, err := c.postVoiceForm(ctx, url, upload)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteVoice deletes a voice from the account.
+func (c *Client) DeleteVoice(ctx context.Context, voiceID string) error {
+	url := fmt.Sprintf("%s/voices/%s", c.textToSpeechBaseURL, voiceID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("xi-api-key", c.apiKey)
+
+	if c.log != nil {
+		c.log.Debug(fmt.Sprintf("ElevenLabs API: DELETE /voices/%s", voiceID))
+	}
+
+	resp, err := c.retryableHTTPRequest(ctx, httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// GetVoice retrieves a voice of the account.
+func (c *Client) GetVoice(ctx context.Context, voiceID string) (*VoiceInfo, error) {
+	url := fmt.Sprintf("%s/voices/%s", c.textToSpeechBaseURL, voiceID)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("xi-api-key", c.apiKey)
+
+	if c.log != nil {
+		c.log.Debug(fmt.Sprintf("ElevenLabs API: GET /voices/%s", voiceID))
+	}
+
+	resp, err := c.retryableHTTPRequest(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var voice VoiceInfo
+	if err := json.NewDecoder(resp.Body).Decode(&voice); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &voice, nil
+}
+
+// postVoiceForm sends a voice upload as a multipart form and returns the
+// successful response. Uploads are not retried, so a request that timed out
+// after the voice was created cannot create it twice.
+func (c *Client) postVoiceForm(ctx context.Context, url string, upload VoiceUpload) (*http.Response, error) {
+	body, contentType, err := voiceForm(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("xi-api-key", c.apiKey)
+	httpReq.Header.Set("Content-Type", contentType)
+
+	if c.log != nil {
+		c.log.Debug(fmt.Sprintf("ElevenLabs API: POST %s (%d sample files)", url, len(upload.Files)))
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, classifyFailure(resp.StatusCode, respBody)
+	}
+	return resp, nil
+}
+
+// voiceForm encodes a voice upload as multipart form data, returning the body
+// and its content type.
+func voiceForm(upload VoiceUpload) (*bytes.Buffer, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	if err := w.WriteField("name", upload.Name); err != nil {
+		return nil, "", fmt.Errorf("failed to encode voice form: %w", err)
+	}
+	if upload.Description != "" {
+		if err := w.WriteField("description", upload.Description); err != nil {
+			return nil, "", fmt.Errorf("failed to encode voice form: %w", err)
+		}
+	}
+	for _, path := range upload.Files {
+		if err := addFormFile(w, path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to encode voice form: %w", err)
+	}
+	return body, w.FormDataContentType(), nil
+}
+
+// addFormFile copies a sample file into the "files" field of the form.
+func addFormFile(w *multipart.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open voice sample: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	part, err := w.CreateFormFile("files", filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to encode voice form: %w", err)
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to read voice sample %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package elevenlabs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+func TestClient_CreateVoice(t *testing.T) {
+	var gotName, gotDescription string
+	var gotFiles []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/voices/add" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("invalid form: %v", err)
+		}
+		gotName, gotDescription = r.FormValue("name"), r.FormValue("description")
+		for _, header := range r.MultipartForm.File["files"] {
+			f, _ := header.Open()
+			data, _ := io.ReadAll(f)
+			_ = f.Close()
+			gotFiles = append(gotFiles, header.Filename+"="+string(data))
+		}
+		_, _ = fmt.Fprint(w, `{"voice_id":"new-voice-id","requires_verification":false}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	samples := []string{filepath.Join(dir, "a.mp3"), filepath.Join(dir, "b.wav")}
+	for i, path := range samples {
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("sample%d", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := &Client{apiKey: "test-api-key", textToSpeechBaseURL: server.URL, httpClient: server.Client()}
+	voiceID, err := client.CreateVoice(context.Background(), VoiceUpload{Name: "Narrator", Description: "Course narrator", Files: samples})
+	if err != nil {
+		t.Fatalf("CreateVoice() error = %v", err)
+	}
+	if voiceID != "new-voice-id" {
+		t.Errorf("voice ID = %q", voiceID)
+	}
+	if gotName != "Narrator" || gotDescription != "Course narrator" {
+		t.Errorf("form = (%q, %q)", gotName, gotDescription)
+	}
+	if strings.Join(gotFiles, ",") != "a.mp3=sample0,b.wav=sample1" {
+		t.Errorf("files = %v", gotFiles)
+	}
+
+	if _, err := client.CreateVoice(context.Background(), VoiceUpload{Name: "Narrator"}); err == nil {
+		t.Error("CreateVoice() accepted a voice without samples")
+	}
+	if _, err := client.CreateVoice(context.Background(), VoiceUpload{Name: "Narrator", Files: []string{filepath.Join(dir, "missing.mp3")}}); err == nil || !strings.Contains(err.Error(), "voice sample") {
+		t.Errorf("CreateVoice() error = %v, want a missing sample error", err)
+	}
+}
+
+func TestClient_EditVoice(t *testing.T) {
+	var gotName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/voices/voice-123":
+			_, _ = fmt.Fprint(w, `{"voice_id":"voice-123","name":"Narrator"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/voices/voice-123/edit":
+			gotName = r.FormValue("name")
+			_, _ = fmt.Fprint(w, `{"status":"ok"}`)
+		default:
+			http.Error(w, `{"detail":{"status":"voice_not_found"}}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{apiKey: "test-api-key", textToSpeechBaseURL: server.URL, httpClient: server.Client()}
+
+	// The current name is kept when no new name is given
+	if err := client.EditVoice(context.Background(), "voice-123", VoiceUpload{Description: "Warmer take"}); err != nil {
+		t.Fatalf("EditVoice() error = %v", err)
+	}
+	if gotName != "Narrator" {
+		t.Errorf("name = %q, want the current name", gotName)
+	}
+	if err := client.EditVoice(context.Background(), "voice-123", VoiceUpload{Name: "Host"}); err != nil || gotName != "Host" {
+		t.Errorf("EditVoice() = %v, name %q, want the new name", err, gotName)
+	}
+
+	err := client.EditVoice(context.Background(), "unknown", VoiceUpload{Name: "Host"})
+	var hard *tts.HardFailure
+	if !errors.As(err, &hard) || hard.Scope != tts.ScopeVoice {
+		t.Errorf("EditVoice() error = %v, want an unknown voice failure", err)
+	}
+}
+
+func TestClient_DeleteVoice(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.Header.Get("xi-api-key") != "test-api-key" {
+			http.Error(w, `{"detail":"Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+		deleted = strings.TrimPrefix(r.URL.Path, "/voices/")
+		_, _ = fmt.Fprint(w, `{"status":"ok"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{apiKey: "test-api-key", textToSpeechBaseURL: server.URL, httpClient: server.Client()}
+	if err := client.DeleteVoice(context.Background(), "voice-123"); err != nil {
+		t.Fatalf("DeleteVoice() error = %v", err)
+	}
+	if deleted != "voice-123" {
+		t.Errorf("deleted = %q, want voice-123", deleted)
+	}
+
+	client.apiKey = "wrong"
+	if err := client.DeleteVoice(context.Background(), "voice-123"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("DeleteVoice() error = %v, want a 401 error", err)
+	}
+}
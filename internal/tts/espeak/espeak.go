@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/indaco/md2audio/internal/text"
 	"github.com/indaco/md2audio/internal/tts"
@@ -18,11 +19,22 @@ import (
 
 // Provider implements the TTS Provider interface for espeak-ng command.
 type Provider struct {
-	// No configuration needed - 'espeak-ng' is a system command
+	config Config
+}
+
+// Config holds the voice tunables of the espeak provider. Zero values keep the
+// espeak defaults.
+type Config struct {
+	Pitch     int    // Pitch adjustment, 1-99 (espeak default: 50)
+	Amplitude int    // Volume, 1-200 (espeak default: 100)
+	WordGap   int    // Pause between words in units of 10ms at the default speed
+	Variant   string // Voice variant appended to the voice (e.g., "f3", "klatt"), or an MBROLA voice used instead of it (e.g., "mb-en1")
+
+	Timeout time.Duration // Kill espeak runs taking longer than this (0 = no limit)
 }
 
 // NewProvider creates a new espeak-ng provider.
-func NewProvider() (*Provider, error) {
+func NewProvider(cfg Config) (*Provider, error) {
 	// Verify we're on Linux
 	if runtime.GOOS != "linux" {
 		return nil, fmt.Errorf("espeak provider is only available on Linux")
@@ -37,7 +49,7 @@ func NewProvider() (*Provider, error) {
 		}
 	}
 
-	return &Provider{}, nil
+	return &Provider{config: cfg}, nil
 }
 
 // Name returns the provider name.
@@ -47,47 +59,26 @@ func (p *Provider) Name() string {
 
 // Generate creates audio from text using the espeak-ng command.
 func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
-	// Clean markdown from text
-	cleanText := text.CleanMarkdown(req.Text)
-	if strings.TrimSpace(cleanText) == "" {
-		return "", fmt.Errorf("no text to generate audio from")
+	args, wavPath, err := p.buildCommand(req)
+	if err != nil {
+		return "", err
 	}
 
 	// Ensure output directory exists
-	outputDir := filepath.Dir(req.OutputPath)
+	outputDir := filepath.Dir(wavPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Determine speaking rate (espeak uses -s for speed)
-	rate := 180 // default
-	if req.Rate != nil {
-		rate = *req.Rate
-	}
-
-	// Map macOS voice names to espeak voices
-	voice := mapVoiceToEspeak(req.Voice)
-
-	// Build espeak command
-	// Format: espeak-ng -v voice -s rate -w output.wav "text"
-	outputPath := req.OutputPath
-	wavPath := outputPath
-
-	// Ensure .wav extension for espeak command
-	if filepath.Ext(outputPath) != ".wav" {
-		wavPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ".wav"
-	}
-
-	// Try espeak-ng first, fall back to espeak
-	cmdName := "espeak-ng"
-	if _, err := exec.LookPath(cmdName); err != nil {
-		cmdName = "espeak"
-	}
-
-	cmd := exec.CommandContext(ctx, cmdName, "-v", voice, "-s", strconv.Itoa(rate), "-w", wavPath, cleanText)
+	cmdCtx, cancel := tts.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, commandName(), args...)
 
 	// Execute espeak command
 	if output, err := cmd.CombinedOutput(); err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return "", fmt.Errorf("espeak command timed out after %s (raise -timeout for long sections)", p.config.Timeout)
+		}
 		return "", fmt.Errorf("espeak command failed: %w\nOutput: %s", err, string(output))
 	}
 
@@ -106,7 +97,7 @@ func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (strin
 	// Convert to other formats if requested
 	if req.Format != "wav" && req.Format != "" {
 		convertedPath := strings.Replace(wavPath, ".wav", "."+req.Format, 1)
-		if err := convertAudio(ctx, wavPath, convertedPath, req.Format); err != nil {
+		if err := utils.ConvertWAV(ctx, wavPath, convertedPath, req.Format); err != nil {
 			return wavPath, fmt.Errorf("audio created but conversion to %s failed: %w", req.Format, err)
 		}
 
@@ -122,15 +113,97 @@ func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (strin
 	return wavPath, nil
 }
 
-// ListVoices returns available voices from the espeak-ng command.
-func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
-	// Try espeak-ng first, fall back to espeak
-	cmdName := "espeak-ng"
-	if _, err := exec.LookPath(cmdName); err != nil {
-		cmdName = "espeak"
+// PreviewRequest returns the espeak command that Generate would run for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	args, _, err := p.buildCommand(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	// The text is the last argument
+	return tts.RequestPreview{
+		Target: commandName() + " " + strings.Join(args[:len(args)-1], " "),
+		Body:   args[len(args)-1],
+	}, nil
+}
+
+// commandName returns espeak-ng if installed, falling back to espeak.
+func commandName() string {
+	if _, err := exec.LookPath("espeak-ng"); err != nil {
+		return "espeak"
+	}
+	return "espeak-ng"
+}
+
+// buildCommand returns the espeak arguments and WAV output path for a request.
+func (p *Provider) buildCommand(req tts.GenerateRequest) ([]string, string, error) {
+	// Clean markdown from text
+	cleanText := text.CleanMarkdown(req.Text)
+	if strings.TrimSpace(cleanText) == "" {
+		return nil, "", fmt.Errorf("no text to generate audio from")
 	}
 
-	cmd := exec.CommandContext(ctx, cmdName, "--voices")
+	// Determine speaking rate (espeak uses -s for speed)
+	rate := 180 // default
+	if req.Rate != nil {
+		rate = *req.Rate
+	}
+
+	// Map macOS voice names to espeak voices
+	voice := p.config.voice(mapVoiceToEspeak(req.Voice))
+
+	// Build espeak command
+	// Format: espeak-ng [-m] -v voice -s rate [-p pitch] [-a amplitude] [-g gap] -w output.wav "text"
+	// Phoneme escapes such as [[h@'loU]] are passed through unchanged,
+	// espeak speaks them as phoneme mnemonics in both modes.
+	wavPath := req.OutputPath
+
+	// Ensure .wav extension for espeak command
+	if filepath.Ext(wavPath) != ".wav" {
+		wavPath = wavPath[:len(wavPath)-len(filepath.Ext(wavPath))] + ".wav"
+	}
+
+	args := []string{"-v", voice, "-s", strconv.Itoa(rate)}
+	args = append(args, p.config.args()...)
+	args = append(args, "-w", wavPath, cleanText)
+	if req.SSML {
+		// -m interprets SSML markup instead of reading the tags aloud
+		args = append([]string{"-m"}, args...)
+	}
+	return args, wavPath, nil
+}
+
+// voice applies the configured variant to an espeak voice: MBROLA voices
+// replace it, other variants are appended (e.g., en-gb+f3).
+func (c Config) voice(voice string) string {
+	switch {
+	case c.Variant == "":
+		return voice
+	case strings.HasPrefix(c.Variant, "mb-"):
+		return c.Variant
+	default:
+		return voice + "+" + c.Variant
+	}
+}
+
+// args returns the espeak arguments of the configured tunables.
+func (c Config) args() []string {
+	var args []string
+	if c.Pitch > 0 {
+		args = append(args, "-p", strconv.Itoa(c.Pitch))
+	}
+	if c.Amplitude > 0 {
+		args = append(args, "-a", strconv.Itoa(c.Amplitude))
+	}
+	if c.WordGap > 0 {
+		args = append(args, "-g", strconv.Itoa(c.WordGap))
+	}
+	return args
+}
+
+// ListVoices returns available voices from the espeak-ng command.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	cmd := exec.CommandContext(ctx, commandName(), "--voices")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list voices: %w", err)
@@ -229,33 +302,3 @@ func mapVoiceToEspeak(voice string) string {
 	// Default to en-us
 	return "en-us"
 }
-
-// convertAudio converts a WAV file to another format using ffmpeg.
-func convertAudio(ctx context.Context, inputPath, outputPath, format string) error {
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return fmt.Errorf("ffmpeg is required for audio conversion but not found. Install with: sudo apt install ffmpeg")
-	}
-
-	// Build ffmpeg command
-	// ffmpeg -i input.wav -codec:a libmp3lame output.mp3 (for mp3)
-	// ffmpeg -i input.wav -codec:a aac output.m4a (for m4a)
-	var codec string
-	switch format {
-	case "mp3":
-		codec = "libmp3lame"
-	case "m4a", "mp4":
-		codec = "aac"
-	case "aiff":
-		codec = "pcm_s16be"
-	default:
-		return fmt.Errorf("unsupported output format: %s", format)
-	}
-
-	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", inputPath, "-codec:a", codec, "-y", outputPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("ffmpeg conversion failed: %w\nOutput: %s", err, string(output))
-	}
-
-	return nil
-}
@@ -16,7 +16,7 @@ func TestNewProvider(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Fatalf("Failed to create espeak provider: %v", err)
 	}
@@ -35,7 +35,7 @@ func TestNewProviderNonLinux(t *testing.T) {
 		t.Skip("Skipping non-Linux test")
 	}
 
-	_, err := NewProvider()
+	_, err := NewProvider(Config{})
 	if err == nil {
 		t.Error("Expected error on non-Linux platform")
 	}
@@ -180,7 +180,7 @@ func TestListVoices(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -214,7 +214,7 @@ func TestGenerate(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -250,7 +250,7 @@ func TestGenerateWithMacOSVoice(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -283,7 +283,7 @@ func TestGenerateWithRate(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -317,7 +317,7 @@ func TestGenerateEmptyText(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -344,7 +344,7 @@ func TestGenerateWithMarkdown(t *testing.T) {
 		t.Skip("Skipping Linux-specific test")
 	}
 
-	provider, err := NewProvider()
+	provider, err := NewProvider(Config{})
 	if err != nil {
 		t.Skipf("Skipping test: %v", err)
 	}
@@ -370,3 +370,93 @@ func TestGenerateWithMarkdown(t *testing.T) {
 		t.Error("Output file was not created")
 	}
 }
+
+func TestPreviewRequest(t *testing.T) {
+	provider := &Provider{}
+	rate := 160
+
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{
+		Text:       "Hello [world](https://example.com)",
+		Voice:      "Kate",
+		OutputPath: "out/section_01_intro.mp3",
+		Rate:       &rate,
+	})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+
+	if !strings.HasSuffix(preview.Target, " -v en-gb -s 160 -w out/section_01_intro.wav") {
+		t.Errorf("Unexpected target: %q", preview.Target)
+	}
+	if !strings.HasPrefix(preview.Target, "espeak") {
+		t.Errorf("Target should start with the espeak command: %q", preview.Target)
+	}
+	if preview.Body != "Hello world" {
+		t.Errorf("Body = %q, want cleaned text", preview.Body)
+	}
+}
+
+func TestPreviewRequestMarkup(t *testing.T) {
+	provider := &Provider{}
+
+	tests := []struct {
+		name       string
+		req        tts.GenerateRequest
+		wantPrefix string
+		wantBody   string
+	}{
+		{
+			name:       "plain text",
+			req:        tts.GenerateRequest{Text: "Say [[h@'loU]] now", Voice: "Kate", OutputPath: "out/a.wav"},
+			wantPrefix: " -v en-gb",
+			wantBody:   "Say [[h@'loU]] now",
+		},
+		{
+			name:       "ssml",
+			req:        tts.GenerateRequest{Text: `Wait <break time="1s"/> then [[h@'loU]]`, Voice: "Kate", OutputPath: "out/a.wav", SSML: true},
+			wantPrefix: " -m -v en-gb",
+			wantBody:   `Wait <break time="1s"/> then [[h@'loU]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := provider.PreviewRequest(tt.req)
+			if err != nil {
+				t.Fatalf("PreviewRequest() error = %v", err)
+			}
+			if !strings.Contains(preview.Target, tt.wantPrefix+" -s 180") {
+				t.Errorf("Target = %q, want arguments %q", preview.Target, tt.wantPrefix)
+			}
+			if preview.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", preview.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPreviewRequestTunables(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		wantArgs string
+	}{
+		{name: "defaults", config: Config{}, wantArgs: " -v en-gb -s 180 -w out/a.wav"},
+		{name: "pitch, amplitude and gap", config: Config{Pitch: 30, Amplitude: 150, WordGap: 3}, wantArgs: " -v en-gb -s 180 -p 30 -a 150 -g 3 -w out/a.wav"},
+		{name: "variant", config: Config{Variant: "f3"}, wantArgs: " -v en-gb+f3 -s 180 -w out/a.wav"},
+		{name: "mbrola voice", config: Config{Variant: "mb-en1"}, wantArgs: " -v mb-en1 -s 180 -w out/a.wav"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &Provider{config: tt.config}
+			preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "Hello", Voice: "Kate", OutputPath: "out/a.wav"})
+			if err != nil {
+				t.Fatalf("PreviewRequest() error = %v", err)
+			}
+			if !strings.HasSuffix(preview.Target, tt.wantArgs) {
+				t.Errorf("Target = %q, want arguments %q", preview.Target, tt.wantArgs)
+			}
+		})
+	}
+}
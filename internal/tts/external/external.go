@@ -0,0 +1,318 @@
+// Package external runs TTS providers implemented as external executables.
+// It lets organizations add proprietary TTS engines without forking md2audio.
+//
+// Protocol: for each call, md2audio starts the executable and writes one JSON
+// request to its stdin. The executable writes one JSON response to stdout
+// and exits. Diagnostics go to stderr and are included in error messages.
+//
+//	-> {"version": 1, "method": "generate", "generate": {"text": "...", "voice": "...", "output_path": "/out/section_01_intro.mp3", "format": "mp3"}}
+//	<- {"output_path": "/out/section_01_intro.mp3"}
+//
+//	-> {"version": 1, "method": "list_voices"}
+//	<- {"voices": [{"id": "anna", "name": "Anna", "language": "en-US", "gender": "female"}]}
+//
+// A response with a non-empty "error" field fails the call.
+//
+// Executables named md2audio-provider-<name> on PATH are discovered without
+// registration and selected with -provider <name>.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// ProtocolVersion is the version of the JSON protocol sent in every request
+const ProtocolVersion = 1
+
+// Protocol methods
+const (
+	MethodGenerate   = "generate"
+	MethodListVoices = "list_voices"
+)
+
+// waitDelay is how long the output of a killed executable is waited for
+const waitDelay = time.Second
+
+// CommandPrefix is the prefix of the executables discovered on PATH
+const CommandPrefix = "md2audio-provider-"
+
+// Config registers an external provider.
+type Config struct {
+	Name    string            `json:"name"`           // Provider name selected with -provider
+	Command string            `json:"command"`        // Executable path or name on PATH
+	Args    []string          `json:"args,omitempty"` // Extra arguments passed to the executable
+	Env     map[string]string `json:"env,omitempty"`  // Extra environment variables (e.g., credentials)
+	Cloud   bool              `json:"cloud"`          // Sends text to a remote service (refused by -local-only; true unless registered with "cloud": false)
+}
+
+// Request is the JSON message written to the executable's stdin.
+type Request struct {
+	Version  int              `json:"version"`
+	Method   string           `json:"method"`
+	Generate *GenerateRequest `json:"generate,omitempty"`
+}
+
+// GenerateRequest holds the parameters of a generate call.
+type GenerateRequest struct {
+	Text           string   `json:"text"`
+	Voice          string   `json:"voice,omitempty"`
+	OutputPath     string   `json:"output_path"`
+	Format         string   `json:"format,omitempty"`
+	Rate           *int     `json:"rate,omitempty"`
+	ModelID        *string  `json:"model_id,omitempty"`
+	TargetDuration *float64 `json:"target_duration,omitempty"`
+	SSML           bool     `json:"ssml,omitempty"`
+}
+
+// Response is the JSON message read from the executable's stdout.
+type Response struct {
+	OutputPath string  `json:"output_path,omitempty"`
+	Voices     []Voice `json:"voices,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Voice is a voice returned by a list_voices call.
+type Voice struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+	Quality     string `json:"quality,omitempty"`
+}
+
+// Provider implements the TTS Provider interface for an external executable.
+type Provider struct {
+	config  Config
+	command string        // Resolved executable path
+	timeout time.Duration // Kill calls taking longer than this (0 = no limit)
+}
+
+// NewProvider creates a provider running the configured executable.
+func NewProvider(cfg Config) (*Provider, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("external provider name is required")
+	}
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("external provider %q: command not found: %s", cfg.Name, cfg.Command)
+	}
+	return &Provider{config: cfg, command: command}, nil
+}
+
+// SetTimeout kills calls of the executable that take longer than timeout (0 = no limit).
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// Generate asks the executable to write the audio for req and returns its path.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return "", fmt.Errorf("no text to generate audio from")
+	}
+	if err := os.MkdirAll(filepath.Dir(req.OutputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	resp, err := p.call(ctx, Request{Version: ProtocolVersion, Method: MethodGenerate, Generate: generateRequest(req)})
+	if err != nil {
+		return "", err
+	}
+
+	outputPath := resp.OutputPath
+	if outputPath == "" {
+		outputPath = req.OutputPath
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return "", fmt.Errorf("external provider %q did not write %s", p.config.Name, outputPath)
+	}
+	return outputPath, nil
+}
+
+// ListVoices asks the executable for its voices.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	resp, err := p.call(ctx, Request{Version: ProtocolVersion, Method: MethodListVoices})
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]tts.Voice, 0, len(resp.Voices))
+	for _, v := range resp.Voices {
+		name := v.Name
+		if name == "" {
+			name = v.ID
+		}
+		voices = append(voices, tts.Voice{
+			ID:          v.ID,
+			Name:        name,
+			Description: v.Description,
+			Language:    v.Language,
+			Gender:      v.Gender,
+			Quality:     v.Quality,
+		})
+	}
+	return voices, nil
+}
+
+// PreviewRequest returns the command and JSON request Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	body, err := json.MarshalIndent(Request{Version: ProtocolVersion, Method: MethodGenerate, Generate: generateRequest(req)}, "", "  ")
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+	return tts.RequestPreview{
+		Target: strings.Join(append([]string{p.command}, p.config.Args...), " "),
+		Body:   string(body),
+	}, nil
+}
+
+// generateRequest converts a TTS request into its protocol message
+func generateRequest(req tts.GenerateRequest) *GenerateRequest {
+	return &GenerateRequest{
+		Text:           req.Text,
+		Voice:          req.Voice,
+		OutputPath:     req.OutputPath,
+		Format:         req.Format,
+		Rate:           req.Rate,
+		ModelID:        req.ModelID,
+		TargetDuration: req.TargetDuration,
+		SSML:           req.SSML,
+	}
+}
+
+// call runs the executable with one request and decodes its response
+func (p *Provider) call(ctx context.Context, req Request) (Response, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	cmdCtx, cancel := tts.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, p.command, p.config.Args...)
+	// Processes started by the executable could keep its output open after it is killed
+	cmd.WaitDelay = waitDelay
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = os.Environ()
+	for key, value := range p.config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return Response{}, fmt.Errorf("external provider %q timed out after %s (raise -timeout for long sections)", p.config.Name, p.timeout)
+		}
+		return Response{}, fmt.Errorf("external provider %q failed: %w\nOutput: %s", p.config.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return Response{}, fmt.Errorf("external provider %q returned an invalid response: %w", p.config.Name, err)
+	}
+	if resp.Error != "" {
+		return Response{}, fmt.Errorf("external provider %q: %s", p.config.Name, resp.Error)
+	}
+	return resp, nil
+}
+
+// providersFile is the JSON document read by LoadConfigs
+type providersFile struct {
+	Providers []registration `json:"providers"`
+}
+
+// registration is a provider entry of the JSON document, telling a missing
+// "cloud" key apart from "cloud": false
+type registration struct {
+	Config
+	Cloud *bool `json:"cloud"`
+}
+
+// LoadConfigs reads external provider registrations from a JSON file:
+//
+//	{"providers": [{"name": "acme", "command": "/opt/acme/tts-plugin", "env": {"ACME_TOKEN": "..."}}]}
+//
+// Names in reserved (the built-in providers) cannot be registered. Like
+// discovered providers, registered providers are treated as cloud providers
+// unless they are registered with "cloud": false.
+func LoadConfigs(path string, reserved []string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read external providers file: %w", err)
+	}
+
+	var file providersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid external providers file %s: %w", path, err)
+	}
+
+	names := make(map[string]bool)
+	configs := make([]Config, len(file.Providers))
+	for i, entry := range file.Providers {
+		cfg := entry.Config
+		if cfg.Name == "" || cfg.Command == "" {
+			return nil, fmt.Errorf("provider %d in %s: name and command are required", i+1, path)
+		}
+		if slices.Contains(reserved, cfg.Name) {
+			return nil, fmt.Errorf("provider %q in %s: the name of a built-in provider cannot be registered", cfg.Name, path)
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate provider %q in %s", cfg.Name, path)
+		}
+		names[cfg.Name] = true
+		cfg.Cloud = entry.Cloud == nil || *entry.Cloud
+		configs[i] = cfg
+	}
+	return configs, nil
+}
+
+// Discover returns the provider for an md2audio-provider-<name> executable on PATH.
+// Discovered providers are treated as cloud providers, as nothing says where they
+// send text; register them with "cloud": false to use them with -local-only.
+func Discover(name string) (Config, bool) {
+	if name == "" {
+		return Config{}, false
+	}
+	command, err := exec.LookPath(CommandPrefix + name)
+	if err != nil {
+		return Config{}, false
+	}
+	return Config{Name: name, Command: command, Cloud: true}, true
+}
+
+// DiscoverAll returns the names of the md2audio-provider-<name> executables on PATH, sorted.
+func DiscoverAll() []string {
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, CommandPrefix+"*"))
+		for _, match := range matches {
+			name := strings.TrimPrefix(filepath.Base(match), CommandPrefix)
+			if ext := filepath.Ext(name); ext != "" && strings.EqualFold(ext, ".exe") {
+				name = strings.TrimSuffix(name, ext)
+			}
+			if _, ok := Discover(name); ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,205 @@
+package external
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// writePlugin writes a fake external provider script and returns its path
+func writePlugin(t *testing.T, content string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+	script := filepath.Join(t.TempDir(), "md2audio-provider-fake")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+content), 0755); err != nil {
+		t.Fatalf("Failed to write fake plugin: %v", err)
+	}
+	return script
+}
+
+// fakePlugin writes "audio" to the requested output path, lists one voice,
+// and records the last request in $REQUEST_LOG
+const fakePlugin = `request=$(cat)
+echo "$request" > "$REQUEST_LOG"
+case "$request" in
+*'"method":"list_voices"'*)
+	echo '{"voices": [{"id": "anna", "name": "Anna", "language": "en-US", "gender": "female"}, {"id": "bob"}]}'
+	;;
+*)
+	path=$(echo "$request" | sed -n 's/.*"output_path":"\([^"]*\)".*/\1/p')
+	printf audio > "$path"
+	echo "{\"output_path\": \"$path\"}"
+	;;
+esac
+`
+
+func TestProviderGenerate(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "request.json")
+	provider, err := NewProvider(Config{Name: "fake", Command: writePlugin(t, fakePlugin), Env: map[string]string{"REQUEST_LOG": logPath}})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if provider.Name() != "fake" {
+		t.Errorf("Name() = %q, want fake", provider.Name())
+	}
+
+	rate := 170
+	outputPath := filepath.Join(t.TempDir(), "out", "section_01_intro.mp3")
+	got, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", Voice: "anna", OutputPath: outputPath, Format: "mp3", Rate: &rate})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got != outputPath {
+		t.Errorf("Generate() = %q, want %q", got, outputPath)
+	}
+
+	request, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"version":1`, `"method":"generate"`, `"text":"Hello"`, `"voice":"anna"`, `"rate":170`, `"format":"mp3"`} {
+		if !strings.Contains(string(request), want) {
+			t.Errorf("Request missing %s: %s", want, request)
+		}
+	}
+}
+
+func TestProviderListVoices(t *testing.T) {
+	provider, err := NewProvider(Config{Name: "fake", Command: writePlugin(t, fakePlugin), Env: map[string]string{"REQUEST_LOG": os.DevNull}})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	if len(voices) != 2 || voices[0].Name != "Anna" || voices[0].Gender != "female" || voices[1].Name != "bob" {
+		t.Errorf("Unexpected voices: %+v", voices)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{name: "error response", script: `cat > /dev/null; echo '{"error": "invalid voice"}'`, wantErr: "invalid voice"},
+		{name: "invalid response", script: `cat > /dev/null; echo 'not json'`, wantErr: "invalid response"},
+		{name: "non-zero exit", script: `cat > /dev/null; echo 'license expired' >&2; exit 3`, wantErr: "license expired"},
+		{name: "no audio written", script: `cat > /dev/null; echo '{}'`, wantErr: "did not write"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(Config{Name: "fake", Command: writePlugin(t, tt.script)})
+			if err != nil {
+				t.Fatalf("NewProvider() error = %v", err)
+			}
+			_, err = provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.mp3")})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	provider, err := NewProvider(Config{Name: "fake", Command: writePlugin(t, "cat > /dev/null\nsleep 30\n")})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	provider.SetTimeout(100 * time.Millisecond)
+
+	_, err = provider.ListVoices(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("ListVoices() error = %v, want a timeout", err)
+	}
+}
+
+func TestNewProviderMissingCommand(t *testing.T) {
+	if _, err := NewProvider(Config{Name: "fake", Command: "md2audio-provider-does-not-exist"}); err == nil {
+		t.Error("NewProvider() should fail for a missing command")
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{Name: "fake", Command: writePlugin(t, fakePlugin), Args: []string{"--quality", "high"}})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "Hello", OutputPath: "out.mp3"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if !strings.HasSuffix(preview.Target, "md2audio-provider-fake --quality high") || !strings.Contains(preview.Body, `"text": "Hello"`) {
+		t.Errorf("Unexpected preview: %+v", preview)
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      int
+		wantCloud bool
+		wantErr   bool
+	}{
+		{name: "valid", content: `{"providers": [{"name": "acme", "command": "acme-tts", "cloud": true}]}`, want: 1, wantCloud: true},
+		{name: "cloud by default", content: `{"providers": [{"name": "acme", "command": "acme-tts"}]}`, want: 1, wantCloud: true},
+		{name: "local", content: `{"providers": [{"name": "acme", "command": "acme-tts", "cloud": false}]}`, want: 1},
+		{name: "built-in name", content: `{"providers": [{"name": "elevenlabs", "command": "true", "cloud": false}]}`, wantErr: true},
+		{name: "missing command", content: `{"providers": [{"name": "acme"}]}`, wantErr: true},
+		{name: "duplicate name", content: `{"providers": [{"name": "acme", "command": "a"}, {"name": "acme", "command": "b"}]}`, wantErr: true},
+		{name: "invalid JSON", content: `[`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "providers.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			configs, err := LoadConfigs(path, []string{"say", "elevenlabs"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfigs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(configs) != tt.want {
+				t.Errorf("LoadConfigs() returned %d providers, want %d", len(configs), tt.want)
+			}
+			if len(configs) > 0 && configs[0].Cloud != tt.wantCloud {
+				t.Errorf("Cloud = %v, want %v", configs[0].Cloud, tt.wantCloud)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	script := writePlugin(t, fakePlugin)
+	t.Setenv("PATH", filepath.Dir(script))
+
+	cfg, ok := Discover("fake")
+	if !ok {
+		t.Fatal("Discover() did not find md2audio-provider-fake on PATH")
+	}
+	if cfg.Name != "fake" || cfg.Command != script || !cfg.Cloud {
+		t.Errorf("Discover() = %+v", cfg)
+	}
+	if _, ok := Discover("missing"); ok {
+		t.Error("Discover() found a missing provider")
+	}
+
+	if names := DiscoverAll(); len(names) != 1 || names[0] != "fake" {
+		t.Errorf("DiscoverAll() = %v, want [fake]", names)
+	}
+}
@@ -0,0 +1,236 @@
+// Package festival implements the TTS Provider interface for the Festival
+// speech synthesis system, for Linux distributions without espeak-ng.
+//
+// Key features:
+//   - Local synthesis with the text2wave command (no network access)
+//   - Voice selection by Festival voice name (e.g., kal_diphone)
+//   - Speaking rate control via the Duration_Stretch parameter
+//   - WAV output, converted to other formats with ffmpeg
+//   - Voice listing from the installed Festival voices
+package festival
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// NaturalWPM approximates the speaking rate of Festival voices without
+// duration stretching
+const NaturalWPM = 175.0
+
+// Duration stretch limits, beyond which Festival speech is unintelligible
+const (
+	minStretch = 0.5
+	maxStretch = 2.0
+)
+
+// voicePattern matches Festival voice names, which are Scheme symbols
+// such as kal_diphone or cmu_us_slt_arctic_hts
+var voicePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Provider implements the TTS Provider interface for the Festival text2wave command.
+type Provider struct {
+	timeout time.Duration // Kill text2wave runs taking longer than this (0 = no limit)
+}
+
+// NewProvider creates a new Festival provider.
+func NewProvider() (*Provider, error) {
+	if _, err := exec.LookPath("text2wave"); err != nil {
+		return nil, fmt.Errorf("text2wave command not found. Install with: sudo apt install festival")
+	}
+	return &Provider{}, nil
+}
+
+// SetTimeout kills text2wave runs that take longer than timeout (0 = no limit).
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "festival"
+}
+
+// Generate creates audio from text using the text2wave command.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	args, wavPath, input, err := buildCommand(req)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure output directory exists
+	if err := os.MkdirAll(filepath.Dir(wavPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// text2wave reads the text from stdin
+	cmdCtx, cancel := tts.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "text2wave", args...)
+	cmd.Stdin = strings.NewReader(input)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return "", fmt.Errorf("text2wave command timed out after %s (raise -timeout for long sections)", p.timeout)
+		}
+		return "", fmt.Errorf("text2wave command failed: %w\nOutput: %s", err, string(output))
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Created: %s\n", wavPath)
+	if duration, err := utils.GetAudioDuration(wavPath); err == nil {
+		fmt.Fprintf(os.Stderr, "  Actual duration: %.2fs\n", duration)
+	}
+
+	// Convert to other formats if requested
+	if req.Format == "wav" || req.Format == "" {
+		return wavPath, nil
+	}
+	convertedPath := strings.TrimSuffix(wavPath, ".wav") + "." + req.Format
+	if err := utils.ConvertWAV(ctx, wavPath, convertedPath, req.Format); err != nil {
+		return wavPath, fmt.Errorf("audio created but conversion to %s failed: %w", req.Format, err)
+	}
+	if err := os.Remove(wavPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not remove temporary wav file: %v\n", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Converted to: %s\n", convertedPath)
+	return convertedPath, nil
+}
+
+// PreviewRequest returns the text2wave command that Generate would run for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	args, _, input, err := buildCommand(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	return tts.RequestPreview{
+		Target: "text2wave " + strings.Join(quoteArgs(args), " "),
+		Body:   input,
+	}, nil
+}
+
+// quoteArgs quotes the arguments holding spaces, for display
+func quoteArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " ()") {
+			arg = "'" + arg + "'"
+		}
+		quoted[i] = arg
+	}
+	return quoted
+}
+
+// buildCommand returns the text2wave arguments, WAV output path, and text
+// read from stdin for a request.
+func buildCommand(req tts.GenerateRequest) ([]string, string, string, error) {
+	cleanText := text.CleanMarkdown(req.Text)
+	if strings.TrimSpace(cleanText) == "" {
+		return nil, "", "", fmt.Errorf("no text to generate audio from")
+	}
+
+	// Festival always writes WAV
+	wavPath := strings.TrimSuffix(req.OutputPath, filepath.Ext(req.OutputPath)) + ".wav"
+
+	// Format: text2wave -o output.wav [-eval (voice_name)] [-eval (Parameter.set 'Duration_Stretch n)]
+	args := []string{"-o", wavPath}
+	if voice := festivalVoice(req.Voice); voice != "" {
+		args = append(args, "-eval", "(voice_"+voice+")")
+	}
+	if req.Rate != nil && *req.Rate > 0 {
+		stretch := durationStretch(*req.Rate)
+		if stretch != 1 {
+			args = append(args, "-eval", "(Parameter.set 'Duration_Stretch "+strconv.FormatFloat(stretch, 'f', 2, 64)+")")
+		}
+	}
+	return args, wavPath, cleanText, nil
+}
+
+// durationStretch returns the Festival duration stretch for a speaking rate in
+// words per minute. Festival stretches durations, so faster rates are smaller
+// values.
+func durationStretch(rate int) float64 {
+	stretch := NaturalWPM / float64(rate)
+	if stretch < minStretch {
+		fmt.Fprintf(os.Stderr, "Warning: rate %d wpm is faster than Festival supports, using a stretch of %.1f\n", rate, minStretch)
+		stretch = minStretch
+	} else if stretch > maxStretch {
+		fmt.Fprintf(os.Stderr, "Warning: rate %d wpm is slower than Festival supports, using a stretch of %.1f\n", rate, maxStretch)
+		stretch = maxStretch
+	}
+	// Rates close to the natural one keep the voice's own timing
+	if stretch > 0.97 && stretch < 1.03 {
+		return 1
+	}
+	return stretch
+}
+
+// festivalVoice returns the Festival voice name for voice, or an empty string
+// for the default voice. Names of other providers' voices (e.g., macOS "Kate")
+// are not Festival voices and select the default voice.
+func festivalVoice(voice string) string {
+	voice = strings.TrimPrefix(voice, "voice_")
+	if !voicePattern.MatchString(voice) {
+		return ""
+	}
+	return voice
+}
+
+// ListVoices returns the voices installed for Festival.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	cmd := exec.CommandContext(ctx, "festival", "--batch", "(print (voice.list))")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list voices: %w", err)
+	}
+	return parseVoiceList(string(output)), nil
+}
+
+// parseVoiceList parses the printed Scheme list of voice names,
+// e.g. "(kal_diphone cmu_us_slt_arctic_hts)"
+func parseVoiceList(output string) []tts.Voice {
+	output = strings.TrimSpace(output)
+	output = strings.TrimSuffix(strings.TrimPrefix(output, "("), ")")
+
+	voices := make([]tts.Voice, 0)
+	for _, name := range strings.Fields(output) {
+		language := voiceLanguage(name)
+		description := "Festival voice"
+		if language != "" {
+			description = fmt.Sprintf("Festival %s voice", language)
+		}
+		voices = append(voices, tts.Voice{
+			ID:          name,
+			Name:        name,
+			Language:    language,
+			Description: description,
+		})
+	}
+	return voices
+}
+
+// voiceLanguage guesses the language of a voice from its name, following the
+// naming of the CMU and Festvox voices (cmu_us_*, kal_diphone, rab_diphone, ...)
+func voiceLanguage(name string) string {
+	switch {
+	case strings.HasPrefix(name, "cmu_us_"), strings.HasPrefix(name, "kal_"), strings.HasPrefix(name, "ked_"):
+		return "en-US"
+	case strings.HasPrefix(name, "rab_"), strings.HasPrefix(name, "cmu_uk_"):
+		return "en-GB"
+	case strings.HasPrefix(name, "el_"):
+		return "es"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,165 @@
+package festival
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// fakeCommands installs text2wave and festival scripts on PATH that record
+// their arguments and stdin in dir
+func fakeCommands(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"text2wave": "#!/bin/sh\necho \"$@\" > \"" + dir + "/args\"\ncat > \"" + dir + "/stdin\"\nprintf 'RIFF' > \"$2\"\n",
+		"festival":  "#!/bin/sh\necho '(kal_diphone cmu_us_slt_arctic_hts nitech_jp_atr503_m001)'\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewProvider(); err == nil || !strings.Contains(err.Error(), "text2wave") {
+		t.Errorf("NewProvider() error = %v, want a missing command error", err)
+	}
+
+	fakeCommands(t)
+	provider, err := NewProvider()
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if provider.Name() != "festival" {
+		t.Errorf("Name() = %q, want festival", provider.Name())
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := fakeCommands(t)
+	provider, err := NewProvider()
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	rate := 350
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello **world**",
+		Voice:      "kal_diphone",
+		Rate:       &rate,
+		OutputPath: filepath.Join(t.TempDir(), "section.aiff"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".wav" {
+		t.Errorf("output path = %s, want .wav", outputPath)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("output not written: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if want := "-o " + outputPath + " -eval (voice_kal_diphone) -eval (Parameter.set 'Duration_Stretch 0.50)"; strings.TrimSpace(string(args)) != want {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+	if stdin, _ := os.ReadFile(filepath.Join(dir, "stdin")); string(stdin) != "Hello world" {
+		t.Errorf("stdin = %q, want the cleaned text", stdin)
+	}
+}
+
+func TestBuildCommand(t *testing.T) {
+	rate := 175
+	tests := []struct {
+		name     string
+		req      tts.GenerateRequest
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "default voice for other providers' voice names",
+			req:      tts.GenerateRequest{Text: "Hi", Voice: "Kate", Rate: &rate, OutputPath: "out/a.mp3"},
+			wantArgs: []string{"-o", "out/a.wav"},
+		},
+		{
+			name:     "voice_ prefix accepted",
+			req:      tts.GenerateRequest{Text: "Hi", Voice: "voice_rab_diphone", OutputPath: "out/a.wav"},
+			wantArgs: []string{"-o", "out/a.wav", "-eval", "(voice_rab_diphone)"},
+		},
+		{
+			name:    "empty text",
+			req:     tts.GenerateRequest{Text: "  ", OutputPath: "out/a.wav"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _, _, err := buildCommand(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("args = %q, want %q", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestDurationStretch(t *testing.T) {
+	tests := []struct {
+		rate int
+		want float64
+	}{
+		{rate: 175, want: 1},
+		{rate: 350, want: 0.5},
+		{rate: 700, want: minStretch},
+		{rate: 50, want: maxStretch},
+	}
+	for _, tt := range tests {
+		if got := durationStretch(tt.rate); got != tt.want {
+			t.Errorf("durationStretch(%d) = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	fakeCommands(t)
+	voices, err := (&Provider{}).ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	if len(voices) != 3 {
+		t.Fatalf("voices = %+v, want 3", voices)
+	}
+	if voices[0].ID != "kal_diphone" || voices[0].Language != "en-US" {
+		t.Errorf("first voice = %+v", voices[0])
+	}
+	if voices[2].Description != "Festival voice" {
+		t.Errorf("unknown language voice = %+v", voices[2])
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	preview, err := (&Provider{}).PreviewRequest(tts.GenerateRequest{Text: "Hello", Voice: "kal_diphone", OutputPath: "out/a.wav"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "text2wave -o out/a.wav -eval '(voice_kal_diphone)'" || preview.Body != "Hello" {
+		t.Errorf("preview = %+v", preview)
+	}
+}
@@ -0,0 +1,261 @@
+// Package marytts implements the TTS Provider interface for servers speaking
+// the MaryTTS HTTP protocol: MaryTTS itself and Mimic 3 (mimic3-server), which
+// serves the same /process and /voices endpoints. Both run locally, so
+// neural voices can be used without a cloud service.
+//
+// Key features:
+//   - Synthesis with the /process endpoint (text or SSML input)
+//   - WAV responses streamed to disk, converted with ffmpeg to mp3, m4a, or aiff
+//   - Voice listing from the server's /voices endpoint
+package marytts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+const (
+	// DefaultURL is the default address of MaryTTS and mimic3-server
+	DefaultURL = "http://localhost:59125"
+
+	// DefaultLocale is the locale spoken when none is specified
+	DefaultLocale = "en_US"
+
+	// NaturalWPM approximates the speaking rate of MaryTTS and Mimic 3 voices
+	NaturalWPM = 160.0
+)
+
+// Provider implements the TTS Provider interface for a MaryTTS-compatible server.
+type Provider struct {
+	baseURL    string
+	locale     string
+	httpClient *http.Client
+	retry      httpclient.RetryPolicy // Retries of failed requests
+}
+
+// Config holds configuration for the MaryTTS provider.
+type Config struct {
+	URL        string // Server base URL (default: DefaultURL)
+	Locale     string // Locale of the input text (default: DefaultLocale)
+	HTTPClient *http.Client
+	Retry      httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// NewProvider creates a new MaryTTS provider.
+func NewProvider(cfg Config) (*Provider, error) {
+	baseURL := strings.TrimRight(cfg.URL, "/")
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("invalid MaryTTS server URL %q: must start with http:// or https://", cfg.URL)
+	}
+
+	locale := cfg.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		// Local synthesis of long sections can take a while on a CPU
+		httpClient = &http.Client{
+			Timeout: 5 * time.Minute,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{
+		baseURL:    baseURL,
+		locale:     locale,
+		httpClient: httpClient,
+		retry:      retry,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "marytts"
+}
+
+// buildRequest returns the URL and form-encoded body of the synthesis request for req.
+func (p *Provider) buildRequest(req tts.GenerateRequest) (string, url.Values, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return "", nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	inputType := "TEXT"
+	if req.SSML {
+		inputType = "SSML"
+		text = tts.SSMLBreaks(text)
+	}
+
+	form := url.Values{}
+	form.Set("INPUT_TEXT", text)
+	form.Set("INPUT_TYPE", inputType)
+	form.Set("OUTPUT_TYPE", "AUDIO")
+	form.Set("AUDIO", "WAVE_FILE")
+	form.Set("LOCALE", p.locale)
+	// Without a voice, the server speaks with its default voice for the locale
+	if req.Voice != "" {
+		form.Set("VOICE", req.Voice)
+	}
+	return p.baseURL + "/process", form, nil
+}
+
+// PreviewRequest returns the API request that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	endpoint, form, err := p.buildRequest(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	return tts.RequestPreview{
+		Target:  http.MethodPost + " " + endpoint,
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:    form.Encode(),
+	}, nil
+}
+
+// Generate creates audio from text using the MaryTTS server.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	endpoint, form, err := p.buildRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach the MaryTTS server at %s (is it running?): %w", p.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("MaryTTS server request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		if resp.StatusCode == http.StatusNotFound && req.Voice != "" {
+			return "", &tts.HardFailure{Scope: tts.ScopeVoice, Err: err}
+		}
+		return "", err
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The server returns WAV, streamed to disk as it arrives
+	wavPath := strings.TrimSuffix(req.OutputPath, filepath.Ext(req.OutputPath)) + ".wav"
+	if err := writeWAV(wavPath, resp.Body); err != nil {
+		return "", err
+	}
+
+	// Convert to other formats if requested
+	if req.Format == "wav" || req.Format == "" {
+		return wavPath, nil
+	}
+	convertedPath := strings.TrimSuffix(wavPath, ".wav") + "." + req.Format
+	if err := utils.ConvertWAV(ctx, wavPath, convertedPath, req.Format); err != nil {
+		return wavPath, fmt.Errorf("audio created but conversion to %s failed: %w", req.Format, err)
+	}
+	if err := os.Remove(wavPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not remove temporary wav file: %v\n", err)
+	}
+	return convertedPath, nil
+}
+
+// writeWAV streams a WAV response to path, refusing bodies that are not WAV
+// data (e.g., an error page returned with status 200).
+func writeWAV(path string, r io.Reader) error {
+	head := make([]byte, 12)
+	n, _ := io.ReadFull(r, head)
+	if n < 12 || string(head[:4]) != "RIFF" || string(head[8:12]) != "WAVE" {
+		return fmt.Errorf("MaryTTS server returned non-WAV data: %q", head[:n])
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := io.Copy(f, io.MultiReader(bytes.NewReader(head), r)); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write audio data: %w", err)
+	}
+	return nil
+}
+
+// ListVoices returns the voices installed on the server.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/voices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the MaryTTS server at %s (is it running?): %w", p.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("voice list request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return parseVoices(string(body)), nil
+}
+
+// parseVoices parses the /voices response, one voice per line:
+// "cmu-slt-hsmm en_US female hmm" (MaryTTS) or "en_UK/apope_low en_UK" (Mimic 3).
+func parseVoices(body string) []tts.Voice {
+	voices := make([]tts.Voice, 0)
+	for line := range strings.Lines(body) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		voice := tts.Voice{ID: fields[0], Name: fields[0], Description: "MaryTTS voice"}
+		if len(fields) > 1 {
+			voice.Language = strings.ReplaceAll(fields[1], "_", "-")
+		}
+		if len(fields) > 2 {
+			voice.Gender = strings.ToLower(fields[2])
+		}
+		if len(fields) > 3 {
+			voice.Description = fmt.Sprintf("MaryTTS %s voice", fields[3])
+		}
+		voices = append(voices, voice)
+	}
+	return voices
+}
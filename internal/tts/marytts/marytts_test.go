@@ -0,0 +1,143 @@
+package marytts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// testWAV is a valid WAV file with an empty data chunk
+const testWAV = "RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x22\x56\x00\x00\x44\xac\x00\x00\x02\x00\x10\x00data\x00\x00\x00\x00"
+
+func TestGenerate(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/process" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		got = r.PostForm
+		w.Header().Set("Content-Type", "audio/x-wav")
+		_, _ = w.Write([]byte(testWAV))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL + "/", Locale: "de"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hallo zusammen",
+		Voice:      "de_DE/thorsten_low",
+		OutputPath: filepath.Join(t.TempDir(), "out", "section.aiff"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".wav" {
+		t.Errorf("output path = %s, want .wav", outputPath)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != testWAV {
+		t.Errorf("output = %q, want the server's WAV", data)
+	}
+	want := url.Values{
+		"INPUT_TEXT":  {"Hallo zusammen"},
+		"INPUT_TYPE":  {"TEXT"},
+		"OUTPUT_TYPE": {"AUDIO"},
+		"AUDIO":       {"WAVE_FILE"},
+		"LOCALE":      {"de"},
+		"VOICE":       {"de_DE/thorsten_low"},
+	}
+	if got.Encode() != want.Encode() {
+		t.Errorf("request = %v, want %v", got, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("VOICE") == "missing" {
+			http.Error(w, "voice not found", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("<html>error</html>"))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	req := tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "section.wav"), Format: "wav"}
+
+	if _, err := provider.Generate(context.Background(), req); err == nil || !strings.Contains(err.Error(), "non-WAV") {
+		t.Errorf("Generate() error = %v, want a non-WAV error", err)
+	}
+	req.Voice = "missing"
+	if _, err := provider.Generate(context.Background(), req); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Generate() error = %v, want a status error", err)
+	}
+	if _, err := NewProvider(Config{URL: "localhost:59125"}); err == nil {
+		t.Error("NewProvider() accepted a URL without scheme")
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/voices" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("cmu-slt-hsmm en_US female hmm\nen_UK/apope_low en_UK\n\n"))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	want := []tts.Voice{
+		{ID: "cmu-slt-hsmm", Name: "cmu-slt-hsmm", Language: "en-US", Gender: "female", Description: "MaryTTS hmm voice"},
+		{ID: "en_UK/apope_low", Name: "en_UK/apope_low", Language: "en-UK", Description: "MaryTTS voice"},
+	}
+	if len(voices) != len(want) {
+		t.Fatalf("voices = %+v, want %+v", voices, want)
+	}
+	for i := range want {
+		if voices[i] != want[i] {
+			t.Errorf("voice %d = %+v, want %+v", i, voices[i], want[i])
+		}
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "<speak>Hi</speak>", SSML: true})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "POST "+DefaultURL+"/process" {
+		t.Errorf("target = %s", preview.Target)
+	}
+	if !strings.Contains(preview.Body, "INPUT_TYPE=SSML") || !strings.Contains(preview.Body, "LOCALE="+DefaultLocale) || strings.Contains(preview.Body, "VOICE=") {
+		t.Errorf("body = %s", preview.Body)
+	}
+}
@@ -0,0 +1,484 @@
+// Package playht implements the TTS Provider interface for the Play.ht API.
+// Play.ht offers prebuilt and cloned voices with emotion control, authenticated
+// with a user ID and secret key.
+//
+// Key features:
+//   - Streaming synthesis (MP3, Ogg, or WAV output)
+//   - Voice listing including the account's cloned voices
+//   - Speed and emotion settings, with the speed adjusted to timed sections
+//   - Long texts split into several requests and joined
+package playht
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/env"
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+const (
+	// BaseURL is the default base URL of the Play.ht API
+	BaseURL = "https://api.play.ht/api/v2"
+
+	// EnvVarUserID is the environment variable holding the user ID
+	EnvVarUserID = "PLAY_HT_USER_ID"
+
+	// EnvVarAPIKey is the environment variable holding the secret key
+	EnvVarAPIKey = "PLAY_HT_API_KEY"
+
+	// DefaultVoice is the voice used when none is specified (Jennifer)
+	DefaultVoice = "s3://voice-cloning-zero-shot/d9ff78ba-d016-47f6-b0ef-dd630f59414e/female-cs/manifest.json"
+
+	// VoiceEngine is the engine used for synthesis; it supports cloned voices and emotions
+	VoiceEngine = "PlayHT2.0"
+
+	// SampleRate is the sample rate requested for WAV generation
+	SampleRate = 24000
+
+	// NaturalWPM approximates the speaking rate of Play.ht voices at speed 1.0
+	NaturalWPM = 155.0
+
+	// MinSpeed and MaxSpeed bound the speed accepted by the API
+	MinSpeed = 0.1
+	MaxSpeed = 5.0
+)
+
+// Emotions lists the emotions accepted by the PlayHT2.0 engine
+var Emotions = []string{
+	"female_happy", "female_sad", "female_angry", "female_fearful", "female_disgust", "female_surprised",
+	"male_happy", "male_sad", "male_angry", "male_fearful", "male_disgust", "male_surprised",
+}
+
+// maxChunkBytes is the size of the text sent per request; the API rejects
+// texts longer than 2000 characters
+const maxChunkBytes = 2000
+
+// Provider implements the TTS Provider interface for Play.ht.
+type Provider struct {
+	userID     string
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	retry      httpclient.RetryPolicy // Retries of failed requests
+	speed      float64
+	emotion    string
+}
+
+// Config holds configuration for the Play.ht provider.
+type Config struct {
+	UserID     string  // User ID (default: PLAY_HT_USER_ID)
+	APIKey     string  // Secret key (default: PLAY_HT_API_KEY)
+	BaseURL    string  // API base URL (defaults to BaseURL)
+	Speed      float64 // Speaking speed (0.1-5.0, default: 1.0, only for non-timed sections)
+	Emotion    string  // Emotion of the voice (see Emotions, default: none)
+	HTTPClient *http.Client
+	Retry      httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// NewProvider creates a new Play.ht provider.
+// It loads the credentials from environment variables or .env file.
+func NewProvider(cfg Config) (*Provider, error) {
+	// Load .env file if it exists (won't override existing env vars)
+	if _, err := env.Load(".env"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load .env file: %v\n", err)
+	}
+
+	userID := cfg.UserID
+	if userID == "" {
+		userID = os.Getenv(EnvVarUserID)
+	}
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(EnvVarAPIKey)
+	}
+	if userID == "" || apiKey == "" {
+		return nil, fmt.Errorf("Play.ht credentials not found: set %s and %s environment variables or use -playht-user-id and -playht-api-key", EnvVarUserID, EnvVarAPIKey)
+	}
+
+	speed := cfg.Speed
+	if speed == 0 {
+		speed = 1.0
+	}
+	if err := ValidateSpeed(speed); err != nil {
+		return nil, err
+	}
+	if err := ValidateEmotion(cfg.Emotion); err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 120 * time.Second,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{
+		userID:     userID,
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		retry:      retry,
+		speed:      speed,
+		emotion:    cfg.Emotion,
+	}, nil
+}
+
+// ValidateSpeed checks a speaking speed against the range accepted by the API.
+func ValidateSpeed(speed float64) error {
+	if speed < MinSpeed || speed > MaxSpeed {
+		return fmt.Errorf("invalid Play.ht speed %.2f: must be between %.1f and %.1f", speed, MinSpeed, MaxSpeed)
+	}
+	return nil
+}
+
+// ValidateEmotion checks an emotion name (empty means none).
+func ValidateEmotion(emotion string) error {
+	if emotion != "" && !slices.Contains(Emotions, emotion) {
+		return fmt.Errorf("invalid Play.ht emotion %q: must be one of %s", emotion, strings.Join(Emotions, ", "))
+	}
+	return nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "playht"
+}
+
+// ttsRequest is the body of a synthesis request
+type ttsRequest struct {
+	Text         string  `json:"text"`
+	Voice        string  `json:"voice"`
+	OutputFormat string  `json:"output_format"`
+	VoiceEngine  string  `json:"voice_engine"`
+	Speed        float64 `json:"speed"`
+	SampleRate   int     `json:"sample_rate"`
+	Emotion      string  `json:"emotion,omitempty"`
+}
+
+// outputFormat returns the output format requested for format and the extension of the result
+func outputFormat(format string) (string, string) {
+	switch format {
+	case "wav", "ogg":
+		return format, "." + format
+	default:
+		return "mp3", ".mp3"
+	}
+}
+
+// buildRequests returns the JSON bodies of the requests for req, one per text chunk.
+func (p *Provider) buildRequests(req tts.GenerateRequest) ([][]byte, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	voice := req.Voice
+	if voice == "" {
+		voice = DefaultVoice
+	}
+
+	// Timing annotations override the configured speed
+	speed := p.speed
+	if req.TargetDuration != nil && *req.TargetDuration > 0 {
+		speed = calculateSpeed(text, *req.TargetDuration)
+		fmt.Fprintf(os.Stderr, "Target duration: %.1fs, Calculated speed: %.2fx\n", *req.TargetDuration, speed)
+	}
+
+	format, _ := outputFormat(req.Format)
+	chunks := utils.SplitText(text, maxChunkBytes)
+	bodies := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		body, err := json.Marshal(ttsRequest{
+			Text:         chunk,
+			Voice:        voice,
+			OutputFormat: format,
+			VoiceEngine:  VoiceEngine,
+			Speed:        speed,
+			SampleRate:   SampleRate,
+			Emotion:      p.emotion,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// calculateSpeed determines the speed needed to match the target duration.
+// The speed is clamped to 0.5-2.0, the range in which voices stay intelligible.
+func calculateSpeed(text string, targetDuration float64) float64 {
+	const (
+		minSpeed = 0.5
+		maxSpeed = 2.0
+	)
+
+	speed := utils.EstimateDuration(text, NaturalWPM) / targetDuration
+	clamped := utils.ClampFloat64(speed, minSpeed, maxSpeed)
+	if clamped != speed {
+		fmt.Fprintf(os.Stderr, "Warning: Required speed (%.2f) is outside %.1f-%.1f, clamping (audio will not match the target)\n", speed, minSpeed, maxSpeed)
+	}
+	return clamped
+}
+
+// setHeaders sets the authentication headers of a request
+func (p *Provider) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", p.apiKey)
+	req.Header.Set("X-User-Id", p.userID)
+}
+
+// PreviewRequest returns the API requests that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	bodies, err := p.buildRequests(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	formatted := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", "  "); err != nil {
+			return tts.RequestPreview{}, fmt.Errorf("failed to format request: %w", err)
+		}
+		formatted = append(formatted, buf.String())
+	}
+
+	return tts.RequestPreview{
+		Target: http.MethodPost + " " + p.baseURL + "/tts/stream",
+		Headers: map[string]string{
+			"Authorization": maskAPIKey(p.apiKey),
+			"X-User-Id":     p.userID,
+			"Content-Type":  "application/json",
+		},
+		Body: strings.Join(formatted, "\n"),
+	}, nil
+}
+
+// maskAPIKey masks all but the last 4 characters of an API key.
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// Generate creates audio from text using the Play.ht API.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	bodies, err := p.buildRequests(req)
+	if err != nil {
+		return "", err
+	}
+	_, ext := outputFormat(req.Format)
+
+	// MP3 frames and chained Ogg streams concatenate; WAV responses are joined by their samples
+	var audio, pcm bytes.Buffer
+	for _, body := range bodies {
+		var chunk bytes.Buffer
+		if err := p.synthesize(ctx, body, &chunk); err != nil {
+			return "", err
+		}
+		if ext != ".wav" {
+			audio.Write(chunk.Bytes())
+			continue
+		}
+		data, err := wavData(chunk.Bytes())
+		if err != nil {
+			return "", err
+		}
+		pcm.Write(data)
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Play.ht returns the requested format, ensure correct extension
+	outputPath := req.OutputPath
+	if filepath.Ext(outputPath) != ext {
+		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ext
+	}
+
+	if ext == ".wav" {
+		if err := utils.WritePCMAsWAV(outputPath, &pcm, SampleRate); err != nil {
+			return "", err
+		}
+		return outputPath, nil
+	}
+
+	if err := os.WriteFile(outputPath, audio.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+	return outputPath, nil
+}
+
+// synthesize sends one synthesis request and writes the audio streamed back to w.
+func (p *Provider) synthesize(ctx context.Context, body []byte, w io.Writer) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/tts/stream", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return classifyFailure(resp.StatusCode, respBody)
+	}
+
+	// Refuse error bodies sent in place of audio
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if mediaType == "application/json" || strings.HasPrefix(mediaType, "text/") {
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+			return fmt.Errorf("API returned %s instead of audio: %s", mediaType, respBody)
+		}
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read audio data: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("API returned an empty audio response")
+	}
+	return nil
+}
+
+// wavData returns the samples of a WAV file. Streamed WAV files may declare
+// a placeholder data size, so the samples run to the end of the file.
+func wavData(wav []byte) ([]byte, error) {
+	if len(wav) < 12 || string(wav[:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("API response is not WAV audio")
+	}
+	for pos := 12; pos+8 <= len(wav); {
+		id := string(wav[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(wav[pos+4 : pos+8]))
+		if id == "data" {
+			return wav[pos+8:], nil
+		}
+		pos += 8 + size + size%2
+	}
+	return nil, fmt.Errorf("WAV response has no data chunk")
+}
+
+// classifyFailure returns the error for a failed API request, marking failures
+// that retrying cannot fix.
+func classifyFailure(statusCode int, body []byte) error {
+	err := fmt.Errorf("API request failed with status %d: %s", statusCode, strings.TrimSpace(string(body)))
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &tts.HardFailure{Scope: tts.ScopeAuth, Err: err}
+	case http.StatusNotFound:
+		return &tts.HardFailure{Scope: tts.ScopeVoice, Err: err}
+	default:
+		return err
+	}
+}
+
+// voice is a voice of the voice list endpoints
+type voice struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Gender   string `json:"gender"`
+	Accent   string `json:"accent"`
+	Style    string `json:"style"`
+}
+
+// ListVoices returns the prebuilt voices followed by the account's cloned voices.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	prebuilt, err := p.fetchVoices(ctx, "/voices")
+	if err != nil {
+		return nil, err
+	}
+	cloned, err := p.fetchVoices(ctx, "/cloned-voices")
+	if err != nil {
+		return nil, err
+	}
+
+	voices := make([]tts.Voice, 0, len(prebuilt)+len(cloned))
+	for _, v := range prebuilt {
+		var details []string
+		for _, detail := range []string{v.Accent, v.Style} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		voices = append(voices, tts.Voice{
+			ID:          v.ID,
+			Name:        v.Name,
+			Description: strings.Join(details, ", "),
+			Language:    v.Language,
+			Gender:      v.Gender,
+		})
+	}
+	for _, v := range cloned {
+		voices = append(voices, tts.Voice{
+			ID:          v.ID,
+			Name:        v.Name,
+			Description: "Cloned voice",
+			Language:    v.Language,
+			Gender:      v.Gender,
+		})
+	}
+	return voices, nil
+}
+
+// fetchVoices returns the voices of a voice list endpoint
+func (p *Provider) fetchVoices(ctx context.Context, path string) ([]voice, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setHeaders(httpReq)
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var voices []voice
+	if err := json.NewDecoder(resp.Body).Decode(&voices); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return voices, nil
+}
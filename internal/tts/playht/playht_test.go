@@ -0,0 +1,195 @@
+package playht
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func newTestProvider(t *testing.T, cfg Config, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.UserID = "user-1"
+	cfg.APIKey = "secret-key"
+	cfg.BaseURL = server.URL + "/api/v2"
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return provider
+}
+
+func TestGenerate(t *testing.T) {
+	var got ttsRequest
+	provider := newTestProvider(t, Config{Speed: 1.2, Emotion: "female_happy"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/tts/stream" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "secret-key" || r.Header.Get("X-User-Id") != "user-1" {
+			t.Error("missing credentials")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3audio"))
+	})
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello there",
+		Voice:      "s3://voices/custom/manifest.json",
+		OutputPath: filepath.Join(t.TempDir(), "section.aiff"),
+		Format:     "aiff",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".mp3" {
+		t.Errorf("output path = %s, want .mp3", outputPath)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "ID3audio" {
+		t.Errorf("output = %q", data)
+	}
+	want := ttsRequest{Text: "Hello there", Voice: "s3://voices/custom/manifest.json", OutputFormat: "mp3", VoiceEngine: VoiceEngine, Speed: 1.2, SampleRate: SampleRate, Emotion: "female_happy"}
+	if got != want {
+		t.Errorf("request = %+v, want %+v", got, want)
+	}
+}
+
+func TestGenerateWAVChunks(t *testing.T) {
+	calls := 0
+	provider := newTestProvider(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		// A streamed WAV with a placeholder data size
+		_, _ = w.Write([]byte("RIFF\xff\xff\xff\xffWAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\xc0\x5d\x00\x00\x80\xbb\x00\x00\x02\x00\x10\x00data\xff\xff\xff\xff\x01\x00\x02\x00"))
+	})
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       strings.Repeat("word ", 500),
+		OutputPath: filepath.Join(t.TempDir(), "section.wav"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("requests = %d, want 2 chunks", calls)
+	}
+	duration, err := utils.GetWAVDuration(outputPath)
+	if err != nil {
+		t.Fatalf("invalid WAV output: %v", err)
+	}
+	if want := 4.0 / SampleRate; duration != want {
+		t.Errorf("duration = %v, want %v (samples of both chunks)", duration, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantScope string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, wantScope: tts.ScopeAuth},
+		{name: "unknown voice", status: http.StatusNotFound, wantScope: tts.ScopeVoice},
+		{name: "server error", status: http.StatusInternalServerError},
+		{name: "json instead of audio", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error_message":"oops"}`))
+			})
+			_, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.mp3")})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var hard *tts.HardFailure
+			if got := errors.As(err, &hard); got != (tt.wantScope != "") || (got && hard.Scope != tt.wantScope) {
+				t.Errorf("error = %v, want hard failure scope %q", err, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	provider := newTestProvider(t, Config{}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/voices":
+			_, _ = w.Write([]byte(`[{"id": "s3://jennifer", "name": "Jennifer", "language": "English (US)", "gender": "female", "accent": "american", "style": "narrative"}]`))
+		case "/api/v2/cloned-voices":
+			_, _ = w.Write([]byte(`[{"id": "s3://mine", "name": "My Voice", "voice_engine": "PlayHT2.0"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	if len(voices) != 2 {
+		t.Fatalf("voices = %+v, want prebuilt and cloned", voices)
+	}
+	if voices[0].Description != "american, narrative" || voices[0].Gender != "female" {
+		t.Errorf("prebuilt voice = %+v", voices[0])
+	}
+	if voices[1].ID != "s3://mine" || voices[1].Description != "Cloned voice" {
+		t.Errorf("cloned voice = %+v", voices[1])
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{UserID: "user-1", APIKey: "secret-key"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	duration := 60.0
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: strings.Repeat("word ", 155), TargetDuration: &duration, Format: "ogg"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "POST "+BaseURL+"/tts/stream" {
+		t.Errorf("target = %s", preview.Target)
+	}
+	if strings.Contains(preview.Headers["Authorization"], "secret") {
+		t.Error("API key not masked")
+	}
+	if !strings.Contains(preview.Body, `"speed": 1`) || !strings.Contains(preview.Body, `"output_format": "ogg"`) {
+		t.Errorf("unexpected body: %s", preview.Body)
+	}
+}
+
+func TestNewProviderValidation(t *testing.T) {
+	t.Setenv(EnvVarUserID, "")
+	t.Setenv(EnvVarAPIKey, "")
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{name: "missing credentials", cfg: Config{UserID: "user-1"}, want: EnvVarAPIKey},
+		{name: "speed out of range", cfg: Config{UserID: "u", APIKey: "k", Speed: 6}, want: "invalid Play.ht speed"},
+		{name: "unknown emotion", cfg: Config{UserID: "u", APIKey: "k", Emotion: "bored"}, want: "invalid Play.ht emotion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewProvider(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewProvider() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
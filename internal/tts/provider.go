@@ -10,10 +10,27 @@
 //
 // Providers:
 //   - say: macOS built-in TTS (AIFF, M4A output)
+//   - festival: Festival text2wave command (WAV output, converted with ffmpeg)
 //   - elevenlabs: ElevenLabs API (MP3 output)
+//   - edge: Microsoft Edge read aloud service (MP3 output, no API key)
+//   - coqui: Coqui XTTS server with voice cloning (WAV output, converted with ffmpeg)
+//   - marytts: MaryTTS or Mimic 3 server (WAV output, converted with ffmpeg)
+//   - watson: IBM Watson Text to Speech (MP3, Ogg Opus, or WAV output)
+//   - playht: Play.ht API with cloned voices and emotions (MP3, Ogg, or WAV output)
+//   - custom-http: HTTP services described in a configuration file (-custom-http-config)
+//   - external: executables speaking a JSON stdio protocol (-external-providers)
+//   - silence: silent timing scaffolds, no speech (-silence-only)
 package tts
 
-import "context"
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/text"
+)
 
 // Provider defines the interface for text-to-speech providers.
 // Implementations include macOS 'say' command and ElevenLabs API.
@@ -51,6 +68,40 @@ type GenerateRequest struct {
 
 	// TargetDuration is the desired duration in seconds (optional, for timing control)
 	TargetDuration *float64
+
+	// SSML marks Text as containing SSML markup (optional, used by 'espeak' provider)
+	SSML bool
+
+	// PreviousText and NextText are the texts spoken before and after Text
+	// (optional, used by ElevenLabs to keep prosody continuous across sections)
+	PreviousText string
+	NextText     string
+}
+
+// RequestPreview describes the request a provider would make for a GenerateRequest.
+type RequestPreview struct {
+	// Target is the API endpoint (e.g., "POST https://...") or command line
+	Target string
+
+	// Headers are the HTTP headers sent with the request (secrets masked)
+	Headers map[string]string
+
+	// Body is the request payload (JSON body or text passed to the command)
+	Body string
+}
+
+// RequestPreviewer is implemented by providers that can describe a request
+// without executing it (used by -dry-run-requests).
+type RequestPreviewer interface {
+	// PreviewRequest returns the request that Generate would make for req.
+	PreviewRequest(req GenerateRequest) (RequestPreview, error)
+}
+
+// Speaker is implemented by providers that can play a request through the
+// speakers without writing a file (used by -preview).
+type Speaker interface {
+	// Speak plays the text of req aloud.
+	Speak(ctx context.Context, req GenerateRequest) error
 }
 
 // Voice represents a TTS voice.
@@ -69,4 +120,97 @@ type Voice struct {
 
 	// Gender is the voice gender (if applicable)
 	Gender string
+
+	// Quality is the voice quality tier (e.g., "compact", "enhanced", "premium"; if applicable)
+	Quality string
+}
+
+// Model represents a speech model offered by a provider (e.g., ElevenLabs models).
+type Model struct {
+	// ID is the unique model identifier (e.g., "eleven_multilingual_v2")
+	ID string
+
+	// Name is the human-readable model name
+	Name string
+
+	// Description provides additional information about the model
+	Description string
+
+	// Languages are the language codes the model speaks (e.g., "en", "de")
+	Languages []string
+
+	// MaxCharacters is the maximum text length of a request (0 if unknown)
+	MaxCharacters int
+}
+
+// Scopes of a HardFailure
+const (
+	// ScopeAuth marks rejected credentials, which fail every request
+	ScopeAuth = "auth"
+
+	// ScopeVoice marks an unknown voice, which fails every request for that voice
+	ScopeVoice = "voice"
+)
+
+// HardFailure is a provider error that retrying will not fix, such as rejected
+// credentials or an unknown voice. Providers return it so repeated runs can
+// fail fast with the recorded diagnosis instead of calling the provider again.
+type HardFailure struct {
+	// Scope is what the failure applies to (ScopeAuth or ScopeVoice)
+	Scope string
+
+	// Err is the provider error
+	Err error
+}
+
+// Error returns the provider error message.
+func (e *HardFailure) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the provider error.
+func (e *HardFailure) Unwrap() error {
+	return e.Err
+}
+
+// WithTimeout returns a copy of ctx cancelled after timeout, for limiting the
+// commands run by local providers. A zero timeout leaves ctx unlimited.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// SpeechTarget returns the spoken text of req (see text.SpokenText) and the
+// part of the target duration left for speech once the pauses are taken out,
+// for providers fitting speech to a target duration. req must have a
+// TargetDuration; pauses filling it leave no time for speech.
+func SpeechTarget(req GenerateRequest) (string, float64) {
+	return text.SpokenText(req.Text), max(*req.TargetDuration-text.PauseDuration(req.Text).Seconds(), 0)
+}
+
+// SSMLBreaks replaces the pause directives of s ("[pause 1.5s]") with SSML
+// break elements, for providers speaking SSML.
+func SSMLBreaks(s string) string {
+	return text.ReplacePauses(s, func(pause time.Duration) string {
+		return fmt.Sprintf(`<break time="%dms"/>`, pause.Milliseconds())
+	})
+}
+
+// SSMLContent returns text as SSML content for providers speaking SSML: plain
+// text is escaped with its pause directives as breaks, and the markup of SSML
+// blocks is passed through as written.
+func SSMLContent(s string) string {
+	var b strings.Builder
+	for _, segment := range text.SplitSSML(s) {
+		if segment.SSML {
+			b.WriteString(segment.Text)
+			continue
+		}
+		var escaped strings.Builder
+		_ = xml.EscapeText(&escaped, []byte(segment.Text))
+		b.WriteString(SSMLBreaks(escaped.String()))
+	}
+	return b.String()
 }
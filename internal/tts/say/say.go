@@ -6,10 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/indaco/md2audio/internal/text"
 	"github.com/indaco/md2audio/internal/tts"
@@ -18,7 +18,8 @@ import (
 
 // Provider implements the TTS Provider interface for macOS 'say' command.
 type Provider struct {
-	// No configuration needed - 'say' is a system command
+	keepIntermediates bool          // Keep the AIFF synthesized before conversion next to the output
+	timeout           time.Duration // Kill say runs taking longer than this (0 = no limit)
 }
 
 // NewProvider creates a new macOS say provider.
@@ -41,38 +42,53 @@ func (p *Provider) Name() string {
 	return "say"
 }
 
+// SetKeepIntermediates keeps the AIFF synthesized before m4a conversion next
+// to the converted file instead of in a temporary directory, for debugging.
+func (p *Provider) SetKeepIntermediates(keep bool) {
+	p.keepIntermediates = keep
+}
+
+// SetTimeout kills say runs that take longer than timeout (0 = no limit).
+func (p *Provider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
 // Generate creates audio from text using the macOS say command.
 func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
-	// Clean markdown from text
-	cleanText := text.CleanMarkdown(req.Text)
-	if strings.TrimSpace(cleanText) == "" {
-		return "", fmt.Errorf("no text to generate audio from")
+	convert := req.Format == "m4a" || req.Format == "mp4"
+	m4aPath := strings.TrimSuffix(req.OutputPath, filepath.Ext(req.OutputPath)) + ".m4a"
+
+	// Synthesize the intermediate AIFF in a temporary directory, so interrupted
+	// conversions don't leave stray .aiff files next to the final outputs
+	if convert && !p.keepIntermediates {
+		tmpDir, err := os.MkdirTemp("", "md2audio-say-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+		req.OutputPath = filepath.Join(tmpDir, filepath.Base(req.OutputPath))
+	}
+
+	args, outputPath, err := buildCommand(req)
+	if err != nil {
+		return "", err
 	}
 
 	// Ensure output directory exists
-	outputDir := filepath.Dir(req.OutputPath)
+	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Determine speaking rate
-	rate := 180 // default
-	if req.Rate != nil {
-		rate = *req.Rate
-	}
-
-	// Build say command
-	// Format: say -v Voice -r Rate -o output.aiff "text"
-	outputPath := req.OutputPath
-	// Ensure .aiff extension for say command
-	if filepath.Ext(outputPath) != ".aiff" {
-		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ".aiff"
-	}
-
-	cmd := exec.CommandContext(ctx, "say", "-v", req.Voice, "-r", strconv.Itoa(rate), "-o", outputPath, cleanText)
+	cmdCtx, cancel := tts.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, "say", args...)
 
 	// Execute say command
 	if output, err := cmd.CombinedOutput(); err != nil {
+		if cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return "", fmt.Errorf("say command timed out after %s (raise -timeout for long sections)", p.timeout)
+		}
 		return "", fmt.Errorf("say command failed: %w\nOutput: %s", err, string(output))
 	}
 
@@ -91,17 +107,17 @@ func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (strin
 	}
 
 	// Convert to M4A if requested
-	if req.Format == "m4a" || req.Format == "mp4" {
-		m4aPath := strings.Replace(outputPath, ".aiff", ".m4a", 1)
+	if convert {
+		if err := os.MkdirAll(filepath.Dir(m4aPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %w", err)
+		}
 		if err := convertToM4A(ctx, outputPath, m4aPath); err != nil {
-			return outputPath, fmt.Errorf("audio created but conversion to m4a failed: %w", err)
+			return "", fmt.Errorf("audio created but conversion to m4a failed: %w", err)
 		}
 
-		// Remove the original aiff file
-		if err := os.Remove(outputPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not remove temporary aiff file: %v\n", err)
+		if p.keepIntermediates {
+			fmt.Fprintf(os.Stderr, "  Kept intermediate: %s\n", outputPath)
 		}
-
 		fmt.Fprintf(os.Stderr, "✓ Converted to: %s\n", m4aPath)
 		return m4aPath, nil
 	}
@@ -109,41 +125,116 @@ func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (strin
 	return outputPath, nil
 }
 
-// ListVoices returns available voices from the macOS say command.
-func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
-	cmd := exec.CommandContext(ctx, "say", "-v", "?")
-	output, err := cmd.Output()
+// PreviewRequest returns the say command that Generate would run for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	args, _, err := buildCommand(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list voices: %w", err)
+		return tts.RequestPreview{}, err
 	}
 
-	// Parse output (format: "VoiceName    locale  # Description")
-	lines := strings.Split(string(output), "\n")
-	voices := make([]tts.Voice, 0, len(lines))
+	// The text is the last argument
+	return tts.RequestPreview{
+		Target: "say " + strings.Join(args[:len(args)-1], " "),
+		Body:   args[len(args)-1],
+	}, nil
+}
 
-	// Regex to parse voice lines
-	voicePattern := regexp.MustCompile(`^([^\s]+(?:\s+\([^)]+\))?)\s+([a-z]{2}_[A-Z]{2})\s+#\s+(.+)$`)
+// Speak plays the text of req through the speakers instead of writing a
+// file, so voices and rates can be auditioned before a batch run.
+func (p *Provider) Speak(ctx context.Context, req tts.GenerateRequest) error {
+	args, cleanText, err := speakArgs(req)
+	if err != nil {
+		return err
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	cmd := exec.CommandContext(ctx, "say", append(args, cleanText)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("say command failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
 
-		matches := voicePattern.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			voices = append(voices, tts.Voice{
-				ID:          matches[1], // Voice name is the ID
-				Name:        matches[1],
-				Language:    matches[2],
-				Description: matches[3],
-			})
-		}
+// buildCommand returns the say arguments and AIFF output path for a request.
+func buildCommand(req tts.GenerateRequest) ([]string, string, error) {
+	args, cleanText, err := speakArgs(req)
+	if err != nil {
+		return nil, "", err
 	}
 
+	// Build say command
+	// Format: say -v Voice -r Rate -o output.aiff "text"
+	outputPath := req.OutputPath
+	// Ensure .aiff extension for say command
+	if filepath.Ext(outputPath) != ".aiff" {
+		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ".aiff"
+	}
+
+	return append(args, "-o", outputPath, cleanText), outputPath, nil
+}
+
+// speakArgs returns the voice and rate arguments of a request and its text,
+// cleaned of markdown with its pauses as silence commands.
+func speakArgs(req tts.GenerateRequest) ([]string, string, error) {
+	// Clean markdown from text
+	cleanText := text.CleanMarkdown(req.Text)
+	if strings.TrimSpace(text.StripPauses(cleanText)) == "" {
+		return nil, "", fmt.Errorf("no text to generate audio from")
+	}
+
+	// Pause directives become embedded silence commands
+	cleanText = text.ReplacePauses(cleanText, func(pause time.Duration) string {
+		return fmt.Sprintf("[[slnc %d]]", pause.Milliseconds())
+	})
+
+	// Determine speaking rate
+	rate := 180 // default
+	if req.Rate != nil {
+		rate = *req.Rate
+	}
+
+	return []string{"-v", req.Voice, "-r", strconv.Itoa(rate)}, cleanText, nil
+}
+
+// ListVoices returns available voices from the macOS say command,
+// with gender and quality tier where they can be determined.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	voices, err := installedVoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addGenders(ctx, voices)
 	return voices, nil
 }
 
+// installedVoices returns the voices listed by `say -v ?`, without genders.
+func installedVoices(ctx context.Context) ([]tts.Voice, error) {
+	cmd := exec.CommandContext(ctx, "say", "-v", "?")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list voices: %w", err)
+	}
+	return parseVoices(string(output)), nil
+}
+
+// VoiceQualityHint returns a warning when voice is only the compact variant
+// of a voice with a higher quality tier, or an empty string.
+func (p *Provider) VoiceQualityHint(ctx context.Context, voice string) (string, error) {
+	voices, err := installedVoices(ctx)
+	if err != nil {
+		return "", err
+	}
+	return qualityHint(voice, voices), nil
+}
+
+// OpenVoiceSettings opens the System Settings pane where voices are downloaded.
+func (p *Provider) OpenVoiceSettings(ctx context.Context) error {
+	if output, err := exec.CommandContext(ctx, "open", voiceSettingsURL).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to open voice settings: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
 // getAudioDuration is deprecated. Use utils.GetAudioDuration instead.
 // This wrapper is kept for backward compatibility but may be removed in future versions.
 func getAudioDuration(audioPath string) (float64, error) {
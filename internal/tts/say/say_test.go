@@ -163,6 +163,33 @@ func TestProvider_GenerateM4A(t *testing.T) {
 	}
 }
 
+func TestProvider_GenerateM4AKeepIntermediates(t *testing.T) {
+	if runtime.GOOS != "darwin" {
+		t.Skip("Skipping macOS-specific test")
+	}
+
+	provider := &Provider{}
+	provider.SetKeepIntermediates(true)
+	tmpDir := t.TempDir()
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Hello world",
+		Voice:      "Kate",
+		OutputPath: filepath.Join(tmpDir, "test.aiff"),
+		Format:     "m4a",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if outputPath != filepath.Join(tmpDir, "test.m4a") {
+		t.Errorf("Expected %s, got %s", filepath.Join(tmpDir, "test.m4a"), outputPath)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "test.aiff")); err != nil {
+		t.Errorf("Intermediate AIFF file should be kept: %v", err)
+	}
+}
+
 func TestProvider_ListVoices(t *testing.T) {
 	if runtime.GOOS != "darwin" {
 		t.Skip("Skipping macOS-specific test")
@@ -310,3 +337,41 @@ func TestConvertToM4A(t *testing.T) {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestProvider_PreviewRequest(t *testing.T) {
+	provider := &Provider{}
+	rate := 200
+
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{
+		Text:       "Hello **world**",
+		Voice:      "Kate",
+		OutputPath: "out/section_01_intro.m4a",
+		Rate:       &rate,
+		Format:     "m4a",
+	})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+
+	if want := "say -v Kate -r 200 -o out/section_01_intro.aiff"; preview.Target != want {
+		t.Errorf("Target = %q, want %q", preview.Target, want)
+	}
+	if preview.Body != "Hello world" {
+		t.Errorf("Body = %q, want cleaned text", preview.Body)
+	}
+
+	if _, err := provider.PreviewRequest(tts.GenerateRequest{Text: "   ", Voice: "Kate"}); err == nil {
+		t.Error("PreviewRequest() should fail for empty text")
+	}
+	if _, err := provider.PreviewRequest(tts.GenerateRequest{Text: "[pause 1s]", Voice: "Kate"}); err == nil {
+		t.Error("PreviewRequest() should fail for text of pauses only")
+	}
+
+	preview, err = provider.PreviewRequest(tts.GenerateRequest{Text: "Ready? [pause 1.5s] Go.", Voice: "Kate", OutputPath: "out/section_01_intro.aiff"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if want := "Ready? [[slnc 1500]] Go."; preview.Body != want {
+		t.Errorf("Body = %q, want %q", preview.Body, want)
+	}
+}
@@ -0,0 +1,206 @@
+package say
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// Voice quality tiers reported for say voices
+const (
+	QualityCompact  = "compact"
+	QualityEnhanced = "enhanced"
+	QualityPremium  = "premium"
+)
+
+// voicePattern parses a `say -v ?` line: "Name [(Variant)]   locale   # Sample text".
+// Names may contain nested parentheses, e.g. "Eddy (English (US))".
+var voicePattern = regexp.MustCompile(`^(.+?)\s+([a-z]{2,3}_[A-Z0-9]{2,3})\s+#\s?(.*)$`)
+
+// voiceDirs lists the directories holding installed voice bundles
+var voiceDirs = []string{
+	"/System/Library/Speech/Voices",
+	"/Library/Speech/Voices",
+	"~/Library/Speech/Voices",
+}
+
+// knownGenders covers common voices whose bundles are not in voiceDirs
+// (voices downloaded through System Settings are stored as mobile assets).
+var knownGenders = map[string]string{
+	"Alex": "male", "Allison": "female", "Ava": "female", "Daniel": "male",
+	"Eddy": "male", "Evan": "male", "Fiona": "female", "Flo": "female",
+	"Fred": "male", "Grandma": "female", "Grandpa": "male", "Karen": "female",
+	"Kate": "female", "Moira": "female", "Nathan": "male", "Oliver": "male",
+	"Reed": "male", "Rishi": "male", "Rocko": "male", "Samantha": "female",
+	"Sandy": "female", "Serena": "female", "Shelley": "female", "Susan": "female",
+	"Tessa": "female", "Tom": "male", "Veena": "female", "Victoria": "female",
+	"Zoe": "female",
+}
+
+// parseVoices parses the output of `say -v ?`.
+// The sample text becomes the description and the quality tier is read
+// from the "(Enhanced)" or "(Premium)" name suffix.
+func parseVoices(output string) []tts.Voice {
+	lines := strings.Split(output, "\n")
+	voices := make([]tts.Voice, 0, len(lines))
+
+	for _, line := range lines {
+		matches := voicePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) != 4 {
+			continue
+		}
+
+		name := strings.TrimSpace(matches[1])
+		voices = append(voices, tts.Voice{
+			ID:          name, // Voice name is the ID
+			Name:        name,
+			Language:    matches[2],
+			Description: strings.TrimSpace(matches[3]),
+			Quality:     voiceQuality(name),
+		})
+	}
+
+	return voices
+}
+
+// voiceQuality returns the quality tier of a voice from its name
+func voiceQuality(name string) string {
+	switch {
+	case strings.HasSuffix(name, "(Premium)"):
+		return QualityPremium
+	case strings.HasSuffix(name, "(Enhanced)"):
+		return QualityEnhanced
+	default:
+		return QualityCompact
+	}
+}
+
+// baseName returns the voice name without its variant suffix (e.g., "Ava (Premium)" -> "Ava")
+func baseName(name string) string {
+	if i := strings.Index(name, " ("); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// addGenders fills in voice genders from installed voice bundles,
+// falling back to known genders of common voices.
+func addGenders(ctx context.Context, voices []tts.Voice) {
+	genders := bundleGenders(ctx)
+	for i := range voices {
+		if gender, ok := genders[voices[i].Name]; ok {
+			voices[i].Gender = gender
+		} else if gender, ok := genders[baseName(voices[i].Name)]; ok {
+			voices[i].Gender = gender
+		} else {
+			voices[i].Gender = knownGenders[baseName(voices[i].Name)]
+		}
+	}
+}
+
+// bundleGenders reads voice genders from the Info.plist of installed voice bundles
+// using plutil. It returns an empty map if plutil is unavailable.
+func bundleGenders(ctx context.Context) map[string]string {
+	genders := make(map[string]string)
+	if _, err := exec.LookPath("plutil"); err != nil {
+		return genders
+	}
+
+	home, _ := os.UserHomeDir()
+	for _, dir := range voiceDirs {
+		if rest, ok := strings.CutPrefix(dir, "~"); ok {
+			if home == "" {
+				continue
+			}
+			dir = filepath.Join(home, rest)
+		}
+
+		plists, _ := filepath.Glob(filepath.Join(dir, "*.SpeechVoice", "Contents", "Info.plist"))
+		for _, plist := range plists {
+			output, err := exec.CommandContext(ctx, "plutil", "-extract", "VoiceAttributes", "json", "-o", "-", plist).Output()
+			if err != nil {
+				continue
+			}
+			if name, gender := parseVoiceAttributes(output); name != "" && gender != "" {
+				genders[name] = gender
+			}
+		}
+	}
+	return genders
+}
+
+// parseVoiceAttributes returns the voice name and gender from the
+// VoiceAttributes dictionary of a voice bundle, converted to JSON
+func parseVoiceAttributes(data []byte) (string, string) {
+	var attrs struct {
+		VoiceName   string `json:"VoiceName"`
+		VoiceGender string `json:"VoiceGender"`
+	}
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return "", ""
+	}
+
+	switch {
+	case strings.HasSuffix(attrs.VoiceGender, "Female"):
+		return attrs.VoiceName, "female"
+	case strings.HasSuffix(attrs.VoiceGender, "Male"):
+		return attrs.VoiceName, "male"
+	case strings.HasSuffix(attrs.VoiceGender, "Neuter"):
+		return attrs.VoiceName, "neutral"
+	default:
+		return attrs.VoiceName, ""
+	}
+}
+
+// voiceSettingsURL opens the Spoken Content pane of System Settings,
+// where enhanced and premium voices are downloaded
+const voiceSettingsURL = "x-apple.systempreferences:com.apple.preference.universalaccess?SpokenContent"
+
+// enhancedVoices lists voices that Apple offers as downloadable enhanced or premium variants
+var enhancedVoices = map[string]bool{
+	"Allison": true, "Ava": true, "Daniel": true, "Evan": true, "Fiona": true,
+	"Jamie": true, "Joelle": true, "Karen": true, "Kate": true, "Lee": true,
+	"Moira": true, "Nathan": true, "Noelle": true, "Oliver": true, "Samantha": true,
+	"Serena": true, "Susan": true, "Tessa": true, "Tom": true, "Veena": true,
+	"Zoe": true,
+}
+
+// qualityHint returns a warning when voice is a compact variant and a better one
+// is installed or can be downloaded. It returns an empty string otherwise.
+func qualityHint(voice string, voices []tts.Voice) string {
+	if voiceQuality(voice) != QualityCompact {
+		return ""
+	}
+
+	// Prefer premium over enhanced when suggesting an installed variant
+	best := ""
+	for _, v := range voices {
+		if baseName(v.Name) != voice {
+			continue
+		}
+		switch v.Quality {
+		case QualityPremium:
+			best = v.Name
+		case QualityEnhanced:
+			if best == "" {
+				best = v.Name
+			}
+		}
+	}
+	if best != "" {
+		return fmt.Sprintf("Voice %s is the compact variant; use -v %q for higher quality audio", voice, best)
+	}
+
+	if !enhancedVoices[voice] {
+		return ""
+	}
+	return fmt.Sprintf("Voice %s is only installed as a compact variant. Download its enhanced or premium version in "+
+		"System Settings > Accessibility > Spoken Content > System Voice > Manage Voices (or run with -open-voice-settings)", voice)
+}
@@ -0,0 +1,123 @@
+package say
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+const sampleVoiceList = `Albert              en_US    # Hello! My name is Albert.
+Ava (Premium)       en_US    # Hello! My name is Ava.
+Eddy (English (US)) en_US    # Hello! My name is Eddy.
+Kate                en_GB    # Hello, my name is Kate. I am a British-English voice.
+Serena (Enhanced)   en_GB    # Hello! My name is Serena.
+Thomas              fr_FR    # Bonjour, je m’appelle Thomas.
+
+not a voice line
+`
+
+func TestParseVoices(t *testing.T) {
+	voices := parseVoices(sampleVoiceList)
+
+	want := []tts.Voice{
+		{ID: "Albert", Name: "Albert", Language: "en_US", Description: "Hello! My name is Albert.", Quality: QualityCompact},
+		{ID: "Ava (Premium)", Name: "Ava (Premium)", Language: "en_US", Description: "Hello! My name is Ava.", Quality: QualityPremium},
+		{ID: "Eddy (English (US))", Name: "Eddy (English (US))", Language: "en_US", Description: "Hello! My name is Eddy.", Quality: QualityCompact},
+		{ID: "Kate", Name: "Kate", Language: "en_GB", Description: "Hello, my name is Kate. I am a British-English voice.", Quality: QualityCompact},
+		{ID: "Serena (Enhanced)", Name: "Serena (Enhanced)", Language: "en_GB", Description: "Hello! My name is Serena.", Quality: QualityEnhanced},
+		{ID: "Thomas", Name: "Thomas", Language: "fr_FR", Description: "Bonjour, je m’appelle Thomas.", Quality: QualityCompact},
+	}
+
+	if len(voices) != len(want) {
+		t.Fatalf("parseVoices() returned %d voices, want %d: %+v", len(voices), len(want), voices)
+	}
+	for i := range want {
+		if voices[i] != want[i] {
+			t.Errorf("voice %d = %+v, want %+v", i, voices[i], want[i])
+		}
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	tests := map[string]string{
+		"Kate":                "Kate",
+		"Ava (Premium)":       "Ava",
+		"Eddy (English (US))": "Eddy",
+	}
+	for name, want := range tests {
+		if got := baseName(name); got != want {
+			t.Errorf("baseName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseVoiceAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantName   string
+		wantGender string
+	}{
+		{name: "female", data: `{"VoiceName":"Kate","VoiceGender":"VoiceGenderFemale"}`, wantName: "Kate", wantGender: "female"},
+		{name: "male", data: `{"VoiceName":"Daniel","VoiceGender":"VoiceGenderMale"}`, wantName: "Daniel", wantGender: "male"},
+		{name: "neuter", data: `{"VoiceName":"Zarvox","VoiceGender":"VoiceGenderNeuter"}`, wantName: "Zarvox", wantGender: "neutral"},
+		{name: "unknown gender", data: `{"VoiceName":"Bells"}`, wantName: "Bells"},
+		{name: "invalid", data: `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, gender := parseVoiceAttributes([]byte(tt.data))
+			if name != tt.wantName || gender != tt.wantGender {
+				t.Errorf("parseVoiceAttributes() = %q, %q; want %q, %q", name, gender, tt.wantName, tt.wantGender)
+			}
+		})
+	}
+}
+
+func TestAddGendersFallback(t *testing.T) {
+	voices := parseVoices(sampleVoiceList)
+	addGenders(context.Background(), voices)
+
+	genders := make(map[string]string)
+	for _, voice := range voices {
+		genders[voice.Name] = voice.Gender
+	}
+	if genders["Ava (Premium)"] != "female" || genders["Eddy (English (US))"] != "male" || genders["Kate"] != "female" {
+		t.Errorf("Unexpected genders: %v", genders)
+	}
+}
+
+func TestQualityHint(t *testing.T) {
+	voices := parseVoices(sampleVoiceList)
+
+	tests := []struct {
+		name     string
+		voice    string
+		wantHint string
+	}{
+		{"premium variant installed", "Ava", `Voice Ava is the compact variant; use -v "Ava (Premium)" for higher quality audio`},
+		{"enhanced variant installed", "Serena", `Voice Serena is the compact variant; use -v "Serena (Enhanced)" for higher quality audio`},
+		{"only compact installed", "Kate", "Voice Kate is only installed as a compact variant"},
+		{"no enhanced variant exists", "Albert", ""},
+		{"premium voice requested", "Ava (Premium)", ""},
+		{"enhanced voice requested", "Serena (Enhanced)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := qualityHint(tt.voice, voices)
+			if tt.wantHint == "" {
+				if got != "" {
+					t.Errorf("qualityHint(%q) = %q, want no hint", tt.voice, got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.wantHint) {
+				t.Errorf("qualityHint(%q) = %q, want prefix %q", tt.voice, got, tt.wantHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package silence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/indaco/md2audio/internal/text"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// Name is the provider name recorded for silent timing scaffolds
+const Name = "silence"
+
+// defaultRate is the speaking rate used to estimate the length of sections without timing
+const defaultRate = 180
+
+// Provider implements the TTS Provider interface by writing silent audio
+// of each section's target duration, without synthesizing speech.
+type Provider struct{}
+
+// NewProvider creates a new silence provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return Name
+}
+
+// Generate writes silence of the request's target duration, or of the estimated
+// spoken length of its text and pauses when no target duration is set.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	spoken, pauses := text.SpokenText(req.Text), text.PauseDuration(req.Text).Seconds()
+	duration := utils.EstimateDuration(spoken, defaultRate) + pauses
+	switch {
+	case req.TargetDuration != nil:
+		duration = *req.TargetDuration
+	case req.Rate != nil && *req.Rate > 0:
+		duration = utils.EstimateDuration(spoken, float64(*req.Rate)) + pauses
+	}
+
+	if err := utils.WriteSilence(ctx, req.OutputPath, duration); err != nil {
+		return "", fmt.Errorf("failed to write silence: %w", err)
+	}
+	return req.OutputPath, nil
+}
+
+// ListVoices returns no voices; silence has none.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	return nil, nil
+}
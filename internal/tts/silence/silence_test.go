@@ -0,0 +1,66 @@
+package silence
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+func TestGenerate(t *testing.T) {
+	target := 4.5
+	rate := 120
+
+	tests := []struct {
+		name string
+		req  tts.GenerateRequest
+		want float64
+	}{
+		{
+			name: "target duration",
+			req:  tts.GenerateRequest{Text: "Short text.", TargetDuration: &target, Rate: &rate},
+			want: 4.5,
+		},
+		{
+			name: "estimated at the speaking rate",
+			req:  tts.GenerateRequest{Text: "one two three four", Rate: &rate},
+			want: 2,
+		},
+		{
+			name: "estimated at the default rate",
+			req:  tts.GenerateRequest{Text: "one two three"},
+			want: 1,
+		},
+	}
+
+	p := NewProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.OutputPath = filepath.Join(t.TempDir(), "section_01_intro.wav")
+			path, err := p.Generate(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if path != tt.req.OutputPath {
+				t.Errorf("Generate() path = %q, want %q", path, tt.req.OutputPath)
+			}
+
+			duration, err := utils.GetWAVDuration(path)
+			if err != nil {
+				t.Fatalf("GetWAVDuration() error = %v", err)
+			}
+			if math.Abs(duration-tt.want) > 0.001 {
+				t.Errorf("Duration = %.3f, want %.3f", duration, tt.want)
+			}
+		})
+	}
+}
+
+func TestName(t *testing.T) {
+	if got := NewProvider().Name(); got != Name {
+		t.Errorf("Name() = %q, want %q", got, Name)
+	}
+}
@@ -0,0 +1,390 @@
+// Package watson implements the TTS Provider interface for IBM Watson Text to
+// Speech, so teams already on IBM Cloud can use their existing service instance.
+//
+// Key features:
+//   - Synthesis with an API key and instance service URL (IBM credential variables)
+//   - MP3, Ogg Opus, or PCM wrapped as WAV output
+//   - Voice listing with languages and genders
+//   - Speaking rate adjusted to timed sections with SSML prosody
+//   - Long texts split into several requests and joined
+package watson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/indaco/md2audio/internal/env"
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+const (
+	// EnvVarAPIKey is the environment variable holding the API key (as in IBM credential files)
+	EnvVarAPIKey = "TEXT_TO_SPEECH_APIKEY"
+
+	// EnvVarURL is the environment variable holding the service URL (as in IBM credential files)
+	EnvVarURL = "TEXT_TO_SPEECH_URL"
+
+	// DefaultVoice is the voice used when none is specified
+	DefaultVoice = "en-US_MichaelV3Voice"
+
+	// PCMSampleRate is the sample rate requested for lossless (WAV) generation
+	PCMSampleRate = 22050
+
+	// NaturalWPM approximates the speaking rate of Watson voices at the default rate
+	NaturalWPM = 165.0
+)
+
+// maxChunkBytes is the size of the text sent per request; the service
+// rejects request bodies larger than 5 KB
+const maxChunkBytes = 4500
+
+// Provider implements the TTS Provider interface for IBM Watson Text to Speech.
+type Provider struct {
+	apiKey     string
+	serviceURL string
+	httpClient *http.Client
+	retry      httpclient.RetryPolicy // Retries of failed requests
+}
+
+// Config holds configuration for the Watson provider.
+type Config struct {
+	APIKey     string // API key (default: TEXT_TO_SPEECH_APIKEY)
+	URL        string // Service instance URL (default: TEXT_TO_SPEECH_URL)
+	HTTPClient *http.Client
+	Retry      httpclient.RetryPolicy // Retries of failed requests (zero = httpclient.DefaultRetryPolicy)
+}
+
+// NewProvider creates a new Watson provider.
+// It loads the API key and service URL from environment variables or .env file.
+func NewProvider(cfg Config) (*Provider, error) {
+	// Load .env file if it exists (won't override existing env vars)
+	if _, err := env.Load(".env"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load .env file: %v\n", err)
+	}
+
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(EnvVarAPIKey)
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("Watson API key not found: set %s environment variable or use -watson-api-key", EnvVarAPIKey)
+	}
+
+	serviceURL := cfg.URL
+	if serviceURL == "" {
+		serviceURL = os.Getenv(EnvVarURL)
+	}
+	if serviceURL == "" {
+		return nil, fmt.Errorf("Watson service URL not found: set %s environment variable or use -watson-url", EnvVarURL)
+	}
+	serviceURL = strings.TrimRight(serviceURL, "/")
+	if !strings.HasPrefix(serviceURL, "http://") && !strings.HasPrefix(serviceURL, "https://") {
+		return nil, fmt.Errorf("invalid Watson service URL %q: must start with https://", serviceURL)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 60 * time.Second,
+		}
+	}
+
+	retry := cfg.Retry
+	if retry.IsZero() {
+		retry = httpclient.DefaultRetryPolicy()
+	}
+
+	return &Provider{
+		apiKey:     apiKey,
+		serviceURL: serviceURL,
+		httpClient: httpClient,
+		retry:      retry,
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "watson"
+}
+
+// synthesizeRequest is the body of a synthesis request
+type synthesizeRequest struct {
+	Text string `json:"text"`
+}
+
+// accept returns the Accept header requesting format and the extension of the result
+func accept(format string) (string, string) {
+	switch format {
+	case "wav":
+		// Raw PCM is wrapped in a WAV container, as chunked WAV responses cannot be joined
+		return fmt.Sprintf("audio/l16;rate=%d;endianness=little-endian", PCMSampleRate), ".wav"
+	case "ogg":
+		return "audio/ogg;codecs=opus", ".ogg"
+	default:
+		return "audio/mp3", ".mp3"
+	}
+}
+
+// buildRequests returns the synthesis URL and the JSON bodies of the requests for req, one per text chunk.
+func (p *Provider) buildRequests(req tts.GenerateRequest) (string, [][]byte, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return "", nil, fmt.Errorf("no text to generate audio from")
+	}
+
+	voice := req.Voice
+	if voice == "" {
+		voice = DefaultVoice
+	}
+	synthesizeURL := p.serviceURL + "/v1/synthesize?" + url.Values{"voice": {voice}}.Encode()
+
+	// SSML markup cannot be split safely, so it is sent as a single request
+	var texts []string
+	if req.SSML {
+		texts = []string{text}
+	} else {
+		// Watson reads all input as SSML, so plain text is escaped
+		rate := ""
+		if req.TargetDuration != nil && *req.TargetDuration > 0 {
+			rate = rateForDuration(tts.SpeechTarget(req))
+			fmt.Fprintf(os.Stderr, "Target duration: %.1fs, Calculated rate: %s\n", *req.TargetDuration, rate)
+		}
+		for _, chunk := range utils.SplitText(text, maxChunkBytes) {
+			texts = append(texts, ssmlText(chunk, rate))
+		}
+	}
+
+	// SSML is sent unescaped, keeping request previews readable
+	bodies := make([][]byte, 0, len(texts))
+	for _, t := range texts {
+		var body bytes.Buffer
+		enc := json.NewEncoder(&body)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(synthesizeRequest{Text: t}); err != nil {
+			return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		bodies = append(bodies, body.Bytes())
+	}
+	return synthesizeURL, bodies, nil
+}
+
+// ssmlText returns text as SSML content for Watson (see tts.SSMLContent),
+// wrapping it in a prosody element when a rate is set
+func ssmlText(text, rate string) string {
+	content := tts.SSMLContent(text)
+	if rate == "" {
+		return content
+	}
+	return fmt.Sprintf("<speak><prosody rate=\"%s\">%s</prosody></speak>", rate, content)
+}
+
+// rateForDuration returns the relative rate that speaks text in targetDuration seconds.
+// The rate is clamped to -50%..+100%, the range in which voices stay intelligible.
+func rateForDuration(text string, targetDuration float64) string {
+	const (
+		minSpeed = 0.5
+		maxSpeed = 2.0
+	)
+
+	speed := utils.EstimateDuration(text, NaturalWPM) / targetDuration
+	clamped := utils.ClampFloat64(speed, minSpeed, maxSpeed)
+	if clamped != speed {
+		fmt.Fprintf(os.Stderr, "Warning: Required speed (%.2f) is outside %.1f-%.1f, clamping (audio will not match the target)\n", speed, minSpeed, maxSpeed)
+	}
+	return fmt.Sprintf("%+d%%", int(math.Round((clamped-1)*100)))
+}
+
+// PreviewRequest returns the API requests that Generate would send for req.
+func (p *Provider) PreviewRequest(req tts.GenerateRequest) (tts.RequestPreview, error) {
+	synthesizeURL, bodies, err := p.buildRequests(req)
+	if err != nil {
+		return tts.RequestPreview{}, err
+	}
+
+	formatted := make([]string, 0, len(bodies))
+	for _, body := range bodies {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", "  "); err != nil {
+			return tts.RequestPreview{}, fmt.Errorf("failed to format request: %w", err)
+		}
+		formatted = append(formatted, buf.String())
+	}
+
+	acceptHeader, _ := accept(req.Format)
+	return tts.RequestPreview{
+		Target: http.MethodPost + " " + synthesizeURL,
+		Headers: map[string]string{
+			"Authorization": "Basic apikey:" + maskAPIKey(p.apiKey),
+			"Content-Type":  "application/json",
+			"Accept":        acceptHeader,
+		},
+		Body: strings.Join(formatted, "\n"),
+	}, nil
+}
+
+// maskAPIKey masks all but the last 4 characters of an API key.
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return "****" + key[len(key)-4:]
+}
+
+// Generate creates audio from text using Watson Text to Speech.
+func (p *Provider) Generate(ctx context.Context, req tts.GenerateRequest) (string, error) {
+	synthesizeURL, bodies, err := p.buildRequests(req)
+	if err != nil {
+		return "", err
+	}
+	acceptHeader, ext := accept(req.Format)
+
+	// MP3 frames, chained Ogg streams, and raw PCM all concatenate, so long texts are joined as received
+	var audio bytes.Buffer
+	for _, body := range bodies {
+		if err := p.synthesize(ctx, synthesizeURL, acceptHeader, body, &audio); err != nil {
+			return "", err
+		}
+	}
+
+	// Ensure output directory exists
+	outputDir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Watson returns the requested encoding, ensure correct extension
+	outputPath := req.OutputPath
+	if filepath.Ext(outputPath) != ext {
+		outputPath = outputPath[:len(outputPath)-len(filepath.Ext(outputPath))] + ext
+	}
+
+	if ext == ".wav" {
+		if err := utils.WritePCMAsWAV(outputPath, &audio, PCMSampleRate); err != nil {
+			return "", err
+		}
+		return outputPath, nil
+	}
+
+	if err := os.WriteFile(outputPath, audio.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+	return outputPath, nil
+}
+
+// synthesize sends one synthesis request and appends the audio received to w.
+func (p *Provider) synthesize(ctx context.Context, synthesizeURL, acceptHeader string, body []byte, w io.Writer) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, synthesizeURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.SetBasicAuth("apikey", p.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", acceptHeader)
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return classifyFailure(resp.StatusCode, respBody)
+	}
+
+	// Refuse error bodies sent in place of audio
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if mediaType == "application/json" || strings.HasPrefix(mediaType, "text/") {
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+			return fmt.Errorf("API returned %s instead of audio: %s", mediaType, respBody)
+		}
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read audio data: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("API returned an empty audio response")
+	}
+	return nil
+}
+
+// classifyFailure returns the error for a failed API request, marking failures
+// that retrying cannot fix.
+func classifyFailure(statusCode int, body []byte) error {
+	err := fmt.Errorf("API request failed with status %d: %s", statusCode, strings.TrimSpace(string(body)))
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &tts.HardFailure{Scope: tts.ScopeAuth, Err: err}
+	case http.StatusNotFound:
+		return &tts.HardFailure{Scope: tts.ScopeVoice, Err: err}
+	default:
+		return err
+	}
+}
+
+// voicesResponse is the response of the voice list endpoint
+type voicesResponse struct {
+	Voices []struct {
+		Name        string `json:"name"`
+		Language    string `json:"language"`
+		Gender      string `json:"gender"`
+		Description string `json:"description"`
+	} `json:"voices"`
+}
+
+// ListVoices returns the voices of the service instance.
+func (p *Provider) ListVoices(ctx context.Context) ([]tts.Voice, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.serviceURL+"/v1/voices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.SetBasicAuth("apikey", p.apiKey)
+
+	resp, err := httpclient.Do(ctx, p.httpClient, httpReq, p.retry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var voicesResp voicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&voicesResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	voices := make([]tts.Voice, len(voicesResp.Voices))
+	for i, v := range voicesResp.Voices {
+		// Voice names look like en-US_AllisonV3Voice
+		name := v.Name
+		if _, rest, ok := strings.Cut(name, "_"); ok {
+			name = strings.TrimSuffix(rest, "Voice")
+		}
+		voices[i] = tts.Voice{
+			ID:          v.Name,
+			Name:        name,
+			Description: v.Description,
+			Language:    v.Language,
+			Gender:      v.Gender,
+		}
+	}
+	return voices, nil
+}
@@ -0,0 +1,222 @@
+package watson
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/indaco/md2audio/internal/httpclient"
+	"github.com/indaco/md2audio/internal/tts"
+)
+
+// testRetry retries failed requests without slowing tests down
+var testRetry = httpclient.RetryPolicy{MaxRetries: 2, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, RetryOn: []int{http.StatusServiceUnavailable}}
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewProvider(Config{APIKey: "test-key", URL: server.URL + "/instances/abc/", Retry: testRetry})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	return provider
+}
+
+func TestGenerate(t *testing.T) {
+	var requests []synthesizeRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "apikey" || pass != "test-key" {
+			t.Errorf("unexpected credentials %q:%q", user, pass)
+		}
+		if r.URL.Path != "/instances/abc/v1/synthesize" || r.URL.Query().Get("voice") != "en-GB_KateV3Voice" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("Accept") != "audio/ogg;codecs=opus" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		var body synthesizeRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write([]byte("OggS"))
+	})
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       "Fish & chips",
+		Voice:      "en-GB_KateV3Voice",
+		OutputPath: filepath.Join(t.TempDir(), "section.mp3"),
+		Format:     "ogg",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if filepath.Ext(outputPath) != ".ogg" {
+		t.Errorf("output path = %s, want .ogg", outputPath)
+	}
+	if data, _ := os.ReadFile(outputPath); string(data) != "OggS" {
+		t.Errorf("output = %q", data)
+	}
+	if len(requests) != 1 || requests[0].Text != "Fish &amp; chips" {
+		t.Errorf("requests = %+v, want escaped text", requests)
+	}
+}
+
+func TestGenerateLongTextWAV(t *testing.T) {
+	calls := 0
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if !strings.HasPrefix(r.Header.Get("Accept"), "audio/l16;rate=22050") {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		_, _ = w.Write([]byte{0, 0, 1, 0})
+	})
+
+	outputPath, err := provider.Generate(context.Background(), tts.GenerateRequest{
+		Text:       strings.Repeat("word ", 2000),
+		OutputPath: filepath.Join(t.TempDir(), "section.wav"),
+		Format:     "wav",
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("requests = %d, want 3 chunks", calls)
+	}
+	data, _ := os.ReadFile(outputPath)
+	if !strings.HasPrefix(string(data), "RIFF") || len(data) != 44+3*4 {
+		t.Errorf("output is not the joined PCM wrapped as WAV (%d bytes)", len(data))
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantScope string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"error":"Unauthorized"}`, wantScope: tts.ScopeAuth},
+		{name: "unknown voice", status: http.StatusNotFound, body: `{"error":"Model not found"}`, wantScope: tts.ScopeVoice},
+		{name: "server error", status: http.StatusInternalServerError, body: `{"error":"oops"}`},
+		{name: "json instead of audio", status: http.StatusOK, body: `{"error":"oops"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+			_, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.mp3")})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var hard *tts.HardFailure
+			if got := errors.As(err, &hard); got != (tt.wantScope != "") || (got && hard.Scope != tt.wantScope) {
+				t.Errorf("error = %v, want hard failure scope %q", err, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestGenerateRetries(t *testing.T) {
+	calls := 0
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write([]byte("OggS"))
+	})
+
+	if _, err := provider.Generate(context.Background(), tts.GenerateRequest{Text: "Hello", OutputPath: filepath.Join(t.TempDir(), "a.ogg"), Format: "ogg"}); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("requests = %d, want the failed request retried once", calls)
+	}
+}
+
+func TestListVoices(t *testing.T) {
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/instances/abc/v1/voices" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"voices": [{"name": "en-US_AllisonV3Voice", "language": "en-US", "gender": "female", "description": "Allison: American English female voice."}]}`))
+	})
+
+	voices, err := provider.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() error = %v", err)
+	}
+	want := tts.Voice{ID: "en-US_AllisonV3Voice", Name: "AllisonV3", Description: "Allison: American English female voice.", Language: "en-US", Gender: "female"}
+	if len(voices) != 1 || voices[0] != want {
+		t.Errorf("voices = %+v, want %+v", voices, want)
+	}
+}
+
+func TestPreviewRequest(t *testing.T) {
+	provider, err := NewProvider(Config{APIKey: "secret-key", URL: "https://api.example.com"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	duration := 1.0
+	preview, err := provider.PreviewRequest(tts.GenerateRequest{Text: "one two three four five six", TargetDuration: &duration})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if preview.Target != "POST https://api.example.com/v1/synthesize?voice="+DefaultVoice {
+		t.Errorf("target = %s", preview.Target)
+	}
+	if strings.Contains(preview.Headers["Authorization"], "secret") {
+		t.Error("API key not masked")
+	}
+	if !strings.Contains(preview.Body, `<prosody rate=\"+100%\">`) {
+		t.Errorf("body = %s, want a prosody rate for the target duration", preview.Body)
+	}
+
+	preview, err = provider.PreviewRequest(tts.GenerateRequest{Text: "Tom & Jerry [pause 750ms] return"})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if !strings.Contains(preview.Body, `Tom &amp; Jerry <break time=\"750ms\"/> return`) {
+		t.Errorf("body = %s, want the pause as a break in escaped text", preview.Body)
+	}
+
+	preview, err = provider.PreviewRequest(tts.GenerateRequest{Text: `Call <speak><say-as interpret-as="digits">112</say-as></speak> now`})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if !strings.Contains(preview.Body, `Call <say-as interpret-as=\"digits\">112</say-as> now`) {
+		t.Errorf("body = %s, want the SSML block as written", preview.Body)
+	}
+}
+
+func TestNewProviderMissingSettings(t *testing.T) {
+	t.Setenv(EnvVarAPIKey, "")
+	t.Setenv(EnvVarURL, "")
+	t.Chdir(t.TempDir())
+
+	if _, err := NewProvider(Config{URL: "https://api.example.com"}); err == nil || !strings.Contains(err.Error(), EnvVarAPIKey) {
+		t.Errorf("NewProvider() without a key error = %v", err)
+	}
+	if _, err := NewProvider(Config{APIKey: "key"}); err == nil || !strings.Contains(err.Error(), EnvVarURL) {
+		t.Errorf("NewProvider() without a URL error = %v", err)
+	}
+	t.Setenv(EnvVarURL, "https://api.example.com")
+	if _, err := NewProvider(Config{APIKey: "key"}); err != nil {
+		t.Errorf("NewProvider() with %s error = %v", EnvVarURL, err)
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import "html/template"
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>md2audio</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem auto; max-width: 48rem; color: #222; }
+#drop { border: 2px dashed #999; border-radius: 0.5rem; padding: 2rem; text-align: center; cursor: pointer; }
+#drop.over { background: #eef5ff; border-color: #3a7bd5; }
+label { display: inline-block; margin: 1rem 1rem 0 0; }
+button { margin: 1rem 0.5rem 0 0; padding: 0.4rem 1rem; }
+li { margin: 0.75rem 0; list-style: none; }
+audio { display: block; margin-top: 0.25rem; }
+.status { font-size: 0.8rem; padding: 0 0.4rem; border-radius: 0.2rem; margin-left: 0.5rem; }
+.flagged { background: #fff3cd; }
+.failed { background: #f8d7da; }
+.skipped { background: #e2e3e5; }
+.reason { color: #666; font-size: 0.85rem; }
+#message.error { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>md2audio</h1>
+<div id="drop">Drop a markdown file here, or click to choose one<input id="file" type="file" accept=".md,.markdown,text/markdown" hidden></div>
+<label>Provider
+<select id="provider">
+{{- range .Providers}}
+<option{{if eq . $.Provider}} selected{{end}}>{{.}}</option>
+{{- end}}
+</select>
+</label>
+<label>Voice <select id="voice"></select></label>
+<span class="reason">Format: {{.Format}}</span>
+<div>
+<button id="preview" disabled>Preview first section</button>
+<button id="generate" disabled>Generate all sections</button>
+</div>
+<p id="message"></p>
+<p id="download"></p>
+<ul id="sections"></ul>
+<script>
+const initialVoice = {{.Voice}};
+const $ = (id) => document.getElementById(id);
+let file = null;
+
+function pick(f) {
+  file = f;
+  $("drop").textContent = f.name;
+  $("preview").disabled = $("generate").disabled = false;
+}
+$("drop").onclick = () => $("file").click();
+$("file").onchange = (e) => e.target.files.length && pick(e.target.files[0]);
+$("drop").ondragover = (e) => { e.preventDefault(); $("drop").classList.add("over"); };
+$("drop").ondragleave = () => $("drop").classList.remove("over");
+$("drop").ondrop = (e) => {
+  e.preventDefault();
+  $("drop").classList.remove("over");
+  if (e.dataTransfer.files.length) pick(e.dataTransfer.files[0]);
+};
+
+function message(text, error) {
+  $("message").textContent = text;
+  $("message").className = error ? "error" : "";
+}
+
+async function loadVoices() {
+  const select = $("voice");
+  select.innerHTML = "";
+  message("Loading voices...");
+  const resp = await fetch("/voices?provider=" + encodeURIComponent($("provider").value));
+  const data = await resp.json();
+  if (!resp.ok) { message(data.error, true); return; }
+  for (const v of data || []) {
+    const option = new Option([v.name, v.language, v.gender].filter(Boolean).join(" - "), v.id);
+    option.selected = v.id === initialVoice;
+    select.add(option);
+  }
+  message("");
+}
+$("provider").onchange = loadVoices;
+
+async function run(preview) {
+  const form = new FormData();
+  form.append("file", file);
+  form.append("provider", $("provider").value);
+  form.append("voice", $("voice").value);
+  if (preview) form.append("preview", "1");
+
+  $("preview").disabled = $("generate").disabled = true;
+  $("sections").innerHTML = $("download").innerHTML = "";
+  message(preview ? "Generating a preview..." : "Generating audio...");
+  try {
+    const resp = await fetch("/generate", { method: "POST", body: form });
+    const data = await resp.json();
+    if (!resp.ok) { message(data.error, true); return; }
+    message("");
+    for (const s of data.sections || []) {
+      const li = document.createElement("li");
+      li.textContent = s.index + ". " + s.title;
+      if (s.status !== "ok") {
+        const status = document.createElement("span");
+        status.className = "status " + s.status;
+        status.textContent = s.status;
+        li.append(status);
+      }
+      if (s.reason) {
+        const reason = document.createElement("span");
+        reason.className = "reason";
+        reason.textContent = " " + s.reason;
+        li.append(reason);
+      }
+      if (s.url) {
+        const audio = document.createElement("audio");
+        audio.controls = true;
+        audio.src = s.url;
+        li.append(audio);
+      }
+      $("sections").append(li);
+    }
+    if (data.download) {
+      const link = document.createElement("a");
+      link.href = data.download;
+      link.textContent = "Download all (zip)";
+      $("download").append(link);
+    }
+  } catch (err) {
+    message(String(err), true);
+  } finally {
+    $("preview").disabled = $("generate").disabled = false;
+  }
+}
+$("preview").onclick = () => run(true);
+$("generate").onclick = () => run(false);
+loadVoices();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,416 @@
+// Package ui serves a minimal local web UI for md2audio, so colleagues who do
+// not use the command line can run the same pipeline from a browser: drop a
+// markdown file, pick a provider and voice from the cached voice lists,
+// preview the first section, and download the generated audio.
+//
+// Key features:
+//   - Single page with no external assets, served by the md2audio binary
+//   - Voice lists from the voice cache shared with -list-voices
+//   - Preview of the first section before generating the whole file
+//   - Audio players for each section and a zip download of the job
+//   - One job at a time, in a temporary work directory removed on exit
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/indaco/md2audio/internal/cache"
+	"github.com/indaco/md2audio/internal/cli"
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+	"github.com/indaco/md2audio/internal/processor"
+	"github.com/indaco/md2audio/internal/progress"
+	"github.com/indaco/md2audio/internal/server"
+	"github.com/indaco/md2audio/internal/tts/customhttp"
+	"github.com/indaco/md2audio/internal/tts/external"
+)
+
+// DefaultAddr is the default listen address of the UI
+const DefaultAddr = "localhost:8090"
+
+// MaxUploadBytes is the largest markdown file accepted by the UI
+const MaxUploadBytes = 1 << 20
+
+// Options configures Serve
+type Options struct {
+	Addr       string            // Listen address (default: DefaultAddr)
+	Config     config.Config     // Base configuration of generation jobs (format, timing, and other flags)
+	VoiceCache *cache.VoiceCache // Cache of the voice lists offered by the UI
+}
+
+// UI handles the requests of the web UI.
+type UI struct {
+	addr       string // Listen address, the only host requests may be addressed to besides loopback names
+	cfg        config.Config
+	voiceCache *cache.VoiceCache
+	workDir    string
+	log        logger.LoggerInterface
+	mu         sync.Mutex // Serializes jobs, as local providers are not safe to run concurrently
+
+	// process generates the audio of a markdown file (processor.ProcessFile, replaced in tests)
+	process func(markdownFile, outputDir string, cfg config.Config, log logger.LoggerInterface) error
+}
+
+// New returns a UI writing jobs into workDir.
+func New(opts Options, workDir string, log logger.LoggerInterface) *UI {
+	addr := opts.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	return &UI{
+		addr:       addr,
+		cfg:        opts.Config,
+		voiceCache: opts.VoiceCache,
+		workDir:    workDir,
+		log:        log,
+		process:    processor.ProcessFile,
+	}
+}
+
+// Handler returns the HTTP handler of the UI. Jobs spend the user's provider
+// credentials, so cross-origin form posts from other web pages are refused,
+// and so are requests addressed to another host, which DNS rebinding would
+// use to read the generated audio.
+func (u *UI) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", u.handleIndex)
+	mux.HandleFunc("GET /voices", u.handleVoices)
+	mux.HandleFunc("POST /generate", u.handleGenerate)
+	mux.Handle("GET /jobs/", http.StripPrefix("/jobs", server.Handler(u.workDir)))
+
+	protected := http.NewCrossOriginProtection().Handler(mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !u.allowedHost(r.Host) {
+			http.Error(w, "request addressed to another host", http.StatusForbidden)
+			return
+		}
+		protected.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether a request Host header addresses the UI: its
+// listen address, or a loopback name or address on its port
+func (u *UI) allowedHost(host string) bool {
+	if strings.EqualFold(host, u.addr) {
+		return true
+	}
+	_, port, err := net.SplitHostPort(u.addr)
+	if err != nil {
+		return false
+	}
+	name, reqPort, err := net.SplitHostPort(host)
+	if err != nil || reqPort != port {
+		return false
+	}
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
+
+// pageData is the data rendered by pageTemplate
+type pageData struct {
+	Providers []string
+	Provider  string
+	Voice     string
+	Format    string
+}
+
+// handleIndex renders the UI page
+func (u *UI) handleIndex(w http.ResponseWriter, r *http.Request) {
+	data := pageData{
+		Providers: u.providers(),
+		Provider:  u.cfg.Provider,
+		Voice:     jobVoice(u.cfg),
+		Format:    u.cfg.OutputFormat(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, data); err != nil {
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+	}
+}
+
+// providers returns the providers offered by the UI, honoring -local-only
+func (u *UI) providers() []string {
+	names := slices.Clone(config.BuiltinProviders)
+	for _, ext := range u.cfg.ExternalProviders {
+		names = append(names, ext.Name)
+	}
+	for _, name := range external.DiscoverAll() {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return slices.DeleteFunc(names, func(name string) bool {
+		if name == customhttp.Name && u.cfg.CustomHTTP == nil {
+			return true // Not configured
+		}
+		return u.cfg.LocalOnly && u.cfg.IsCloud(name)
+	})
+}
+
+// voiceJSON is a voice offered by the UI
+type voiceJSON struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Language string `json:"language,omitempty"`
+	Gender   string `json:"gender,omitempty"`
+}
+
+// handleVoices returns the cached voice list of a provider, fetching it on a cache miss
+func (u *UI) handleVoices(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("provider")
+	if !slices.Contains(u.providers(), name) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown provider %q", name))
+		return
+	}
+
+	cfg := u.cfg
+	cfg.Provider = name
+	provider, err := cli.CreateProvider(cfg)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	var voices []voiceJSON
+	if u.voiceCache != nil {
+		list, err := cache.NewCachedProvider(provider, u.voiceCache).ListVoices(r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Errorf("failed to list voices: %w", err))
+			return
+		}
+		for _, voice := range list {
+			voices = append(voices, voiceJSON{ID: voice.ID, Name: voice.Name, Language: voice.Language, Gender: voice.Gender})
+		}
+	}
+	writeJSON(w, voices)
+}
+
+// sectionJSON is a generated section returned by /generate
+type sectionJSON struct {
+	Index  int             `json:"index"`
+	Title  string          `json:"title"`
+	Status manifest.Status `json:"status"`
+	Reason string          `json:"reason,omitempty"`
+	URL    string          `json:"url,omitempty"`
+}
+
+// jobJSON is the result of a generation job
+type jobJSON struct {
+	Sections []sectionJSON `json:"sections"`
+	Download string        `json:"download,omitempty"`
+}
+
+// handleGenerate generates audio for an uploaded markdown file. With
+// preview=1, only its first section is generated.
+func (u *UI) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadBytes+64*1024)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no markdown file uploaded (max %d KB)", MaxUploadBytes/1024))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	provider := r.FormValue("provider")
+	if !slices.Contains(u.providers(), provider) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown provider %q", provider))
+		return
+	}
+
+	jobID, jobDir, err := u.newJob()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	name := filepath.Base(header.Filename)
+	if !strings.EqualFold(filepath.Ext(name), ".md") {
+		name += ".md"
+	}
+	markdownFile := filepath.Join(jobDir, name)
+	if err := saveUpload(markdownFile, file); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	cfg := jobConfig(u.cfg, provider, r.FormValue("voice"), r.FormValue("preview") == "1")
+	cfg.MarkdownFile = markdownFile
+	cfg.OutputDir = filepath.Join(jobDir, "audio")
+	if err := cfg.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var job jobJSON
+	cfg.Progress = func(event progress.Event) {
+		if event.Kind == progress.FileStarted || event.Kind == progress.RunCompleted {
+			return
+		}
+		section := sectionJSON{Index: event.Index, Title: event.Title, Status: event.Status, Reason: event.Reason}
+		if event.Kind == progress.SectionGenerated {
+			section.URL = u.fileURL(event.Output)
+		}
+		job.Sections = append(job.Sections, section)
+	}
+
+	u.mu.Lock()
+	u.log.Info(fmt.Sprintf("UI job %s: %s with %s", jobID, name, provider))
+	err = u.process(markdownFile, cfg.OutputDir, cfg, u.log)
+	u.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	zipPath := filepath.Join(cfg.OutputDir, strings.TrimSuffix(name, filepath.Ext(name))+".zip")
+	if _, err := os.Stat(zipPath); err == nil {
+		job.Download = u.fileURL(zipPath)
+	}
+	writeJSON(w, job)
+}
+
+// newJob creates the directory of a new job
+func (u *UI) newJob() (string, string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	jobID := time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(id)
+	jobDir := filepath.Join(u.workDir, jobID)
+	if err := os.MkdirAll(jobDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create job directory: %w", err)
+	}
+	return jobID, jobDir, nil
+}
+
+// fileURL returns the URL of a file in the work directory
+func (u *UI) fileURL(path string) string {
+	rel, err := filepath.Rel(u.workDir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return ""
+	}
+	return "/jobs/" + filepath.ToSlash(rel)
+}
+
+// jobConfig returns the configuration of a job with provider and voice. Previews
+// generate the first section only; every job is packaged into a zip archive.
+func jobConfig(base config.Config, provider, voice string, preview bool) config.Config {
+	cfg := base
+	cfg.Provider = provider
+	cfg.InputDir = ""
+	cfg.Languages = nil
+	cfg.Rerun = config.RerunConfig{}
+	cfg.Bundle = ""
+	cfg.Incremental = false
+	cfg.ZipPerFile = !preview
+	cfg.VoiceCriteria = nil
+	if preview {
+		cfg.LimitSections = 1
+	}
+
+	if voice != "" {
+		if provider == "elevenlabs" {
+			cfg.ElevenLabs.VoiceID = voice
+		} else {
+			cfg.Say.Voice = voice
+		}
+	}
+	return cfg
+}
+
+// jobVoice returns the voice of a configuration's provider
+func jobVoice(cfg config.Config) string {
+	if cfg.Provider == "elevenlabs" {
+		return cfg.ElevenLabs.VoiceID
+	}
+	return cfg.Say.Voice
+}
+
+// saveUpload writes an uploaded file to path
+func saveUpload(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	if _, err := io.Copy(f, io.LimitReader(r, MaxUploadBytes)); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to save upload: %w", err)
+	}
+	return f.Close()
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// Serve serves the UI until ctx is canceled. Jobs are written to a temporary
+// directory, removed when the UI stops.
+func Serve(ctx context.Context, opts Options, log logger.LoggerInterface) error {
+	addr := opts.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	opts.Addr = addr
+
+	workDir, err := os.MkdirTemp("", "md2audio-ui-*")
+	if err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           New(opts, workDir, log).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	log.Success(fmt.Sprintf("md2audio UI at http://%s/", addr))
+	log.Faint("Generated audio is kept until the UI stops. Press Ctrl+C to stop")
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server error: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to stop server: %w", err)
+		}
+		log.Blank()
+		log.Info("UI stopped")
+		return nil
+	}
+}
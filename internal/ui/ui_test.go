@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/indaco/md2audio/internal/config"
+	"github.com/indaco/md2audio/internal/logger"
+	"github.com/indaco/md2audio/internal/manifest"
+)
+
+// uploadForm returns a multipart body uploading content as name with fields
+func uploadForm(t *testing.T, name, content string, fields map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if name != "" {
+		fw, err := mw.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(fw, content)
+	}
+	for key, value := range fields {
+		_ = mw.WriteField(key, value)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, mw.FormDataContentType()
+}
+
+// newRequest returns a request addressed to the UI's default listen address
+func newRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Host = DefaultAddr
+	return req
+}
+
+// silentConfig returns a base configuration generating silent WAV scaffolds
+func silentConfig() config.Config {
+	return config.Config{Provider: "elevenlabs", Format: "wav", SilenceOnly: true, Say: config.SayConfig{Rate: 180}}
+}
+
+func TestIndex(t *testing.T) {
+	cfg := silentConfig()
+	cfg.LocalOnly = true
+	cfg.Provider = "say"
+	ui := New(Options{Config: cfg}, t.TempDir(), logger.NewDefaultLogger())
+
+	rec := httptest.NewRecorder()
+	ui.Handler().ServeHTTP(rec, newRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	page := rec.Body.String()
+	if !strings.Contains(page, "<option selected>say</option>") || !strings.Contains(page, "<option>espeak</option>") {
+		t.Error("local providers missing from the page")
+	}
+	if strings.Contains(page, "<option>elevenlabs</option>") {
+		t.Error("cloud provider offered in local-only mode")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	workDir := t.TempDir()
+	handler := New(Options{Config: silentConfig()}, workDir, logger.NewDefaultLogger()).Handler()
+	content := "## Intro (1s)\n\nHello there.\n\n## Outro (1s)\n\nGoodbye.\n"
+
+	run := func(fields map[string]string) jobJSON {
+		t.Helper()
+		body, contentType := uploadForm(t, "demo.md", content, fields)
+		req := newRequest(http.MethodPost, "/generate", body)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var job jobJSON
+		if err := json.NewDecoder(rec.Body).Decode(&job); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return job
+	}
+
+	t.Run("all sections", func(t *testing.T) {
+		job := run(map[string]string{"provider": "elevenlabs", "voice": "voice-1"})
+		if len(job.Sections) != 2 || job.Sections[1].Title != "Outro" || job.Sections[1].Status != manifest.StatusOK {
+			t.Fatalf("unexpected sections: %+v", job.Sections)
+		}
+		if !strings.HasPrefix(job.Download, "/jobs/") || !strings.HasSuffix(job.Download, "/audio/demo.zip") {
+			t.Errorf("download = %q", job.Download)
+		}
+
+		// Generated files are served for playback
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(http.MethodGet, job.Sections[0].URL, nil))
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "RIFF") {
+			t.Errorf("GET %s = %d", job.Sections[0].URL, rec.Code)
+		}
+	})
+
+	t.Run("preview", func(t *testing.T) {
+		job := run(map[string]string{"provider": "elevenlabs", "voice": "voice-1", "preview": "1"})
+		if len(job.Sections) != 1 || job.Sections[0].Title != "Intro" {
+			t.Errorf("preview sections = %+v, want the first section only", job.Sections)
+		}
+		if job.Download != "" {
+			t.Errorf("preview download = %q, want none", job.Download)
+		}
+	})
+}
+
+func TestGenerateErrors(t *testing.T) {
+	handler := New(Options{Config: silentConfig()}, t.TempDir(), logger.NewDefaultLogger()).Handler()
+
+	tests := []struct {
+		name   string
+		file   string
+		fields map[string]string
+		want   string
+	}{
+		{name: "no file", fields: map[string]string{"provider": "say"}, want: "no markdown file"},
+		{name: "unknown provider", file: "a.md", fields: map[string]string{"provider": "nope"}, want: "unknown provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := uploadForm(t, tt.file, "## A\n\nText.\n", tt.fields)
+			req := newRequest(http.MethodPost, "/generate", body)
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("got %d %s, want 400 with %q", rec.Code, rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossOriginRequests(t *testing.T) {
+	handler := New(Options{Config: silentConfig()}, t.TempDir(), logger.NewDefaultLogger()).Handler()
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		host    string
+		headers map[string]string
+	}{
+		{name: "cross-site form post", method: http.MethodPost, target: "/generate", host: DefaultAddr, headers: map[string]string{"Sec-Fetch-Site": "cross-site"}},
+		{name: "post from another origin", method: http.MethodPost, target: "/generate", host: DefaultAddr, headers: map[string]string{"Origin": "https://evil.example"}},
+		{name: "rebound host name", method: http.MethodGet, target: "/jobs/", host: "evil.example:8090"},
+		{name: "other port", method: http.MethodGet, target: "/", host: "localhost:9999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := uploadForm(t, "demo.md", "## Intro\n\nHello.\n", nil)
+			req := httptest.NewRequest(tt.method, tt.target, body)
+			req.Host = tt.host
+			req.Header.Set("Content-Type", contentType)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+
+	// Loopback addresses on the UI's port are the UI
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "127.0.0.1:8090"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d for a loopback host, want 200", rec.Code)
+	}
+}
+
+func TestJobConfig(t *testing.T) {
+	base := silentConfig()
+	base.InputDir = "./docs"
+	base.Incremental = true
+
+	cfg := jobConfig(base, "elevenlabs", "voice-1", true)
+	if cfg.ElevenLabs.VoiceID != "voice-1" || cfg.LimitSections != 1 || cfg.ZipPerFile {
+		t.Errorf("unexpected preview config: %+v", cfg)
+	}
+	if cfg.InputDir != "" || cfg.Incremental {
+		t.Error("directory and incremental settings not cleared")
+	}
+
+	cfg = jobConfig(base, "edge", "en-GB-SoniaNeural", false)
+	if cfg.Say.Voice != "en-GB-SoniaNeural" || cfg.LimitSections != 0 || !cfg.ZipPerFile {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
@@ -3,25 +3,39 @@
 // word counting, WPM calculations, and clamping functions.
 //
 // Key features:
-//   - Audio duration measurement (macOS afinfo)
+//   - Audio duration measurement (macOS afinfo, WAV headers)
 //   - Word counting and WPM calculations
 //   - Duration estimation utilities
+//   - Text chunking for per-request limits
 //   - Value clamping functions
+//   - Metadata comments (ffmpeg)
+//   - Time-stretching (ffmpeg atempo)
+//   - Joining audio with generated silence (ffmpeg concat)
+//   - Sample fingerprints and loudness levels
 package utils
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // GetAudioDuration measures the duration of an audio file using macOS afinfo.
 // Returns duration in seconds, or an error if the file cannot be read or parsed.
-// This function is macOS-specific and requires the afinfo command.
+// WAV files are measured from their header on any platform; other formats
+// are macOS-specific and require the afinfo command.
 func GetAudioDuration(audioPath string) (float64, error) {
+	if strings.EqualFold(filepath.Ext(audioPath), ".wav") {
+		return GetWAVDuration(audioPath)
+	}
+
 	// Verify we're on macOS
 	if runtime.GOOS != "darwin" {
 		return 0, fmt.Errorf("audio duration measurement is only available on macOS")
@@ -56,6 +70,28 @@ func CountWords(text string) int {
 	return len(strings.Fields(text))
 }
 
+// SplitText splits text into chunks of at most limit bytes, breaking at
+// whitespace where possible (for providers limiting the text per request).
+func SplitText(text string, limit int) []string {
+	var chunks []string
+	for len(text) > limit {
+		cut := strings.LastIndexAny(text[:limit], " \t\n")
+		if cut <= 0 {
+			// No whitespace: cut at the last rune boundary
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
 // CalculateWPM calculates words per minute given word count and duration.
 // Duration should be in seconds. Returns 0 if duration is invalid.
 func CalculateWPM(wordCount int, durationSeconds float64) float64 {
@@ -96,3 +132,112 @@ func ClampFloat64(value, min, max float64) float64 {
 	}
 	return value
 }
+
+// SetAudioComment writes comment into the metadata of an audio file using ffmpeg,
+// replacing the file in place. The audio stream is copied without re-encoding.
+func SetAudioComment(ctx context.Context, audioPath, comment string) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for audio tagging but not found")
+	}
+
+	ext := filepath.Ext(audioPath)
+	tmpPath := strings.TrimSuffix(audioPath, ext) + ".tagging" + ext
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", audioPath, "-map", "0", "-c", "copy", "-metadata", "comment="+comment, "-y", tmpPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ffmpeg tagging failed: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.Rename(tmpPath, audioPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace tagged audio: %w", err)
+	}
+	return nil
+}
+
+// ChangeTempo time-stretches an audio file by tempo using the ffmpeg atempo filter,
+// replacing the file in place. A tempo above 1 shortens the audio without changing
+// its pitch; the filter accepts tempos between 0.5 and 2.
+func ChangeTempo(ctx context.Context, audioPath string, tempo float64) error {
+	ext := filepath.Ext(audioPath)
+	tmpPath := strings.TrimSuffix(audioPath, ext) + ".retiming" + ext
+	if err := WriteTempo(ctx, audioPath, tmpPath, tempo); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, audioPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace time-stretched audio: %w", err)
+	}
+	return nil
+}
+
+// WriteTempo writes a copy of an audio file time-stretched by tempo to outputPath,
+// using the ffmpeg atempo filter. The filter accepts tempos between 0.5 and 2.
+func WriteTempo(ctx context.Context, audioPath, outputPath string, tempo float64) error {
+	if tempo < 0.5 || tempo > 2 {
+		return fmt.Errorf("invalid tempo %.3f: must be between 0.5 and 2", tempo)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for time-stretching but not found")
+	}
+
+	filter := "atempo=" + strconv.FormatFloat(tempo, 'f', 4, 64)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", audioPath, "-map_metadata", "0", "-filter:a", filter, "-y", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(outputPath)
+		return fmt.Errorf("ffmpeg time-stretching failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// joinSampleRate is the sample rate parts are converted to before joining
+const joinSampleRate = 44100
+
+// AudioPart is a part of the audio joined by JoinAudio: an audio file, or
+// Silence seconds of silence when Path is empty.
+type AudioPart struct {
+	Path    string
+	Silence float64
+}
+
+// JoinAudio joins audio files and silences generated by ffmpeg, in order,
+// into outputPath. The format of the output is given by its extension.
+func JoinAudio(ctx context.Context, outputPath string, parts []AudioPart) error {
+	if len(parts) == 0 {
+		return fmt.Errorf("no audio parts to join")
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for joining audio but not found")
+	}
+
+	ext := filepath.Ext(outputPath)
+	tmpPath := strings.TrimSuffix(outputPath, ext) + ".joining" + ext
+	cmd := exec.CommandContext(ctx, "ffmpeg", append(joinArgs(parts), "-y", tmpPath)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ffmpeg joining failed: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace joined audio: %w", err)
+	}
+	return nil
+}
+
+// joinArgs returns the ffmpeg inputs and filter graph concatenating parts,
+// converted to a common sample rate and channel layout
+func joinArgs(parts []AudioPart) []string {
+	var args, filters []string
+	var labels strings.Builder
+	for i, part := range parts {
+		if part.Path != "" {
+			args = append(args, "-i", part.Path)
+		} else {
+			source := "anullsrc=r=" + strconv.Itoa(joinSampleRate) + ":cl=mono"
+			args = append(args, "-f", "lavfi", "-t", strconv.FormatFloat(part.Silence, 'f', 3, 64), "-i", source)
+		}
+		filters = append(filters, fmt.Sprintf("[%d:a]aformat=sample_rates=%d:channel_layouts=mono[p%d]", i, joinSampleRate, i))
+		fmt.Fprintf(&labels, "[p%d]", i)
+	}
+	filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=0:a=1[out]", labels.String(), len(parts)))
+	return append(args, "-filter_complex", strings.Join(filters, ";"), "-map", "[out]")
+}
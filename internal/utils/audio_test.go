@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -285,3 +289,155 @@ func TestGetAudioDurationNonMacOS(t *testing.T) {
 		}
 	}
 }
+
+func TestSetAudioComment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "section_01_intro.wav")
+	if err := os.WriteFile(path, []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SetAudioComment(context.Background(), path, "md2audio dev; provider=say")
+	if err == nil {
+		t.Fatal("Expected error for invalid audio, got nil")
+	}
+	if _, lookErr := exec.LookPath("ffmpeg"); lookErr != nil && !strings.Contains(err.Error(), "ffmpeg is required") {
+		t.Errorf("Unexpected error without ffmpeg: %v", err)
+	}
+
+	// The original file is left untouched and no temporary file remains
+	if data, _ := os.ReadFile(path); string(data) != "not audio" {
+		t.Error("original file should be unchanged after a failed tagging")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "section_01_intro.tagging.wav")); !os.IsNotExist(err) {
+		t.Error("temporary file should be removed")
+	}
+}
+
+func TestChangeTempo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "section_01_intro.wav")
+	if err := os.WriteFile(path, []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tempo   float64
+		wantErr string
+	}{
+		{name: "too slow", tempo: 0.4, wantErr: "invalid tempo"},
+		{name: "too fast", tempo: 2.5, wantErr: "invalid tempo"},
+		{name: "invalid audio", tempo: 1.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ChangeTempo(context.Background(), path, tt.tempo)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ChangeTempo() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The original file is left untouched and no temporary file remains
+	if data, _ := os.ReadFile(path); string(data) != "not audio" {
+		t.Error("original file should be unchanged after a failed time-stretch")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "section_01_intro.retiming.wav")); !os.IsNotExist(err) {
+		t.Error("temporary file should be removed")
+	}
+}
+
+func TestWriteTempo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "section_01_intro.wav")
+	if err := WriteSilence(context.Background(), path, 1); err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, "1.5x", "section_01_intro.wav")
+
+	if err := WriteTempo(context.Background(), path, outputPath, 3); err == nil || !strings.Contains(err.Error(), "invalid tempo") {
+		t.Errorf("WriteTempo() error = %v, want invalid tempo", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteTempo(context.Background(), path, outputPath, 1.5)
+	if _, lookErr := exec.LookPath("ffmpeg"); lookErr != nil {
+		if err == nil {
+			t.Error("expected an error without ffmpeg")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("WriteTempo() error = %v", err)
+	}
+	duration, err := GetWAVDuration(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duration < 0.6 || duration > 0.73 {
+		t.Errorf("duration = %.2fs, want about 0.67s", duration)
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	got := strings.Join(joinArgs([]AudioPart{{Path: "a.wav"}, {Silence: 1.5}, {Path: "b.wav"}}), " ")
+	want := "-i a.wav -f lavfi -t 1.500 -i anullsrc=r=44100:cl=mono -i b.wav -filter_complex " +
+		"[0:a]aformat=sample_rates=44100:channel_layouts=mono[p0];" +
+		"[1:a]aformat=sample_rates=44100:channel_layouts=mono[p1];" +
+		"[2:a]aformat=sample_rates=44100:channel_layouts=mono[p2];" +
+		"[p0][p1][p2]concat=n=3:v=0:a=1[out] -map [out]"
+	if got != want {
+		t.Errorf("joinArgs() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJoinAudio(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "part.wav")
+	if err := WriteSilence(context.Background(), path, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, "section_01_intro.wav")
+
+	if err := JoinAudio(context.Background(), outputPath, nil); err == nil {
+		t.Error("JoinAudio() should fail without parts")
+	}
+
+	err := JoinAudio(context.Background(), outputPath, []AudioPart{{Path: path}, {Silence: 1}, {Path: path}})
+	if _, lookErr := exec.LookPath("ffmpeg"); lookErr != nil {
+		if err == nil {
+			t.Error("expected an error without ffmpeg")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("JoinAudio() error = %v", err)
+	}
+	duration, err := GetWAVDuration(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duration < 1.9 || duration > 2.1 {
+		t.Errorf("duration = %.2fs, want about 2s", duration)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	chunks := SplitText("alpha beta gamma delta", 11)
+	want := []string{"alpha beta", "gamma delta"}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Errorf("SplitText() = %q, want %q", chunks, want)
+	}
+
+	// Text without whitespace is cut at rune boundaries
+	for _, chunk := range SplitText(strings.Repeat("è", 10), 5) {
+		if !strings.HasPrefix(chunk, "è") || len(chunk) > 5 {
+			t.Errorf("invalid chunk %q", chunk)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SilenceFloorDB is the level reported for silent audio, the dynamic range of 16-bit samples
+const SilenceFloorDB = -96.0
+
+// AudioStats describes the decoded samples of an audio file.
+type AudioStats struct {
+	Fingerprint string  // SHA-256 of the decoded 16-bit PCM samples (hex)
+	RMS         float64 // RMS level in dBFS
+	Peak        float64 // Peak sample level in dBFS
+}
+
+// AnalyzeAudio fingerprints the decoded samples of an audio file and measures
+// their loudness. The fingerprint ignores the container and metadata, so
+// rewriting tags or headers does not change it. 16-bit PCM WAV files are read
+// directly; other formats are decoded with ffmpeg.
+func AnalyzeAudio(ctx context.Context, audioPath string) (AudioStats, error) {
+	if strings.EqualFold(filepath.Ext(audioPath), ".wav") {
+		stats, err := analyzeWAV(audioPath)
+		if !errors.Is(err, errNotPCM16) {
+			return stats, err
+		}
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return AudioStats{}, fmt.Errorf("ffmpeg is required to analyze %s files but not found", strings.TrimPrefix(filepath.Ext(audioPath), "."))
+	}
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-v", "error", "-i", audioPath, "-f", "s16le", "-acodec", "pcm_s16le", "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return AudioStats{}, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return AudioStats{}, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	stats, readErr := analyzePCM16(stdout)
+	if err := cmd.Wait(); err != nil {
+		return AudioStats{}, fmt.Errorf("ffmpeg decoding failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return stats, readErr
+}
+
+// errNotPCM16 reports a WAV file whose samples are not 16-bit PCM
+var errNotPCM16 = errors.New("not a 16-bit PCM WAV file")
+
+// analyzeWAV analyzes the sample data of a 16-bit PCM WAV file
+func analyzeWAV(path string) (AudioStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return AudioStats{}, fmt.Errorf("failed to open WAV file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	format, size, err := seekWAVData(f)
+	if err != nil {
+		return AudioStats{}, err
+	}
+	if format.audioFormat != 1 || format.bitsPerSample != 16 {
+		return AudioStats{}, errNotPCM16
+	}
+	return analyzePCM16(io.LimitReader(f, int64(size)))
+}
+
+// analyzePCM16 hashes little-endian 16-bit samples read from r and measures their levels
+func analyzePCM16(r io.Reader) (AudioStats, error) {
+	h := sha256.New()
+	var sumSquares float64
+	var peak, count int64
+	buf := make([]byte, 32*1024)
+	var carry []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			data := append(carry, buf[:n]...)
+			i := 0
+			for ; i+1 < len(data); i += 2 {
+				sample := int64(int16(binary.LittleEndian.Uint16(data[i:])))
+				sumSquares += float64(sample * sample)
+				peak = max(peak, sample, -sample)
+				count++
+			}
+			carry = append(carry[:0], data[i:]...)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return AudioStats{}, fmt.Errorf("failed to read audio samples: %w", err)
+		}
+	}
+
+	stats := AudioStats{Fingerprint: hex.EncodeToString(h.Sum(nil)), RMS: SilenceFloorDB, Peak: SilenceFloorDB}
+	if count > 0 {
+		stats.RMS = levelDB(math.Sqrt(sumSquares / float64(count)))
+		stats.Peak = levelDB(float64(peak))
+	}
+	return stats, nil
+}
+
+// levelDB converts a sample amplitude to dBFS, rounded to 0.1 dB and floored at SilenceFloorDB
+func levelDB(amplitude float64) float64 {
+	if amplitude <= 0 {
+		return SilenceFloorDB
+	}
+	db := 20 * math.Log10(amplitude/math.MaxInt16)
+	return max(math.Round(db*10)/10, SilenceFloorDB)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTone writes a WAV file of 16-bit samples alternating between +amplitude and -amplitude
+func writeTone(t *testing.T, path string, amplitude int16, samples int) {
+	t.Helper()
+	var pcm bytes.Buffer
+	for i := range samples {
+		sample := amplitude
+		if i%2 == 1 {
+			sample = -amplitude
+		}
+		_ = binary.Write(&pcm, binary.LittleEndian, sample)
+	}
+	if err := WritePCMAsWAV(path, &pcm, 22050); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeAudio(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	silent := filepath.Join(dir, "silent.wav")
+	if err := WriteSilence(ctx, silent, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := AnalyzeAudio(ctx, silent)
+	if err != nil {
+		t.Fatalf("AnalyzeAudio() error = %v", err)
+	}
+	if stats.RMS != SilenceFloorDB || stats.Peak != SilenceFloorDB || len(stats.Fingerprint) != 64 {
+		t.Errorf("silent stats = %+v, want levels at the floor and a SHA-256 fingerprint", stats)
+	}
+
+	half := filepath.Join(dir, "half.wav")
+	writeTone(t, half, 16384, 1000)
+	stats, err = AnalyzeAudio(ctx, half)
+	if err != nil {
+		t.Fatalf("AnalyzeAudio() error = %v", err)
+	}
+	if stats.RMS != -6 || stats.Peak != -6 {
+		t.Errorf("half-scale stats = %+v, want -6 dBFS", stats)
+	}
+
+	// Extra chunks (e.g., metadata) do not change the fingerprint of the samples
+	data, err := os.ReadFile(half)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagged := filepath.Join(dir, "tagged.wav")
+	list := append([]byte("LIST\x04\x00\x00\x00INFO"), data[12:]...)
+	withChunk := append(append([]byte{}, data[:12]...), list...)
+	if err := os.WriteFile(tagged, withChunk, 0644); err != nil {
+		t.Fatal(err)
+	}
+	taggedStats, err := AnalyzeAudio(ctx, tagged)
+	if err != nil {
+		t.Fatalf("AnalyzeAudio() error = %v", err)
+	}
+	if taggedStats.Fingerprint != stats.Fingerprint {
+		t.Error("Expected a metadata chunk to leave the fingerprint unchanged")
+	}
+
+	louder := filepath.Join(dir, "louder.wav")
+	writeTone(t, louder, 20000, 1000)
+	if louderStats, err := AnalyzeAudio(ctx, louder); err != nil || louderStats.Fingerprint == stats.Fingerprint {
+		t.Errorf("Expected different samples to change the fingerprint (err = %v)", err)
+	}
+}
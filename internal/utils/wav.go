@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wavHeaderSize is the size in bytes of a canonical 44-byte PCM WAV header.
+const wavHeaderSize = 44
+
+// silenceSampleRate is the sample rate of generated silence.
+const silenceSampleRate = 22050
+
+// WriteWAVHeader writes a canonical PCM WAV header for dataSize bytes of sample data.
+func WriteWAVHeader(w io.Writer, dataSize uint32, sampleRate, channels, bitsPerSample int) error {
+	blockAlign := channels * bitsPerSample / 8
+	byteRate := sampleRate * blockAlign
+
+	header := []any{
+		[]byte("RIFF"),
+		uint32(wavHeaderSize - 8 + dataSize),
+		[]byte("WAVE"),
+		[]byte("fmt "),
+		uint32(16), // fmt chunk size
+		uint16(1),  // audio format: PCM
+		uint16(channels),
+		uint32(sampleRate),
+		uint32(byteRate),
+		uint16(blockAlign),
+		uint16(bitsPerSample),
+		[]byte("data"),
+		dataSize,
+	}
+
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("failed to write WAV header: %w", err)
+		}
+	}
+	return nil
+}
+
+// WritePCMAsWAV writes raw 16-bit mono PCM data from r into a WAV file at path.
+// The header is patched with the final data size once all samples are written.
+func WritePCMAsWAV(path string, r io.Reader, sampleRate int) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create WAV file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// Reserve space for the header, then stream the samples
+	if err := WriteWAVHeader(f, 0, sampleRate, channels, bitsPerSample); err != nil {
+		return err
+	}
+	written, err := io.Copy(f, r)
+	if err != nil {
+		return fmt.Errorf("failed to write PCM data: %w", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind WAV file: %w", err)
+	}
+	if err := WriteWAVHeader(f, uint32(written), sampleRate, channels, bitsPerSample); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// GetWAVDuration returns the duration in seconds of a PCM WAV file by reading its header chunks.
+func GetWAVDuration(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open WAV file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	format, size, err := seekWAVData(f)
+	if err != nil {
+		return 0, err
+	}
+	return float64(size) / float64(format.byteRate), nil
+}
+
+// wavFormat holds the fmt chunk fields of a WAV file used by md2audio
+type wavFormat struct {
+	audioFormat   uint16
+	byteRate      uint32
+	bitsPerSample uint16
+}
+
+// seekWAVData walks the chunks of a WAV file, leaving f positioned at the
+// start of the sample data. It returns the format and the data size in bytes.
+func seekWAVData(f *os.File) (wavFormat, uint32, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil {
+		return wavFormat{}, 0, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return wavFormat{}, 0, fmt.Errorf("not a WAV file: %s", f.Name())
+	}
+
+	// Walk chunks until both "fmt " and "data" have been seen
+	var format wavFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(f, chunk[:]); err != nil {
+			return wavFormat{}, 0, fmt.Errorf("failed to find WAV data chunk: %w", err)
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			fmtChunk := make([]byte, size)
+			if _, err := io.ReadFull(f, fmtChunk); err != nil || size < 16 {
+				return wavFormat{}, 0, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			format = wavFormat{
+				audioFormat:   binary.LittleEndian.Uint16(fmtChunk[0:2]),
+				byteRate:      binary.LittleEndian.Uint32(fmtChunk[8:12]),
+				bitsPerSample: binary.LittleEndian.Uint16(fmtChunk[14:16]),
+			}
+		case "data":
+			if format.byteRate == 0 {
+				return wavFormat{}, 0, fmt.Errorf("WAV data chunk found before fmt chunk")
+			}
+			// Streamed WAVs may leave the size unset; fall back to the file size
+			if size == 0 || size == 0xFFFFFFFF {
+				if info, err := f.Stat(); err == nil {
+					pos, _ := f.Seek(0, io.SeekCurrent)
+					size = uint32(info.Size() - pos)
+				}
+			}
+			return format, size, nil
+		default:
+			// Chunks are padded to an even size
+			if _, err := f.Seek(int64(size+size%2), io.SeekCurrent); err != nil {
+				return wavFormat{}, 0, fmt.Errorf("failed to skip WAV chunk %q: %w", id, err)
+			}
+		}
+	}
+}
+
+// ConvertWAV converts a WAV file to another format (mp3, m4a, mp4, or aiff) using ffmpeg.
+func ConvertWAV(ctx context.Context, inputPath, outputPath, format string) error {
+	// Check if ffmpeg is available
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for audio conversion but not found")
+	}
+
+	// Build ffmpeg command
+	// ffmpeg -i input.wav -codec:a libmp3lame output.mp3 (for mp3)
+	// ffmpeg -i input.wav -codec:a aac output.m4a (for m4a)
+	var codec string
+	switch format {
+	case "mp3":
+		codec = "libmp3lame"
+	case "m4a", "mp4":
+		codec = "aac"
+	case "aiff":
+		codec = "pcm_s16be"
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", inputPath, "-codec:a", codec, "-y", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg conversion failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// ConvertTo16kMonoWAV converts an audio file to 16kHz mono 16-bit WAV using ffmpeg,
+// the input format expected by whisper.cpp.
+func ConvertTo16kMonoWAV(ctx context.Context, inputPath, outputPath string) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for audio conversion but not found")
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", inputPath, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", "-y", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg conversion failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// WriteSilence writes seconds of silent audio to path, in the format given by its extension.
+// WAV files are written directly; other formats require ffmpeg.
+func WriteSilence(ctx context.Context, path string, seconds float64) error {
+	if seconds < 0 {
+		return fmt.Errorf("invalid silence duration: %.2fs", seconds)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		samples := int64(seconds * silenceSampleRate)
+		return WritePCMAsWAV(path, io.LimitReader(zeroReader{}, samples*2), silenceSampleRate)
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("ffmpeg is required for silent %s files but not found", strings.TrimPrefix(filepath.Ext(path), "."))
+	}
+	source := "anullsrc=r=" + strconv.Itoa(silenceSampleRate) + ":cl=mono"
+	duration := strconv.FormatFloat(seconds, 'f', 3, 64)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-f", "lavfi", "-i", source, "-t", duration, "-y", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg silence generation failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// zeroReader is an endless source of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteWAVHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteWAVHeader(&buf, 1000, 44100, 1, 16); err != nil {
+		t.Fatalf("WriteWAVHeader() error = %v", err)
+	}
+
+	header := buf.Bytes()
+	if len(header) != wavHeaderSize {
+		t.Fatalf("Header size = %d, want %d", len(header), wavHeaderSize)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" || string(header[36:40]) != "data" {
+		t.Errorf("Unexpected chunk identifiers in header: %q", header)
+	}
+	if got := binary.LittleEndian.Uint32(header[4:8]); got != 1036 {
+		t.Errorf("RIFF size = %d, want 1036", got)
+	}
+	if got := binary.LittleEndian.Uint32(header[24:28]); got != 44100 {
+		t.Errorf("Sample rate = %d, want 44100", got)
+	}
+	if got := binary.LittleEndian.Uint32(header[28:32]); got != 88200 {
+		t.Errorf("Byte rate = %d, want 88200", got)
+	}
+	if got := binary.LittleEndian.Uint32(header[40:44]); got != 1000 {
+		t.Errorf("Data size = %d, want 1000", got)
+	}
+}
+
+func TestWritePCMAsWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	pcm := bytes.Repeat([]byte{0x01, 0x02}, 500)
+
+	if err := WritePCMAsWAV(path, bytes.NewReader(pcm), 24000); err != nil {
+		t.Fatalf("WritePCMAsWAV() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read WAV file: %v", err)
+	}
+	if len(data) != wavHeaderSize+len(pcm) {
+		t.Fatalf("File size = %d, want %d", len(data), wavHeaderSize+len(pcm))
+	}
+	if got := binary.LittleEndian.Uint32(data[40:44]); got != uint32(len(pcm)) {
+		t.Errorf("Data size = %d, want %d", got, len(pcm))
+	}
+	if !bytes.Equal(data[wavHeaderSize:], pcm) {
+		t.Error("PCM payload was not copied verbatim")
+	}
+}
+
+func TestGetWAVDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	// 1.5 seconds of 16-bit mono audio at 8kHz
+	pcm := make([]byte, 8000*2*3/2)
+	if err := WritePCMAsWAV(path, bytes.NewReader(pcm), 8000); err != nil {
+		t.Fatalf("WritePCMAsWAV() error = %v", err)
+	}
+
+	duration, err := GetWAVDuration(path)
+	if err != nil {
+		t.Fatalf("GetWAVDuration() error = %v", err)
+	}
+	if duration != 1.5 {
+		t.Errorf("GetWAVDuration() = %v, want 1.5", duration)
+	}
+
+	// GetAudioDuration measures WAV files on every platform
+	if d, err := GetAudioDuration(path); err != nil || d != 1.5 {
+		t.Errorf("GetAudioDuration() = %v, %v; want 1.5, nil", d, err)
+	}
+}
+
+func TestGetWAVDurationInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.wav")
+	if err := os.WriteFile(path, []byte("not a wav file at all"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := GetWAVDuration(path); err == nil {
+		t.Error("Expected error for invalid WAV file, got nil")
+	}
+	if _, err := GetWAVDuration(filepath.Join(t.TempDir(), "missing.wav")); err == nil {
+		t.Error("Expected error for missing WAV file, got nil")
+	}
+}
+
+func TestWriteSilence(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds float64
+	}{
+		{name: "zero length", seconds: 0},
+		{name: "whole seconds", seconds: 2},
+		{name: "fractional seconds", seconds: 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "silence.wav")
+			if err := WriteSilence(context.Background(), path, tt.seconds); err != nil {
+				t.Fatalf("WriteSilence() error = %v", err)
+			}
+
+			duration, err := GetWAVDuration(path)
+			if err != nil {
+				t.Fatalf("GetWAVDuration() error = %v", err)
+			}
+			if math.Abs(duration-tt.seconds) > 0.001 {
+				t.Errorf("Duration = %.3f, want %.3f", duration, tt.seconds)
+			}
+
+			data, _ := os.ReadFile(path)
+			if !bytes.Equal(data[wavHeaderSize:], make([]byte, len(data)-wavHeaderSize)) {
+				t.Error("Expected silent samples")
+			}
+		})
+	}
+}
+
+func TestWriteSilenceErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteSilence(context.Background(), filepath.Join(dir, "silence.wav"), -1); err == nil {
+		t.Error("Expected error for negative duration, got nil")
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		t.Skip("ffmpeg is installed")
+	}
+	err := WriteSilence(context.Background(), filepath.Join(dir, "silence.mp3"), 1)
+	if err == nil || !strings.Contains(err.Error(), "ffmpeg is required for silent mp3 files") {
+		t.Errorf("Expected missing ffmpeg error, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package verify provides quality checks for generated audio.
+// It transcribes audio with a local speech recognizer and compares the
+// transcript to the source text, flagging sections the TTS engine mangled.
+//
+// Key features:
+//   - Transcriber interface for local speech-to-text engines
+//   - whisper.cpp transcription via exec
+//   - Word error rate (WER) computation with text normalization
+//   - Threshold-based flagging of generated sections
+package verify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/indaco/md2audio/internal/utils"
+)
+
+// DefaultThreshold is the default maximum word error rate before a section is flagged
+const DefaultThreshold = 0.25
+
+// Transcriber converts speech audio into text.
+type Transcriber interface {
+	// Transcribe returns the text spoken in the audio file.
+	Transcribe(ctx context.Context, audioPath string) (string, error)
+
+	// Name returns the transcriber name (e.g., "whisper").
+	Name() string
+}
+
+// Result holds the outcome of verifying a generated audio file.
+type Result struct {
+	Transcript     string  // Text recognized in the audio
+	WordErrorRate  float64 // Word error rate of the transcript against the source text
+	Flagged        bool    // Whether the word error rate exceeded the threshold
+	ThresholdUsed  float64 // Threshold the word error rate was compared against
+	ReferenceWords int     // Number of words in the normalized source text
+}
+
+// Reason returns a human-readable explanation for a flagged result.
+func (r Result) Reason() string {
+	return fmt.Sprintf("word error rate %.0f%% exceeds %.0f%%", r.WordErrorRate*100, r.ThresholdUsed*100)
+}
+
+// Check transcribes audioPath and compares the transcript to text.
+// Sections are flagged when the word error rate exceeds threshold
+// (DefaultThreshold if threshold <= 0).
+func Check(ctx context.Context, transcriber Transcriber, audioPath, text string, threshold float64) (Result, error) {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	transcript, err := transcriber.Transcribe(ctx, audioPath)
+	if err != nil {
+		return Result{}, fmt.Errorf("transcription with %s failed: %w", transcriber.Name(), err)
+	}
+
+	wer := WordErrorRate(text, transcript)
+	return Result{
+		Transcript:     transcript,
+		WordErrorRate:  wer,
+		Flagged:        wer > threshold,
+		ThresholdUsed:  threshold,
+		ReferenceWords: len(NormalizeWords(text)),
+	}, nil
+}
+
+// NormalizeWords lowercases text and splits it into words, dropping punctuation.
+func NormalizeWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// WordErrorRate computes the word-level edit distance between reference and
+// hypothesis, divided by the number of reference words. Returns 0 when both
+// are empty and 1 when only the reference is empty.
+func WordErrorRate(reference, hypothesis string) float64 {
+	ref := NormalizeWords(reference)
+	hyp := NormalizeWords(hypothesis)
+
+	if len(ref) == 0 {
+		if len(hyp) == 0 {
+			return 0
+		}
+		return 1
+	}
+
+	// Levenshtein distance over words, keeping only two rows
+	prev := make([]int, len(hyp)+1)
+	curr := make([]int, len(hyp)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ref); i++ {
+		curr[0] = i
+		for j := 1; j <= len(hyp); j++ {
+			cost := 1
+			if ref[i-1] == hyp[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return float64(prev[len(hyp)]) / float64(len(ref))
+}
+
+// WhisperTranscriber transcribes audio using whisper.cpp.
+type WhisperTranscriber struct {
+	command  string
+	model    string
+	language string
+}
+
+// NewWhisperTranscriber creates a whisper.cpp transcriber.
+// The command defaults to "whisper-cli" and the language to "en".
+func NewWhisperTranscriber(command, model, language string) (*WhisperTranscriber, error) {
+	if model == "" {
+		return nil, fmt.Errorf("whisper transcription requires a model: use -whisper-model flag")
+	}
+	if command == "" {
+		command = "whisper-cli"
+	}
+	if language == "" {
+		language = "en"
+	}
+	return &WhisperTranscriber{command: command, model: model, language: language}, nil
+}
+
+// Name returns the transcriber name.
+func (w *WhisperTranscriber) Name() string {
+	return "whisper"
+}
+
+// Transcribe runs whisper.cpp on the audio file and returns the plain-text transcript.
+func (w *WhisperTranscriber) Transcribe(ctx context.Context, audioPath string) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "md2audio-verify-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// whisper.cpp requires 16kHz mono WAV input
+	wavPath := filepath.Join(tmpDir, "input.wav")
+	if err := utils.ConvertTo16kMonoWAV(ctx, audioPath, wavPath); err != nil {
+		return "", err
+	}
+
+	outBase := filepath.Join(tmpDir, "transcript")
+	cmd := exec.CommandContext(ctx, w.command, "-m", w.model, "-f", wavPath, "-l", w.language, "-nt", "-otxt", "-of", outBase)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("whisper failed: %w\nOutput: %s", err, string(output))
+	}
+
+	data, err := os.ReadFile(outBase + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to read whisper transcript: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
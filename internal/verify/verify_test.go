@@ -0,0 +1,129 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeWords(t *testing.T) {
+	got := NormalizeWords("Hello, World! It's 2025 — isn't it?")
+	expected := []string{"hello", "world", "it's", "2025", "isn't", "it"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("NormalizeWords() = %v, want %v", got, expected)
+	}
+}
+
+func TestWordErrorRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		reference  string
+		hypothesis string
+		expected   float64
+	}{
+		{
+			name:       "identical ignoring case and punctuation",
+			reference:  "Welcome to the demo.",
+			hypothesis: "welcome to the demo",
+			expected:   0,
+		},
+		{
+			name:       "one substitution",
+			reference:  "the quick brown fox",
+			hypothesis: "the quick brown box",
+			expected:   0.25,
+		},
+		{
+			name:       "skipped sentence",
+			reference:  "first part here. second part here.",
+			hypothesis: "first part here",
+			expected:   0.5,
+		},
+		{
+			name:       "insertion",
+			reference:  "hello world",
+			hypothesis: "hello big world",
+			expected:   0.5,
+		},
+		{
+			name:       "both empty",
+			reference:  "",
+			hypothesis: "",
+			expected:   0,
+		},
+		{
+			name:       "empty reference",
+			reference:  "",
+			hypothesis: "noise",
+			expected:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WordErrorRate(tt.reference, tt.hypothesis)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("WordErrorRate(%q, %q) = %v, want %v", tt.reference, tt.hypothesis, got, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeTranscriber returns a fixed transcript
+type fakeTranscriber struct {
+	transcript string
+	err        error
+}
+
+func (f *fakeTranscriber) Name() string { return "fake" }
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, audioPath string) (string, error) {
+	return f.transcript, f.err
+}
+
+func TestCheck(t *testing.T) {
+	text := "The quick brown fox jumps over the lazy dog."
+
+	result, err := Check(context.Background(), &fakeTranscriber{transcript: "the quick brown fox jumps over the lazy dog"}, "a.wav", text, 0)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if result.Flagged || result.WordErrorRate != 0 || result.ThresholdUsed != DefaultThreshold {
+		t.Errorf("Expected clean result with default threshold, got %+v", result)
+	}
+	if result.ReferenceWords != 9 {
+		t.Errorf("ReferenceWords = %d, want 9", result.ReferenceWords)
+	}
+
+	result, err = Check(context.Background(), &fakeTranscriber{transcript: "the quick brown"}, "a.wav", text, 0.3)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !result.Flagged {
+		t.Errorf("Expected flagged result, got %+v", result)
+	}
+	if !strings.Contains(result.Reason(), "exceeds 30%") {
+		t.Errorf("Unexpected reason: %s", result.Reason())
+	}
+
+	if _, err := Check(context.Background(), &fakeTranscriber{err: fmt.Errorf("boom")}, "a.wav", text, 0); err == nil {
+		t.Error("Expected error when transcription fails")
+	}
+}
+
+func TestNewWhisperTranscriber(t *testing.T) {
+	if _, err := NewWhisperTranscriber("", "", ""); err == nil {
+		t.Error("Expected error without model, got nil")
+	}
+
+	w, err := NewWhisperTranscriber("", "model.bin", "")
+	if err != nil {
+		t.Fatalf("NewWhisperTranscriber() error = %v", err)
+	}
+	if w.command != "whisper-cli" || w.language != "en" || w.Name() != "whisper" {
+		t.Errorf("Unexpected defaults: %+v", w)
+	}
+}